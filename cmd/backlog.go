@@ -5,10 +5,22 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
 
+	"git-gemini-reviewer-go/internal/backlogattach"
+	"git-gemini-reviewer-go/internal/backlogpr"
+	"git-gemini-reviewer-go/internal/backlogwiki"
+	"git-gemini-reviewer-go/internal/builder"
 	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/feedback"
+	"git-gemini-reviewer-go/internal/findings"
+	"git-gemini-reviewer-go/internal/htmlreport"
+	"git-gemini-reviewer-go/internal/msgfit"
+	"git-gemini-reviewer-go/internal/pkg/retry"
+	"git-gemini-reviewer-go/internal/verdict"
 
-	"github.com/shouni/go-notifier/pkg/factory"
 	"github.com/spf13/cobra"
 )
 
@@ -22,21 +34,37 @@ type backlogAuthInfo struct {
 
 // --- コマンド固有のフラグ変数 ---
 var (
-	backlogIssueID string // Backlog課題ID。他の issueID との競合を避けるため backlogIssueID としています。
-	noPost         bool
+	backlogIssueID  string // Backlog課題ID。他の issueID との競合を避けるため backlogIssueID としています。
+	noPost          bool
+	wikiPage        string
+	wikiProject     string
+	wikiMode        string
+	backlogPRNumber int
+	backlogRepoName string
+	autoIssueID     bool
+	// backlogCommentLimit は、全文を添付ファイルに切り替えるコメントの文字数です。post コマンドと共有します。
+	backlogCommentLimit int
 )
 
 // backlogCmd は、レビュー結果を Backlog にコメントとして投稿するコマンドです。
 var backlogCmd = &cobra.Command{
 	Use:   "backlog",
 	Short: "コードレビューを実行し、その結果をBacklogにコメントとして投稿します。",
-	Long:  `このコマンドは、指定されたGitリポジトリのブランチ間の差分をAIでレビューし、その結果をBacklogの指定された課題にコメントとして自動で投稿します。`,
-	RunE:  runBacklogCommand,
+	Long: `このコマンドは、指定されたGitリポジトリのブランチ間の差分をAIでレビューし、その結果をBacklogの指定された課題にコメントとして自動で投稿します。
+--pr-number を指定すると、Backlog Git のプルリクエストにもコメントとして投稿します。`,
+	RunE: withReviewGate(runBacklogCommand),
 }
 
 func init() {
 	backlogCmd.Flags().StringVarP(&backlogIssueID, "issue-id", "i", "", "コメントを投稿するBacklog課題ID（例: PROJECT-123）")
+	backlogCmd.Flags().BoolVar(&autoIssueID, "auto-issue-id", false, "--issue-id が未指定の場合、フィーチャーブランチ名と最新のコミットメッセージから課題キー (例: PROJECT-123) を検出して使用する")
+	addBacklogCommentLimitFlag(backlogCmd)
 	backlogCmd.Flags().BoolVar(&noPost, "no-post", false, "投稿をスキップし、結果を標準出力する")
+	backlogCmd.Flags().StringVar(&wikiPage, "wiki-page", "", "リリース判定モードのレビュー結果を公開する Backlog Wiki のページ名 (例: 'リリース/v1.2.0')。ページがなければ作成します")
+	backlogCmd.Flags().StringVar(&wikiProject, "wiki-project", "", "Wiki ページを作成するプロジェクトキー (省略時は --issue-id のプロジェクト)")
+	backlogCmd.Flags().IntVar(&backlogPRNumber, "pr-number", 0, "コメントを投稿する Backlog Git のプルリクエスト番号 (例: 12)")
+	backlogCmd.Flags().StringVar(&backlogRepoName, "repo-name", "", "プルリクエストのリポジトリ名 (省略時は --repo-url から判別)")
+	backlogCmd.Flags().StringVar(&wikiMode, "wiki-mode", string(backlogwiki.ModeAppend), "既存の Wiki ページの更新方法: 'append' (追記) または 'replace' (置き換え)")
 }
 
 // --------------------------------------------------------------------------
@@ -44,54 +72,127 @@ func init() {
 // --------------------------------------------------------------------------
 
 // runBacklogCommand はコマンドの主要な実行ロジックを含みます。
-func runBacklogCommand(cmd *cobra.Command, args []string) error {
+func runBacklogCommand(cmd *cobra.Command, args []string) (pipelineResult, error) {
 	ctx := cmd.Context()
 
 	// 1. 環境変数の確認と構造体へのカプセル化
 	authInfo := getBacklogAuthInfo()
 
 	if authInfo.APIKey == "" || authInfo.SpaceURL == "" {
-		return fmt.Errorf("Backlog連携には環境変数 BACKLOG_API_KEY および BACKLOG_SPACE_URL が必須です")
+		return pipelineResult{}, fmt.Errorf("Backlog連携には環境変数 BACKLOG_API_KEY および BACKLOG_SPACE_URL が必須です")
+	}
+
+	// プルリクエストの指定は、レビューを実行する前に検証する
+	var prRepo backlogpr.Repo
+	if backlogPRNumber > 0 {
+		repo, err := resolveBacklogPullRequestRepo()
+		if err != nil {
+			return pipelineResult{}, err
+		}
+		prRepo = repo
 	}
 
 	// 2. パイプラインを実行し、結果を受け取る
-	reviewResult, err := executeReviewPipeline(cmd.Context(), ReviewConfig)
+	res, err := executeReviewPipeline(cmd.Context(), ReviewConfig)
 	if err != nil {
-		return err
+		return res, err
 	}
+	reviewResult := res.markdown
 
 	if reviewResult == "" {
 		slog.Warn("レビュー結果の内容が空のため、Backlogへの投稿ををスキップします。")
-		return nil
+		return res, nil
 	}
 
 	// 3. no-post フラグによる出力分岐
 	if noPost {
-		printReviewResult(reviewResult)
-		return nil
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		return res, nil
 	}
 
 	// 4. Backlog投稿の必須フラグ確認
-	if backlogIssueID == "" {
-		return fmt.Errorf("Backlogに投稿するには --issue-id フラグが必須です")
+	if backlogIssueID == "" && autoIssueID {
+		backlogIssueID = detectBacklogIssueID(ReviewConfig, res.commitMessages)
+		if backlogIssueID == "" && wikiPage == "" && backlogPRNumber == 0 {
+			printReviewResult(ReviewConfig.Destination, reviewResult)
+			return res, fmt.Errorf("ブランチ名 '%s' とコミットメッセージから Backlog の課題キーを検出できませんでした。--issue-id を指定してください", ReviewConfig.FeatureBranch)
+		}
+		if backlogIssueID != "" {
+			slog.Info("ブランチ名またはコミットメッセージから Backlog の課題キーを検出しました。", "issue_id", backlogIssueID)
+		}
+	}
+	if backlogIssueID == "" && wikiPage == "" && backlogPRNumber == 0 {
+		return res, fmt.Errorf("Backlogに投稿するには --issue-id、--auto-issue-id、--pr-number または --wiki-page フラグが必須です")
+	}
+
+	// 5. 課題へのコメント投稿を実行
+	if backlogIssueID != "" {
+		err = postBacklogReview(ctx, authInfo, backlogIssueID, reviewResult)
+		if err != nil {
+			slog.Error("Backlogへのコメント投稿に失敗しました。",
+				"issue_id", backlogIssueID,
+				"error", err,
+				"mode", ReviewConfig.ReviewMode)
+			printReviewResult(ReviewConfig.Destination, reviewResult)
+
+			return res, fmt.Errorf("Backlog課題 %s へのコメント投稿処理が失敗しました。詳細はログを確認してください。", backlogIssueID)
+		}
+		slog.Info("レビュー結果を Backlog 課題にコメント投稿しました。", "issue_id", backlogIssueID)
+	}
+
+	// 6. プルリクエストへのコメント投稿を実行
+	if backlogPRNumber > 0 {
+		permalink, err := postToBacklogPullRequest(ctx, authInfo, prRepo, reviewResult)
+		if err != nil {
+			printReviewResult(ReviewConfig.Destination, reviewResult)
+			return res, fmt.Errorf("Backlog のプルリクエスト %s/%s #%d へのコメント投稿に失敗しました: %w", prRepo.ProjectKey, prRepo.Name, backlogPRNumber, err)
+		}
+		slog.Info("レビュー結果を Backlog のプルリクエストにコメント投稿しました。", "url", permalink)
 	}
 
-	// 5. 投稿内容の整形
-	finalContent := formatBacklogComment(backlogIssueID, ReviewConfig, reviewResult)
+	// 7. リリース判定の Wiki 公開を実行
+	if wikiPage != "" {
+		if err := publishReleaseWiki(ctx, authInfo, reviewResult); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
 
-	// 6. Backlog投稿を実行
-	err = postToBacklog(ctx, backlogIssueID, finalContent)
+// publishReleaseWiki は、リリース判定モードのレビュー結果を Backlog Wiki のページに公開します。
+// リリースの証跡として残すことが目的のため、他のモードでは公開しません。
+func publishReleaseWiki(ctx context.Context, authInfo backlogAuthInfo, reviewResult string) error {
+	if ReviewConfig.ReviewMode != "release" {
+		slog.Warn("Wiki への公開はリリース判定モード (--mode release) のみ対象のため、スキップします。", "mode", ReviewConfig.ReviewMode)
+		return nil
+	}
+	mode, err := backlogwiki.ParseMode(wikiMode)
 	if err != nil {
-		slog.Error("Backlogへのコメント投稿に失敗しました。",
-			"issue_id", backlogIssueID,
-			"error", err,
-			"mode", ReviewConfig.ReviewMode)
-		printReviewResult(reviewResult)
+		return err
+	}
+	project := wikiProject
+	if project == "" {
+		project = backlogwiki.ProjectKeyFromIssue(backlogIssueID)
+	}
+	if project == "" {
+		return fmt.Errorf("Wiki を公開するプロジェクトを特定できません。--wiki-project を指定してください")
+	}
+
+	client := builder.BacklogWikiClient(ctx, newHTTPClient(), authInfo.SpaceURL, authInfo.APIKey)
+	content := localizeHeadings("backlog", formatBacklogWikiEntry(ReviewConfig, reviewResult))
 
-		return fmt.Errorf("Backlog課題 %s へのコメント投稿処理が失敗しました。詳細はログを確認してください。", backlogIssueID)
+	var pageURL string
+	err = retry.Do(ctx, "backlog.publish_wiki", func(ctx context.Context) error {
+		var err error
+		pageURL, err = client.Publish(ctx, project, wikiPage, content, mode)
+		return err
+	}, retry.WithBudget(notifyRetryBudget))
+	if err != nil {
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		return fmt.Errorf("Backlog Wiki ページ '%s' への公開に失敗しました: %w", wikiPage, err)
 	}
 
-	slog.Info("レビュー結果を Backlog 課題にコメント投稿しました。", "issue_id", backlogIssueID)
+	slog.Info("レビュー結果を Backlog Wiki に公開しました。", "page", wikiPage, "mode", mode, "url", pageURL)
 	return nil
 }
 
@@ -108,30 +209,158 @@ func getBacklogAuthInfo() backlogAuthInfo {
 }
 
 // postToBacklog は、Backlogへの投稿処理の責務を持ちます。
-func postToBacklog(ctx context.Context, issueID, content string) error {
-	// 1. Contextから httpkit.Client を取得 (cmd/root.go の関数を使用)
-	httpClient, err := GetHTTPClient(ctx)
+// go-notifier の PostComment は絵文字の除去で日本語の本文まで損なうことがあるため、
+// 絵文字のみを取り除く backlogattach のクライアントで投稿します。
+func postToBacklog(ctx context.Context, authInfo backlogAuthInfo, issueID, content string) error {
+	client := builder.BacklogAttachClient(ctx, newHTTPClient(), authInfo.SpaceURL, authInfo.APIKey)
+	slog.Info("Backlog課題にレビュー結果を投稿します...", "issue_id", issueID)
+
+	// 一時的な障害に備え、共通のリトライポリシーで再試行する
+	return retry.Do(ctx, "backlog.post_comment", func(ctx context.Context) error {
+		return client.PostComment(ctx, issueID, content, nil)
+	}, retry.WithBudget(notifyRetryBudget))
+}
+
+// addBacklogCommentLimitFlag は、課題コメントの文字数の上限を指定するフラグを追加します。
+func addBacklogCommentLimitFlag(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&backlogCommentLimit, "comment-limit", msgfit.Backlog.Max, "課題コメントの文字数の上限。超える場合は全文を Markdown と HTML の添付ファイルとしてアップロードし、要約のコメントを投稿します。0 で無効化します")
+}
+
+// postBacklogReview は、レビュー結果を課題にコメントとして投稿します。
+// コメントが --comment-limit を超える場合は Backlog に拒否されるため、全文を添付ファイルとしてアップロードし、
+// 判定と指摘の件数をまとめた要約のコメントに添付します。
+func postBacklogReview(ctx context.Context, authInfo backlogAuthInfo, issueID, reviewResult string) error {
+	content := localizeHeadings("backlog", formatBacklogComment(issueID, ReviewConfig, reviewResult))
+	if msgfit.Chars(backlogCommentLimit).Fits(content) {
+		return postToBacklog(ctx, authInfo, issueID, content)
+	}
+	slog.Warn("レビュー結果がコメントの文字数の上限を超えるため、全文を添付ファイルとして投稿します。",
+		"issue_id", issueID, "length", utf8.RuneCountInString(content), "limit", backlogCommentLimit)
+
+	files, err := backlogReviewAttachments(reviewResult)
 	if err != nil {
-		slog.Error("🚨 HTTP Clientの取得に失敗しました", "error", err)
-		return fmt.Errorf("HTTP Clientの取得に失敗しました: %w", err) // エラーを返す
+		return err
 	}
+	client := builder.BacklogAttachClient(ctx, newHTTPClient(), authInfo.SpaceURL, authInfo.APIKey)
+	attachmentIDs := make([]int, 0, len(files))
+	for _, f := range files {
+		var id int
+		err := retry.Do(ctx, "backlog.upload_attachment", func(ctx context.Context) error {
+			var err error
+			id, err = client.Upload(ctx, f)
+			return err
+		}, retry.WithBudget(notifyRetryBudget))
+		if err != nil {
+			return fmt.Errorf("添付ファイル '%s' のアップロードに失敗しました: %w", f.Name, err)
+		}
+		attachmentIDs = append(attachmentIDs, id)
+	}
+
+	summary := localizeHeadings("backlog", formatBacklogAttachmentComment(issueID, ReviewConfig, reviewResult, files))
+	return retry.Do(ctx, "backlog.post_comment", func(ctx context.Context) error {
+		return client.PostComment(ctx, issueID, summary, attachmentIDs)
+	}, retry.WithBudget(notifyRetryBudget))
+}
 
-	// httpClient を使用して依存性を注入
-	backlogClient, err := factory.GetBacklogClient(httpClient)
+// backlogReviewAttachments は、レビュー結果の全文を Markdown と HTML の添付ファイルにします。
+func backlogReviewAttachments(reviewResult string) ([]backlogattach.File, error) {
+	reviewResult = localizeHeadings("backlog", reviewResult)
+	html, err := htmlreport.Render(htmlreport.ReportData{
+		Title:          localizeTitle("backlog", htmlreport.DefaultTitle),
+		RepoURL:        ReviewConfig.RepoURL,
+		BaseBranch:     ReviewConfig.BaseBranch,
+		FeatureBranch:  ReviewConfig.FeatureBranch,
+		ReviewMarkdown: reviewResult,
+		GeneratedAt:    time.Now(),
+	}, htmlReportOptions())
 	if err != nil {
-		slog.Error("🚨 Backlogクライアントの初期化に失敗しました", "error", err)
-		return fmt.Errorf("Backlogクライアントの初期化に失敗しました: %w", err) // エラーを返す
+		return nil, fmt.Errorf("HTML変換に失敗しました: %w", err)
 	}
-	slog.Info("Backlog課題にレビュー結果を投稿します...", "issue_id", issueID)
+	name := "ai-review-" + ReviewConfig.ReviewID
+	return []backlogattach.File{
+		{Name: name + ".md", Content: []byte(reviewResult)},
+		{Name: name + ".html", Content: html},
+	}, nil
+}
+
+// resolveBacklogPullRequestRepo は、プルリクエストのプロジェクトキーとリポジトリ名を解決します。
+// --repo-url が Backlog Git の URL であればそこから読み取り、プロジェクトキーが得られない場合は --issue-id から補完します。
+func resolveBacklogPullRequestRepo() (backlogpr.Repo, error) {
+	repo, _ := backlogpr.ParseRepoURL(ReviewConfig.RepoURL)
+	if backlogRepoName != "" {
+		repo.Name = backlogRepoName
+	}
+	if repo.ProjectKey == "" {
+		repo.ProjectKey = backlogwiki.ProjectKeyFromIssue(backlogIssueID)
+	}
+	if repo.ProjectKey == "" || repo.Name == "" {
+		return backlogpr.Repo{}, fmt.Errorf("プルリクエストのリポジトリを特定できません。Backlog Git の --repo-url、または --repo-name と --issue-id を指定してください")
+	}
+	return repo, nil
+}
+
+// postToBacklogPullRequest は、レビュー結果を Backlog Git のプルリクエストにコメントとして投稿し、プルリクエストのURLを返します。
+func postToBacklogPullRequest(ctx context.Context, authInfo backlogAuthInfo, repo backlogpr.Repo, reviewResult string) (string, error) {
+	client := builder.BacklogPullRequestClient(ctx, newHTTPClient(), authInfo.SpaceURL, authInfo.APIKey)
+	content := localizeHeadings("backlog", formatBacklogPullRequestComment(ReviewConfig, reviewResult))
+	slog.Info("Backlog のプルリクエストにレビュー結果を投稿します...", "project", repo.ProjectKey, "repo", repo.Name, "pr_number", backlogPRNumber)
+
+	return postParts(ctx, "backlog.post_pull_request_comment", content, msgfit.Backlog, func(ctx context.Context, part string) (string, error) {
+		return client.PostComment(ctx, repo, backlogPRNumber, part)
+	})
+}
+
+// backlogIssueURL は Backlog 課題の閲覧URLを返します。
+func backlogIssueURL(spaceURL, issueID string) string {
+	return strings.TrimRight(spaceURL, "/") + "/view/" + issueID
+}
 
-	// PostComment はリトライロジックを持つ
-	return backlogClient.PostComment(ctx, issueID, content)
+// formatBacklogWikiEntry は、Wiki ページに追記する1回分のレビュー結果を整形します。
+// 同じページに複数回追記されても区別できるよう、実施日時とレビューIDを見出しに含めます。
+func formatBacklogWikiEntry(cfg config.ReviewConfig, reviewResult string) string {
+	header := fmt.Sprintf(
+		"## AI リリース判定 (%s)\n\n"+
+			"**リポジトリ:** `%s`\n"+
+			"**基準ブランチ:** `%s`\n"+
+			"**レビュー対象ブランチ:** `%s`\n"+
+			"**レビューID:** `%s`\n\n",
+		time.Now().Format("2006-01-02 15:04"),
+		cfg.RepoURL,
+		cfg.BaseBranch,
+		cfg.FeatureBranch,
+		cfg.ReviewID,
+	)
+	return header + reviewResult
 }
 
 // formatBacklogComment はコメントのヘッダーと本文を整形します。
 func formatBacklogComment(issueID string, cfg config.ReviewConfig, reviewResult string) string {
-	// 課題番号、リポジトリ名、ブランチ情報を整形
-	header := fmt.Sprintf(
+	// ヘッダーとレビュー結果、フィードバックリンクを結合
+	return backlogCommentHeader(issueID, cfg) + reviewResult + feedback.Footer(cfg.FeedbackURL, cfg.FeedbackSecret, cfg.ReviewID)
+}
+
+// formatBacklogAttachmentComment は、全文を添付ファイルに格納した場合の要約のコメントを整形します。
+func formatBacklogAttachmentComment(issueID string, cfg config.ReviewConfig, reviewResult string, files []backlogattach.File) string {
+	var sb strings.Builder
+	sb.WriteString(backlogCommentHeader(issueID, cfg))
+	fmt.Fprintf(&sb, "**判定:** %s\n\n", verdict.Parse(reviewResult).Label())
+	counts := findings.Count(reviewResult)
+	for _, c := range findings.Categories() {
+		if n := counts[c]; n > 0 {
+			fmt.Fprintf(&sb, "- %s: %d 件\n", c.Label(), n)
+		}
+	}
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		names = append(names, "`"+f.Name+"`")
+	}
+	fmt.Fprintf(&sb, "\nレビュー結果がコメントの文字数の上限を超えるため、全文を添付ファイル %s に格納しました。\n", strings.Join(names, " / "))
+	return sb.String() + feedback.Footer(cfg.FeedbackURL, cfg.FeedbackSecret, cfg.ReviewID)
+}
+
+// backlogCommentHeader は、課題コメントの課題番号とブランチ情報のヘッダーを整形します。
+func backlogCommentHeader(issueID string, cfg config.ReviewConfig) string {
+	return fmt.Sprintf(
 		"### AI コードレビュー結果\n\n"+
 			"**対象課題ID:** `%s`\n"+
 			"**基準ブランチ:** `%s`\n"+
@@ -141,7 +370,10 @@ func formatBacklogComment(issueID string, cfg config.ReviewConfig, reviewResult
 		cfg.BaseBranch,
 		cfg.FeatureBranch,
 	)
+}
 
-	// ヘッダーとレビュー結果を結合
-	return header + reviewResult
+// formatBacklogPullRequestComment はプルリクエストへのコメントのヘッダーと本文を整形します。
+// プルリクエストの画面ではブランチが明らかなため、ヘッダーは見出しのみとします。
+func formatBacklogPullRequestComment(cfg config.ReviewConfig, reviewResult string) string {
+	return "### AI コードレビュー結果\n\n---\n" + reviewResult + feedback.Footer(cfg.FeedbackURL, cfg.FeedbackSecret, cfg.ReviewID)
 }
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"git-gemini-reviewer-go/internal/bitbucket"
+	"git-gemini-reviewer-go/internal/feedback"
+	"git-gemini-reviewer-go/internal/msgfit"
+
+	"github.com/spf13/cobra"
+)
+
+// --- 構造体: Bitbucket認証情報 ---
+
+// bitbucketAuthInfo は、Bitbucket へのコメント投稿に必要な接続先と認証情報をカプセル化します。
+type bitbucketAuthInfo struct {
+	BaseURL string
+	Creds   bitbucket.Credentials
+}
+
+// --- コマンド固有のフラグ変数 ---
+var (
+	bitbucketPullRequest int
+	bitbucketRepo        string
+	noPostBitbucket      bool
+)
+
+// bitbucketCmd は、ブランチ差分をレビューし、その結果を Bitbucket のプルリクエストにコメントとして投稿するコマンドです。
+var bitbucketCmd = &cobra.Command{
+	Use:   "bitbucket",
+	Short: "ブランチ差分をレビューし、その結果を Bitbucket のプルリクエストにコメントとして投稿します。",
+	Long: `このコマンドは、ブランチ間の差分をAIでレビューし、--pr で指定した Bitbucket のプルリクエストにコメントとして投稿します。
+環境変数 BITBUCKET_URL のホストが bitbucket.org の場合 (未指定時を含む) は Bitbucket Cloud の API 2.0 を、
+それ以外の場合は Bitbucket Server / Data Center の REST API 1.0 を使用します。
+認証には BITBUCKET_TOKEN (アクセストークン)、または BITBUCKET_USERNAME と BITBUCKET_APP_PASSWORD を使用します。`,
+	Args: cobra.NoArgs,
+	RunE: withReviewGate(runBitbucketCommand),
+}
+
+func init() {
+	bitbucketCmd.Flags().IntVar(&bitbucketPullRequest, "pr", 0, "コメントを投稿する Bitbucket のプルリクエストID (例: 42)")
+	bitbucketCmd.Flags().StringVar(&bitbucketRepo, "bitbucket-repo", "", "投稿先のリポジトリ ('ワークスペースまたはプロジェクトキー/リポジトリ')。未指定時は --repo-url から判別します")
+	bitbucketCmd.Flags().BoolVar(&noPostBitbucket, "no-post", false, "投稿をスキップし、結果を標準出力する")
+	_ = bitbucketCmd.MarkFlagRequired("pr")
+}
+
+// --------------------------------------------------------------------------
+// コマンドの実行ロジック
+// --------------------------------------------------------------------------
+
+// runBitbucketCommand はコマンドの主要な実行ロジックを含みます。
+func runBitbucketCommand(cmd *cobra.Command, args []string) (pipelineResult, error) {
+	ctx := cmd.Context()
+
+	repoSpec := bitbucketRepo
+	if repoSpec == "" {
+		repoSpec = ReviewConfig.RepoURL
+	}
+	repo, err := bitbucket.ParseRepo(repoSpec)
+	if err != nil {
+		return pipelineResult{}, err
+	}
+
+	// 1. 環境変数の確認 (no-post の場合は不要)
+	authInfo := getBitbucketAuthInfo()
+	if !noPostBitbucket && authInfo.Creds.Token == "" && (authInfo.Creds.Username == "" || authInfo.Creds.Password == "") {
+		return pipelineResult{}, fmt.Errorf("Bitbucket連携には環境変数 BITBUCKET_TOKEN、または BITBUCKET_USERNAME および BITBUCKET_APP_PASSWORD が必須です")
+	}
+
+	// 2. パイプラインを実行し、結果を受け取る
+	res, err := executeReviewPipeline(ctx, ReviewConfig)
+	if err != nil {
+		return res, err
+	}
+	reviewResult := res.markdown
+	if reviewResult == "" {
+		slog.Warn("レビュー結果の内容が空のため、Bitbucketへの投稿をスキップします。")
+		return res, nil
+	}
+
+	// 3. no-post フラグによる出力分岐
+	if noPostBitbucket {
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		return res, nil
+	}
+
+	// 4. Bitbucket投稿を実行
+	permalink, err := postToBitbucket(ctx, authInfo, repo, reviewResult)
+	if err != nil {
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		return res, fmt.Errorf("Bitbucket のプルリクエスト %s #%d へのコメント投稿に失敗しました: %w", repo, bitbucketPullRequest, err)
+	}
+
+	slog.Info("レビュー結果を Bitbucket に投稿しました。", "url", permalink)
+	return res, nil
+}
+
+// --------------------------------------------------------------------------
+// ヘルパー関数
+// --------------------------------------------------------------------------
+
+// getBitbucketAuthInfo は、環境変数から Bitbucket の接続先と認証情報を取得します。
+func getBitbucketAuthInfo() bitbucketAuthInfo {
+	return bitbucketAuthInfo{
+		BaseURL: os.Getenv("BITBUCKET_URL"),
+		Creds: bitbucket.Credentials{
+			Token:    os.Getenv("BITBUCKET_TOKEN"),
+			Username: os.Getenv("BITBUCKET_USERNAME"),
+			Password: os.Getenv("BITBUCKET_APP_PASSWORD"),
+		},
+	}
+}
+
+// postToBitbucket は、レビュー結果をプルリクエストのコメントとして投稿し、コメントのURLを返します。
+func postToBitbucket(ctx context.Context, authInfo bitbucketAuthInfo, repo bitbucket.Repo, reviewResult string) (string, error) {
+	client := bitbucket.NewClient(newHTTPClient(), authInfo.BaseURL, authInfo.Creds)
+	content := localizeHeadings("bitbucket", reviewResult) + feedback.Footer(ReviewConfig.FeedbackURL, ReviewConfig.FeedbackSecret, ReviewConfig.ReviewID)
+	slog.Info("Bitbucket のプルリクエストにレビュー結果を投稿します...", "repo", repo.String(), "pr", bitbucketPullRequest, "flavor", client.Flavor())
+
+	// 一時的な障害に備え、Backlog と同じ共通のリトライポリシーで再試行する
+	return postParts(ctx, "bitbucket.post_comment", content, msgfit.Bitbucket, func(ctx context.Context, part string) (string, error) {
+		return client.PostPullRequestComment(ctx, repo, bitbucketPullRequest, part)
+	})
+}
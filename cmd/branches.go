@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/builder"
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/gitclient"
+
+	"github.com/spf13/cobra"
+)
+
+// validateFeatureBranchesFlags は、--feature-branches と同時に指定できないフラグを検証します。
+func validateFeatureBranchesFlags() error {
+	switch {
+	case ReviewConfig.PatchFile != "" || ReviewConfig.Worktree != "":
+		return fmt.Errorf("--feature-branches は --patch-file、--worktree と同時に指定できません")
+	case ReviewConfig.FeatureBranch != "" || ReviewConfig.FeatureRev != "":
+		return fmt.Errorf("--feature-branches は --feature-branch、--feature-rev と同時に指定できません")
+	case ReviewConfig.GerritChange != "" || ReviewConfig.GitHubPullRequest != 0:
+		return fmt.Errorf("--feature-branches は Gerrit の変更や GitHub のプルリクエストのレビューでは指定できません")
+	case len(ReviewConfig.Stack) > 0:
+		return fmt.Errorf("--feature-branches と --stack は同時に指定できません")
+	case ReviewConfig.WriteBaseline:
+		// 1つのベースラインファイルをブランチごとに書き換えると、最後のブランチの指摘のみが残るため
+		return fmt.Errorf("--feature-branches と --write-baseline は同時に指定できません")
+	}
+	return nil
+}
+
+// withFeatureBranches は、レビューを実行するコマンドの RunE を包み、--feature-branches が指定された場合に
+// ブランチごとに RunE を実行して、ブランチごとにレビュー結果を投稿します。
+// クローンとフェッチは最初の1回のみ行い、以降のブランチはフェッチ済みのクローンを再利用します。
+// 一部のブランチのレビューに失敗しても残りのブランチのレビューは継続し、最後にまとめてエラーを返します。
+func withFeatureBranches(cmds ...*cobra.Command) {
+	for _, c := range cmds {
+		runE := c.RunE
+		c.RunE = func(cmd *cobra.Command, args []string) error {
+			if len(ReviewConfig.FeatureBranches) == 0 {
+				return runE(cmd, args)
+			}
+			orig := ReviewConfig
+			defer func() { ReviewConfig = orig }()
+			// グロブの展開とブランチごとのレビューで同じクローンを使うよう、クローン先をここで決める
+			base := withDefaultLocalPath(orig)
+
+			branches, fetched, err := expandFeatureBranches(cmd.Context(), base)
+			if err != nil {
+				return err
+			}
+			slog.Info("複数のフィーチャーブランチをレビューします。", "base_branch", base.BaseBranch, "branches", branches)
+			base.FeatureBranches = branches
+
+			var errs []error
+			for i, branch := range branches {
+				ReviewConfig = base
+				ReviewConfig.FeatureBranch = branch
+				// メモリ上のリポジトリはレビューごとにクローンし直すため、フェッチは省略しない
+				ReviewConfig.SkipFetch = (fetched || i > 0) && !base.InMemoryRepo
+				// クローンは最後のブランチのレビューが終わるまで残し、指定された方法での後処理は最後に1回だけ行う
+				if i < len(branches)-1 {
+					ReviewConfig.GitCleanup = string(gitclient.CleanupNone)
+				}
+				if i > 0 {
+					ReviewConfig.ReviewID = newReviewID()
+				}
+				if err := assignPromptVariant(&ReviewConfig); err != nil {
+					return err
+				}
+				slog.Info("フィーチャーブランチのレビューを開始します。", "feature_branch", branch, "review_id", ReviewConfig.ReviewID, "progress", fmt.Sprintf("%d/%d", i+1, len(branches)))
+				if err := runE(cmd, args); err != nil {
+					slog.Error("フィーチャーブランチのレビューに失敗しました。", "feature_branch", branch, "error", err)
+					errs = append(errs, fmt.Errorf("フィーチャーブランチ '%s': %w", branch, err))
+				}
+			}
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// expandFeatureBranches は --feature-branches の各要素をレビューするブランチ名に展開します。
+// グロブを含む場合はリポジトリをクローンしてフェッチし、リモートのブランチ (ベースブランチを除く) に一致させます。
+// このとき fetched は true で、クローンは以降のレビューでフェッチせずに再利用します。
+func expandFeatureBranches(ctx context.Context, cfg config.ReviewConfig) (branches []string, fetched bool, err error) {
+	var remote []string
+	if slices.ContainsFunc(cfg.FeatureBranches, isBranchGlob) {
+		if remote, err = listRemoteBranches(ctx, cfg); err != nil {
+			return nil, false, err
+		}
+		fetched = true
+	}
+
+	for _, pattern := range cfg.FeatureBranches {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if !isBranchGlob(pattern) {
+			branches = append(branches, pattern)
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, false, fmt.Errorf("--feature-branches のパターンが不正です: '%s': %w", pattern, err)
+		}
+		matched := 0
+		for _, name := range remote {
+			if ok, _ := path.Match(pattern, name); ok && name != cfg.BaseBranch {
+				branches = append(branches, name)
+				matched++
+			}
+		}
+		if matched == 0 {
+			slog.Warn("パターンに一致するリモートブランチがありません。", "pattern", pattern)
+		}
+	}
+	slices.Sort(branches)
+	branches = slices.Compact(branches)
+	if len(branches) == 0 {
+		return nil, false, fmt.Errorf("--feature-branches に一致するフィーチャーブランチがありません: %s", strings.Join(cfg.FeatureBranches, ", "))
+	}
+	return branches, fetched, nil
+}
+
+// listRemoteBranches は、リポジトリをクローン (既存のクローンは再利用) してフェッチし、リモートのブランチの一覧を返します。
+// 失敗した場合は、指定された方法でクローンを後処理します。
+func listRemoteBranches(ctx context.Context, cfg config.ReviewConfig) (branches []string, err error) {
+	cfg.FetchAll = true
+	client, err := builder.BuildGitClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := client.CloneOrUpdate(ctx, cfg.RepoURL); err != nil {
+		return nil, fmt.Errorf("リポジトリのセットアップに失敗しました: %w", err)
+	}
+	defer func() {
+		if err == nil {
+			return
+		}
+		if cleanupErr := client.Cleanup(ctx); cleanupErr != nil {
+			slog.Warn("Gitリポジトリのクリーンアップに失敗しました。", "error", cleanupErr)
+		}
+	}()
+	if err := client.Fetch(ctx); err != nil {
+		return nil, fmt.Errorf("最新の変更のフェッチに失敗しました: %w", err)
+	}
+	return client.RemoteBranches(ctx)
+}
+
+// isBranchGlob はブランチの指定がグロブ ('*'、'?'、'[') を含むかを返します。
+func isBranchGlob(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
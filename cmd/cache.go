@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"git-gemini-reviewer-go/internal/clonecache"
+
+	"github.com/spf13/cobra"
+)
+
+// CacheFlags は cache コマンド固有のフラグを保持します。
+type CacheFlags struct {
+	Format    string        // 出力形式
+	OlderThan time.Duration // clean で削除するクローンの最終使用からの経過時間 (0 の場合はすべて)
+}
+
+var cacheFlags CacheFlags
+
+// cacheCmd は、リポジトリのクローンのキャッシュディレクトリを照会・整理するコマンドです。
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "リポジトリのクローンのキャッシュディレクトリを照会・整理します。",
+	Long: `このコマンドは、--local-path を指定しない場合にリポジトリをクローンするキャッシュディレクトリ (--cache-dir) を管理します。
+'cache list' でキャッシュされたクローンの一覧を、'cache clean' でクローンの削除を行います。
+レビューのたびに合計サイズを抑える場合は --cache-max-mb を指定します。`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{standaloneCommandAnnotation: "true"},
+}
+
+// cacheListCmd は、キャッシュされたクローンの一覧を出力するコマンドです。
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "キャッシュされたクローンの一覧を最終使用日時の新しい順に出力します。",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheListCommand,
+}
+
+// cacheCleanCmd は、キャッシュされたクローンを削除するコマンドです。
+var cacheCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "キャッシュされたクローンを削除します。--older-than を指定した場合は、その期間使われていないクローンのみを削除します。",
+	Args:  cobra.NoArgs,
+	RunE:  runCacheCleanCommand,
+}
+
+func init() {
+	cacheListCmd.Flags().StringVar(&cacheFlags.Format, "format", "markdown", "出力形式: 'markdown' または 'json'")
+	cacheCleanCmd.Flags().DurationVar(&cacheFlags.OlderThan, "older-than", 0, "この期間使われていないクローンのみを削除します (例: 168h)。未指定時はすべて削除します")
+
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cacheCleanCmd)
+}
+
+// runCacheListCommand は list サブコマンドの実行ロジックです。
+func runCacheListCommand(cmd *cobra.Command, args []string) error {
+	entries, err := clonecache.List(cloneCacheDir(ReviewConfig))
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(cacheFlags.Format) {
+	case "json":
+		return writeJSON(cmd.OutOrStdout(), entries)
+	case "markdown":
+		return writeCacheTable(cmd.OutOrStdout(), cloneCacheDir(ReviewConfig), entries)
+	}
+	return fmt.Errorf("出力形式が不正です: '%s' ('markdown' または 'json' を指定してください)", cacheFlags.Format)
+}
+
+// runCacheCleanCommand は clean サブコマンドの実行ロジックです。
+func runCacheCleanCommand(cmd *cobra.Command, args []string) error {
+	if cacheFlags.OlderThan < 0 {
+		return fmt.Errorf("--older-than には0以上の期間を指定してください")
+	}
+	dir := cloneCacheDir(ReviewConfig)
+	removed, err := clonecache.Clean(dir, time.Now().Add(-cacheFlags.OlderThan))
+	slog.Info("キャッシュのクローンを削除しました。", "dir", dir, "entries", len(removed), "size", formatBytes(clonecache.TotalSize(removed)))
+	return err
+}
+
+// writeCacheTable はクローンの一覧を Markdown の表として出力します。
+func writeCacheTable(w io.Writer, dir string, entries []clonecache.Entry) error {
+	if len(entries) == 0 {
+		_, err := fmt.Fprintf(w, "キャッシュされたクローンはありません (%s)。\n", dir)
+		return err
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %d 件、合計 %s\n\n", dir, len(entries), formatBytes(clonecache.TotalSize(entries)))
+	sb.WriteString("| リポジトリ | サイズ | 最終使用日時 | パス |\n")
+	sb.WriteString("| :--- | ---: | :--- | :--- |\n")
+	for _, e := range entries {
+		repo := e.RepoURL
+		if repo == "" {
+			repo = "-"
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s | `%s` |\n", repo, formatBytes(e.Size), e.LastUsed.Local().Format("2006/01/02 15:04"), e.Path)
+	}
+	_, err := fmt.Fprint(w, sb.String())
+	return err
+}
+
+// formatBytes はバイト数を MiB または GiB の単位で表示します。
+func formatBytes(n int64) string {
+	if n >= 1<<30 {
+		return fmt.Sprintf("%.1f GiB", float64(n)/(1<<30))
+	}
+	return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"git-gemini-reviewer-go/internal/aggregate"
+	"git-gemini-reviewer-go/internal/callback"
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/diffstat"
+	"git-gemini-reviewer-go/internal/findings"
+	"git-gemini-reviewer-go/internal/pkg/retry"
+	"git-gemini-reviewer-go/internal/schema"
+	"git-gemini-reviewer-go/internal/tokenusage"
+	"git-gemini-reviewer-go/internal/verdict"
+)
+
+// sendCallback は、--callback-url が指定されている場合に、パイプラインの最終的な結果をコールバックで送信します。
+// 送信に失敗してもレビュー結果の投稿は継続するため、縮退した処理として記録します。
+// stats はレビューした差分の変更量、usage はトークン数と推定費用で、レビューが完了した場合にペイロードに含めます。
+func sendCallback(ctx context.Context, cfg config.ReviewConfig, reviewResult string, stats diffstat.Stats, usage tokenusage.Report, pipelineErr error) {
+	if cfg.CallbackURL == "" {
+		return
+	}
+	payload := callback.Payload{
+		SchemaVersion: schema.Version,
+		ReviewID:      cfg.ReviewID,
+		Status:        callback.StatusCompleted,
+		RepoURL:       cfg.RepoURL,
+		BaseBranch:    cfg.BaseBranch,
+		FeatureBranch: cfg.FeatureBranch,
+		Mode:          cfg.ReviewMode,
+		Model:         cfg.GeminiModel,
+		PromptVariant: cfg.PromptVariant,
+		Destination:   cfg.Destination,
+		Review:        reviewResult,
+		CompletedAt:   time.Now(),
+	}
+	switch {
+	case pipelineErr != nil:
+		payload.Status, payload.Error = callback.StatusFailed, pipelineErr.Error()
+	case reviewResult == "":
+		payload.Status = callback.StatusNoDiff
+	default:
+		payload.Verdict = string(verdict.Parse(reviewResult))
+		payload.Findings = findings.Count(reviewResult)
+		report := stats.Report()
+		payload.DiffStats = &report
+		if usage.Requests > 0 {
+			payload.Usage = &usage
+		}
+	}
+
+	httpClient := newHTTPClient()
+	err := retry.Do(ctx, "callback.post", func(ctx context.Context) error {
+		return callback.Send(ctx, httpClient, cfg.CallbackURL, cfg.CallbackSecret, payload)
+	}, retry.WithBudget(notifyRetryBudget))
+	if err != nil {
+		aggregate.FromContext(ctx).Degrade("callback", fmt.Errorf("レビュー結果のコールバックに失敗しました (%s): %w", cfg.CallbackURL, err))
+		return
+	}
+	slog.Info("レビュー結果をコールバックで送信しました。", "review_id", cfg.ReviewID, "status", payload.Status)
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"git-gemini-reviewer-go/internal/codecommit"
+	"git-gemini-reviewer-go/internal/feedback"
+	"git-gemini-reviewer-go/internal/msgfit"
+
+	"github.com/spf13/cobra"
+)
+
+// --- コマンド固有のフラグ変数 ---
+var (
+	pullRequestID    string
+	noPostCodeCommit bool
+)
+
+// codeCommitCmd は、CodeCommit のリポジトリをレビューし、その結果をプルリクエストのコメントとして投稿するコマンドです。
+var codeCommitCmd = &cobra.Command{
+	Use:   "codecommit",
+	Short: "AWS CodeCommit のブランチ差分をレビューし、その結果をプルリクエストのコメントとして投稿します。",
+	Long: `このコマンドは、--repo-url で指定した CodeCommit リポジトリを HTTPS (GRC 方式の SigV4 署名) でクローンし、ブランチ間の差分をAIでレビューします。
+リポジトリURLには codecommit::<region>://<repository> 形式 (git-remote-codecommit と同じ) または HTTPS URL を指定できます。
+レビュー結果は --pull-request-id で指定したプルリクエストに、最新のソースコミットに対するコメントとして投稿されます。`,
+	Args: cobra.NoArgs,
+	RunE: withReviewGate(runCodeCommitCommand),
+}
+
+func init() {
+	codeCommitCmd.Flags().StringVar(&pullRequestID, "pull-request-id", "", "コメントを投稿する CodeCommit のプルリクエストID (例: 42)")
+	codeCommitCmd.Flags().BoolVar(&noPostCodeCommit, "no-post", false, "投稿をスキップし、結果を標準出力する")
+	_ = codeCommitCmd.MarkFlagRequired("pull-request-id")
+}
+
+// --------------------------------------------------------------------------
+// コマンドの実行ロジック
+// --------------------------------------------------------------------------
+
+// runCodeCommitCommand はコマンドの主要な実行ロジックを含みます。
+func runCodeCommitCommand(cmd *cobra.Command, args []string) (pipelineResult, error) {
+	ctx := cmd.Context()
+
+	// 1. リポジトリと認証情報の確認 (no-post の場合も、クローンに認証情報が必要です)
+	remote, err := codecommit.ParseURL(ReviewConfig.RepoURL, codecommit.RegionFromEnv())
+	if err != nil {
+		return pipelineResult{}, err
+	}
+	creds, err := codecommit.CredentialsFromEnv()
+	if err != nil {
+		return pipelineResult{}, err
+	}
+
+	// 2. パイプラインを実行し、結果を受け取る
+	res, err := executeReviewPipeline(ctx, ReviewConfig)
+	if err != nil {
+		return res, err
+	}
+	reviewResult := res.markdown
+	if reviewResult == "" {
+		slog.Warn("レビュー結果の内容が空のため、CodeCommitへの投稿をスキップします。")
+		return res, nil
+	}
+
+	// 3. no-post フラグによる出力分岐
+	if noPostCodeCommit {
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		return res, nil
+	}
+
+	// 4. CodeCommit投稿を実行
+	permalink, err := postToCodeCommit(ctx, creds, remote, reviewResult)
+	if err != nil {
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		return res, fmt.Errorf("CodeCommit のプルリクエスト %s へのコメント投稿に失敗しました: %w", pullRequestID, err)
+	}
+
+	slog.Info("レビュー結果を CodeCommit に投稿しました。", "url", permalink)
+	return res, nil
+}
+
+// --------------------------------------------------------------------------
+// ヘルパー関数
+// --------------------------------------------------------------------------
+
+// postToCodeCommit は、レビュー結果をプルリクエストのコメントとして投稿し、プルリクエストのURLを返します。
+func postToCodeCommit(ctx context.Context, creds codecommit.Credentials, remote codecommit.Remote, reviewResult string) (string, error) {
+	client := codecommit.NewClient(newHTTPClient(), creds, remote.Region)
+	content := localizeHeadings("codecommit", reviewResult) + feedback.Footer(ReviewConfig.FeedbackURL, ReviewConfig.FeedbackSecret, ReviewConfig.ReviewID)
+
+	return postParts(ctx, "codecommit.post_comment", content, msgfit.CodeCommit, func(ctx context.Context, part string) (string, error) {
+		return client.PostPullRequestComment(ctx, pullRequestID, remote.Repository, part)
+	})
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"git-gemini-reviewer-go/internal/digest"
+	"git-gemini-reviewer-go/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+// --- コマンド固有のフラグ変数 ---
+var (
+	digestDays   int
+	digestEvery  time.Duration
+	noPostDigest bool
+)
+
+// digestCmd は、レビュー履歴から直近の期間のダイジェストを作成し、Slack に投稿するコマンドです。
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "直近のレビュー履歴を集計したダイジェストを Slack に投稿します。",
+	Long: `このコマンドは、--history-file に記録されたレビュー履歴から直近 --days 日分を集計し、
+レビューしたブランチ、判定の内訳、よく指摘されたカテゴリをまとめたダイジェストを Slack に投稿します。
+cron などで週次に実行するか、--every を指定して常駐させてください。`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{standaloneCommandAnnotation: "true"},
+	RunE:        runDigestCommand,
+}
+
+func init() {
+	digestCmd.Flags().IntVar(&digestDays, "days", 7, "集計する期間 (日数)")
+	digestCmd.Flags().DurationVar(&digestEvery, "every", 0, "指定した間隔でダイジェストを繰り返し投稿します (例: 168h)。0 の場合は1回だけ実行します")
+	digestCmd.Flags().BoolVar(&noPostDigest, "no-post", false, "投稿をスキップし、ダイジェストを標準出力する")
+}
+
+// runDigestCommand はコマンドの主要な実行ロジックを含みます。
+func runDigestCommand(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if digestDays <= 0 {
+		return fmt.Errorf("--days には1以上を指定してください")
+	}
+
+	authInfo := getSlackAuthInfo()
+	if !noPostDigest && authInfo.WebhookURL == "" {
+		return fmt.Errorf("SLACK_WEBHOOK_URL 環境変数の設定が必須です。")
+	}
+
+	path := ReviewConfig.HistoryFile
+	if path == "" {
+		path = history.DefaultPath()
+	}
+	store := history.NewStore(path)
+
+	if digestEvery <= 0 {
+		return postDigest(ctx, store, authInfo)
+	}
+
+	slog.Info("ダイジェストを定期的に投稿します。", "every", digestEvery, "history", path)
+	ticker := time.NewTicker(digestEvery)
+	defer ticker.Stop()
+	for {
+		// 一時的な失敗で常駐を止めないよう、エラーはログに留めて次回に再試行します
+		if err := postDigest(ctx, store, authInfo); err != nil {
+			slog.Error("ダイジェストの投稿に失敗しました。", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// postDigest は、直近の期間のダイジェストを作成して投稿します。
+func postDigest(ctx context.Context, store *history.Store, authInfo slackAuthInfo) error {
+	until := time.Now()
+	since := until.AddDate(0, 0, -digestDays)
+
+	reviews, err := store.List(since)
+	if err != nil {
+		return err
+	}
+	d := digest.Build(reviews, since, until)
+
+	if noPostDigest {
+		fmt.Printf("## %s\n\n%s", d.Title(), d.Markdown())
+		return nil
+	}
+	if err := sendSlackText(ctx, d.Title(), d.Markdown(), authInfo); err != nil {
+		return fmt.Errorf("ダイジェストの Slack への投稿に失敗しました: %w", err)
+	}
+	slog.Info("ダイジェストを Slack に投稿しました。", "reviews", d.Total, "since", since.Format(time.DateOnly))
+	return nil
+}
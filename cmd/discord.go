@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"git-gemini-reviewer-go/internal/discord"
+	"git-gemini-reviewer-go/internal/feedback"
+	"git-gemini-reviewer-go/internal/msgfit"
+
+	"github.com/spf13/cobra"
+)
+
+// --- コマンド固有のフラグ変数 ---
+var (
+	noPostDiscord bool // 投稿をスキップする
+)
+
+// discordCmd は、レビュー結果を Discord の Webhook に投稿するコマンドです。
+var discordCmd = &cobra.Command{
+	Use:   "discord",
+	Short: "コードレビューを実行し、その結果を Discord のチャンネルに投稿します。",
+	Long: `このコマンドは、ブランチ間の差分をAIでレビューし、その結果を環境変数 DISCORD_WEBHOOK_URL の Webhook に埋め込み (embed) として投稿します。
+1件の埋め込みの上限 (4096 文字) を超える場合は、コードブロックを保ったまま複数のメッセージに分割して投稿します。`,
+	Args: cobra.NoArgs,
+	RunE: withReviewGate(runDiscordCommand),
+}
+
+func init() {
+	discordCmd.Flags().BoolVar(&noPostDiscord, "no-post", false, "投稿をスキップし、結果を標準出力する")
+}
+
+// --------------------------------------------------------------------------
+// コマンドの実行ロジック
+// --------------------------------------------------------------------------
+
+// runDiscordCommand はコマンドの主要な実行ロジックを含みます。
+func runDiscordCommand(cmd *cobra.Command, args []string) (pipelineResult, error) {
+	ctx := cmd.Context()
+
+	// 1. 環境変数の確認 (no-post の場合は不要)
+	webhookURL := os.Getenv("DISCORD_WEBHOOK_URL")
+	if !noPostDiscord && webhookURL == "" {
+		return pipelineResult{}, fmt.Errorf("DISCORD_WEBHOOK_URL 環境変数の設定が必須です。")
+	}
+
+	// 2. パイプラインを実行し、結果を受け取る
+	res, err := executeReviewPipeline(ctx, ReviewConfig)
+	if err != nil {
+		return res, err
+	}
+	reviewResult := res.markdown
+	if reviewResult == "" {
+		slog.Warn("レビュー結果の内容が空のため、Discordへの投稿をスキップします。")
+		return res, nil
+	}
+
+	// 3. no-post フラグによる出力分岐
+	if noPostDiscord {
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		return res, nil
+	}
+
+	// 4. Discord投稿を実行
+	if err := postToDiscord(ctx, webhookURL, reviewResult); err != nil {
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		return res, fmt.Errorf("Discord へのメッセージ投稿に失敗しました: %w", err)
+	}
+
+	slog.Info("レビュー結果を Discord に投稿しました。")
+	return res, nil
+}
+
+// --------------------------------------------------------------------------
+// ヘルパー関数
+// --------------------------------------------------------------------------
+
+// postToDiscord は、レビュー結果を Discord の Webhook に投稿します。
+// タイトルは最初のメッセージにのみ付与し、分割した後続のメッセージは '(2/3)' のような番号で続きであることを示します。
+func postToDiscord(ctx context.Context, webhookURL, reviewResult string) error {
+	client := discord.NewClient(newHTTPClient(), webhookURL)
+	title := fmt.Sprintf(localizeTitle("discord", "AIコードレビュー結果 (ブランチ: %s ← %s)"), ReviewConfig.BaseBranch, ReviewConfig.FeatureBranch)
+	content := localizeHeadings("discord", reviewResult) + feedback.Footer(ReviewConfig.FeedbackURL, ReviewConfig.FeedbackSecret, ReviewConfig.ReviewID)
+	slog.Info("Discord Webhook URL に投稿します...")
+
+	first := true
+	_, err := postParts(ctx, "discord.execute_webhook", content, msgfit.Discord, func(ctx context.Context, part string) (string, error) {
+		partTitle := ""
+		if first {
+			partTitle = title
+		}
+		if err := client.Post(ctx, partTitle, part); err != nil {
+			return "", err
+		}
+		first = false
+		return "", nil
+	})
+	return err
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"git-gemini-reviewer-go/internal/aggregate"
+	"git-gemini-reviewer-go/internal/batch"
+	"git-gemini-reviewer-go/internal/builder"
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/estimate"
+	"git-gemini-reviewer-go/internal/ratelimit"
+
+	"github.com/spf13/cobra"
+)
+
+// --- コマンド固有のフラグ変数 ---
+var (
+	estimateBatchFile       string
+	estimateFormat          string
+	estimateOutputTokens    int
+	estimateRequestDuration time.Duration
+	estimateInputPrice      float64
+	estimateOutputPrice     float64
+)
+
+// estimateCmd は、モデルを呼び出さずにレビューの費用と所要時間を見積もるコマンドです。
+var estimateCmd = &cobra.Command{
+	Use:   "estimate",
+	Short: "モデルを呼び出さずに、レビューのトークン数・費用・所要時間を見積もります。",
+	Long: `このコマンドは、--batch-file のバッチ設定の各レビュー対象 (未指定時はフラグで指定した1件) の差分のみを取得し、
+レビューと同じプロンプトを組み立ててトークン数を数え、レビュー対象ごとと合計の費用と所要時間の見積もりを出力します。
+Gemini API は呼び出さず、レビュー結果の投稿や履歴への記録も行いません。
+
+入力トークン数はプロンプトの長さからの概算です。出力トークン数と応答時間は --output-tokens と --request-duration の仮定を使用し、
+--ai-qpm / --ai-tpm が指定されている場合は、レート制限から求めた時間を所要時間の下限とします。`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{standaloneCommandAnnotation: "true"},
+	RunE:        runEstimateCommand,
+}
+
+func init() {
+	estimateCmd.Flags().StringVar(&estimateBatchFile, "batch-file", "", "レビュー対象の一覧を記述したバッチ設定 (YAML) のパス。未指定時は --repo-url と --feature-branch の1件を見積もります")
+	estimateCmd.Flags().StringVar(&estimateFormat, "format", "markdown", "出力形式: 'markdown' または 'json'")
+	estimateCmd.Flags().IntVar(&estimateOutputTokens, "output-tokens", 2000, "1リクエストあたりの出力トークン数の仮定")
+	estimateCmd.Flags().DurationVar(&estimateRequestDuration, "request-duration", 30*time.Second, "1リクエストの応答時間の仮定")
+	estimateCmd.Flags().Float64Var(&estimateInputPrice, "input-price", 0, "100万入力トークンあたりの料金 (USD)。指定時は組み込みのモデルごとの料金の代わりに使用します")
+	estimateCmd.Flags().Float64Var(&estimateOutputPrice, "output-price", 0, "100万出力トークンあたりの料金 (USD)。指定時は組み込みのモデルごとの料金の代わりに使用します")
+}
+
+// runEstimateCommand はコマンドの主要な実行ロジックを含みます。
+func runEstimateCommand(cmd *cobra.Command, args []string) error {
+	format := strings.ToLower(estimateFormat)
+	if format != "markdown" && format != "json" {
+		return fmt.Errorf("出力形式が不正です: '%s' ('markdown' または 'json' を指定してください)", estimateFormat)
+	}
+	if estimateOutputTokens < 0 || estimateRequestDuration < 0 || estimateInputPrice < 0 || estimateOutputPrice < 0 {
+		return fmt.Errorf("--output-tokens、--request-duration、--input-price、--output-price には 0 以上の値を指定してください")
+	}
+
+	targets, err := estimateTargets()
+	if err != nil {
+		return err
+	}
+	prices, err := estimate.LoadPrices(ReviewConfig.PricingFile)
+	if err != nil {
+		return err
+	}
+	assumptions := estimate.Assumptions{
+		OutputTokens:    estimateOutputTokens,
+		RequestDuration: estimateRequestDuration,
+		Limits: ratelimit.Limits{
+			RequestsPerMinute: ReviewConfig.AIRequestsPerMinute,
+			TokensPerMinute:   ReviewConfig.AITokensPerMinute,
+		},
+		Prices: prices,
+	}
+	if cmd.Flags().Changed("input-price") || cmd.Flags().Changed("output-price") {
+		assumptions.Price = &estimate.Price{Input: estimateInputPrice, Output: estimateOutputPrice}
+	}
+
+	// 同じリポジトリの複数のレビュー対象で SSH の認証や PromptBuilder を構築し直さないよう、実行の間は再利用します
+	cache := builder.NewCache()
+	defer cache.Close()
+	ctx := builder.ContextWithCache(cmd.Context(), cache)
+
+	items := make([]estimate.Item, 0, len(targets))
+	for _, t := range targets {
+		items = append(items, estimateTarget(ctx, t.label, t.cfg, assumptions))
+	}
+	report := estimate.NewReport(items, assumptions)
+
+	if format == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	_, err = fmt.Fprint(cmd.OutOrStdout(), report.Markdown())
+	return err
+}
+
+// estimateTargetConfig は見積もるレビュー対象とその設定です。
+type estimateTargetConfig struct {
+	label string
+	cfg   config.ReviewConfig
+}
+
+// estimateTargets は、バッチ設定またはフラグから見積もるレビュー対象を返します。
+func estimateTargets() ([]estimateTargetConfig, error) {
+	if estimateBatchFile == "" {
+		if err := validateReviewTargetFlags(); err != nil {
+			return nil, err
+		}
+		label := batch.Target{RepoURL: ReviewConfig.RepoURL, FeatureBranch: ReviewConfig.FeatureBranch}.Label()
+		if ReviewConfig.PatchFile != "" {
+			label = ReviewConfig.PatchFile
+		} else if ReviewConfig.Worktree != "" {
+			label = ReviewConfig.Worktree
+		}
+		return []estimateTargetConfig{{label: label, cfg: ReviewConfig}}, nil
+	}
+
+	file, err := batch.Load(estimateBatchFile)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]estimateTargetConfig, 0, len(file.Targets))
+	for _, t := range file.Targets {
+		cfg := t.Apply(ReviewConfig)
+		cfg.PatchFile, cfg.Worktree = "", ""
+		targets = append(targets, estimateTargetConfig{label: t.Label(), cfg: cfg})
+	}
+	return targets, nil
+}
+
+// estimateTarget は、1件のレビュー対象の差分を取得し、モデルを呼び出さずにプロンプトの大きさを見積もります。
+// 見積もりに失敗した場合も他のレビュー対象の見積もりを継続するため、エラーは見積もりの一覧に記録します。
+func estimateTarget(ctx context.Context, label string, cfg config.ReviewConfig, a estimate.Assumptions) estimate.Item {
+	slog.Info("レビューの見積もりを開始します。", "target", label)
+	cfg = withDefaultLocalPath(cfg)
+
+	recorder := estimate.NewRecorder()
+	reviewRunner, err := builder.BuildEstimateRunner(ctx, cfg, recorder)
+	if err != nil {
+		return estimate.Failed(label, cfg.GeminiModel, fmt.Errorf("レビュー実行器の構築に失敗しました: %w", err))
+	}
+	if _, err := reviewRunner.Run(ctx, cfg); err != nil {
+		if pe, ok := aggregate.AsPipelineError(err); !ok || pe.Fatal() {
+			slog.Warn("レビューの見積もりに失敗しました。", "target", label, "error", err)
+			return estimate.Failed(label, cfg.GeminiModel, err)
+		}
+		slog.Warn("レビューの見積もりで一部の処理が失敗しました。", "target", label, "error", err)
+	}
+	return estimate.NewItem(label, cfg.GeminiModel, recorder.Tokens(), a)
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/experiment"
+)
+
+// assignPromptVariant は、プロンプトの A/B 実験が設定されている場合に、レビュー対象に派生を割り当てます。
+// 同じリポジトリとブランチは常に同じ派生になるため、再レビューで派生が入れ替わることはありません。
+func assignPromptVariant(cfg *config.ReviewConfig) error {
+	cfg.PromptVariant = ""
+	if cfg.PromptVariantB == "" {
+		return nil
+	}
+	if err := experiment.ValidateSplit(cfg.PromptSplit); err != nil {
+		return err
+	}
+	// テンプレートの誤りは、B が割り当てられたレビューの実行時ではなく起動時に検出する
+	if _, err := experiment.LoadTemplate(cfg.PromptVariantB); err != nil {
+		return err
+	}
+
+	key := cfg.RepoURL + "\x00" + cfg.FeatureBranch
+	if cfg.RepoURL == "" && cfg.FeatureBranch == "" {
+		// パッチファイルのレビューなど振り分けキーがない場合は、レビューごとに振り分ける
+		key = cfg.ReviewID
+	}
+	cfg.PromptVariant = experiment.Assign(key, cfg.PromptSplit)
+	slog.Info("プロンプトの派生を割り当てました。", "prompt_variant", cfg.PromptVariant, "split", cfg.PromptSplit)
+	return nil
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"git-gemini-reviewer-go/internal/aggregate"
+	"git-gemini-reviewer-go/internal/notify"
+	"git-gemini-reviewer-go/internal/progress"
+
+	"github.com/spf13/cobra"
+)
+
+// withFailureReport は、レビューを実行するコマンドの RunE を包み、パイプラインで発生した失敗を収集します。
+// 失敗があった場合は深刻度ごとの要約を標準エラー出力に表示し、深刻度に応じた終了コードでプロセスを終了します
+// (致命的な失敗: 1、縮退した処理のみ: 3)。
+func withFailureReport(cmds ...*cobra.Command) {
+	for _, c := range cmds {
+		runE := c.RunE
+		c.RunE = func(cmd *cobra.Command, args []string) error {
+			issues := aggregate.NewPipeline()
+			cmd.SetContext(aggregate.NewContext(cmd.Context(), issues))
+
+			done := progress.Start(cmd.Context(), ReviewConfig.ReviewID, progress.PhaseCommand, cmd.Name())
+			issues.Merge(cmd.Name(), runE(cmd, args))
+			pe, ok := aggregate.AsPipelineError(issues.Err())
+			if !ok {
+				done(nil)
+				return nil
+			}
+			if pe.Fatal() {
+				done(pe)
+			} else {
+				// 縮退した処理のみの場合、レビュー結果は配信されているため完了として通知します
+				done(nil)
+			}
+			fmt.Fprintln(os.Stderr, pe.Summary())
+			os.Exit(pe.ExitCode())
+			return nil
+		}
+	}
+}
+
+// deliveryError は、ファンアウト配信の失敗を深刻度に応じて振り分けます。
+// 一部の配信先のみが失敗した場合は縮退した処理として記録して nil を返し、すべて失敗した場合はエラーを返します。
+func deliveryError(cmd *cobra.Command, results []notify.Result, err error) error {
+	me, ok := aggregate.AsMultiError(err)
+	if !ok || len(me.Failures) >= len(results) {
+		return err
+	}
+	issues := aggregate.FromContext(cmd.Context())
+	for _, f := range me.Failures {
+		issues.Degrade("notify."+f.Name, f.Err)
+	}
+	return nil
+}
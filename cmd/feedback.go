@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"git-gemini-reviewer-go/internal/feedback"
+
+	"github.com/spf13/cobra"
+)
+
+// --- コマンド固有のフラグ変数 ---
+var (
+	feedbackStorePath string
+	feedbackReviewID  string
+	feedbackChannel   string
+	feedbackTimestamp string
+)
+
+// feedbackCmd は、レビュー結果に対するフィードバック (👍/👎) の収集と集計を行うコマンド群です。
+var feedbackCmd = &cobra.Command{
+	Use:   "feedback",
+	Short: "レビュー結果へのフィードバック (👍/👎) を収集・集計します。",
+	Long: `AIレビューの指摘が実際に役立っているかを測定するため、コメント内リンクからの投票や Slack のリアクション数をレビューIDと紐付けて記録します。
+
+コメント内リンクからの投票は serve コマンドの /feedback で受け付けます。`,
+	Annotations: map[string]string{standaloneCommandAnnotation: "true"},
+}
+
+// feedbackSlackReactionsCmd は、Slack に投稿したレビューへのリアクション数を取得して記録します。
+var feedbackSlackReactionsCmd = &cobra.Command{
+	Use:   "slack-reactions",
+	Short: "Slack メッセージの 👍/👎 リアクション数を取得し、レビューIDと紐付けて記録します。",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		token := os.Getenv("SLACK_BOT_TOKEN")
+		if token == "" {
+			return fmt.Errorf("リアクションの取得には SLACK_BOT_TOKEN 環境変数の設定が必須です。")
+		}
+		if feedbackReviewID == "" || feedbackChannel == "" || feedbackTimestamp == "" {
+			return fmt.Errorf("--review-id, --channel, --ts フラグはすべて必須です")
+		}
+
+		counter := feedback.NewSlackReactionCounter(newHTTPClient(), token)
+		up, down, err := counter.Count(cmd.Context(), feedbackChannel, feedbackTimestamp)
+		if err != nil {
+			return err
+		}
+
+		store := feedback.NewStore(feedbackStorePath)
+		for vote, count := range map[feedback.Vote]int{feedback.VoteUp: up, feedback.VoteDown: down} {
+			// リアクション数は集計値のスナップショットとして記録するため、0件も明示的に保存します。
+			rec := feedback.Record{ReviewID: feedbackReviewID, Source: feedback.SourceSlackReaction, Vote: vote, Count: count}
+			if err := store.Append(rec); err != nil {
+				return err
+			}
+		}
+
+		slog.Info("Slack リアクション数を記録しました。", "review_id", feedbackReviewID, "up", up, "down", down)
+		return nil
+	},
+}
+
+// feedbackReportCmd は、記録済みのフィードバックをレビューIDごとに集計して JSON で出力します。
+var feedbackReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "記録済みのフィードバックをレビューIDごとに集計して出力します。",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		summaries, err := feedback.NewStore(feedbackStorePath).Summarize()
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(summaries)
+	},
+}
+
+func init() {
+	feedbackCmd.PersistentFlags().StringVar(&feedbackStorePath, "store", defaultFeedbackStorePath(), "フィードバックを保存する JSON Lines ファイルのパス")
+
+	feedbackSlackReactionsCmd.Flags().StringVar(&feedbackReviewID, "review-id", "", "紐付けるレビューID (ログの review_id)")
+	feedbackSlackReactionsCmd.Flags().StringVar(&feedbackChannel, "channel", "", "レビューを投稿した Slack チャンネルID")
+	feedbackSlackReactionsCmd.Flags().StringVar(&feedbackTimestamp, "ts", "", "レビューを投稿した Slack メッセージのタイムスタンプ")
+
+	feedbackCmd.AddCommand(feedbackSlackReactionsCmd, feedbackReportCmd)
+}
+
+// defaultFeedbackStorePath は、フィードバック保存先のデフォルトパスを返します。
+func defaultFeedbackStorePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "feedback.jsonl"
+	}
+	return filepath.Join(home, ".git-gemini-reviewer", "feedback.jsonl")
+}
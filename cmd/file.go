@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"git-gemini-reviewer-go/internal/gcsindex"
+	"git-gemini-reviewer-go/internal/htmlreport"
+	"git-gemini-reviewer-go/internal/mdreport"
+
+	"github.com/spf13/cobra"
+)
+
+// FileFlags は file コマンド固有のフラグを保持します。
+type FileFlags struct {
+	Output         string // 保存先のファイルのパス
+	Format         string // 保存する形式 ('markdown' または 'html')。空の場合は拡張子から判別します
+	HeaderTemplate string // Markdown のヘッダのテンプレートのパス
+}
+
+var fileFlags FileFlags
+
+// 保存する形式です。
+const (
+	fileFormatMarkdown = "markdown"
+	fileFormatHTML     = "html"
+)
+
+// fileCmd は、レビュー結果をローカルの Markdown または HTML ファイルに保存するコマンドです。
+var fileCmd = &cobra.Command{
+	Use:   "file",
+	Short: "コードレビューを実行し、その結果をローカルの Markdown または HTML ファイルに保存します。",
+	Long: `このコマンドは、ブランチ間の差分をAIでレビューし、リポジトリ・ブランチ・日時・モデルを記したヘッダを付与して --output のファイルに保存します。
+形式は --output の拡張子 (.html / .htm は HTML、それ以外は Markdown) または --file-format で指定します。
+Markdown のヘッダは --header-template で text/template 形式のテンプレートに置き換えられます ({{.RepoURL}}、{{.BaseBranch}}、{{.FeatureBranch}}、{{.Model}}、{{.Mode}}、{{.ReviewID}}、{{.GeneratedAt}})。
+'{review_id}' と '{repo}' はレビューIDとリポジトリ名に置き換わります。`,
+	Args: cobra.NoArgs,
+	RunE: withReviewGate(runFileCommand),
+}
+
+func init() {
+	fileCmd.Flags().StringVarP(&fileFlags.Output, "output", "o", "", "保存先のファイルのパス (例: 'reviews/{repo}/{review_id}.md')。(必須)")
+	fileCmd.Flags().StringVar(&fileFlags.Format, "file-format", "", "保存する形式: 'markdown' または 'html'。未指定時は --output の拡張子から判別します")
+	fileCmd.Flags().StringVar(&fileFlags.HeaderTemplate, "header-template", "", "Markdown のヘッダのテンプレート (text/template 形式) のパス。未指定時は組み込みのヘッダを使用します")
+	addHTMLReportFlags(fileCmd)
+}
+
+// --------------------------------------------------------------------------
+// コマンドの実行ロジック
+// --------------------------------------------------------------------------
+
+// runFileCommand はコマンドの主要な実行ロジックを含みます。
+func runFileCommand(cmd *cobra.Command, args []string) (pipelineResult, error) {
+	ctx := cmd.Context()
+
+	if fileFlags.Output == "" {
+		return pipelineResult{}, fmt.Errorf(`required flag(s) "output" not set`)
+	}
+	format, err := fileFormat(fileFlags.Output, fileFlags.Format)
+	if err != nil {
+		return pipelineResult{}, err
+	}
+	// レビュー実行前にテンプレートと表示オプションの不備を検出する
+	header, err := mdreport.Load(fileFlags.HeaderTemplate)
+	if err != nil {
+		return pipelineResult{}, err
+	}
+	if format == fileFormatHTML {
+		if _, err := htmlReportOptions().Validate(); err != nil {
+			return pipelineResult{}, err
+		}
+	}
+
+	res, err := executeReviewPipeline(ctx, ReviewConfig)
+	if err != nil {
+		return res, err
+	}
+	reviewResult := res.markdown
+	if reviewResult == "" {
+		slog.Warn("レビュー結果の内容が空のため、ファイルへの保存をスキップします。", "path", fileFlags.Output)
+		return res, nil
+	}
+
+	path := gcsindex.Expand(fileFlags.Output, gcsindex.Vars{ReviewID: ReviewConfig.ReviewID, RepoURL: ReviewConfig.RepoURL}).Object
+	content, err := renderFileReport(format, header, localizeHeadings(cmd.Name(), reviewResult), res.model, time.Now())
+	if err != nil {
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		return res, err
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			printReviewResult(ReviewConfig.Destination, reviewResult)
+			return res, fmt.Errorf("保存先のディレクトリの作成に失敗しました (%s): %w", dir, err)
+		}
+	}
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		return res, fmt.Errorf("レビュー結果のファイルへの書き込みに失敗しました (%s): %w", path, err)
+	}
+	slog.Info("レビュー結果をファイルに保存しました。", "path", path, "format", format)
+	return res, nil
+}
+
+// --------------------------------------------------------------------------
+// ヘルパー関数
+// --------------------------------------------------------------------------
+
+// fileFormat は保存する形式を決定します。format が空の場合は path の拡張子から判別します。
+func fileFormat(path, format string) (string, error) {
+	switch strings.ToLower(format) {
+	case fileFormatMarkdown, "md":
+		return fileFormatMarkdown, nil
+	case fileFormatHTML:
+		return fileFormatHTML, nil
+	case "":
+	default:
+		return "", fmt.Errorf("不明な保存形式です: '%s' ('%s' または '%s' を指定してください)", format, fileFormatMarkdown, fileFormatHTML)
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return fileFormatHTML, nil
+	}
+	return fileFormatMarkdown, nil
+}
+
+// renderFileReport はレビュー結果を保存する形式に変換します。model はレビューで実際に応答したモデルです。
+func renderFileReport(format string, header *mdreport.Template, reviewResult, model string, generatedAt time.Time) ([]byte, error) {
+	if format == fileFormatHTML {
+		html, err := htmlreport.Render(htmlreport.ReportData{
+			Title:          localizeTitle(ReviewConfig.Destination, htmlreport.DefaultTitle),
+			RepoURL:        ReviewConfig.RepoURL,
+			BaseBranch:     ReviewConfig.BaseBranch,
+			FeatureBranch:  ReviewConfig.FeatureBranch,
+			Model:          model,
+			ReviewMarkdown: reviewResult,
+			GeneratedAt:    generatedAt,
+		}, htmlReportOptions())
+		if err != nil {
+			return nil, fmt.Errorf("HTML変換に失敗しました: %w", err)
+		}
+		return html, nil
+	}
+	report, err := header.Render(mdreport.Data{
+		RepoURL:       ReviewConfig.RepoURL,
+		BaseBranch:    ReviewConfig.BaseBranch,
+		FeatureBranch: ReviewConfig.FeatureBranch,
+		Model:         model,
+		Mode:          ReviewConfig.ReviewMode,
+		ReviewID:      ReviewConfig.ReviewID,
+		GeneratedAt:   generatedAt,
+	}, reviewResult)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(localizeHeadings(ReviewConfig.Destination, report)), nil
+}
@@ -1,11 +1,18 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"time"
 
-	"github.com/shouni/gemini-reviewer-core/pkg/publisher"
-	"github.com/shouni/go-remote-io/pkg/factory"
+	"git-gemini-reviewer-go/internal/aggregate"
+	"git-gemini-reviewer-go/internal/gcs"
+	"git-gemini-reviewer-go/internal/gcsindex"
+	"git-gemini-reviewer-go/internal/htmlreport"
+	"git-gemini-reviewer-go/internal/verdict"
 
 	"github.com/spf13/cobra"
 )
@@ -14,6 +21,10 @@ import (
 type GCSFlags struct {
 	GCSURI      string // GCSへ保存する際の宛先URI (例: gs://bucket/path/to/result.html)
 	ContentType string // GCSに保存する際のMIMEタイプ
+	Theme       string // HTMLレポートの配色テーマ
+	FontSize    int    // HTMLレポートの基準フォントサイズ (px)
+	Index       bool   // 保存先のプレフィックスにレビューの一覧ページを作成するかどうか
+	IndexLimit  int    // 一覧ページに掲載するレビューの最大件数
 }
 
 var gcsFlags GCSFlags
@@ -22,14 +33,30 @@ var gcsFlags GCSFlags
 var gcsCmd = &cobra.Command{
 	Use:   "gcs",
 	Short: "AIレビュー結果をスタイル付きHTMLに変換し、その結果を指定されたGCS URIに保存します。",
-	Long:  `このコマンドは、指定されたGitリポジトリのブランチ間の差分をAIでレビューし、その結果をさらにAIでスタイル付きHTMLに変換した後、go-remote-io を利用してGCSにアップロードします。`,
+	Long:  `このコマンドは、指定されたGitリポジトリのブランチ間の差分をAIでレビューし、その結果をテーマ付きのアクセシブルなHTMLに変換した後、go-remote-io を利用してGCSにアップロードします。`,
 	Args:  cobra.NoArgs,
-	RunE:  gcsCommand,
+	RunE:  withReviewGate(gcsCommand),
 }
 
 func init() {
 	gcsCmd.Flags().StringVarP(&gcsFlags.ContentType, "content-type", "t", "text/html; charset=utf-8", "GCSに保存する際のMIMEタイプ (デフォルトはHTML)")
-	gcsCmd.Flags().StringVarP(&gcsFlags.GCSURI, "gcs-uri", "s", "gs://git-gemini-reviewer-go/review/result.html", "GCSの保存先")
+	gcsCmd.Flags().StringVarP(&gcsFlags.GCSURI, "gcs-uri", "s", "gs://git-gemini-reviewer-go/review/result.html", "GCSの保存先。'{review_id}' と '{repo}' はレビューIDとリポジトリ名に置き換わります (例: gs://bucket/reviews/{repo}/{review_id}.html)")
+	addHTMLReportFlags(gcsCmd)
+	addGCSIndexFlags(gcsCmd)
+}
+
+// addGCSIndexFlags は、GCS に作成するレビューの一覧ページに関するフラグをコマンドに追加します。
+// gcs コマンドと post コマンドの gcs 配信で共通の設定を使用します。
+func addGCSIndexFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&gcsFlags.Index, "gcs-index", true, "保存先のプレフィックス ('{review_id}' を含む最初のディレクトリの手前) に、レビューの一覧ページ (index.html) とマニフェスト (index.json) を作成・更新します。")
+	cmd.Flags().IntVar(&gcsFlags.IndexLimit, "gcs-index-limit", gcsindex.DefaultLimit, "一覧ページに掲載するレビューの最大件数 (新しい順)。0 は無制限です。")
+}
+
+// addHTMLReportFlags は、HTMLレポートの表示オプションに関するフラグをコマンドに追加します。
+// gcs コマンドと post コマンドの gcs 配信で共通の設定を使用します。
+func addHTMLReportFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&gcsFlags.Theme, "html-theme", htmlreport.DefaultTheme, fmt.Sprintf("HTMLレポートの配色テーマ %v", htmlreport.Themes()))
+	cmd.Flags().IntVar(&gcsFlags.FontSize, "html-font-size", htmlreport.DefaultFontSize, "HTMLレポートの基準フォントサイズ (px)")
 }
 
 // --------------------------------------------------------------------------
@@ -37,41 +64,133 @@ func init() {
 // --------------------------------------------------------------------------
 
 // gcsCommand は gcs コマンドの実行ロジックです。
-func gcsCommand(cmd *cobra.Command, args []string) error {
+func gcsCommand(cmd *cobra.Command, args []string) (pipelineResult, error) {
 	ctx := cmd.Context()
 	gcsURI := gcsFlags.GCSURI
 
+	// レビュー実行前に表示オプションの不備を検出する
+	if _, err := htmlReportOptions().Validate(); err != nil {
+		return pipelineResult{}, err
+	}
+
 	// 1. レビューパイプラインを実行
-	reviewResult, err := executeReviewPipeline(ctx, ReviewConfig)
+	res, err := executeReviewPipeline(ctx, ReviewConfig)
 	if err != nil {
-		return err
+		return res, err
 	}
+	reviewResult := res.markdown
 
 	if reviewResult == "" {
 		slog.Warn("レビュー結果の内容が空のため、GCSへの保存をスキップします。", "uri", gcsURI)
-		return nil
+		return res, nil
 	}
 
 	// 2. GCSへの結果保存
-	ioFactory, err := factory.NewClientFactory(ctx)
+	uri, err := publishToGCS(ctx, gcsURI, reviewResult)
 	if err != nil {
-		return fmt.Errorf("クライアントファクトリの初期化に失敗しました: %w", err)
+		return res, err
 	}
-	writer, err := publisher.NewGCSPublisher(ioFactory)
+	slog.Info("GCSへのアップロードが完了しました。", "uri", uri)
+
+	return res, nil
+}
+
+// htmlReportOptions は、フラグで指定されたHTMLレポートの表示オプションを返します。
+func htmlReportOptions() htmlreport.Options {
+	return htmlreport.Options{Theme: gcsFlags.Theme, FontSize: gcsFlags.FontSize, Lang: reviewLanguage(ReviewConfig)}
+}
+
+// publishToGCS は、レビュー結果をHTMLに変換して指定されたGCS URIに保存し、プレースホルダを展開した保存先のURIを返します。
+// gcsFlags.Index が有効な場合は、保存先のプレフィックスの一覧ページも更新します。一覧の更新の失敗は縮退として扱います。
+func publishToGCS(ctx context.Context, gcsURI, reviewResult string) (string, error) {
+	bucketName, objectPath, err := gcs.ParseURI(gcsURI)
 	if err != nil {
-		return fmt.Errorf("GCSパブリッシャーの初期化に失敗しました: %w", err)
+		return "", err
 	}
-	meta := publisher.ReviewData{
+	layout := gcsindex.Expand(objectPath, gcsindex.Vars{ReviewID: ReviewConfig.ReviewID, RepoURL: ReviewConfig.RepoURL})
+	uri := fmt.Sprintf("gs://%s/%s", bucketName, layout.Object)
+
+	generatedAt := time.Now()
+	html, err := htmlreport.Render(htmlreport.ReportData{
+		Title:          localizeTitle("gcs", htmlreport.DefaultTitle),
 		RepoURL:        ReviewConfig.RepoURL,
 		BaseBranch:     ReviewConfig.BaseBranch,
 		FeatureBranch:  ReviewConfig.FeatureBranch,
-		ReviewMarkdown: reviewResult,
+		ReviewMarkdown: localizeHeadings("gcs", reviewResult),
+		GeneratedAt:    generatedAt,
+	}, htmlReportOptions())
+	if err != nil {
+		return "", fmt.Errorf("HTML変換に失敗しました: %w", err)
 	}
-	err = writer.Publish(ctx, gcsFlags.GCSURI, meta)
+
+	writer, err := gcs.NewWriter(ctx)
 	if err != nil {
-		return fmt.Errorf("GCSへの書き込みに失敗しました (URI: %s): %w", gcsFlags.GCSURI, err)
+		return "", err
+	}
+	slog.Info("GCSへアップロード開始", "bucketName", bucketName, "objectPath", layout.Object, "theme", gcsFlags.Theme)
+	if err := writer.WriteToGCS(ctx, bucketName, layout.Object, bytes.NewReader(html), gcsFlags.ContentType); err != nil {
+		return "", fmt.Errorf("GCSへの書き込みに失敗しました (URI: %s): %w", uri, err)
 	}
-	slog.Info("GCSへのアップロードが完了しました。", "uri", gcsFlags.GCSURI)
 
+	if gcsFlags.Index {
+		entry := gcsindex.Entry{
+			Object:        layout.Relative(),
+			ReviewID:      ReviewConfig.ReviewID,
+			RepoURL:       ReviewConfig.RepoURL,
+			BaseBranch:    ReviewConfig.BaseBranch,
+			FeatureBranch: ReviewConfig.FeatureBranch,
+			Verdict:       verdict.Parse(reviewResult),
+			GeneratedAt:   generatedAt,
+		}
+		if err := updateGCSIndex(ctx, writer, bucketName, layout.Prefix, entry); err != nil {
+			aggregate.FromContext(ctx).Degrade("gcs.index", fmt.Errorf("レビューの一覧ページの更新に失敗しました (gs://%s/%s%s): %w", bucketName, layout.Prefix, gcsindex.PageName, err))
+		}
+	}
+	return uri, nil
+}
+
+// updateGCSIndex は、プレフィックスのマニフェストにレビューを追加し、マニフェストと一覧ページを保存します。
+// マニフェストが存在しない場合は新規に作成します。読み込みに失敗した場合は、既存の一覧を失わないよう更新しません。
+func updateGCSIndex(ctx context.Context, writer gcs.Writer, bucketName, prefix string, entry gcsindex.Entry) error {
+	reader, err := gcs.NewReader(ctx)
+	if err != nil {
+		return err
+	}
+	manifestURI := fmt.Sprintf("gs://%s/%s%s", bucketName, prefix, gcsindex.ManifestName)
+	var data []byte
+	rc, err := reader.Open(ctx, manifestURI)
+	switch {
+	case gcs.IsNotExist(err):
+		slog.Info("レビューの一覧を新規に作成します。", "uri", manifestURI)
+	case err != nil:
+		return fmt.Errorf("マニフェストの読み込みに失敗しました: %w", err)
+	default:
+		data, err = io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return fmt.Errorf("マニフェストの読み込みに失敗しました: %w", err)
+		}
+	}
+	manifest, err := gcsindex.Parse(data)
+	if err != nil {
+		return err
+	}
+	manifest.Add(entry, gcsFlags.IndexLimit)
+
+	manifestJSON, err := manifest.JSON()
+	if err != nil {
+		return err
+	}
+	page, err := manifest.HTML(htmlReportOptions())
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteToGCS(ctx, bucketName, prefix+gcsindex.ManifestName, bytes.NewReader(manifestJSON), "application/json"); err != nil {
+		return fmt.Errorf("マニフェストの書き込みに失敗しました: %w", err)
+	}
+	if err := writer.WriteToGCS(ctx, bucketName, prefix+gcsindex.PageName, bytes.NewReader(page), "text/html; charset=utf-8"); err != nil {
+		return fmt.Errorf("一覧ページの書き込みに失敗しました: %w", err)
+	}
+	slog.Info("レビューの一覧ページを更新しました。", "uri", fmt.Sprintf("gs://%s/%s%s", bucketName, prefix, gcsindex.PageName), "entries", len(manifest.Entries))
 	return nil
 }
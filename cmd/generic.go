@@ -1,8 +1,19 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/inline"
+	"git-gemini-reviewer-go/internal/sarif"
+	"git-gemini-reviewer-go/internal/streamai"
 
 	"github.com/spf13/cobra"
 )
@@ -11,35 +22,167 @@ import (
 var genericCmd = &cobra.Command{
 	Use:   "generic",
 	Short: "コードレビューを実行し、その結果を標準出力に出力します。",
-	Long:  `このコマンドは、指定されたGitリポジトリのブランチ間の差分をAIでレビューし、その結果を標準出力に直接表示します。Backlogなどの外部サービスとの連携は行いません。`,
-	RunE: func(cmd *cobra.Command, args []string) error {
+	Long: `このコマンドは、指定されたGitリポジトリのブランチ間の差分をAIでレビューし、その結果を標準出力に直接表示します。Backlogなどの外部サービスとの連携は行いません。
+--stream を指定すると、AI の応答を生成と同時に出力します。Ctrl-C で途中で中断できます。
+--format json を指定すると、AI に構造化された指摘 (ファイル・行の範囲・重大度・カテゴリ・修正案) を出力させ、
+スキーマを満たしていることを検証したうえで JSON として出力します。形式は 'schema findings' で確認できます。
+--format sarif を指定すると、同じ指摘を SARIF 2.1.0 として出力します。GitHub Code Scanning などにアップロードできます。`,
+	RunE: withReviewGate(runGenericCommand),
+}
 
-		// 1. パイプラインを実行し、結果を受け取る
-		reviewResult, err := executeReviewPipeline(cmd.Context(), ReviewConfig)
-		if err != nil {
-			return err
-		}
+const (
+	formatMarkdown = "markdown"
+	formatJSON     = "json"
+	formatSARIF    = "sarif"
+)
 
-		// 2. レビュー結果の出力 (generic 固有の処理)
-		// ユーザーの提案に基づき、レビュー結果の内容が空でない場合にのみ標準出力に出力する
-		if reviewResult != "" {
-			printReviewResult(reviewResult)
-			slog.Info("レビュー結果を標準出力に出力しました。")
-		} else {
-			slog.Info("レビュー結果の内容が空のため、標準出力への出力はスキップしました。")
-		}
+// genericFormat は generic コマンドの出力形式です。
+var genericFormat string
+
+func init() {
+	genericCmd.Flags().BoolVar(&ReviewConfig.Stream, "stream", false, "AI の応答を生成と同時に標準出力に出力します (Ctrl-C で中断)。出力は AI の応答そのもので、見出しの翻訳やフックによる加工は反映されません")
+	genericCmd.Flags().StringVar(&genericFormat, "format", formatMarkdown, "出力形式: 'markdown'、'json' (構造化された指摘。形式は 'schema findings' を参照) または 'sarif' (SARIF 2.1.0)")
+}
+
+// runGenericCommand はコマンドの主要な実行ロジックを含みます。
+func runGenericCommand(cmd *cobra.Command, args []string) (pipelineResult, error) {
+	if err := applyGenericFormat(); err != nil {
+		return pipelineResult{}, err
+	}
+	if genericFormat == formatJSON || genericFormat == formatSARIF {
+		return runGenericStructured(cmd.Context(), cmd.OutOrStdout())
+	}
+	if ReviewConfig.Stream {
+		return runGenericStream(cmd.Context())
+	}
+
+	// 1. パイプラインを実行し、結果を受け取る
+	res, err := executeReviewPipeline(cmd.Context(), ReviewConfig)
+	if err != nil {
+		return res, err
+	}
+
+	// 2. レビュー結果の出力 (generic 固有の処理)
+	// ユーザーの提案に基づき、レビュー結果の内容が空でない場合にのみ標準出力に出力する
+	if res.markdown != "" {
+		printReviewResult(ReviewConfig.Destination, res.markdown)
+		slog.Info("レビュー結果を標準出力に出力しました。")
+	} else {
+		slog.Info("レビュー結果の内容が空のため、標準出力への出力はスキップしました。")
+	}
+
+	return res, nil
+}
 
+// applyGenericFormat は --format を検証し、'json' と 'sarif' の場合は構造化された指摘を出力させる設定を有効にします。
+func applyGenericFormat() error {
+	genericFormat = strings.ToLower(genericFormat)
+	switch genericFormat {
+	case formatMarkdown:
 		return nil
-	},
+	case formatJSON, formatSARIF:
+	default:
+		return fmt.Errorf("不明な出力形式です: '%s' ('%s'、'%s' または '%s' を指定してください)", genericFormat, formatMarkdown, formatJSON, formatSARIF)
+	}
+	if ReviewConfig.Stream {
+		return fmt.Errorf("--stream と --format %s は同時に指定できません", genericFormat)
+	}
+	if ReviewConfig.SplitModules {
+		return fmt.Errorf("--split-modules と --format %s は同時に指定できません", genericFormat)
+	}
+	// スキーマを満たさない応答は、欠けた値を含む JSON を出力せずにレビューの失敗とする
+	ReviewConfig.InlineFindings = true
+	ReviewConfig.StrictFindings = true
+	return nil
 }
 
-func init() {
+// runGenericStructured は、構造化されたレビュー結果を --format の形式 (JSON または SARIF) で w に出力します。
+// 差分がない場合やスキップされた場合も、指摘のない文書を出力します。
+func runGenericStructured(ctx context.Context, w io.Writer) (pipelineResult, error) {
+	res, err := executeReviewPipeline(ctx, ReviewConfig)
+	if err != nil {
+		return res, err
+	}
+	review := inline.Review{}
+	if res.inline != nil {
+		review = *res.inline
+	}
+	document := review.Document(ReviewConfig.ReviewID)
+	if len(res.diffStats.Files) > 0 {
+		report := res.diffStats.Report()
+		document.DiffStats = &report
+	}
+	if res.usage.Requests > 0 {
+		usage := res.usage
+		document.Usage = &usage
+	}
+	var doc any = document
+	if genericFormat == formatSARIF {
+		doc = sarif.Convert(review, ReviewConfig.ReviewID)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return res, fmt.Errorf("レビュー結果の %s の出力に失敗しました: %w", strings.ToUpper(genericFormat), err)
+	}
+	slog.Info("構造化されたレビュー結果を標準出力に出力しました。", "findings", len(review.Findings))
+	return res, nil
+}
+
+// runGenericStream は、AI の応答を生成と同時に標準出力に出力しながらレビューを実行します。
+// Ctrl-C (SIGINT) を受け取るとリクエストを取り消し、クリーンアップなどの後処理を行って終了します。
+func runGenericStream(parent context.Context) (pipelineResult, error) {
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt)
+	defer stop()
+
+	out := &streamOutput{w: os.Stdout}
+	res, err := executeReviewPipeline(streamai.WithWriter(ctx, out), ReviewConfig)
+	if out.started {
+		out.finish()
+	}
+	if err != nil {
+		if errors.Is(ctx.Err(), context.Canceled) && parent.Err() == nil {
+			return res, fmt.Errorf("レビューを中断しました: %w", err)
+		}
+		return res, err
+	}
+
+	// スタブの AI や定型メッセージなど、逐次出力されなかった結果はまとめて出力する
+	switch {
+	case out.started:
+		slog.Info("レビュー結果を標準出力に逐次出力しました。")
+	case res.markdown != "":
+		printReviewResult(ReviewConfig.Destination, res.markdown)
+		slog.Info("レビュー結果を標準出力に出力しました。")
+	default:
+		slog.Info("レビュー結果の内容が空のため、標準出力への出力はスキップしました。")
+	}
+	return res, nil
+}
+
+// streamOutput は、最初の書き出しの前に printReviewResult と同じ見出しを出力する io.Writer です。
+type streamOutput struct {
+	w       io.Writer
+	started bool
+}
+
+func (o *streamOutput) Write(p []byte) (int, error) {
+	if !o.started {
+		o.started = true
+		fmt.Fprintln(o.w, "\n--- Gemini AI レビュー結果 ---")
+	}
+	return o.w.Write(p)
+}
+
+// finish は逐次出力の末尾に区切り線を出力します。
+func (o *streamOutput) finish() {
+	fmt.Fprintln(o.w, "\n-----------------------------------------------------")
 }
 
-// printReviewResult は noPost 時に結果を標準出力します。
-func printReviewResult(result string) {
+// printReviewResult は noPost 時に結果を標準出力します。見出しは destination の投稿先の言語に翻訳します。
+func printReviewResult(destination, result string) {
 	// 標準出力 (fmt.Println) は維持
 	fmt.Println("\n--- Gemini AI レビュー結果 ---")
-	fmt.Println(result)
+	fmt.Println(localizeHeadings(destination, result))
 	fmt.Println("-----------------------------------------------------")
 }
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"git-gemini-reviewer-go/internal/feedback"
+	"git-gemini-reviewer-go/internal/gerrit"
+	"git-gemini-reviewer-go/internal/msgfit"
+	"git-gemini-reviewer-go/internal/pkg/retry"
+	"git-gemini-reviewer-go/internal/verdict"
+
+	"github.com/spf13/cobra"
+)
+
+// --- 構造体: Gerrit認証情報 ---
+
+// gerritAuthInfo は、Gerrit へのレビュー投稿に必要な認証情報をカプセル化します。
+type gerritAuthInfo struct {
+	BaseURL  string
+	Username string
+	Password string
+}
+
+// --- コマンド固有のフラグ変数 ---
+var (
+	noPostGerrit bool
+	noVoteGerrit bool
+)
+
+// gerritCmd は、Gerrit の変更をレビューし、その結果を Gerrit のレビューコメントとして投稿するコマンドです。
+var gerritCmd = &cobra.Command{
+	Use:   "gerrit",
+	Short: "Gerritの変更 (パッチセット) をレビューし、その結果をCode-Reviewの投票付きでGerritに投稿します。",
+	Long: `このコマンドは、--change で指定した Gerrit の変更のパッチセット (refs/changes/xx/yyyy/z) をフェッチし、--base-branch との差分をAIでレビューします。
+レビュー結果はパッチセットへのレビューコメントとして投稿され、判定に応じて Code-Review に投票します (リリース不可: -1、リリース可: +1、それ以外: 0)。`,
+	Args: cobra.NoArgs,
+	RunE: withReviewGate(runGerritCommand),
+}
+
+func init() {
+	gerritCmd.Flags().StringVar(&ReviewConfig.GerritChange, "change", "", "レビューする Gerrit の変更とパッチセット (例: 12345/3)。指定時は --feature-branch は不要です。")
+	gerritCmd.Flags().BoolVar(&noPostGerrit, "no-post", false, "投稿をスキップし、結果を標準出力する")
+	gerritCmd.Flags().BoolVar(&noVoteGerrit, "no-vote", false, "レビューコメントのみを投稿し、Code-Review には投票しない")
+	_ = gerritCmd.MarkFlagRequired("change")
+}
+
+// --------------------------------------------------------------------------
+// コマンドの実行ロジック
+// --------------------------------------------------------------------------
+
+// runGerritCommand はコマンドの主要な実行ロジックを含みます。
+func runGerritCommand(cmd *cobra.Command, args []string) (pipelineResult, error) {
+	ctx := cmd.Context()
+
+	change, err := gerrit.ParseChange(ReviewConfig.GerritChange)
+	if err != nil {
+		return pipelineResult{}, err
+	}
+
+	// 1. 環境変数の確認 (no-post の場合は不要)
+	authInfo := getGerritAuthInfo()
+	if !noPostGerrit && (authInfo.BaseURL == "" || authInfo.Username == "" || authInfo.Password == "") {
+		return pipelineResult{}, fmt.Errorf("Gerrit連携には環境変数 GERRIT_URL, GERRIT_USERNAME および GERRIT_HTTP_PASSWORD が必須です")
+	}
+
+	// 2. パイプラインを実行し、結果を受け取る
+	res, err := executeReviewPipeline(ctx, ReviewConfig)
+	if err != nil {
+		return res, err
+	}
+	reviewResult := res.markdown
+	if reviewResult == "" {
+		slog.Warn("レビュー結果の内容が空のため、Gerritへの投稿をスキップします。")
+		return res, nil
+	}
+
+	// 3. no-post フラグによる出力分岐
+	if noPostGerrit {
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		return res, nil
+	}
+
+	// 4. Gerrit投稿を実行
+	permalink, err := postToGerrit(ctx, authInfo, change, reviewResult)
+	if err != nil {
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		return res, fmt.Errorf("Gerrit の変更 %d/%d へのレビュー投稿に失敗しました: %w", change.Number, change.Patchset, err)
+	}
+
+	slog.Info("レビュー結果を Gerrit に投稿しました。", "url", permalink)
+	return res, nil
+}
+
+// --------------------------------------------------------------------------
+// ヘルパー関数
+// --------------------------------------------------------------------------
+
+// getGerritAuthInfo は、環境変数から Gerrit 認証情報を取得します。
+func getGerritAuthInfo() gerritAuthInfo {
+	return gerritAuthInfo{
+		BaseURL:  os.Getenv("GERRIT_URL"),
+		Username: os.Getenv("GERRIT_USERNAME"),
+		Password: os.Getenv("GERRIT_HTTP_PASSWORD"),
+	}
+}
+
+// applyGerritChange は、Gerrit の変更が指定されている場合に、そのパッチセットをレビュー対象のブランチとして設定します。
+func applyGerritChange() error {
+	if ReviewConfig.GerritChange == "" {
+		return nil
+	}
+	change, err := gerrit.ParseChange(ReviewConfig.GerritChange)
+	if err != nil {
+		return err
+	}
+	ReviewConfig.FeatureBranch = change.Branch()
+	return nil
+}
+
+// gerritTruncatedNote は、メッセージの上限を超えて省略したレビュー結果の末尾に付ける注記です。
+const gerritTruncatedNote = "…(Gerrit のメッセージの上限を超えるため、以降を省略しました)"
+
+// postToGerrit は、判定に応じた Code-Review の投票とともにレビューコメントを投稿し、パッチセットのURLを返します。
+func postToGerrit(ctx context.Context, authInfo gerritAuthInfo, change gerrit.Change, reviewResult string) (string, error) {
+	client := gerrit.NewClient(newHTTPClient(), authInfo.BaseURL, authInfo.Username, authInfo.Password)
+
+	var vote *int
+	if !noVoteGerrit {
+		v := verdict.Parse(reviewResult)
+		score := gerrit.Vote(v)
+		vote = &score
+		slog.Info("判定に基づいて Code-Review に投票します。", "verdict", v.Label(), "vote", score)
+	}
+
+	// 投票は1件のレビューメッセージに紐付くため、分割せずに上限に収まるよう要約する
+	footer := feedback.Footer(ReviewConfig.FeedbackURL, ReviewConfig.FeedbackSecret, ReviewConfig.ReviewID)
+	message := msgfit.Summarize(localizeHeadings("gerrit", reviewResult), msgfit.Gerrit.Minus(footer), gerritTruncatedNote) + footer
+
+	var permalink string
+	err := retry.Do(ctx, "gerrit.set_review", func(ctx context.Context) error {
+		var err error
+		permalink, err = client.PostReview(ctx, change, message, vote)
+		return err
+	}, retry.WithBudget(notifyRetryBudget))
+	return permalink, err
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/aggregate"
+	"git-gemini-reviewer-go/internal/ghaction"
+	"git-gemini-reviewer-go/internal/inline"
+	"git-gemini-reviewer-go/internal/verdict"
+
+	"github.com/spf13/cobra"
+)
+
+// githubAction は、github コマンドを GitHub Actions のステップとして実行するかを表します。
+var githubAction bool
+
+// githubActionTokenUser は、GITHUB_TOKEN で HTTPS のクローンを行う際のユーザー名です。
+const githubActionTokenUser = "x-access-token"
+
+// applyGitHubAction は、GitHub Actions のステップとして実行する場合に、イベントのペイロードからレビュー対象を設定します。
+// 明示的に指定されたフラグの値は上書きしません。
+func applyGitHubAction(cmd *cobra.Command) error {
+	if !githubAction {
+		return nil
+	}
+	event, err := ghaction.LoadEvent()
+	if err != nil {
+		return err
+	}
+	pr := event.PullRequest
+
+	if githubPullRequest == 0 {
+		githubPullRequest = pr.Number
+	}
+	if githubRepo == "" {
+		githubRepo = event.Repository.FullName
+	}
+	if ReviewConfig.RepoURL == "" {
+		ReviewConfig.RepoURL = event.RepoURL()
+	}
+	if !cmd.Flags().Changed("base-branch") && pr.Base.Ref != "" {
+		ReviewConfig.BaseBranch = pr.Base.Ref
+	}
+	// フォークからのプルリクエストも取得できるよう、ブランチではなく refs/pull/ 配下の参照をレビューする
+	if ReviewConfig.FeatureBranch == "" && ReviewConfig.FeatureRev == "" {
+		ReviewConfig.FeatureBranch = ghaction.PullBranch(githubPullRequest)
+		ReviewConfig.GitHubPullRequest = githubPullRequest
+	}
+	if ReviewConfig.GitHTTPToken == "" {
+		ReviewConfig.GitHTTPToken = os.Getenv("GITHUB_TOKEN")
+		if ReviewConfig.GitHTTPUsername == "" {
+			ReviewConfig.GitHTTPUsername = githubActionTokenUser
+		}
+	}
+	if len(ReviewConfig.PRLabels) == 0 {
+		ReviewConfig.PRLabels = pr.LabelNames()
+	}
+
+	slog.Info("GitHub Actions のイベントからレビュー対象を設定しました。",
+		"repo", githubRepo, "pr", githubPullRequest, "base_branch", ReviewConfig.BaseBranch, "feature_branch", ReviewConfig.FeatureBranch)
+	return nil
+}
+
+// reportGitHubAction は、レビュー結果をジョブの概要・注釈・ステップの出力として書き出します。
+// 書き出しに失敗しても投稿は継続し、縮退した処理として記録します。
+func reportGitHubAction(ctx context.Context, res pipelineResult, permalink string) {
+	reviewResult, structured := res.markdown, res.inline
+	v := res.gate.verdict
+	if v == "" {
+		v = verdict.Unknown
+	}
+	score := ghaction.RiskScore(v, structured, reviewResult)
+	annotations := ghaction.Annotations(structured, reviewResult)
+
+	if err := ghaction.WriteAnnotations(os.Stdout, annotations); err != nil {
+		aggregate.FromContext(ctx).Degrade("github.action", err)
+	}
+	if err := ghaction.AppendSummary(githubActionSummary(v, score, reviewResult, structured, permalink)); err != nil {
+		aggregate.FromContext(ctx).Degrade("github.action", fmt.Errorf("ジョブの概要の書き込みに失敗しました: %w", err))
+	}
+	err := ghaction.SetOutputs(map[string]string{
+		"verdict":    string(v),
+		"risk-score": strconv.Itoa(score),
+		"findings":   strconv.Itoa(len(annotations)),
+		"review-id":  ReviewConfig.ReviewID,
+		"review-url": permalink,
+	})
+	if err != nil {
+		aggregate.FromContext(ctx).Degrade("github.action", fmt.Errorf("ステップの出力の書き込みに失敗しました: %w", err))
+	}
+	slog.Info("GitHub Actions にレビュー結果を出力しました。", "verdict", v, "risk_score", score, "annotations", len(annotations))
+}
+
+// githubActionSummary は、ジョブの概要に表示する Markdown を組み立てます。
+func githubActionSummary(v verdict.Verdict, score int, reviewResult string, structured *inline.Review, permalink string) string {
+	body := reviewResult
+	if structured != nil {
+		body = structured.Body()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "## AI コードレビュー (%s#%d)\n\n", githubRepo, githubPullRequest)
+	fmt.Fprintf(&b, "| 判定 | リスクスコア |\n| --- | --- |\n| %s | %d / 100 |\n\n", v.Label(), score)
+	if permalink != "" {
+		fmt.Fprintf(&b, "[プルリクエストのレビュー](%s)\n\n", permalink)
+	}
+	b.WriteString(localizeHeadings("github", body))
+	return b.String()
+}
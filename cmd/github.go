@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"git-gemini-reviewer-go/internal/builder"
+	"git-gemini-reviewer-go/internal/feedback"
+	"git-gemini-reviewer-go/internal/github"
+	"git-gemini-reviewer-go/internal/inline"
+	"git-gemini-reviewer-go/internal/msgfit"
+	"git-gemini-reviewer-go/internal/pkg/retry"
+
+	"github.com/spf13/cobra"
+)
+
+// --- コマンド固有のフラグ変数 ---
+var (
+	githubPullRequest int
+	githubRepo        string
+	noPostGitHub      bool
+)
+
+// githubCmd は、GitHub のプルリクエストをレビューし、その結果をプルリクエストのレビューとして投稿するコマンドです。
+var githubCmd = &cobra.Command{
+	Use:   "github",
+	Short: "ブランチ差分をレビューし、その結果を GitHub のプルリクエストにレビューとして投稿します。",
+	Long: `このコマンドは、ブランチ間の差分をAIでレビューし、--pr で指定した GitHub のプルリクエストにレビューとして投稿します。
+--inline を指定すると、AI に行単位の構造化された指摘 (ファイル・行・重大度・内容) を出力させ、差分の行に紐付けたインラインコメントとして投稿します。
+差分の範囲外の行への指摘は、レビュー本文にまとめて記載します。
+環境変数 GITHUB_TOKEN (pull_requests: write 権限) が必要です。GitHub Enterprise Server の場合は GITHUB_API_URL も指定してください。
+--github-action を指定すると、GitHub Actions のイベントのペイロードからレビュー対象のプルリクエストを読み取り、
+レビュー結果をジョブの概要、指摘の注釈 (::error など)、ステップの出力 (verdict、risk-score など) として書き出します。`,
+	Args: cobra.NoArgs,
+	RunE: withReviewGate(runGitHubCommand),
+}
+
+func init() {
+	githubCmd.Flags().IntVar(&githubPullRequest, "pr", 0, "レビューを投稿する GitHub のプルリクエスト番号 (例: 42)")
+	githubCmd.Flags().StringVar(&githubRepo, "github-repo", "", "投稿先のリポジトリ ('owner/name')。未指定時は --repo-url から判別します")
+	githubCmd.Flags().BoolVar(&ReviewConfig.InlineFindings, "inline", false, "行単位の構造化された指摘を、差分の行に紐付けたインラインコメントとして投稿します")
+	githubCmd.Flags().BoolVar(&noPostGitHub, "no-post", false, "投稿をスキップし、結果を標準出力する")
+	githubCmd.Flags().BoolVar(&githubAction, "github-action", false, "GitHub Actions のステップとして実行します。イベントからプルリクエスト・リポジトリ・ブランチを読み取り、ジョブの概要・注釈・ステップの出力を書き出します")
+}
+
+// --------------------------------------------------------------------------
+// コマンドの実行ロジック
+// --------------------------------------------------------------------------
+
+// runGitHubCommand はコマンドの主要な実行ロジックを含みます。
+func runGitHubCommand(cmd *cobra.Command, args []string) (pipelineResult, error) {
+	ctx := cmd.Context()
+
+	if ReviewConfig.InlineFindings && ReviewConfig.SplitModules {
+		return pipelineResult{}, fmt.Errorf("--inline と --split-modules は同時に指定できません")
+	}
+	if githubPullRequest == 0 {
+		return pipelineResult{}, fmt.Errorf(`required flag(s) "pr" not set`)
+	}
+	repoSpec := githubRepo
+	if repoSpec == "" {
+		repoSpec = ReviewConfig.RepoURL
+	}
+	repo, err := github.ParseRepo(repoSpec)
+	if err != nil {
+		return pipelineResult{}, err
+	}
+
+	// 1. 環境変数の確認 (no-post の場合は不要)
+	token := os.Getenv("GITHUB_TOKEN")
+	if !noPostGitHub && token == "" {
+		return pipelineResult{}, fmt.Errorf("GitHub連携には環境変数 GITHUB_TOKEN が必須です")
+	}
+
+	// 2. パイプラインを実行し、結果を受け取る
+	res, err := executeReviewPipeline(ctx, ReviewConfig)
+	if err != nil {
+		return res, err
+	}
+	reviewResult := res.markdown
+	if reviewResult == "" {
+		slog.Warn("レビュー結果の内容が空のため、GitHubへの投稿をスキップします。")
+		return res, nil
+	}
+
+	// 3. no-post フラグによる出力分岐
+	if noPostGitHub {
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		if githubAction {
+			reportGitHubAction(ctx, res, "")
+		}
+		return res, nil
+	}
+
+	// 4. GitHub投稿を実行
+	permalink, err := postToGitHub(ctx, token, repo, githubPullRequest, ReviewConfig.ReviewID, reviewResult, res.inline)
+	if githubAction {
+		reportGitHubAction(ctx, res, permalink)
+	}
+	if err != nil {
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		return res, fmt.Errorf("GitHub のプルリクエスト %s#%d へのレビュー投稿に失敗しました: %w", repo, githubPullRequest, err)
+	}
+
+	slog.Info("レビュー結果を GitHub に投稿しました。", "url", permalink)
+	return res, nil
+}
+
+// --------------------------------------------------------------------------
+// ヘルパー関数
+// --------------------------------------------------------------------------
+
+// postToGitHub は、レビュー結果をプルリクエストの最新のコミットに対するレビューとして投稿し、レビューのURLを返します。
+// 構造化された指摘がある場合は、差分の行に紐付く指摘をインラインコメントとして添付します。
+func postToGitHub(ctx context.Context, token string, repo github.Repo, number int, reviewID, reviewResult string, structured *inline.Review) (string, error) {
+	client := builder.GitHubClient(ctx, newHTTPClient(), os.Getenv("GITHUB_API_URL"), token)
+
+	input := github.ReviewInput{Event: github.EventComment, Body: reviewResult}
+	if structured != nil {
+		input.Body = structured.Body()
+		for _, f := range structured.Inline() {
+			input.Comments = append(input.Comments, github.ReviewComment{Path: f.File, Line: f.Line, Side: "RIGHT", Body: f.Comment()})
+		}
+		slog.Info("インラインコメントを添付します。", "inline", len(input.Comments), "findings", len(structured.Findings))
+	}
+	input.Body = localizeHeadings("github", input.Body) + feedback.Footer(ReviewConfig.FeedbackURL, ReviewConfig.FeedbackSecret, reviewID)
+
+	// レビュー本文の上限を超える場合は、インラインコメントとともに先頭を投稿し、続きを会話のコメントとして投稿する
+	parts := msgfit.Split(input.Body, msgfit.GitHub)
+	input.Body = parts[0]
+
+	var permalink string
+	err := retry.Do(ctx, "github.create_review", func(ctx context.Context) error {
+		pr, err := client.GetPullRequest(ctx, repo, number)
+		if err != nil {
+			return err
+		}
+		// インラインコメントの行番号は、レビューした時点の最新のコミットに対して解釈されます
+		input.CommitID = pr.Head.SHA
+		permalink, err = client.CreateReview(ctx, repo, number, input)
+		return err
+	}, retry.WithBudget(notifyRetryBudget))
+	if err != nil {
+		return "", err
+	}
+
+	for i, part := range parts[1:] {
+		err := retry.Do(ctx, "github.create_issue_comment", func(ctx context.Context) error {
+			_, err := client.CreateIssueComment(ctx, repo, number, part)
+			return err
+		}, retry.WithBudget(notifyRetryBudget))
+		if err != nil {
+			return permalink, fmt.Errorf("分割したメッセージ (%d/%d) の投稿に失敗しました: %w", i+2, len(parts), err)
+		}
+	}
+	return permalink, nil
+}
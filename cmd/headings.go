@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"log/slog"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/headings"
+	"git-gemini-reviewer-go/internal/messages"
+
+	"github.com/spf13/cobra"
+)
+
+// headingLocalizer は --heading-lang と --heading-translations から構築した、投稿先ごとの見出しの翻訳です。
+var headingLocalizer *headings.Localizer
+
+// initHeadingLocalizer は、設定から見出しの翻訳を構築します。
+// --review-language を指定した場合は、--heading-lang ですべての投稿先の言語を指定していなければ、見出しもその言語にします。
+func initHeadingLocalizer(cfg config.ReviewConfig) error {
+	l, err := headings.New(cfg.HeadingLangs, cfg.HeadingTranslations)
+	if err != nil {
+		return err
+	}
+	if lang := reviewLanguage(cfg); lang != headings.DefaultLang {
+		if err := l.SetDefaultLang(lang); err != nil {
+			slog.Warn("レビュー結果の言語の見出しの辞書がないため、見出しは日本語のまま投稿します。", "review_language", lang, "error", err)
+		}
+	}
+	headingLocalizer = l
+	return nil
+}
+
+// applyReviewLanguage は、--message-lang を指定していない場合に、定型メッセージの言語を --review-language に合わせます。
+// 組み込みのテンプレートがない言語の場合は既定の言語のままにします。
+func applyReviewLanguage(cmd *cobra.Command) {
+	lang := reviewLanguage(ReviewConfig)
+	if cmd.Flags().Changed("message-lang") || lang == headings.DefaultLang || !messages.HasLang(lang) {
+		return
+	}
+	ReviewConfig.MessageLang = lang
+}
+
+// reviewLanguage は --review-language の値を正規化して返します。未指定の場合は日本語です。
+func reviewLanguage(cfg config.ReviewConfig) string {
+	if lang := strings.ToLower(strings.TrimSpace(cfg.ReviewLanguage)); lang != "" {
+		return lang
+	}
+	return headings.DefaultLang
+}
+
+// localizeHeadings は、投稿する直前の本文の見出しを投稿先の言語に翻訳します。
+// 判定や指摘の件数の解析は日本語の見出しを前提とするため、解析を終えた後の整形済みの本文にのみ適用します。
+func localizeHeadings(destination, content string) string {
+	return headingLocalizer.Localize(destination, content)
+}
+
+// localizeTitle は、チャットのメッセージのタイトルなど、見出しの行ではない固定の文言を投稿先の言語に翻訳します。
+func localizeTitle(destination, title string) string {
+	return headingLocalizer.Text(destination, title)
+}
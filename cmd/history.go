@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"git-gemini-reviewer-go/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+// HistoryFlags は history コマンド固有のフラグを保持します。
+type HistoryFlags struct {
+	Repo      string        // 一覧をこのリポジトリURLに限定する
+	Branch    string        // 一覧をこのフィーチャーブランチに限定する
+	Days      int           // 一覧を直近の日数に限定する (0 の場合は限定しない)
+	Limit     int           // 一覧に表示する最大件数
+	Format    string        // 出力形式
+	OlderThan time.Duration // prune で削除する履歴の経過時間
+}
+
+var historyFlags HistoryFlags
+
+// historyCmd は、レビュー履歴を照会・整理するコマンドです。
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "--history-file に記録されたレビュー履歴を照会・整理します。",
+	Long: `このコマンドは、--history-file (未指定時は既定の履歴ファイル) に記録されたレビュー履歴を照会します。
+'history list' で過去のレビューの一覧を、'history show <レビューID>' で1件のレビューの詳細と結果の全文を出力します。
+'history prune' は保持期間を過ぎた履歴を削除します。レビューのたびに削除する場合は --history-retention を指定します。`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{standaloneCommandAnnotation: "true"},
+}
+
+// historyListCmd は、過去のレビューの一覧を出力するコマンドです。
+var historyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "過去のレビューの一覧を新しい順に出力します。",
+	Args:  cobra.NoArgs,
+	RunE:  runHistoryListCommand,
+}
+
+// historyShowCmd は、1件のレビューの詳細を出力するコマンドです。
+var historyShowCmd = &cobra.Command{
+	Use:   "show <review-id>",
+	Short: "レビューIDのレビューの詳細と結果の全文を出力します。",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runHistoryShowCommand,
+}
+
+// historyPruneCmd は、保持期間を過ぎた履歴を削除するコマンドです。
+var historyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "--older-than より前のレビューと承認判断を履歴から削除します。",
+	Args:  cobra.NoArgs,
+	RunE:  runHistoryPruneCommand,
+}
+
+func init() {
+	historyListCmd.Flags().StringVar(&historyFlags.Repo, "repo", "", "一覧をこのリポジトリURLに限定します")
+	historyListCmd.Flags().StringVar(&historyFlags.Branch, "branch", "", "一覧をこのフィーチャーブランチに限定します")
+	historyListCmd.Flags().IntVar(&historyFlags.Days, "days", 0, "一覧を直近の日数に限定します。0 の場合は限定しません")
+	historyListCmd.Flags().IntVar(&historyFlags.Limit, "limit", 20, "表示する最大件数。0 の場合はすべて表示します")
+	historyListCmd.Flags().StringVar(&historyFlags.Format, "format", "markdown", "出力形式: 'markdown' または 'json'")
+	historyShowCmd.Flags().StringVar(&historyFlags.Format, "format", "markdown", "出力形式: 'markdown' または 'json'")
+	historyPruneCmd.Flags().DurationVar(&historyFlags.OlderThan, "older-than", 0, "この期間より前のレビューと承認判断を削除します (例: 2160h)。(必須)")
+
+	historyCmd.AddCommand(historyListCmd)
+	historyCmd.AddCommand(historyShowCmd)
+	historyCmd.AddCommand(historyPruneCmd)
+}
+
+// historyStore は --history-file (未指定時は既定の履歴ファイル) の Store を返します。
+func historyStore() (*history.Store, string) {
+	path := ReviewConfig.HistoryFile
+	if path == "" {
+		path = history.DefaultPath()
+	}
+	return history.NewStore(path), path
+}
+
+// runHistoryListCommand は list サブコマンドの実行ロジックです。
+func runHistoryListCommand(cmd *cobra.Command, args []string) error {
+	if historyFlags.Days < 0 || historyFlags.Limit < 0 {
+		return fmt.Errorf("--days と --limit には0以上を指定してください")
+	}
+	var since time.Time
+	if historyFlags.Days > 0 {
+		since = time.Now().AddDate(0, 0, -historyFlags.Days)
+	}
+	store, _ := historyStore()
+	reviews, err := store.List(since)
+	if err != nil {
+		return err
+	}
+	reviews = slices.DeleteFunc(reviews, func(r history.Review) bool {
+		return (historyFlags.Repo != "" && r.RepoURL != historyFlags.Repo) ||
+			(historyFlags.Branch != "" && r.FeatureBranch != historyFlags.Branch)
+	})
+	slices.Reverse(reviews)
+	if historyFlags.Limit > 0 && len(reviews) > historyFlags.Limit {
+		reviews = reviews[:historyFlags.Limit]
+	}
+
+	switch strings.ToLower(historyFlags.Format) {
+	case "json":
+		// 一覧では結果の全文を省略します。全文は 'history show' で参照します
+		for i := range reviews {
+			reviews[i].Result = ""
+		}
+		return writeJSON(cmd.OutOrStdout(), reviews)
+	case "markdown":
+		return writeHistoryTable(cmd.OutOrStdout(), reviews)
+	}
+	return fmt.Errorf("出力形式が不正です: '%s' ('markdown' または 'json' を指定してください)", historyFlags.Format)
+}
+
+// runHistoryShowCommand は show サブコマンドの実行ロジックです。
+func runHistoryShowCommand(cmd *cobra.Command, args []string) error {
+	store, path := historyStore()
+	review, err := store.Get(args[0])
+	if err != nil {
+		return err
+	}
+	if review == nil {
+		return fmt.Errorf("レビューID '%s' のレビューは履歴にありません (%s)", args[0], path)
+	}
+
+	switch strings.ToLower(historyFlags.Format) {
+	case "json":
+		return writeJSON(cmd.OutOrStdout(), review)
+	case "markdown":
+		return writeHistoryDetail(cmd.OutOrStdout(), *review)
+	}
+	return fmt.Errorf("出力形式が不正です: '%s' ('markdown' または 'json' を指定してください)", historyFlags.Format)
+}
+
+// runHistoryPruneCommand は prune サブコマンドの実行ロジックです。
+func runHistoryPruneCommand(cmd *cobra.Command, args []string) error {
+	if historyFlags.OlderThan <= 0 {
+		return fmt.Errorf("--older-than には正の期間を指定してください (例: 2160h)")
+	}
+	store, path := historyStore()
+	pruned, err := store.Prune(time.Now().Add(-historyFlags.OlderThan))
+	if err != nil {
+		return fmt.Errorf("レビュー履歴の削除に失敗しました: %w", err)
+	}
+	slog.Info("保持期間を過ぎたレビュー履歴を削除しました。", "path", path, "entries", pruned, "older_than", historyFlags.OlderThan)
+	return nil
+}
+
+// writeJSON は v をインデント付きの JSON として出力します。
+func writeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// writeHistoryTable はレビューの一覧を Markdown の表として出力します。
+func writeHistoryTable(w io.Writer, reviews []history.Review) error {
+	if len(reviews) == 0 {
+		_, err := fmt.Fprintln(w, "該当するレビューはありません。")
+		return err
+	}
+	var sb strings.Builder
+	sb.WriteString("| レビューID | 実行日時 | リポジトリ | ブランチ | モデル | 判定 | 指摘 | トークン (入力/出力) | 承認判断 |\n")
+	sb.WriteString("| :--- | :--- | :--- | :--- | :--- | :--- | ---: | ---: | :--- |\n")
+	for _, r := range reviews {
+		total := 0
+		for _, n := range r.FindingCounts() {
+			total += n
+		}
+		decision := "-"
+		if r.Decision != nil {
+			decision = r.Decision.Outcome.Label()
+		}
+		fmt.Fprintf(&sb, "| `%s` | %s | %s | `%s` ← `%s` | %s | %s | %d | %s | %s |\n",
+			r.ReviewID, r.ReviewedAt.Local().Format("2006/01/02 15:04"), r.RepoURL, r.BaseBranch, r.FeatureBranch,
+			r.Model, r.Verdict, total, historyTokens(r), decision)
+	}
+	_, err := fmt.Fprint(w, sb.String())
+	return err
+}
+
+// writeHistoryDetail は1件のレビューの詳細と結果の全文を Markdown として出力します。
+func writeHistoryDetail(w io.Writer, r history.Review) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# レビュー %s\n\n", r.ReviewID)
+	sb.WriteString("| 項目 | 内容 |\n| :--- | :--- |\n")
+	fmt.Fprintf(&sb, "| リポジトリ | `%s` |\n", r.RepoURL)
+	fmt.Fprintf(&sb, "| ブランチ差分 | `%s` ← `%s` |\n", r.BaseBranch, r.FeatureBranch)
+	if r.BaseCommit != "" {
+		fmt.Fprintf(&sb, "| コミット | `%s` ← `%s` |\n", shortSHA(r.BaseCommit), shortSHA(r.HeadCommit))
+	}
+	fmt.Fprintf(&sb, "| レビュー実行日時 | %s |\n", r.ReviewedAt.Local().Format("2006/01/02 15:04:05 MST"))
+	fmt.Fprintf(&sb, "| モード | %s |\n", r.Mode)
+	fmt.Fprintf(&sb, "| モデル | `%s` |\n", r.Model)
+	fmt.Fprintf(&sb, "| トークン (入力/出力) | %s |\n", historyTokens(r))
+	fmt.Fprintf(&sb, "| 判定 | %s |\n", r.Verdict)
+	if r.Decision != nil {
+		fmt.Fprintf(&sb, "| 承認判断 | %s (%s, %s) |\n", r.Decision.Outcome.Label(), r.Decision.Decider, r.Decision.DecidedAt.Local().Format("2006/01/02 15:04"))
+	}
+	sb.WriteString("\n---\n\n")
+	sb.WriteString(strings.TrimSpace(r.Result))
+	sb.WriteString("\n")
+	_, err := fmt.Fprint(w, sb.String())
+	return err
+}
+
+// historyTokens はトークン数の表示です。記録されていない場合は '-' を返します。
+func historyTokens(r history.Review) string {
+	if r.InputTokens == 0 && r.OutputTokens == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d / %d", r.InputTokens, r.OutputTokens)
+}
+
+// shortSHA はコミットの SHA の先頭12文字を返します。
+func shortSHA(sha string) string {
+	if len(sha) > 12 {
+		return sha[:12]
+	}
+	return sha
+}
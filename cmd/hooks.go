@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"context"
+
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/hooks"
+	"git-gemini-reviewer-go/internal/progress"
+	"git-gemini-reviewer-go/internal/schema"
+	"git-gemini-reviewer-go/internal/verdict"
+)
+
+// hookSpecs は --hook フラグで指定されたフックです。
+var hookSpecs []string
+
+// runHooks は、指定した段階のフックにレビューの文脈を渡して実行し、フックによる置き換えを反映したレビュー結果を返します。
+func runHooks(ctx context.Context, cfg config.ReviewConfig, stage hooks.Stage, review string) (string, error) {
+	if len(cfg.Hooks) == 0 {
+		return review, nil
+	}
+	event := hooks.Event{
+		SchemaVersion: schema.Version,
+		ReviewID:      cfg.ReviewID,
+		RepoURL:       cfg.RepoURL,
+		BaseBranch:    cfg.BaseBranch,
+		FeatureBranch: cfg.FeatureBranch,
+		Mode:          cfg.ReviewMode,
+		Model:         cfg.GeminiModel,
+		Destination:   cfg.Destination,
+		Review:        review,
+	}
+	if review != "" {
+		event.Verdict = string(verdict.Parse(review))
+	}
+	done := progress.Start(ctx, cfg.ReviewID, progress.PhaseHooks, string(stage))
+	result, err := hooks.Run(ctx, cfg.Hooks, stage, event)
+	done(err)
+	return result, err
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/backlogpr"
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/issuelink"
+)
+
+// issueLinkSpecs は --issue-link フラグで指定された課題トラッカーの設定です。
+var issueLinkSpecs []string
+
+// resolveIssueTrackers は --issue-link フラグから課題トラッカーを構築します。
+// フラグが未指定の場合は、BACKLOG_SPACE_URL 環境変数と GitHub のリポジトリURL (repoURL) から既定のトラッカーを推定します。
+func resolveIssueTrackers(repoURL string) ([]issuelink.Tracker, error) {
+	if len(issueLinkSpecs) > 0 {
+		trackers := make([]issuelink.Tracker, 0, len(issueLinkSpecs))
+		for _, spec := range issueLinkSpecs {
+			t, err := issuelink.ParseTracker(spec)
+			if err != nil {
+				return nil, err
+			}
+			trackers = append(trackers, t)
+		}
+		return trackers, nil
+	}
+
+	var trackers []issuelink.Tracker
+	if spaceURL := os.Getenv("BACKLOG_SPACE_URL"); spaceURL != "" {
+		trackers = append(trackers, issuelink.Tracker{
+			Name:        issuelink.TrackerBacklog,
+			URLTemplate: strings.TrimRight(spaceURL, "/") + "/view/{key}",
+		})
+	}
+	if issuesURL := githubIssuesURL(repoURL); issuesURL != "" {
+		trackers = append(trackers, issuelink.Tracker{
+			Name:        issuelink.TrackerGitHub,
+			URLTemplate: issuesURL + "/{number}",
+		})
+	}
+	if len(trackers) > 0 {
+		slog.Debug("既定の課題トラッカーを使用します。", "count", len(trackers))
+	}
+	return trackers, nil
+}
+
+// githubIssuesURL は GitHub のリポジトリURL (SSH / HTTPS) から Issues のURLを返します。GitHub 以外の場合は空文字列です。
+func githubIssuesURL(repoURL string) string {
+	var path string
+	switch {
+	case strings.HasPrefix(repoURL, "git@github.com:"):
+		path = strings.TrimPrefix(repoURL, "git@github.com:")
+	case strings.HasPrefix(repoURL, "ssh://git@github.com/"):
+		path = strings.TrimPrefix(repoURL, "ssh://git@github.com/")
+	case strings.HasPrefix(repoURL, "https://github.com/"):
+		path = strings.TrimPrefix(repoURL, "https://github.com/")
+	default:
+		return ""
+	}
+	path = strings.TrimSuffix(strings.TrimSuffix(path, "/"), ".git")
+	if strings.Count(path, "/") != 1 {
+		return ""
+	}
+	return fmt.Sprintf("https://github.com/%s/issues", path)
+}
+
+// detectBacklogIssueID は、フィーチャーブランチ名とレビューで取得したコミットメッセージ (新しい順) から Backlog の課題キーを検出します。
+// --repo-url が Backlog Git の URL の場合は、そのプロジェクトの課題キーのみを対象とします。検出できない場合は空文字列を返します。
+func detectBacklogIssueID(cfg config.ReviewConfig, commitMessages []string) string {
+	var projects []string
+	if repo, ok := backlogpr.ParseRepoURL(cfg.RepoURL); ok {
+		projects = []string{repo.ProjectKey}
+	}
+	// ブランチ名は小文字で付けられることが多いため、コミットメッセージにも見つからない場合は大文字に変換して探す
+	texts := append([]string{cfg.FeatureBranch}, commitMessages...)
+	texts = append(texts, strings.ToUpper(cfg.FeatureBranch))
+	keys := issuelink.ProjectKeys(projects, texts...)
+	if len(keys) == 0 {
+		return ""
+	}
+	if len(keys) > 1 {
+		slog.Debug("複数の課題キーを検出しました。最初のキーを使用します。", "keys", keys)
+	}
+	return keys[0]
+}
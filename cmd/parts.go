@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"git-gemini-reviewer-go/internal/msgfit"
+	"git-gemini-reviewer-go/internal/pkg/retry"
+)
+
+// postParts は、content を投稿先の上限に収まるメッセージに分割して順に投稿し、最初のメッセージの URL を返します。
+// 投稿済みのメッセージを重複して投稿しないよう、リトライはメッセージごとに行います。
+func postParts(ctx context.Context, op, content string, limit msgfit.Limit, post func(ctx context.Context, part string) (string, error)) (string, error) {
+	parts := msgfit.Split(content, limit)
+	if len(parts) > 1 {
+		slog.Info("レビュー結果が投稿先の上限を超えるため、分割して投稿します。", "operation", op, "parts", len(parts), "limit", limit.Max)
+	}
+
+	var permalink string
+	for i, part := range parts {
+		var url string
+		err := retry.Do(ctx, op, func(ctx context.Context) error {
+			var err error
+			url, err = post(ctx, part)
+			return err
+		}, retry.WithBudget(notifyRetryBudget))
+		if err != nil && len(parts) > 1 {
+			return permalink, fmt.Errorf("分割したメッセージ (%d/%d) の投稿に失敗しました: %w", i+1, len(parts), err)
+		}
+		if err != nil {
+			return permalink, err
+		}
+		if permalink == "" {
+			permalink = url
+		}
+	}
+	return permalink, nil
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/inline"
+	"git-gemini-reviewer-go/internal/policy"
+	"git-gemini-reviewer-go/internal/verdict"
+
+	"github.com/spf13/cobra"
+)
+
+// --- ポリシーパック関連のフラグ変数 ---
+var (
+	policyName string
+	policyDir  string
+)
+
+// reviewGate は、レビューについてポリシーの合否判定に使用する情報です。
+type reviewGate struct {
+	reviewed     bool
+	verdict      verdict.Verdict
+	failedChecks []string
+	// findings は指摘の件数です。構造化された指摘の場合は抑制された指摘を除きます。
+	findings int
+	// structured は --inline や --format json で得た構造化された指摘です。重大度のしきい値の照合に使用します。
+	structured *inline.Review
+}
+
+// defaultPolicyDir は、ポリシーパックを検索するデフォルトのディレクトリを返します。
+// 一元管理しているリポジトリのチェックアウト先などを GEMINI_REVIEWER_POLICY_DIR で指定できます。
+func defaultPolicyDir() string {
+	if dir := os.Getenv("GEMINI_REVIEWER_POLICY_DIR"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "policies"
+	}
+	return filepath.Join(home, ".git-gemini-reviewer", "policies")
+}
+
+// applyPolicy は --policy で指定されたポリシーパックを読み込み、明示的に指定されていないフラグの値に適用します。
+// コマンドラインで指定した値は常にポリシーより優先されます。
+func applyPolicy(cmd *cobra.Command) error {
+	if policyName == "" {
+		return nil
+	}
+	pack, err := policy.Load(policyName, policyDir)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	unset := func(name string) bool {
+		f := flags.Lookup(name)
+		return f != nil && !f.Changed
+	}
+	if pack.Mode != "" && unset("mode") {
+		ReviewConfig.ReviewMode = pack.Mode
+	}
+	if pack.Model != "" && unset("gemini") {
+		ReviewConfig.GeminiModel = pack.Model
+	}
+	if len(pack.Personas) > 0 && unset("persona") {
+		ReviewConfig.Personas = pack.Personas
+	}
+	if len(pack.Excludes) > 0 && unset("exclude") {
+		ReviewConfig.Excludes = pack.Excludes
+	}
+	if len(pack.CriticalPaths) > 0 && unset("critical-path") {
+		ReviewConfig.CriticalPaths = pack.CriticalPaths
+	}
+	if pack.MaxFiles > 0 && unset("max-files") {
+		ReviewConfig.MaxFiles = pack.MaxFiles
+	}
+	if pack.MaxHunks > 0 && unset("max-hunks") {
+		ReviewConfig.MaxHunks = pack.MaxHunks
+	}
+	if pack.FailOn != "" && unset("fail-on") {
+		ReviewConfig.FailOn = pack.FailOn
+	}
+	if len(pack.RequiredChecks) > 0 && unset("require-check") {
+		ReviewConfig.RequiredChecks = pack.RequiredChecks
+	}
+	// 配信先は post コマンドのみが持つフラグです
+	if len(pack.Destinations) > 0 && unset("to") {
+		postDestinations = pack.Destinations
+	}
+
+	slog.Info("ポリシーパックを適用しました。", "policy", pack.Name, "mode", ReviewConfig.ReviewMode)
+	return nil
+}
+
+// validateGateFlags は、判定のしきい値と必須チェックのフラグを検証します。
+func validateGateFlags() error {
+	if _, err := policy.ParseThreshold(ReviewConfig.FailOn); err != nil {
+		return err
+	}
+	if ReviewConfig.MaxFindings < -1 {
+		return fmt.Errorf("--max-findings には0以上 (無制限は -1) を指定してください")
+	}
+	return policy.ValidateChecks(ReviewConfig.RequiredChecks)
+}
+
+// reviewRunFunc は、レビューを実行して結果を投稿し、そのレビューの結果を返すコマンドの実行関数です。
+type reviewRunFunc func(cmd *cobra.Command, args []string) (pipelineResult, error)
+
+// withReviewGate は、レビューを実行するコマンドの実行関数を RunE に変換し、投稿の完了後に
+// 判定のしきい値と必須チェックを評価して、満たさない場合にコマンドを失敗させます。
+// 投稿先への配信を妨げないよう、評価は run が成功した後に行います。
+func withReviewGate(run reviewRunFunc) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		res, err := run(cmd, args)
+		if err != nil {
+			return err
+		}
+		return evaluateReviewGate(res.gate)
+	}
+}
+
+// evaluateReviewGate は、レビューの判定と必須チェックの結果をポリシーと照合します。
+func evaluateReviewGate(g reviewGate) error {
+	if !g.reviewed {
+		return nil
+	}
+	threshold, _ := policy.ParseThreshold(ReviewConfig.FailOn)
+
+	var reasons []string
+	switch {
+	case threshold.Severity != "" && g.structured != nil:
+		if n := g.structured.CountAtLeast(threshold.Severity); n > 0 {
+			reasons = append(reasons, fmt.Sprintf("重大度 '%s' 以上の指摘が %d 件あります (fail-on: %s)", threshold.Severity, n, threshold))
+		}
+	case policy.Exceeds(threshold.VerdictThreshold(), g.verdict):
+		reasons = append(reasons, fmt.Sprintf("判定 '%s' がしきい値 (fail-on: %s) に達しました", g.verdict.Label(), threshold))
+	}
+	if ReviewConfig.MaxFindings >= 0 && g.findings > ReviewConfig.MaxFindings {
+		reasons = append(reasons, fmt.Sprintf("指摘が %d 件あり、上限 (max-findings: %d) を超えています", g.findings, ReviewConfig.MaxFindings))
+	}
+	reasons = append(reasons, g.failedChecks...)
+	if len(reasons) == 0 {
+		return nil
+	}
+	return fmt.Errorf("レビューがポリシーを満たしていません: %s", strings.Join(reasons, " / "))
+}
@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"git-gemini-reviewer-go/internal/gcs"
+	"git-gemini-reviewer-go/internal/notify"
+
+	"github.com/spf13/cobra"
+)
+
+// --- コマンド固有のフラグ変数 ---
+var (
+	postDestinations []string
+	postIssueID      string
+	postAutoIssueID  bool
+	postGCSURI       string
+	postReportJSON   string
+	postLinks        []string
+)
+
+// postCmd は、1回のレビュー結果を複数の投稿先に配信 (ファンアウト) するコマンドです。
+var postCmd = &cobra.Command{
+	Use:   "post",
+	Short: "コードレビューを実行し、その結果を複数の投稿先にまとめて配信します。",
+	Long: `このコマンドは、指定されたGitリポジトリのブランチ間の差分をAIでレビューし、その結果を --to で指定したすべての投稿先 (stdout, backlog, slack, teams, discord, gcs) に配信します。
+一部の投稿先への配信が失敗しても残りの投稿先への配信は継続し、最後に失敗した投稿先の一覧を縮退した処理として報告します (終了コード 3)。すべての投稿先への配信が失敗した場合は終了コード 1 で終了します。`,
+	Args: cobra.NoArgs,
+	RunE: withReviewGate(runPostCommand),
+}
+
+func init() {
+	postCmd.Flags().StringSliceVar(&postDestinations, "to", []string{"stdout"}, "配信先をカンマ区切りで指定: 'stdout', 'backlog', 'slack', 'teams', 'discord', 'gcs'")
+	postCmd.Flags().StringVarP(&postIssueID, "issue-id", "i", "", "backlog 配信時にコメントを投稿するBacklog課題ID（例: PROJECT-123）")
+	postCmd.Flags().BoolVar(&postAutoIssueID, "auto-issue-id", false, "--issue-id が未指定の場合、フィーチャーブランチ名と最新のコミットメッセージから Backlog の課題キーを検出して使用する")
+	postCmd.Flags().StringVarP(&postGCSURI, "gcs-uri", "s", "gs://git-gemini-reviewer-go/review/result.html", "gcs 配信時の保存先。'{review_id}' と '{repo}' はレビューIDとリポジトリ名に置き換わります")
+	postCmd.Flags().StringArrayVar(&postLinks, "link", nil, "配信先の投稿に、先に配信した別の配信先のパーマリンクを添えます (例: 'slack=gcs', 'backlog=gcs|slack')。複数指定可。")
+	postCmd.Flags().StringVar(&postReportJSON, "report-json", "", "配信先ごとの状態・所要時間・パーマリンクをまとめた配信レポート (JSON) の出力先ファイル ('-' で標準出力)")
+	addHTMLReportFlags(postCmd)
+	addGCSIndexFlags(postCmd)
+	addBacklogCommentLimitFlag(postCmd)
+}
+
+// --------------------------------------------------------------------------
+// コマンドの実行ロジック
+// --------------------------------------------------------------------------
+
+// runPostCommand はコマンドの主要な実行ロジックを含みます。
+func runPostCommand(cmd *cobra.Command, args []string) (pipelineResult, error) {
+	ctx := cmd.Context()
+
+	// 1. 配信先の構築 (レビュー実行前に設定不備を検出する)
+	var res pipelineResult
+	destinations, err := buildDestinations(postDestinations, &res)
+	if err != nil {
+		return pipelineResult{}, err
+	}
+	links, err := notify.ParseLinkMatrix(postLinks)
+	if err != nil {
+		return pipelineResult{}, err
+	}
+	if err := links.Validate(destinations); err != nil {
+		return pipelineResult{}, err
+	}
+
+	// 2. パイプラインを実行し、結果を受け取る
+	res, err = executeReviewPipeline(ctx, ReviewConfig)
+	if err != nil {
+		return res, err
+	}
+	reviewResult := res.markdown
+	if reviewResult == "" {
+		slog.Warn("レビュー結果の内容が空のため、配信をスキップします。")
+		return res, nil
+	}
+
+	// 3. すべての配信先にファンアウト
+	results, err := notify.FanOut(ctx, destinations, reviewResult, notify.WithLinks(links))
+	slog.Info(notify.Summary(results))
+	err = deliveryError(cmd, results, err)
+
+	// 4. 配信レポートの出力 (一部の配信が失敗した場合も出力する)
+	report := notify.NewReport(ReviewConfig.ReviewID, ReviewConfig.PromptVariant, results)
+	report.Log()
+	if postReportJSON != "" {
+		if reportErr := writeDeliveryReport(postReportJSON, report); reportErr != nil {
+			return res, errors.Join(err, reportErr)
+		}
+	}
+	return res, err
+}
+
+// --------------------------------------------------------------------------
+// ヘルパー関数
+// --------------------------------------------------------------------------
+
+// buildDestinations は、配信先名のリストから notify.Destination を構築します。
+// Backlog の配信先は、配信時に res (レビューの結果) のコミットメッセージから課題キーを検出します。
+func buildDestinations(names []string, res *pipelineResult) ([]notify.Destination, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("--to フラグで配信先を1つ以上指定してください")
+	}
+
+	destinations := make([]notify.Destination, 0, len(names))
+	for _, name := range names {
+		switch name {
+		case "stdout":
+			destinations = append(destinations, notify.Destination{Name: name, Post: func(_ context.Context, content string) (string, error) {
+				printReviewResult(name, content)
+				return "", nil
+			}})
+		case "backlog":
+			authInfo := getBacklogAuthInfo()
+			if authInfo.APIKey == "" || authInfo.SpaceURL == "" {
+				return nil, fmt.Errorf("Backlog連携には環境変数 BACKLOG_API_KEY および BACKLOG_SPACE_URL が必須です")
+			}
+			if postIssueID == "" && !postAutoIssueID {
+				return nil, fmt.Errorf("backlog に配信するには --issue-id または --auto-issue-id フラグが必須です")
+			}
+			destinations = append(destinations, notify.Destination{Name: name, Post: func(ctx context.Context, content string) (string, error) {
+				// 課題キーの検出にはレビューで取得したコミットメッセージを使うため、配信時に解決する
+				issueID := postIssueID
+				if issueID == "" {
+					if issueID = detectBacklogIssueID(ReviewConfig, res.commitMessages); issueID == "" {
+						return "", fmt.Errorf("ブランチ名 '%s' とコミットメッセージから Backlog の課題キーを検出できませんでした", ReviewConfig.FeatureBranch)
+					}
+					slog.Info("ブランチ名またはコミットメッセージから Backlog の課題キーを検出しました。", "issue_id", issueID)
+				}
+				if err := postBacklogReview(ctx, authInfo, issueID, content); err != nil {
+					return "", err
+				}
+				return backlogIssueURL(authInfo.SpaceURL, issueID), nil
+			}})
+		case "slack":
+			authInfo := getSlackAuthInfo()
+			if authInfo.WebhookURL == "" {
+				return nil, fmt.Errorf("SLACK_WEBHOOK_URL 環境変数の設定が必須です。")
+			}
+			// Incoming Webhook は投稿したメッセージのURLを返さないため、パーマリンクは記録しません
+			destinations = append(destinations, notify.Destination{Name: name, Post: func(ctx context.Context, content string) (string, error) {
+				return "", postToSlack(ctx, content, authInfo)
+			}})
+		case "teams":
+			webhookURL := os.Getenv("TEAMS_WEBHOOK_URL")
+			if webhookURL == "" {
+				return nil, fmt.Errorf("TEAMS_WEBHOOK_URL 環境変数の設定が必須です。")
+			}
+			destinations = append(destinations, notify.Destination{Name: name, Post: func(ctx context.Context, content string) (string, error) {
+				return "", postToTeams(ctx, webhookURL, content)
+			}})
+		case "discord":
+			webhookURL := os.Getenv("DISCORD_WEBHOOK_URL")
+			if webhookURL == "" {
+				return nil, fmt.Errorf("DISCORD_WEBHOOK_URL 環境変数の設定が必須です。")
+			}
+			destinations = append(destinations, notify.Destination{Name: name, Post: func(ctx context.Context, content string) (string, error) {
+				return "", postToDiscord(ctx, webhookURL, content)
+			}})
+		case "gcs":
+			if _, err := htmlReportOptions().Validate(); err != nil {
+				return nil, err
+			}
+			destinations = append(destinations, notify.Destination{Name: name, Post: func(ctx context.Context, content string) (string, error) {
+				uri, err := publishToGCS(ctx, postGCSURI, content)
+				if err != nil {
+					return "", err
+				}
+				bucket, objectPath, _ := gcs.ParseURI(uri)
+				return gcs.BrowserURL(bucket, objectPath), nil
+			}})
+		default:
+			return nil, fmt.Errorf("不明な配信先です: '%s' ('stdout', 'backlog', 'slack', 'teams', 'discord', 'gcs' のいずれかを指定してください)", name)
+		}
+	}
+	return destinations, nil
+}
+
+// writeDeliveryReport は配信レポートを JSON としてファイルまたは標準出力に書き込みます。
+func writeDeliveryReport(path string, report notify.Report) error {
+	if path == "-" {
+		return report.WriteJSON(os.Stdout)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("配信レポートファイルの作成に失敗しました: %w", err)
+	}
+	defer f.Close()
+	return report.WriteJSON(f)
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"git-gemini-reviewer-go/internal/profile"
+
+	"github.com/shouni/go-cli-base"
+	"github.com/spf13/cobra"
+)
+
+// profileName は --profile で指定されたプロファイルの名前です。
+var profileName string
+
+// configPath は、プロファイルを定義した設定ファイルのパスを返します。
+// go-cli-base の --config (-C) を優先し、未指定の場合は環境変数 GEMINI_REVIEWER_CONFIG、デフォルトのパスの順に使用します。
+func configPath() string {
+	if clibase.Flags.ConfigFile != "" {
+		return clibase.Flags.ConfigFile
+	}
+	if path := os.Getenv("GEMINI_REVIEWER_CONFIG"); path != "" {
+		return path
+	}
+	return profile.DefaultPath()
+}
+
+// applyProfile は --profile で指定されたプロファイルを設定ファイルから読み込み、明示的に指定されていないフラグに適用します。
+// フラグの値はコマンドラインと同じ方法で設定するため、プロファイルで指定した値はポリシーパックより優先されます。
+// このコマンドにないフラグ (post の --to など) は、他のコマンドと共有するプロファイルのため無視します。
+func applyProfile(cmd *cobra.Command) error {
+	if profileName == "" {
+		return nil
+	}
+	path := configPath()
+	file, err := profile.Load(path)
+	if err != nil {
+		return err
+	}
+	p, err := file.Get(profileName)
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	for _, name := range p.FlagNames() {
+		if name == "profile" || name == "config" {
+			return fmt.Errorf("プロファイル '%s' に '%s' は指定できません", profileName, name)
+		}
+		f := flags.Lookup(name)
+		if f == nil {
+			if !definedInAnyCommand(cmd.Root(), name) {
+				return fmt.Errorf("プロファイル '%s' の '%s' は不明なフラグです", profileName, name)
+			}
+			slog.Debug("このコマンドにないフラグのため、プロファイルの値を無視します。", "profile", profileName, "flag", name)
+			continue
+		}
+		if f.Changed {
+			continue
+		}
+		values, err := p.Values(name)
+		if err != nil {
+			return fmt.Errorf("プロファイル '%s' が不正です: %w", profileName, err)
+		}
+		for _, v := range values {
+			if err := flags.Set(name, v); err != nil {
+				return fmt.Errorf("プロファイル '%s' の '%s' の値が不正です: %w", profileName, name, err)
+			}
+		}
+	}
+
+	for key, value := range p.Env {
+		if _, ok := os.LookupEnv(key); ok {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("プロファイル '%s' の環境変数 %s の設定に失敗しました: %w", profileName, key, err)
+		}
+	}
+
+	slog.Info("プロファイルを適用しました。", "profile", profileName, "config", path)
+	return nil
+}
+
+// definedInAnyCommand は、いずれかのコマンドにフラグが定義されているかを返します。
+func definedInAnyCommand(root *cobra.Command, name string) bool {
+	if root.PersistentFlags().Lookup(name) != nil || root.Flags().Lookup(name) != nil {
+		return true
+	}
+	for _, c := range root.Commands() {
+		if definedInAnyCommand(c, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"git-gemini-reviewer-go/internal/progress"
+)
+
+// progressEvents は --progress-events フラグで指定された進捗イベントの出力先です。
+var progressEvents string
+
+// withProgressEvents は、進捗イベントの出力先が指定されている場合に、イベントを JSON Lines で書き込む Func をコンテキストに格納します。
+// 'stderr' 以外はファイルのパスとみなして追記します。ファイルはプロセスの終了まで開いたままにします。
+func withProgressEvents(ctx context.Context) (context.Context, error) {
+	switch progressEvents {
+	case "":
+		return ctx, nil
+	case "stderr":
+		return progress.NewContext(ctx, progress.JSONLines(os.Stderr)), nil
+	}
+	f, err := os.OpenFile(progressEvents, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("進捗イベントの出力先を開けませんでした (%s): %w", progressEvents, err)
+	}
+	return progress.NewContext(ctx, progress.JSONLines(f)), nil
+}
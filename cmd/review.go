@@ -2,46 +2,214 @@ package cmd
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
+	"time"
 
+	"git-gemini-reviewer-go/internal/aggregate"
 	"git-gemini-reviewer-go/internal/builder"
+	"git-gemini-reviewer-go/internal/clonecache"
 	"git-gemini-reviewer-go/internal/config"
-
-	"github.com/shouni/go-utils/urlpath"
+	"git-gemini-reviewer-go/internal/diffstat"
+	"git-gemini-reviewer-go/internal/findings"
+	"git-gemini-reviewer-go/internal/history"
+	"git-gemini-reviewer-go/internal/hooks"
+	"git-gemini-reviewer-go/internal/inline"
+	"git-gemini-reviewer-go/internal/messages"
+	"git-gemini-reviewer-go/internal/progress"
+	"git-gemini-reviewer-go/internal/runner"
+	"git-gemini-reviewer-go/internal/tokenusage"
+	"git-gemini-reviewer-go/internal/verdict"
 )
 
+// pipelineResult は、executeReviewPipeline による1件のレビューの結果です。
+// serve コマンドなどは複数のレビューを並行して実行するため、結果はパッケージの変数ではなく呼び出し元に返します。
+type pipelineResult struct {
+	// markdown は投稿するレビュー結果です。差分がなくレビューをスキップした場合は空文字列です。
+	markdown string
+	// inline は --inline や --format json で得た構造化された指摘です。
+	inline *inline.Review
+	// gate はポリシーの合否判定に使用する判定と必須チェックの結果です。
+	gate reviewGate
+	// usage はトークン数と推定費用です。
+	usage tokenusage.Report
+	// diffStats は差分の種類別の変更量です。
+	diffStats diffstat.Stats
+	// model は実際に応答したモデルです。フォールバックのモデルが応答した場合はそのモデルです。
+	model string
+	// commitMessages はレビューで取得したコミットメッセージ (新しい順) です。--auto-issue-id で課題キーを検出するために使用します。
+	commitMessages []string
+}
+
 // executeReviewPipeline は、すべての依存関係を構築し、レビューパイプラインを実行します。
-// 実行結果の文字列とエラーを返します。
+// 構築やレビューに失敗した場合も、それまでに得た結果 (トークン数など) を返します。
 func executeReviewPipeline(
 	ctx context.Context,
 	cfg config.ReviewConfig,
-) (string, error) {
-	const baseRepoDirName = "reviewerRepos"
-
-	// LocalPathが指定されていない場合、RepoURLから動的に生成しcfgを更新します。
-	if cfg.LocalPath == "" {
-		cfg.LocalPath = urlpath.SanitizeURLToUniquePath(cfg.RepoURL, baseRepoDirName)
-		slog.Debug("LocalPathが未指定のため、URLから動的にパスを生成しました。", "generatedPath", cfg.LocalPath)
-	}
+) (res pipelineResult, err error) {
+	done := progress.Start(ctx, cfg.ReviewID, progress.PhasePipeline, cfg.Destination)
+	res.model = cfg.GeminiModel
+	defer func() {
+		sendCallback(ctx, cfg, res.markdown, res.diffStats, res.usage, err)
+		done(err)
+	}()
 
+	cfg = withDefaultLocalPath(cfg)
 	reviewRunner, err := builder.BuildReviewRunner(ctx, cfg)
 	if err != nil {
 		// BuildReviewRunner が内部でアダプタやビルダーの構築エラーをラップして返す
-		return "", fmt.Errorf("レビュー実行器の構築に失敗しました: %w", err)
+		return res, fmt.Errorf("レビュー実行器の構築に失敗しました: %w", err)
 	}
 
 	slog.Info("レビューパイプラインを開始します。")
 
+	if _, err := runHooks(ctx, cfg, hooks.PreDiff, ""); err != nil {
+		return res, err
+	}
+
 	reviewResult, err := reviewRunner.Run(ctx, cfg)
-	if err != nil {
-		return "", err
+	maintainCloneCache(cfg)
+	res.commitMessages = reviewRunner.CommitMessages()
+	res.diffStats = reviewRunner.DiffStats()
+	// フォールバックのモデルが応答した場合は、履歴・コールバック・フックに実際に応答したモデルを記録します
+	if m := reviewRunner.Model(); m != "" {
+		cfg.GeminiModel = m
+	}
+	res.model = cfg.GeminiModel
+	res.usage = reviewRunner.Usage()
+	if res.usage.Requests > 0 {
+		slog.Info("AIのトークン使用量と推定費用", append([]any{"model", cfg.GeminiModel}, res.usage.LogAttrs()...)...)
+	}
+	if pe, ok := aggregate.AsPipelineError(err); ok && !pe.Fatal() {
+		// 縮退した処理はコマンドの終了時にまとめて報告し、レビュー結果の投稿は継続します
+		aggregate.FromContext(ctx).Merge("review", err)
+	} else if err != nil {
+		return res, err
 	}
 
 	if reviewResult == "" {
 		slog.Info("Diff がないためレビューをスキップしました。")
-		return "", nil
+		if !cfg.NotifyNoDiff {
+			return res, nil
+		}
+		res.markdown, err = messages.Render(messages.NoDiff, runner.MessageData(cfg), runner.MessageOptions(cfg))
+		return res, err
 	}
 
-	return reviewResult, nil
+	// post-review フックによる加工は、履歴と判定にも反映します
+	reviewResult, err = runHooks(ctx, cfg, hooks.PostReview, reviewResult)
+	if err != nil {
+		return res, err
+	}
+
+	recordHistory(ctx, cfg, reviewResult, reviewRunner)
+	res.inline = reviewRunner.InlineReview()
+	res.gate = reviewGate{
+		reviewed:     true,
+		verdict:      verdict.Parse(reviewResult),
+		failedChecks: reviewRunner.FailedChecks(),
+		findings:     len(findings.Extract(reviewResult)),
+		structured:   res.inline,
+	}
+	if res.inline != nil {
+		res.gate.findings = len(res.inline.Findings)
+	}
+	// トークン数と推定費用は履歴と判定には含めず、投稿する本文にのみ付与します
+	if cfg.UsageTrailer {
+		reviewResult += res.usage.Trailer(cfg.GeminiModel)
+	}
+	res.markdown, err = runHooks(ctx, cfg, hooks.PrePost, reviewResult)
+	return res, err
+}
+
+// withDefaultLocalPath は、LocalPathが指定されていない場合に、キャッシュディレクトリの下の RepoURL ごとのパスを設定した cfg を返します。
+// パッチファイルや作業ツリーをレビューする場合はクローンを行わないため不要です。
+func withDefaultLocalPath(cfg config.ReviewConfig) config.ReviewConfig {
+	if cfg.LocalPath == "" && cfg.PatchFile == "" && cfg.Worktree == "" {
+		cfg.LocalPath = clonecache.Path(cloneCacheDir(cfg), cfg.RepoURL)
+		slog.Debug("LocalPathが未指定のため、キャッシュディレクトリの下のパスを使用します。", "generatedPath", cfg.LocalPath)
+	}
+	return cfg
+}
+
+// cloneCacheDir は --cache-dir (未指定時は既定のキャッシュディレクトリ) を返します。
+func cloneCacheDir(cfg config.ReviewConfig) string {
+	if cfg.CacheDir != "" {
+		return cfg.CacheDir
+	}
+	return clonecache.DefaultDir()
+}
+
+// maintainCloneCache は、キャッシュディレクトリのクローンの使用日時を更新し、合計サイズの上限を超えた場合は
+// 最も長く使われていないクローンから削除します。--local-path で指定したクローン先は管理しません。
+func maintainCloneCache(cfg config.ReviewConfig) {
+	dir := cloneCacheDir(cfg)
+	if cfg.LocalPath == "" || cfg.InMemoryRepo || !clonecache.Contains(dir, cfg.LocalPath) {
+		return
+	}
+	if err := clonecache.Touch(cfg.LocalPath); err != nil {
+		slog.Warn("キャッシュの管理に失敗しました。", "error", err)
+	}
+	if cfg.CacheMaxMB <= 0 {
+		return
+	}
+	evicted, err := clonecache.Evict(dir, int64(cfg.CacheMaxMB)<<20, cfg.LocalPath)
+	if err != nil {
+		slog.Warn("キャッシュのクローンの削除に失敗しました。", "dir", dir, "error", err)
+	}
+	for _, e := range evicted {
+		slog.Info("キャッシュの上限を超えたため、最も長く使われていないクローンを削除しました。", "path", e.Path, "size", e.Size, "last_used", e.LastUsed)
+	}
+}
+
+// recordHistory は、履歴ファイルが指定されている場合にレビュー結果を記録し、保持期間を過ぎた履歴を削除します。
+// 履歴の記録に失敗してもレビュー結果の投稿は継続するため、縮退した処理として記録します。
+func recordHistory(ctx context.Context, cfg config.ReviewConfig, reviewResult string, reviewRunner *runner.ReviewRunner) {
+	if cfg.HistoryFile == "" {
+		return
+	}
+	done := progress.Start(ctx, cfg.ReviewID, progress.PhaseHistory, "")
+	store := history.NewStore(cfg.HistoryFile)
+	baseCommit, headCommit := reviewRunner.Commits()
+	usage := reviewRunner.Usage()
+	err := store.RecordReview(history.Review{
+		ReviewID:      cfg.ReviewID,
+		RepoURL:       cfg.RepoURL,
+		BaseBranch:    cfg.BaseBranch,
+		FeatureBranch: cfg.FeatureBranch,
+		Mode:          cfg.ReviewMode,
+		Model:         cfg.GeminiModel,
+		PromptVariant: cfg.PromptVariant,
+		BaseCommit:    baseCommit,
+		HeadCommit:    headCommit,
+		InputTokens:   usage.InputTokens,
+		OutputTokens:  usage.OutputTokens,
+		CostUSD:       usage.CostUSD,
+		Verdict:       string(verdict.Parse(reviewResult)),
+		Findings:      findings.Count(reviewResult),
+		Result:        reviewResult,
+	})
+	if err == nil && cfg.HistoryRetention > 0 {
+		var pruned int
+		pruned, err = store.Prune(time.Now().Add(-cfg.HistoryRetention))
+		if pruned > 0 {
+			slog.Info("保持期間を過ぎたレビュー履歴を削除しました。", "entries", pruned, "retention", cfg.HistoryRetention)
+		}
+	}
+	done(err)
+	if err != nil {
+		aggregate.FromContext(ctx).Degrade("history", fmt.Errorf("レビュー履歴の記録に失敗しました (%s): %w", cfg.HistoryFile, err))
+	}
+}
+
+// newReviewID は実行ごとに一意なレビューIDを採番します。
+// 時刻を先頭に置くことで、ID を並べた際に実行順となるようにしています。
+func newReviewID() string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return time.Now().Format("20060102-150405.000000")
+	}
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(suffix)
 }
@@ -0,0 +1,221 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"git-gemini-reviewer-go/internal/batch"
+	"git-gemini-reviewer-go/internal/builder"
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/findings"
+	"git-gemini-reviewer-go/internal/hooks"
+	"git-gemini-reviewer-go/internal/messages"
+	"git-gemini-reviewer-go/internal/runner"
+	"git-gemini-reviewer-go/internal/verdict"
+
+	"github.com/spf13/cobra"
+)
+
+// ReviewAllFlags は review-all コマンド固有のフラグを保持します。
+type ReviewAllFlags struct {
+	BatchFile   string // レビュー対象の一覧を記述したバッチ設定のパス
+	Concurrency int    // 同時にレビューするレビュー対象の件数
+	Format      string // 出力形式
+	Output      string // 報告の保存先のファイルのパス (空の場合は標準出力)
+}
+
+var reviewAllFlags ReviewAllFlags
+
+// defaultReviewAllConcurrency は、フラグとバッチ設定のいずれでも指定しない場合に同時にレビューする件数です。
+const defaultReviewAllConcurrency = 4
+
+// reviewAllCmd は、バッチ設定の複数のリポジトリを並行してレビューし、まとめた報告を出力するコマンドです。
+var reviewAllCmd = &cobra.Command{
+	Use:   "review-all",
+	Short: "バッチ設定の複数のリポジトリを並行してレビューし、まとめた報告を出力します。",
+	Long: `このコマンドは、--batch-file のバッチ設定 (estimate コマンドと共通) の各レビュー対象をクローンして並行してレビューし、
+レビュー対象ごとの判定の一覧と各レビュー結果の全文をまとめた報告を出力します。
+リリーストレインに含まれる複数のサービスのリリースレビューなどに使用します。
+
+同時にレビューする件数は --concurrency (未指定時はバッチ設定の concurrency、いずれもない場合は 4) です。
+同じクローン先を使うレビュー対象は順番にレビューします。一部のレビュー対象のレビューに失敗しても他のレビューは継続し、
+報告を出力した後にコマンドを失敗させます。`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{standaloneCommandAnnotation: "true"},
+	RunE:        runReviewAllCommand,
+}
+
+func init() {
+	reviewAllCmd.Flags().StringVar(&reviewAllFlags.BatchFile, "batch-file", "", "レビュー対象の一覧を記述したバッチ設定 (YAML) のパス。(必須)")
+	reviewAllCmd.Flags().IntVar(&reviewAllFlags.Concurrency, "concurrency", 0, "同時にレビューするレビュー対象の件数。未指定時はバッチ設定の concurrency を使用します")
+	reviewAllCmd.Flags().StringVar(&reviewAllFlags.Format, "format", "markdown", "出力形式: 'markdown' または 'json'")
+	reviewAllCmd.Flags().StringVarP(&reviewAllFlags.Output, "output", "o", "", "報告を保存するファイルのパス。未指定時は標準出力に出力します")
+}
+
+// --------------------------------------------------------------------------
+// コマンドの実行ロジック
+// --------------------------------------------------------------------------
+
+// runReviewAllCommand はコマンドの主要な実行ロジックを含みます。
+func runReviewAllCommand(cmd *cobra.Command, args []string) error {
+	if reviewAllFlags.BatchFile == "" {
+		return fmt.Errorf(`required flag(s) "batch-file" not set`)
+	}
+	format := strings.ToLower(reviewAllFlags.Format)
+	if format != "markdown" && format != "json" {
+		return fmt.Errorf("出力形式が不正です: '%s' ('markdown' または 'json' を指定してください)", reviewAllFlags.Format)
+	}
+	if reviewAllFlags.Concurrency < 0 {
+		return fmt.Errorf("--concurrency には1以上を指定してください")
+	}
+	// ベースラインはリポジトリごとに異なるため、1つのファイルを複数のレビュー対象で共有させない
+	if ReviewConfig.BaselineFile != "" {
+		return fmt.Errorf("--baseline-file は review-all コマンドでは指定できません")
+	}
+	file, err := batch.Load(reviewAllFlags.BatchFile)
+	if err != nil {
+		return err
+	}
+	concurrency := reviewAllFlags.Concurrency
+	if concurrency == 0 {
+		concurrency = file.Concurrency
+	}
+	if concurrency == 0 {
+		concurrency = defaultReviewAllConcurrency
+	}
+
+	// レビュー対象を必要とするコマンドと同様に、レビューの実行前に設定の誤りを検出する
+	ReviewConfig.Destination = cmd.Name()
+	if err := messages.Validate(runner.MessageOptions(ReviewConfig), ReviewConfig.Destination); err != nil {
+		return err
+	}
+	if ReviewConfig.Hooks, err = hooks.ParseAll(hookSpecs); err != nil {
+		return err
+	}
+
+	// 同じリポジトリのレビュー対象で Gemini のクライアントや SSH の認証を構築し直さないよう、実行の間は再利用します
+	cache := builder.NewCache()
+	defer cache.Close()
+	ctx := builder.ContextWithCache(cmd.Context(), cache)
+
+	slog.Info("複数リポジトリのレビューを開始します。", "targets", len(file.Targets), "concurrency", concurrency)
+	results := reviewTargets(ctx, file.Targets, concurrency)
+	summary := batch.NewSummary(results, time.Now())
+
+	if err := writeReviewAllSummary(cmd, format, summary); err != nil {
+		return err
+	}
+	if summary.Failed > 0 {
+		return fmt.Errorf("%d 件のレビュー対象のレビューに失敗しました", summary.Failed)
+	}
+	return nil
+}
+
+// --------------------------------------------------------------------------
+// ヘルパー関数
+// --------------------------------------------------------------------------
+
+// reviewTargets は、レビュー対象を最大 concurrency 件まで並行してレビューし、レビュー対象の順に結果を返します。
+// 同じローカルパスへのクローンが競合しないよう、同じクローン先を使うレビュー対象は順番にレビューします。
+func reviewTargets(ctx context.Context, targets []batch.Target, concurrency int) []batch.Result {
+	var (
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, concurrency)
+		locksMu sync.Mutex
+		locks   = make(map[string]*sync.Mutex)
+		results = make([]batch.Result, len(targets))
+	)
+	lockFor := func(path string) *sync.Mutex {
+		locksMu.Lock()
+		defer locksMu.Unlock()
+		if locks[path] == nil {
+			locks[path] = &sync.Mutex{}
+		}
+		return locks[path]
+	}
+
+	for i, t := range targets {
+		cfg := withDefaultLocalPath(t.Apply(ReviewConfig))
+		cfg.PatchFile, cfg.Worktree = "", ""
+		if len(cfg.GitTokenHosts) == 0 {
+			cfg.GitTokenHosts = repoURLHosts(cfg.RepoURL)
+		}
+		lock := lockFor(cfg.LocalPath)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lock.Lock()
+			defer lock.Unlock()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = reviewTarget(ctx, t.Label(), cfg)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// reviewTarget は1件のレビュー対象をレビューします。
+// 失敗した場合も他のレビュー対象のレビューを継続するため、エラーは結果に記録します。
+func reviewTarget(ctx context.Context, label string, cfg config.ReviewConfig) batch.Result {
+	cfg.ReviewID = newReviewID()
+	result := batch.Result{Name: label, RepoURL: cfg.RepoURL, FeatureBranch: cfg.FeatureBranch, ReviewID: cfg.ReviewID}
+	startedAt := time.Now()
+	defer func() { result.DurationMS = time.Since(startedAt).Milliseconds() }()
+
+	res, err := func() (pipelineResult, error) {
+		if err := assignPromptVariant(&cfg); err != nil {
+			return pipelineResult{}, err
+		}
+		trackers, err := resolveIssueTrackers(cfg.RepoURL)
+		if err != nil {
+			return pipelineResult{}, err
+		}
+		cfg.IssueTrackers = trackers
+		slog.Info("レビュー対象のレビューを開始します。", "target", label, "review_id", cfg.ReviewID)
+		return executeReviewPipeline(ctx, cfg)
+	}()
+	markdown := res.markdown
+	switch {
+	case err != nil:
+		slog.Error("レビュー対象のレビューに失敗しました。", "target", label, "review_id", cfg.ReviewID, "error", err)
+		result.Status, result.Error = batch.StatusFailed, err.Error()
+	case markdown == "":
+		result.Status = batch.StatusNoDiff
+	default:
+		result.Status = batch.StatusCompleted
+		result.Review = localizeHeadings("review-all", markdown)
+		result.Verdict = verdict.Parse(markdown)
+		result.Findings = len(findings.Extract(markdown))
+	}
+	return result
+}
+
+// writeReviewAllSummary は、報告を --output のファイル (未指定時は標準出力) に出力します。
+func writeReviewAllSummary(cmd *cobra.Command, format string, summary batch.Summary) error {
+	var content []byte
+	if format == "json" {
+		data, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return fmt.Errorf("報告のエンコードに失敗しました: %w", err)
+		}
+		content = append(data, '\n')
+	} else {
+		content = []byte(summary.Markdown())
+	}
+	if reviewAllFlags.Output == "" {
+		_, err := cmd.OutOrStdout().Write(content)
+		return err
+	}
+	if err := os.WriteFile(reviewAllFlags.Output, content, 0o644); err != nil {
+		return fmt.Errorf("報告のファイルへの書き込みに失敗しました (%s): %w", reviewAllFlags.Output, err)
+	}
+	slog.Info("複数リポジトリのレビューの報告を保存しました。", "path", reviewAllFlags.Output)
+	return nil
+}
@@ -4,14 +4,40 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"net/url"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
+	"git-gemini-reviewer-go/internal/aiprovider"
+	"git-gemini-reviewer-go/internal/chunk"
 	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/diffguard"
+	"git-gemini-reviewer-go/internal/difftransform"
+	"git-gemini-reviewer-go/internal/filecontext"
+	"git-gemini-reviewer-go/internal/gitclient"
+	"git-gemini-reviewer-go/internal/guidelines"
+	"git-gemini-reviewer-go/internal/headings"
+	"git-gemini-reviewer-go/internal/hooks"
+	"git-gemini-reviewer-go/internal/httpconfig"
+	"git-gemini-reviewer-go/internal/messages"
+	"git-gemini-reviewer-go/internal/modelchain"
+	"git-gemini-reviewer-go/internal/persona"
+	"git-gemini-reviewer-go/internal/prompttmpl"
+	"git-gemini-reviewer-go/internal/respcache"
+	"git-gemini-reviewer-go/internal/reviewignore"
+	"git-gemini-reviewer-go/internal/reviewmode"
+	"git-gemini-reviewer-go/internal/runner"
+	"git-gemini-reviewer-go/internal/sampling"
+	"git-gemini-reviewer-go/internal/tokencount"
+	"git-gemini-reviewer-go/internal/twopass"
 
 	"github.com/shouni/go-cli-base"
 	"github.com/shouni/go-http-kit/pkg/httpkit"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // ReviewConfig は、レビュー実行のパラメータです
@@ -19,6 +45,20 @@ var ReviewConfig config.ReviewConfig
 
 const defaultHTTPTimeout = 30 * time.Second
 
+// httpSettings は、外部サービスへのすべての HTTP 通信で共有するタイムアウト・プロキシ・TLS の設定です。
+var httpSettings httpconfig.Settings
+
+// notifyRetryBudget は Backlog や Slack への1回の投稿に許容する、リトライを含めた制限時間です。
+const notifyRetryBudget = 2 * time.Minute
+
+// standaloneCommandAnnotation が付与されたコマンド (およびそのサブコマンド) は、
+// レビュー対象のリポジトリやブランチの指定を必要としません。
+const standaloneCommandAnnotation = "standalone"
+
+// cleanupAnnotation は、--git-cleanup を指定しない場合のコマンドの既定のクリーンアップ方法です。
+// 付与されていないコマンドは gitclient.CleanupDelete を使用します。
+const cleanupAnnotation = "git-cleanup"
+
 // clientKey は context.Context に httpkit.Client を格納・取得するための非公開キー
 type clientKey struct{}
 
@@ -44,39 +84,408 @@ func initAppPreRunE(cmd *cobra.Command, args []string) error {
 	})
 	slog.SetDefault(slog.New(handler))
 
+	// プロファイルとポリシーパックは、明示的に指定されていないフラグの値を補完します
+	// プロファイルで指定した値は指定済みのフラグとして扱うため、ポリシーパックより優先されます
+	if err := applyProfile(cmd); err != nil {
+		return err
+	}
+	if err := applyPolicy(cmd); err != nil {
+		return err
+	}
+
+	if err := applyProviderDefaults(cmd); err != nil {
+		return err
+	}
+	if err := applyCleanupDefault(cmd); err != nil {
+		return err
+	}
+
+	// トークンがヘルプに表示されないよう、フラグの既定値ではなくここで環境変数から補完します
+	if ReviewConfig.GitHTTPToken == "" {
+		ReviewConfig.GitHTTPToken = os.Getenv("GIT_HTTP_TOKEN")
+	}
+	if ReviewConfig.GitHTTPUsername == "" {
+		ReviewConfig.GitHTTPUsername = os.Getenv("GIT_HTTP_USERNAME")
+	}
+	if ReviewConfig.SSHKeyPassphrase == "" {
+		ReviewConfig.SSHKeyPassphrase = os.Getenv("SSH_KEY_PASSPHRASE")
+	}
+	if ReviewConfig.CallbackSecret == "" {
+		ReviewConfig.CallbackSecret = os.Getenv("REVIEWER_CALLBACK_SECRET")
+	}
+	// 👍/👎 リンクは署名のないものを受け付けないため、署名の鍵がなければリンクを付与できません
+	ReviewConfig.FeedbackSecret = os.Getenv("REVIEWER_FEEDBACK_SECRET")
+	if ReviewConfig.FeedbackURL != "" && ReviewConfig.FeedbackSecret == "" {
+		return fmt.Errorf("--feedback-url を指定する場合は、リンクの署名に使用する環境変数 REVIEWER_FEEDBACK_SECRET を設定してください")
+	}
+
+	// レビュー対象を必要とするコマンドでのみ必須フラグを検証
+	if requiresReviewTarget(cmd) {
+		if err := validateGateFlags(); err != nil {
+			return err
+		}
+		if err := applyGerritChange(); err != nil {
+			return err
+		}
+		if err := applyGitHubAction(cmd); err != nil {
+			return err
+		}
+		if err := validateReviewTargetFlags(); err != nil {
+			return err
+		}
+		if err := validateBaselineFlags(); err != nil {
+			return err
+		}
+		if len(ReviewConfig.GitTokenHosts) == 0 {
+			ReviewConfig.GitTokenHosts = repoURLHosts(ReviewConfig.RepoURL)
+		}
+		trackers, err := resolveIssueTrackers(ReviewConfig.RepoURL)
+		if err != nil {
+			return err
+		}
+		ReviewConfig.IssueTrackers = trackers
+		if ReviewConfig.Hooks, err = hooks.ParseAll(hookSpecs); err != nil {
+			return err
+		}
+
+		// 定型メッセージのテンプレートは、レビューを実行する前に読み込めることを確認する
+		applyReviewLanguage(cmd)
+		ReviewConfig.Destination = cmd.Name()
+		if err := messages.Validate(runner.MessageOptions(ReviewConfig), ReviewConfig.Destination); err != nil {
+			return err
+		}
+	}
+	// 見出しの辞書は、レビューを実行する前に読み込めることを確認する
+	if err := initHeadingLocalizer(ReviewConfig); err != nil {
+		return err
+	}
+	ReviewConfig.ReviewID = newReviewID()
+	if requiresReviewTarget(cmd) {
+		if err := assignPromptVariant(&ReviewConfig); err != nil {
+			return err
+		}
+	}
+
 	// 2. HTTPクライアントの初期化
-	httpClient := httpkit.New(defaultHTTPTimeout)
+	// 既定のトランスポートにも設定を適用し、Gemini・GCS・Slack・Backlog の各クライアントに同じプロキシと TLS を使わせる
+	if err := httpSettings.Install(); err != nil {
+		return err
+	}
+	httpClient := httpkit.New(httpSettings.Timeout, httpkit.WithHTTPClient(newHTTPClient()))
 
 	// コマンドのコンテキストに HTTP Client を格納
 	ctx := context.WithValue(cmd.Context(), clientKey{}, httpClient)
+	ctx, err := withProgressEvents(ctx)
+	if err != nil {
+		return err
+	}
 	cmd.SetContext(ctx)
 
-	slog.Info("アプリケーション設定初期化完了", slog.String("mode", ReviewConfig.ReviewMode))
+	slog.Info("アプリケーション設定初期化完了", slog.String("mode", ReviewConfig.ReviewMode), slog.String("review_id", ReviewConfig.ReviewID))
 
 	return nil
 }
 
+// newHTTPClient は、共通の HTTP 設定 (タイムアウト・プロキシ・TLS) を適用した *http.Client を返します。
+func newHTTPClient() *http.Client {
+	return httpSettings.NewClient()
+}
+
+// requiresReviewTarget は、コマンドがレビュー対象の指定を必要とするかを判定します。
+func requiresReviewTarget(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if _, ok := c.Annotations[standaloneCommandAnnotation]; ok {
+			return false
+		}
+	}
+	return true
+}
+
+// flagAliases は、フラグの別名と正式な名前の対応です。
+var flagAliases = map[string]string{
+	"provider": "ai-provider",
+	"model":    "gemini",
+}
+
+// normalizeFlagAliases は、フラグの別名を正式な名前に読み替えます。
+func normalizeFlagAliases(_ *pflag.FlagSet, name string) pflag.NormalizedName {
+	if canonical, ok := flagAliases[name]; ok {
+		name = canonical
+	}
+	return pflag.NormalizedName(name)
+}
+
+// applyProviderDefaults は、AI プロバイダと --vertex の組み合わせを検証し、モデルが指定されていない場合にプロバイダの既定のモデルを設定します。
+// カンマ区切りのモデルは、先頭のモデルとフォールバックのモデルに分けます。応答の生成を調整するパラメータの範囲もここで検証します。
+// プロファイルやポリシーパックで指定したモデルは既定値より優先します。
+func applyProviderDefaults(cmd *cobra.Command) error {
+	provider, err := aiprovider.Lookup(ReviewConfig.AIProvider)
+	if err != nil {
+		return err
+	}
+	if ReviewConfig.Vertex && ReviewConfig.AIProvider != "" && ReviewConfig.AIProvider != aiprovider.Gemini {
+		return fmt.Errorf("--vertex は --ai-provider %s でのみ使用できます", aiprovider.Gemini)
+	}
+	if f := cmd.Flags().Lookup("gemini"); f != nil && !f.Changed && ReviewConfig.GeminiModel == f.DefValue {
+		ReviewConfig.GeminiModel = provider.DefaultModel
+	}
+	// カンマ区切りで指定された2番目以降のモデルは、先頭のモデルが失敗した場合のフォールバックとする
+	ReviewConfig.GeminiModel, ReviewConfig.FallbackModels = modelchain.Split(ReviewConfig.GeminiModel)
+	return sampling.Params{
+		Temperature:     ReviewConfig.AITemperature,
+		TopP:            ReviewConfig.AITopP,
+		MaxOutputTokens: ReviewConfig.AIMaxOutputTokens,
+	}.Validate()
+}
+
+// applyCleanupDefault は、--git-cleanup が未指定の場合にコマンドの既定のクリーンアップ方法を設定し、値を検証します。
+func applyCleanupDefault(cmd *cobra.Command) error {
+	if ReviewConfig.GitCleanup == "" {
+		ReviewConfig.GitCleanup = string(gitclient.CleanupDelete)
+		for c := cmd; c != nil; c = c.Parent() {
+			if strategy, ok := c.Annotations[cleanupAnnotation]; ok {
+				ReviewConfig.GitCleanup = strategy
+				break
+			}
+		}
+	}
+	_, err := gitclient.ParseCleanupStrategy(ReviewConfig.GitCleanup)
+	return err
+}
+
+// validateBaselineFlags は、ベースラインのフラグの組み合わせを検証します。
+// ベースラインは構造化された指摘を照合するため、指定時は構造化された指摘でレビューします。
+func validateBaselineFlags() error {
+	if ReviewConfig.BaselineFile == "" {
+		if ReviewConfig.WriteBaseline {
+			return fmt.Errorf("--write-baseline には --baseline-file の指定が必要です")
+		}
+		return nil
+	}
+	if ReviewConfig.SplitModules {
+		return fmt.Errorf("--split-modules と --baseline-file は同時に指定できません")
+	}
+	if ReviewConfig.Stream {
+		return fmt.Errorf("--stream と --baseline-file は同時に指定できません")
+	}
+	ReviewConfig.InlineFindings = true
+	return nil
+}
+
+// validateReviewTargetFlags は、レビュー対象の指定に必須のフラグが設定されているか検証します。
+// パッチファイルや作業ツリーをレビューする場合、リポジトリとブランチの指定は不要です。
+func validateReviewTargetFlags() error {
+	if ReviewConfig.PatchFile != "" && ReviewConfig.Worktree != "" {
+		return fmt.Errorf("--patch-file と --worktree は同時に指定できません")
+	}
+	// テンプレートの誤りは、レビューの実行時ではなく起動時に検出する
+	templates, err := prompttmpl.Load(ReviewConfig.PromptFile, ReviewConfig.PromptDir)
+	if err != nil {
+		return err
+	}
+	var customModes []string
+	if templates != nil {
+		customModes = templates.Modes()
+	}
+	if err := reviewmode.Validate(ReviewConfig.ReviewMode, customModes...); err != nil {
+		return err
+	}
+	if len(reviewmode.Split(ReviewConfig.ReviewMode)) > 1 && ReviewConfig.Stream {
+		return fmt.Errorf("複数のレビューモードと --stream は同時に指定できません")
+	}
+	if _, err := filecontext.Parse(ReviewConfig.FileContext); err != nil {
+		return err
+	}
+	if ReviewConfig.FileContextMaxBytes < 1 {
+		return fmt.Errorf("--file-context-max-bytes には1以上を指定してください")
+	}
+	if ReviewConfig.IncludeCommitLog && ReviewConfig.CommitLogMax < 1 {
+		return fmt.Errorf("--commit-log-max には1以上を指定してください")
+	}
+	if ReviewConfig.TwoPass {
+		if ReviewConfig.DeepDiveFiles < 1 {
+			return fmt.Errorf("--deep-dive-files には1以上を指定してください")
+		}
+		if len(reviewmode.Split(ReviewConfig.ReviewMode)) > 1 {
+			return fmt.Errorf("複数のレビューモードと --two-pass は同時に指定できません")
+		}
+		if ReviewConfig.SplitModules {
+			return fmt.Errorf("--split-modules と --two-pass は同時に指定できません")
+		}
+	}
+	if len(ReviewConfig.FeatureBranches) > 0 {
+		if err := validateFeatureBranchesFlags(); err != nil {
+			return err
+		}
+	}
+	if ReviewConfig.Worktree != "" {
+		if _, err := gitclient.ParseWorktreeChanges(ReviewConfig.WorktreeChanges); err != nil {
+			return err
+		}
+		return nil
+	}
+	if ReviewConfig.PatchFile != "" {
+		return nil
+	}
+	var missing []string
+	if ReviewConfig.RepoURL == "" {
+		missing = append(missing, `"repo-url"`)
+	}
+	if ReviewConfig.FeatureBranch == "" && ReviewConfig.FeatureRev == "" && len(ReviewConfig.FeatureBranches) == 0 {
+		missing = append(missing, `"feature-branch"`)
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("required flag(s) %s not set", strings.Join(missing, ", "))
+	}
+	if len(ReviewConfig.Stack) > 0 && ReviewConfig.BaseRev != "" {
+		return fmt.Errorf("--stack と --base-rev は同時に指定できません")
+	}
+	if ReviewConfig.SinceLastReview {
+		if ReviewConfig.BaseRev != "" {
+			return fmt.Errorf("--since-last-review と --base-rev は同時に指定できません")
+		}
+		if ReviewConfig.HistoryFile == "" {
+			return fmt.Errorf("--since-last-review には --history-file の指定が必要です")
+		}
+	}
+	// 履歴や投稿の見出しでレビュー対象を示せるよう、ブランチの指定がない場合はリビジョンを使用する
+	if ReviewConfig.FeatureBranch == "" {
+		ReviewConfig.FeatureBranch = ReviewConfig.FeatureRev
+	}
+	return nil
+}
+
 // --- フラグ設定ロジック ---
 
+// repoURLHosts は、HTTP(S) のリポジトリURL (または URL の接頭辞) のホストを返します。
+// --git-token-host が未指定の場合に、トークンを送信するホストとして使用します。
+func repoURLHosts(repoURLs ...string) []string {
+	var hosts []string
+	for _, repoURL := range repoURLs {
+		u, err := url.Parse(repoURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			continue
+		}
+		if !slices.Contains(hosts, u.Host) {
+			hosts = append(hosts, u.Host)
+		}
+	}
+	return hosts
+}
+
 // addAppPersistentFlags は、アプリケーション固有の永続フラグをルートコマンドに追加します。
 func addAppPersistentFlags(rootCmd *cobra.Command) {
 	// ReviewConfig.ReviewMode にバインド
-	rootCmd.PersistentFlags().StringVarP(&ReviewConfig.ReviewMode, "mode", "m", "detail", "レビューモードを指定: 'release' (リリース判定) または 'detail' (詳細レビュー)")
-	rootCmd.PersistentFlags().StringVarP(&ReviewConfig.RepoURL, "repo-url", "u", "", "レビュー対象の Git リポジトリの SSH URL。")
-	rootCmd.MarkPersistentFlagRequired("repo-url")
+	rootCmd.PersistentFlags().StringVarP(&ReviewConfig.ReviewMode, "mode", "m", "detail", "レビューモードを指定: 'detail' (詳細レビュー)、'release' (リリース判定)、'security' (セキュリティ監査)、'performance' (パフォーマンス)、'test-coverage' (テストの十分さ)、'api-compat' (API 互換性)。カンマ区切りで組み合わせると (例: 'security,detail')、モードごとにレビューしてセクションにまとめます。")
+	rootCmd.PersistentFlags().StringSliceVar(&ReviewConfig.Personas, "persona", nil, fmt.Sprintf("レビューモードに重ねるレビュアーペルソナをカンマ区切りで指定 %v", persona.Names()))
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.PromptFile, "prompt-file", "", "組み込みのテンプレートの代わりに、すべてのレビューモードで使用するプロンプトテンプレート (text/template 形式) のパス。{{.DiffContent}} (必須)、{{.RepoURL}}、{{.BaseBranch}}、{{.FeatureBranch}}、{{.Mode}}、{{.Commits}} を参照できます。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.PromptDir, "prompt-dir", "", "レビューモードごとのプロンプトテンプレート ('<モード>.tmpl'、例: detail.tmpl) を置いたディレクトリ。テンプレートのないレビューモードは組み込みのテンプレートを使用します。--prompt-file と同時には指定できません。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.PromptVariantB, "prompt-variant-b", "", "プロンプトの A/B 実験で B に使用するテンプレート (text/template 形式、{{.DiffContent}} で差分を参照) のパス。A は組み込みのテンプレートです。")
+	rootCmd.PersistentFlags().IntVar(&ReviewConfig.PromptSplit, "prompt-split", 50, "プロンプトの A/B 実験で B に割り当てるレビューの割合 (0〜100)。リポジトリとブランチから決定的に振り分けます。")
+	rootCmd.PersistentFlags().StringVarP(&ReviewConfig.RepoURL, "repo-url", "u", "", "レビュー対象の Git リポジトリの SSH URL (CodeCommit の場合は codecommit::<region>://<repository> も可)。(必須)")
 	rootCmd.PersistentFlags().StringVarP(&ReviewConfig.BaseBranch, "base-branch", "b", "main", "差分比較の基準ブランチ (例: 'main').")
-	rootCmd.PersistentFlags().StringVarP(&ReviewConfig.FeatureBranch, "feature-branch", "f", "", "レビュー対象のフィーチャーブランチ (例: 'feature/my-branch').")
-	rootCmd.MarkPersistentFlagRequired("feature-branch")
-	rootCmd.PersistentFlags().StringVarP(&ReviewConfig.LocalPath, "local-path", "l", "", "リポジトリをクローンするローカルパス。")
-	rootCmd.PersistentFlags().StringVarP(&ReviewConfig.GeminiModel, "gemini", "g", "gemini-2.5-flash", "レビューに使用する Gemini モデル名 (例: 'gemini-2.5-flash').")
-	rootCmd.PersistentFlags().StringVarP(&ReviewConfig.SSHKeyPath, "ssh-key-path", "k", "~/.ssh/id_rsa", "Git 認証に使用する SSH 秘密鍵のパス。")
+	rootCmd.PersistentFlags().StringVarP(&ReviewConfig.FeatureBranch, "feature-branch", "f", "", "レビュー対象のフィーチャーブランチ (例: 'feature/my-branch'). (必須)")
+	rootCmd.PersistentFlags().StringSliceVar(&ReviewConfig.FeatureBranches, "feature-branches", nil, "同じベースブランチに対してレビューする複数のフィーチャーブランチ (カンマ区切り。'release/*' のようなグロブも可)。1つのクローンとフェッチを再利用し、ブランチごとにレビュー結果を投稿します。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.BaseRev, "base-rev", "", "ブランチの代わりに差分の基準とするリビジョン (コミットの SHA、タグ、'main~3' など)。'HEAD' はベースブランチの最新のコミットです。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.BaselineFile, "baseline-file", "", "既存の指摘を記録したベースラインファイルのパス。指定時は構造化された指摘でレビューし、ベースラインにない新しい指摘のみを報告します。")
+	rootCmd.PersistentFlags().BoolVar(&ReviewConfig.WriteBaseline, "write-baseline", false, "現在の指摘で --baseline-file のベースラインを作成 (または書き換え) します。このとき指摘は除外せずにすべて報告します。")
+	rootCmd.PersistentFlags().BoolVar(&ReviewConfig.SinceLastReview, "since-last-review", false, "--history-file に同じフィーチャーブランチの前回のレビューがある場合、前回レビューしたコミット以降の差分のみをレビューします。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.FeatureRev, "feature-rev", "", "ブランチの代わりにレビュー対象とするリビジョン。指定時は --feature-branch は不要です。")
+	rootCmd.PersistentFlags().StringVarP(&ReviewConfig.LocalPath, "local-path", "l", "", "リポジトリをクローンするローカルパス。未指定時はキャッシュディレクトリの下のリポジトリURLごとのディレクトリを使用します。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.CacheDir, "cache-dir", "", "--local-path を指定しない場合にリポジトリをクローンするキャッシュディレクトリ。未指定時はユーザーのキャッシュディレクトリの下の 'git-gemini-reviewer' (Linux では ~/.cache/git-gemini-reviewer) です。")
+	rootCmd.PersistentFlags().IntVar(&ReviewConfig.CacheMaxMB, "cache-max-mb", 0, "キャッシュディレクトリのクローンの合計サイズの上限 (MiB)。超えた場合はレビューの後に最も長く使われていないクローンから削除します (直近1時間以内に使用したクローンは除く)。0 は無制限です。")
+	rootCmd.PersistentFlags().StringVarP(&ReviewConfig.GeminiModel, "gemini", "g", "gemini-2.5-flash", "レビューに使用するモデル名 (例: 'gemini-2.5-flash'、'gpt-4o')。カンマ区切りで複数指定すると (例: 'gemini-2.5-pro,gemini-2.5-flash')、先頭のモデルがレート制限・サーバーエラー・コンテキスト長の超過で失敗した場合に次のモデルで再試行します。--model でも指定できます。未指定で --ai-provider が 'gemini' 以外の場合は、プロバイダの既定のモデルを使用します。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.AIProvider, "ai-provider", aiprovider.Gemini, "レビューに使用する AI (--provider でも指定可): 'gemini'、'openai' (OpenAI 互換の Chat Completions API。環境変数 OPENAI_API_KEY または AZURE_OPENAI_API_KEY)、'ollama' (ローカルの Ollama サーバー。差分を外部に送信しません)、'stub' (ネットワークに接続せず、差分の統計から決定的な結果を生成します。CI やデモ向け)")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.AIBaseURL, "ai-base-url", "", "AI の API のベース URL (環境変数 OPENAI_BASE_URL でも指定可)。Azure OpenAI (例: 'https://<リソース>.openai.azure.com/openai/deployments/<デプロイ>?api-version=2024-10-21') や社内の OpenAI 互換ゲートウェイに接続する場合に指定します。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.OllamaURL, "ollama-url", "", "--ai-provider ollama で使用する Ollama サーバーの URL (環境変数 OLLAMA_HOST でも指定可)。未指定時は 'http://localhost:11434' です。")
+	rootCmd.PersistentFlags().IntVar(&ReviewConfig.OllamaNumCtx, "ollama-num-ctx", 0, "Ollama に指定するコンテキスト長 (num_ctx)。0 はプロンプトの長さから自動で決めます。Ollama は超えたプロンプトを警告なく切り詰めるため、既定値は使用しません。")
+	rootCmd.PersistentFlags().Float64Var(&ReviewConfig.AITemperature, "ai-temperature", sampling.DefaultTemperature, "AI の応答の温度 (0〜2)。低いほど同じ差分に対して同じ結果になりやすく、高いほど多様な指摘が得られます。")
+	rootCmd.PersistentFlags().Float64Var(&ReviewConfig.AITopP, "ai-top-p", 0, "AI の応答の top-p (0 より大きく 1 以下)。0 はプロバイダの既定値を使用します。")
+	rootCmd.PersistentFlags().IntVar(&ReviewConfig.AIMaxOutputTokens, "ai-max-output-tokens", 0, "AI の応答の最大トークン数。上限に達した応答は途中で打ち切られます。0 はプロバイダの既定値を使用します。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.ResponseCache, "response-cache", "", "AI の応答のキャッシュの保存先。ディレクトリのパス、または 'redis://[:パスワード@]ホスト[:ポート][/DB番号]' ('rediss://' は TLS) を指定します。同じモデル・プロンプト・差分の再レビューでは、AI を呼び出さずに保存した応答を返します。")
+	rootCmd.PersistentFlags().DurationVar(&ReviewConfig.ResponseCacheTTL, "response-cache-ttl", respcache.DefaultTTL, "AI の応答をキャッシュに保存する期間")
+	rootCmd.PersistentFlags().BoolVar(&ReviewConfig.NoCache, "no-cache", false, "--response-cache が指定されていても、キャッシュを使用せずに AI を呼び出します。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.PricingFile, "pricing-file", "", "推定費用の計算に使用するモデルごとの料金表 (YAML または JSON) のパス。組み込みの料金表に重ねて使用します。形式は '<モデル名>: {input_per_million: <USD>, output_per_million: <USD>}' です。")
+	rootCmd.PersistentFlags().BoolVar(&ReviewConfig.UsageTrailer, "usage-trailer", false, "投稿するレビュー結果の末尾に、トークン数と推定費用を付与します。")
+	rootCmd.PersistentFlags().BoolVar(&ReviewConfig.Vertex, "vertex", false, "Gemini を API キーの代わりに Application Default Credentials (ADC) またはサービスアカウントで認証し、Vertex AI のエンドポイント経由で呼び出します。--vertex-project と --vertex-location が必要です。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.VertexProject, "vertex-project", "", "Vertex AI を使用する GCP のプロジェクトID (環境変数 GOOGLE_CLOUD_PROJECT でも指定可)")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.VertexLocation, "vertex-location", "", "Vertex AI でリクエストを処理させるリージョン (例: 'asia-northeast1') または 'global' (環境変数 GOOGLE_CLOUD_LOCATION でも指定可)。リージョンを指定すると、そのリージョンのエンドポイントで処理されます。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.VertexCredentials, "vertex-credentials", "", "Vertex AI の認証に使用するサービスアカウントキー (JSON) のパス。未指定時は ADC (GOOGLE_APPLICATION_CREDENTIALS、gcloud のログイン、メタデータサーバー) を使用します。")
+	rootCmd.SetGlobalNormalizationFunc(normalizeFlagAliases)
+	rootCmd.PersistentFlags().StringVarP(&ReviewConfig.SSHKeyPath, "ssh-key-path", "k", "~/.ssh/id_rsa", "Git 認証に使用する SSH 秘密鍵のパス。空文字列の場合、またはパスフレーズで保護された鍵の場合は ssh-agent を使用します。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.SSHKeyPassphrase, "ssh-key-passphrase", "", "パスフレーズで保護された SSH 秘密鍵のパスフレーズ (環境変数 SSH_KEY_PASSPHRASE でも指定可)。未指定時は ssh-agent を使用し、ssh-agent がなく端末から実行している場合は入力を求めます。")
+	rootCmd.PersistentFlags().BoolVar(&ReviewConfig.UseSSHAgent, "use-ssh-agent", false, "SSH 秘密鍵のファイルを使わず、ssh-agent (SSH_AUTH_SOCK) に読み込まれた鍵で認証します。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.PatchFile, "patch-file", "", "Git リポジトリにアクセスせず、指定した unified diff ファイルをレビューします ('-' で標準入力)。指定時は --repo-url と --feature-branch は不要です。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.Worktree, "worktree", "", "リモートにアクセスせず、指定したローカルリポジトリ (例: '.') のコミットされていない変更をレビューします。指定時は --repo-url と --feature-branch は不要です。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.WorktreeChanges, "worktree-changes", string(gitclient.WorktreeAll), "--worktree でレビューする変更: 'staged' (ステージ済み)、'unstaged' (未ステージと未追跡のファイル)、'all' (HEAD からのすべての変更)")
+	rootCmd.PersistentFlags().IntVar(&ReviewConfig.MaxFiles, "max-files", 0, "レビュー対象とする変更ファイル数の上限。超えた場合はリスクの高いファイルを優先し、除外したファイルを結果に列挙します。0 は無制限です。")
+	rootCmd.PersistentFlags().IntVar(&ReviewConfig.MaxHunks, "max-hunks", 0, "レビュー対象とするハンク数の上限。0 は無制限です。")
+	rootCmd.PersistentFlags().IntVar(&ReviewConfig.ChunkTokens, "chunk-tokens", chunk.DefaultMaxTokens, "1回のリクエストに含める差分のトークン数 (概算) の上限。超える場合はファイル単位 (必要に応じてハンク単位) に分割してレビューし、最後に結果を統合します。0 は分割しません。")
+	rootCmd.PersistentFlags().IntVar(&ReviewConfig.MaxInputTokens, "max-input-tokens", 0, "1回のリクエストで AI に送信するプロンプトの入力トークン数の上限。超える場合は差分を分割し直してレビューし (--chunk-tokens 0 の場合は送信を中止します)、0 は無制限です。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.TokenCounter, "token-counter", tokencount.MethodAPI, "送信前にプロンプトの入力トークン数を数える方法: 'api' (Gemini の countTokens API。失敗時は概算) または 'estimate' (ネットワークに接続せずバイト長から概算)")
+	rootCmd.PersistentFlags().StringSliceVar(&ReviewConfig.Stack, "stack", nil, "スタックされたブランチをトランクに近い順に指定します (カンマ区切り。例: 'main,feature/a,feature/b')。フィーチャーブランチを直近の親ブランチと比較し、下位の層を再レビューしません。")
+	rootCmd.PersistentFlags().StringSliceVar(&ReviewConfig.Excludes, "exclude", nil, "レビュー対象から除外するファイルのパターン (カンマ区切り。例: 'vendor/,*.pb.go,docs/**/*.png')。")
+	rootCmd.PersistentFlags().StringSliceVar(&ReviewConfig.Omits, "omit", diffguard.DefaultOmitPatterns, "変更されたことのみを伝え、内容をプロンプトから省略するファイルのパターン (カンマ区切り。--exclude と同じ書式)。バイナリファイルは常に省略します。空文字列を指定すると無効になります。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.ReviewIgnoreFile, "review-ignore-file", reviewignore.DefaultFileName, "レビュー対象から除外するファイルを .gitignore の書式で記述した、リポジトリのルートからのパス。ベースブランチの内容を使用します。空文字列で無効になります。")
+	rootCmd.PersistentFlags().StringSliceVar(&ReviewConfig.GuidelinesFiles, "guidelines-file", guidelines.DefaultFiles, "プロジェクトのルールとしてプロンプトに添える規約ファイルの候補 (カンマ区切り、リポジトリのルートからのパス)。先に見つかったファイルのベースブランチの内容を使用します。空文字列で無効になります。")
+	rootCmd.PersistentFlags().StringSliceVar(&ReviewConfig.CriticalPaths, "critical-path", nil, "重要なパスのパターン (カンマ区切り。例: 'auth/**,payments/**')。一致するファイルへの指摘は重大度と判定を1段階引き上げ、レビュー結果の冒頭で強調します。")
+	rootCmd.PersistentFlags().StringSliceVar(&ReviewConfig.DiffTransforms, "diff-transform", difftransform.DefaultNames, "差分に順に適用する変換器 (カンマ区切り): 'exclude' (--exclude の適用), 'omit' (バイナリと --omit のファイルの内容の省略), 'redact' (秘匿情報のマスク), 'normalize' (改行コードの正規化), 'exec:コマンド', 'plugin:パス.so'")
+	rootCmd.PersistentFlags().BoolVar(&ReviewConfig.SplitModules, "split-modules", false, "モノレポ向けに、go.mod や package.json などのモジュール境界ごとに差分を分割し、モジュール単位でレビュー結果と判定を出力します。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.GitHTTPToken, "git-token", "", "HTTPS のリポジトリURLにアクセスするためのアクセストークン (環境変数 GIT_HTTP_TOKEN でも指定可)")
+	rootCmd.PersistentFlags().StringSliceVar(&ReviewConfig.GitTokenHosts, "git-token-host", nil, "--git-token を送信するホスト (カンマ区切り)。未指定時は --repo-url のホスト (serve と slack-app では --allowed-repo の HTTPS のURLのホスト) にのみ送信します")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.GitHTTPUsername, "git-username", "", "--git-token と組み合わせるユーザー名 (環境変数 GIT_HTTP_USERNAME でも指定可)。未指定時は 'git' を使用します")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.Submodules, "submodules", string(gitclient.SubmodulePointer), "差分に含まれるサブモジュールの参照先の変更の扱い: 'pointer' (参照先のコミットの変更のみ)、'log' (サブモジュールをクローンし、変更に含まれるコミットの件名をプロンプトに添える)、'diff' (さらにサブモジュール自身の差分をレビュー対象に含める)")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.FileContext, "file-context", "none", "変更されたファイルの内容をプロンプトに添え、差分の外側のコードも踏まえてレビューさせます: 'none' (添えない)、'full' (ファイル全体)、行数 N (変更箇所の前後 N 行)。除外・省略したファイルと --file-context-max-bytes を超えるファイルは添えず、--max-input-tokens に収まらないファイルは省略します。")
+	rootCmd.PersistentFlags().IntVar(&ReviewConfig.FileContextMaxBytes, "file-context-max-bytes", filecontext.DefaultMaxBytes, "--file-context で内容を添えるファイルの最大サイズ (バイト)。超えるファイルは添えません。")
+	rootCmd.PersistentFlags().BoolVar(&ReviewConfig.IncludeCommitLog, "include-commit-log", false, "差分に含まれるコミットの作者・日時・件名・本文の一覧をプロンプトに添え、コミットメッセージに書かれた意図と実装が一致しているかも確認させます。パッチファイルと作業ツリーのレビューでは無視します。")
+	rootCmd.PersistentFlags().IntVar(&ReviewConfig.CommitLogMax, "commit-log-max", 20, "--include-commit-log でプロンプトに添えるコミットの最大件数。超えた場合は新しいコミットから指定した件数を添えます。")
+	rootCmd.PersistentFlags().BoolVar(&ReviewConfig.TwoPass, "two-pass", false, "2段階でレビューします。1回目で変更全体の概要とファイルのリスクの順位を求め、2回目でリスクの高い上位 --deep-dive-files 件のファイルのみを詳しくレビューし、1つのレポートにまとめます。巨大な差分でトークン数を抑えます。")
+	rootCmd.PersistentFlags().IntVar(&ReviewConfig.DeepDiveFiles, "deep-dive-files", twopass.DefaultTopFiles, "--two-pass の2回目に詳しくレビューするファイルの件数。ファイル数がこの値以下の場合は1回目を省略します。")
+	rootCmd.PersistentFlags().BoolVar(&ReviewConfig.InMemoryRepo, "in-memory-repo", false, "リポジトリを --local-path ではなくメモリ上にクローンし、ワークツリーを作成せずに差分を求めます。ディスクの小さい CI のコンテナ向けで、--git-cleanup の後処理は行いません。")
+	rootCmd.PersistentFlags().BoolVar(&ReviewConfig.FetchAll, "fetch-all", false, "リモートのすべてのブランチをフェッチします。未指定時はベースブランチとレビュー対象のブランチのみをフェッチします (--base-rev / --feature-rev / --stack の指定時はすべてのブランチ)。")
+	rootCmd.PersistentFlags().IntVar(&ReviewConfig.CloneDepth, "clone-depth", 0, "クローンとフェッチで取得する履歴の深さ (各ブランチの先頭からのコミット数)。巨大なリポジトリのクローンを高速化します。マージベースが取得した履歴に含まれない場合は、履歴をすべて含むクローンに切り替えます。0 の場合はすべての履歴を取得します。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.GitCleanup, "git-cleanup", "", "レビュー後のローカルリポジトリの後処理: 'delete' (ディレクトリを削除)、'reset' (ワークツリーをベースブランチに戻し、クローンを次回に再利用)、'none' (何もせずに残す)。未指定時はコマンドごとの既定値で、slack-app は 'reset'、それ以外は 'delete' です。")
 	rootCmd.PersistentFlags().BoolVar(&ReviewConfig.SkipHostKeyCheck, "skip-host-key-check", false, "【🚨 危険な設定】 SSH ホストキーの検証を無効にします。中間者攻撃のリスクを劇的に高めるため、本番環境では絶対に使用しないでください。開発/テスト環境でのみ使用してください。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.FailOn, "fail-on", "", "投稿後、レビューの判定または指摘の重大度がこのしきい値に達した場合にコマンドを失敗させます: 'blocked' (リリース不可)、'conditional' (条件付きリリース可以上)、または重大度 'critical'、'major'、'minor' (構造化された指摘にその重大度以上の指摘がある場合)")
+	rootCmd.PersistentFlags().IntVar(&ReviewConfig.MaxFindings, "max-findings", -1, "投稿後、指摘の件数がこの値を超えた場合にコマンドを失敗させます。構造化された指摘では抑制された指摘を数えません。-1 は無制限です。")
+	rootCmd.PersistentFlags().StringSliceVar(&ReviewConfig.RequiredChecks, "require-check", nil, "満たされない場合に投稿後コマンドを失敗させるチェック (カンマ区切り): 'tests' (本番コードの変更にテストの変更を伴う), 'docs' (ドキュメントの変更を伴う)")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "設定ファイルから適用するプロファイルの名前 (例: 'backend')。明示的に指定したフラグが優先されます。")
+	rootCmd.PersistentFlags().StringVar(&policyName, "policy", "", "適用するポリシーパックの名前または YAML ファイルのパス (例: 'backend-default')。明示的に指定したフラグが優先されます。")
+	rootCmd.PersistentFlags().StringVar(&policyDir, "policy-dir", defaultPolicyDir(), "ポリシーパックを名前で検索するディレクトリ (環境変数 GEMINI_REVIEWER_POLICY_DIR でも指定可)")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.SkipMarker, "skip-marker", "[skip ai-review]", "コミットメッセージに含まれる場合にAIレビューをスキップするマーカー。空文字列で無効化します。")
+	rootCmd.PersistentFlags().StringSliceVar(&ReviewConfig.PRLabels, "pr-labels", nil, "プルリクエストに付与されたラベル (カンマ区切り)。CI から渡します。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.SkipLabel, "skip-label", "skip-ai-review", "--pr-labels に含まれる場合にAIレビューをスキップするラベル。")
+	rootCmd.PersistentFlags().BoolVar(&ReviewConfig.NotifyNoDiff, "notify-no-diff", false, "差分がない場合にも、その旨の定型メッセージを投稿先に送信します。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.MessageTemplateDir, "message-template-dir", "", "差分なし・スキップ時の定型メッセージを上書きするテンプレートのディレクトリ ('no-diff.md', 'skipped.md'。投稿先ごとに 'no-diff.slack.md' のように指定可)")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.MessageLang, "message-lang", messages.DefaultLang, "定型メッセージの組み込みテンプレートの言語: 'ja' または 'en'")
+	rootCmd.PersistentFlags().StringSliceVar(&ReviewConfig.HeadingLangs, "heading-lang", nil, "レビュー結果の見出しの言語 (カンマ区切り): 'en' (すべての投稿先) または '投稿先=言語' (例: 'github=en,backlog=ja')。投稿先はコマンド名と post の投稿先の名前です。未指定時は日本語のままです。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.ReviewLanguage, "review-language", headings.DefaultLang, "AI に記述させるレビュー結果の言語 (例: 'ja'、'en')。'ja' 以外の場合は出力言語をプロンプトで指示し、--heading-lang と --message-lang を指定していなければ見出しと定型メッセージもこの言語にします。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.HeadingTranslations, "heading-translations", "", "見出しの辞書を言語ごとに記述した YAML ファイルのパス (例: 'en: {総評: Overview}')。組み込みの辞書に重ね、新しい言語も追加できます。")
+	rootCmd.PersistentFlags().IntVar(&ReviewConfig.AIRequestsPerMinute, "ai-qpm", 0, "Gemini への1分あたりの最大リクエスト数。0 は無制限です。")
+	rootCmd.PersistentFlags().IntVar(&ReviewConfig.AITokensPerMinute, "ai-tpm", 0, "Gemini への1分あたりの最大入力トークン数 (概算)。0 は無制限です。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.RateLimitStateFile, "rate-limit-state", "", "レート制限の状態を複数プロセスで共有するファイルのパス。未指定時はプロセス内でのみ共有します。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.CallbackURL, "callback-url", "", "パイプラインの完了時に、最終的なレビュー結果 (状態・判定・本文) を JSON で POST するエンドポイント。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.CallbackSecret, "callback-secret", "", "コールバックの HMAC-SHA256 署名 (X-Reviewer-Signature ヘッダ) に使用するシークレット (環境変数 REVIEWER_CALLBACK_SECRET でも指定可)")
+	rootCmd.PersistentFlags().DurationVar(&httpSettings.Timeout, "http-timeout", defaultHTTPTimeout, "外部サービス (Slack, Backlog, GitHub など) への1回の HTTP リクエストの制限時間。")
+	rootCmd.PersistentFlags().StringVar(&httpSettings.ProxyURL, "http-proxy", "", "外部サービスへの HTTP 通信に使用するプロキシのURL (例: 'http://proxy.example.com:8080')。未指定時は環境変数 HTTPS_PROXY / HTTP_PROXY / NO_PROXY に従います。")
+	rootCmd.PersistentFlags().StringVar(&httpSettings.CABundle, "ca-bundle", "", "システムの証明書に加えて信頼する CA 証明書 (PEM) のファイルのパス。TLS インスペクションを行うプロキシの証明書などを指定します。")
+	rootCmd.PersistentFlags().StringVar(&httpSettings.TLSMinVersion, "tls-min-version", "", "外部サービスとの通信で許可する TLS の最小バージョン: '1.2' または '1.3'。未指定時は Go の既定値です。")
+	rootCmd.PersistentFlags().StringVar(&progressEvents, "progress-events", "", "パイプラインの段階の遷移を JSON Lines で出力する先: 'stderr' またはファイルのパス。CI のラッパーなどが人向けのログを解析せずに進捗を表示するために使用します。")
+	rootCmd.PersistentFlags().StringArrayVar(&hookSpecs, "hook", nil, "パイプラインの段階で実行するフック ('段階=コマンド' または '段階=plugin:パス.so')。段階は 'pre-diff', 'post-review', 'pre-post'。レビューの文脈を JSON で標準入力に渡します。複数指定可。")
+	rootCmd.PersistentFlags().StringArrayVar(&issueLinkSpecs, "issue-link", nil, "課題キーをリンクに変換するトラッカーとURLテンプレート (例: 'jira:ABC|DEF=https://example.atlassian.net/browse/{key}', 'github=https://github.com/org/repo/issues/{number}')。複数指定可。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.FeedbackURL, "feedback-url", "", "👍/👎 フィードバック受付エンドポイントのベースURL (例: 'https://reviewer.example.com')。指定時は投稿に環境変数 REVIEWER_FEEDBACK_SECRET で署名したフィードバックリンクを付与します。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.ArchiveURI, "archive-uri", "", "監査用にプロンプト・AIの生レスポンス・メタデータを保存する先 (例: 'gs://bucket/prefix/')。秘匿情報はマスクされます。")
+	rootCmd.PersistentFlags().StringVar(&ReviewConfig.HistoryFile, "history-file", "", "レビューの実行履歴 (判定・結果) を記録する JSON Lines ファイルのパス、GCS のプレフィックス (gs://バケット/プレフィックス)、またはデータベースのURL (sqlite://パス、postgres://...)。未指定時は記録しません。")
+	rootCmd.PersistentFlags().DurationVar(&ReviewConfig.HistoryRetention, "history-retention", 0, "レビュー履歴を保持する期間 (例: 2160h)。記録のたびに、これより古いレビューと承認判断を削除します。0 の場合は削除しません。")
+	rootCmd.PersistentFlags().BoolVar(&ReviewConfig.FollowUp, "follow-up", true, "--history-file に同じフィーチャーブランチの前回のレビューがある場合、その指摘が対応済みかを確認するセクションを出力させます。")
 }
 
 // --- エントリポイント ---
 
 // Execute は、clibase.Execute を使用してルートコマンドの構築と実行を委譲します。
 func Execute() {
+	withFeatureBranches(genericCmd, backlogCmd, slackCmd, teamsCmd, discordCmd, webhookCmd, gcsCmd, fileCmd, postCmd, gerritCmd, codeCommitCmd, githubCmd, bitbucketCmd)
+	withFailureReport(genericCmd, backlogCmd, slackCmd, teamsCmd, discordCmd, webhookCmd, gcsCmd, fileCmd, postCmd, gerritCmd, codeCommitCmd, githubCmd, bitbucketCmd)
 	clibase.Execute(
 		"git-gemini-reviewer-go",
 		addAppPersistentFlags,
@@ -84,6 +493,26 @@ func Execute() {
 		genericCmd,
 		backlogCmd,
 		slackCmd,
+		teamsCmd,
+		discordCmd,
+		webhookCmd,
 		gcsCmd,
+		fileCmd,
+		postCmd,
+		gerritCmd,
+		codeCommitCmd,
+		githubCmd,
+		bitbucketCmd,
+		slackAppCmd,
+		serveCmd,
+		reviewAllCmd,
+		digestCmd,
+		trendsCmd,
+		historyCmd,
+		cacheCmd,
+		feedbackCmd,
+		selftestCmd,
+		schemaCmd,
+		estimateCmd,
 	)
 }
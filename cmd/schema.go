@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"git-gemini-reviewer-go/internal/history"
+	"git-gemini-reviewer-go/internal/schema"
+
+	"github.com/spf13/cobra"
+)
+
+// schemaCmd は、レビュー結果の JSON Schema を出力するコマンドです。
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "レビュー結果の JSON Schema を出力します。",
+	Long: fmt.Sprintf(`このコマンドは、完了コールバックのペイロードとレビュー履歴に共通のレビュー結果の JSON Schema (バージョン %d) を標準出力に出力します。
+コールバック・フック・配信レポート・監査アーカイブ・レビュー履歴の JSON には schema_version が含まれます。
+古いバージョンで記録したレビュー履歴は 'schema migrate' で現在のバージョンに移行できます。
+generic コマンドの --format json の出力の JSON Schema は 'schema findings' で出力できます。`, schema.Version),
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{standaloneCommandAnnotation: "true"},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := cmd.OutOrStdout().Write(schema.ReviewResult())
+		return err
+	},
+}
+
+// schemaMigrateCmd は、レビュー履歴を現在のスキーマのバージョンに移行するコマンドです。
+var schemaMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "レビュー履歴を現在のスキーマのバージョンに移行します。",
+	Long: `このコマンドは、--history-file (未指定時は既定の履歴ファイル) のうち古いスキーマのバージョンで記録された行を、現在のバージョンの形式に書き換えます。
+読み込み時にも同じ移行を行うため、本ツールから利用する場合は必須ではありません。履歴ファイルを直接読む下流の処理のために実行します。`,
+	Args: cobra.NoArgs,
+	RunE: runSchemaMigrateCommand,
+}
+
+// schemaFindingsCmd は、generic コマンドの --format json の出力の JSON Schema を出力するコマンドです。
+var schemaFindingsCmd = &cobra.Command{
+	Use:   "findings",
+	Short: "--format json で出力する構造化されたレビュー結果の JSON Schema を出力します。",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := cmd.OutOrStdout().Write(schema.ReviewFindings())
+		return err
+	},
+}
+
+func init() {
+	schemaCmd.AddCommand(schemaMigrateCmd)
+	schemaCmd.AddCommand(schemaFindingsCmd)
+}
+
+// runSchemaMigrateCommand はコマンドの主要な実行ロジックを含みます。
+func runSchemaMigrateCommand(cmd *cobra.Command, args []string) error {
+	path := ReviewConfig.HistoryFile
+	if path == "" {
+		path = history.DefaultPath()
+	}
+	migrated, err := history.NewStore(path).Migrate()
+	if err != nil {
+		return fmt.Errorf("レビュー履歴の移行に失敗しました: %w", err)
+	}
+	slog.Info("レビュー履歴を移行しました。", "path", path, "migrated", migrated, "schema_version", schema.Version)
+	return nil
+}
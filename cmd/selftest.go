@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/aggregate"
+	"git-gemini-reviewer-go/internal/builder"
+	"git-gemini-reviewer-go/internal/selftest"
+
+	"github.com/spf13/cobra"
+)
+
+// --- コマンド固有のフラグ変数 ---
+var (
+	selftestCases        []string
+	selftestFormat       string
+	selftestMinRecall    float64
+	selftestMinPrecision float64
+)
+
+// selftestCmd は、組み込みのゴールデンコーパスをレビューさせ、レビュー品質を採点するコマンドです。
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "既知の指摘を含む合成差分をレビューさせ、現在のモデルとプロンプトの再現率・適合率を測定します。",
+	Long: `このコマンドは、期待される指摘が既知の合成差分 (ゴールデンコーパス) を実際のパイプラインでレビューさせ、
+抽出した指摘を期待値と照合して再現率 (recall) と適合率 (precision) を出力します。
+--gemini や --mode、--persona を変えて実行することで、モデルやプロンプトテンプレートの更新による品質の変化を確認できます。
+
+--min-recall / --min-precision を指定すると、しきい値を下回った場合にコマンドを失敗させるため、CI でのゲートとして利用できます。
+Git リポジトリにはアクセスせず、Gemini API のみを使用します。`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{standaloneCommandAnnotation: "true"},
+	RunE:        runSelftestCommand,
+}
+
+func init() {
+	selftestCmd.Flags().StringSliceVar(&selftestCases, "case", nil, "実行するケースの名前 (カンマ区切り)。未指定時はすべてのケースを実行します")
+	selftestCmd.Flags().StringVar(&selftestFormat, "format", "markdown", "出力形式: 'markdown' または 'json'")
+	selftestCmd.Flags().Float64Var(&selftestMinRecall, "min-recall", 0, "再現率がこの値 (0〜1) を下回った場合にコマンドを失敗させます")
+	selftestCmd.Flags().Float64Var(&selftestMinPrecision, "min-precision", 0, "適合率がこの値 (0〜1) を下回った場合にコマンドを失敗させます")
+}
+
+// runSelftestCommand はコマンドの主要な実行ロジックを含みます。
+func runSelftestCommand(cmd *cobra.Command, args []string) error {
+	format := strings.ToLower(selftestFormat)
+	if format != "markdown" && format != "json" {
+		return fmt.Errorf("出力形式が不正です: '%s' ('markdown' または 'json' を指定してください)", selftestFormat)
+	}
+	if selftestMinRecall < 0 || selftestMinRecall > 1 || selftestMinPrecision < 0 || selftestMinPrecision > 1 {
+		return fmt.Errorf("--min-recall と --min-precision には 0 から 1 の値を指定してください")
+	}
+
+	corpus, err := selftest.Corpus()
+	if err != nil {
+		return err
+	}
+	cases, err := selftest.Filter(corpus, selftestCases)
+	if err != nil {
+		return err
+	}
+
+	// ケースごとに Gemini のクライアントや PromptBuilder を構築し直さないよう、実行の間は再利用します
+	cache := builder.NewCache()
+	defer cache.Close()
+	report := selftest.Run(builder.ContextWithCache(cmd.Context(), cache), ReviewConfig.GeminiModel, cases, reviewSelftestCase)
+
+	if format == "json" {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else if _, err := fmt.Fprint(cmd.OutOrStdout(), report.Markdown()); err != nil {
+		return err
+	}
+
+	if report.Recall < selftestMinRecall {
+		return fmt.Errorf("再現率 %.3f がしきい値 %.3f を下回りました", report.Recall, selftestMinRecall)
+	}
+	if report.Precision < selftestMinPrecision {
+		return fmt.Errorf("適合率 %.3f がしきい値 %.3f を下回りました", report.Precision, selftestMinPrecision)
+	}
+	return nil
+}
+
+// reviewSelftestCase は、ケースの差分をパッチファイルとしてレビューパイプラインに渡します。
+// モデルとプロンプトの品質のみを測定するため、リポジトリ固有の設定や履歴への記録は無効にします。
+func reviewSelftestCase(ctx context.Context, c selftest.Case) (string, error) {
+	f, err := os.CreateTemp("", "selftest-*.diff")
+	if err != nil {
+		return "", fmt.Errorf("セルフテスト用の一時ファイルの作成に失敗しました: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(c.Diff); err != nil {
+		f.Close()
+		return "", fmt.Errorf("セルフテスト用の一時ファイルへの書き込みに失敗しました: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("セルフテスト用の一時ファイルへの書き込みに失敗しました: %w", err)
+	}
+
+	cfg := ReviewConfig
+	cfg.PatchFile = f.Name()
+	cfg.ReviewMode = c.Mode
+	cfg.RepoURL = "selftest"
+	cfg.FeatureBranch = c.Name
+	cfg.ReviewID = newReviewID()
+	if err := assignPromptVariant(&cfg); err != nil {
+		return "", err
+	}
+	cfg.Stack = nil
+	cfg.CriticalPaths = nil
+	cfg.PRLabels = nil
+	cfg.SkipMarker = ""
+	cfg.SplitModules = false
+	cfg.HistoryFile = ""
+	cfg.ArchiveURI = ""
+	cfg.Hooks = nil
+
+	reviewRunner, err := builder.BuildReviewRunner(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+	result, err := reviewRunner.Run(ctx, cfg)
+	if pe, ok := aggregate.AsPipelineError(err); ok && !pe.Fatal() {
+		// 後処理の失敗はレビュー結果の採点に影響しないため無視します
+		err = nil
+	}
+	return result, err
+}
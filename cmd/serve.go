@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"git-gemini-reviewer-go/internal/builder"
+	"git-gemini-reviewer-go/internal/feedback"
+	"git-gemini-reviewer-go/internal/gitclient"
+	"git-gemini-reviewer-go/internal/github"
+	"git-gemini-reviewer-go/internal/hooks"
+	"git-gemini-reviewer-go/internal/messages"
+	"git-gemini-reviewer-go/internal/runner"
+	"git-gemini-reviewer-go/internal/server"
+
+	"github.com/spf13/cobra"
+)
+
+// ServeFlags は serve コマンド固有のフラグを保持します。
+type ServeFlags struct {
+	Addr         string        // 待ち受けアドレス
+	AllowedRepos []string      // レビューを受け付けるリポジトリURLの接頭辞
+	RunTimeout   time.Duration // 1件のレビューに許容する最大時間
+	MaxJobs      int           // メモリに保持する終了済みのジョブの件数
+	GitHubClone  string        // GitHub の Webhook から依頼されたリポジトリのクローンに使用するプロトコル
+	Workers      int           // 同時に実行するレビューの件数
+	QueueSize    int           // 実行を待機できるジョブの件数
+	// InsecureNoAuth が true の場合、REVIEWER_SERVER_TOKEN が未設定でもループバック以外のアドレスで待ち受けます
+	InsecureNoAuth bool
+	// FeedbackStore は 👍/👎 リンクからの投票を記録する JSON Lines ファイルのパスです
+	FeedbackStore string
+}
+
+var serveFlags ServeFlags
+
+// serveCmd は、レビューを非同期に実行する HTTP API のサーバーを起動します。
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "レビューを非同期に実行する HTTP API のサーバーを起動します。",
+	Long: `このコマンドは、社内のレビューサービスとして常駐する HTTP API のサーバーを起動します。
+
+  POST /review     {"repo_url": "...", "feature_branch": "...", "base_branch": "...", "mode": "..."}
+                   レビューを受け付け、ジョブID (job_id) と実行状況のURL (status_url) を返します (202 Accepted)
+  GET  /jobs       ワーカーの稼働状況 (待機中・実行中の件数) とジョブの一覧を返します ('?status=running' で絞り込み)
+  GET  /jobs/{id}  ジョブの状態 (queued / running / completed / no-diff / failed) と、終了していればレビュー結果を返します
+  GET  /feedback   投稿の 👍/👎 リンク (--feedback-url にこのサーバーのURLを指定) から投票の確認ページを返し、
+                   POST で投票を記録します (環境変数 REVIEWER_FEEDBACK_SECRET を設定した場合のみ有効)
+  POST /webhooks/github
+                   GitHub の pull_request イベント (opened / synchronize / reopened) を受け付け、
+                   プルリクエストのブランチをレビューして結果をプルリクエストのレビューとして投稿します
+                   (環境変数 GITHUB_WEBHOOK_SECRET と GITHUB_TOKEN を設定した場合のみ有効)
+
+依頼に含まれない設定 (モデル・プロンプト・投稿先以外のフラグ) はコマンドラインの指定を使用します。
+レビューは --workers 件まで並行して実行し、同じクローン先を使うリポジトリのレビューは順番に実行します。
+待機中のジョブが --queue-size 件に達している間は、新しい依頼を 503 Service Unavailable で拒否します。
+環境変数 REVIEWER_SERVER_TOKEN を設定すると、'Authorization: Bearer <token>' ヘッダを必須にします。
+REVIEWER_SERVER_TOKEN が未設定の場合は、ループバックアドレス (例: 127.0.0.1:8080) でのみ起動します。
+認証なしで公開する場合は --insecure-no-auth を指定してください。
+repo_url には file:// やローカルパスは指定できません。`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{standaloneCommandAnnotation: "true", cleanupAnnotation: string(gitclient.CleanupReset)},
+	RunE:        runServeCommand,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveFlags.Addr, "addr", ":8080", "API の待ち受けアドレス")
+	serveCmd.Flags().StringSliceVar(&serveFlags.AllowedRepos, "allowed-repo", nil, "レビューを受け付けるリポジトリURLの接頭辞 (カンマ区切り)。未指定時はすべて受け付けます")
+	serveCmd.Flags().DurationVar(&serveFlags.RunTimeout, "run-timeout", 30*time.Minute, "1件のレビューに許容する最大時間")
+	serveCmd.Flags().IntVar(&serveFlags.MaxJobs, "max-jobs", 1000, "メモリに保持する終了済みのジョブの件数。超えた場合は古いジョブから破棄します")
+	serveCmd.Flags().IntVar(&serveFlags.Workers, "workers", 1, "同時に実行するレビューの件数")
+	serveCmd.Flags().IntVar(&serveFlags.QueueSize, "queue-size", 100, "実行を待機できるジョブの件数。超えた依頼は 503 で拒否します")
+	serveCmd.Flags().BoolVar(&serveFlags.InsecureNoAuth, "insecure-no-auth", false, "REVIEWER_SERVER_TOKEN が未設定でも、ループバック以外のアドレスで認証なしの API を起動します")
+	serveCmd.Flags().StringVar(&serveFlags.FeedbackStore, "feedback-store", defaultFeedbackStorePath(), "👍/👎 リンクからの投票を保存する JSON Lines ファイルのパス")
+	serveCmd.Flags().StringVar(&serveFlags.GitHubClone, "github-clone", server.CloneSSH, "GitHub の Webhook から依頼されたリポジトリのクローンに使用するプロトコル: 'ssh' または 'https'")
+}
+
+// runServeCommand はコマンドの主要な実行ロジックを含みます。
+func runServeCommand(cmd *cobra.Command, args []string) error {
+	if serveFlags.RunTimeout <= 0 || serveFlags.MaxJobs <= 0 || serveFlags.Workers <= 0 || serveFlags.QueueSize <= 0 {
+		return fmt.Errorf("--run-timeout、--max-jobs、--workers、--queue-size には正の値を指定してください")
+	}
+	ReviewConfig.Destination = cmd.Name()
+	if err := messages.Validate(runner.MessageOptions(ReviewConfig), ReviewConfig.Destination); err != nil {
+		return err
+	}
+	hookList, err := hooks.ParseAll(hookSpecs)
+	if err != nil {
+		return err
+	}
+	ReviewConfig.Hooks = hookList
+	if len(serveFlags.AllowedRepos) == 0 {
+		slog.Warn("--allowed-repo が未指定のため、任意のリポジトリのレビューを受け付けます。")
+	}
+	if serveFlags.GitHubClone != server.CloneSSH && serveFlags.GitHubClone != server.CloneHTTPS {
+		return fmt.Errorf("不明なクローンのプロトコルです: '%s' ('%s' または '%s' を指定してください)", serveFlags.GitHubClone, server.CloneSSH, server.CloneHTTPS)
+	}
+	token := os.Getenv("REVIEWER_SERVER_TOKEN")
+	if token == "" {
+		if !serveFlags.InsecureNoAuth && !isLoopbackAddr(serveFlags.Addr) {
+			return fmt.Errorf("REVIEWER_SERVER_TOKEN が未設定のため、ループバック以外のアドレス '%s' では起動できません。トークンを設定するか、--addr 127.0.0.1:8080 のようにループバックアドレスを指定してください (認証なしで公開する場合は --insecure-no-auth)", serveFlags.Addr)
+		}
+		slog.Warn("REVIEWER_SERVER_TOKEN が未設定のため、API の認証を行いません。信頼できるネットワークでのみ公開してください。")
+	}
+	applyServerGitTokenHosts(serveFlags.AllowedRepos)
+	githubSecret, githubToken := os.Getenv("GITHUB_WEBHOOK_SECRET"), os.Getenv("GITHUB_TOKEN")
+	if githubSecret != "" && githubToken == "" {
+		return fmt.Errorf("GitHub の Webhook を受け付けるには、環境変数 GITHUB_TOKEN (pull_requests: write 権限) が必須です")
+	}
+
+	// 依頼ごとに Gemini のクライアントや SSH の認証を構築し直さないよう、プロセスの間は再利用します
+	cache := builder.NewCache()
+	defer cache.Close()
+
+	handler := server.NewHandler(
+		newServeRunner(githubToken),
+		server.WithAllowedRepoPrefixes(serveFlags.AllowedRepos...),
+		server.WithBaseContext(builder.ContextWithCache(cmd.Context(), cache)),
+		server.WithRunTimeout(serveFlags.RunTimeout),
+		server.WithMaxFinishedJobs(serveFlags.MaxJobs),
+		server.WithWorkers(serveFlags.Workers),
+		server.WithQueueSize(serveFlags.QueueSize),
+		server.WithLockKey(serveLockKey),
+		server.WithToken(token),
+		server.WithGitHubWebhook(githubSecret, serveFlags.GitHubClone),
+		server.WithFeedback(feedback.NewStore(serveFlags.FeedbackStore), ReviewConfig.FeedbackSecret),
+	)
+	mux := http.NewServeMux()
+	handler.Register(mux)
+
+	slog.Info("レビュー API を起動します。", "addr", serveFlags.Addr, "review", server.ReviewPath, "jobs", server.JobsPath, "workers", serveFlags.Workers, "queue_size", serveFlags.QueueSize)
+	if ReviewConfig.FeedbackSecret != "" {
+		slog.Info("👍/👎 リンクからの投票を受け付けます。", "path", feedback.HandlerPath, "store", serveFlags.FeedbackStore)
+	} else {
+		slog.Info("REVIEWER_FEEDBACK_SECRET が未設定のため、👍/👎 リンクからの投票は受け付けません。")
+	}
+	if githubSecret != "" {
+		slog.Info("GitHub の Webhook を受け付けます。", "path", server.GitHubWebhookPath, "clone", serveFlags.GitHubClone)
+	}
+	srv := &http.Server{Addr: serveFlags.Addr, Handler: mux, ReadHeaderTimeout: defaultHTTPTimeout}
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("レビュー API の起動に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// isLoopbackAddr は、待ち受けアドレスのホストがループバックアドレスかどうかを判定します。
+// ホストを省略したアドレス (例: ':8080') はすべてのインターフェースで待ち受けるため、ループバックではありません。
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// applyServerGitTokenHosts は、--git-token-host が未指定の場合に、--allowed-repo の HTTPS のURLのホストをトークンの送信先にします。
+// 依頼されたリポジトリのホストにトークンが漏れないよう、送信先を決められない場合はトークンを使用しません。
+func applyServerGitTokenHosts(allowedRepos []string) {
+	if len(ReviewConfig.GitTokenHosts) == 0 {
+		ReviewConfig.GitTokenHosts = repoURLHosts(allowedRepos...)
+	}
+	if ReviewConfig.GitHTTPToken != "" && len(ReviewConfig.GitTokenHosts) == 0 {
+		slog.Warn("--git-token-host が未指定で --allowed-repo に HTTPS のURLもないため、--git-token は使用しません。")
+	}
+}
+
+// newServeRunner は、API の依頼をコマンドラインの設定に重ねてレビューを実行する関数を返します。
+// GitHub の Webhook から依頼された場合は、レビュー結果をプルリクエストのレビューとして投稿します。
+// 同じローカルパスへのクローンが競合しないよう、同じリポジトリのレビューは serveLockKey によって順番に実行されます。
+func newServeRunner(githubToken string) server.RunFunc {
+	return func(ctx context.Context, req server.Request) (server.Result, error) {
+		cfg := ReviewConfig
+		cfg.RepoURL = req.RepoURL
+		cfg.FeatureBranch = req.FeatureBranch
+		if req.BaseBranch != "" {
+			cfg.BaseBranch = req.BaseBranch
+		}
+		if req.Mode != "" {
+			cfg.ReviewMode = req.Mode
+		}
+		// リポジトリごとにクローン先を分けるため、URL からローカルパスを生成させます
+		cfg.LocalPath = ""
+		cfg.ReviewID = newReviewID()
+		if err := assignPromptVariant(&cfg); err != nil {
+			return server.Result{ReviewID: cfg.ReviewID}, err
+		}
+
+		trackers, err := resolveIssueTrackers(cfg.RepoURL)
+		if err != nil {
+			return server.Result{ReviewID: cfg.ReviewID}, err
+		}
+		cfg.IssueTrackers = trackers
+
+		slog.Info("API から依頼されたレビューを開始します。", "review_id", cfg.ReviewID, "repo", cfg.RepoURL, "branch", cfg.FeatureBranch)
+		res, err := executeReviewPipeline(ctx, cfg)
+		markdown := res.markdown
+		result := server.Result{ReviewID: cfg.ReviewID, Markdown: localizeHeadings("serve", markdown)}
+		if err != nil || markdown == "" || req.GitHub == nil {
+			return result, err
+		}
+		return result, postServeResultToGitHub(ctx, githubToken, *req.GitHub, cfg.ReviewID, markdown)
+	}
+}
+
+// serveLockKey は、依頼のレビューが使用するクローン先のローカルパスを返します。
+// 同じパスを使う依頼は同時に実行しません。
+func serveLockKey(req server.Request) string {
+	cfg := ReviewConfig
+	cfg.RepoURL = req.RepoURL
+	cfg.LocalPath = ""
+	return withDefaultLocalPath(cfg).LocalPath
+}
+
+// postServeResultToGitHub は、GitHub の Webhook から依頼されたレビューの結果をプルリクエストに投稿します。
+func postServeResultToGitHub(ctx context.Context, token string, pr server.GitHubPullRequest, reviewID, reviewResult string) error {
+	repo, err := github.ParseRepo(pr.Repo)
+	if err != nil {
+		return err
+	}
+	permalink, err := postToGitHub(ctx, token, repo, pr.Number, reviewID, reviewResult, nil)
+	if err != nil {
+		return fmt.Errorf("GitHub のプルリクエスト %s#%d へのレビュー投稿に失敗しました: %w", repo, pr.Number, err)
+	}
+	slog.Info("レビュー結果を GitHub に投稿しました。", "url", permalink, "head", pr.HeadSHA)
+	return nil
+}
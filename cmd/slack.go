@@ -7,6 +7,11 @@ import (
 	"log/slog"
 	"os"
 
+	"git-gemini-reviewer-go/internal/builder"
+	"git-gemini-reviewer-go/internal/feedback"
+	"git-gemini-reviewer-go/internal/msgfit"
+	"git-gemini-reviewer-go/internal/pkg/retry"
+
 	"github.com/shouni/go-notifier/pkg/factory"
 	"github.com/spf13/cobra"
 )
@@ -32,7 +37,7 @@ var (
 var slackCmd = &cobra.Command{
 	Use:   "slack",
 	Short: "コードレビューを実行し、その結果をSlackの指定されたチャンネルに投稿します。",
-	RunE:  runSlackCommand,
+	RunE:  withReviewGate(runSlackCommand),
 }
 
 func init() {
@@ -44,45 +49,46 @@ func init() {
 // --------------------------------------------------------------------------
 
 // runSlackCommand はコマンドの主要な実行ロジックを含みます。
-func runSlackCommand(cmd *cobra.Command, args []string) error {
+func runSlackCommand(cmd *cobra.Command, args []string) (pipelineResult, error) {
 	ctx := cmd.Context()
 
 	// 1. Slack 連携に必要な環境変数を取得し、構造体にまとめる
 	authInfo := getSlackAuthInfo()
 
 	if authInfo.WebhookURL == "" {
-		return fmt.Errorf("SLACK_WEBHOOK_URL 環境変数の設定が必須です。")
+		return pipelineResult{}, fmt.Errorf("SLACK_WEBHOOK_URL 環境変数の設定が必須です。")
 	}
 
 	// 2. パイプラインを実行し、結果を受け取る
-	reviewResult, err := executeReviewPipeline(cmd.Context(), ReviewConfig)
+	res, err := executeReviewPipeline(cmd.Context(), ReviewConfig)
 	if err != nil {
-		return err
+		return res, err
 	}
+	reviewResult := res.markdown
 
 	if reviewResult == "" {
 		slog.Warn("レビュー結果の内容が空のため、Slackへのメッセージ投稿ををスキップします。")
-		return nil
+		return res, nil
 	}
 
 	// 3. no-post フラグによる出力分岐
 	if noPostSlack {
-		printReviewResult(reviewResult)
-		return nil
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		return res, nil
 	}
 
 	// 4. Slack投稿処理を実行
 	err = postToSlack(ctx, reviewResult, authInfo)
 	if err != nil {
 		// 投稿失敗時: エラーログとレビュー結果の出力順序は適切
-		printReviewResult(reviewResult) // レビュー結果を標準出力 (fmt.Println)
+		printReviewResult(ReviewConfig.Destination, reviewResult) // レビュー結果を標準出力 (fmt.Println)
 		slog.Error("Slackへのメッセージ投稿に失敗しました。", "error", err)
 
-		return fmt.Errorf("Slack へのメッセージ投稿に失敗しました。詳細はログを確認してください。")
+		return res, fmt.Errorf("Slack へのメッセージ投稿に失敗しました。詳細はログを確認してください。")
 	}
 
 	slog.Info("レビュー結果を Slack に投稿しました。")
-	return nil
+	return res, nil
 }
 
 // --------------------------------------------------------------------------
@@ -106,30 +112,42 @@ func postToSlack(
 	content string,
 	authInfo slackAuthInfo,
 ) error {
+	// ヘッダー文字列の作成 (ブランチ情報を結合)
+	title := fmt.Sprintf(
+		localizeTitle("slack", "AIコードレビュー結果 (ブランチ: `%s` ← `%s`)"),
+		ReviewConfig.BaseBranch,
+		ReviewConfig.FeatureBranch,
+	)
+
+	// フィードバックリンクを本文末尾に付与
+	content = localizeHeadings("slack", content) + feedback.Footer(ReviewConfig.FeedbackURL, ReviewConfig.FeedbackSecret, ReviewConfig.ReviewID)
+
+	return sendSlackText(ctx, title, content, authInfo)
+}
+
+// sendSlackText は、見出し付きのテキストを Slack Webhook に投稿します。
+func sendSlackText(ctx context.Context, title, content string, authInfo slackAuthInfo) error {
 	// 1. Contextから httpkit.Client を取得 (cmd/root.go の関数を使用)
 	httpClient, err := GetHTTPClient(ctx)
 	if err != nil {
 		slog.Error("🚨 HTTP Clientの取得に失敗しました", "error", err)
-		return fmt.Errorf("HTTP Clientの取得に失敗しました: %w", err) // エラーを返す
+		return retry.Permanent(fmt.Errorf("HTTP Clientの取得に失敗しました: %w", err)) // エラーを返す
 	}
 
-	// httpClient を使用して依存性を注入
-	slackClient, err := factory.GetSlackClient(httpClient)
+	// httpClient を使用して依存性を注入する。slack-app や serve では同じ Webhook URL のクライアントを再利用する
+	slackClient, err := builder.SlackClient(ctx, authInfo.WebhookURL, func() (builder.SlackSender, error) {
+		return factory.GetSlackClient(httpClient)
+	})
 	if err != nil {
 		slog.Error("🚨 Slackクライアントの初期化に失敗しました", "error", err)
-		return fmt.Errorf("Slackクライアントの初期化に失敗しました: %w", err) // エラーを返す
+		return retry.Permanent(fmt.Errorf("Slackクライアントの初期化に失敗しました: %w", err)) // エラーを返す
 	}
 
-	// slogへ移行
-	slog.Info("Slack Webhook URL にレビュー結果を投稿します...", "channel", authInfo.Channel)
-
-	// ヘッダー文字列の作成 (ブランチ情報を結合)
-	title := fmt.Sprintf(
-		"AIコードレビュー結果 (ブランチ: `%s` ← `%s`)",
-		ReviewConfig.BaseBranch,
-		ReviewConfig.FeatureBranch,
-	)
+	slog.Info("Slack Webhook URL に投稿します...", "channel", authInfo.Channel)
 
-	// SendTextWithHeader は content を整形し、ヘッダー情報を含めて投稿する
-	return slackClient.SendTextWithHeader(ctx, title, content)
+	// SendTextWithHeader は上限を超える本文を切り捨てるため、セクションに収まるメッセージに分割して投稿する
+	_, err = postParts(ctx, "slack.send_text", content, msgfit.Slack, func(ctx context.Context, part string) (string, error) {
+		return "", slackClient.SendTextWithHeader(ctx, title, part)
+	})
+	return err
 }
@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+
+	"git-gemini-reviewer-go/internal/builder"
+	"git-gemini-reviewer-go/internal/gitclient"
+	"git-gemini-reviewer-go/internal/history"
+	"git-gemini-reviewer-go/internal/hooks"
+	"git-gemini-reviewer-go/internal/messages"
+	"git-gemini-reviewer-go/internal/runner"
+	"git-gemini-reviewer-go/internal/slackapp"
+
+	"github.com/spf13/cobra"
+)
+
+// --- コマンド固有のフラグ変数 ---
+var (
+	slackAppAddr         string
+	slackAppAllowedRepos []string
+	slackAppApproval     bool
+)
+
+// slackAppCmd は、Slack のスラッシュコマンドとショートカットからレビューを起動するサーバーを起動します。
+var slackAppCmd = &cobra.Command{
+	Use:   "slack-app",
+	Short: "Slack のスラッシュコマンド (/ai-review) とショートカットからレビューを起動するサーバーを起動します。",
+	Long: `このコマンドは Slack アプリのエンドポイントを起動し、開発者が Slack からセルフサービスでレビューを依頼できるようにします。
+
+  スラッシュコマンド: /ai-review <リポジトリURL> <ブランチ> [基準ブランチ] [mode=release|detail]
+  ショートカット:     コールバックID 'ai_review' のグローバルショートカットから入力モーダルを開きます
+
+レビューはバックグラウンドで1件ずつ実行され、進捗と結果はスレッドに返信されます。
+環境変数 SLACK_SIGNING_SECRET と SLACK_BOT_TOKEN (chat:write, commands スコープ) が必要です。`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{standaloneCommandAnnotation: "true", cleanupAnnotation: string(gitclient.CleanupReset)},
+	RunE:        runSlackAppCommand,
+}
+
+func init() {
+	slackAppCmd.Flags().StringVar(&slackAppAddr, "addr", ":8080", "Slack アプリのエンドポイントの待ち受けアドレス")
+	slackAppCmd.Flags().BoolVar(&slackAppApproval, "approval", false, "レビュー結果に承認・修正依頼のボタンを付与し、人の判断をAIの判定とともに履歴に記録します")
+	slackAppCmd.Flags().StringSliceVar(&slackAppAllowedRepos, "allowed-repo", nil, "レビューを受け付けるリポジトリURLの接頭辞 (カンマ区切り)。未指定時はすべて受け付けます")
+}
+
+// runSlackAppCommand はコマンドの主要な実行ロジックを含みます。
+func runSlackAppCommand(cmd *cobra.Command, args []string) error {
+	signingSecret := os.Getenv("SLACK_SIGNING_SECRET")
+	botToken := os.Getenv("SLACK_BOT_TOKEN")
+	if signingSecret == "" || botToken == "" {
+		return fmt.Errorf("Slack アプリには環境変数 SLACK_SIGNING_SECRET および SLACK_BOT_TOKEN が必須です")
+	}
+	ReviewConfig.Destination = cmd.Name()
+	if err := messages.Validate(runner.MessageOptions(ReviewConfig), ReviewConfig.Destination); err != nil {
+		return err
+	}
+	hookList, err := hooks.ParseAll(hookSpecs)
+	if err != nil {
+		return err
+	}
+	ReviewConfig.Hooks = hookList
+	if len(slackAppAllowedRepos) == 0 {
+		slog.Warn("--allowed-repo が未指定のため、Slack から任意のリポジトリのレビューを受け付けます。")
+	}
+	applyServerGitTokenHosts(slackAppAllowedRepos)
+
+	// 依頼ごとに Gemini のクライアントや SSH の認証を構築し直さないよう、プロセスの間は再利用します
+	cache := builder.NewCache()
+	defer cache.Close()
+
+	opts := []slackapp.Option{
+		slackapp.WithAllowedRepoPrefixes(slackAppAllowedRepos...),
+		slackapp.WithBaseContext(builder.ContextWithCache(cmd.Context(), cache)),
+	}
+	if slackAppApproval {
+		// 人の判断をAIの判定と突き合わせられるよう、レビュー結果も同じ履歴ファイルに記録します
+		if ReviewConfig.HistoryFile == "" {
+			ReviewConfig.HistoryFile = history.DefaultPath()
+		}
+		slog.Info("承認ワークフローを有効にします。", "history", ReviewConfig.HistoryFile)
+		opts = append(opts, slackapp.WithApproval(history.NewStore(ReviewConfig.HistoryFile)))
+	}
+
+	handler := slackapp.NewHandler(
+		newHTTPClient(),
+		signingSecret,
+		botToken,
+		newSlackAppRunner(),
+		opts...,
+	)
+	mux := http.NewServeMux()
+	handler.Register(mux)
+
+	slog.Info("Slack アプリのエンドポイントを起動します。", "addr", slackAppAddr, "commands", slackapp.CommandPath, "interactions", slackapp.InteractionPath)
+	server := &http.Server{Addr: slackAppAddr, Handler: mux, ReadHeaderTimeout: defaultHTTPTimeout}
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("Slack アプリのエンドポイントの起動に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// newSlackAppRunner は、Slack からの依頼をコマンドラインの設定に重ねてレビューを実行する関数を返します。
+// 同じローカルパスへのクローンが競合しないよう、レビューは1件ずつ順番に実行します。
+func newSlackAppRunner() slackapp.RunFunc {
+	var mu sync.Mutex
+	return func(ctx context.Context, req slackapp.Request) (slackapp.Result, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		cfg := ReviewConfig
+		cfg.RepoURL = req.RepoURL
+		cfg.FeatureBranch = req.FeatureBranch
+		if req.BaseBranch != "" {
+			cfg.BaseBranch = req.BaseBranch
+		}
+		if req.Mode != "" {
+			cfg.ReviewMode = req.Mode
+		}
+		// リポジトリごとにクローン先を分けるため、URL からローカルパスを生成させます
+		cfg.LocalPath = ""
+		cfg.ReviewID = newReviewID()
+		if err := assignPromptVariant(&cfg); err != nil {
+			return slackapp.Result{}, err
+		}
+
+		trackers, err := resolveIssueTrackers(cfg.RepoURL)
+		if err != nil {
+			return slackapp.Result{}, err
+		}
+		cfg.IssueTrackers = trackers
+
+		slog.Info("Slack から依頼されたレビューを開始します。", "review_id", cfg.ReviewID, "repo", cfg.RepoURL, "branch", cfg.FeatureBranch, "user", req.UserID)
+		res, err := executeReviewPipeline(ctx, cfg)
+		return slackapp.Result{ReviewID: cfg.ReviewID, Markdown: localizeHeadings("slack-app", res.markdown)}, err
+	}
+}
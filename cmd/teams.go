@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"git-gemini-reviewer-go/internal/feedback"
+	"git-gemini-reviewer-go/internal/msgfit"
+	"git-gemini-reviewer-go/internal/teams"
+
+	"github.com/spf13/cobra"
+)
+
+// --- コマンド固有のフラグ変数 ---
+var (
+	noPostTeams bool // 投稿をスキップする
+)
+
+// teamsCmd は、レビュー結果を Microsoft Teams に Adaptive Card として投稿するコマンドです。
+var teamsCmd = &cobra.Command{
+	Use:   "teams",
+	Short: "コードレビューを実行し、その結果を Microsoft Teams のチャネルに投稿します。",
+	Long: `このコマンドは、ブランチ間の差分をAIでレビューし、その結果を環境変数 TEAMS_WEBHOOK_URL の Incoming Webhook (または Workflows の Webhook) に Adaptive Card として投稿します。
+レビュー結果は見出しごとのセクションに分けて表示し、1件のメッセージの上限を超える場合は複数のメッセージに分割して投稿します。`,
+	Args: cobra.NoArgs,
+	RunE: withReviewGate(runTeamsCommand),
+}
+
+func init() {
+	teamsCmd.Flags().BoolVar(&noPostTeams, "no-post", false, "投稿をスキップし、結果を標準出力する")
+}
+
+// --------------------------------------------------------------------------
+// コマンドの実行ロジック
+// --------------------------------------------------------------------------
+
+// runTeamsCommand はコマンドの主要な実行ロジックを含みます。
+func runTeamsCommand(cmd *cobra.Command, args []string) (pipelineResult, error) {
+	ctx := cmd.Context()
+
+	// 1. 環境変数の確認 (no-post の場合は不要)
+	webhookURL := os.Getenv("TEAMS_WEBHOOK_URL")
+	if !noPostTeams && webhookURL == "" {
+		return pipelineResult{}, fmt.Errorf("TEAMS_WEBHOOK_URL 環境変数の設定が必須です。")
+	}
+
+	// 2. パイプラインを実行し、結果を受け取る
+	res, err := executeReviewPipeline(ctx, ReviewConfig)
+	if err != nil {
+		return res, err
+	}
+	reviewResult := res.markdown
+	if reviewResult == "" {
+		slog.Warn("レビュー結果の内容が空のため、Teamsへの投稿をスキップします。")
+		return res, nil
+	}
+
+	// 3. no-post フラグによる出力分岐
+	if noPostTeams {
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		return res, nil
+	}
+
+	// 4. Teams投稿を実行
+	if err := postToTeams(ctx, webhookURL, reviewResult); err != nil {
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		return res, fmt.Errorf("Teams へのメッセージ投稿に失敗しました: %w", err)
+	}
+
+	slog.Info("レビュー結果を Teams に投稿しました。")
+	return res, nil
+}
+
+// --------------------------------------------------------------------------
+// ヘルパー関数
+// --------------------------------------------------------------------------
+
+// postToTeams は、レビュー結果を Teams の Webhook に投稿します。
+// Webhook は投稿したメッセージのURLを返さないため、パーマリンクは返しません。
+func postToTeams(ctx context.Context, webhookURL, reviewResult string) error {
+	client := teams.NewClient(newHTTPClient(), webhookURL)
+	title := fmt.Sprintf(localizeTitle("teams", "AIコードレビュー結果 (ブランチ: %s ← %s)"), ReviewConfig.BaseBranch, ReviewConfig.FeatureBranch)
+	content := localizeHeadings("teams", reviewResult) + feedback.Footer(ReviewConfig.FeedbackURL, ReviewConfig.FeedbackSecret, ReviewConfig.ReviewID)
+	slog.Info("Teams Webhook URL に投稿します...")
+
+	_, err := postParts(ctx, "teams.post_card", content, msgfit.Teams, func(ctx context.Context, part string) (string, error) {
+		return "", client.Post(ctx, title, part)
+	})
+	return err
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"git-gemini-reviewer-go/internal/digest"
+	"git-gemini-reviewer-go/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+// --- コマンド固有のフラグ変数 ---
+var (
+	trendsDays   int
+	trendsBucket string
+	trendsRepo   string
+	trendsFormat string
+)
+
+// trendsCmd は、レビュー履歴から指摘カテゴリのリポジトリごとの推移を出力するコマンドです。
+var trendsCmd = &cobra.Command{
+	Use:   "trends",
+	Short: "レビュー履歴から、指摘カテゴリのリポジトリごとの推移を出力します。",
+	Long: `このコマンドは、--history-file に記録されたレビュー履歴の指摘を固定のカテゴリ
+(security, correctness, performance, style, tests, docs) に分類し、リポジトリ・期間ごとの件数の推移を出力します。
+どの分野の指摘が多いチームかを把握し、教育やガイドライン整備の対象を決める材料として利用できます。`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{standaloneCommandAnnotation: "true"},
+	RunE:        runTrendsCommand,
+}
+
+func init() {
+	trendsCmd.Flags().IntVar(&trendsDays, "days", 90, "集計する期間 (日数)")
+	trendsCmd.Flags().StringVar(&trendsBucket, "bucket", string(digest.BucketWeek), "集計単位: 'week' または 'month'")
+	trendsCmd.Flags().StringVar(&trendsRepo, "repo", "", "集計対象をこのリポジトリURLに限定します")
+	trendsCmd.Flags().StringVar(&trendsFormat, "format", "markdown", "出力形式: 'markdown' または 'json'")
+}
+
+// runTrendsCommand はコマンドの主要な実行ロジックを含みます。
+func runTrendsCommand(cmd *cobra.Command, args []string) error {
+	bucket, err := digest.ParseBucket(trendsBucket)
+	if err != nil {
+		return err
+	}
+	if trendsDays <= 0 {
+		return fmt.Errorf("--days には1以上を指定してください")
+	}
+
+	path := ReviewConfig.HistoryFile
+	if path == "" {
+		path = history.DefaultPath()
+	}
+	reviews, err := history.NewStore(path).List(time.Now().AddDate(0, 0, -trendsDays))
+	if err != nil {
+		return err
+	}
+	if trendsRepo != "" {
+		filtered := reviews[:0]
+		for _, r := range reviews {
+			if r.RepoURL == trendsRepo {
+				filtered = append(filtered, r)
+			}
+		}
+		reviews = filtered
+	}
+	rows := digest.Trends(reviews, bucket)
+
+	switch strings.ToLower(trendsFormat) {
+	case "json":
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(rows)
+	case "markdown":
+		_, err := fmt.Fprint(cmd.OutOrStdout(), digest.TrendsMarkdown(rows))
+		return err
+	}
+	return fmt.Errorf("出力形式が不正です: '%s' ('markdown' または 'json' を指定してください)", trendsFormat)
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"git-gemini-reviewer-go/internal/callback"
+	"git-gemini-reviewer-go/internal/pkg/retry"
+	"git-gemini-reviewer-go/internal/schema"
+	"git-gemini-reviewer-go/internal/verdict"
+	"git-gemini-reviewer-go/internal/webhook"
+
+	"github.com/spf13/cobra"
+)
+
+// WebhookFlags は webhook コマンド固有のフラグを保持します。
+type WebhookFlags struct {
+	URL        string // 送信先のURL
+	Secret     string // HMAC-SHA256 署名のシークレット
+	Structured bool   // 構造化された指摘を AI に出力させる
+	NoPost     bool   // 送信をスキップし、ペイロードを標準出力する
+}
+
+var webhookFlags WebhookFlags
+
+// webhookCmd は、レビュー結果を構造化された JSON で任意のURLに POST するコマンドです。
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "コードレビューを実行し、その結果を構造化された JSON で任意のURLに送信します。",
+	Long: `このコマンドは、ブランチ間の差分をAIでレビューし、リポジトリ・ブランチ・差分の変更量・レビュー結果の Markdown・指摘の一覧を含む JSON を --url に POST します。
+--secret (環境変数 REVIEWER_WEBHOOK_SECRET でも指定可) を指定すると、--callback-url と同じ方式 (X-Reviewer-Timestamp / X-Reviewer-Signature ヘッダ) で HMAC-SHA256 署名を付与します。`,
+	Args: cobra.NoArgs,
+	RunE: withReviewGate(runWebhookCommand),
+}
+
+func init() {
+	webhookCmd.Flags().StringVar(&webhookFlags.URL, "url", "", "送信先のURL (環境変数 REVIEWER_WEBHOOK_URL でも指定可)")
+	webhookCmd.Flags().StringVar(&webhookFlags.Secret, "secret", "", "HMAC-SHA256 署名に使用するシークレット (環境変数 REVIEWER_WEBHOOK_SECRET でも指定可)。未指定時は署名を付与しません")
+	webhookCmd.Flags().BoolVar(&webhookFlags.Structured, "structured", false, "AI に構造化された指摘を出力させ、重大度と修正案を含む指摘の一覧を送信します")
+	webhookCmd.Flags().BoolVar(&webhookFlags.NoPost, "no-post", false, "送信をスキップし、ペイロードを標準出力する")
+}
+
+// --------------------------------------------------------------------------
+// コマンドの実行ロジック
+// --------------------------------------------------------------------------
+
+// runWebhookCommand はコマンドの主要な実行ロジックを含みます。
+func runWebhookCommand(cmd *cobra.Command, args []string) (pipelineResult, error) {
+	ctx := cmd.Context()
+
+	// 1. 送信先の確認 (no-post の場合は不要)
+	if webhookFlags.URL == "" {
+		webhookFlags.URL = os.Getenv("REVIEWER_WEBHOOK_URL")
+	}
+	if webhookFlags.Secret == "" {
+		webhookFlags.Secret = os.Getenv("REVIEWER_WEBHOOK_SECRET")
+	}
+	if !webhookFlags.NoPost && webhookFlags.URL == "" {
+		return pipelineResult{}, fmt.Errorf("--url または REVIEWER_WEBHOOK_URL 環境変数の設定が必須です。")
+	}
+	if webhookFlags.Structured {
+		if ReviewConfig.SplitModules {
+			return pipelineResult{}, fmt.Errorf("--split-modules と --structured は同時に指定できません")
+		}
+		ReviewConfig.InlineFindings = true
+	}
+
+	// 2. パイプラインを実行し、結果を受け取る
+	res, err := executeReviewPipeline(ctx, ReviewConfig)
+	if err != nil {
+		return res, err
+	}
+	reviewResult := res.markdown
+	if reviewResult == "" {
+		slog.Warn("レビュー結果の内容が空のため、Webhookの送信をスキップします。")
+		return res, nil
+	}
+	payload := buildWebhookPayload(res, time.Now())
+
+	// 3. no-post フラグによる出力分岐
+	if webhookFlags.NoPost {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return res, enc.Encode(payload)
+	}
+
+	// 4. 送信を実行
+	httpClient := newHTTPClient()
+	err = retry.Do(ctx, "webhook.post", func(ctx context.Context) error {
+		return callback.SendJSON(ctx, httpClient, webhookFlags.URL, webhookFlags.Secret, payload.ReviewID, payload)
+	}, retry.WithBudget(notifyRetryBudget))
+	if err != nil {
+		printReviewResult(ReviewConfig.Destination, reviewResult)
+		return res, fmt.Errorf("Webhook の送信に失敗しました: %w", err)
+	}
+
+	slog.Info("レビュー結果を Webhook で送信しました。", "review_id", payload.ReviewID)
+	return res, nil
+}
+
+// --------------------------------------------------------------------------
+// ヘルパー関数
+// --------------------------------------------------------------------------
+
+// buildWebhookPayload は、レビューの結果から送信するペイロードを組み立てます。
+func buildWebhookPayload(res pipelineResult, generatedAt time.Time) webhook.Payload {
+	return webhook.Payload{
+		SchemaVersion: schema.Version,
+		ReviewID:      ReviewConfig.ReviewID,
+		RepoURL:       ReviewConfig.RepoURL,
+		BaseBranch:    ReviewConfig.BaseBranch,
+		FeatureBranch: ReviewConfig.FeatureBranch,
+		Mode:          ReviewConfig.ReviewMode,
+		Model:         res.model,
+		Verdict:       string(verdict.Parse(res.markdown)),
+		DiffStats:     webhook.NewDiffStats(res.diffStats),
+		Usage:         res.usage,
+		Review:        res.markdown,
+		Findings:      webhook.Findings(res.inline, res.markdown),
+		GeneratedAt:   generatedAt,
+	}
+}
@@ -0,0 +1,117 @@
+package aggregate
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Failure は1つの処理単位 (チャンクや投稿先) の失敗を表します。
+type Failure struct {
+	Name string
+	Err  error
+}
+
+// MultiError は複数の処理単位の失敗をまとめた複合エラーです。
+// errors.Is / errors.As で個々のエラーを辿れるよう Unwrap() []error を実装します。
+type MultiError struct {
+	// Op は失敗した処理の種類 (例: "AIレビュー", "投稿") です。
+	Op       string
+	Failures []Failure
+}
+
+// Error は失敗の一覧を1行ずつ整形して返します。
+func (e *MultiError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %d 件の処理が失敗しました", e.Op, len(e.Failures))
+	for _, f := range e.Failures {
+		fmt.Fprintf(&sb, "\n  - %s: %v", f.Name, f.Err)
+	}
+	return sb.String()
+}
+
+// Unwrap は個々の失敗のエラーを返します。
+func (e *MultiError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		errs = append(errs, f.Err)
+	}
+	return errs
+}
+
+// Names は失敗した処理単位の名前を返します。
+func (e *MultiError) Names() []string {
+	names := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// Collector は処理単位ごとの成否を収集し、最後に MultiError としてまとめます。
+type Collector struct {
+	op       string
+	failures []Failure
+}
+
+// NewCollector は Collector を生成します。
+func NewCollector(op string) *Collector {
+	return &Collector{op: op}
+}
+
+// Add は処理単位の結果を記録します。err が nil の場合は何もしません。
+func (c *Collector) Add(name string, err error) {
+	if err != nil {
+		c.failures = append(c.failures, Failure{Name: name, Err: err})
+	}
+}
+
+// Err は失敗が1件以上ある場合に *MultiError を返します。
+func (c *Collector) Err() error {
+	if len(c.failures) == 0 {
+		return nil
+	}
+	return &MultiError{Op: c.op, Failures: c.failures}
+}
+
+// AsMultiError は err が *MultiError を含む場合にそれを返します。
+func AsMultiError(err error) (*MultiError, bool) {
+	var me *MultiError
+	ok := errors.As(err, &me)
+	return me, ok
+}
+
+// Section は部分的なレビュー結果 (チャンク) を表します。
+type Section struct {
+	Name    string
+	Content string
+	Err     error
+}
+
+// MergeSections は成功したセクションを順に結合し、失敗したセクションには欠落の注記を挿入します。
+// 失敗が1件以上ある場合は *MultiError を返します。全セクションが失敗した場合、結合結果は空文字列です。
+func MergeSections(sections []Section) (string, error) {
+	collector := NewCollector("AIレビュー")
+	var parts []string
+	succeeded := 0
+
+	for _, s := range sections {
+		if s.Err != nil {
+			collector.Add(s.Name, s.Err)
+			parts = append(parts, MissingNotice(s.Name))
+			continue
+		}
+		succeeded++
+		parts = append(parts, strings.TrimSpace(s.Content))
+	}
+
+	if succeeded == 0 {
+		return "", collector.Err()
+	}
+	return strings.Join(parts, "\n\n"), collector.Err()
+}
+
+// MissingNotice は取得できなかったセクションの代わりに挿入する注記を返します。
+func MissingNotice(name string) string {
+	return fmt.Sprintf("> ⚠️ **%s** のレビュー結果は取得に失敗したため、このレポートには含まれていません。", name)
+}
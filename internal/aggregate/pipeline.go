@@ -0,0 +1,206 @@
+package aggregate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// Severity はパイプラインで発生した失敗の深刻度です。
+type Severity int
+
+const (
+	// Degraded は、結果の一部が欠けるか後処理に失敗したものの、パイプラインは継続できた失敗です
+	// (例: クリーンアップの失敗、一部の投稿先への配信失敗)。
+	Degraded Severity = iota
+	// Fatal は、パイプラインを継続できなかった失敗です (例: 差分の取得やAIレビューの失敗)。
+	Fatal
+)
+
+// Label は深刻度の表示名を返します。
+func (s Severity) Label() string {
+	if s == Fatal {
+		return "致命的"
+	}
+	return "縮退"
+}
+
+// 終了コード。1 は従来どおりの失敗とし、縮退のみの場合は CI で区別できるよう別の値とします。
+const (
+	ExitFatal    = 1
+	ExitDegraded = 3
+)
+
+// Issue はパイプラインの1つの段階で発生した失敗です。
+type Issue struct {
+	// Stage は失敗した段階の名前です (例: "git.cleanup", "notify.slack")。
+	Stage    string
+	Severity Severity
+	Err      error
+}
+
+// PipelineError はパイプライン全体で発生した失敗をまとめた複合エラーです。
+// 致命的な失敗を含まない場合、レビュー結果は得られており、一部の処理のみが失敗しています。
+type PipelineError struct {
+	Issues []Issue
+}
+
+// Error は失敗の一覧を1行ずつ整形して返します。
+func (e *PipelineError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "パイプラインで %d 件の処理が失敗しました", len(e.Issues))
+	for _, is := range e.Issues {
+		fmt.Fprintf(&sb, "\n  - [%s] %s: %v", is.Severity.Label(), is.Stage, is.Err)
+	}
+	return sb.String()
+}
+
+// Unwrap は個々の失敗のエラーを返します。
+func (e *PipelineError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Issues))
+	for _, is := range e.Issues {
+		errs = append(errs, is.Err)
+	}
+	return errs
+}
+
+// Fatal は致命的な失敗を含むかどうかを返します。
+func (e *PipelineError) Fatal() bool {
+	for _, is := range e.Issues {
+		if is.Severity == Fatal {
+			return true
+		}
+	}
+	return false
+}
+
+// ExitCode は失敗の深刻度に応じたプロセスの終了コードを返します。
+func (e *PipelineError) ExitCode() int {
+	if e.Fatal() {
+		return ExitFatal
+	}
+	return ExitDegraded
+}
+
+// Summary は失敗を深刻度ごとにまとめた、人が読むための要約を返します。
+func (e *PipelineError) Summary() string {
+	var sb strings.Builder
+	if e.Fatal() {
+		sb.WriteString("❌ レビューパイプラインは失敗しました。\n")
+	} else {
+		sb.WriteString("⚠️ レビューは完了しましたが、一部の処理が失敗しました。\n")
+	}
+	for _, severity := range []Severity{Fatal, Degraded} {
+		var lines []string
+		for _, is := range e.Issues {
+			if is.Severity == severity {
+				lines = append(lines, fmt.Sprintf("  - %s: %v", is.Stage, is.Err))
+			}
+		}
+		if len(lines) > 0 {
+			fmt.Fprintf(&sb, "\n%s (%d 件):\n%s\n", severity.Label(), len(lines), strings.Join(lines, "\n"))
+		}
+	}
+	fmt.Fprintf(&sb, "\n終了コード: %d", e.ExitCode())
+	return sb.String()
+}
+
+// Pipeline はパイプラインの各段階の失敗を深刻度とともに収集します。
+// 複数の goroutine から同時に使用できます。nil の場合、記録した失敗はログにのみ出力されます。
+type Pipeline struct {
+	mu     sync.Mutex
+	issues []Issue
+}
+
+// NewPipeline は Pipeline を生成します。
+func NewPipeline() *Pipeline {
+	return &Pipeline{}
+}
+
+// Fatal は致命的な失敗を記録し、それまでの失敗をまとめた複合エラーを返します。err が nil の場合は nil を返します。
+func (p *Pipeline) Fatal(stage string, err error) error {
+	if err == nil {
+		return nil
+	}
+	p.add(Issue{Stage: stage, Severity: Fatal, Err: err})
+	if p == nil {
+		return &PipelineError{Issues: []Issue{{Stage: stage, Severity: Fatal, Err: err}}}
+	}
+	return p.Err()
+}
+
+// Degrade は、パイプラインを継続できる失敗を記録します。err が nil の場合は何もしません。
+func (p *Pipeline) Degrade(stage string, err error) {
+	if err == nil {
+		return
+	}
+	slog.Warn("処理の一部が失敗しました。パイプラインは継続します。", "stage", stage, "error", err)
+	p.add(Issue{Stage: stage, Severity: Degraded, Err: err})
+}
+
+// Merge は err に含まれる失敗を取り込みます。
+// *PipelineError の場合は個々の失敗を深刻度ごと取り込み、それ以外のエラーは stage の致命的な失敗として記録します。
+func (p *Pipeline) Merge(stage string, err error) {
+	if err == nil {
+		return
+	}
+	var pe *PipelineError
+	if !errors.As(err, &pe) {
+		p.add(Issue{Stage: stage, Severity: Fatal, Err: err})
+		return
+	}
+	for _, is := range pe.Issues {
+		p.add(is)
+	}
+}
+
+// Err は失敗が1件以上ある場合に *PipelineError を返します。
+func (p *Pipeline) Err() error {
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.issues) == 0 {
+		return nil
+	}
+	return &PipelineError{Issues: append([]Issue(nil), p.issues...)}
+}
+
+func (p *Pipeline) add(is Issue) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, existing := range p.issues {
+		// 同じ失敗を複数の経路から取り込んだ場合は1件として扱う
+		if existing.Stage == is.Stage && existing.Err == is.Err {
+			return
+		}
+	}
+	p.issues = append(p.issues, is)
+}
+
+type pipelineKey struct{}
+
+// NewContext は Pipeline を格納したコンテキストを返します。
+func NewContext(ctx context.Context, p *Pipeline) context.Context {
+	return context.WithValue(ctx, pipelineKey{}, p)
+}
+
+// FromContext はコンテキストに格納された Pipeline を返します。格納されていない場合は nil を返します。
+func FromContext(ctx context.Context) *Pipeline {
+	p, _ := ctx.Value(pipelineKey{}).(*Pipeline)
+	return p
+}
+
+// AsPipelineError は err が *PipelineError を含む場合にそれを返します。
+func AsPipelineError(err error) (*PipelineError, bool) {
+	var pe *PipelineError
+	ok := errors.As(err, &pe)
+	return pe, ok
+}
@@ -0,0 +1,132 @@
+// Package aiprovider は、レビューに使用する AI (adapters.CodeReviewAI) を名前で選択するためのレジストリを提供します。
+package aiprovider
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"git-gemini-reviewer-go/internal/ollama"
+	"git-gemini-reviewer-go/internal/openai"
+	"git-gemini-reviewer-go/internal/sampling"
+	"git-gemini-reviewer-go/internal/streamai"
+	"git-gemini-reviewer-go/internal/stubai"
+	"git-gemini-reviewer-go/internal/vertexai"
+
+	"github.com/shouni/gemini-reviewer-core/pkg/adapters"
+)
+
+// 組み込みの AI プロバイダの名前です。
+const (
+	Gemini = "gemini"
+	OpenAI = "openai"
+	Ollama = "ollama"
+	Stub   = "stub"
+)
+
+// Options は AI を構築する際の設定です。
+type Options struct {
+	// Model は使用するモデル名です。
+	Model string
+	// HTTPClient は API の呼び出しに使用する HTTP クライアントです。
+	HTTPClient *http.Client
+	// BaseURL は API のベース URL です。空の場合はプロバイダの既定値を使用します。
+	BaseURL string
+	// Stream は、応答を生成と同時に書き出すかを表します。対応しないプロバイダは無視します。
+	Stream bool
+	// ContextLength は、ローカルの LLM に指定するコンテキスト長です。0 の場合はプロンプトの長さから決めます。
+	ContextLength int
+	// Vertex は Vertex AI への接続の設定です。nil でない場合、Gemini は API キーの代わりに Vertex AI 経由で呼び出します。
+	Vertex *vertexai.Options
+	// Sampling は応答の生成を調整するパラメータ (温度・top-p・出力トークン数の上限) です。
+	Sampling sampling.Params
+}
+
+// Provider は名前で選択できる AI です。
+type Provider struct {
+	// DefaultModel はモデルが指定されなかった場合に使用するモデル名です。
+	DefaultModel string
+	// New は AI を構築します。
+	New func(ctx context.Context, opts Options) (adapters.CodeReviewAI, error)
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]Provider{}
+)
+
+func init() {
+	Register(Gemini, Provider{DefaultModel: "gemini-2.5-flash", New: newGemini})
+	Register(OpenAI, Provider{DefaultModel: "gpt-4o", New: newOpenAI})
+	Register(Ollama, Provider{DefaultModel: "qwen2.5-coder:7b", New: newOllama})
+	Register(Stub, Provider{DefaultModel: "stub", New: newStub})
+}
+
+// Register は名前で指定できる AI を登録します。同じ名前の登録は上書きします。
+func Register(name string, p Provider) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = p
+}
+
+// Names は登録済みの AI の名前を昇順で返します。
+func Names() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup は名前で登録済みの AI を返します。空の名前は Gemini とみなします。
+func Lookup(name string) (Provider, error) {
+	if name == "" {
+		name = Gemini
+	}
+	registryMu.Lock()
+	p, ok := registry[name]
+	registryMu.Unlock()
+	if !ok {
+		return Provider{}, fmt.Errorf("不明な AI プロバイダです: '%s' (登録済み: %s)", name, strings.Join(Names(), ", "))
+	}
+	return p, nil
+}
+
+// newGemini は Gemini の AI を構築します。Stream が指定された場合は、GenerateContentStream で受信するアダプタを使用します。
+// Vertex が指定された場合は、Vertex AI のエンドポイントを使用します (応答は常に GenerateContentStream で受信します)。
+// gemini-reviewer-core のアダプタは温度を固定しているため、既定以外の Sampling が指定された場合も GenerateContentStream で受信するアダプタを使用します。
+func newGemini(ctx context.Context, opts Options) (adapters.CodeReviewAI, error) {
+	if opts.Vertex != nil {
+		return vertexai.New(ctx, opts.HTTPClient, opts.Model, *opts.Vertex, opts.Sampling)
+	}
+	if opts.Stream || !opts.Sampling.IsDefault() {
+		return streamai.New(ctx, opts.HTTPClient, opts.Model, opts.Sampling)
+	}
+	return adapters.NewGeminiAdapter(ctx, opts.Model)
+}
+
+// newOpenAI は OpenAI 互換の Chat Completions API の AI を構築します。ストリーミングには対応しません。
+func newOpenAI(_ context.Context, opts Options) (adapters.CodeReviewAI, error) {
+	if opts.Stream {
+		slog.Warn("OpenAI 互換の AI はストリーミング出力に対応していないため、応答の受信後にまとめて出力します。")
+	}
+	return openai.New(opts.HTTPClient, opts.Model, opts.BaseURL, opts.Sampling)
+}
+
+// newOllama はローカルの Ollama サーバーの AI を構築します。
+func newOllama(_ context.Context, opts Options) (adapters.CodeReviewAI, error) {
+	return ollama.New(opts.HTTPClient, opts.Model, opts.BaseURL, opts.ContextLength, opts.Sampling)
+}
+
+// newStub は、ネットワークに接続せず差分の統計から決定的な結果を返すスタブを構築します。
+func newStub(context.Context, Options) (adapters.CodeReviewAI, error) {
+	slog.Warn("スタブの AI を使用します。レビュー結果は差分の統計から生成した定型の内容です。")
+	return stubai.New(), nil
+}
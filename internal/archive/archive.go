@@ -0,0 +1,133 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"git-gemini-reviewer-go/internal/gcs"
+	"git-gemini-reviewer-go/internal/redact"
+)
+
+const (
+	promptFileName   = "prompt.md"
+	responseFileName = "response.md"
+	metadataFileName = "metadata.json"
+
+	contentTypeMarkdown = "text/markdown; charset=utf-8"
+	contentTypeJSON     = "application/json; charset=utf-8"
+)
+
+// Metadata はアーカイブされる実行のメタデータです。
+type Metadata struct {
+	// SchemaVersion はメタデータのスキーマのバージョン (schema.Version) です。
+	SchemaVersion int       `json:"schema_version"`
+	ReviewID      string    `json:"review_id"`
+	RepoURL       string    `json:"repo_url"`
+	BaseBranch    string    `json:"base_branch"`
+	FeatureBranch string    `json:"feature_branch"`
+	ReviewMode    string    `json:"review_mode"`
+	Model         string    `json:"model"`
+	PromptVariant string    `json:"prompt_variant,omitempty"`
+	PromptSHA256  string    `json:"prompt_sha256"`
+	PromptBytes   int       `json:"prompt_bytes"`
+	ResponseBytes int       `json:"response_bytes"`
+	StartedAt     time.Time `json:"started_at"`
+	FinishedAt    time.Time `json:"finished_at"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// Record は1回のレビュー実行で AI に送信したプロンプトと、受信した生のレスポンスです。
+type Record struct {
+	Prompt   string
+	Response string
+	Metadata Metadata
+}
+
+// Archiver はレビュー実行の記録を保存する契約です。
+type Archiver interface {
+	Archive(ctx context.Context, rec Record) error
+}
+
+// Store は baseURI 配下に <review_id>/ ディレクトリを作成し、プロンプト・レスポンス・メタデータを保存します。
+// baseURI が gs:// で始まる場合は GCS に、それ以外はローカルファイルシステムに保存します。
+type Store struct {
+	baseURI string
+	writer  gcs.Writer
+	secrets []string
+}
+
+// NewStore は Store を生成します。ローカルパスに保存する場合、writer は nil で構いません。
+// secrets にはフラグで指定されたトークンなど、環境変数以外から渡された秘匿情報を指定します。保存する前に除去されます。
+func NewStore(baseURI string, writer gcs.Writer, secrets ...string) (*Store, error) {
+	if gcs.IsURI(baseURI) && writer == nil {
+		return nil, fmt.Errorf("GCS へのアーカイブには gcs.Writer が必要です (URI: %s)", baseURI)
+	}
+	return &Store{baseURI: baseURI, writer: writer, secrets: secrets}, nil
+}
+
+// Archive は秘匿情報を除去したうえで記録を保存します。
+func (s *Store) Archive(ctx context.Context, rec Record) error {
+	prompt := redact.String(rec.Prompt, s.secrets...)
+	response := redact.String(rec.Response, s.secrets...)
+
+	meta := rec.Metadata
+	meta.RepoURL = redact.URL(meta.RepoURL)
+	meta.Error = redact.String(meta.Error, s.secrets...)
+	sum := sha256.Sum256([]byte(rec.Prompt))
+	meta.PromptSHA256 = hex.EncodeToString(sum[:])
+	meta.PromptBytes = len(rec.Prompt)
+	meta.ResponseBytes = len(rec.Response)
+
+	metaJSON, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("メタデータのエンコードに失敗しました: %w", err)
+	}
+
+	files := []struct {
+		name        string
+		content     []byte
+		contentType string
+	}{
+		{promptFileName, []byte(prompt), contentTypeMarkdown},
+		{responseFileName, []byte(response), contentTypeMarkdown},
+		{metadataFileName, metaJSON, contentTypeJSON},
+	}
+	for _, f := range files {
+		if err := s.write(ctx, meta.ReviewID, f.name, f.content, f.contentType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write は1ファイルを保存先に書き込みます。
+func (s *Store) write(ctx context.Context, reviewID, name string, content []byte, contentType string) error {
+	if gcs.IsURI(s.baseURI) {
+		bucket, prefix, err := gcs.ParseURI(s.baseURI)
+		if err != nil {
+			return fmt.Errorf("アーカイブ先のGCS URIが不正です: %w", err)
+		}
+		objectPath := path.Join(prefix, reviewID, name)
+		if err := s.writer.WriteToGCS(ctx, bucket, objectPath, bytes.NewReader(content), contentType); err != nil {
+			return fmt.Errorf("アーカイブのGCSへの書き込みに失敗しました (gs://%s/%s): %w", bucket, objectPath, err)
+		}
+		return nil
+	}
+
+	dir := filepath.Join(s.baseURI, reviewID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("アーカイブディレクトリの作成に失敗しました: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+		return fmt.Errorf("アーカイブファイルの書き込みに失敗しました: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+// Package backlogattach は、Backlog の課題コメントの文字数上限 (msgfit.Backlog) を超えるレビュー結果を、課題の添付ファイルとして投稿する機能を提供します。
+// go-notifier の Backlog クライアントは添付ファイルに対応しておらず、絵文字の除去で本文が損なわれることがあるため、
+// 課題コメントの投稿にも使用し、API を直接呼び出します。
+package backlogattach
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/backlogtext"
+	"git-gemini-reviewer-go/internal/pkg/retry"
+)
+
+// File は添付するファイルです。
+type File struct {
+	Name    string
+	Content []byte
+}
+
+// Client は Backlog の添付ファイルと課題コメントの API のクライアントです。
+type Client struct {
+	httpClient *http.Client
+	spaceURL   string
+	apiKey     string
+}
+
+// NewClient は Client を生成します。
+func NewClient(httpClient *http.Client, spaceURL, apiKey string) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{httpClient: httpClient, spaceURL: strings.TrimRight(spaceURL, "/"), apiKey: apiKey}
+}
+
+// Upload はファイルをスペースにアップロードし、添付ファイルIDを返します。
+// アップロードしたファイルは、PostComment で課題に紐付けるまで課題には表示されません。
+func (c *Client) Upload(ctx context.Context, file File) (int, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", file.Name)
+	if err != nil {
+		return 0, retry.Permanent(fmt.Errorf("添付ファイルのリクエストの作成に失敗しました: %w", err))
+	}
+	if _, err := part.Write(file.Content); err != nil {
+		return 0, retry.Permanent(fmt.Errorf("添付ファイルのリクエストの作成に失敗しました: %w", err))
+	}
+	if err := w.Close(); err != nil {
+		return 0, retry.Permanent(fmt.Errorf("添付ファイルのリクエストの作成に失敗しました: %w", err))
+	}
+
+	var attachment struct {
+		ID int `json:"id"`
+	}
+	if err := c.do(ctx, "/api/v2/space/attachment", w.FormDataContentType(), &body, &attachment); err != nil {
+		return 0, err
+	}
+	return attachment.ID, nil
+}
+
+// PostComment は、添付ファイルを紐付けたコメントを課題に投稿します。attachmentIDs が空の場合は通常のコメントです。
+// Backlog が保存できない絵文字は、backlogtext.Sanitize で本文を損なわずに取り除きます。
+func (c *Client) PostComment(ctx context.Context, issueID, content string, attachmentIDs []int) error {
+	form := url.Values{"content": {backlogtext.Sanitize(content)}}
+	for _, id := range attachmentIDs {
+		form.Add("attachmentId[]", strconv.Itoa(id))
+	}
+	path := "/api/v2/issues/" + url.PathEscape(issueID) + "/comments"
+	return c.do(ctx, path, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()), nil)
+}
+
+// do は Backlog API に POST し、レスポンスを out にデコードします。
+func (c *Client) do(ctx context.Context, path, contentType string, body io.Reader, out any) error {
+	endpoint, err := url.Parse(c.spaceURL + path)
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("Backlog APIのURLが不正です: %w", err))
+	}
+	q := endpoint.Query()
+	q.Set("apiKey", c.apiKey)
+	endpoint.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), body)
+	if err != nil {
+		return fmt.Errorf("Backlog APIリクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Backlog API (POST %s) の呼び出しに失敗しました: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("Backlog API (POST %s) のレスポンスの読み込みに失敗しました: %w", path, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		err := fmt.Errorf("Backlog API (POST %s) がエラーを返しました (status: %d): %s", path, resp.StatusCode, errorMessage(respBody))
+		// 権限不足や存在しない課題など、レート制限以外のクライアントエラーはリトライしても解消しません
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("Backlog API (POST %s) のレスポンスのデコードに失敗しました: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// errorMessage は Backlog のエラーレスポンス ({"errors": [{"message": ...}]}) からメッセージを取り出します。
+func errorMessage(body []byte) string {
+	var apiErr struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &apiErr); err != nil || len(apiErr.Errors) == 0 {
+		return strings.TrimSpace(string(body))
+	}
+	messages := make([]string, 0, len(apiErr.Errors))
+	for _, e := range apiErr.Errors {
+		messages = append(messages, e.Message)
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,119 @@
+// Package backlogpr は、レビュー結果を Backlog Git のプルリクエストにコメントとして投稿する機能を提供します。
+// go-notifier の Backlog クライアントは課題コメントのみに対応しているため、プルリクエスト API は直接呼び出します。
+package backlogpr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/backlogtext"
+	"git-gemini-reviewer-go/internal/pkg/retry"
+)
+
+// Repo は Backlog Git のリポジトリです。
+type Repo struct {
+	ProjectKey string
+	Name       string
+}
+
+// ParseRepoURL は Backlog Git のクローンURLからプロジェクトキーとリポジトリ名を読み取ります。
+// SSH (space@space.git.backlog.jp:/PROJ/repo.git) と HTTPS (https://space.backlog.jp/git/PROJ/repo.git) に対応します。
+func ParseRepoURL(repoURL string) (Repo, bool) {
+	s := strings.TrimSpace(repoURL)
+	if strings.Contains(s, "://") {
+		u, err := url.Parse(s)
+		if err != nil {
+			return Repo{}, false
+		}
+		s = strings.TrimPrefix(u.Path, "/git/")
+	} else if _, path, ok := strings.Cut(s, ":"); ok {
+		s = path
+	}
+	s = strings.TrimSuffix(strings.Trim(s, "/"), ".git")
+	project, name, ok := strings.Cut(s, "/")
+	if !ok || project == "" || name == "" || strings.Contains(name, "/") {
+		return Repo{}, false
+	}
+	return Repo{ProjectKey: project, Name: name}, true
+}
+
+// Client は Backlog のプルリクエスト API のクライアントです。
+type Client struct {
+	httpClient *http.Client
+	spaceURL   string
+	apiKey     string
+}
+
+// NewClient は Client を生成します。
+func NewClient(httpClient *http.Client, spaceURL, apiKey string) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{httpClient: httpClient, spaceURL: strings.TrimRight(spaceURL, "/"), apiKey: apiKey}
+}
+
+// PostComment はプルリクエストにコメントを投稿し、プルリクエストの閲覧URLを返します。
+func (c *Client) PostComment(ctx context.Context, repo Repo, number int, content string) (string, error) {
+	path := fmt.Sprintf("/api/v2/projects/%s/git/repositories/%s/pullRequests/%d/comments",
+		url.PathEscape(repo.ProjectKey), url.PathEscape(repo.Name), number)
+	if err := c.do(ctx, path, url.Values{"content": {backlogtext.Sanitize(content)}}); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/git/%s/%s/pullRequests/%d", c.spaceURL, repo.ProjectKey, repo.Name, number), nil
+}
+
+// do は Backlog API に POST します。
+func (c *Client) do(ctx context.Context, path string, form url.Values) error {
+	endpoint, err := url.Parse(c.spaceURL + path)
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("Backlog APIのURLが不正です: %w", err))
+	}
+	q := endpoint.Query()
+	q.Set("apiKey", c.apiKey)
+	endpoint.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("Backlog APIリクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Backlog API (POST %s) の呼び出しに失敗しました: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("Backlog API (POST %s) がエラーを返しました (status: %d): %s", path, resp.StatusCode, errorMessage(detail))
+		// 権限不足や存在しないプルリクエストなど、レート制限以外のクライアントエラーはリトライしても解消しません
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// errorMessage は Backlog のエラーレスポンス ({"errors": [{"message": ...}]}) からメッセージを取り出します。
+func errorMessage(body []byte) string {
+	var apiErr struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &apiErr); err != nil || len(apiErr.Errors) == 0 {
+		return strings.TrimSpace(string(body))
+	}
+	messages := make([]string, 0, len(apiErr.Errors))
+	for _, e := range apiErr.Errors {
+		messages = append(messages, e.Message)
+	}
+	return strings.Join(messages, "; ")
+}
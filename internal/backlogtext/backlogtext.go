@@ -0,0 +1,60 @@
+// Package backlogtext は、Backlog に投稿するテキストから、Backlog が保存できない文字のみを取り除く処理を提供します。
+// Backlog は4バイトの UTF-8 文字 (基本多言語面の外の文字) を含む投稿を拒否しますが、go-notifier の絵文字の除去は
+// 日本語などのレビュー本文まで失われることがあるため、対象を絵文字に限定して置き換えます。
+package backlogtext
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// zeroWidthJoiner は、複数の絵文字を1つの絵文字として結合する文字です (例: 👨‍💻)。
+	zeroWidthJoiner = '\u200d'
+	// variationSelector は、直前の文字を絵文字として表示させる異体字セレクタです。
+	variationSelector = '\ufe0f'
+	// maxBMP は、3バイト以下の UTF-8 で表せる文字 (基本多言語面) の最大値です。
+	maxBMP = '\uffff'
+)
+
+// Sanitize は、Backlog が保存できない文字を置き換えたテキストを返します。
+// 基本多言語面の外の絵文字 (🚨 や 👨‍💻 など) は、結合文字や異体字セレクタとともに取り除きます。行頭の絵文字の直後の空白も取り除きます。
+// それ以外の基本多言語面の外の文字 (𠮷 などの漢字) は、内容を失わないよう HTML の文字参照 (&#x20BB7;) に置き換えます。
+// ✅ や ⚠ のような基本多言語面の記号と、日本語を含むその他の文字はそのまま残します。
+func Sanitize(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	lineStart := true
+	dropping := false
+	for _, r := range s {
+		switch {
+		case isEmoji(r):
+			dropping = true
+			continue
+		case dropping && (r == zeroWidthJoiner || r == variationSelector):
+			continue
+		case dropping && lineStart && r == ' ':
+			dropping = false
+			continue
+		case r > maxBMP:
+			fmt.Fprintf(&sb, "&#x%X;", r)
+		default:
+			sb.WriteRune(r)
+		}
+		dropping = false
+		lineStart = r == '\n'
+	}
+	return sb.String()
+}
+
+// isEmoji は、r が基本多言語面の外の絵文字 (絵文字の修飾子、国旗の地域指示子、タグ文字を含む) かを返します。
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF:
+		return true
+	case r >= 0xE0000 && r <= 0xE007F:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,181 @@
+// Package backlogwiki は、レビュー結果を Backlog の Wiki ページとして公開する機能を提供します。
+// go-notifier の Backlog クライアントは課題コメントのみに対応しているため、Wiki API は直接呼び出します。
+package backlogwiki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/backlogtext"
+	"git-gemini-reviewer-go/internal/pkg/retry"
+)
+
+// Mode は既存ページがある場合の更新方法です。
+type Mode string
+
+const (
+	// ModeAppend は既存ページの末尾にレビュー結果を追記します。
+	ModeAppend Mode = "append"
+	// ModeReplace は既存ページの内容をレビュー結果で置き換えます。
+	ModeReplace Mode = "replace"
+)
+
+// entrySeparator は追記時に既存の内容との間に挟む区切りです。
+const entrySeparator = "\n\n---\n\n"
+
+// ParseMode は文字列を Mode に変換します。
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(strings.TrimSpace(s))); m {
+	case ModeAppend, ModeReplace:
+		return m, nil
+	}
+	return "", fmt.Errorf("Wiki の更新方法が不正です: '%s' ('%s' または '%s' を指定してください)", s, ModeAppend, ModeReplace)
+}
+
+// ProjectKeyFromIssue は課題キー (例: PROJECT-123) からプロジェクトキーを取り出します。
+func ProjectKeyFromIssue(issueKey string) string {
+	i := strings.LastIndex(issueKey, "-")
+	if i <= 0 {
+		return ""
+	}
+	return issueKey[:i]
+}
+
+// Client は Backlog Wiki API のクライアントです。
+type Client struct {
+	httpClient *http.Client
+	spaceURL   string
+	apiKey     string
+}
+
+// NewClient は Client を生成します。
+func NewClient(httpClient *http.Client, spaceURL, apiKey string) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{httpClient: httpClient, spaceURL: strings.TrimRight(spaceURL, "/"), apiKey: apiKey}
+}
+
+// wikiPage は Wiki API のレスポンスのうち、使用する項目です。
+type wikiPage struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// Publish は、プロジェクトの Wiki ページ pageName にレビュー結果を公開し、ページのURLを返します。
+// ページが存在しない場合は新規作成し、存在する場合は mode に従って追記または置き換えます。
+func (c *Client) Publish(ctx context.Context, projectKey, pageName, content string, mode Mode) (string, error) {
+	page, err := c.findPage(ctx, projectKey, pageName)
+	if err != nil {
+		return "", err
+	}
+	content = backlogtext.Sanitize(content)
+
+	if page == nil {
+		var project struct {
+			ID int `json:"id"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/api/v2/projects/"+url.PathEscape(projectKey), nil, &project); err != nil {
+			return "", err
+		}
+		form := url.Values{
+			"projectId": {strconv.Itoa(project.ID)},
+			"name":      {pageName},
+			"content":   {content},
+		}
+		var created wikiPage
+		if err := c.do(ctx, http.MethodPost, "/api/v2/wikis", form, &created); err != nil {
+			return "", err
+		}
+		return c.pageURL(created.ID), nil
+	}
+
+	if mode == ModeAppend {
+		// 検索結果には本文が含まれないため、追記前に現在の内容を取得します
+		var current wikiPage
+		if err := c.do(ctx, http.MethodGet, fmt.Sprintf("/api/v2/wikis/%d", page.ID), nil, &current); err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(current.Content) != "" {
+			content = strings.TrimRight(current.Content, "\n") + entrySeparator + content
+		}
+	}
+	if err := c.do(ctx, http.MethodPatch, fmt.Sprintf("/api/v2/wikis/%d", page.ID), url.Values{"content": {content}}, nil); err != nil {
+		return "", err
+	}
+	return c.pageURL(page.ID), nil
+}
+
+// findPage は名前が完全一致する Wiki ページを検索します。見つからない場合は nil を返します。
+func (c *Client) findPage(ctx context.Context, projectKey, pageName string) (*wikiPage, error) {
+	query := url.Values{"projectIdOrKey": {projectKey}, "keyword": {pageName}}
+	var pages []wikiPage
+	if err := c.do(ctx, http.MethodGet, "/api/v2/wikis?"+query.Encode(), nil, &pages); err != nil {
+		return nil, err
+	}
+	// keyword は部分一致のため、名前が完全に一致するページのみを対象とします
+	for i := range pages {
+		if pages[i].Name == pageName {
+			return &pages[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// pageURL は Wiki ページの閲覧URLを返します。
+func (c *Client) pageURL(id int) string {
+	return fmt.Sprintf("%s/alias/wiki/%d", c.spaceURL, id)
+}
+
+// do は Backlog API を呼び出し、レスポンスを out にデコードします。
+func (c *Client) do(ctx context.Context, method, path string, form url.Values, out any) error {
+	endpoint, err := url.Parse(c.spaceURL + path)
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("Backlog APIのURLが不正です: %w", err))
+	}
+	q := endpoint.Query()
+	q.Set("apiKey", c.apiKey)
+	endpoint.RawQuery = q.Encode()
+
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint.String(), body)
+	if err != nil {
+		return fmt.Errorf("Backlog APIリクエストの作成に失敗しました: %w", err)
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Backlog API (%s %s) の呼び出しに失敗しました: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("Backlog API (%s %s) がエラーを返しました (status: %d): %s", method, path, resp.StatusCode, strings.TrimSpace(string(detail)))
+		// 権限不足や存在しないプロジェクトなど、レート制限以外のクライアントエラーはリトライしても解消しません
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("Backlog API (%s %s) のレスポンスのデコードに失敗しました: %w", method, path, err)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+// Package baseline は、既存の指摘を記録したベースラインファイルを扱います。
+// staticcheck や semgrep のベースラインと同様に、導入時点の指摘を記録しておき、
+// 以降のレビューではベースラインにない新しい指摘のみを報告します。
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"git-gemini-reviewer-go/internal/findings"
+	"git-gemini-reviewer-go/internal/inline"
+	"git-gemini-reviewer-go/internal/monorepo"
+)
+
+// Version はベースラインファイルの形式のバージョンです。
+const Version = 1
+
+// Entry はベースラインに記録した1件の指摘です。
+// 照合には Fingerprint のみを使用し、その他の値は人が確認するための情報です。
+type Entry struct {
+	Fingerprint string            `json:"fingerprint"`
+	File        string            `json:"file"`
+	Line        int               `json:"line"`
+	Severity    inline.Severity   `json:"severity"`
+	Category    findings.Category `json:"category"`
+	Message     string            `json:"message"`
+}
+
+// File はベースラインファイルの内容です。
+type File struct {
+	Version   int       `json:"version"`
+	ReviewID  string    `json:"review_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Findings  []Entry   `json:"findings"`
+}
+
+// New は、構造化されたレビュー結果の指摘 (抑制された指摘を除く) を記録したベースラインを返します。
+func New(r inline.Review, diff, reviewID string, now time.Time) File {
+	code := codeLines(diff)
+	entries := make([]Entry, 0, len(r.Findings))
+	for _, f := range r.Findings {
+		entries = append(entries, Entry{
+			Fingerprint: Fingerprint(f, code),
+			File:        f.File,
+			Line:        f.Line,
+			Severity:    f.Severity,
+			Category:    f.Category,
+			Message:     strings.TrimSpace(f.Message),
+		})
+	}
+	return File{Version: Version, ReviewID: reviewID, CreatedAt: now.UTC(), Findings: entries}
+}
+
+// Load はベースラインファイルを読み込みます。
+func Load(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, fmt.Errorf("ベースラインファイルの読み込みに失敗しました (%s): %w", path, err)
+	}
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return File{}, fmt.Errorf("ベースラインファイルの解析に失敗しました (%s): %w", path, err)
+	}
+	if f.Version > Version {
+		return File{}, fmt.Errorf("ベースラインファイル (%s) の形式のバージョン %d はこのバージョンのツールでは読み込めません (対応: %d 以下)", path, f.Version, Version)
+	}
+	return f, nil
+}
+
+// Save は、一時ファイルに書き込んでから置き換えることで、ベースラインファイルを途中まで書かれた状態にせずに保存します。
+func (f File) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ベースラインのエンコードに失敗しました: %w", err)
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("ベースラインの保存先ディレクトリの作成に失敗しました (%s): %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("ベースラインの一時ファイルの作成に失敗しました: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(append(data, '\n'))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), 0o644)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("ベースラインファイルの書き込みに失敗しました (%s): %w", path, err)
+	}
+	return nil
+}
+
+// Filter は、ベースラインに記録された指摘と一致する指摘をレビュー結果から取り除き、取り除いた件数を返します。
+// 同じ Fingerprint の指摘が複数ある場合は、ベースラインに記録された件数までを取り除きます。
+func (f File) Filter(r *inline.Review, diff string) int {
+	if len(f.Findings) == 0 {
+		return 0
+	}
+	known := make(map[string]int, len(f.Findings))
+	for _, e := range f.Findings {
+		known[e.Fingerprint]++
+	}
+	code := codeLines(diff)
+	kept := r.Findings[:0]
+	removed := 0
+	for _, finding := range r.Findings {
+		if fp := Fingerprint(finding, code); known[fp] > 0 {
+			known[fp]--
+			removed++
+			continue
+		}
+		kept = append(kept, finding)
+	}
+	r.Findings = kept
+	return removed
+}
+
+// Fingerprint は、行番号の変化に影響されない指摘の識別子を返します。
+// 指摘の行が差分に含まれる場合はファイルと対象のコードの内容から、含まれない場合はファイルと指摘の本文から求めます。
+// AI が付けるカテゴリや重大度は実行ごとに揺れるため、識別子には含めません。
+func Fingerprint(f inline.Finding, code map[string]map[int]string) string {
+	var target []string
+	for line := f.Line; line <= max(f.Line, f.EndLine); line++ {
+		if text, ok := code[f.File][line]; ok {
+			target = append(target, normalize(text))
+		}
+	}
+	subject := "code\x00" + strings.Join(target, "\n")
+	if len(target) == 0 {
+		subject = "message\x00" + normalize(f.Message)
+	}
+	sum := sha256.Sum256([]byte(f.File + "\x00" + subject))
+	return hex.EncodeToString(sum[:16])
+}
+
+// normalize は、空白の違いで識別子が変わらないよう、連続する空白を1つにまとめます。
+func normalize(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// codeLines は、ファイルごとに差分の変更後の行番号と行の内容 (追加行とコンテキスト行) を返します。
+func codeLines(diff string) map[string]map[int]string {
+	result := make(map[string]map[int]string)
+	for _, f := range monorepo.SplitDiff(diff) {
+		lines := make(map[int]string)
+		next := 0
+		for _, line := range strings.Split(f.Content, "\n") {
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				next = inline.HunkStart(line)
+			case next == 0:
+			case strings.HasPrefix(line, "+"), strings.HasPrefix(line, " "):
+				lines[next] = line[1:]
+				next++
+			}
+		}
+		result[f.Path] = lines
+	}
+	return result
+}
+
+// IsNotExist は、ベースラインファイルが存在しないことによるエラーかを返します。
+func IsNotExist(err error) bool {
+	return errors.Is(err, os.ErrNotExist)
+}
+
+// Notice は、ベースラインにより除外した指摘の件数を示すバッジを返します。除外した指摘がない場合は空文字列です。
+func Notice(known int) string {
+	if known == 0 {
+		return ""
+	}
+	return fmt.Sprintf("📋 **ベースラインの既存の指摘 %d 件:** ベースラインファイルに記録済みのため、新しい指摘のみを報告しました。", known)
+}
@@ -0,0 +1,110 @@
+// Package batch は、複数のリポジトリをまとめてレビューする際のレビュー対象の一覧 (バッチ設定) を提供します。
+// 各レビュー対象で指定しなかった項目は、コマンドラインのフラグの値を使用します。
+package batch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/modelchain"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Target は1件のレビュー対象です。
+type Target struct {
+	// Name は結果の表示に使用する名前です。未指定時はリポジトリURLとフィーチャーブランチから生成します。
+	Name          string `yaml:"name"`
+	RepoURL       string `yaml:"repo_url"`
+	BaseBranch    string `yaml:"base_branch"`
+	FeatureBranch string `yaml:"feature_branch"`
+	BaseRev       string `yaml:"base_rev"`
+	FeatureRev    string `yaml:"feature_rev"`
+	Mode          string `yaml:"mode"`
+	Model         string `yaml:"model"`
+	// Excludes は、コマンドラインの --exclude に追加する除外パターンです。
+	Excludes []string `yaml:"excludes"`
+}
+
+// File はバッチ設定のファイルです。
+type File struct {
+	// Concurrency は review-all コマンドで同時にレビューするレビュー対象の件数です。0 の場合はコマンドの既定値を使用します。
+	Concurrency int      `yaml:"concurrency"`
+	Targets     []Target `yaml:"targets"`
+}
+
+// Load はバッチ設定を読み込み、各レビュー対象を検証します。
+func Load(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return File{}, fmt.Errorf("バッチ設定が見つかりません: %s", path)
+	}
+	if err != nil {
+		return File{}, fmt.Errorf("バッチ設定の読み込みに失敗しました (%s): %w", path, err)
+	}
+
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	// 誤記した項目が黙って無視されないよう、未知の項目はエラーとします
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil {
+		return File{}, fmt.Errorf("バッチ設定の解析に失敗しました (%s): %w", path, err)
+	}
+	if f.Concurrency < 0 {
+		return File{}, fmt.Errorf("バッチ設定の concurrency には0以上を指定してください: %s", path)
+	}
+	if len(f.Targets) == 0 {
+		return File{}, fmt.Errorf("バッチ設定にレビュー対象 (targets) がありません: %s", path)
+	}
+	for i, t := range f.Targets {
+		if t.RepoURL == "" {
+			return File{}, fmt.Errorf("バッチ設定の %d 件目のレビュー対象に repo_url がありません", i+1)
+		}
+		if t.FeatureBranch == "" && t.FeatureRev == "" {
+			return File{}, fmt.Errorf("バッチ設定の %d 件目のレビュー対象 (%s) に feature_branch または feature_rev がありません", i+1, t.RepoURL)
+		}
+	}
+	return f, nil
+}
+
+// Label は結果の表示に使用する名前を返します。
+func (t Target) Label() string {
+	if t.Name != "" {
+		return t.Name
+	}
+	feature := t.FeatureBranch
+	if feature == "" {
+		feature = t.FeatureRev
+	}
+	return t.RepoURL + " " + feature
+}
+
+// Apply は、base にレビュー対象の項目を重ねた設定を返します。
+// リポジトリごとに異なる値となるローカルパスは空にします。
+func (t Target) Apply(base config.ReviewConfig) config.ReviewConfig {
+	cfg := base
+	cfg.RepoURL = t.RepoURL
+	cfg.LocalPath = ""
+	cfg.FeatureBranch = t.FeatureBranch
+	cfg.FeatureRev = t.FeatureRev
+	if cfg.FeatureBranch == "" {
+		cfg.FeatureBranch = t.FeatureRev
+	}
+	cfg.BaseRev = t.BaseRev
+	if t.BaseBranch != "" {
+		cfg.BaseBranch = t.BaseBranch
+	}
+	if t.Mode != "" {
+		cfg.ReviewMode = t.Mode
+	}
+	if t.Model != "" {
+		cfg.GeminiModel, cfg.FallbackModels = modelchain.Split(t.Model)
+	}
+	if len(t.Excludes) > 0 {
+		cfg.Excludes = append(append([]string(nil), base.Excludes...), t.Excludes...)
+	}
+	return cfg
+}
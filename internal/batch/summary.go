@@ -0,0 +1,95 @@
+package batch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"git-gemini-reviewer-go/internal/verdict"
+)
+
+// Status は1件のレビュー対象のレビューの結果の状態です。
+type Status string
+
+const (
+	StatusCompleted Status = "completed"
+	StatusNoDiff    Status = "no-diff"
+	StatusFailed    Status = "failed"
+)
+
+// Result は1件のレビュー対象のレビューの結果です。
+type Result struct {
+	Name          string          `json:"name"`
+	RepoURL       string          `json:"repo_url"`
+	FeatureBranch string          `json:"feature_branch"`
+	ReviewID      string          `json:"review_id"`
+	Status        Status          `json:"status"`
+	Verdict       verdict.Verdict `json:"verdict,omitempty"`
+	Findings      int             `json:"findings"`
+	DurationMS    int64           `json:"duration_ms"`
+	Error         string          `json:"error,omitempty"`
+	Review        string          `json:"review,omitempty"`
+}
+
+// Summary は、複数のレビュー対象のレビューの結果をまとめた報告です。
+type Summary struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Completed   int       `json:"completed"`
+	NoDiff      int       `json:"no_diff"`
+	Failed      int       `json:"failed"`
+	// Blocked はリリースを止めるべき判定 (blocked) のレビュー対象の件数です。
+	Blocked int      `json:"blocked"`
+	Results []Result `json:"results"`
+}
+
+// NewSummary は、レビュー対象の順に並んだ結果から報告を作成します。
+func NewSummary(results []Result, generatedAt time.Time) Summary {
+	s := Summary{GeneratedAt: generatedAt, Results: results}
+	for _, r := range results {
+		switch r.Status {
+		case StatusCompleted:
+			s.Completed++
+		case StatusNoDiff:
+			s.NoDiff++
+		case StatusFailed:
+			s.Failed++
+		}
+		if r.Verdict == verdict.Blocked {
+			s.Blocked++
+		}
+	}
+	return s
+}
+
+// Markdown は、レビュー対象ごとの判定の一覧と、各レビュー対象のレビュー結果の全文を Markdown で返します。
+func (s Summary) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("# 🚆 複数リポジトリのレビュー結果\n\n")
+	fmt.Fprintf(&sb, "%d 件のレビュー対象: 完了 %d 件 (うち 🛑 リリース不可 %d 件)、差分なし %d 件、失敗 %d 件 (%s)\n\n",
+		len(s.Results), s.Completed, s.Blocked, s.NoDiff, s.Failed, s.GeneratedAt.Local().Format("2006/01/02 15:04:05 MST"))
+	sb.WriteString("| レビュー対象 | 状態 | 判定 | 指摘 | 所要時間 | レビューID |\n")
+	sb.WriteString("| :--- | :--- | :--- | ---: | ---: | :--- |\n")
+	for _, r := range s.Results {
+		state, judged, findings := "✅ 完了", r.Verdict.Label(), fmt.Sprint(r.Findings)
+		switch r.Status {
+		case StatusNoDiff:
+			state, judged, findings = "➖ 差分なし", "", ""
+		case StatusFailed:
+			state, judged, findings = "❌ 失敗: "+cell(r.Error), "", ""
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s | `%s` |\n",
+			cell(r.Name), state, judged, findings, (time.Duration(r.DurationMS) * time.Millisecond).Round(time.Second), r.ReviewID)
+	}
+	for _, r := range s.Results {
+		if r.Status != StatusCompleted {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n---\n\n## %s\n\n%s\n", r.Name, strings.TrimSpace(r.Review))
+	}
+	return sb.String()
+}
+
+// cell は表のセルに入れる文字列から、表を崩す文字を取り除きます。
+func cell(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
@@ -0,0 +1,217 @@
+// Package bitbucket は、Bitbucket のプルリクエストにコメントを投稿するクライアントです。
+// Bitbucket Cloud (API 2.0) と Bitbucket Server / Data Center (REST API 1.0) の両方に対応し、ベースURLから API を選択します。
+package bitbucket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/pkg/retry"
+)
+
+// CloudBaseURL は Bitbucket Cloud の API のベースURLです。
+const CloudBaseURL = "https://api.bitbucket.org"
+
+// Flavor は Bitbucket の API の種類です。
+type Flavor string
+
+const (
+	Cloud  Flavor = "cloud"
+	Server Flavor = "server"
+)
+
+// FlavorOf は、ベースURLのホストから API の種類を判別します。bitbucket.org 以外は Server / Data Center とみなします。
+func FlavorOf(baseURL string) Flavor {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return Server
+	}
+	switch strings.ToLower(u.Hostname()) {
+	case "bitbucket.org", "api.bitbucket.org":
+		return Cloud
+	}
+	return Server
+}
+
+// Repo は Bitbucket のリポジトリです。
+// Owner は Cloud ではワークスペース、Server ではプロジェクトキーです。
+type Repo struct {
+	Owner string
+	Slug  string
+}
+
+// String は 'owner/slug' 形式の表記を返します。
+func (r Repo) String() string {
+	return r.Owner + "/" + r.Slug
+}
+
+// ParseRepo は 'owner/slug' またはリポジトリのクローンURLからリポジトリを読み取ります。
+// Server の HTTPS URL (https://host/scm/PROJ/repo.git) と SSH URL (ssh://git@host:7999/PROJ/repo.git) にも対応します。
+func ParseRepo(spec string) (Repo, error) {
+	s := strings.TrimSpace(spec)
+	switch {
+	case strings.Contains(s, "://"):
+		u, err := url.Parse(s)
+		if err != nil {
+			return Repo{}, fmt.Errorf("Bitbucket のリポジトリURLが不正です: '%s': %w", spec, err)
+		}
+		s = u.Path
+	case strings.HasPrefix(s, "git@"):
+		_, s, _ = strings.Cut(s, ":")
+	}
+	s = strings.TrimSuffix(strings.Trim(s, "/"), ".git")
+	s = strings.TrimPrefix(s, "scm/")
+	owner, slug, ok := strings.Cut(s, "/")
+	if !ok || owner == "" || slug == "" || strings.Contains(slug, "/") {
+		return Repo{}, fmt.Errorf("Bitbucket のリポジトリの指定が不正です: '%s' ('ワークスペースまたはプロジェクトキー/リポジトリ' またはリポジトリURLを指定してください)", spec)
+	}
+	return Repo{Owner: owner, Slug: slug}, nil
+}
+
+// Credentials は Bitbucket の認証情報です。
+// Token (Cloud のアクセストークン、Server の HTTP アクセストークン) を優先し、未指定の場合はユーザー名とアプリパスワードで認証します。
+type Credentials struct {
+	Token    string
+	Username string
+	Password string
+}
+
+// Client は Bitbucket の REST API のクライアントです。
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	flavor     Flavor
+	creds      Credentials
+}
+
+// NewClient は Client を生成します。baseURL が空の場合は Bitbucket Cloud を使用します。
+func NewClient(httpClient *http.Client, baseURL string, creds Credentials) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if baseURL == "" {
+		baseURL = CloudBaseURL
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+	flavor := FlavorOf(baseURL)
+	if flavor == Cloud {
+		// bitbucket.org の閲覧URLが指定された場合も API のホストを使用する
+		baseURL = CloudBaseURL
+	}
+	return &Client{httpClient: httpClient, baseURL: baseURL, flavor: flavor, creds: creds}
+}
+
+// Flavor はクライアントが使用する API の種類を返します。
+func (c *Client) Flavor() Flavor {
+	return c.flavor
+}
+
+// PostPullRequestComment はプルリクエストにコメントを投稿し、コメントのURLを返します。
+func (c *Client) PostPullRequestComment(ctx context.Context, repo Repo, pullRequestID int, content string) (string, error) {
+	if c.flavor == Cloud {
+		return c.postCloudComment(ctx, repo, pullRequestID, content)
+	}
+	return c.postServerComment(ctx, repo, pullRequestID, content)
+}
+
+// postCloudComment は Bitbucket Cloud の API 2.0 でコメントを投稿します。
+func (c *Client) postCloudComment(ctx context.Context, repo Repo, pullRequestID int, content string) (string, error) {
+	path := fmt.Sprintf("/2.0/repositories/%s/%s/pullrequests/%d/comments", url.PathEscape(repo.Owner), url.PathEscape(repo.Slug), pullRequestID)
+	input := map[string]any{"content": map[string]string{"raw": content}}
+	var output struct {
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+	}
+	if err := c.call(ctx, path, input, &output); err != nil {
+		return "", err
+	}
+	return output.Links.HTML.Href, nil
+}
+
+// postServerComment は Bitbucket Server / Data Center の REST API 1.0 でコメントを投稿します。
+func (c *Client) postServerComment(ctx context.Context, repo Repo, pullRequestID int, content string) (string, error) {
+	path := fmt.Sprintf("/rest/api/1.0/projects/%s/repos/%s/pull-requests/%d/comments", url.PathEscape(repo.Owner), url.PathEscape(repo.Slug), pullRequestID)
+	var output struct {
+		ID int `json:"id"`
+	}
+	if err := c.call(ctx, path, map[string]string{"text": content}, &output); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s/projects/%s/repos/%s/pull-requests/%d/overview?commentId=%d", c.baseURL, repo.Owner, repo.Slug, pullRequestID, output.ID), nil
+}
+
+// call は JSON のリクエストを POST し、レスポンスを output にデコードします。
+func (c *Client) call(ctx context.Context, path string, input any, output any) error {
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("Bitbucket API (%s) のリクエストのエンコードに失敗しました: %w", path, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("Bitbucket APIリクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if c.creds.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.creds.Token)
+	} else {
+		req.SetBasicAuth(c.creds.Username, c.creds.Password)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Bitbucket API (%s) の呼び出しに失敗しました: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("Bitbucket API (%s) のレスポンスの読み込みに失敗しました: %w", path, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		err := fmt.Errorf("Bitbucket API (%s) がエラーを返しました (status: %d): %s", path, resp.StatusCode, errorMessage(body))
+		// レート制限以外のクライアントエラーはリトライしても解消しません
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	if output != nil && len(body) > 0 {
+		if err := json.Unmarshal(body, output); err != nil {
+			return fmt.Errorf("Bitbucket API (%s) のレスポンスのデコードに失敗しました: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// errorMessage は Cloud ({"error": {"message": ...}}) と Server ({"errors": [{"message": ...}]}) のエラー形式からメッセージを取り出します。
+func errorMessage(body []byte) string {
+	var apiErr struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &apiErr); err != nil {
+		return strings.TrimSpace(string(body))
+	}
+	messages := []string{}
+	if apiErr.Error.Message != "" {
+		messages = append(messages, apiErr.Error.Message)
+	}
+	for _, e := range apiErr.Errors {
+		messages = append(messages, e.Message)
+	}
+	return strings.Join(messages, "; ")
+}
@@ -4,65 +4,449 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"slices"
 
+	"git-gemini-reviewer-go/internal/aiprovider"
+	"git-gemini-reviewer-go/internal/archive"
 	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/difftransform"
+	"git-gemini-reviewer-go/internal/estimate"
+	"git-gemini-reviewer-go/internal/experiment"
+	"git-gemini-reviewer-go/internal/followup"
+	"git-gemini-reviewer-go/internal/gcs"
+	"git-gemini-reviewer-go/internal/gerrit"
+	"git-gemini-reviewer-go/internal/ghaction"
+	"git-gemini-reviewer-go/internal/gitclient"
+	"git-gemini-reviewer-go/internal/history"
+	"git-gemini-reviewer-go/internal/modelchain"
+	"git-gemini-reviewer-go/internal/persona"
+	"git-gemini-reviewer-go/internal/prompttmpl"
+	"git-gemini-reviewer-go/internal/ratelimit"
+	"git-gemini-reviewer-go/internal/respcache"
+	"git-gemini-reviewer-go/internal/reviewmode"
 	"git-gemini-reviewer-go/internal/runner"
+	"git-gemini-reviewer-go/internal/sampling"
+	"git-gemini-reviewer-go/internal/tokencount"
+	"git-gemini-reviewer-go/internal/vertexai"
 
 	"github.com/shouni/gemini-reviewer-core/pkg/adapters"
 	"github.com/shouni/gemini-reviewer-core/pkg/prompts"
 )
 
-// buildGitService は adapters.GitService のインスタンスを構築します。
+// BuildGitClient は、レビューを実行せずにリポジトリを操作するための gitclient.Client を構築します。
+// 認証などの設定はレビューで使用する GitService と共通です。
+func BuildGitClient(ctx context.Context, cfg config.ReviewConfig) (*gitclient.Client, error) {
+	return buildGitService(cfg, CacheFromContext(ctx))
+}
+
+// buildGitService は adapters.GitService を実装する gitclient.Client のインスタンスを構築します。
 // この関数は BuildReviewRunner の内部ヘルパーとして使用されます。
-func buildGitService(cfg config.ReviewConfig) adapters.GitService {
-	return adapters.NewGitAdapter(
-		cfg.LocalPath,
-		cfg.SSHKeyPath,
-		adapters.WithInsecureSkipHostKeyCheck(cfg.SkipHostKeyCheck),
-		adapters.WithBaseBranch(cfg.BaseBranch),
-	)
+func buildGitService(cfg config.ReviewConfig, cache *Cache) (*gitclient.Client, error) {
+	cleanup, err := gitclient.ParseCleanupStrategy(cfg.GitCleanup)
+	if err != nil {
+		return nil, err
+	}
+	submodules, err := gitclient.ParseSubmoduleMode(cfg.Submodules)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.CloneDepth < 0 {
+		return nil, fmt.Errorf("--clone-depth には0以上を指定してください")
+	}
+	opts := []gitclient.Option{
+		gitclient.WithInsecureSkipHostKeyCheck(cfg.SkipHostKeyCheck),
+		gitclient.WithBaseBranch(cfg.BaseBranch),
+		gitclient.WithCleanupStrategy(cleanup),
+		gitclient.WithHTTPToken(cfg.GitHTTPUsername, cfg.GitHTTPToken),
+		gitclient.WithHTTPTokenHosts(cfg.GitTokenHosts...),
+		gitclient.WithSSHAgent(cfg.UseSSHAgent),
+		gitclient.WithSSHKeyPassphrase(cfg.SSHKeyPassphrase),
+		gitclient.WithFetchTags(cfg.BaseRev != "" || cfg.FeatureRev != ""),
+		gitclient.WithDepth(cfg.CloneDepth),
+		gitclient.WithInMemory(cfg.InMemoryRepo),
+		gitclient.WithSubmoduleMode(submodules),
+		gitclient.WithFetchBranches(fetchBranches(cfg)...),
+		gitclient.WithAuthCache(cache.authCache()),
+	}
+	if cfg.GerritChange != "" {
+		change, err := gerrit.ParseChange(cfg.GerritChange)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, gitclient.WithExtraRefSpecs(change.RefSpec()))
+	}
+	if cfg.GitHubPullRequest != 0 {
+		opts = append(opts, gitclient.WithExtraRefSpecs(ghaction.PullRefSpec(cfg.GitHubPullRequest)))
+	}
+	return gitclient.New(cfg.LocalPath, cfg.SSHKeyPath, opts...), nil
+}
+
+// fetchBranches は Fetch で取得するブランチを返します。nil の場合はすべてのブランチをフェッチします。
+// リビジョンは任意のブランチのコミットを指しうるため、リビジョンの指定時はすべてのブランチをフェッチします。
+// スタックは削除済みの親ブランチを含みうるため、同様にすべてのブランチをフェッチします。
+// Gerrit の変更と GitHub のプルリクエストは、レビュー対象を ExtraRefSpecs でフェッチします。
+func fetchBranches(cfg config.ReviewConfig) []string {
+	if cfg.FetchAll || cfg.BaseRev != "" || cfg.FeatureRev != "" || len(cfg.Stack) > 0 {
+		return nil
+	}
+	branches := []string{cfg.BaseBranch}
+	if cfg.GerritChange == "" && cfg.GitHubPullRequest == 0 {
+		branches = append(branches, cfg.FeatureBranch)
+	}
+	// --feature-branches のブランチは、最初のレビューのフェッチでまとめて取得します
+	return append(branches, cfg.FeatureBranches...)
+}
+
+// buildTokenCounter は、送信前にプロンプトの入力トークン数を数える tokencount.Counter を構築します。
+// Vertex AI を使用する場合は Vertex AI の countTokens で数えます。
+// Gemini 以外の AI を使用する場合と、認証情報を取得できない場合は、ネットワークに接続しない概算を使用します。
+// cache が指定された場合は、同じモデルの Counter を再利用します。
+func buildTokenCounter(ctx context.Context, cfg config.ReviewConfig, cache *Cache) (tokencount.Counter, error) {
+	switch cfg.TokenCounter {
+	case tokencount.MethodEstimate:
+		return tokencount.Estimator{}, nil
+	case "", tokencount.MethodAPI:
+	default:
+		return nil, fmt.Errorf("不明なトークン数の計数方法です: '%s' ('%s' または '%s' を指定してください)", cfg.TokenCounter, tokencount.MethodAPI, tokencount.MethodEstimate)
+	}
+	if cfg.AIProvider != "" && cfg.AIProvider != aiprovider.Gemini {
+		return tokencount.Estimator{}, nil
+	}
+	if vertex := vertexOptions(cfg); vertex != nil {
+		key := fmt.Sprintf("token-counter\x00vertex\x00%s\x00%v", cfg.GeminiModel, *vertex)
+		counter, err := cached(cache, key, func() (*vertexai.Counter, error) {
+			return vertexai.NewCounter(ctx, http.DefaultClient, cfg.GeminiModel, *vertex)
+		})
+		if err != nil {
+			slog.Warn("Vertex AI の countTokens を使用できないため、入力トークン数を概算します。", "error", err)
+			return tokencount.Estimator{}, nil
+		}
+		return counter, nil
+	}
+	counter, err := cached(cache, "token-counter\x00gemini\x00"+cfg.GeminiModel, func() (*tokencount.Gemini, error) {
+		return tokencount.NewGemini(http.DefaultClient, cfg.GeminiModel)
+	})
+	if err != nil {
+		slog.Warn("countTokens API を使用できないため、入力トークン数を概算します。", "error", err)
+		return tokencount.Estimator{}, nil
+	}
+	return counter, nil
 }
 
 // buildGeminiService は adapters.CodeReviewAI のインスタンスを構築します。
+// cfg.AIProvider で指定された AI を aiprovider のレジストリから選択します。
+// cache が指定された場合は、同じプロバイダとモデルのクライアントを再利用します。
+// cfg.FallbackModels が指定された場合は、先頭のモデルから順に試す modelchain.Chain を返します。
 // この関数は BuildReviewRunner の内部ヘルパーとして使用されます。
-func buildGeminiService(ctx context.Context, cfg config.ReviewConfig) (adapters.CodeReviewAI, error) {
-	geminiService, err := adapters.NewGeminiAdapter(ctx, cfg.GeminiModel)
+func buildGeminiService(ctx context.Context, cfg config.ReviewConfig, cache *Cache) (adapters.CodeReviewAI, error) {
+	provider, err := aiprovider.Lookup(cfg.AIProvider)
 	if err != nil {
-		return nil, fmt.Errorf("Gemini Service の構築に失敗しました: %w", err)
+		return nil, err
+	}
+	opts := aiprovider.Options{
+		Model:         cfg.GeminiModel,
+		HTTPClient:    http.DefaultClient,
+		BaseURL:       cfg.AIBaseURL,
+		Stream:        cfg.Stream,
+		ContextLength: cfg.OllamaNumCtx,
+		Vertex:        vertexOptions(cfg),
+		Sampling:      samplingParams(cfg),
+	}
+	if cfg.AIProvider == aiprovider.Ollama && cfg.OllamaURL != "" {
+		opts.BaseURL = cfg.OllamaURL
+	}
+	models := append([]string{cfg.GeminiModel}, cfg.FallbackModels...)
+	chain := make([]modelchain.Model, 0, len(models))
+	for _, model := range models {
+		opts.Model = model
+		key := fmt.Sprintf("ai\x00%s\x00%s\x00%s\x00%t\x00%d\x00%v\x00%v", cfg.AIProvider, model, opts.BaseURL, cfg.Stream, opts.ContextLength, opts.Vertex, opts.Sampling)
+		service, err := cached(cache, key, func() (adapters.CodeReviewAI, error) {
+			return provider.New(ctx, opts)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("AI Service (%s, %s) の構築に失敗しました: %w", cfg.AIProvider, model, err)
+		}
+		chain = append(chain, modelchain.Model{Name: model, AI: service})
+	}
+	if len(chain) == 1 {
+		return chain[0].AI, nil
 	}
+	return modelchain.New(chain...), nil
+}
+
+// vertexOptions は、cfg.Vertex が有効な場合に Vertex AI への接続の設定を返します。無効な場合は nil を返します。
+func vertexOptions(cfg config.ReviewConfig) *vertexai.Options {
+	if !cfg.Vertex {
+		return nil
+	}
+	return &vertexai.Options{
+		Project:         cfg.VertexProject,
+		Location:        cfg.VertexLocation,
+		CredentialsFile: cfg.VertexCredentials,
+	}
+}
+
+// buildResponseCache は、--response-cache が指定され、--no-cache が指定されていない場合に AI の応答のキャッシュを構築します。
+// キャッシュを使用しない場合は nil を返します。
+func buildResponseCache(cfg config.ReviewConfig) (respcache.Store, error) {
+	if cfg.ResponseCache == "" || cfg.NoCache {
+		return nil, nil
+	}
+	if cfg.ResponseCacheTTL <= 0 {
+		return nil, fmt.Errorf("--response-cache-ttl には正の期間を指定してください: %s", cfg.ResponseCacheTTL)
+	}
+	store, err := respcache.Open(cfg.ResponseCache)
+	if err != nil {
+		return nil, fmt.Errorf("応答のキャッシュの構築に失敗しました: %w", err)
+	}
+	return store, nil
+}
+
+// samplingParams は、応答の生成を調整するパラメータ (--ai-temperature / --ai-top-p / --ai-max-output-tokens) を返します。
+func samplingParams(cfg config.ReviewConfig) sampling.Params {
+	return sampling.Params{
+		Temperature:     cfg.AITemperature,
+		TopP:            cfg.AITopP,
+		MaxOutputTokens: cfg.AIMaxOutputTokens,
+	}
+}
 
-	return geminiService, nil
+// buildDiffTransformers は、差分をプロンプトの組み立て前に加工する変換器の列を構築します。
+func buildDiffTransformers(cfg config.ReviewConfig) (difftransform.Chain, error) {
+	if len(cfg.Excludes) > 0 && !slices.Contains(cfg.DiffTransforms, "exclude") {
+		slog.Warn("--diff-transform に 'exclude' が含まれていないため、除外パターンは適用されません。", "excludes", cfg.Excludes)
+	}
+	chain, err := difftransform.Build(cfg.DiffTransforms, difftransform.Options{Excludes: cfg.Excludes, Omits: cfg.Omits})
+	if err != nil {
+		return nil, fmt.Errorf("差分の変換器の構築に失敗しました: %w", err)
+	}
+	return chain, nil
+}
+
+// buildFollowUpPrompt は、同じフィーチャーブランチの前回のレビューが履歴にある場合に、その指摘の対応状況を確認させるプロンプトを返します。
+// 履歴を読めない場合もレビューは継続するため、エラーはログに留めます。
+func buildFollowUpPrompt(cfg config.ReviewConfig) string {
+	if !cfg.FollowUp || cfg.HistoryFile == "" || cfg.FeatureBranch == "" {
+		return ""
+	}
+	previous, err := history.NewStore(cfg.HistoryFile).Latest(cfg.RepoURL, cfg.FeatureBranch)
+	if err != nil {
+		slog.Warn("前回のレビューの読み込みに失敗しました。フォローアップなしでレビューします。", "path", cfg.HistoryFile, "error", err)
+		return ""
+	}
+	if previous == nil {
+		return ""
+	}
+	prompt := followup.Prompt(*previous)
+	if prompt != "" {
+		slog.Info("前回のレビューの指摘をフォローアップします。", "previous_review_id", previous.ReviewID)
+	}
+	return prompt
+}
+
+// buildSinceReview は、cfg.SinceLastReview が有効で、同じフィーチャーブランチの前回のレビューのコミットが履歴にある場合に、
+// 前回のレビュー以降の差分のみをレビューする設定を返します。履歴を読めない場合はブランチ全体をレビューするため、エラーはログに留めます。
+func buildSinceReview(cfg config.ReviewConfig) (runner.SinceReview, bool) {
+	if !cfg.SinceLastReview || cfg.HistoryFile == "" || cfg.FeatureBranch == "" {
+		return runner.SinceReview{}, false
+	}
+	previous, err := history.NewStore(cfg.HistoryFile).Latest(cfg.RepoURL, cfg.FeatureBranch)
+	if err != nil {
+		slog.Warn("前回のレビューの読み込みに失敗しました。ブランチ全体をレビューします。", "path", cfg.HistoryFile, "error", err)
+		return runner.SinceReview{}, false
+	}
+	if previous == nil || previous.HeadCommit == "" {
+		slog.Info("前回レビューしたコミットが履歴にないため、ブランチ全体をレビューします。", "feature_branch", cfg.FeatureBranch)
+		return runner.SinceReview{}, false
+	}
+	return runner.SinceReview{ReviewID: previous.ReviewID, HeadCommit: previous.HeadCommit}, true
+}
+
+// buildPromptBuilder は、プロンプトの A/B 実験で B が割り当てられた場合は指定されたテンプレートを、
+// それ以外の場合は --prompt-file / --prompt-dir のテンプレート (ないレビューモードは組み込みのテンプレート) を使用する PromptBuilder を構築します。
+// 組み込みと利用者のテンプレートの PromptBuilder は、cache が指定された場合に再利用します。
+func buildPromptBuilder(cfg config.ReviewConfig, cache *Cache) (prompts.ReviewPromptBuilder, error) {
+	if cfg.PromptVariant == experiment.VariantB {
+		tmpl, err := experiment.LoadTemplate(cfg.PromptVariantB)
+		if err != nil {
+			return nil, err
+		}
+		return experiment.NewPromptBuilder(tmpl), nil
+	}
+	promptBuilder, err := cached(cache, "prompt-builder", func() (prompts.ReviewPromptBuilder, error) {
+		core, err := prompts.NewPromptBuilder()
+		if err != nil {
+			return nil, err
+		}
+		return reviewmode.NewPromptBuilder(core), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Prompt Builder の構築に失敗しました: %w", err)
+	}
+	if cfg.PromptFile == "" && cfg.PromptDir == "" {
+		return promptBuilder, nil
+	}
+	return cached(cache, "prompt-templates", func() (prompts.ReviewPromptBuilder, error) {
+		templates, err := prompttmpl.Load(cfg.PromptFile, cfg.PromptDir)
+		if err != nil {
+			return nil, err
+		}
+		return templates.WithFallback(promptBuilder), nil
+	})
+}
+
+// buildArchiver は archive.Archiver のインスタンスを構築します。
+// GCS URI が指定された場合は go-remote-io の Writer を利用します。Writer は cache が指定された場合に再利用します。
+func buildArchiver(ctx context.Context, cfg config.ReviewConfig, cache *Cache) (archive.Archiver, error) {
+	var writer gcs.Writer
+	if gcs.IsURI(cfg.ArchiveURI) {
+		w, err := cached(cache, "gcs-writer", func() (gcs.Writer, error) {
+			return gcs.NewWriter(ctx)
+		})
+		if err != nil {
+			return nil, err
+		}
+		writer = w
+	}
+
+	// --git-token などのフラグで指定された秘匿情報は環境変数に含まれないため、明示的に除去対象に加えます。
+	store, err := archive.NewStore(cfg.ArchiveURI, writer, cfg.GitHTTPToken, cfg.CallbackSecret, cfg.SSHKeyPassphrase)
+	if err != nil {
+		return nil, fmt.Errorf("Archiver の構築に失敗しました: %w", err)
+	}
+	return store, nil
+}
+
+// buildPromptOptions は、AI に送るプロンプトの内容に影響する任意の依存関係 (差分の変換器、ペルソナ、フォローアップ、前回のレビュー以降の差分の範囲) を構築します。
+func buildPromptOptions(cfg config.ReviewConfig) ([]runner.Option, error) {
+	var opts []runner.Option
+	transformers, err := buildDiffTransformers(cfg)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, runner.WithDiffTransformers(transformers))
+	if len(cfg.Personas) > 0 {
+		personaPrompt, err := persona.Compose(cfg.Personas)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, runner.WithPersonaPrompt(personaPrompt))
+		slog.Debug("レビュアーペルソナを設定しました。", slog.Any("personas", cfg.Personas))
+	}
+	if prompt := buildFollowUpPrompt(cfg); prompt != "" {
+		opts = append(opts, runner.WithFollowUpPrompt(prompt))
+	}
+	if since, ok := buildSinceReview(cfg); ok {
+		opts = append(opts, runner.WithSinceReview(since))
+	}
+	return opts, nil
+}
+
+// BuildEstimateRunner は、AI の代わりに ai を使用する ReviewRunner を返します。
+// レビューと同じ差分とプロンプトを ai に渡しますが、アーカイブとレート制限は設定しません。
+// モデルを呼び出さずにプロンプトの大きさを見積もるために使用します。
+func BuildEstimateRunner(ctx context.Context, cfg config.ReviewConfig, ai adapters.CodeReviewAI) (*runner.ReviewRunner, error) {
+	cache := CacheFromContext(ctx)
+	gitService, err := buildGitService(cfg, cache)
+	if err != nil {
+		return nil, err
+	}
+	promptBuilder, err := buildPromptBuilder(cfg, cache)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := buildPromptOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return runner.NewReviewRunner(gitService, ai, promptBuilder, opts...), nil
 }
 
 // BuildReviewRunner は、必要な依存関係をすべて構築し、
 // 実行可能な ReviewRunner のインスタンスを返します。
+// ctx に ContextWithCache で Cache が設定されている場合は、構築済みの依存関係を再利用します。
 func BuildReviewRunner(ctx context.Context, cfg config.ReviewConfig) (*runner.ReviewRunner, error) {
+	cache := CacheFromContext(ctx)
+
 	// 1. GitService の構築
-	gitService := buildGitService(cfg)
-	slog.Debug("GitService (Adapter) を構築しました。",
+	gitService, err := buildGitService(cfg, cache)
+	if err != nil {
+		return nil, err
+	}
+	slog.Debug("GitService (gitclient) を構築しました。",
 		slog.String("local_path", cfg.LocalPath),
 		slog.String("base_branch", cfg.BaseBranch),
 	)
 
 	// 2. GeminiService の構築
-	geminiService, err := buildGeminiService(ctx, cfg)
+	geminiService, err := buildGeminiService(ctx, cfg, cache)
 	if err != nil {
 		return nil, err
 	}
-	slog.Debug("GeminiService (Adapter) を構築しました。", slog.String("model", cfg.GeminiModel))
+	slog.Debug("GeminiService (Adapter) を構築しました。", slog.String("provider", cfg.AIProvider), slog.String("model", cfg.GeminiModel))
 
 	// 3. Prompt Builder の構築
-	promptBuilder, err := prompts.NewPromptBuilder()
+	promptBuilder, err := buildPromptBuilder(cfg, cache)
 	if err != nil {
-		return nil, fmt.Errorf("Prompt Builder の構築に失敗しました: %w", err)
+		return nil, err
+	}
+	slog.Debug("PromptBuilderを構築しました。", slog.String("component", "PromptBuilder"), slog.String("prompt_variant", cfg.PromptVariant))
+
+	// 4. 任意の依存関係 (差分の変換器、ペルソナ、アーカイブ、レート制限) の構築
+	opts, err := buildPromptOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ArchiveURI != "" {
+		archiver, err := buildArchiver(ctx, cfg, cache)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, runner.WithArchiver(archiver))
+		slog.Debug("Archiver を構築しました。", slog.String("uri", cfg.ArchiveURI))
+	}
+
+	if limiter := ratelimit.New(ratelimit.Limits{
+		RequestsPerMinute: cfg.AIRequestsPerMinute,
+		TokensPerMinute:   cfg.AITokensPerMinute,
+	}, cfg.RateLimitStateFile); limiter != nil {
+		opts = append(opts, runner.WithRateLimiter(limiter))
+		slog.Debug("AIリクエストのレート制限を設定しました。",
+			slog.Int("qpm", cfg.AIRequestsPerMinute),
+			slog.Int("tpm", cfg.AITokensPerMinute),
+			slog.String("state_file", cfg.RateLimitStateFile),
+		)
+	}
+
+	if store, err := buildResponseCache(cfg); err != nil {
+		return nil, err
+	} else if store != nil {
+		opts = append(opts, runner.WithResponseCache(store, cfg.ResponseCacheTTL))
+		slog.Debug("応答のキャッシュを設定しました。", slog.String("uri", cfg.ResponseCache), slog.Duration("ttl", cfg.ResponseCacheTTL))
+	}
+
+	if cfg.PricingFile != "" {
+		prices, err := estimate.LoadPrices(cfg.PricingFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, runner.WithPrices(prices))
+		slog.Debug("料金表を読み込みました。", slog.String("path", cfg.PricingFile))
+	}
+
+	counter, err := buildTokenCounter(ctx, cfg, cache)
+	if err != nil {
+		return nil, err
 	}
-	slog.Debug("PromptBuilderを構築しました。", slog.String("component", "PromptBuilder"))
+	opts = append(opts, runner.WithTokenCounter(counter))
 
-	// 4. 依存関係を注入して Runner を組み立てる
+	// 5. 依存関係を注入して Runner を組み立てる
 	reviewRunner := runner.NewReviewRunner(
 		gitService,
 		geminiService,
 		promptBuilder,
+		opts...,
 	)
 
 	slog.Debug("ReviewRunner の構築が完了しました。")
@@ -0,0 +1,86 @@
+package builder
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+
+	"git-gemini-reviewer-go/internal/gitclient"
+)
+
+// Cache は、同じプロセスで複数のレビューを実行するモード (slack-app や selftest) で、
+// 実行ごとに構築し直す必要のない依存関係 (Gemini のクライアント、countTokens の Counter、PromptBuilder、GCS の Writer、SSH の認証、Slack・Backlog・GitHub のクライアント) を保持します。
+// 複数のゴルーチンから使用できます。使用後は Close を呼び出してください。
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]any
+	auth    *gitclient.AuthCache
+}
+
+// NewCache は空の Cache を生成します。
+func NewCache() *Cache {
+	return &Cache{entries: make(map[string]any), auth: gitclient.NewAuthCache()}
+}
+
+// Close は、保持している依存関係のうち io.Closer を実装するものを閉じ、キャッシュを空にします。
+func (c *Cache) Close() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var errs []error
+	for _, entry := range c.entries {
+		if closer, ok := entry.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	c.entries = make(map[string]any)
+	c.auth = gitclient.NewAuthCache()
+	return errors.Join(errs...)
+}
+
+// authCache は SSH の認証のキャッシュを返します。c が nil の場合は nil を返します。
+func (c *Cache) authCache() *gitclient.AuthCache {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.auth
+}
+
+// cached は、key の依存関係がキャッシュにあればそれを返し、なければ build で構築して保持します。
+// c が nil の場合は、毎回 build で構築します。
+func cached[T any](c *Cache, key string, build func() (T, error)) (T, error) {
+	if c == nil {
+		return build()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, ok := c.entries[key].(T); ok {
+		slog.Debug("キャッシュ済みの依存関係を再利用します。", slog.String("key", key))
+		return entry, nil
+	}
+	v, err := build()
+	if err != nil {
+		return v, err
+	}
+	c.entries[key] = v
+	return v, nil
+}
+
+type cacheKey struct{}
+
+// ContextWithCache は、BuildReviewRunner が依存関係を再利用する Cache を設定したコンテキストを返します。
+func ContextWithCache(ctx context.Context, c *Cache) context.Context {
+	return context.WithValue(ctx, cacheKey{}, c)
+}
+
+// CacheFromContext は、コンテキストに設定された Cache を返します。設定されていない場合は nil を返します。
+func CacheFromContext(ctx context.Context) *Cache {
+	c, _ := ctx.Value(cacheKey{}).(*Cache)
+	return c
+}
@@ -0,0 +1,86 @@
+package builder
+
+import (
+	"context"
+	"testing"
+
+	"git-gemini-reviewer-go/internal/config"
+)
+
+type fakeSlackSender struct{}
+
+func (*fakeSlackSender) SendTextWithHeader(context.Context, string, string) error { return nil }
+
+func TestNotifierClientsAreReusedWithCache(t *testing.T) {
+	cache := NewCache()
+	defer cache.Close()
+	ctx := ContextWithCache(context.Background(), cache)
+
+	if first, second := GitHubClient(ctx, nil, "", "token"), GitHubClient(ctx, nil, "", "token"); first != second {
+		t.Error("GitHub のクライアントが再利用されていません")
+	}
+	if first, other := GitHubClient(ctx, nil, "", "token"), GitHubClient(ctx, nil, "", "other"); first == other {
+		t.Error("異なるトークンの GitHub のクライアントが共有されています")
+	}
+	if first, second := BacklogAttachClient(ctx, nil, "https://example.backlog.com", "key"), BacklogAttachClient(ctx, nil, "https://example.backlog.com", "key"); first != second {
+		t.Error("Backlog の課題のクライアントが再利用されていません")
+	}
+	if first, second := BacklogWikiClient(ctx, nil, "https://example.backlog.com", "key"), BacklogWikiClient(ctx, nil, "https://example.backlog.com", "key"); first != second {
+		t.Error("Backlog Wiki のクライアントが再利用されていません")
+	}
+	if first, second := BacklogPullRequestClient(ctx, nil, "https://example.backlog.com", "key"), BacklogPullRequestClient(ctx, nil, "https://example.backlog.com", "key"); first != second {
+		t.Error("Backlog のプルリクエストのクライアントが再利用されていません")
+	}
+
+	builds := 0
+	build := func() (SlackSender, error) {
+		builds++
+		return &fakeSlackSender{}, nil
+	}
+	first, err := SlackClient(ctx, "https://hooks.slack.com/services/x", build)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := SlackClient(ctx, "https://hooks.slack.com/services/x", build)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second || builds != 1 {
+		t.Errorf("Slack のクライアントが再利用されていません (構築回数: %d)", builds)
+	}
+}
+
+func TestNotifierClientsAreBuiltEachTimeWithoutCache(t *testing.T) {
+	ctx := context.Background()
+	if GitHubClient(ctx, nil, "", "token") == GitHubClient(ctx, nil, "", "token") {
+		t.Error("Cache が設定されていないのに GitHub のクライアントが共有されています")
+	}
+}
+
+func TestBuildTokenCounterReusesGeminiCounter(t *testing.T) {
+	t.Setenv("GEMINI_API_KEY", "test-key")
+	cache := NewCache()
+	defer cache.Close()
+	cfg := config.ReviewConfig{GeminiModel: "gemini-2.5-flash"}
+
+	first, err := buildTokenCounter(context.Background(), cfg, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := buildTokenCounter(context.Background(), cfg, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second {
+		t.Error("countTokens API の Counter が再利用されていません")
+	}
+
+	cfg.GeminiModel = "gemini-2.5-pro"
+	other, err := buildTokenCounter(context.Background(), cfg, cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == first {
+		t.Error("異なるモデルの Counter が共有されています")
+	}
+}
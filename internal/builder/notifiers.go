@@ -0,0 +1,73 @@
+package builder
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"git-gemini-reviewer-go/internal/backlogattach"
+	"git-gemini-reviewer-go/internal/backlogpr"
+	"git-gemini-reviewer-go/internal/backlogwiki"
+	"git-gemini-reviewer-go/internal/github"
+)
+
+// SlackSender は、見出し付きのテキストを Slack Webhook に投稿するクライアントです。
+// go-notifier の Slack のクライアントを、具体的な型に依存せずにキャッシュするためのものです。
+type SlackSender interface {
+	SendTextWithHeader(ctx context.Context, title, text string) error
+}
+
+// SlackClient は webhookURL に投稿する Slack のクライアントを返します。
+// ctx に ContextWithCache で Cache が設定されている場合は、同じ Webhook URL のクライアントを再利用します。
+func SlackClient(ctx context.Context, webhookURL string, build func() (SlackSender, error)) (SlackSender, error) {
+	return cached(CacheFromContext(ctx), "slack\x00"+credentialKey(webhookURL), build)
+}
+
+// GitHubClient は GitHub の API のクライアントを返します。
+// ctx に Cache が設定されている場合は、同じ API の URL とトークンのクライアントを再利用します。
+func GitHubClient(ctx context.Context, httpClient *http.Client, baseURL, token string) *github.Client {
+	client, _ := cached(CacheFromContext(ctx), "github\x00"+baseURL+"\x00"+credentialKey(token), func() (*github.Client, error) {
+		return github.NewClient(httpClient, baseURL, token), nil
+	})
+	return client
+}
+
+// BacklogAttachClient は Backlog の課題にコメントと添付ファイルを投稿するクライアントを返します。
+// ctx に Cache が設定されている場合は、同じスペースと API キーのクライアントを再利用します。
+func BacklogAttachClient(ctx context.Context, httpClient *http.Client, spaceURL, apiKey string) *backlogattach.Client {
+	client, _ := cached(CacheFromContext(ctx), backlogKey("attach", spaceURL, apiKey), func() (*backlogattach.Client, error) {
+		return backlogattach.NewClient(httpClient, spaceURL, apiKey), nil
+	})
+	return client
+}
+
+// BacklogWikiClient は Backlog Wiki のクライアントを返します。
+// ctx に Cache が設定されている場合は、同じスペースと API キーのクライアントを再利用します。
+func BacklogWikiClient(ctx context.Context, httpClient *http.Client, spaceURL, apiKey string) *backlogwiki.Client {
+	client, _ := cached(CacheFromContext(ctx), backlogKey("wiki", spaceURL, apiKey), func() (*backlogwiki.Client, error) {
+		return backlogwiki.NewClient(httpClient, spaceURL, apiKey), nil
+	})
+	return client
+}
+
+// BacklogPullRequestClient は Backlog Git のプルリクエストのクライアントを返します。
+// ctx に Cache が設定されている場合は、同じスペースと API キーのクライアントを再利用します。
+func BacklogPullRequestClient(ctx context.Context, httpClient *http.Client, spaceURL, apiKey string) *backlogpr.Client {
+	client, _ := cached(CacheFromContext(ctx), backlogKey("pr", spaceURL, apiKey), func() (*backlogpr.Client, error) {
+		return backlogpr.NewClient(httpClient, spaceURL, apiKey), nil
+	})
+	return client
+}
+
+// backlogKey は Backlog のクライアントのキャッシュのキーです。
+func backlogKey(kind, spaceURL, apiKey string) string {
+	return "backlog\x00" + kind + "\x00" + spaceURL + "\x00" + credentialKey(apiKey)
+}
+
+// credentialKey は、トークンや Webhook URL をキャッシュのキーに含めるためのハッシュを返します。
+// キャッシュのキーはデバッグログに出力されるため、値をそのまま含めません。
+func credentialKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,134 @@
+// Package callback は、レビューパイプラインの完了時に最終的なレビュー結果を JSON で利用者のエンドポイントに POST します。
+// 時間のかかるレビューを起動元のシステムから切り離し、非同期に結果を受け取れるようにするためのものです。
+package callback
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"git-gemini-reviewer-go/internal/diffstat"
+	"git-gemini-reviewer-go/internal/findings"
+	"git-gemini-reviewer-go/internal/pkg/retry"
+	"git-gemini-reviewer-go/internal/tokenusage"
+)
+
+// 署名に関するリクエストヘッダです。
+const (
+	HeaderTimestamp = "X-Reviewer-Timestamp"
+	HeaderSignature = "X-Reviewer-Signature"
+	HeaderReviewID  = "X-Reviewer-Review-Id"
+	// signaturePrefix は署名のアルゴリズムを表す接頭辞です。
+	signaturePrefix = "sha256="
+	// maxAge は、リプレイ攻撃を防ぐために Verify が受け付ける最大経過時間です。
+	maxAge = 5 * time.Minute
+)
+
+// レビューの状態です。
+const (
+	StatusCompleted = "completed"
+	StatusNoDiff    = "no-diff"
+	StatusFailed    = "failed"
+)
+
+// Payload はコールバックで送信するレビュー結果です。
+type Payload struct {
+	// SchemaVersion はペイロードのスキーマのバージョン (schema.Version) です。
+	SchemaVersion int                       `json:"schema_version"`
+	ReviewID      string                    `json:"review_id"`
+	Status        string                    `json:"status"`
+	RepoURL       string                    `json:"repo_url"`
+	BaseBranch    string                    `json:"base_branch"`
+	FeatureBranch string                    `json:"feature_branch"`
+	Mode          string                    `json:"mode"`
+	Model         string                    `json:"model"`
+	PromptVariant string                    `json:"prompt_variant,omitempty"`
+	Destination   string                    `json:"destination"`
+	Verdict       string                    `json:"verdict,omitempty"`
+	Findings      map[findings.Category]int `json:"findings,omitempty"`
+	// DiffStats はレビューした差分の変更量です。レビューが完了した場合のみ含めます。
+	DiffStats   *diffstat.Report `json:"diff_stats,omitempty"`
+	Review      string           `json:"review,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	CompletedAt time.Time        `json:"completed_at"`
+	// Usage は AI に送信・受信したトークン数と推定費用です。レビューが完了した場合のみ含めます。
+	Usage *tokenusage.Report `json:"usage,omitempty"`
+}
+
+// Sign は、タイムスタンプとボディを '.' で連結した文字列の HMAC-SHA256 を 'sha256=<hex>' 形式で返します。
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s.%s", timestamp, body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ErrInvalidSignature は署名の検証に失敗したことを表します。
+var ErrInvalidSignature = errors.New("コールバックの署名が不正です")
+
+// Verify は受信側で署名とタイムスタンプを検証します。Go で受信エンドポイントを実装する場合に使用できます。
+func Verify(secret, timestamp, signature string, body []byte, now time.Time) error {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(sec, 0)); age > maxAge || age < -maxAge {
+		return fmt.Errorf("%w: タイムスタンプが古すぎます", ErrInvalidSignature)
+	}
+	if !hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Send はペイロードを url に POST します。secret が空の場合は署名を付与しません。
+func Send(ctx context.Context, httpClient *http.Client, url, secret string, payload Payload) error {
+	return SendJSON(ctx, httpClient, url, secret, payload.ReviewID, payload)
+}
+
+// SendJSON は任意の値を JSON にエンコードし、コールバックと同じ署名のヘッダを付与して url に POST します。
+// webhook コマンドなど、コールバック以外のペイロードを同じ方式で送信するために使用します。secret が空の場合は署名を付与しません。
+func SendJSON(ctx context.Context, httpClient *http.Client, url, secret, reviewID string, v any) error {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	body, err := json.Marshal(v)
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("コールバックのペイロードのエンコードに失敗しました: %w", err))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("コールバックのリクエストの作成に失敗しました: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderReviewID, reviewID)
+	if secret != "" {
+		// リトライのたびに署名し直し、受信側のタイムスタンプの検証を通るようにする
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(HeaderTimestamp, timestamp)
+		req.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("コールバックの送信に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode/100 != 2 {
+		err := fmt.Errorf("コールバックの送信先がエラーを返しました (status: %d)", resp.StatusCode)
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+// Package chunk は、1回の AI リクエストに収まらない巨大な差分をファイル単位 (必要に応じてハンク単位) に分割し、
+// 分割した差分ごとのレビュー結果を1つのレポートに統合させるプロンプトを提供します。
+package chunk
+
+import (
+	"fmt"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/monorepo"
+	"git-gemini-reviewer-go/internal/ratelimit"
+)
+
+// DefaultMaxTokens は、1回のレビューに含める差分の既定のトークン数の上限 (概算) です。
+const DefaultMaxTokens = 200000
+
+// Chunk は1回のレビューの対象とする差分の一部です。
+type Chunk struct {
+	// Files は差分に含まれるファイルのパスです。
+	Files []string
+	// Partial が true の場合、ファイルのハンクの一部のみを含みます。
+	Partial bool
+	Diff    string
+}
+
+// Label はレポートやログに表示する、差分に含まれるファイルの一覧です。
+func (c Chunk) Label() string {
+	names := make([]string, 0, len(c.Files))
+	for _, f := range c.Files {
+		names = append(names, "`"+f+"`")
+	}
+	label := strings.Join(names, ", ")
+	if c.Partial {
+		label += " (一部のハンク)"
+	}
+	return label
+}
+
+// Merge は、段階的な統合でまとめたチャンクを表す1つのチャンクを返します。Diff は含めません。
+func Merge(chunks ...Chunk) Chunk {
+	var merged Chunk
+	seen := make(map[string]bool)
+	for _, c := range chunks {
+		for _, f := range c.Files {
+			if !seen[f] {
+				seen[f] = true
+				merged.Files = append(merged.Files, f)
+			}
+		}
+		merged.Partial = merged.Partial || c.Partial
+	}
+	return merged
+}
+
+// Split は、diff を maxTokens のトークン数 (概算) に収まるチャンクに分割します。
+// ファイルは分割せずに順にまとめ、1ファイルで上限を超える場合のみ、ファイルヘッダを付けたハンクの単位に分割します。
+// 1つのハンクが上限を超える場合は、そのハンクのみのチャンクとします。
+// maxTokens が 0 以下の場合、または diff 全体が上限に収まる場合は、diff 全体を1つのチャンクとして返します。
+func Split(diff string, maxTokens int) []Chunk {
+	files := monorepo.SplitDiff(diff)
+	if maxTokens <= 0 || ratelimit.EstimateTokens(diff) <= maxTokens || len(files) == 0 {
+		paths := make([]string, 0, len(files))
+		for _, f := range files {
+			paths = append(paths, f.Path)
+		}
+		return []Chunk{{Files: paths, Diff: diff}}
+	}
+
+	var (
+		chunks  []Chunk
+		current Chunk
+	)
+	flush := func() {
+		if current.Diff != "" {
+			chunks = append(chunks, current)
+		}
+		current = Chunk{}
+	}
+	for _, f := range files {
+		if ratelimit.EstimateTokens(f.Content) > maxTokens {
+			flush()
+			parts := splitHunks(f.Content, maxTokens)
+			for _, part := range parts {
+				chunks = append(chunks, Chunk{Files: []string{f.Path}, Partial: len(parts) > 1, Diff: part})
+			}
+			continue
+		}
+		if current.Diff != "" && ratelimit.EstimateTokens(current.Diff+f.Content) > maxTokens {
+			flush()
+		}
+		current.Files = append(current.Files, f.Path)
+		current.Diff += f.Content
+	}
+	flush()
+	return chunks
+}
+
+// splitHunks は1ファイル分の差分を、ファイルヘッダを先頭に付けたハンクのまとまりに分割します。
+func splitHunks(content string, maxTokens int) []string {
+	lines := strings.SplitAfter(content, "\n")
+	var header strings.Builder
+	var hunks []string
+	var hunk strings.Builder
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			if hunk.Len() > 0 {
+				hunks = append(hunks, hunk.String())
+				hunk.Reset()
+			}
+			hunk.WriteString(line)
+		case hunk.Len() > 0:
+			hunk.WriteString(line)
+		default:
+			header.WriteString(line)
+		}
+	}
+	if hunk.Len() > 0 {
+		hunks = append(hunks, hunk.String())
+	}
+	if len(hunks) <= 1 {
+		return []string{content}
+	}
+
+	var parts []string
+	current := header.String()
+	hasHunk := false
+	for _, h := range hunks {
+		if hasHunk && ratelimit.EstimateTokens(current+h) > maxTokens {
+			parts = append(parts, current)
+			current, hasHunk = header.String(), false
+		}
+		current += h
+		hasHunk = true
+	}
+	return append(parts, current)
+}
+
+// Partial は1つのチャンクのレビュー結果です。
+type Partial struct {
+	Chunk  Chunk
+	Review string
+}
+
+// ConsolidationPrompt は、チャンクごとのレビュー結果を1つの一貫したレポートに統合させるプロンプトを返します。
+// total は分割したチャンクの総数で、レビューに失敗したチャンクがある場合は len(partials) より大きくなります。
+func ConsolidationPrompt(partials []Partial, total int) string {
+	var sb strings.Builder
+	sb.WriteString("# 🧩 分割レビューの統合\n\n")
+	fmt.Fprintf(&sb, "差分が大きいため、変更を %d 個の部分に分割してそれぞれレビューしました。以下の部分ごとのレビュー結果を、変更全体に対する1つの一貫したレビューレポートに統合してください。\n\n", total)
+	sb.WriteString("## 統合の規則 (MUST)\n\n")
+	sb.WriteString("- 出力は、部分ごとのレビュー結果と同じ見出しとフォーマットに従ってください。\n")
+	sb.WriteString("- リリース可否の判定は、部分ごとの判定のうち最も厳しいもの (リリース不可 > 条件付きリリース可 > リリース可) を採用してください。\n")
+	sb.WriteString("- 総評は、部分ごとの総評を要約するのではなく、変更全体を対象に書き直してください。\n")
+	sb.WriteString("- 指摘事項は重複を除いたうえで、省略せずにすべて含めてください。同じファイルへの指摘は1か所にまとめてください。\n")
+	sb.WriteString("- 部分ごとのレビュー結果に含まれない指摘を新たに追加しないでください。\n\n")
+	sb.WriteString("## 部分ごとのレビュー結果\n")
+	for i, p := range partials {
+		fmt.Fprintf(&sb, "\n### 部分 %d (対象: %s)\n\n<review>\n%s\n</review>\n", i+1, p.Chunk.Label(), strings.TrimSpace(p.Review))
+	}
+	return sb.String()
+}
+
+// Concat は、統合に失敗した場合の代わりに、チャンクごとのレビュー結果を見出しを付けて順に結合します。
+func Concat(partials []Partial) string {
+	sections := make([]string, 0, len(partials))
+	for i, p := range partials {
+		sections = append(sections, fmt.Sprintf("## 🧩 部分 %d/%d (対象: %s)\n\n%s", i+1, len(partials), p.Chunk.Label(), strings.TrimSpace(p.Review)))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// Notice は、分割してレビューしたことを伝えるレビュー結果の冒頭の注記です。
+// failed はレビューに失敗し、結果に含まれていないチャンクです。
+func Notice(total int, failed []Chunk) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "> 🧩 差分が大きいため、%d 個の部分に分割してレビューし、結果を統合しました。\n", total)
+	for _, c := range failed {
+		fmt.Fprintf(&sb, "> ⚠️ %s のレビューは失敗したため、このレポートには含まれていません。\n", c.Label())
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -0,0 +1,197 @@
+// Package clonecache は、--local-path を指定しない場合にリポジトリをクローンするキャッシュディレクトリを管理します。
+// リポジトリURLごとに1つのディレクトリを割り当て、合計サイズの上限を超えた場合は最も長く使われていないクローンから削除します。
+package clonecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// dirName は、ユーザーのキャッシュディレクトリの下に作成するディレクトリの名前です。
+const dirName = "git-gemini-reviewer"
+
+// EvictGrace は、削除の対象としない直近の使用からの経過時間です。
+// 他のプロセスがレビュー中のクローンを削除しないよう、レビューの所要時間より十分に長くしています。
+const EvictGrace = time.Hour
+
+// DefaultDir は既定のキャッシュディレクトリ (Linux では ~/.cache/git-gemini-reviewer) を返します。
+// ユーザーのキャッシュディレクトリを決められない場合は、一時ディレクトリの下を使用します。
+func DefaultDir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, dirName)
+}
+
+// Path は、キャッシュディレクトリ dir の下の、リポジトリURLのクローン先を返します。
+// ディレクトリ名は読みやすさのためにURLのホストとパスを残し、衝突しないようURLのハッシュを付けます。
+func Path(dir, repoURL string) string {
+	name := repoURL
+	if _, rest, ok := strings.Cut(name, "://"); ok {
+		name = rest
+	}
+	if _, rest, ok := strings.Cut(name, "@"); ok {
+		name = rest
+	}
+	name = strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			return r
+		}
+		return '_'
+	}, strings.TrimSuffix(name, ".git")), "_")
+	if len(name) > 80 {
+		name = name[len(name)-80:]
+	}
+	sum := sha256.Sum256([]byte(repoURL))
+	return filepath.Join(dir, name+"-"+hex.EncodeToString(sum[:4]))
+}
+
+// Contains は、path がキャッシュディレクトリ dir の直下のクローン先かを返します。
+func Contains(dir, path string) bool {
+	return filepath.Clean(filepath.Dir(path)) == filepath.Clean(dir)
+}
+
+// Touch は、クローンの最終使用日時を現在時刻に更新します。クローンが存在しない場合は何もしません。
+func Touch(path string) error {
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("クローンの使用日時の更新に失敗しました (%s): %w", path, err)
+	}
+	return nil
+}
+
+// Entry はキャッシュディレクトリ内の1つのクローンです。
+type Entry struct {
+	Path string `json:"path"`
+	// RepoURL はクローンの origin のURLです。クローン中の一時ディレクトリなど、読み取れない場合は空です。
+	RepoURL  string    `json:"repo_url"`
+	Size     int64     `json:"size"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// List は、キャッシュディレクトリ内のクローンを最終使用日時の新しい順に返します。
+// キャッシュディレクトリが存在しない場合は空の一覧を返します。
+func List(dir string) ([]Entry, error) {
+	items, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("キャッシュディレクトリの読み込みに失敗しました (%s): %w", dir, err)
+	}
+	var entries []Entry
+	for _, item := range items {
+		if !item.IsDir() {
+			continue
+		}
+		info, err := item.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(dir, item.Name())
+		entries = append(entries, Entry{
+			Path:     path,
+			RepoURL:  originURL(path),
+			Size:     dirSize(path),
+			LastUsed: info.ModTime(),
+		})
+	}
+	slices.SortFunc(entries, func(a, b Entry) int { return b.LastUsed.Compare(a.LastUsed) })
+	return entries, nil
+}
+
+// TotalSize はクローンの合計サイズを返します。
+func TotalSize(entries []Entry) int64 {
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+	return total
+}
+
+// Evict は、合計サイズが maxBytes 以下になるまで、最も長く使われていないクローンから削除し、削除したクローンを返します。
+// keep のクローンと、直近 EvictGrace 以内に使用されたクローンは削除しません。
+func Evict(dir string, maxBytes int64, keep string) ([]Entry, error) {
+	entries, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	total := TotalSize(entries)
+	var evicted []Entry
+	// 最終使用日時の古い順に削除する
+	for i := len(entries) - 1; i >= 0 && total > maxBytes; i-- {
+		e := entries[i]
+		if filepath.Clean(e.Path) == filepath.Clean(keep) || time.Since(e.LastUsed) < EvictGrace {
+			continue
+		}
+		if err := os.RemoveAll(e.Path); err != nil {
+			slog.Warn("キャッシュのクローンの削除に失敗しました。", "path", e.Path, "error", err)
+			continue
+		}
+		total -= e.Size
+		evicted = append(evicted, e)
+	}
+	return evicted, nil
+}
+
+// Clean は、最終使用日時が before より前のクローンをすべて削除し、削除したクローンを返します。
+func Clean(dir string, before time.Time) ([]Entry, error) {
+	entries, err := List(dir)
+	if err != nil {
+		return nil, err
+	}
+	var removed []Entry
+	var errs []error
+	for _, e := range entries {
+		if !e.LastUsed.Before(before) {
+			continue
+		}
+		if err := os.RemoveAll(e.Path); err != nil {
+			errs = append(errs, fmt.Errorf("キャッシュのクローンの削除に失敗しました (%s): %w", e.Path, err))
+			continue
+		}
+		removed = append(removed, e)
+	}
+	return removed, errors.Join(errs...)
+}
+
+// originURL はクローンの origin のURLを返します。読み取れない場合は空文字列です。
+func originURL(path string) string {
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return ""
+	}
+	remote, err := repo.Remote("origin")
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+	return remote.Config().URLs[0]
+}
+
+// dirSize はディレクトリ内のファイルの合計サイズを返します。読み取れないファイルは数えません。
+func dirSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && info.Mode().IsRegular() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
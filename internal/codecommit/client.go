@@ -0,0 +1,122 @@
+package codecommit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"git-gemini-reviewer-go/internal/pkg/retry"
+)
+
+const apiTargetPrefix = "CodeCommit_20150413."
+
+// Client は CodeCommit API のクライアントです。
+type Client struct {
+	httpClient *http.Client
+	creds      Credentials
+	region     string
+}
+
+// NewClient は Client を生成します。
+func NewClient(httpClient *http.Client, creds Credentials, region string) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{httpClient: httpClient, creds: creds, region: region}
+}
+
+// PullRequestTarget はプルリクエストの比較対象のコミットです。
+type PullRequestTarget struct {
+	RepositoryName    string `json:"repositoryName"`
+	SourceReference   string `json:"sourceReference"`
+	DestinationCommit string `json:"destinationCommit"`
+	SourceCommit      string `json:"sourceCommit"`
+}
+
+// PostPullRequestComment はプルリクエストにコメントを投稿し、プルリクエストのコンソールURLを返します。
+// コメントは最新のソースコミットに対して投稿されます。
+func (c *Client) PostPullRequestComment(ctx context.Context, pullRequestID, repository, content string) (string, error) {
+	var pr struct {
+		PullRequest struct {
+			Targets []PullRequestTarget `json:"pullRequestTargets"`
+		} `json:"pullRequest"`
+	}
+	if err := c.call(ctx, "GetPullRequest", map[string]string{"pullRequestId": pullRequestID}, &pr); err != nil {
+		return "", err
+	}
+
+	var target *PullRequestTarget
+	for i := range pr.PullRequest.Targets {
+		if pr.PullRequest.Targets[i].RepositoryName == repository {
+			target = &pr.PullRequest.Targets[i]
+			break
+		}
+	}
+	if target == nil {
+		return "", retry.Permanent(fmt.Errorf("プルリクエスト %s はリポジトリ '%s' を対象としていません", pullRequestID, repository))
+	}
+
+	input := map[string]string{
+		"pullRequestId":  pullRequestID,
+		"repositoryName": repository,
+		"beforeCommitId": target.DestinationCommit,
+		"afterCommitId":  target.SourceCommit,
+		"content":        content,
+	}
+	if err := c.call(ctx, "PostCommentForPullRequest", input, nil); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("https://%s.console.aws.amazon.com/codesuite/codecommit/repositories/%s/pull-requests/%s/activity?region=%s",
+		c.region, repository, pullRequestID, c.region), nil
+}
+
+// call は JSON プロトコルで CodeCommit API を呼び出します。
+func (c *Client) call(ctx context.Context, operation string, input any, output any) error {
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("CodeCommit API (%s) のリクエストのエンコードに失敗しました: %w", operation, err)
+	}
+
+	endpoint := fmt.Sprintf("https://codecommit.%s.amazonaws.com/", c.region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("CodeCommit APIリクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", apiTargetPrefix+operation)
+	signRequest(req, payload, c.creds, c.region, serviceName, time.Now())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("CodeCommit API (%s) の呼び出しに失敗しました: %w", operation, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("CodeCommit API (%s) のレスポンスの読み込みに失敗しました: %w", operation, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(body, &apiErr)
+		err := fmt.Errorf("CodeCommit API (%s) がエラーを返しました (status: %d, type: %s): %s", operation, resp.StatusCode, apiErr.Type, apiErr.Message)
+		// スロットリング以外のクライアントエラーはリトライしても解消しません
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests && apiErr.Type != "ThrottlingException" {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	if output != nil {
+		if err := json.Unmarshal(body, output); err != nil {
+			return fmt.Errorf("CodeCommit API (%s) のレスポンスのデコードに失敗しました: %w", operation, err)
+		}
+	}
+	return nil
+}
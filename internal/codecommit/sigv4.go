@@ -0,0 +1,138 @@
+package codecommit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	serviceName     = "codecommit"
+	signAlgorithm   = "AWS4-HMAC-SHA256"
+	amzDateLayout   = "20060102T150405Z"
+	grcTimeLayout   = "20060102T150405"
+	scopeDateLayout = "20060102"
+)
+
+// Credentials は AWS の認証情報です。
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// CredentialsFromEnv は標準の AWS 環境変数から認証情報を読み込みます。
+// 共有認証情報ファイルや IAM ロールからの取得には対応していないため、CI では環境変数で渡してください。
+func CredentialsFromEnv() (Credentials, error) {
+	creds := Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return Credentials{}, fmt.Errorf("CodeCommit 連携には環境変数 AWS_ACCESS_KEY_ID および AWS_SECRET_ACCESS_KEY が必須です")
+	}
+	return creds, nil
+}
+
+// RegionFromEnv は AWS_REGION (または AWS_DEFAULT_REGION) 環境変数からリージョンを返します。
+func RegionFromEnv() string {
+	if r := os.Getenv("AWS_REGION"); r != "" {
+		return r
+	}
+	return os.Getenv("AWS_DEFAULT_REGION")
+}
+
+// GitCredentials は git-remote-codecommit (GRC) と同じ方式で、HTTPS の Git 操作に使用するユーザー名とパスワードを生成します。
+// パスワードは SigV4 で署名した一時的な値で、生成から一定時間のみ有効です。
+func GitCredentials(creds Credentials, region, repository string, now time.Time) (username, password string) {
+	host := gitHost(region)
+	path := "/v1/repos/" + repository
+	timestamp := now.UTC().Format(grcTimeLayout)
+
+	canonicalRequest := fmt.Sprintf("GIT\n%s\n\nhost:%s\n\nhost\n", path, host)
+	scope := credentialScope(now, region, serviceName)
+	stringToSign := strings.Join([]string{signAlgorithm, timestamp, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+	signature := hex.EncodeToString(hmacSHA256(signingKey(creds.SecretAccessKey, now, region, serviceName), stringToSign))
+
+	username = creds.AccessKeyID
+	if creds.SessionToken != "" {
+		username += "%" + creds.SessionToken
+	}
+	return username, timestamp + "Z" + signature
+}
+
+// signRequest は API リクエストに SigV4 の署名ヘッダーを付与します。
+// service は署名のスコープに含めるサービス名で、CodeCommit の API では serviceName です。
+func signRequest(req *http.Request, payload []byte, creds Credentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format(amzDateLayout)
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+	payloadHash := sha256Hex(payload)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := credentialScope(now, region, service)
+	stringToSign := strings.Join([]string{signAlgorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+	signature := hex.EncodeToString(hmacSHA256(signingKey(creds.SecretAccessKey, now, region, service), stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signAlgorithm, creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func credentialScope(now time.Time, region, service string) string {
+	return fmt.Sprintf("%s/%s/%s/aws4_request", now.UTC().Format(scopeDateLayout), region, service)
+}
+
+func signingKey(secret string, now time.Time, region, service string) []byte {
+	k := hmacSHA256([]byte("AWS4"+secret), now.UTC().Format(scopeDateLayout))
+	k = hmacSHA256(k, region)
+	k = hmacSHA256(k, service)
+	return hmacSHA256(k, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,70 @@
+package codecommit
+
+import (
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// AWS の Signature Version 4 のテストスイートの認証情報と日時です。
+var (
+	testSuiteCreds = Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	testSuiteTime  = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+)
+
+func TestSignRequestMatchesAWSTestSuite(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		url           string
+		wantSignature string
+	}{
+		{"get-vanilla", http.MethodGet, "https://example.amazonaws.com/", "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"},
+		{"post-vanilla", http.MethodPost, "https://example.amazonaws.com/", "5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signRequest(req, nil, testSuiteCreds, "us-east-1", "service", testSuiteTime)
+
+			want := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=" + tt.wantSignature
+			if got := req.Header.Get("Authorization"); got != want {
+				t.Errorf("Authorization = %q, want %q", got, want)
+			}
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %q, want 20150830T123600Z", got)
+			}
+		})
+	}
+}
+
+func TestSignRequestIncludesSessionToken(t *testing.T) {
+	creds := testSuiteCreds
+	creds.SessionToken = "session-token"
+	req, err := http.NewRequest(http.MethodPost, "https://codecommit.us-east-1.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signRequest(req, nil, creds, "us-east-1", serviceName, testSuiteTime)
+
+	if got := req.Header.Get("X-Amz-Security-Token"); got != "session-token" {
+		t.Errorf("X-Amz-Security-Token = %q, want session-token", got)
+	}
+	if got := req.Header.Get("Authorization"); !strings.Contains(got, "SignedHeaders=host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("セッショントークンが署名の対象に含まれていません: %s", got)
+	}
+}
+
+func TestSigningKeyMatchesAWSExample(t *testing.T) {
+	// AWS のドキュメントの「署名キーの導出」の例です。
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", time.Date(2012, 2, 15, 0, 0, 0, 0, time.UTC), "us-east-1", "iam")
+	want := "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d"
+	if got := hex.EncodeToString(key); got != want {
+		t.Errorf("signingKey = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,66 @@
+package codecommit
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Remote は CodeCommit リポジトリの所在です。
+type Remote struct {
+	Region     string
+	Repository string
+}
+
+// IsURL は、リポジトリURLが CodeCommit (GRC 形式または HTTPS) を指しているかを判定します。
+func IsURL(repoURL string) bool {
+	return strings.HasPrefix(repoURL, "codecommit:") ||
+		strings.HasPrefix(repoURL, "https://git-codecommit.") && strings.Contains(repoURL, ".amazonaws.com/v1/repos/")
+}
+
+// ParseURL は CodeCommit のリポジトリURLを解析します。次の形式に対応します。
+//   - codecommit::<region>://<repository> (git-remote-codecommit 形式)
+//   - codecommit://<repository> (リージョンは defaultRegion を使用)
+//   - https://git-codecommit.<region>.amazonaws.com/v1/repos/<repository>
+//
+// GRC 形式のプロファイル指定 (<profile>@<repository>) は無視し、認証には環境変数を使用します。
+func ParseURL(repoURL, defaultRegion string) (Remote, error) {
+	var r Remote
+	switch {
+	case strings.HasPrefix(repoURL, "codecommit::"):
+		rest := strings.TrimPrefix(repoURL, "codecommit::")
+		region, repo, ok := strings.Cut(rest, "://")
+		if !ok {
+			return Remote{}, fmt.Errorf("CodeCommit のURLが不正です: %s", repoURL)
+		}
+		r = Remote{Region: region, Repository: repo}
+	case strings.HasPrefix(repoURL, "codecommit://"):
+		r = Remote{Region: defaultRegion, Repository: strings.TrimPrefix(repoURL, "codecommit://")}
+	case strings.HasPrefix(repoURL, "https://git-codecommit."):
+		host, path, _ := strings.Cut(strings.TrimPrefix(repoURL, "https://"), "/")
+		region := strings.TrimSuffix(strings.TrimPrefix(host, "git-codecommit."), ".amazonaws.com")
+		r = Remote{Region: region, Repository: strings.TrimPrefix(path, "v1/repos/")}
+	default:
+		return Remote{}, fmt.Errorf("CodeCommit のURLではありません: %s", repoURL)
+	}
+
+	if _, repo, ok := strings.Cut(r.Repository, "@"); ok {
+		r.Repository = repo
+	}
+	r.Repository = strings.TrimSuffix(r.Repository, "/")
+	if r.Region == "" {
+		return Remote{}, fmt.Errorf("CodeCommit のリージョンを特定できません。URL に含めるか、環境変数 AWS_REGION を設定してください: %s", repoURL)
+	}
+	if r.Repository == "" {
+		return Remote{}, fmt.Errorf("CodeCommit のURLにリポジトリ名が含まれていません: %s", repoURL)
+	}
+	return r, nil
+}
+
+// CloneURL は go-git でクローンするための HTTPS URL を返します。
+func (r Remote) CloneURL() string {
+	return fmt.Sprintf("https://%s/v1/repos/%s", gitHost(r.Region), r.Repository)
+}
+
+func gitHost(region string) string {
+	return fmt.Sprintf("git-codecommit.%s.amazonaws.com", region)
+}
@@ -1,5 +1,12 @@
 package config
 
+import (
+	"time"
+
+	"git-gemini-reviewer-go/internal/hooks"
+	"git-gemini-reviewer-go/internal/issuelink"
+)
+
 // ReviewConfig はAIコードレビューに必要なすべての設定を含みます。
 // この構造体は、コマンドライン引数からサービスロジックへ設定を渡すための共通のデータモデルです。
 type ReviewConfig struct {
@@ -11,4 +18,232 @@ type ReviewConfig struct {
 	SSHKeyPath       string
 	LocalPath        string
 	SkipHostKeyCheck bool
+	// SSHKeyPassphrase はパスフレーズで保護された SSH キーのパスフレーズです。
+	SSHKeyPassphrase string
+	// UseSSHAgent が true の場合、SSH の認証に鍵ファイルではなく ssh-agent を使用します。
+	UseSSHAgent bool
+	// GitHTTPUsername と GitHTTPToken は HTTPS のリポジトリURLにアクセスするための認証情報です。
+	GitHTTPUsername string
+	GitHTTPToken    string
+	// GitTokenHosts は GitHTTPToken を送信するホストです。リポジトリのホストが含まれない場合、トークンは送信しません。
+	GitTokenHosts []string
+	// GitCleanup はレビュー後のローカルリポジトリの後処理の方法です ('none', 'reset', 'delete')。
+	// コマンドラインで未指定の場合は、コマンドごとの既定値が設定されます。
+	GitCleanup string
+	// CacheDir は、LocalPath を指定しない場合にリポジトリをクローンするキャッシュディレクトリです。空の場合は clonecache.DefaultDir です。
+	CacheDir string
+	// CacheMaxMB はキャッシュディレクトリのクローンの合計サイズの上限 (MiB) です。
+	// 超えた場合はレビューの後に最も長く使われていないクローンから削除します。0 は無制限です。
+	CacheMaxMB int
+	// Submodules は差分に含まれるサブモジュールの参照先の変更の扱いです: 'pointer' (参照先の変更のみ)、
+	// 'log' (サブモジュールのコミットの件名をプロンプトに添える)、'diff' (さらにサブモジュール自身の差分を含める)。
+	Submodules string
+	// FileContext は変更されたファイルの内容をプロンプトに添える方法です: 'none' (または空)、'full' (ファイル全体)、
+	// 正の整数 N (各ハンクの変更箇所の前後 N 行)。除外・省略したファイルの内容は添えません。
+	FileContext string
+	// FileContextMaxBytes は、FileContext で内容を添えるファイルの最大サイズ (バイト) です。超えるファイルは添えません。
+	FileContextMaxBytes int
+	// IncludeCommitLog が true の場合、差分に含まれるコミットの作者・日時・メッセージの一覧をプロンプトに添えます。
+	IncludeCommitLog bool
+	// CommitLogMax はプロンプトに添えるコミットの最大件数です。超えた場合は新しいコミットから CommitLogMax 件を添えます。
+	CommitLogMax int
+	// TwoPass が true の場合、1回目で変更全体の概要とファイルのリスクの順位を求め、2回目でリスクの高いファイルのみを詳しくレビューします。
+	TwoPass bool
+	// DeepDiveFiles は、TwoPass の2回目に詳しくレビューするファイルの件数です。
+	DeepDiveFiles int
+	// InMemoryRepo が true の場合、リポジトリを LocalPath ではなくメモリ上にクローンし、ワークツリーを作成しません。
+	// ディスクに何も書き込まないため、GitCleanup の後処理は行いません。
+	InMemoryRepo bool
+	// CloneDepth が正の場合、クローンとフェッチを各ブランチの先頭から CloneDepth 個のコミットに限定します。
+	// マージベースが取得した履歴に含まれない場合は、履歴をすべて含むクローンに切り替えます。0 の場合はすべての履歴を取得します。
+	CloneDepth int
+	// FetchAll が true の場合、リモートのすべてのブランチをフェッチします。
+	// false の場合はベースブランチとレビュー対象のブランチのみをフェッチします (リビジョンや --stack の指定時はすべて)。
+	FetchAll bool
+	// SkipFetch が true の場合、既存のクローンをフェッチせずにそのまま使用します。
+	// 同じ実行の中でフェッチ済みのクローンで複数のフィーチャーブランチをレビューする場合に設定します。
+	SkipFetch bool
+	// Personas はレビューモードのプロンプトに重ねるレビュアーペルソナ名です (例: 'strict-security', 'mentor')。
+	Personas []string
+	// CallbackURL はパイプラインの完了時に最終的なレビュー結果を JSON で POST するエンドポイントです。
+	CallbackURL string
+	// CallbackSecret はコールバックの HMAC-SHA256 署名に使用するシークレットです。空の場合は署名しません。
+	CallbackSecret string
+	// PromptFile は、すべてのレビューモードで組み込みのテンプレートの代わりに使用するプロンプトテンプレート (text/template) のパスです。
+	PromptFile string
+	// PromptDir は、レビューモードごとのプロンプトテンプレート ('<モード>.tmpl') を置いたディレクトリです。
+	// テンプレートのないレビューモードは組み込みのテンプレートを使用します。PromptFile と同時には指定できません。
+	PromptDir string
+	// PromptVariantB はプロンプトの A/B 実験で B に使用するテンプレートのパスです。空の場合は実験を行いません。
+	PromptVariantB string
+	// PromptSplit はプロンプトの A/B 実験で B に割り当てるレビューの割合 (0〜100) です。
+	PromptSplit int
+	// PromptVariant は今回のレビューに割り当てたプロンプトの派生 ('A' または 'B') です。実験を行わない場合は空です。
+	PromptVariant string
+	// PatchFile はレビュー対象の unified diff ファイルのパスです ("-" は標準入力)。
+	// 指定時は Git リポジトリへのアクセスを行いません。
+	PatchFile string
+	// Worktree はコミットされていない変更をレビューするローカルリポジトリのパスです。
+	// 指定時はリモートへのアクセスやクローンを行いません。
+	Worktree string
+	// WorktreeChanges は Worktree の差分に含める変更の種類です ('staged', 'unstaged', 'all')。
+	WorktreeChanges string
+	// MaxFiles と MaxHunks はレビュー対象とする差分のファイル数・ハンク数の上限です。0 は無制限です。
+	// 上限を超える場合、リスクの高いファイルを優先して選択し、除外したファイルはレビュー結果に列挙します。
+	MaxFiles int
+	MaxHunks int
+	// ChunkTokens は1回のレビューに含める差分のトークン数 (概算) の上限です。
+	// 超える場合はファイル単位 (必要に応じてハンク単位) に分割してレビューし、最後に結果を統合します。0 は分割しません。
+	ChunkTokens int
+	// MaxInputTokens は1回のリクエストで AI に送信するプロンプトの入力トークン数の上限です。0 は無制限です。
+	// 超える場合は、ChunkTokens による分割が有効であれば差分を分割し直してレビューし、無効であれば送信を中止します。
+	MaxInputTokens int
+	// TokenCounter はプロンプトの入力トークン数の数え方です: 'api' (Gemini の countTokens API) または 'estimate' (バイト長からの概算)。
+	TokenCounter string
+	// Excludes はレビュー対象から除外するファイルのパターンです (例: 'vendor/', '*.pb.go')。
+	Excludes []string
+	// Omits は、変更されたことのみを伝え、内容をレビュー対象から省略するファイルのパターンです (例: 'go.sum', 'yarn.lock')。
+	Omits []string
+	// ReviewIgnoreFile は、除外するファイルのパターンを .gitignore の書式で記述した、リポジトリ内のファイルのパスです。
+	// ベースブランチの内容を使用します。空文字列の場合は読み込みません。
+	ReviewIgnoreFile string
+	// GuidelinesFiles は、プロジェクトのルールとしてプロンプトに添える規約ファイルの候補の、リポジトリのルートからのパスです。
+	// 先に見つかったファイルを使用します。空の場合は規約を読み込みません。
+	GuidelinesFiles []string
+	// CriticalPaths は重要なパスのパターンです (例: 'auth/**', 'payments/**')。
+	// 一致するファイルへの指摘は重大度を1段階引き上げ、レビュー結果の冒頭で強調します。
+	CriticalPaths []string
+	// DiffTransforms は取得した差分に順に適用する変換器の名前です (例: 'exclude', 'redact', 'exec:./filter.sh')。
+	DiffTransforms []string
+	// SplitModules が true の場合、差分をモジュール境界 (go.mod, package.json 等) ごとに分割し、
+	// モジュール単位で判定を含むレビューを行います。
+	SplitModules bool
+	// InlineFindings が true の場合、AI に行単位の構造化された指摘 (ファイル・行・重大度・内容) を JSON で出力させます。
+	// 結果は Markdown に変換して返し、構造化された指摘はインラインコメントの投稿に使用します。
+	InlineFindings bool
+	// StrictFindings が true の場合、構造化された指摘がスキーマの必須の値 (ファイル・行・本文) を満たしているかを検証し、
+	// 満たしていない場合はレビューを失敗させます。機械可読な形式で出力する場合に使用します。
+	StrictFindings bool
+
+	// FailOn は、レビューの判定がこのしきい値 ('blocked' または 'conditional') に達した場合、
+	// または構造化された指摘にこの重大度 ('critical', 'major', 'minor') 以上の指摘がある場合にコマンドを失敗させます。空の場合は判定で失敗させません。
+	FailOn string
+	// MaxFindings は、指摘の件数がこの値を超えた場合にコマンドを失敗させる上限です。-1 は無制限です。
+	MaxFindings int
+	// RequiredChecks は、満たされない場合にコマンドを失敗させる変更構成のチェックです ('tests', 'docs')。
+	RequiredChecks []string
+
+	// GerritChange はレビュー対象の Gerrit の変更 ("変更番号/パッチセット番号") です。
+	// 指定時は refs/changes/ 配下のパッチセットをフェッチし、FeatureBranch として扱います。
+	GerritChange string
+
+	// GitHubPullRequest は、GitHub Actions のイベントから読み取ったレビュー対象のプルリクエスト番号です。
+	// 指定時は refs/pull/ 配下のプルリクエストの先頭をフェッチし、FeatureBranch として扱います。
+	GitHubPullRequest int
+
+	// Stack はスタックされたブランチをトランクに近い順に並べたものです (例: 'main,feature/a,feature/b')。
+	// 指定時はフィーチャーブランチを BaseBranch ではなく、リモートに存在する直近の親ブランチと比較します。
+	Stack []string
+
+	// BaseRev と FeatureRev は、ブランチの代わりに差分の両端とするリビジョンです (コミットの SHA、タグ、'HEAD~3' など)。
+	// 空の場合は BaseBranch と FeatureBranch を使用します。
+	BaseRev    string
+	FeatureRev string
+	// FeatureBranches は、同じベースブランチに対して1回の実行でレビューするフィーチャーブランチの一覧です。
+	// 'release/*' のようなグロブはリモートのブランチに一致させます。指定時はブランチごとにレビューして結果を投稿します。
+	FeatureBranches []string
+	// BaselineFile は既存の指摘を記録したベースラインファイルのパスです。指定時は構造化された指摘でレビューし、
+	// ベースラインに記録済みの指摘を報告から除外します。WriteBaseline が true の場合は、現在の指摘でベースラインを書き換えます。
+	BaselineFile  string
+	WriteBaseline bool
+	// SinceLastReview が true の場合、HistoryFile に同じフィーチャーブランチの前回のレビューがあれば、
+	// 前回レビューしたコミットから現在のフィーチャーブランチまでの差分のみをレビューします。
+	SinceLastReview bool
+
+	// SkipMarker はコミットメッセージに含まれる場合にレビューをスキップする文字列です (例: '[skip ai-review]')。空の場合は判定しません。
+	SkipMarker string
+	// PRLabels は CI から渡されるプルリクエストのラベルです。
+	PRLabels []string
+	// SkipLabel は PRLabels に含まれる場合にレビューをスキップするラベルです。空の場合は判定しません。
+	SkipLabel string
+
+	// NotifyNoDiff は、差分がない場合にも定型メッセージを投稿先に送信するかどうかです。
+	NotifyNoDiff bool
+	// MessageTemplateDir は差分なし・スキップ時の定型メッセージを上書きするテンプレートのディレクトリです。
+	MessageTemplateDir string
+	// MessageLang は定型メッセージの組み込みテンプレートの言語です ('ja' または 'en')。
+	MessageLang string
+	// HeadingLangs は投稿先ごとのレビュー結果の見出しの言語です ('en' はすべての投稿先、'github=en' は投稿先ごと)。
+	// 空の場合は組み込みの日本語の見出しのまま投稿します。
+	HeadingLangs []string
+	// HeadingTranslations は見出しの辞書を言語ごとに記述した YAML ファイルのパスです。組み込みの辞書に重ねます。
+	HeadingTranslations string
+	// ReviewLanguage は AI に記述させるレビュー結果の言語です (例: 'ja'、'en')。'ja' 以外の場合はプロンプトで出力言語を指示し、
+	// --heading-lang と --message-lang を指定していなければ、見出しと定型メッセージもその言語にします。
+	ReviewLanguage string
+	// Hooks はパイプラインの各段階で実行するフックです。
+	Hooks []hooks.Hook
+	// Destination は実行中のコマンド (投稿先) の名前です。定型メッセージのテンプレート選択に使用します。
+	Destination string
+
+	// IssueTrackers はブランチ名やコミットメッセージ中の課題キーをリンクに変換する設定です。空の場合はリンクを付与しません。
+	IssueTrackers []issuelink.Tracker
+
+	// AIProvider はレビューに使用する AI です: 'gemini'、'openai' (OpenAI 互換の API)、'ollama' (ローカルの Ollama サーバー) または 'stub' (ネットワークに接続しない決定的なスタブ)。
+	AIProvider string
+	// FallbackModels は、GeminiModel がレート制限・サーバーエラー・コンテキスト長の超過で失敗した場合に順に試すモデルです。
+	// --gemini にカンマ区切りで指定した2番目以降のモデルです。
+	FallbackModels []string
+	// AIBaseURL は AI の API のベース URL です (例: Azure OpenAI や OpenAI 互換のゲートウェイ)。空の場合はプロバイダの既定値を使用します。
+	AIBaseURL string
+	// OllamaURL は 'ollama' プロバイダで使用する Ollama サーバーの URL です。空の場合は環境変数 OLLAMA_HOST または既定値を使用します。
+	OllamaURL string
+	// OllamaNumCtx は Ollama に指定するコンテキスト長 (num_ctx) です。0 の場合はプロンプトの長さから決めます。
+	OllamaNumCtx int
+	// Vertex は、Gemini を API キーの代わりに ADC またはサービスアカウントで認証し、Vertex AI 経由で呼び出すかを表します。
+	Vertex bool
+	// VertexProject と VertexLocation は Vertex AI のプロジェクトIDとロケーション (リージョンまたは 'global') です。
+	VertexProject  string
+	VertexLocation string
+	// VertexCredentials は Vertex AI の認証に使用するサービスアカウントキー (JSON) のパスです。空の場合は ADC を使用します。
+	VertexCredentials string
+	// AITemperature、AITopP、AIMaxOutputTokens は応答の生成を調整するパラメータです (sampling.Params)。
+	// AITopP と AIMaxOutputTokens が 0 の場合はプロバイダの既定値を使用します。
+	AITemperature     float64
+	AITopP            float64
+	AIMaxOutputTokens int
+	// Stream は、Gemini の応答を GenerateContentStream で受信し、生成と同時に書き出すかを表します。
+	// 書き出し先はコンテキスト (streamai.WithWriter) で指定します。
+	Stream bool
+	// ResponseCache は AI の応答のキャッシュの保存先 (ディレクトリ、または 'redis://' / 'rediss://' の URI) です。空の場合は使用しません。
+	ResponseCache string
+	// ResponseCacheTTL は応答をキャッシュに保存する期間です。
+	ResponseCacheTTL time.Duration
+	// NoCache は、ResponseCache が指定されていても応答のキャッシュを使用しないかを表します。
+	NoCache bool
+	// PricingFile は、推定費用の計算に使用するモデルごとの料金表 (YAML または JSON) のパスです。空の場合は組み込みの料金表を使用します。
+	PricingFile string
+	// UsageTrailer は、投稿するレビュー結果の末尾にトークン数と推定費用を付与するかを表します。
+	UsageTrailer bool
+	// AIRequestsPerMinute と AITokensPerMinute は Gemini へのリクエストの分間上限 (QPM/TPM) です。0 は無制限です。
+	AIRequestsPerMinute int
+	AITokensPerMinute   int
+	// RateLimitStateFile はレート制限の状態を複数プロセスで共有するためのファイルパスです。空の場合はプロセス内でのみ共有します。
+	RateLimitStateFile string
+
+	// ReviewID は実行ごとに採番されるレビューの識別子です。フィードバックの紐付けに使用します。
+	ReviewID string
+	// FeedbackURL はフィードバック受付エンドポイントのベースURLです。空の場合、👍/👎 リンクは付与しません。
+	FeedbackURL string
+	// FeedbackSecret は 👍/👎 リンクの署名に使用する鍵です (環境変数 REVIEWER_FEEDBACK_SECRET)。
+	FeedbackSecret string
+	// ArchiveURI はプロンプトとレスポンスを監査用に保存する先 (gs://bucket/prefix/ またはローカルパス) です。
+	ArchiveURI string
+	// HistoryFile はレビューの実行履歴を記録するファイルのパス、GCS のプレフィックス (gs://バケット/プレフィックス)、
+	// またはデータベースのURL (sqlite://パス、postgres://...) です。空の場合は記録しません。
+	HistoryFile string
+	// HistoryRetention は履歴を保持する期間です。記録のたびに、これより古いレビューと承認判断を削除します。0 の場合は削除しません。
+	HistoryRetention time.Duration
+	// FollowUp が true の場合、HistoryFile に同じフィーチャーブランチの前回のレビューがあれば、その指摘の対応状況も確認させます。
+	FollowUp bool
 }
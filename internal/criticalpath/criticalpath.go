@@ -0,0 +1,126 @@
+// Package criticalpath は、認証や決済など重要なパス (クリティカルパス) への変更と指摘を強調し、指摘の重大度と判定を1段階引き上げます。
+// 重要なコードへの変更が、指摘を伴ったまま「リリース可」として見過ごされないようにするためのものです。
+package criticalpath
+
+import (
+	"fmt"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/diffguard"
+	"git-gemini-reviewer-go/internal/findings"
+	"git-gemini-reviewer-go/internal/inline"
+	"git-gemini-reviewer-go/internal/monorepo"
+	"git-gemini-reviewer-go/internal/verdict"
+)
+
+// Touched は差分のうち、重要パスのパターン (--exclude と同じ書式) に一致するファイルを返します。
+func Touched(diff string, patterns []string) []string {
+	if len(patterns) == 0 {
+		return nil
+	}
+	var touched []string
+	for _, f := range monorepo.SplitDiff(diff) {
+		if match(patterns, f.Path) {
+			touched = append(touched, f.Path)
+		}
+	}
+	return touched
+}
+
+// Findings はレビュー結果の指摘のうち、重要パスのファイルに対する指摘を返します。
+func Findings(result string, patterns []string) []findings.Finding {
+	var critical []findings.Finding
+	for _, f := range findings.Extract(result) {
+		if f.File != "" && match(patterns, f.File) {
+			critical = append(critical, f)
+		}
+	}
+	return critical
+}
+
+// RaiseSeverities は、構造化された指摘のうち重要パスのファイルに対する指摘の重大度を1段階引き上げ、引き上げた件数を返します。
+// AI が PromptNote の指示に従わなかった場合も、重要パスへの指摘が軽く扱われないようにします。
+// 既に重大な指摘は引き上げの件数に含めません。
+func RaiseSeverities(review *inline.Review, patterns []string) int {
+	if review == nil || len(patterns) == 0 {
+		return 0
+	}
+	raised := 0
+	for i := range review.Findings {
+		f := &review.Findings[i]
+		if f.File == "" || !match(patterns, f.File) {
+			continue
+		}
+		if next := f.Severity.Raise(); next != f.Severity {
+			f.Severity = next
+			raised++
+		}
+	}
+	return raised
+}
+
+// PromptNote は、重要パスへの指摘の重大度を1段階高く評価するよう AI に指示するプロンプトの前置きを返します。
+func PromptNote(touched []string) string {
+	if len(touched) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## 🔐 重要パス (ツールによる自動判定)\n\n")
+	sb.WriteString("次のファイルは、障害やセキュリティ上の影響が大きい重要なコードです。これらのファイルへの指摘は、重大度を1段階高く評価してください。\n\n")
+	for _, p := range touched {
+		fmt.Fprintf(&sb, "- `%s`\n", p)
+	}
+	sb.WriteString("\n---\n\n")
+	return sb.String()
+}
+
+// Notice はレビュー結果の冒頭に置く、重要パスへの変更と指摘の要約を返します。
+// 重要パスへの指摘がある場合は判定を1段階引き上げ、引き上げ後の判定を先頭に記載します。
+// verdict.Parse は最初に現れる判定を採用するため、履歴やポリシーの判定にも引き上げ後の判定が使われます。
+func Notice(touched []string, critical []findings.Finding, aiVerdict verdict.Verdict) string {
+	return notice(touched, critical, aiVerdict, aiVerdict.Raise())
+}
+
+// StructuredNotice は、構造化された指摘の重大度を RaiseSeverities で引き上げたレビュー結果の Notice です。
+// 判定は指摘の重大度から導かれるため、判定をさらに引き上げず、引き上げ前 (before) と引き上げ後 (after) の判定を記載します。
+func StructuredNotice(touched []string, critical []findings.Finding, before, after verdict.Verdict) string {
+	return notice(touched, critical, before, after)
+}
+
+func notice(touched []string, critical []findings.Finding, aiVerdict, raised verdict.Verdict) string {
+	if len(touched) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	if len(critical) == 0 {
+		fmt.Fprintf(&sb, "🔐 **重要パスの変更:** %s (指摘なし。重点的な確認を推奨します)", codeList(touched))
+		return sb.String()
+	}
+
+	if raised != aiVerdict {
+		fmt.Fprintf(&sb, "🔐 **重要パスへの指摘 %d 件:** 判定を **%s** に引き上げました (AIの判定: %s)\n", len(critical), raised.Label(), aiVerdict.Label())
+	} else {
+		fmt.Fprintf(&sb, "🔐 **重要パスへの指摘 %d 件** (判定: %s)\n", len(critical), aiVerdict.Label())
+	}
+	for _, f := range critical {
+		fmt.Fprintf(&sb, "\n- `%s` [%s] %s", f.File, f.Category.Label(), f.Text)
+	}
+	return sb.String()
+}
+
+func match(patterns []string, path string) bool {
+	for _, pattern := range patterns {
+		if diffguard.MatchPath(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func codeList(paths []string) string {
+	quoted := make([]string, len(paths))
+	for i, p := range paths {
+		quoted[i] = "`" + p + "`"
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,196 @@
+package diffguard
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/diffstat"
+	"git-gemini-reviewer-go/internal/monorepo"
+)
+
+// Limits はレビュー対象とする差分の上限です。0 の項目は制限しません。
+type Limits struct {
+	MaxFiles int
+	MaxHunks int
+}
+
+// Enabled は上限が1つ以上設定されているかを返します。
+func (l Limits) Enabled() bool {
+	return l.MaxFiles > 0 || l.MaxHunks > 0
+}
+
+// Omitted は上限を超えたためレビュー対象から除外されたファイルです。
+type Omitted struct {
+	Path  string
+	Hunks int
+	Lines int
+}
+
+// Result は上限を適用した結果です。
+type Result struct {
+	// Diff はレビュー対象として残したファイルの差分です。ファイルの順序は元の差分のままです。
+	Diff    string
+	Kept    int
+	Omitted []Omitted
+}
+
+var (
+	// highRiskPattern は不具合が重大な影響につながりやすいパスです。
+	highRiskPattern = regexp.MustCompile(`(?i)(auth|security|crypto|secret|token|password|permission|payment|billing|migrat|schema|\.sql$|api/|handler|router|middleware)`)
+	// lowRiskPattern は自動生成物やロックファイルなど、レビューの優先度が低いパスです。
+	lowRiskPattern = regexp.MustCompile(`(?i)(\.lock$|\.sum$|lock\.json$|\.pb\.go$|_gen\.go$|\.gen\.|generated|/mocks?/|^vendor/|/vendor/|\.min\.(js|css)$|\.snap$)`)
+)
+
+// Apply は差分を上限内に収まるよう削減します。
+// ファイルはリスクの推定値 (パスのパターン・変更の種類・変更行数) が高い順に選ばれ、上限を超えた分は Omitted として返します。
+func Apply(diff string, limits Limits) Result {
+	files := monorepo.SplitDiff(diff)
+	if !limits.Enabled() {
+		return Result{Diff: diff, Kept: len(files)}
+	}
+
+	type candidate struct {
+		index int
+		file  monorepo.FileDiff
+		hunks int
+		lines int
+		score float64
+	}
+	candidates := make([]candidate, 0, len(files))
+	totalHunks := 0
+	for i, f := range files {
+		hunks, lines := measure(f.Content)
+		totalHunks += hunks
+		candidates = append(candidates, candidate{index: i, file: f, hunks: hunks, lines: lines, score: riskScore(f.Path, lines)})
+	}
+	if (limits.MaxFiles == 0 || len(files) <= limits.MaxFiles) && (limits.MaxHunks == 0 || totalHunks <= limits.MaxHunks) {
+		return Result{Diff: diff, Kept: len(files)}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].score > candidates[j].score
+	})
+
+	keep := make(map[int]bool, len(candidates))
+	var omitted []Omitted
+	usedFiles, usedHunks := 0, 0
+	for _, c := range candidates {
+		fitsFiles := limits.MaxFiles == 0 || usedFiles < limits.MaxFiles
+		fitsHunks := limits.MaxHunks == 0 || usedHunks+c.hunks <= limits.MaxHunks
+		if fitsFiles && fitsHunks {
+			keep[c.index] = true
+			usedFiles++
+			usedHunks += c.hunks
+			continue
+		}
+		omitted = append(omitted, Omitted{Path: c.file.Path, Hunks: c.hunks, Lines: c.lines})
+	}
+
+	var sb strings.Builder
+	for i, f := range files {
+		if keep[i] {
+			sb.WriteString(f.Content)
+		}
+	}
+	return Result{Diff: sb.String(), Kept: usedFiles, Omitted: omitted}
+}
+
+// RiskOrder は、差分に含まれるファイルのパスを、Apply と同じリスクの推定値が高い順に返します。
+func RiskOrder(diff string) []string {
+	type scored struct {
+		path  string
+		score float64
+	}
+	var files []scored
+	seen := make(map[string]bool)
+	for _, f := range monorepo.SplitDiff(diff) {
+		if seen[f.Path] {
+			continue
+		}
+		seen[f.Path] = true
+		_, lines := measure(f.Content)
+		files = append(files, scored{path: f.Path, score: riskScore(f.Path, lines)})
+	}
+	sort.SliceStable(files, func(i, j int) bool { return files[i].score > files[j].score })
+	paths := make([]string, len(files))
+	for i, f := range files {
+		paths[i] = f.path
+	}
+	return paths
+}
+
+// riskScore はファイルのレビュー優先度を推定します。値が大きいほど優先されます。
+func riskScore(path string, lines int) float64 {
+	score := 0.0
+	switch diffstat.Classify(path) {
+	case diffstat.Production:
+		score += 3
+	case diffstat.Config:
+		score += 2
+	case diffstat.Test:
+		score += 1
+	}
+	if highRiskPattern.MatchString(path) {
+		score += 3
+	}
+	if lowRiskPattern.MatchString(path) {
+		score -= 5
+	}
+	// 変更行数は対数で加味し、巨大な変更1件が他のファイルをすべて押し出さないようにします
+	return score + math.Log1p(float64(lines))
+}
+
+// measure は1ファイル分の差分のハンク数と変更行数を数えます。
+func measure(content string) (hunks, lines int) {
+	inHunk := false
+	for _, line := range strings.Split(content, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			hunks++
+			inHunk = true
+		case !inHunk:
+		case strings.HasPrefix(line, "+"), strings.HasPrefix(line, "-"):
+			lines++
+		}
+	}
+	return hunks, lines
+}
+
+// Notice はレビュー結果の冒頭に置く1行の警告です。除外がない場合は空文字列です。
+func (r Result) Notice() string {
+	if len(r.Omitted) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("⚠️ **差分が上限を超えたため、%d ファイルをレビュー対象から除外しました (一覧は末尾を参照)**", len(r.Omitted))
+}
+
+// OmittedSection はレビュー結果の末尾に置く、除外したファイルの一覧です。除外がない場合は空文字列です。
+func (r Result) OmittedSection() string {
+	if len(r.Omitted) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\n---\n\n### ⚠️ レビュー対象から除外されたファイル\n\n")
+	sb.WriteString("以下のファイルは `--max-files` / `--max-hunks` の上限により AI のレビュー対象に含まれていません。必要に応じて人手で確認してください。\n\n")
+	sb.WriteString("| ファイル | ハンク数 | 変更行数 |\n| :--- | ---: | ---: |\n")
+	for _, o := range r.Omitted {
+		fmt.Fprintf(&sb, "| `%s` | %d | %d |\n", o.Path, o.Hunks, o.Lines)
+	}
+	return sb.String()
+}
+
+// PromptNote は、一部のファイルが除外されていることを AI に伝える注記です。除外がない場合は空文字列です。
+func (r Result) PromptNote() string {
+	if len(r.Omitted) == 0 {
+		return ""
+	}
+	paths := make([]string, 0, len(r.Omitted))
+	for _, o := range r.Omitted {
+		paths = append(paths, o.Path)
+	}
+	return fmt.Sprintf("## ✂️ 差分の削減について\n\n差分が上限を超えたため、以下の %d ファイルは差分に含まれていません。これらのファイルの内容を推測して指摘しないでください: %s\n\n---\n\n",
+		len(paths), strings.Join(paths, ", "))
+}
@@ -0,0 +1,79 @@
+package diffguard
+
+import (
+	"path"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/monorepo"
+)
+
+// Exclude は、パスがいずれかのパターンに一致するファイルを差分から除外し、残った差分と除外したパスを返します。
+// パターンは gitignore に近い書式で、末尾以外に '/' を含まないパターンは任意の階層に一致し、'**' は0個以上のディレクトリに一致します。
+func Exclude(diff string, patterns []string) (string, []string) {
+	if len(patterns) == 0 {
+		return diff, nil
+	}
+	var sb strings.Builder
+	var excluded []string
+	for _, f := range monorepo.SplitDiff(diff) {
+		if matchAny(patterns, f.Path) {
+			excluded = append(excluded, f.Path)
+			continue
+		}
+		sb.WriteString(f.Content)
+	}
+	if len(excluded) == 0 {
+		return diff, nil
+	}
+	return sb.String(), excluded
+}
+
+func matchAny(patterns []string, p string) bool {
+	for _, pattern := range patterns {
+		if MatchPath(pattern, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchPath はファイルパスが除外パターンに一致するかを判定します。
+func MatchPath(pattern, p string) bool {
+	pattern = strings.TrimSpace(pattern)
+	// 末尾以外に '/' を含むパターンはリポジトリのルートからの相対パスとして扱います
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if pattern == "" {
+		return false
+	}
+	if strings.HasSuffix(pattern, "/") {
+		// ディレクトリ指定は配下のすべてのファイルに一致します
+		pattern += "**"
+	}
+	if !anchored {
+		pattern = "**/" + pattern
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(p, "/"))
+}
+
+// matchSegments はパスの区切りごとにパターンを照合します。
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], segments[0]); err != nil || !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
@@ -0,0 +1,91 @@
+package diffguard
+
+import (
+	"fmt"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/monorepo"
+)
+
+// DefaultOmitPatterns は、内容を省略する既定のファイルのパターンです。
+// 依存関係のロックファイルは変更量が大きい割にレビューの価値が低く、プロンプトを圧迫するため省略します。
+var DefaultOmitPatterns = []string{
+	"go.sum",
+	"package-lock.json",
+	"npm-shrinkwrap.json",
+	"yarn.lock",
+	"pnpm-lock.yaml",
+	"bun.lockb",
+	"Cargo.lock",
+	"Gemfile.lock",
+	"composer.lock",
+	"poetry.lock",
+	"Pipfile.lock",
+	"uv.lock",
+	"Podfile.lock",
+	"mix.lock",
+	"packages.lock.json",
+	"gradle.lockfile",
+}
+
+// OmittedMarker は、内容を省略したファイルの差分に置く行の接頭辞です。
+// '+' '-' ' ' '@' のいずれでも始まらないため、差分の変更行としては扱われません。
+const OmittedMarker = "(変更あり・内容は省略)"
+
+// Omit は、バイナリファイルとパスがいずれかのパターンに一致するファイルの差分を、ヘッダと「変更あり (省略)」の1行に置き換えます。
+// Exclude と異なり、ファイルが変更されたこと自体は AI に伝わります。置き換えた差分と、省略したパスを返します。
+func Omit(diff string, patterns []string) (string, []string) {
+	var sb strings.Builder
+	var omitted []string
+	for _, f := range monorepo.SplitDiff(diff) {
+		binary := isBinary(f.Content)
+		if !binary && !matchAny(patterns, f.Path) {
+			sb.WriteString(f.Content)
+			continue
+		}
+		omitted = append(omitted, f.Path)
+		sb.WriteString(omittedDiff(f.Content, binary))
+	}
+	if len(omitted) == 0 {
+		return diff, nil
+	}
+	return sb.String(), omitted
+}
+
+// isBinary は、1ファイル分の差分がバイナリファイルの変更かを判定します。
+func isBinary(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(line, "@@") {
+			break
+		}
+		if line == "GIT binary patch" || strings.HasPrefix(line, "Binary files ") && strings.HasSuffix(strings.TrimRight(line, "\r"), " differ") {
+			return true
+		}
+	}
+	return strings.IndexByte(content, 0) >= 0
+}
+
+// omittedDiff は、1ファイル分の差分のヘッダ (最初のハンクの前まで) に、変更行数を添えた省略の行を付けて返します。
+func omittedDiff(content string, binary bool) string {
+	var header strings.Builder
+	added, deleted := 0, 0
+	inHunk := false
+	for _, line := range strings.SplitAfter(content, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case binary && (line == "GIT binary patch\n" || strings.HasPrefix(line, "Binary files ")):
+			inHunk = true
+		case !inHunk:
+			header.WriteString(line)
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			deleted++
+		}
+	}
+	if binary {
+		return header.String() + OmittedMarker + " バイナリファイル\n"
+	}
+	return header.String() + fmt.Sprintf("%s +%d -%d 行\n", OmittedMarker, added, deleted)
+}
@@ -0,0 +1,263 @@
+package diffstat
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/monorepo"
+)
+
+// Category は変更ファイルの種類です。
+type Category string
+
+const (
+	Production Category = "production"
+	Test       Category = "test"
+	Docs       Category = "docs"
+	Config     Category = "config"
+)
+
+// Categories は集計結果を表示する順序です。
+var Categories = []Category{Production, Test, Docs, Config}
+
+// Label はカテゴリの表示名を返します。
+func (c Category) Label() string {
+	switch c {
+	case Production:
+		return "本番コード"
+	case Test:
+		return "テスト"
+	case Docs:
+		return "ドキュメント"
+	case Config:
+		return "設定"
+	}
+	return string(c)
+}
+
+// Count はカテゴリごとの変更量です。
+type Count struct {
+	Files   int `json:"files"`
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+}
+
+// Lines は追加行数と削除行数の合計です。
+func (c Count) Lines() int {
+	return c.Added + c.Removed
+}
+
+// File は1ファイルの変更量です。
+type File struct {
+	Path     string   `json:"path"`
+	Category Category `json:"category"`
+	Added    int      `json:"added"`
+	Removed  int      `json:"removed"`
+}
+
+// Lines は追加行数と削除行数の合計です。
+func (f File) Lines() int {
+	return f.Added + f.Removed
+}
+
+// Stats は差分全体の種類別の変更量です。
+type Stats struct {
+	ByCategory map[Category]Count
+	// Files は差分の順に並んだファイルごとの変更量です。
+	Files []File
+}
+
+// LargestFiles は、要約と構造化された出力に含める変更量の大きいファイルの件数です。
+const LargestFiles = 3
+
+// Classify はファイルパスから変更の種類を判定します。
+func Classify(p string) Category {
+	lower := strings.ToLower(p)
+	base := path.Base(lower)
+	ext := path.Ext(base)
+	dirs := "/" + path.Dir(lower) + "/"
+
+	switch {
+	case isTestFile(path.Base(p), dirs):
+		return Test
+	case ext == ".md" || ext == ".rst" || ext == ".adoc" || ext == ".txt" ||
+		strings.Contains(dirs, "/docs/") || strings.Contains(dirs, "/doc/") ||
+		strings.HasPrefix(base, "license") || strings.HasPrefix(base, "changelog"):
+		return Docs
+	case ext == ".yaml" || ext == ".yml" || ext == ".json" || ext == ".toml" || ext == ".ini" ||
+		ext == ".cfg" || ext == ".conf" || ext == ".properties" || ext == ".env" || ext == ".lock" || ext == ".sum" ||
+		base == "dockerfile" || base == "makefile" || base == "go.mod" || strings.HasPrefix(base, ".") ||
+		strings.Contains(dirs, "/.github/") || strings.Contains(dirs, "/deploy/") || strings.Contains(dirs, "/k8s/"):
+		return Config
+	}
+	return Production
+}
+
+// isTestFile はテストコード (テストデータを含む) かどうかを判定します。
+// Java などの FooTest / FooSpec 形式を判別するため、base は元の大文字小文字のまま受け取ります。
+func isTestFile(base, dirs string) bool {
+	name := strings.TrimSuffix(base, path.Ext(base))
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, "_test") || strings.HasPrefix(lower, "test_") ||
+		strings.HasSuffix(lower, ".test") || strings.HasSuffix(lower, ".spec") ||
+		strings.HasSuffix(name, "Test") || strings.HasSuffix(name, "Tests") || strings.HasSuffix(name, "Spec") ||
+		strings.Contains(dirs, "/test/") || strings.Contains(dirs, "/tests/") ||
+		strings.Contains(dirs, "/__tests__/") || strings.Contains(dirs, "/testdata/") ||
+		strings.Contains(dirs, "/spec/")
+}
+
+// Compute は unified diff を種類別に集計します。
+func Compute(diff string) Stats {
+	stats := Stats{ByCategory: make(map[Category]Count, len(Categories))}
+	for _, f := range monorepo.SplitDiff(diff) {
+		category := Classify(f.Path)
+		c := stats.ByCategory[category]
+		c.Files++
+		added, removed := countLines(f.Content)
+		c.Added += added
+		c.Removed += removed
+		stats.ByCategory[category] = c
+		stats.Files = append(stats.Files, File{Path: f.Path, Category: category, Added: added, Removed: removed})
+	}
+	return stats
+}
+
+// Total は差分全体の変更量です。
+func (s Stats) Total() Count {
+	var total Count
+	for _, c := range Categories {
+		n := s.ByCategory[c]
+		total.Files += n.Files
+		total.Added += n.Added
+		total.Removed += n.Removed
+	}
+	return total
+}
+
+// Largest は変更行数の多い順に最大 n 件のファイルを返します。同じ行数の場合は差分の順です。
+func (s Stats) Largest(n int) []File {
+	files := slices.Clone(s.Files)
+	slices.SortStableFunc(files, func(a, b File) int { return b.Lines() - a.Lines() })
+	if len(files) > n {
+		files = files[:n]
+	}
+	return files
+}
+
+// Summary はレビュー結果の冒頭に表示する、変更の規模の1行の要約です。
+// 例: "📏 **変更規模:** 4 ファイル (+120 / -30 行)。変更の大きいファイル: `auth/login.go` (+80 / -20)、..."
+func (s Stats) Summary() string {
+	total := s.Total()
+	if total.Files == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "📏 **変更規模:** %d ファイル (+%d / -%d 行)", total.Files, total.Added, total.Removed)
+	if total.Files > 1 {
+		largest := s.Largest(LargestFiles)
+		items := make([]string, len(largest))
+		for i, f := range largest {
+			items[i] = fmt.Sprintf("`%s` (+%d / -%d)", f.Path, f.Added, f.Removed)
+		}
+		sb.WriteString("。変更の大きいファイル: " + strings.Join(items, "、"))
+	}
+	return sb.String()
+}
+
+// Report は構造化された出力 (JSON) に含める差分の変更量です。
+type Report struct {
+	Files   int `json:"files"`
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	// ByCategory は本番コード・テスト・ドキュメント・設定の種類別の変更量です。
+	ByCategory map[Category]Count `json:"by_category"`
+	// LargestFiles は変更行数の多い順の、最大 LargestFiles 件のファイルの変更量です。
+	LargestFiles []File `json:"largest_files"`
+}
+
+// Report は集計結果を構造化された出力の形式に変換します。
+func (s Stats) Report() Report {
+	total := s.Total()
+	out := Report{
+		Files:        total.Files,
+		Added:        total.Added,
+		Removed:      total.Removed,
+		ByCategory:   make(map[Category]Count, len(Categories)),
+		LargestFiles: s.Largest(LargestFiles),
+	}
+	for _, c := range Categories {
+		out.ByCategory[c] = s.ByCategory[c]
+	}
+	if out.LargestFiles == nil {
+		out.LargestFiles = []File{}
+	}
+	return out
+}
+
+// countLines は1ファイル分の差分から追加行数と削除行数を数えます。
+func countLines(content string) (added, removed int) {
+	inHunk := false
+	for _, line := range strings.Split(content, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+		case !inHunk:
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// Untested は本番コードが変更されているにもかかわらず、テストが1件も変更されていないかを返します。
+func (s Stats) Untested() bool {
+	return s.ByCategory[Production].Files > 0 && s.ByCategory[Test].Files == 0
+}
+
+// Badge はレビュー結果の冒頭に表示する1行の要約です。
+// 例: "⚠️ 本番コード 500 行の変更に対して、テストファイルの変更は 0 件です"
+func (s Stats) Badge() string {
+	prod, test := s.ByCategory[Production], s.ByCategory[Test]
+	if prod.Files == 0 {
+		return ""
+	}
+	icon := "🧪"
+	if s.Untested() {
+		icon = "⚠️"
+	}
+	return fmt.Sprintf("%s **本番コード %d 行 (%d ファイル) の変更に対して、テストの変更は %d ファイル / %d 行です**",
+		icon, prod.Lines(), prod.Files, test.Files, test.Lines())
+}
+
+// Table は種類別の変更量を Markdown の表で返します。
+func (s Stats) Table() string {
+	var sb strings.Builder
+	sb.WriteString("| 種類 | ファイル数 | 追加行 | 削除行 |\n| :--- | ---: | ---: | ---: |\n")
+	for _, c := range Categories {
+		n := s.ByCategory[c]
+		fmt.Fprintf(&sb, "| %s | %d | %d | %d |\n", c.Label(), n.Files, n.Added, n.Removed)
+	}
+	return sb.String()
+}
+
+// PromptContext は AI に渡す変更構成の説明です。
+// release モードでは、テストを伴わない本番コードの変更をリスクとして重く評価するよう指示します。
+func (s Stats) PromptContext(mode string) string {
+	var sb strings.Builder
+	sb.WriteString("## 📊 変更構成 (ツールによる自動集計)\n\n")
+	sb.WriteString(s.Table())
+	sb.WriteString("\n")
+	if mode == "release" {
+		sb.WriteString("リリース判定では、本番コードの変更量に対するテストの変更量を重要な判断材料としてください。")
+		if s.Untested() {
+			sb.WriteString("**この変更セットには本番コードの変更がある一方でテストの変更がありません。** 回帰のリスクとして判定と理由に必ず反映してください。")
+		}
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("---\n\n")
+	return sb.String()
+}
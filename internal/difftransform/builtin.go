@@ -0,0 +1,55 @@
+package difftransform
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/diffguard"
+	"git-gemini-reviewer-go/internal/redact"
+)
+
+func init() {
+	Register("exclude", func(opts Options) (DiffTransformer, error) {
+		return NewFunc("exclude", func(_ context.Context, diff string) (string, error) {
+			diff, excluded := diffguard.Exclude(diff, opts.Excludes)
+			if len(excluded) > 0 {
+				slog.Info("除外パターンに一致したファイルをレビュー対象から除外しました。", "count", len(excluded), "files", excluded)
+			}
+			return diff, nil
+		}), nil
+	})
+	Register("omit", func(opts Options) (DiffTransformer, error) {
+		return NewFunc("omit", func(_ context.Context, diff string) (string, error) {
+			diff, omitted := diffguard.Omit(diff, opts.Omits)
+			if len(omitted) > 0 {
+				slog.Info("バイナリファイルと省略パターンに一致したファイルの内容を省略しました。", "count", len(omitted), "files", omitted)
+			}
+			return diff, nil
+		}), nil
+	})
+	Register("redact", func(Options) (DiffTransformer, error) {
+		return NewFunc("redact", func(_ context.Context, diff string) (string, error) {
+			return redact.String(diff), nil
+		}), nil
+	})
+	Register("normalize", func(Options) (DiffTransformer, error) {
+		return NewFunc("normalize", func(_ context.Context, diff string) (string, error) {
+			return normalize(diff), nil
+		}), nil
+	})
+}
+
+// normalize は改行コードを LF に揃え、レビューに不要な "\ No newline at end of file" の注記を取り除きます。
+func normalize(diff string) string {
+	diff = strings.ReplaceAll(diff, "\r\n", "\n")
+	lines := strings.Split(diff, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if strings.HasPrefix(line, `\ No newline at end of file`) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,124 @@
+// Package difftransform は、取得した差分をプロンプトの組み立て前に加工する変換器 (トランスフォーマー) を提供します。
+// 除外・秘匿情報のマスク・正規化などを連結した変換器として順に適用します。
+package difftransform
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DiffTransformer は unified diff を加工する変換器です。
+type DiffTransformer interface {
+	// Name はログとエラーに表示する変換器の名前です。
+	Name() string
+	// Transform は差分を加工して返します。
+	Transform(ctx context.Context, diff string) (string, error)
+}
+
+// Options は組み込みの変換器の構築に使用する設定です。
+type Options struct {
+	// Excludes は exclude 変換器で除外するファイルのパターンです。
+	Excludes []string
+	// Omits は omit 変換器で内容を省略するファイルのパターンです。バイナリファイルはパターンによらず省略します。
+	Omits []string
+}
+
+// Factory は設定から変換器を構築する関数です。
+type Factory func(opts Options) (DiffTransformer, error)
+
+// DefaultNames は既定で適用する変換器の名前です。
+var DefaultNames = []string{"exclude", "omit"}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]Factory{}
+)
+
+// Register は名前で指定できる変換器を登録します。同じ名前の登録は上書きします。
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// Names は登録済みの変換器の名前を昇順で返します。
+func Names() []string {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Chain は順に適用する変換器の列です。
+type Chain []DiffTransformer
+
+// Build は名前の列から変換器の列を構築します。
+// 名前には登録済みの変換器のほか、'plugin:パス.so' (Go プラグイン) と 'exec:コマンド' (外部コマンド) を指定できます。
+func Build(names []string, opts Options) (Chain, error) {
+	chain := make(Chain, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		t, err := build(name, opts)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, t)
+	}
+	return chain, nil
+}
+
+func build(name string, opts Options) (DiffTransformer, error) {
+	if path, ok := strings.CutPrefix(name, pluginPrefix); ok {
+		return openPlugin(path)
+	}
+	if command, ok := strings.CutPrefix(name, execPrefix); ok {
+		return Command(command), nil
+	}
+
+	registryMu.Lock()
+	factory, ok := registry[name]
+	registryMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("不明な差分の変換器です: '%s' (登録済み: %s。'plugin:' または 'exec:' でも指定できます)", name, strings.Join(Names(), ", "))
+	}
+	return factory(opts)
+}
+
+// Apply は変換器を順に適用します。いずれかが失敗した場合はエラーを返します。
+func (c Chain) Apply(ctx context.Context, diff string) (string, error) {
+	for _, t := range c {
+		before := len(diff)
+		var err error
+		diff, err = t.Transform(ctx, diff)
+		if err != nil {
+			return "", fmt.Errorf("差分の変換器 '%s' が失敗しました: %w", t.Name(), err)
+		}
+		slog.Debug("差分の変換器を適用しました。", "transformer", t.Name(), "before_bytes", before, "after_bytes", len(diff))
+	}
+	return diff, nil
+}
+
+// Func は関数を DiffTransformer として扱うためのアダプタです。
+type Func struct {
+	name string
+	fn   func(ctx context.Context, diff string) (string, error)
+}
+
+// NewFunc は名前と関数から DiffTransformer を生成します。
+func NewFunc(name string, fn func(ctx context.Context, diff string) (string, error)) Func {
+	return Func{name: name, fn: fn}
+}
+
+// Name は変換器の名前を返します。
+func (f Func) Name() string { return f.name }
+
+// Transform は関数を呼び出します。
+func (f Func) Transform(ctx context.Context, diff string) (string, error) { return f.fn(ctx, diff) }
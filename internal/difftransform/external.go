@@ -0,0 +1,57 @@
+package difftransform
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"plugin"
+	"time"
+)
+
+const (
+	pluginPrefix = "plugin:"
+	execPrefix   = "exec:"
+	// commandTimeout は外部コマンドの変換器1件に許容する実行時間です。
+	commandTimeout = 2 * time.Minute
+)
+
+// Command は、差分を標準入力に渡し、標準出力を加工後の差分として受け取る外部コマンドの変換器を返します。
+func Command(command string) DiffTransformer {
+	return NewFunc(execPrefix+command, func(ctx context.Context, diff string) (string, error) {
+		ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+		defer cancel()
+
+		var stdout bytes.Buffer
+		c := exec.CommandContext(ctx, "sh", "-c", command)
+		c.Stdin = bytes.NewBufferString(diff)
+		c.Stdout = &stdout
+		c.Stderr = os.Stderr
+		if err := c.Run(); err != nil {
+			return "", err
+		}
+		return stdout.String(), nil
+	})
+}
+
+// openPlugin は Go プラグインから 'Transformer' という名前の DiffTransformer を読み込みます。
+// プラグインは本ツールと同じバージョンの Go とこのパッケージで `go build -buildmode=plugin` によりビルドする必要があります。
+func openPlugin(path string) (DiffTransformer, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("差分の変換器のプラグインの読み込みに失敗しました (%s): %w", path, err)
+	}
+	sym, err := p.Lookup("Transformer")
+	if err != nil {
+		return nil, fmt.Errorf("プラグインに Transformer がありません (%s): %w", path, err)
+	}
+	switch t := sym.(type) {
+	case DiffTransformer:
+		return t, nil
+	case *DiffTransformer:
+		return *t, nil
+	default:
+		return nil, fmt.Errorf("プラグインの Transformer の型が不正です (%s): difftransform.DiffTransformer を実装する必要があります", path)
+	}
+}
@@ -0,0 +1,149 @@
+// Package digest は、レビュー履歴を期間ごとに集計したダイジェストを生成します。
+package digest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"git-gemini-reviewer-go/internal/findings"
+	"git-gemini-reviewer-go/internal/history"
+	"git-gemini-reviewer-go/internal/verdict"
+)
+
+// topCategories はダイジェストに表示する指摘カテゴリの最大数です。
+const topCategories = 5
+
+// Branch はブランチごとのレビュー件数と最新の判定です。
+type Branch struct {
+	RepoURL     string
+	Branch      string
+	Reviews     int
+	LastVerdict verdict.Verdict
+	LastAt      time.Time
+}
+
+// Category は指摘カテゴリと、その指摘件数です。
+type Category struct {
+	Category findings.Category
+	Count    int
+}
+
+// Digest は期間内のレビューの集計結果です。
+type Digest struct {
+	Since      time.Time
+	Until      time.Time
+	Total      int
+	Branches   []Branch
+	Verdicts   map[verdict.Verdict]int
+	Decisions  map[history.Outcome]int
+	Categories []Category
+}
+
+// Build は since から until までのレビューを集計します。
+func Build(reviews []history.Review, since, until time.Time) Digest {
+	d := Digest{
+		Since:     since,
+		Until:     until,
+		Verdicts:  make(map[verdict.Verdict]int),
+		Decisions: make(map[history.Outcome]int),
+	}
+	branches := make(map[string]*Branch)
+	categories := make(map[findings.Category]int)
+
+	for _, r := range reviews {
+		if r.ReviewedAt.Before(since) || r.ReviewedAt.After(until) {
+			continue
+		}
+		d.Total++
+		v := verdict.Verdict(r.Verdict)
+		if v == "" {
+			v = verdict.Unknown
+		}
+		d.Verdicts[v]++
+		if r.Decision != nil {
+			d.Decisions[r.Decision.Outcome]++
+		}
+
+		key := r.RepoURL + "\x00" + r.FeatureBranch
+		b, ok := branches[key]
+		if !ok {
+			b = &Branch{RepoURL: r.RepoURL, Branch: r.FeatureBranch}
+			branches[key] = b
+		}
+		b.Reviews++
+		if !r.ReviewedAt.Before(b.LastAt) {
+			b.LastAt, b.LastVerdict = r.ReviewedAt, v
+		}
+
+		for c, n := range r.FindingCounts() {
+			categories[c] += n
+		}
+	}
+
+	for _, b := range branches {
+		d.Branches = append(d.Branches, *b)
+	}
+	sort.Slice(d.Branches, func(i, j int) bool { return d.Branches[i].LastAt.After(d.Branches[j].LastAt) })
+
+	for c, n := range categories {
+		d.Categories = append(d.Categories, Category{Category: c, Count: n})
+	}
+	sort.Slice(d.Categories, func(i, j int) bool {
+		if d.Categories[i].Count != d.Categories[j].Count {
+			return d.Categories[i].Count > d.Categories[j].Count
+		}
+		return d.Categories[i].Category < d.Categories[j].Category
+	})
+	if len(d.Categories) > topCategories {
+		d.Categories = d.Categories[:topCategories]
+	}
+	return d
+}
+
+// Title はダイジェストの見出しを返します。
+func (d Digest) Title() string {
+	return fmt.Sprintf("AIレビュー ダイジェスト (%s 〜 %s)", d.Since.Format("2006-01-02"), d.Until.Format("2006-01-02"))
+}
+
+// Markdown はダイジェストの本文を Markdown で返します。
+func (d Digest) Markdown() string {
+	var sb strings.Builder
+	if d.Total == 0 {
+		sb.WriteString("期間内に実施されたレビューはありません。\n")
+		return sb.String()
+	}
+
+	fmt.Fprintf(&sb, "**レビュー件数:** %d 件 (%d ブランチ)\n\n", d.Total, len(d.Branches))
+
+	sb.WriteString("### 判定の内訳\n\n")
+	for _, v := range []verdict.Verdict{verdict.Blocked, verdict.Conditional, verdict.Approved, verdict.Unknown} {
+		if n := d.Verdicts[v]; n > 0 {
+			fmt.Fprintf(&sb, "- %s: %d 件 (%.0f%%)\n", v.Label(), n, float64(n)*100/float64(d.Total))
+		}
+	}
+	if len(d.Decisions) > 0 {
+		sb.WriteString("\n**人による判断:** ")
+		var parts []string
+		for _, o := range []history.Outcome{history.OutcomeApproved, history.OutcomeChangesRequested} {
+			if n := d.Decisions[o]; n > 0 {
+				parts = append(parts, fmt.Sprintf("%s %d 件", o.Label(), n))
+			}
+		}
+		sb.WriteString(strings.Join(parts, " / ") + "\n")
+	}
+
+	if len(d.Categories) > 0 {
+		sb.WriteString("\n### よく指摘されたカテゴリ\n\n")
+		for i, c := range d.Categories {
+			fmt.Fprintf(&sb, "%d. %s (%d 件)\n", i+1, c.Category.Label(), c.Count)
+		}
+	}
+
+	sb.WriteString("\n### レビューしたブランチ\n\n")
+	for _, b := range d.Branches {
+		fmt.Fprintf(&sb, "- `%s` (%s): %d 回, 最新の判定: %s\n", b.Branch, b.RepoURL, b.Reviews, b.LastVerdict.Label())
+	}
+	return sb.String()
+}
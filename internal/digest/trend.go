@@ -0,0 +1,111 @@
+package digest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"git-gemini-reviewer-go/internal/findings"
+	"git-gemini-reviewer-go/internal/history"
+)
+
+// Bucket はトレンドを集計する期間の単位です。
+type Bucket string
+
+const (
+	BucketWeek  Bucket = "week"
+	BucketMonth Bucket = "month"
+)
+
+// ParseBucket は文字列を Bucket に変換します。
+func ParseBucket(s string) (Bucket, error) {
+	switch b := Bucket(strings.ToLower(strings.TrimSpace(s))); b {
+	case BucketWeek, BucketMonth:
+		return b, nil
+	}
+	return "", fmt.Errorf("集計単位が不正です: '%s' ('%s' または '%s' を指定してください)", s, BucketWeek, BucketMonth)
+}
+
+// start は t を含む期間の開始日時を返します。週は月曜日始まりとします。
+func (b Bucket) start(t time.Time) time.Time {
+	y, m, d := t.Date()
+	if b == BucketMonth {
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	}
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+}
+
+// TrendRow は、リポジトリと期間ごとの指摘カテゴリの件数です。
+type TrendRow struct {
+	RepoURL string                    `json:"repo_url"`
+	Period  time.Time                 `json:"period"`
+	Reviews int                       `json:"reviews"`
+	Counts  map[findings.Category]int `json:"counts"`
+}
+
+// Trends は、レビュー履歴をリポジトリと期間ごとに集計し、リポジトリ・期間の昇順で返します。
+func Trends(reviews []history.Review, bucket Bucket) []TrendRow {
+	type key struct {
+		repo   string
+		period time.Time
+	}
+	rows := make(map[key]*TrendRow)
+	for _, r := range reviews {
+		k := key{r.RepoURL, bucket.start(r.ReviewedAt)}
+		row, ok := rows[k]
+		if !ok {
+			row = &TrendRow{RepoURL: k.repo, Period: k.period, Counts: make(map[findings.Category]int)}
+			rows[k] = row
+		}
+		row.Reviews++
+		for c, n := range r.FindingCounts() {
+			row.Counts[c] += n
+		}
+	}
+
+	result := make([]TrendRow, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, *row)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].RepoURL != result[j].RepoURL {
+			return result[i].RepoURL < result[j].RepoURL
+		}
+		return result[i].Period.Before(result[j].Period)
+	})
+	return result
+}
+
+// TrendsMarkdown は、リポジトリごとに期間 × カテゴリの件数表を Markdown で返します。
+func TrendsMarkdown(rows []TrendRow) string {
+	if len(rows) == 0 {
+		return "集計対象のレビューはありません。\n"
+	}
+
+	var sb strings.Builder
+	header := "| 期間 | レビュー数 |"
+	divider := "| :--- | ---: |"
+	for _, c := range findings.Categories() {
+		header += " " + c.Label() + " |"
+		divider += " ---: |"
+	}
+
+	repo := ""
+	for _, row := range rows {
+		if row.RepoURL != repo {
+			if repo != "" {
+				sb.WriteString("\n")
+			}
+			repo = row.RepoURL
+			fmt.Fprintf(&sb, "### %s\n\n%s\n%s\n", repo, header, divider)
+		}
+		fmt.Fprintf(&sb, "| %s | %d |", row.Period.Format("2006-01-02"), row.Reviews)
+		for _, c := range findings.Categories() {
+			fmt.Fprintf(&sb, " %d |", row.Counts[c])
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,91 @@
+// Package discord は、Discord の Webhook にレビュー結果を埋め込み (embed) として投稿するクライアントです。
+package discord
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"git-gemini-reviewer-go/internal/pkg/retry"
+)
+
+// titleLimit は埋め込みのタイトルの文字数の上限です。
+const titleLimit = 256
+
+// Client は Discord の Webhook のクライアントです。
+type Client struct {
+	httpClient *http.Client
+	webhookURL string
+}
+
+// NewClient は Client を生成します。
+func NewClient(httpClient *http.Client, webhookURL string) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{httpClient: httpClient, webhookURL: webhookURL}
+}
+
+// message は Webhook に POST するメッセージです。
+type message struct {
+	Embeds []embed `json:"embeds"`
+	// AllowedMentions は、レビュー結果に含まれる '@everyone' などでメンションが飛ばないよう、すべてのメンションを無効にします。
+	AllowedMentions allowedMentions `json:"allowed_mentions"`
+}
+
+type embed struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description"`
+}
+
+type allowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+// Post は、本文を1つの埋め込みとして投稿します。title が空の場合はタイトルを付けません。
+// 埋め込みの本文は 4096 文字までのため、呼び出し側で msgfit.Discord に収まるよう分割してください。
+func (c *Client) Post(ctx context.Context, title, content string) error {
+	msg := message{
+		Embeds:          []embed{{Title: truncate(title, titleLimit), Description: content}},
+		AllowedMentions: allowedMentions{Parse: []string{}},
+	}
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("Discord のメッセージのエンコードに失敗しました: %w", err))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("Discord の Webhook のリクエストの作成に失敗しました: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Discord の Webhook の呼び出しに失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+		err := fmt.Errorf("Discord の Webhook がエラーを返しました (status: %d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		// レート制限 (429) 以外のクライアントエラーはリトライしても解消しません
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// truncate は s を n 文字以下に切り詰めます。
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	r := []rune(s)
+	return string(r[:n-1]) + "…"
+}
@@ -0,0 +1,248 @@
+// Package estimate は、モデルを呼び出さずに、レビューに必要なリクエスト数・トークン数・費用・所要時間を見積もる機能を提供します。
+// 多数のリポジトリのレビューを実行する前に、費用と時間を把握するために使用します。
+package estimate
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"git-gemini-reviewer-go/internal/ratelimit"
+
+	"github.com/shouni/gemini-reviewer-core/pkg/adapters"
+	"gopkg.in/yaml.v3"
+)
+
+// Recorder は、プロンプトの入力トークン数を記録し、モデルを呼び出さない adapters.CodeReviewAI です。
+// レビュー結果として空文字列を返すため、パイプラインはレビュー結果なしとして終了します。
+type Recorder struct {
+	mu     sync.Mutex
+	tokens []int
+}
+
+var _ adapters.CodeReviewAI = (*Recorder)(nil)
+
+// NewRecorder は Recorder を返します。
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// ReviewCodeDiff はプロンプトの入力トークン数を記録します。
+func (r *Recorder) ReviewCodeDiff(ctx context.Context, finalPrompt string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens = append(r.tokens, ratelimit.EstimateTokens(finalPrompt))
+	return "", nil
+}
+
+// Tokens は、記録したリクエストごとの入力トークン数を返します。
+func (r *Recorder) Tokens() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int(nil), r.tokens...)
+}
+
+// Price は 100 万トークンあたりの料金 (USD) です。
+type Price struct {
+	Input  float64 `json:"input_per_million" yaml:"input_per_million"`
+	Output float64 `json:"output_per_million" yaml:"output_per_million"`
+}
+
+// Cost は、入力と出力のトークン数の料金 (USD) を返します。
+func (p Price) Cost(input, output int) float64 {
+	return (float64(input)*p.Input + float64(output)*p.Output) / 1e6
+}
+
+// Prices は、モデルごとの公開料金 (有料枠、USD) です。料金の改定に追従していない場合があるため、
+// 正確な見積もりには --pricing-file、または --input-price と --output-price で上書きしてください。
+var Prices = map[string]Price{
+	"gemini-2.5-pro":        {Input: 1.25, Output: 10},
+	"gemini-2.5-flash":      {Input: 0.30, Output: 2.50},
+	"gemini-2.5-flash-lite": {Input: 0.10, Output: 0.40},
+	"gemini-2.0-flash":      {Input: 0.10, Output: 0.40},
+	"gemini-2.0-flash-lite": {Input: 0.075, Output: 0.30},
+	"gpt-4o":                {Input: 2.50, Output: 10},
+	"gpt-4o-mini":           {Input: 0.15, Output: 0.60},
+}
+
+// LoadPrices は、組み込みの料金表に path の料金表 (YAML または JSON) を重ねたモデルごとの料金を返します。
+// path が空の場合は組み込みの料金表を返します。ファイルの形式は、モデル名をキーとする Price のマップです。
+//
+//	gemini-2.5-pro: {input_per_million: 1.25, output_per_million: 10}
+func LoadPrices(path string) (map[string]Price, error) {
+	prices := maps.Clone(Prices)
+	if path == "" {
+		return prices, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("料金表 '%s' の読み込みに失敗しました: %w", path, err)
+	}
+	var custom map[string]Price
+	if err := yaml.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("料金表 '%s' の解析に失敗しました: %w", path, err)
+	}
+	for model, p := range custom {
+		if p.Input < 0 || p.Output < 0 {
+			return nil, fmt.Errorf("料金表 '%s' のモデル '%s' の料金が負の値です", path, model)
+		}
+		prices[model] = p
+	}
+	return prices, nil
+}
+
+// Assumptions は、プロンプトから分からない値の仮定です。
+type Assumptions struct {
+	// OutputTokens は1リクエストあたりの出力トークン数です。
+	OutputTokens int
+	// RequestDuration は1リクエストの応答にかかる時間です。
+	RequestDuration time.Duration
+	// Price が設定されている場合は、モデルによらずこの料金を使用します。
+	Price *Price
+	// Prices はモデルごとの料金です。nil の場合は組み込みの料金表 (Prices) を使用します。
+	Prices map[string]Price
+	// Limits は --ai-qpm と --ai-tpm のレート制限です。所要時間の下限の計算に使用します。
+	Limits ratelimit.Limits
+}
+
+// price はモデルの料金を返します。料金が不明な場合は false を返します。
+func (a Assumptions) price(model string) (Price, bool) {
+	if a.Price != nil {
+		return *a.Price, true
+	}
+	prices := a.Prices
+	if prices == nil {
+		prices = Prices
+	}
+	p, ok := prices[model]
+	return p, ok
+}
+
+// Item は1件のレビュー対象の見積もりです。
+type Item struct {
+	Name         string `json:"name"`
+	Model        string `json:"model,omitempty"`
+	Requests     int    `json:"requests"`
+	InputTokens  int    `json:"input_tokens"`
+	OutputTokens int    `json:"output_tokens"`
+	// CostUSD は費用の見積もりです。モデルの料金が不明な場合は nil です。
+	CostUSD *float64 `json:"cost_usd,omitempty"`
+	// DurationMS は、リクエストを順に実行する場合の所要時間の見積もり (ミリ秒) です。
+	DurationMS int64 `json:"duration_ms"`
+	// Error は差分の取得などに失敗した場合のエラーです。
+	Error string `json:"error,omitempty"`
+}
+
+// NewItem は、リクエストごとの入力トークン数から見積もりを作成します。
+func NewItem(name, model string, tokens []int, a Assumptions) Item {
+	item := Item{Name: name, Model: model, Requests: len(tokens)}
+	for _, t := range tokens {
+		item.InputTokens += t
+	}
+	item.OutputTokens = item.Requests * a.OutputTokens
+	if p, ok := a.price(model); ok {
+		cost := p.Cost(item.InputTokens, item.OutputTokens)
+		item.CostUSD = &cost
+	}
+	item.DurationMS = a.duration(item.Requests, item.InputTokens).Milliseconds()
+	return item
+}
+
+// Failed は、見積もりに失敗したレビュー対象の Item を返します。
+func Failed(name, model string, err error) Item {
+	return Item{Name: name, Model: model, Error: err.Error()}
+}
+
+// duration は、リクエストを順に実行する場合の所要時間を返します。
+// レート制限が設定されている場合は、制限から求めた時間を下限とします。
+// レート制限のバケットは満杯の状態から始まるため、1分あたりの上限を超えた分のみ待機が発生します。
+func (a Assumptions) duration(requests, inputTokens int) time.Duration {
+	d := time.Duration(requests) * a.RequestDuration
+	if limit := a.Limits.RequestsPerMinute; limit > 0 {
+		d = max(d, minutes(float64(max(requests-limit, 0))/float64(limit)))
+	}
+	if limit := a.Limits.TokensPerMinute; limit > 0 {
+		d = max(d, minutes(float64(max(inputTokens-limit, 0))/float64(limit)))
+	}
+	return d
+}
+
+func minutes(m float64) time.Duration {
+	return time.Duration(math.Ceil(m * float64(time.Minute)))
+}
+
+// Report は見積もりの一覧と合計です。
+type Report struct {
+	Items []Item `json:"items"`
+	Total Item   `json:"total"`
+	// AssumedOutputTokens と AssumedRequestMS は、見積もりに使用した1リクエストあたりの出力トークン数と応答時間です。
+	AssumedOutputTokens int   `json:"assumed_output_tokens_per_request"`
+	AssumedRequestMS    int64 `json:"assumed_request_ms"`
+}
+
+// NewReport は見積もりの一覧から合計を計算します。
+// 合計の費用は、いずれかの料金が不明な場合は nil です。所要時間はすべてのリクエストを順に実行する場合の時間です。
+func NewReport(items []Item, a Assumptions) Report {
+	total := Item{Name: "合計"}
+	known := true
+	var totalCost float64
+	for _, item := range items {
+		if item.Error != "" {
+			continue
+		}
+		total.Requests += item.Requests
+		total.InputTokens += item.InputTokens
+		total.OutputTokens += item.OutputTokens
+		if item.CostUSD == nil {
+			known = known && item.Requests == 0
+			continue
+		}
+		totalCost += *item.CostUSD
+	}
+	if known {
+		total.CostUSD = &totalCost
+	}
+	total.DurationMS = a.duration(total.Requests, total.InputTokens).Milliseconds()
+	return Report{Items: items, Total: total, AssumedOutputTokens: a.OutputTokens, AssumedRequestMS: a.RequestDuration.Milliseconds()}
+}
+
+// Markdown は見積もりを Markdown の表で返します。
+func (r Report) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("# 💰 レビューの見積もり\n\n")
+	sb.WriteString("| レビュー対象 | モデル | リクエスト数 | 入力トークン | 出力トークン | 費用 (USD) | 所要時間 |\n")
+	sb.WriteString("| :--- | :--- | ---: | ---: | ---: | ---: | ---: |\n")
+	for _, item := range r.Items {
+		if item.Error != "" {
+			fmt.Fprintf(&sb, "| %s | %s | ❌ %s | | | | |\n", item.Name, item.Model, cell(item.Error))
+			continue
+		}
+		writeRow(&sb, item, item.Name)
+	}
+	writeRow(&sb, r.Total, "**"+r.Total.Name+"**")
+	fmt.Fprintf(&sb, "\n入力トークンはプロンプトの長さからの概算です。出力トークンは1リクエストあたり %d、応答時間は1リクエストあたり %s と仮定しています。\n",
+		r.AssumedOutputTokens, time.Duration(r.AssumedRequestMS)*time.Millisecond)
+	return sb.String()
+}
+
+func writeRow(sb *strings.Builder, item Item, name string) {
+	costText := "不明"
+	if item.CostUSD != nil {
+		costText = fmt.Sprintf("$%.4f", *item.CostUSD)
+	}
+	fmt.Fprintf(sb, "| %s | %s | %d | %d | %d | %s | %s |\n",
+		name, item.Model, item.Requests, item.InputTokens, item.OutputTokens, costText, (time.Duration(item.DurationMS) * time.Millisecond).Round(time.Second))
+}
+
+// cell は表のセルに入れる文字列から、表を崩す文字を取り除きます。
+func cell(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
@@ -0,0 +1,77 @@
+// Package experiment は、2つのプロンプトの派生 (A/B) をトラフィックの割合で振り分ける実験を提供します。
+// A は組み込みのプロンプトテンプレート、B は利用者が指定したテンプレートです。
+// 振り分けた派生はレビュー履歴と配信レポートに記録し、テンプレートを切り替える前にレビュー品質を比較できるようにします。
+package experiment
+
+import (
+	"fmt"
+	"hash/fnv"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/shouni/gemini-reviewer-core/pkg/prompts"
+)
+
+// プロンプトの派生の名前です。
+const (
+	// VariantA は組み込みのプロンプトテンプレートです (対照群)。
+	VariantA = "A"
+	// VariantB は --prompt-variant-b で指定したプロンプトテンプレートです。
+	VariantB = "B"
+)
+
+// Assign は、振り分けキーと B に割り当てる割合 (0〜100) から派生を決定します。
+// 同じキー (リポジトリとブランチ) の再レビューが常に同じ派生になるよう、キーのハッシュ値で決定的に振り分けます。
+func Assign(key string, split int) string {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	if int(h.Sum32()%100) < split {
+		return VariantB
+	}
+	return VariantA
+}
+
+// ValidateSplit は B に割り当てる割合が 0〜100 の範囲にあるかを検証します。
+func ValidateSplit(split int) error {
+	if split < 0 || split > 100 {
+		return fmt.Errorf("--prompt-split には 0 から 100 の値を指定してください: %d", split)
+	}
+	return nil
+}
+
+// LoadTemplate は B のプロンプトテンプレートを読み込みます。
+// テンプレートは text/template 形式で、組み込みのテンプレートと同じく {{.DiffContent}} で差分を参照できます。
+func LoadTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("プロンプトテンプレートの読み込みに失敗しました (%s): %w", path, err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return nil, fmt.Errorf("プロンプトテンプレートが空です (%s)", path)
+	}
+	tmpl, err := template.New(path).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("プロンプトテンプレートの解析に失敗しました (%s): %w", path, err)
+	}
+	return tmpl, nil
+}
+
+// PromptBuilder は、レビューモードによらず利用者が指定したテンプレートでプロンプトを組み立てます。
+type PromptBuilder struct {
+	tmpl *template.Template
+}
+
+// NewPromptBuilder は PromptBuilder を生成します。
+func NewPromptBuilder(tmpl *template.Template) *PromptBuilder {
+	return &PromptBuilder{tmpl: tmpl}
+}
+
+// Build は prompts.ReviewPromptBuilder を満たします。
+func (b *PromptBuilder) Build(mode string, data prompts.TemplateData) (string, error) {
+	var sb strings.Builder
+	if err := b.tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("プロンプトテンプレート (派生 %s, モード %s) の実行に失敗しました: %w", VariantB, mode, err)
+	}
+	return sb.String(), nil
+}
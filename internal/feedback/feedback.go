@@ -0,0 +1,218 @@
+package feedback
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Vote はレビューに対する評価 (👍/👎) を表します。
+type Vote string
+
+const (
+	VoteUp   Vote = "up"
+	VoteDown Vote = "down"
+)
+
+// ParseVote は文字列を Vote に変換します。
+func ParseVote(s string) (Vote, error) {
+	switch Vote(strings.ToLower(strings.TrimSpace(s))) {
+	case VoteUp:
+		return VoteUp, nil
+	case VoteDown:
+		return VoteDown, nil
+	}
+	return "", fmt.Errorf("不明な評価です: '%s' ('up' または 'down' を指定してください)", s)
+}
+
+// Record は1件のフィードバックを表します。
+// Count はリアクション集計のように複数票をまとめて記録する場合に使用します。
+type Record struct {
+	ReviewID   string    `json:"review_id"`
+	Source     string    `json:"source"`
+	Vote       Vote      `json:"vote"`
+	Count      int       `json:"count"`
+	RecordedAt time.Time `json:"recorded_at"`
+	// Voter はリンク経由の投票者の識別子 (Cookie のハッシュ) です。同じ投票者の同じレビューへの投票は最新の1票のみを集計します。
+	Voter string `json:"voter,omitempty"`
+}
+
+// Summary はレビューIDごとの集計結果です。
+type Summary struct {
+	ReviewID string `json:"review_id"`
+	Up       int    `json:"up"`
+	Down     int    `json:"down"`
+}
+
+// Store はフィードバックを JSON Lines 形式のファイルに追記保存します。
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore は指定されたパスを保存先とする Store を生成します。
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Append はフィードバックを1件追記します。
+func (s *Store) Append(rec Record) error {
+	if rec.ReviewID == "" {
+		return errors.New("レビューIDが空のフィードバックは記録できません")
+	}
+	// リンク経由の投票は1アクセス1票として扱います。
+	if rec.Source == SourceLink && rec.Count == 0 {
+		rec.Count = 1
+	}
+	if rec.RecordedAt.IsZero() {
+		rec.RecordedAt = time.Now()
+	}
+
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("フィードバックのエンコードに失敗しました: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("フィードバック保存先ディレクトリの作成に失敗しました: %w", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("フィードバックファイルのオープンに失敗しました: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("フィードバックの書き込みに失敗しました: %w", err)
+	}
+	return nil
+}
+
+// Summarize は保存済みのフィードバックをレビューIDごとに集計します。
+// 同一レビュー・同一ソースのリアクション集計と、同一レビュー・同一投票者のリンク経由の投票は最新の値のみを採用します。
+func (s *Store) Summarize() ([]Summary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("フィードバックファイルのオープンに失敗しました: %w", err)
+	}
+	defer f.Close()
+
+	type snapshotKey struct {
+		reviewID string
+		source   string
+		vote     Vote
+	}
+	snapshots := make(map[snapshotKey]int)
+	type voterKey struct {
+		reviewID string
+		voter    string
+	}
+	voters := make(map[voterKey]Vote)
+	summaries := make(map[string]*Summary)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		if _, ok := summaries[rec.ReviewID]; !ok {
+			summaries[rec.ReviewID] = &Summary{ReviewID: rec.ReviewID}
+		}
+		if rec.Source == SourceLink && rec.Voter != "" {
+			voters[voterKey{rec.ReviewID, rec.Voter}] = rec.Vote
+			continue
+		}
+		if rec.Source == SourceLink {
+			addVote(summaries[rec.ReviewID], rec.Vote, rec.Count)
+			continue
+		}
+		snapshots[snapshotKey{rec.ReviewID, rec.Source, rec.Vote}] = rec.Count
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("フィードバックファイルの読み込みに失敗しました: %w", err)
+	}
+
+	for key, count := range snapshots {
+		addVote(summaries[key.reviewID], key.vote, count)
+	}
+	for key, vote := range voters {
+		addVote(summaries[key.reviewID], vote, 1)
+	}
+
+	result := make([]Summary, 0, len(summaries))
+	for _, sum := range summaries {
+		result = append(result, *sum)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ReviewID < result[j].ReviewID })
+	return result, nil
+}
+
+func addVote(sum *Summary, vote Vote, count int) {
+	switch vote {
+	case VoteUp:
+		sum.Up += count
+	case VoteDown:
+		sum.Down += count
+	}
+}
+
+// Footer は投稿本文の末尾に付与する 👍/👎 リンクを Markdown で生成します。
+// baseURL または secret が空の場合は空文字列を返します。
+func Footer(baseURL, secret, reviewID string) string {
+	if baseURL == "" || secret == "" || reviewID == "" {
+		return ""
+	}
+	return fmt.Sprintf(
+		"\n\n---\nこのレビューは役に立ちましたか？ [👍 役に立った](%s) / [👎 役に立たなかった](%s)\n",
+		VoteURL(baseURL, secret, reviewID, VoteUp),
+		VoteURL(baseURL, secret, reviewID, VoteDown),
+	)
+}
+
+// VoteURL はフィードバック受付エンドポイントへの、secret で署名したリンクを生成します。
+func VoteURL(baseURL, secret, reviewID string, vote Vote) string {
+	q := url.Values{}
+	q.Set("review_id", reviewID)
+	q.Set("vote", string(vote))
+	q.Set("sig", Sign(secret, reviewID, vote))
+	return strings.TrimRight(baseURL, "/") + HandlerPath + "?" + q.Encode()
+}
+
+// Sign は、レビューIDと評価に対する HMAC-SHA256 の署名を16進数で返します。
+// 署名のないリンクや、別のレビュー・評価の署名を付け替えたリンクからの投票を拒否するために使用します。
+func Sign(secret, reviewID string, vote Vote) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(reviewID + "\x00" + string(vote)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify は、リンクの署名がレビューIDと評価に一致するかを検証します。
+func verify(secret, reviewID string, vote Vote, signature string) bool {
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	want, _ := hex.DecodeString(Sign(secret, reviewID, vote))
+	return hmac.Equal(got, want)
+}
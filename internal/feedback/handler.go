@@ -0,0 +1,130 @@
+package feedback
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net"
+	"net/http"
+)
+
+const (
+	// HandlerPath はフィードバック受付エンドポイントのパスです。
+	HandlerPath = "/feedback"
+
+	// SourceLink はコメント内の 👍/👎 リンク経由の投票を表します。
+	SourceLink = "link"
+	// SourceSlackReaction は Slack のリアクション集計を表します。
+	SourceSlackReaction = "slack-reaction"
+
+	// voterCookie は投票者を識別する Cookie の名前です。
+	voterCookie = "reviewer_feedback_voter"
+	// voterCookieMaxAge は投票者の Cookie の有効期間 (秒) です。
+	voterCookieMaxAge = 365 * 24 * 60 * 60
+)
+
+// confirmPage は、リンクを開いた人に投票の確認を求めるページです。
+// チャットのリンクのプレビューやクローラーの GET で投票が記録されないよう、投票はボタンの POST でのみ受け付けます。
+var confirmPage = template.Must(template.New("confirm").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>フィードバック</title></head><body>
+<form method="post">
+<input type="hidden" name="review_id" value="{{.ReviewID}}">
+<input type="hidden" name="vote" value="{{.Vote}}">
+<input type="hidden" name="sig" value="{{.Sig}}">
+<p>このレビューに「{{.Label}}」と投票しますか？</p>
+<button type="submit">投票する</button>
+</form>
+</body></html>`))
+
+// NewHandler は 👍/👎 リンクからの投票を記録する http.Handler を返します。
+// リンクは VoteURL で secret を使って署名されている必要があります。
+// GET は投票の確認ページを返し、POST でのみ投票を記録します。同じ投票者の同じレビューへの投票は最新の1票として集計されます。
+func NewHandler(store *Store, secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		reviewID := r.FormValue("review_id")
+		if reviewID == "" {
+			http.Error(w, "review_id is required", http.StatusBadRequest)
+			return
+		}
+		vote, err := ParseVote(r.FormValue("vote"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sig := r.FormValue("sig")
+		if !verify(secret, reviewID, vote, sig) {
+			slog.Warn("署名が一致しないフィードバックを拒否しました。", "review_id", reviewID, "remote", r.RemoteAddr)
+			http.Error(w, "invalid signature", http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		if r.Method == http.MethodGet {
+			ensureVoterCookie(w, r)
+			label := "👍 役に立った"
+			if vote == VoteDown {
+				label = "👎 役に立たなかった"
+			}
+			data := struct{ ReviewID, Vote, Sig, Label string }{reviewID, string(vote), sig, label}
+			if err := confirmPage.Execute(w, data); err != nil {
+				slog.Error("フィードバックの確認ページの書き込みに失敗しました。", "error", err)
+			}
+			return
+		}
+
+		voter := voterID(r)
+		if err := store.Append(Record{ReviewID: reviewID, Source: SourceLink, Vote: vote, Voter: voter}); err != nil {
+			slog.Error("フィードバックの記録に失敗しました。", "review_id", reviewID, "error", err)
+			http.Error(w, "failed to record feedback", http.StatusInternalServerError)
+			return
+		}
+		slog.Info("フィードバックを記録しました。", "review_id", reviewID, "vote", vote, "voter", voter)
+
+		fmt.Fprint(w, "<!DOCTYPE html><html><body><p>フィードバックありがとうございました。</p></body></html>")
+	})
+}
+
+// ensureVoterCookie は、投票者を識別する Cookie がない場合に発行します。
+func ensureVoterCookie(w http.ResponseWriter, r *http.Request) {
+	if c, err := r.Cookie(voterCookie); err == nil && c.Value != "" {
+		return
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		slog.Warn("投票者の識別子の生成に失敗しました。", "error", err)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     voterCookie,
+		Value:    hex.EncodeToString(b),
+		Path:     HandlerPath,
+		MaxAge:   voterCookieMaxAge,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// voterID は、投票を重複して集計しないための投票者の識別子を返します。
+// Cookie がない場合 (Cookie を無効にしている場合など) は接続元のアドレスで識別します。
+// 記録に Cookie やアドレスをそのまま残さないよう、ハッシュを返します。
+func voterID(r *http.Request) string {
+	id := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		id = host
+	}
+	if c, err := r.Cookie(voterCookie); err == nil && c.Value != "" {
+		id = "cookie:" + c.Value
+	}
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:8])
+}
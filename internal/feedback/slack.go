@@ -0,0 +1,81 @@
+package feedback
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const slackReactionsGetURL = "https://slack.com/api/reactions.get"
+
+// upReactions / downReactions は 👍/👎 として扱う Slack のリアクション名です。
+var (
+	upReactions   = map[string]bool{"+1": true, "thumbsup": true, "white_check_mark": true}
+	downReactions = map[string]bool{"-1": true, "thumbsdown": true, "x": true}
+)
+
+// SlackReactionCounter は Slack Web API (reactions.get) からリアクション数を取得します。
+// Incoming Webhook ではリアクションを参照できないため、Bot トークンが必要です。
+type SlackReactionCounter struct {
+	httpClient *http.Client
+	token      string
+}
+
+// NewSlackReactionCounter は SlackReactionCounter を生成します。
+func NewSlackReactionCounter(httpClient *http.Client, token string) *SlackReactionCounter {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &SlackReactionCounter{httpClient: httpClient, token: token}
+}
+
+type slackReactionsResponse struct {
+	OK      bool   `json:"ok"`
+	Error   string `json:"error"`
+	Message struct {
+		Reactions []struct {
+			Name  string `json:"name"`
+			Count int    `json:"count"`
+		} `json:"reactions"`
+	} `json:"message"`
+}
+
+// Count は指定されたメッセージに付与された 👍/👎 系リアクションの数を返します。
+func (c *SlackReactionCounter) Count(ctx context.Context, channel, timestamp string) (up, down int, err error) {
+	q := url.Values{}
+	q.Set("channel", channel)
+	q.Set("timestamp", timestamp)
+	q.Set("full", "true")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, slackReactionsGetURL+"?"+q.Encode(), nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Slack APIリクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Slack API (reactions.get) の呼び出しに失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body slackReactionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, 0, fmt.Errorf("Slack APIレスポンスのデコードに失敗しました: %w", err)
+	}
+	if !body.OK {
+		return 0, 0, fmt.Errorf("Slack API (reactions.get) がエラーを返しました: %s", body.Error)
+	}
+
+	for _, r := range body.Message.Reactions {
+		switch {
+		case upReactions[r.Name]:
+			up += r.Count
+		case downReactions[r.Name]:
+			down += r.Count
+		}
+	}
+	return up, down, nil
+}
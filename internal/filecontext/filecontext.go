@@ -0,0 +1,184 @@
+// Package filecontext は、変更されたファイルの内容 (全体または変更箇所の前後の行) をプロンプトに添えます。
+// 差分のハンクだけでは分からない、変更箇所の外側のコードを踏まえて AI がレビューできるようにするためのものです。
+package filecontext
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/diffguard"
+	"git-gemini-reviewer-go/internal/monorepo"
+	"git-gemini-reviewer-go/internal/ratelimit"
+)
+
+// DefaultMaxBytes は、内容を添えるファイルの既定の最大サイズ (バイト) です。
+const DefaultMaxBytes = 32 * 1024
+
+// Mode はファイルの内容の添え方です。
+type Mode struct {
+	// Full が true の場合はファイル全体を添えます。
+	Full bool
+	// Lines が正の場合は、各ハンクの変更箇所の前後 Lines 行を添えます。
+	Lines int
+}
+
+// Parse は --file-context の値を解析します。
+// 'none' (または空文字列) は無効、'full' はファイル全体、正の整数 N は変更箇所の前後 N 行です。
+func Parse(s string) (Mode, error) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "", "none":
+		return Mode{}, nil
+	case "full":
+		return Mode{Full: true}, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return Mode{}, fmt.Errorf("--file-context の値が不正です: '%s' ('none'、'full' または1以上の行数を指定してください)", s)
+	}
+	return Mode{Lines: n}, nil
+}
+
+// Enabled はファイルの内容を添えるかを返します。
+func (m Mode) Enabled() bool {
+	return m.Full || m.Lines > 0
+}
+
+// Collect は、差分に含まれるファイルのうち maxBytes 以下のファイルの変更後の内容を read で読み込み、パスごとに返します。
+// 削除されたファイル (read が nil を返すファイル)、読み込めないファイルとバイナリファイルは含めません。
+func Collect(diff string, maxBytes int, read func(path string) ([]byte, error)) map[string]string {
+	contents := make(map[string]string)
+	for _, f := range monorepo.SplitDiff(diff) {
+		if _, ok := contents[f.Path]; ok || f.Path == "" {
+			continue
+		}
+		content, err := read(f.Path)
+		if err != nil || content == nil || len(content) > maxBytes || strings.IndexByte(string(content), 0) >= 0 {
+			continue
+		}
+		contents[f.Path] = string(content)
+	}
+	return contents
+}
+
+// PromptNote は、diff に含まれるファイルの内容を行番号付きで AI に伝えるプロンプトの前置きを返します。
+// 除外されたファイルは diff に含まれず、内容を省略したファイルは添えないため、除外と省略の指定はそのまま反映されます。
+// maxTokens が正の場合は、前置き全体の推定トークン数が maxTokens を超えないよう、収まらないファイルを省略します。
+func PromptNote(contents map[string]string, diff string, mode Mode, maxTokens int) string {
+	if !mode.Enabled() || len(contents) == 0 {
+		return ""
+	}
+	const header = "## 📄 変更されたファイルの内容 (ツールによる自動取得)\n\n" +
+		"差分の外側のコードも踏まえてレビューできるよう、変更後のファイルの内容を行番号付きで示します。指摘の対象は差分の変更に限ってください。\n\n"
+	const footer = "---\n\n"
+
+	var blocks []string
+	tokens := ratelimit.EstimateTokens(header + footer)
+	skipped := 0
+	seen := make(map[string]bool)
+	for _, f := range monorepo.SplitDiff(diff) {
+		content, ok := contents[f.Path]
+		if !ok || seen[f.Path] || strings.Contains(f.Content, "\n"+diffguard.OmittedMarker) {
+			continue
+		}
+		seen[f.Path] = true
+		block := fileBlock(f.Path, f.Content, content, mode)
+		if block == "" {
+			continue
+		}
+		n := ratelimit.EstimateTokens(block)
+		if maxTokens > 0 && tokens+n > maxTokens {
+			skipped++
+			continue
+		}
+		tokens += n
+		blocks = append(blocks, block)
+	}
+	if len(blocks) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString(header)
+	for _, b := range blocks {
+		sb.WriteString(b)
+	}
+	if skipped > 0 {
+		fmt.Fprintf(&sb, "(入力トークン数の上限のため、ほか %d 件のファイルの内容は省略)\n\n", skipped)
+	}
+	sb.WriteString(footer)
+	return sb.String()
+}
+
+// fileBlock は1ファイル分の内容を、見出しと行番号付きのコードブロックで返します。
+func fileBlock(path, fileDiff, content string, mode Mode) string {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	ranges := []lineRange{{1, len(lines)}}
+	title := "全体"
+	if !mode.Full {
+		ranges = hunkRanges(fileDiff, mode.Lines, len(lines))
+		title = fmt.Sprintf("変更箇所の前後 %d 行", mode.Lines)
+	}
+	if len(ranges) == 0 {
+		return ""
+	}
+
+	fence := "```"
+	for strings.Contains(content, fence) {
+		fence += "`"
+	}
+	width := len(strconv.Itoa(len(lines)))
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### `%s` (%s)\n\n%s\n", path, title, fence)
+	for i, r := range ranges {
+		if i > 0 {
+			sb.WriteString("...\n")
+		}
+		for n := r.start; n <= r.end; n++ {
+			fmt.Fprintf(&sb, "%*d | %s\n", width, n, lines[n-1])
+		}
+	}
+	sb.WriteString(fence + "\n\n")
+	return sb.String()
+}
+
+// lineRange は変更後のファイルの行の範囲 (1始まり、両端を含む) です。
+type lineRange struct {
+	start, end int
+}
+
+// hunkHeader はハンクのヘッダ行から、変更後の開始行と行数を取り出します。
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// hunkRanges は、1ファイル分の差分の各ハンクの変更後の範囲を前後 context 行に広げ、重なる範囲をまとめて返します。
+func hunkRanges(fileDiff string, context, total int) []lineRange {
+	var ranges []lineRange
+	for _, line := range strings.Split(fileDiff, "\n") {
+		m := hunkHeader.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		start, _ := strconv.Atoi(m[1])
+		count := 1
+		if m[2] != "" {
+			count, _ = strconv.Atoi(m[2])
+		}
+		end := start + max(count, 1) - 1
+		ranges = append(ranges, lineRange{max(start-context, 1), min(end+context, total)})
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start < ranges[j].start })
+
+	var merged []lineRange
+	for _, r := range ranges {
+		if r.start > r.end {
+			continue
+		}
+		if n := len(merged); n > 0 && r.start <= merged[n-1].end+1 {
+			merged[n-1].end = max(merged[n-1].end, r.end)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
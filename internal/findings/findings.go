@@ -0,0 +1,160 @@
+// Package findings は、レビュー結果の指摘を固定のカテゴリ体系に正規化します。
+package findings
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Category は指摘のカテゴリです。
+type Category string
+
+const (
+	Security    Category = "security"
+	Correctness Category = "correctness"
+	Performance Category = "performance"
+	Style       Category = "style"
+	Tests       Category = "tests"
+	Docs        Category = "docs"
+)
+
+// Categories はカテゴリ体系の全カテゴリを表示順に返します。
+func Categories() []Category {
+	return []Category{Security, Correctness, Performance, Style, Tests, Docs}
+}
+
+// ParseCategory は文字列を Category に変換します。
+func ParseCategory(s string) (Category, error) {
+	c := Category(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range Categories() {
+		if c == known {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("不明な指摘カテゴリです: '%s' (%v のいずれかを指定してください)", s, Categories())
+}
+
+// Label はカテゴリの表示名を返します。
+func (c Category) Label() string {
+	switch c {
+	case Security:
+		return "セキュリティ"
+	case Correctness:
+		return "正確性"
+	case Performance:
+		return "パフォーマンス"
+	case Style:
+		return "スタイル・保守性"
+	case Tests:
+		return "テスト"
+	case Docs:
+		return "ドキュメント"
+	}
+	return string(c)
+}
+
+// Finding はレビュー結果から抽出した1件の指摘です。
+type Finding struct {
+	File string
+	// Line は指摘の対象の、変更後のファイルにおける行番号です。読み取れない場合は0です。
+	Line     int
+	Category Category
+	Text     string
+	// Index は、レビュー結果の Markdown における「問題点」の行の位置 (0始まり) です。
+	Index int
+}
+
+// rules は指摘文からカテゴリを判別するキーワードです。上から順に評価し、最初に一致したカテゴリを採用します。
+// 「テストが不足しておりバグを見逃す」のような指摘をテストとして扱えるよう、対象の明確なカテゴリを先に評価します。
+var rules = []struct {
+	category Category
+	keywords []string
+}{
+	{Security, []string{"セキュリティ", "脆弱性", "インジェクション", "認証", "認可", "秘匿", "xss", "csrf", "security", "vulnerab"}},
+	{Tests, []string{"テスト", "test"}},
+	{Docs, []string{"ドキュメント", "コメント", "docstring", "readme", "doc comment"}},
+	{Performance, []string{"パフォーマンス", "非効率", "計算量", "メモリ使用", "n+1", "performance"}},
+	{Correctness, []string{"バグ", "エッジケース", "nil", "競合", "リーク", "例外", "エラー処理", "エラーハンドリング", "不整合", "bug", "race"}},
+	{Style, []string{"可読性", "命名", "重複", "複雑", "スタイル", "フォーマット", "設計", "アーキテクチャ", "責務", "readability", "naming"}},
+}
+
+// Classify は指摘文をカテゴリに分類します。
+// 観点を読み取れない指摘は、プロンプトが最優先の観点とする正確性の指摘として扱います。
+func Classify(text string) Category {
+	lower := strings.ToLower(text)
+	for _, r := range rules {
+		for _, kw := range r.keywords {
+			if strings.Contains(lower, kw) {
+				return r.category
+			}
+		}
+	}
+	return Correctness
+}
+
+// filePrefix はプロンプトが指示するファイルごとの見出しです。
+const filePrefix = "#### ファイル名:"
+
+// Extract はレビュー結果の Markdown から指摘を抽出します。
+// プロンプトの出力形式に従い、「問題点」の行を1件の指摘とみなし、直前のファイル見出しと紐付けます。
+// 行番号は、直前の「行番号」の行、または構造化された指摘の "(L12, ...)" から読み取ります。
+func Extract(result string) []Finding {
+	var found []Finding
+	file := ""
+	lineNo := 0
+	for i, line := range strings.Split(result, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, filePrefix) {
+			file = strings.Trim(strings.TrimSpace(strings.TrimPrefix(trimmed, filePrefix)), "[]`")
+			lineNo = 0
+			continue
+		}
+		if _, rest, ok := strings.Cut(trimmed, "行番号"); ok {
+			lineNo = leadingNumber(rest)
+		}
+		if !strings.Contains(trimmed, "問題点") {
+			continue
+		}
+		// "- **問題点**: ..." の見出し部分を除いた本文を指摘とします
+		_, text, _ := strings.Cut(trimmed, "問題点")
+		if _, rest, ok := strings.Cut(text, "(L"); ok && strings.IndexAny(rest, "0123456789") == 0 {
+			lineNo = leadingNumber(rest)
+		}
+		text = strings.TrimLeft(strings.TrimPrefix(text, "の要約"), "*:： ")
+		found = append(found, Finding{File: file, Line: lineNo, Category: Classify(text), Text: strings.TrimSpace(text), Index: i})
+		lineNo = 0
+	}
+	return found
+}
+
+// leadingNumber は、s に最初に現れる数字の並びを整数として返します。数字がない場合は0を返します。
+// "**: 42-45" や "L12" のような行番号の表記から開始行を読み取るために使用します。
+func leadingNumber(s string) int {
+	start := strings.IndexAny(s, "0123456789")
+	if start == -1 {
+		return 0
+	}
+	n := 0
+	for _, r := range s[start:] {
+		if r < '0' || r > '9' {
+			break
+		}
+		n = n*10 + int(r-'0')
+	}
+	return n
+}
+
+// Mute は、行が指摘として抽出されないよう「問題点」の語を置き換えます。
+// 抑制した指摘など、レビュー結果に残しつつ集計の対象外とする行に使用します。
+func Mute(line string) string {
+	return strings.ReplaceAll(line, "問題点", "指摘")
+}
+
+// Count はレビュー結果の指摘をカテゴリごとに数えます。
+func Count(result string) map[Category]int {
+	counts := make(map[Category]int)
+	for _, f := range Extract(result) {
+		counts[f.Category]++
+	}
+	return counts
+}
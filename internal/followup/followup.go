@@ -0,0 +1,58 @@
+// Package followup は、同じフィーチャーブランチを再レビューする際に、前回の指摘の対応状況を確認させるプロンプトを生成します。
+package followup
+
+import (
+	"fmt"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/findings"
+	"git-gemini-reviewer-go/internal/history"
+	"git-gemini-reviewer-go/internal/verdict"
+)
+
+const (
+	// maxFindings はプロンプトに含める前回の指摘の上限です。
+	maxFindings = 10
+	// maxTextRunes は指摘1件あたりの本文の上限 (文字数) です。
+	maxTextRunes = 200
+)
+
+// SectionTitle はAIに出力させるフォローアップのセクションの見出しです。
+const SectionTitle = "## 🔁 前回の指摘へのフォローアップ"
+
+// Prompt は前回のレビューの主な指摘を要約し、対応状況をフォローアップのセクションとして出力するよう指示するプロンプトを返します。
+// 前回のレビューから指摘を抽出できない場合は空文字列を返します。
+func Prompt(previous history.Review) string {
+	found := findings.Extract(previous.Result)
+	if len(found) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## 🔁 前回のレビューでの指摘 (ツールによる自動抽出)\n\n")
+	fmt.Fprintf(&sb, "このブランチは %s にもレビュー済みです (判定: %s)。前回の主な指摘は次のとおりです。\n\n",
+		previous.ReviewedAt.Local().Format("2006-01-02 15:04"), verdict.Verdict(previous.Verdict).Label())
+	for i, f := range found {
+		if i == maxFindings {
+			fmt.Fprintf(&sb, "- (ほか %d 件)\n", len(found)-maxFindings)
+			break
+		}
+		file := f.File
+		if file == "" {
+			file = "全体"
+		}
+		fmt.Fprintf(&sb, "%d. `%s` [%s] %s\n", i+1, file, f.Category.Label(), truncate(f.Text))
+	}
+	fmt.Fprintf(&sb, "\n今回の差分を踏まえて、それぞれの指摘が対応済みかどうかを確認し、通常の出力の末尾に「%s」という見出しのセクションを追加してください。", SectionTitle)
+	sb.WriteString("各指摘について番号とともに「✅ 対応済み」「⚠️ 未対応」「❓ 差分からは判断できない」のいずれかと、その根拠を1行で記載してください。\n\n---\n\n")
+	return sb.String()
+}
+
+func truncate(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	r := []rune(s)
+	if len(r) <= maxTextRunes {
+		return s
+	}
+	return string(r[:maxTextRunes]) + "…"
+}
@@ -0,0 +1,160 @@
+// Package gcsindex は、GCS に公開したレビュー結果の一覧ページ (index.html) と、その元になるマニフェスト (index.json) を管理します。
+// 一覧ページは保存先のプレフィックスごとに作成し、関係者がオブジェクト名を知らなくても過去のレビューを辿れるようにします。
+package gcsindex
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"git-gemini-reviewer-go/internal/htmlreport"
+	"git-gemini-reviewer-go/internal/verdict"
+)
+
+const (
+	// PageName は一覧ページのオブジェクト名です。
+	PageName = "index.html"
+	// ManifestName は一覧ページの元になるマニフェストのオブジェクト名です。
+	ManifestName = "index.json"
+	// DefaultLimit は一覧に残すレビューの既定の件数です。
+	DefaultLimit = 100
+
+	// PlaceholderReviewID はオブジェクトパス中のレビューIDに置き換わる文字列です。
+	PlaceholderReviewID = "{review_id}"
+	// PlaceholderRepo はオブジェクトパス中のリポジトリ名に置き換わる文字列です。
+	PlaceholderRepo = "{repo}"
+)
+
+// Vars はオブジェクトパスのプレースホルダに埋め込む値です。
+type Vars struct {
+	ReviewID string
+	RepoURL  string
+}
+
+// Layout は、展開したレビュー結果のオブジェクトパスと、一覧ページを置くプレフィックスです。
+type Layout struct {
+	Object string
+	// Prefix は一覧ページとマニフェストを置くディレクトリです (末尾の '/' を含みます。バケット直下の場合は空文字列)。
+	Prefix string
+}
+
+// Expand は objectPath のプレースホルダを展開します。
+// 一覧ページは、{review_id} を含む最初のディレクトリ (含まない場合はオブジェクト名) の手前のプレフィックスに置きます。
+// 例: 'reviews/{repo}/{review_id}/result.html' の一覧ページは 'reviews/<リポジトリ名>/index.html' です。
+func Expand(objectPath string, vars Vars) Layout {
+	replacer := strings.NewReplacer(PlaceholderReviewID, vars.ReviewID, PlaceholderRepo, RepoName(vars.RepoURL))
+	segments := strings.Split(objectPath, "/")
+	prefixLen := len(segments) - 1
+	for i, s := range segments {
+		if strings.Contains(s, PlaceholderReviewID) {
+			prefixLen = i
+			break
+		}
+	}
+	var prefix string
+	if prefixLen > 0 {
+		prefix = replacer.Replace(strings.Join(segments[:prefixLen], "/")) + "/"
+	}
+	return Layout{Object: replacer.Replace(objectPath), Prefix: prefix}
+}
+
+// Relative は、レビュー結果のオブジェクトパスを一覧ページのプレフィックスからの相対パスで返します。
+func (l Layout) Relative() string {
+	return strings.TrimPrefix(l.Object, l.Prefix)
+}
+
+// RepoName は、リポジトリURLの末尾の名前 ('.git' を除く) を、オブジェクトパスに使える形で返します。
+func RepoName(repoURL string) string {
+	name := strings.TrimSuffix(strings.TrimRight(repoURL, "/"), ".git")
+	if i := strings.LastIndexAny(name, "/:"); i >= 0 {
+		name = name[i+1:]
+	}
+	if name == "" {
+		return "unknown"
+	}
+	return name
+}
+
+// Entry はマニフェストに記録する1件のレビューです。
+type Entry struct {
+	// Object は一覧ページのプレフィックスからのレビュー結果の相対パスです。
+	Object        string          `json:"object"`
+	ReviewID      string          `json:"review_id"`
+	RepoURL       string          `json:"repo_url"`
+	BaseBranch    string          `json:"base_branch"`
+	FeatureBranch string          `json:"feature_branch"`
+	Verdict       verdict.Verdict `json:"verdict"`
+	GeneratedAt   time.Time       `json:"generated_at"`
+}
+
+// Manifest は一覧ページに掲載するレビューの記録です。
+type Manifest struct {
+	Entries []Entry `json:"entries"`
+}
+
+// Parse はマニフェストの JSON を読み込みます。空の場合は空のマニフェストを返します。
+func Parse(data []byte) (Manifest, error) {
+	var m Manifest
+	if strings.TrimSpace(string(data)) == "" {
+		return m, nil
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Manifest{}, fmt.Errorf("一覧のマニフェストの解析に失敗しました: %w", err)
+	}
+	return m, nil
+}
+
+// Add はレビューを追加し、新しい順に並べて最大 limit 件を残します。
+// 同じオブジェクトへの記録は、上書き保存されたものとして置き換えます。limit が 0 以下の場合は件数を制限しません。
+func (m *Manifest) Add(e Entry, limit int) {
+	entries := []Entry{e}
+	for _, existing := range m.Entries {
+		if existing.Object != e.Object {
+			entries = append(entries, existing)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].GeneratedAt.After(entries[j].GeneratedAt)
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	m.Entries = entries
+}
+
+// JSON はマニフェストを JSON に変換します。
+func (m Manifest) JSON() ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("一覧のマニフェストの変換に失敗しました: %w", err)
+	}
+	return data, nil
+}
+
+// HTML はマニフェストから一覧ページの HTML 文書を生成します。
+func (m Manifest) HTML(opts htmlreport.Options) ([]byte, error) {
+	entries := make([]htmlreport.IndexEntry, 0, len(m.Entries))
+	for _, e := range m.Entries {
+		entries = append(entries, htmlreport.IndexEntry{
+			Href:          href(e.Object),
+			RepoURL:       e.RepoURL,
+			BaseBranch:    e.BaseBranch,
+			FeatureBranch: e.FeatureBranch,
+			Verdict:       e.Verdict.Label(),
+			GeneratedAt:   e.GeneratedAt,
+		})
+	}
+	return htmlreport.RenderIndex(entries, opts)
+}
+
+// href は、相対パスの各要素をエスケープし、一覧ページからのリンクとして使える形にします。
+func href(object string) string {
+	segments := strings.Split(object, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,135 @@
+package gerrit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/pkg/retry"
+	"git-gemini-reviewer-go/internal/verdict"
+)
+
+// CodeReviewLabel は投票に使用する Gerrit のラベル名です。
+const CodeReviewLabel = "Code-Review"
+
+// Change はレビュー対象の Gerrit の変更とパッチセットです。
+type Change struct {
+	Number   int
+	Patchset int
+}
+
+// ParseChange は "12345/3" 形式の指定を Change に変換します。
+func ParseChange(spec string) (Change, error) {
+	num, ps, ok := strings.Cut(strings.TrimSpace(spec), "/")
+	if !ok {
+		return Change{}, fmt.Errorf("Gerrit の変更の指定が不正です: '%s' ('変更番号/パッチセット番号' の形式で指定してください。例: 12345/3)", spec)
+	}
+	number, err := strconv.Atoi(num)
+	if err != nil || number <= 0 {
+		return Change{}, fmt.Errorf("Gerrit の変更番号が不正です: '%s'", num)
+	}
+	patchset, err := strconv.Atoi(ps)
+	if err != nil || patchset <= 0 {
+		return Change{}, fmt.Errorf("Gerrit のパッチセット番号が不正です: '%s'", ps)
+	}
+	return Change{Number: number, Patchset: patchset}, nil
+}
+
+// Ref は変更のパッチセットを指す Gerrit の参照 (refs/changes/xx/yyyy/z) です。
+func (c Change) Ref() string {
+	return fmt.Sprintf("refs/changes/%02d/%d/%d", c.Number%100, c.Number, c.Patchset)
+}
+
+// Branch は、パッチセットを origin のリモート追跡ブランチとして扱うためのブランチ名です。
+func (c Change) Branch() string {
+	return fmt.Sprintf("gerrit/%d/%d", c.Number, c.Patchset)
+}
+
+// RefSpec はパッチセットを Branch() のリモート追跡ブランチとしてフェッチするための refspec です。
+func (c Change) RefSpec() string {
+	return fmt.Sprintf("+%s:refs/remotes/origin/%s", c.Ref(), c.Branch())
+}
+
+// Vote はレビューの判定から Code-Review の投票値を決定します。
+// 判定を読み取れない場合や条件付きの場合は、人のレビューに委ねるため 0 とします。
+func Vote(v verdict.Verdict) int {
+	switch v {
+	case verdict.Blocked:
+		return -1
+	case verdict.Approved:
+		return 1
+	}
+	return 0
+}
+
+// Client は Gerrit REST API のクライアントです。
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	username   string
+	password   string
+}
+
+// NewClient は Client を生成します。password には Gerrit の HTTP パスワード (HTTP credentials) を指定します。
+func NewClient(httpClient *http.Client, baseURL, username, password string) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		username:   username,
+		password:   password,
+	}
+}
+
+// reviewInput は Set Review API のリクエストボディです。
+type reviewInput struct {
+	Message string         `json:"message"`
+	Labels  map[string]int `json:"labels,omitempty"`
+	Tag     string         `json:"tag,omitempty"`
+}
+
+// PostReview はパッチセットにレビューコメントを投稿します。vote が nil の場合は投票しません。
+// 投稿したパッチセットの URL を返します。
+func (c *Client) PostReview(ctx context.Context, change Change, message string, vote *int) (string, error) {
+	input := reviewInput{Message: message, Tag: "autogenerated:git-gemini-reviewer"}
+	if vote != nil {
+		input.Labels = map[string]int{CodeReviewLabel: *vote}
+	}
+	body, err := json.Marshal(input)
+	if err != nil {
+		return "", fmt.Errorf("Gerrit レビューのエンコードに失敗しました: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/a/changes/%s/revisions/%d/review", c.baseURL, url.PathEscape(strconv.Itoa(change.Number)), change.Patchset)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("Gerrit APIリクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.SetBasicAuth(c.username, c.password)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("Gerrit API (Set Review) の呼び出しに失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err := fmt.Errorf("Gerrit API (Set Review) がエラーを返しました (status: %d): %s", resp.StatusCode, strings.TrimSpace(string(detail)))
+		// 認証エラーや存在しない変更など、レート制限以外のクライアントエラーはリトライしても解消しません
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+			return "", retry.Permanent(err)
+		}
+		return "", err
+	}
+	return fmt.Sprintf("%s/c/%d/%d", c.baseURL, change.Number, change.Patchset), nil
+}
@@ -0,0 +1,296 @@
+// Package ghaction は、GitHub Actions のステップとして実行する際の入出力を扱います。
+// イベントのペイロードからレビュー対象のプルリクエストを読み取り、レビュー結果をジョブの概要 ($GITHUB_STEP_SUMMARY)、
+// ワークフローコマンドによる注釈 (::error など)、ステップの出力 ($GITHUB_OUTPUT) として書き出します。
+package ghaction
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/findings"
+	"git-gemini-reviewer-go/internal/inline"
+	"git-gemini-reviewer-go/internal/verdict"
+)
+
+// Actions のランナーが設定する環境変数です。
+const (
+	EnvEventPath   = "GITHUB_EVENT_PATH"
+	EnvStepSummary = "GITHUB_STEP_SUMMARY"
+	EnvOutput      = "GITHUB_OUTPUT"
+	EnvServerURL   = "GITHUB_SERVER_URL"
+)
+
+// defaultServerURL は GITHUB_SERVER_URL が未設定の場合に使用する github.com のURLです。
+const defaultServerURL = "https://github.com"
+
+// Event は、プルリクエストに関するイベント (pull_request、pull_request_target) のペイロードのうち、レビューに使用する部分です。
+type Event struct {
+	PullRequest *PullRequest `json:"pull_request"`
+	Repository  struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// PullRequest はイベントのペイロードに含まれるプルリクエストです。
+type PullRequest struct {
+	Number int `json:"number"`
+	Head   struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+	Labels []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+}
+
+// LabelNames はプルリクエストに付与されたラベルの名前を返します。
+func (pr PullRequest) LabelNames() []string {
+	names := make([]string, 0, len(pr.Labels))
+	for _, l := range pr.Labels {
+		names = append(names, l.Name)
+	}
+	return names
+}
+
+// LoadEvent は、環境変数 GITHUB_EVENT_PATH のイベントのペイロードを読み込みます。
+// プルリクエストに関するイベント以外から実行された場合はエラーを返します。
+func LoadEvent() (Event, error) {
+	path := os.Getenv(EnvEventPath)
+	if path == "" {
+		return Event{}, fmt.Errorf("環境変数 %s が設定されていません。GitHub Actions のステップとして実行してください", EnvEventPath)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Event{}, fmt.Errorf("イベントのペイロードの読み込みに失敗しました: %w", err)
+	}
+	var e Event
+	if err := json.Unmarshal(data, &e); err != nil {
+		return Event{}, fmt.Errorf("イベントのペイロードの解析に失敗しました: %w", err)
+	}
+	if e.PullRequest == nil || e.PullRequest.Number == 0 {
+		return Event{}, fmt.Errorf("イベントにプルリクエストが含まれていません。pull_request または pull_request_target のイベントで実行してください")
+	}
+	return e, nil
+}
+
+// RepoURL は、イベントのリポジトリをクローンするための HTTPS のURLを返します。
+func (e Event) RepoURL() string {
+	server := os.Getenv(EnvServerURL)
+	if server == "" {
+		server = defaultServerURL
+	}
+	return strings.TrimRight(server, "/") + "/" + e.Repository.FullName + ".git"
+}
+
+// PullBranch は、プルリクエストの先頭を origin のリモート追跡ブランチとして扱うためのブランチ名です。
+// フォークからのプルリクエストも、ベースのリポジトリの refs/pull/ 配下から取得できます。
+func PullBranch(number int) string {
+	return fmt.Sprintf("pull/%d/head", number)
+}
+
+// PullRefSpec は、プルリクエストの先頭を PullBranch のリモート追跡ブランチとしてフェッチするための refspec です。
+func PullRefSpec(number int) string {
+	return fmt.Sprintf("+refs/pull/%d/head:refs/remotes/origin/%s", number, PullBranch(number))
+}
+
+// Level はワークフローコマンドによる注釈の種類です。
+type Level string
+
+const (
+	Error   Level = "error"
+	Warning Level = "warning"
+	Notice  Level = "notice"
+)
+
+// Annotation はファイルの行に表示する注釈です。
+type Annotation struct {
+	Level   Level
+	File    string
+	Line    int
+	Title   string
+	Message string
+}
+
+// Command は注釈をワークフローコマンドの1行に変換します。
+func (a Annotation) Command() string {
+	var props []string
+	if a.File != "" {
+		props = append(props, "file="+escapeProperty(a.File))
+	}
+	if a.Line > 0 {
+		props = append(props, fmt.Sprintf("line=%d", a.Line))
+	}
+	if a.Title != "" {
+		props = append(props, "title="+escapeProperty(a.Title))
+	}
+	cmd := "::" + string(a.Level)
+	if len(props) > 0 {
+		cmd += " " + strings.Join(props, ",")
+	}
+	return cmd + "::" + escapeData(a.Message)
+}
+
+// escapeData はワークフローコマンドのメッセージに含められない文字をエスケープします。
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty はワークフローコマンドのプロパティの値に含められない文字をエスケープします。
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// Annotations はレビュー結果の指摘を注釈に変換します。
+// 構造化された指摘がある場合は重大度に応じた種類で行に紐付け、ない場合は Markdown から抽出した指摘をファイル単位の警告とします。
+func Annotations(structured *inline.Review, result string) []Annotation {
+	var out []Annotation
+	if structured != nil {
+		for _, f := range structured.Findings {
+			out = append(out, Annotation{
+				Level:   severityLevel(f.Severity),
+				File:    f.File,
+				Line:    f.Line,
+				Title:   "AIレビュー: " + f.Severity.Label(),
+				Message: f.Message,
+			})
+		}
+		return out
+	}
+	for _, f := range findings.Extract(result) {
+		out = append(out, Annotation{
+			Level:   Warning,
+			File:    f.File,
+			Title:   "AIレビュー: " + f.Category.Label(),
+			Message: f.Text,
+		})
+	}
+	return out
+}
+
+// severityLevel は指摘の重大度を注釈の種類に対応付けます。
+func severityLevel(s inline.Severity) Level {
+	switch s {
+	case inline.Critical:
+		return Error
+	case inline.Major:
+		return Warning
+	}
+	return Notice
+}
+
+// WriteAnnotations は注釈をワークフローコマンドとして w に書き出します。
+func WriteAnnotations(w io.Writer, annotations []Annotation) error {
+	for _, a := range annotations {
+		if _, err := fmt.Fprintln(w, a.Command()); err != nil {
+			return fmt.Errorf("ワークフローコマンドの出力に失敗しました: %w", err)
+		}
+	}
+	return nil
+}
+
+// 判定ごとのリスクスコアの基準値と、指摘1件あたりの加算値です。
+var (
+	verdictRisk = map[verdict.Verdict]int{
+		verdict.Approved:    0,
+		verdict.Conditional: 30,
+		verdict.Blocked:     70,
+		verdict.Unknown:     50,
+	}
+	severityRisk = map[inline.Severity]int{
+		inline.Critical: 15,
+		inline.Major:    5,
+		inline.Minor:    1,
+	}
+	categoryRisk = map[findings.Category]int{
+		findings.Security: 5,
+	}
+)
+
+// defaultCategoryRisk は、セキュリティ以外のカテゴリの指摘1件あたりの加算値です。
+const defaultCategoryRisk = 2
+
+// maxRisk はリスクスコアの上限です。
+const maxRisk = 100
+
+// RiskScore は、判定と指摘の件数から、後続のステップの条件分岐に使う 0〜100 のリスクスコアを算出します。
+// 判定ごとの基準値に、構造化された指摘は重大度に応じて、それ以外はカテゴリに応じて加算します。
+func RiskScore(v verdict.Verdict, structured *inline.Review, result string) int {
+	score := verdictRisk[v]
+	if structured != nil {
+		for _, f := range structured.Findings {
+			score += severityRisk[f.Severity]
+		}
+	} else {
+		for _, f := range findings.Extract(result) {
+			if add, ok := categoryRisk[f.Category]; ok {
+				score += add
+			} else {
+				score += defaultCategoryRisk
+			}
+		}
+	}
+	return min(score, maxRisk)
+}
+
+// AppendSummary は、環境変数 GITHUB_STEP_SUMMARY のファイルにジョブの概要を追記します。
+func AppendSummary(markdown string) error {
+	return appendEnvFile(EnvStepSummary, strings.TrimRight(markdown, "\n")+"\n")
+}
+
+// SetOutputs は、環境変数 GITHUB_OUTPUT のファイルにステップの出力を書き込みます。
+// 値が改行を含んでも解釈が崩れないよう、ランダムな区切り文字を使う複数行の書式で書き込みます。
+func SetOutputs(outputs map[string]string) error {
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		delimiter, err := newDelimiter()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&b, "%s<<%s\n%s\n%s\n", name, delimiter, outputs[name], delimiter)
+	}
+	return appendEnvFile(EnvOutput, b.String())
+}
+
+// newDelimiter は複数行の値の区切り文字を生成します。
+func newDelimiter() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("出力の区切り文字の生成に失敗しました: %w", err)
+	}
+	return "ghadelimiter_" + hex.EncodeToString(buf), nil
+}
+
+// appendEnvFile は、環境変数 name が指すファイルに content を追記します。
+func appendEnvFile(name, content string) error {
+	path := os.Getenv(name)
+	if path == "" {
+		return fmt.Errorf("環境変数 %s が設定されていません", name)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("%s のファイルを開けませんでした: %w", name, err)
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return fmt.Errorf("%s のファイルへの書き込みに失敗しました: %w", name, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("%s のファイルへの書き込みに失敗しました: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package gitclient
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	cryptossh "golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+// expandTilde はクロスプラットフォームなチルダ展開をサポートします。
+func expandTilde(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	currentUser, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("現在のユーザーのホームディレクトリの取得に失敗しました: %w", err)
+	}
+	return filepath.Join(currentUser.HomeDir, path[2:]), nil
+}
+
+// isSSHURL はリポジトリURLがSSH形式かどうかを判定します。
+func isSSHURL(repoURL string) bool {
+	return strings.HasPrefix(repoURL, "git@") || strings.HasPrefix(repoURL, "ssh://")
+}
+
+// isHTTPURL はリポジトリURLが HTTP(S) 形式かどうかを判定します。
+func isHTTPURL(repoURL string) bool {
+	return strings.HasPrefix(repoURL, "https://") || strings.HasPrefix(repoURL, "http://")
+}
+
+// getAuthMethod は go-git が使用する認証方法を返します。
+// Client の設定に基づいて SSH 認証、または HTTPS のトークン認証を構築します。
+func (c *Client) getAuthMethod(repoURL string) (transport.AuthMethod, error) {
+	if isHTTPURL(repoURL) {
+		return c.getHTTPAuthMethod(repoURL), nil
+	}
+	if !isSSHURL(repoURL) {
+		// ローカルパスなど SSH でも HTTP(S) でもないURLの場合は認証なしでアクセスを試みます。
+		return nil, nil
+	}
+
+	// 1. リポジトリURLの解析とユーザー名の決定
+	username := "git"
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		// git@github.com:user/repo.git のようなSSH短縮形の場合、url.Parseは失敗するため "git" ユーザーを使用します。
+		if !strings.HasPrefix(repoURL, "git@") {
+			return nil, fmt.Errorf("リポジトリURLのパースに失敗しました: %w", err)
+		}
+	} else if u.User != nil {
+		username = u.User.Username()
+	}
+
+	if c.authCache != nil {
+		key := strings.Join([]string{username, c.SSHKeyPath, strconv.FormatBool(c.UseSSHAgent), strconv.FormatBool(c.InsecureSkipHostKeyCheck)}, "\x00")
+		return c.authCache.get(key, func() (transport.AuthMethod, error) {
+			return c.getSSHAuthMethod(username)
+		})
+	}
+	return c.getSSHAuthMethod(username)
+}
+
+// getSSHAuthMethod は、鍵ファイルまたは ssh-agent による SSH 認証を構築します。
+func (c *Client) getSSHAuthMethod(username string) (transport.AuthMethod, error) {
+	// 2. ssh-agent の使用が指定されているか、鍵ファイルが指定されていない場合は ssh-agent に委ねます
+	if c.UseSSHAgent || c.SSHKeyPath == "" {
+		return c.getSSHAgentAuthMethod(username)
+	}
+
+	// 3. SSHキーパスの展開とファイルの読み込み
+	sshKeyPath, err := expandTilde(c.SSHKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("SSHキーパスの展開に失敗しました: %w", err)
+	}
+	sshKey, err := os.ReadFile(sshKeyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("SSHキーファイルが見つかりません: %s", sshKeyPath)
+		}
+		return nil, fmt.Errorf("SSHキーファイルの読み込みに失敗しました: %w", err)
+	}
+
+	// 4. パスフレーズで保護された鍵は、指定されたパスフレーズ、ssh-agent、端末での入力の順に解決します
+	passphrase := c.SSHKeyPassphrase
+	var passphraseMissing *cryptossh.PassphraseMissingError
+	if _, err := cryptossh.ParsePrivateKey(sshKey); errors.As(err, &passphraseMissing) && passphrase == "" {
+		if os.Getenv("SSH_AUTH_SOCK") != "" {
+			slog.Info("SSHキーがパスフレーズで保護されているため、ssh-agent による認証を使用します。", "path", sshKeyPath)
+			return c.getSSHAgentAuthMethod(username)
+		}
+		if passphrase, err = promptPassphrase(sshKeyPath); err != nil {
+			return nil, err
+		}
+		// リトライのたびに入力を求めないよう、入力されたパスフレーズを保持します
+		c.SSHKeyPassphrase = passphrase
+	}
+
+	// 5. PublicKeys 認証メソッドの生成
+	auth, err := ssh.NewPublicKeys(username, sshKey, passphrase)
+	if err != nil {
+		if passphrase != "" {
+			return nil, fmt.Errorf("SSH認証キーのロードに失敗しました (パスフレーズが正しくない可能性があります): %w", err)
+		}
+		return nil, fmt.Errorf("SSH認証キーのロードに失敗しました: %w", err)
+	}
+
+	// 6. HostKeyCallback の設定 (nil の場合、go-git は known_hosts による検証を行います)
+	if c.InsecureSkipHostKeyCheck {
+		auth.HostKeyCallback = cryptossh.InsecureIgnoreHostKey()
+	}
+
+	return auth, nil
+}
+
+// promptPassphrase は、標準入力が端末の場合にエコーを無効にしてSSHキーのパスフレーズの入力を求めます。
+// CI など端末から実行されていない場合は、指定方法を案内するエラーを返します。
+func promptPassphrase(sshKeyPath string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("SSHキー '%s' はパスフレーズで保護されています。--ssh-key-passphrase (環境変数 SSH_KEY_PASSPHRASE) で指定するか、ssh-agent に鍵を追加してください", sshKeyPath)
+	}
+	fmt.Fprintf(os.Stderr, "SSHキー '%s' のパスフレーズ: ", sshKeyPath)
+	input, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("パスフレーズの読み込みに失敗しました: %w", err)
+	}
+	return string(input), nil
+}
+
+// getSSHAgentAuthMethod は、SSH_AUTH_SOCK の ssh-agent に読み込まれた鍵で認証する方法を返します。
+func (c *Client) getSSHAgentAuthMethod(username string) (transport.AuthMethod, error) {
+	if os.Getenv("SSH_AUTH_SOCK") == "" {
+		return nil, fmt.Errorf("ssh-agent が起動していません (SSH_AUTH_SOCK が未設定です)。--ssh-key-path でパスフレーズなしの鍵を指定するか、ssh-agent に鍵を追加してください")
+	}
+	auth, err := ssh.NewSSHAgentAuth(username)
+	if err != nil {
+		return nil, fmt.Errorf("ssh-agent への接続に失敗しました: %w", err)
+	}
+	if c.InsecureSkipHostKeyCheck {
+		auth.HostKeyCallback = cryptossh.InsecureIgnoreHostKey()
+	}
+	return auth, nil
+}
+
+// defaultHTTPUsername は、トークンのみが指定された場合に使用するユーザー名です。
+// GitHub や GitLab のアクセストークンは、ユーザー名を問わずパスワードとして検証されます。
+const defaultHTTPUsername = "git"
+
+// getHTTPAuthMethod は HTTPS のリポジトリURLに対する Basic 認証を返します。
+// リポジトリのホストが HTTPTokenHosts に含まれる場合は HTTPToken を、URL にユーザー情報 (user:token@) が含まれる場合はそれを使用します。
+// どちらもない場合は、公開リポジトリとして認証なしでアクセスします。
+func (c *Client) getHTTPAuthMethod(repoURL string) transport.AuthMethod {
+	u, err := url.Parse(repoURL)
+	if c.HTTPToken != "" {
+		if err == nil && c.isTokenHost(u) {
+			username := c.HTTPUsername
+			if username == "" {
+				username = defaultHTTPUsername
+			}
+			if u.Scheme == "http" {
+				slog.Warn("暗号化されていない HTTP でトークンを送信します。HTTPS のURLを使用してください。")
+			}
+			return &http.BasicAuth{Username: username, Password: c.HTTPToken}
+		}
+		// 依頼されたURLのホストにトークンが漏れないよう、設定されたホスト以外には送信しません
+		slog.Warn("リポジトリのホストが --git-token-host に含まれないため、アクセストークンを送信しません。", "hosts", c.HTTPTokenHosts)
+	}
+
+	if err != nil || u.User == nil {
+		return nil
+	}
+	if password, ok := u.User.Password(); ok {
+		return &http.BasicAuth{Username: u.User.Username(), Password: password}
+	}
+	return nil
+}
+
+// isTokenHost は、URL のホストが HTTPTokenHosts に含まれるかどうかを判定します。
+// ポートを含まない設定は、ホスト名が一致すればポートを問わず一致とみなします。
+func (c *Client) isTokenHost(u *url.URL) bool {
+	for _, host := range c.HTTPTokenHosts {
+		if strings.EqualFold(host, u.Host) || strings.EqualFold(host, u.Hostname()) {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthCache は、SSH の認証方法 (鍵の読み込みとパスフレーズの入力、ssh-agent への接続) を、
+// 同じプロセスでの複数のレビューをまたいで再利用するキャッシュです。複数のゴルーチンから使用できます。
+type AuthCache struct {
+	mu      sync.Mutex
+	methods map[string]transport.AuthMethod
+}
+
+// NewAuthCache は空の AuthCache を生成します。
+func NewAuthCache() *AuthCache {
+	return &AuthCache{methods: make(map[string]transport.AuthMethod)}
+}
+
+// get は key の認証方法を返します。キャッシュにない場合は build で構築して保持します。
+func (a *AuthCache) get(key string, build func() (transport.AuthMethod, error)) (transport.AuthMethod, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if auth, ok := a.methods[key]; ok {
+		slog.Debug("キャッシュ済みの SSH 認証を再利用します。")
+		return auth, nil
+	}
+	auth, err := build()
+	if err != nil {
+		return nil, err
+	}
+	a.methods[key] = auth
+	return auth, nil
+}
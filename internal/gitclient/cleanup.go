@@ -0,0 +1,108 @@
+package gitclient
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// CleanupStrategy はレビュー後のローカルリポジトリの後処理の方法です。
+type CleanupStrategy string
+
+const (
+	// CleanupDelete はローカルリポジトリのディレクトリを削除します。実行ごとにクローンし直します。
+	CleanupDelete CleanupStrategy = "delete"
+	// CleanupReset はワークツリーをベースブランチに戻し、未追跡のファイルを削除してクローンを残します。
+	// 次回の実行ではクローンを再利用し、フェッチのみで最新化します。
+	CleanupReset CleanupStrategy = "reset"
+	// CleanupNone はローカルリポジトリに何もせず、レビューした時点のワークツリーを残します。
+	// 差分の調査などで、レビュー後にクローンを確認する場合に使用します。
+	CleanupNone CleanupStrategy = "none"
+)
+
+// ParseCleanupStrategy は文字列を CleanupStrategy に変換します。空文字列は CleanupDelete として扱います。
+func ParseCleanupStrategy(s string) (CleanupStrategy, error) {
+	switch CleanupStrategy(s) {
+	case "", CleanupDelete:
+		return CleanupDelete, nil
+	case CleanupReset:
+		return CleanupReset, nil
+	case CleanupNone:
+		return CleanupNone, nil
+	default:
+		return "", fmt.Errorf("不明なクリーンアップ方法です: '%s' ('none', 'reset', 'delete' のいずれかを指定してください)", s)
+	}
+}
+
+// WithCleanupStrategy は Cleanup でのローカルリポジトリの後処理の方法を設定します。
+func WithCleanupStrategy(strategy CleanupStrategy) Option {
+	return func(c *Client) {
+		c.CleanupStrategy = strategy
+	}
+}
+
+// Cleanup は処理後のローカルリポジトリを CleanupStrategy に従って後処理します。
+// CleanupReset でワークツリーを戻せなかった場合は、次回に壊れたクローンを使わないようディレクトリを削除します。
+func (c *Client) Cleanup(ctx context.Context) error {
+	if c.InMemory {
+		slog.Info("クリーンアップ: メモリ上のリポジトリを破棄します。ディスクに後処理するファイルはありません。")
+		c.repo = nil
+		return nil
+	}
+	if c.CleanupStrategy == CleanupNone {
+		slog.Info("クリーンアップ: ローカルリポジトリをそのまま残します。", "path", c.LocalPath)
+		return nil
+	}
+	if c.CleanupStrategy == CleanupReset {
+		err := c.resetWorktree()
+		if err == nil {
+			slog.Info("クリーンアップ: ワークツリーをベースブランチに戻しました。クローンは次回の実行で再利用します。", "path", c.LocalPath)
+			return nil
+		}
+		slog.Warn("ワークツリーのリセットに失敗したため、ローカルリポジトリディレクトリを削除します。", "path", c.LocalPath, "error", err)
+	}
+	return c.removeLocal()
+}
+
+// resetWorktree は、ワークツリーをベースブランチのリモート追跡ブランチ (未設定時は HEAD) に戻し、未追跡のファイルを削除します。
+func (c *Client) resetWorktree() error {
+	repo, err := c.getRepository()
+	if err != nil {
+		return err
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("ワークツリーの取得に失敗しました: %w", err)
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return fmt.Errorf("HEAD の解決に失敗しました: %w", err)
+	}
+	target := head.Hash()
+	if c.BaseBranch != "" {
+		if commit, err := resolveRemoteCommit(repo, c.BaseBranch); err == nil {
+			target = commit.Hash
+		}
+	}
+
+	if err := wt.Reset(&git.ResetOptions{Commit: target, Mode: git.HardReset}); err != nil {
+		return fmt.Errorf("ワークツリーのリセットに失敗しました: %w", err)
+	}
+	if err := wt.Clean(&git.CleanOptions{Dir: true}); err != nil {
+		return fmt.Errorf("未追跡ファイルの削除に失敗しました: %w", err)
+	}
+	return nil
+}
+
+// removeLocal はローカルリポジトリディレクトリを完全に削除します。
+func (c *Client) removeLocal() error {
+	slog.Info("クリーンアップ: ローカルリポジトリディレクトリを削除します。", "path", c.LocalPath)
+	if err := os.RemoveAll(c.LocalPath); err != nil {
+		return fmt.Errorf("ローカルリポジトリディレクトリ '%s' の削除に失敗しました: %w", c.LocalPath, err)
+	}
+	c.repo = nil
+	return nil
+}
@@ -0,0 +1,412 @@
+package gitclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/codecommit"
+	"git-gemini-reviewer-go/internal/pkg/retry"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/shouni/gemini-reviewer-core/pkg/adapters"
+)
+
+// Client は adapters.GitService インターフェースを実装する go-git ベースのクライアントです。
+type Client struct {
+	LocalPath                string
+	SSHKeyPath               string
+	BaseBranch               string
+	InsecureSkipHostKeyCheck bool
+	// SSHKeyPassphrase はパスフレーズで保護された SSHKeyPath の鍵のパスフレーズです。
+	SSHKeyPassphrase string
+	// UseSSHAgent が true の場合、SSHKeyPath を使わず ssh-agent の鍵で認証します。
+	UseSSHAgent bool
+	// HTTPUsername と HTTPToken は HTTPS のリポジトリURLに対する Basic 認証の認証情報です。
+	// HTTPToken が空の場合、HTTPS のリポジトリには URL のユーザー情報を使うか、認証なしでアクセスします。
+	HTTPUsername string
+	HTTPToken    string
+	// HTTPTokenHosts は HTTPToken を送信するホスト (例: github.com、git.example.com:8443) です。
+	// 空の場合は、どのホストにも HTTPToken を送信しません。
+	HTTPTokenHosts []string
+	// Branches はフェッチするブランチです。空の場合はリモートのすべてのブランチをフェッチします。
+	// 指定した場合はクローンもベースブランチのみに限定し、ブランチの多いリポジトリでの転送量を抑えます。
+	Branches []string
+	// ExtraRefSpecs は、ブランチに加えてフェッチする refspec です (例: Gerrit の refs/changes/...)。
+	ExtraRefSpecs []string
+	// FetchTags が true の場合、Fetch ですべてのタグを取得します。タグをリビジョンとして指定する場合に使用します。
+	FetchTags bool
+	// CleanupStrategy は Cleanup でのローカルリポジトリの後処理の方法です。未設定の場合は CleanupDelete です。
+	CleanupStrategy CleanupStrategy
+	// SubmoduleMode は差分に含まれるサブモジュールの参照先の変更の扱いです。未設定の場合は SubmodulePointer です。
+	SubmoduleMode SubmoduleMode
+	// InMemory が true の場合、リポジトリを LocalPath ではなくメモリ上にクローンし、ワークツリーを作成しません。
+	// ディスクに何も書き込まないため、Cleanup は何もしません。
+	InMemory bool
+	// Depth が正の場合、クローンとフェッチを各ブランチの先頭から Depth 個のコミットに限定します (浅いクローン)。
+	// マージベースが取得した履歴に含まれない場合は、履歴をすべて含むクローンに置き換えます (deepen を参照)。
+	Depth     int
+	authCache *AuthCache
+	auth      transport.AuthMethod
+	// remoteURL は CloneOrUpdate で使用したリポジトリのURLです。浅いクローンを置き換える際に使用します。
+	remoteURL string
+	repo      *git.Repository
+	// submodules は、サブモジュールのURLごとのメモリ上のクローンです。
+	submodules map[string]*git.Repository
+}
+
+// Client が adapters.GitService を満たすことをコンパイル時に保証します。
+var _ adapters.GitService = (*Client)(nil)
+
+// Option は Client の初期化オプションを設定するための関数です。
+type Option func(*Client)
+
+// WithInsecureSkipHostKeyCheck はSSHホストキーチェックをスキップするオプションを設定します。
+func WithInsecureSkipHostKeyCheck(skip bool) Option {
+	return func(c *Client) {
+		c.InsecureSkipHostKeyCheck = skip
+	}
+}
+
+// WithHTTPToken は、HTTPS のリポジトリURLに対するトークン認証を設定します。username が空の場合は既定のユーザー名を使用します。
+func WithHTTPToken(username, token string) Option {
+	return func(c *Client) {
+		c.HTTPUsername = username
+		c.HTTPToken = token
+	}
+}
+
+// WithHTTPTokenHosts は、WithHTTPToken のトークンを送信するホストを設定します。
+func WithHTTPTokenHosts(hosts ...string) Option {
+	return func(c *Client) {
+		c.HTTPTokenHosts = hosts
+	}
+}
+
+// WithSSHKeyPassphrase は、パスフレーズで保護された SSH キーのパスフレーズを設定します。
+func WithSSHKeyPassphrase(passphrase string) Option {
+	return func(c *Client) {
+		c.SSHKeyPassphrase = passphrase
+	}
+}
+
+// WithSSHAgent は、SSH の認証に ssh-agent を使用するかどうかを設定します。
+func WithSSHAgent(use bool) Option {
+	return func(c *Client) {
+		c.UseSSHAgent = use
+	}
+}
+
+// WithBaseBranch はクローン時にチェックアウトするベースブランチを設定します。
+func WithBaseBranch(branch string) Option {
+	return func(c *Client) {
+		c.BaseBranch = branch
+	}
+}
+
+// WithFetchBranches は、Fetch で取得するブランチを限定します。空の文字列は無視します。
+func WithFetchBranches(branches ...string) Option {
+	return func(c *Client) {
+		for _, b := range branches {
+			if b != "" && !slices.Contains(c.Branches, b) {
+				c.Branches = append(c.Branches, b)
+			}
+		}
+	}
+}
+
+// WithExtraRefSpecs は、ブランチ以外の参照をリモート追跡ブランチとしてフェッチする refspec を追加します。
+func WithExtraRefSpecs(specs ...string) Option {
+	return func(c *Client) {
+		c.ExtraRefSpecs = append(c.ExtraRefSpecs, specs...)
+	}
+}
+
+// WithFetchTags は、Fetch ですべてのタグを取得するかどうかを設定します。
+func WithFetchTags(fetch bool) Option {
+	return func(c *Client) {
+		c.FetchTags = fetch
+	}
+}
+
+// WithDepth は、クローンとフェッチで取得する履歴の深さを設定します。0 の場合はすべての履歴を取得します。
+func WithDepth(depth int) Option {
+	return func(c *Client) {
+		c.Depth = depth
+	}
+}
+
+// WithInMemory は、リポジトリをメモリ上にクローンするかどうかを設定します。
+func WithInMemory(inMemory bool) Option {
+	return func(c *Client) {
+		c.InMemory = inMemory
+	}
+}
+
+// WithAuthCache は、SSH の認証方法を複数の Client で共有するキャッシュを設定します。
+func WithAuthCache(cache *AuthCache) Option {
+	return func(c *Client) {
+		c.authCache = cache
+	}
+}
+
+// New は Client を初期化します。
+func New(localPath string, sshKeyPath string, opts ...Option) *Client {
+	c := &Client{
+		LocalPath:  localPath,
+		SSHKeyPath: sshKeyPath,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// getRepository は、内部で保持しているリポジトリインスタンスを取得するヘルパー関数です。
+func (c *Client) getRepository() (*git.Repository, error) {
+	if c.repo == nil {
+		if c.InMemory {
+			return nil, fmt.Errorf("メモリ上のリポジトリがクローンされていません")
+		}
+		repo, err := git.PlainOpen(c.LocalPath)
+		if err != nil {
+			return nil, fmt.Errorf("内部リポジトリのオープンに失敗: %w", err)
+		}
+		c.repo = repo
+	}
+	return c.repo, nil
+}
+
+// CloneOrUpdate はリポジトリをクローンするか、既に存在する場合はそれを再利用します。
+// 既存ディレクトリが壊れている場合は、一時ディレクトリへクローンした後にアトミックに置き換えます。
+func (c *Client) CloneOrUpdate(ctx context.Context, repositoryURL string) error {
+	var auth transport.AuthMethod
+	var err error
+	if codecommit.IsURL(repositoryURL) {
+		// CodeCommit は GRC 形式のURLを HTTPS に変換し、署名付きの認証情報でアクセスします。
+		repositoryURL, auth, err = codeCommitRemote(repositoryURL)
+	} else {
+		auth, err = c.getAuthMethod(repositoryURL)
+	}
+	if err != nil {
+		// 鍵ファイルの不備はリトライしても解消しません
+		return retry.Permanent(fmt.Errorf("go-git用の認証情報取得に失敗しました: %w", err))
+	}
+	c.auth = auth
+	c.remoteURL = repositoryURL
+
+	if c.InMemory {
+		return c.cloneInMemory(ctx, repositoryURL)
+	}
+
+	// 前回の実行が途中で中断された場合に残る一時ディレクトリを掃除します。
+	removeStaleSwapDirs(c.LocalPath)
+
+	repo, err := openVerified(c.LocalPath)
+	if err == nil {
+		slog.Info("既存リポジトリをオープンしました。更新は後続の Fetch に委ねます。", "path", c.LocalPath)
+		c.repo = repo
+		return nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		slog.Info("リポジトリが存在しないため、クローンします。", "url", repositoryURL, "path", c.LocalPath, "branch", c.BaseBranch)
+	} else {
+		slog.Warn("既存のローカルリポジトリが利用できないため、再クローンします。", "path", c.LocalPath, "error", err)
+	}
+
+	repo, err = c.cloneAtomically(ctx, repositoryURL)
+	if err != nil {
+		return classifyRemoteError(fmt.Errorf("リポジトリのクローンに失敗しました (URL: %s): %w", repositoryURL, err))
+	}
+
+	c.repo = repo
+	return nil
+}
+
+// Fetch はリモートから最新の変更を取得します。
+func (c *Client) Fetch(ctx context.Context) error {
+	repo, err := c.getRepository()
+	if err != nil {
+		return err
+	}
+
+	slog.Info("リモートから最新の変更をフェッチしています...", "path", c.LocalPath, "branches", c.Branches)
+	if c.auth == nil {
+		slog.Warn("認証情報が設定されていません。プライベートリポジトリの場合、Fetchは失敗します。")
+	}
+
+	refSpecs := []config.RefSpec{"+refs/heads/*:refs/remotes/origin/*"}
+	if len(c.Branches) > 0 {
+		refSpecs = refSpecs[:0]
+		for _, b := range c.Branches {
+			refSpecs = append(refSpecs, config.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", b, b)))
+		}
+	}
+	for _, spec := range c.ExtraRefSpecs {
+		refSpecs = append(refSpecs, config.RefSpec(spec))
+	}
+
+	tags := git.TagFollowing
+	if c.FetchTags {
+		tags = git.AllTags
+	}
+
+	err = repo.FetchContext(ctx, &git.FetchOptions{
+		Auth:     c.auth,
+		RefSpecs: refSpecs,
+		Depth:    c.Depth,
+		Tags:     tags,
+		Progress: io.Discard,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return classifyRemoteError(fmt.Errorf("リモートからのフェッチに失敗しました: %w", err))
+	}
+
+	return nil
+}
+
+// GetCodeDiff は指定された2つのブランチ間の純粋な差分 (3-dot diff) を、go-gitのみで取得します。
+// ブランチの代わりに、コミットの SHA、タグ、'main~3' のようなリビジョンも指定できます (resolveCommit を参照)。
+func (c *Client) GetCodeDiff(ctx context.Context, baseBranch, featureBranch string) (string, error) {
+	repo, err := c.getRepository()
+	if err != nil {
+		return "", err
+	}
+
+	slog.Info("go-gitを使用して差分を計算しています。", "path", c.LocalPath, "base_branch", baseBranch, "feature_branch", featureBranch)
+
+	mergeBase, featureCommit, err := c.resolveMergeBase(repo, baseBranch, featureBranch)
+	if err != nil && isShallow(repo) {
+		// 浅いクローンでは、取得した履歴にマージベースが含まれないことがある
+		slog.Warn("浅いクローンの履歴ではマージベースを求められませんでした。", "depth", c.Depth, "error", err)
+		if repo, err = c.deepen(ctx); err != nil {
+			return "", err
+		}
+		mergeBase, featureCommit, err = c.resolveMergeBase(repo, baseBranch, featureBranch)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	baseTree, err := mergeBase.Tree()
+	if err != nil {
+		return "", fmt.Errorf("マージベースのツリー取得に失敗しました: %w", err)
+	}
+	featureTree, err := featureCommit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("フィーチャーブランチのツリー取得に失敗しました: %w", err)
+	}
+
+	changes, err := baseTree.DiffContext(ctx, featureTree)
+	if err != nil {
+		return "", fmt.Errorf("ツリーの差分取得に失敗しました: %w", err)
+	}
+	patch, err := changes.PatchContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("パッチの生成に失敗しました: %w", err)
+	}
+
+	// go-git のパッチはサブモジュールの参照先の変更を含まないため、git diff と同じ形式で補う
+	diff := patch.String()
+	for _, sc := range c.submoduleChanges(ctx, baseTree, featureTree, changes) {
+		diff += c.submodulePatch(ctx, sc)
+	}
+	return diff, nil
+}
+
+// CheckRemoteBranchExists は指定されたブランチがリモート 'origin' に存在するか確認します。
+func (c *Client) CheckRemoteBranchExists(ctx context.Context, branch string) (bool, error) {
+	repo, err := c.getRepository()
+	if err != nil {
+		return false, err
+	}
+	if branch == "" {
+		return false, fmt.Errorf("リモートブランチの存在確認に失敗しました: ブランチ名が空です")
+	}
+
+	_, err = repo.Reference(plumbing.NewRemoteReferenceName("origin", branch), false)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("リモートブランチ '%s' の確認に失敗しました: %w", branch, err)
+	}
+	return true, nil
+}
+
+// RemoteBranches は、リモート 'origin' のリモート追跡ブランチの名前を名前順に返します。'HEAD' は含めません。
+func (c *Client) RemoteBranches(ctx context.Context) ([]string, error) {
+	repo, err := c.getRepository()
+	if err != nil {
+		return nil, err
+	}
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("リモートブランチの一覧の取得に失敗しました: %w", err)
+	}
+	var branches []string
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		name, ok := strings.CutPrefix(ref.Name().String(), "refs/remotes/origin/")
+		if ok && name != "HEAD" {
+			branches = append(branches, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("リモートブランチの一覧の取得に失敗しました: %w", err)
+	}
+	slices.Sort(branches)
+	return branches, nil
+}
+
+// resolveRemoteCommit は origin のリモート追跡ブランチが指すコミットを返します。
+func resolveRemoteCommit(repo *git.Repository, branch string) (*object.Commit, error) {
+	ref, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", branch), false)
+	if err != nil {
+		return nil, fmt.Errorf("参照の解決に失敗しました: %w", err)
+	}
+	commit, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("コミット '%s' の取得に失敗しました: %w", ref.Hash(), err)
+	}
+	return commit, nil
+}
+
+// resolveCommit は、ブランチ名またはリビジョンが指すコミットを返します。
+// origin のリモート追跡ブランチを優先し、見つからない場合はコミットの SHA、タグ、'main~3' のような相対指定を
+// リビジョンとして解決します。ローカルのブランチはフェッチで更新されないため、'main~3' は 'origin/main~3' として解決します。
+// 'HEAD' はベースブランチのリモート追跡ブランチの最新のコミットとして扱います。
+func (c *Client) resolveCommit(repo *git.Repository, rev string) (*object.Commit, error) {
+	if commit, err := resolveRemoteCommit(repo, rev); err == nil {
+		return commit, nil
+	}
+
+	candidates := []string{"origin/" + rev, rev}
+	if rest, ok := strings.CutPrefix(rev, "HEAD"); ok && c.BaseBranch != "" && (rest == "" || strings.ContainsAny(rest[:1], "~^")) {
+		candidates = []string{"origin/" + c.BaseBranch + rest}
+	}
+
+	var lastErr error
+	for _, candidate := range candidates {
+		hash, err := repo.ResolveRevision(plumbing.Revision(candidate))
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		commit, err := repo.CommitObject(*hash)
+		if err != nil {
+			return nil, fmt.Errorf("コミット '%s' の取得に失敗しました: %w", hash, err)
+		}
+		return commit, nil
+	}
+	return nil, fmt.Errorf("リビジョン '%s' を解決できません: %w", rev, lastErr)
+}
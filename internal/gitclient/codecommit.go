@@ -0,0 +1,29 @@
+package gitclient
+
+import (
+	"fmt"
+	"time"
+
+	"git-gemini-reviewer-go/internal/codecommit"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// codeCommitRemote は CodeCommit のリポジトリURLを go-git で扱える HTTPS URL に変換し、
+// git-remote-codecommit (GRC) と同じ SigV4 署名による認証情報を生成します。
+func codeCommitRemote(repoURL string) (string, transport.AuthMethod, error) {
+	remote, err := codecommit.ParseURL(repoURL, codecommit.RegionFromEnv())
+	if err != nil {
+		return "", nil, err
+	}
+	creds, err := codecommit.CredentialsFromEnv()
+	if err != nil {
+		return "", nil, err
+	}
+	username, password := codecommit.GitCredentials(creds, remote.Region, remote.Repository, time.Now())
+	if username == "" {
+		return "", nil, fmt.Errorf("CodeCommit の認証情報の生成に失敗しました")
+	}
+	return remote.CloneURL(), &http.BasicAuth{Username: username, Password: password}, nil
+}
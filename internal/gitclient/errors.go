@@ -0,0 +1,32 @@
+package gitclient
+
+import (
+	"errors"
+
+	"git-gemini-reviewer-go/internal/pkg/retry"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// nonRetryableTransportErrors は、リトライしても解消しないリモート操作のエラーです。
+var nonRetryableTransportErrors = []error{
+	transport.ErrAuthenticationRequired,
+	transport.ErrAuthorizationFailed,
+	transport.ErrRepositoryNotFound,
+	transport.ErrEmptyRemoteRepository,
+	transport.ErrInvalidAuthMethod,
+	// フェッチするブランチがリモートに存在しない
+	git.NoMatchingRefSpecError{},
+}
+
+// classifyRemoteError は、認証エラーやリポジトリが存在しないエラーを retry.Permanent としてマークします。
+// ネットワークの一時的な障害などそれ以外のエラーは、呼び出し元のリトライ対象のまま返します。
+func classifyRemoteError(err error) error {
+	for _, target := range nonRetryableTransportErrors {
+		if errors.Is(err, target) {
+			return retry.Permanent(err)
+		}
+	}
+	return err
+}
@@ -0,0 +1,34 @@
+package gitclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// ReadFile は、リビジョン rev のコミットに含まれる、リポジトリのルートからの相対パス path のファイルの内容を返します。
+// ファイルが存在しない場合は nil を返します。作業ツリーのチェックアウトの状態には依存しません。
+func (c *Client) ReadFile(ctx context.Context, rev, path string) ([]byte, error) {
+	repo, err := c.getRepository()
+	if err != nil {
+		return nil, err
+	}
+	commit, err := c.resolveCommit(repo, rev)
+	if err != nil {
+		return nil, err
+	}
+	f, err := commit.File(path)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ファイル '%s' の取得に失敗しました: %w", path, err)
+	}
+	content, err := readBlob(&f.Blob)
+	if err != nil {
+		return nil, fmt.Errorf("ファイル '%s' の読み込みに失敗しました: %w", path, err)
+	}
+	return content, nil
+}
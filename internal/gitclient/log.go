@@ -0,0 +1,125 @@
+package gitclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// maxLogCommits は CommitMessages が辿るコミット数の上限です。
+// 長期間マージされていないブランチでも、ログの走査に時間をかけすぎないようにします。
+const maxLogCommits = 200
+
+// Commit はフィーチャーブランチのコミットログの1件のコミットです。
+type Commit struct {
+	Hash    string
+	Author  string
+	Date    time.Time
+	Message string
+}
+
+// CommitMessages は、ベースブランチから分岐した後にフィーチャーブランチへ積まれたコミットのメッセージを新しい順に返します。
+func (c *Client) CommitMessages(ctx context.Context, baseBranch, featureBranch string) ([]string, error) {
+	commits, err := c.Commits(ctx, baseBranch, featureBranch)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]string, len(commits))
+	for i, commit := range commits {
+		messages[i] = commit.Message
+	}
+	return messages, nil
+}
+
+// Commits は、ベースブランチから分岐した後にフィーチャーブランチへ積まれたコミットを新しい順に返します。
+// 辿るコミット数は maxLogCommits までです。
+func (c *Client) Commits(ctx context.Context, baseBranch, featureBranch string) ([]Commit, error) {
+	repo, err := c.getRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	baseCommit, err := c.resolveCommit(repo, baseBranch)
+	if err != nil {
+		return nil, fmt.Errorf("ベースブランチ '%s' の解決に失敗しました: %w", baseBranch, err)
+	}
+	featureCommit, err := c.resolveCommit(repo, featureBranch)
+	if err != nil {
+		return nil, fmt.Errorf("フィーチャーブランチ '%s' の解決に失敗しました: %w", featureBranch, err)
+	}
+	mergeBaseCommits, err := baseCommit.MergeBase(featureCommit)
+	if err != nil {
+		return nil, fmt.Errorf("マージベースの検索に失敗しました: %w", err)
+	}
+	stopAt := make(map[string]bool, len(mergeBaseCommits))
+	for _, mb := range mergeBaseCommits {
+		stopAt[mb.Hash.String()] = true
+	}
+
+	iter, err := repo.Log(&git.LogOptions{From: featureCommit.Hash})
+	if err != nil {
+		return nil, fmt.Errorf("コミットログの取得に失敗しました: %w", err)
+	}
+	defer iter.Close()
+
+	var commits []Commit
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if stopAt[commit.Hash.String()] || len(commits) >= maxLogCommits {
+			return storer.ErrStop
+		}
+		commits = append(commits, Commit{
+			Hash:    commit.Hash.String(),
+			Author:  commit.Author.Name,
+			Date:    commit.Author.When,
+			Message: commit.Message,
+		})
+		return nil
+	})
+	if err != nil && !errors.Is(err, storer.ErrStop) {
+		return nil, fmt.Errorf("コミットログの走査に失敗しました: %w", err)
+	}
+	return commits, nil
+}
+
+// ResolveCommit は、ブランチ名またはリビジョンが指すコミットの SHA を返します。
+// レビュー履歴に、レビューした差分の両端のコミットを記録するために使用します。
+func (c *Client) ResolveCommit(ctx context.Context, rev string) (string, error) {
+	repo, err := c.getRepository()
+	if err != nil {
+		return "", err
+	}
+	commit, err := c.resolveCommit(repo, rev)
+	if err != nil {
+		return "", err
+	}
+	return commit.Hash.String(), nil
+}
+
+// IsAncestor は、リビジョン ancestor が rev の祖先 (または同じコミット) かを返します。
+// 前回レビューしたコミット以降の差分を求める際に、強制プッシュで履歴が書き換えられていないかを確認するために使用します。
+func (c *Client) IsAncestor(ctx context.Context, ancestor, rev string) (bool, error) {
+	repo, err := c.getRepository()
+	if err != nil {
+		return false, err
+	}
+	ancestorCommit, err := c.resolveCommit(repo, ancestor)
+	if err != nil {
+		return false, err
+	}
+	commit, err := c.resolveCommit(repo, rev)
+	if err != nil {
+		return false, err
+	}
+	if ancestorCommit.Hash == commit.Hash {
+		return true, nil
+	}
+	return ancestorCommit.IsAncestor(commit)
+}
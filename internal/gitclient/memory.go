@@ -0,0 +1,22 @@
+package gitclient
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// cloneInMemory は、ワークツリーを作成せずにリポジトリをメモリ上にクローンします。
+// ディスクの小さい CI のコンテナで、LocalPath にファイルを展開せずに差分を求める場合に使用します。
+func (c *Client) cloneInMemory(ctx context.Context, repositoryURL string) error {
+	slog.Info("リポジトリをメモリ上にクローンします。ワークツリーは作成しません。", "url", repositoryURL, "branch", c.BaseBranch, "depth", c.Depth)
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, c.cloneOptions(repositoryURL))
+	if err != nil {
+		return classifyRemoteError(fmt.Errorf("リポジトリのメモリ上へのクローンに失敗しました (URL: %s): %w", repositoryURL, err))
+	}
+	c.repo = repo
+	return nil
+}
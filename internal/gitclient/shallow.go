@@ -0,0 +1,60 @@
+package gitclient
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// resolveMergeBase は、2つのブランチ (またはリビジョン) のマージベースとフィーチャー側のコミットを返します。
+func (c *Client) resolveMergeBase(repo *git.Repository, baseBranch, featureBranch string) (mergeBase, feature *object.Commit, err error) {
+	baseCommit, err := c.resolveCommit(repo, baseBranch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ベースブランチ '%s' の解決に失敗しました: %w", baseBranch, err)
+	}
+	featureCommit, err := c.resolveCommit(repo, featureBranch)
+	if err != nil {
+		return nil, nil, fmt.Errorf("フィーチャーブランチ '%s' の解決に失敗しました: %w", featureBranch, err)
+	}
+
+	mergeBaseCommits, err := baseCommit.MergeBase(featureCommit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("マージベースの検索に失敗しました: %w", err)
+	}
+	if len(mergeBaseCommits) == 0 {
+		return nil, nil, fmt.Errorf("ブランチ '%s' と '%s' の間に共通の祖先が見つかりませんでした。3-dot diffは計算できません。", baseBranch, featureBranch)
+	}
+	return mergeBaseCommits[0], featureCommit, nil
+}
+
+// isShallow は、リポジトリが履歴の一部のみを取得した浅いクローンかを返します。
+func isShallow(repo *git.Repository) bool {
+	shallow, err := repo.Storer.Shallow()
+	return err == nil && len(shallow) > 0
+}
+
+// deepen は、浅いクローンを履歴をすべて含むクローンに置き換え、ブランチを再度フェッチします。
+// マージベースに届くまでに必要な深さは事前に分からないため、段階的に深くせずにクローンし直します。
+// 以降のフェッチも履歴をすべて取得します。
+func (c *Client) deepen(ctx context.Context) (*git.Repository, error) {
+	slog.Info("履歴をすべて含むクローンに置き換えます。", "path", c.LocalPath)
+	c.Depth = 0
+	if c.InMemory {
+		if err := c.cloneInMemory(ctx, c.remoteURL); err != nil {
+			return nil, err
+		}
+	} else {
+		repo, err := c.cloneAtomically(ctx, c.remoteURL)
+		if err != nil {
+			return nil, classifyRemoteError(fmt.Errorf("履歴をすべて含むクローンに失敗しました (URL: %s): %w", c.remoteURL, err))
+		}
+		c.repo = repo
+	}
+	if err := c.Fetch(ctx); err != nil {
+		return nil, err
+	}
+	return c.repo, nil
+}
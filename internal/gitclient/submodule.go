@@ -0,0 +1,295 @@
+package gitclient
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+// SubmoduleMode は、差分に含まれるサブモジュールの参照先の変更の扱いです。
+type SubmoduleMode string
+
+const (
+	// SubmodulePointer は、参照先のコミットの変更 ('Subproject commit ...') のみを差分に含めます。
+	SubmodulePointer SubmoduleMode = "pointer"
+	// SubmoduleLog は、サブモジュールをクローンし、参照先の変更に含まれるコミットの件名を取得します。
+	SubmoduleLog SubmoduleMode = "log"
+	// SubmoduleDiff は、SubmoduleLog に加えて、サブモジュール自身の差分 (変更前..変更後) を差分に含めます。
+	SubmoduleDiff SubmoduleMode = "diff"
+)
+
+// maxSubmoduleCommits は、1つのサブモジュールについて取得するコミットの件名の上限です。
+const maxSubmoduleCommits = 50
+
+// ParseSubmoduleMode は文字列を SubmoduleMode に変換します。空文字列は SubmodulePointer として扱います。
+func ParseSubmoduleMode(s string) (SubmoduleMode, error) {
+	switch SubmoduleMode(s) {
+	case "", SubmodulePointer:
+		return SubmodulePointer, nil
+	case SubmoduleLog:
+		return SubmoduleLog, nil
+	case SubmoduleDiff:
+		return SubmoduleDiff, nil
+	default:
+		return "", fmt.Errorf("不明なサブモジュールの扱いです: '%s' ('pointer', 'log', 'diff' のいずれかを指定してください)", s)
+	}
+}
+
+// WithSubmoduleMode は、差分に含まれるサブモジュールの参照先の変更の扱いを設定します。
+func WithSubmoduleMode(mode SubmoduleMode) Option {
+	return func(c *Client) {
+		c.SubmoduleMode = mode
+	}
+}
+
+// SubmoduleChange は、サブモジュールの参照先のコミットの変更です。
+type SubmoduleChange struct {
+	Path string
+	URL  string
+	// OldCommit と NewCommit は変更前後の参照先のコミットです。追加・削除の場合は一方が空です。
+	OldCommit, NewCommit string
+	// Commits は NewCommit から OldCommit までのコミットの件名です (新しい順、最大 maxSubmoduleCommits 件)。
+	// SubmodulePointer の場合と、取得できなかった場合は空です。
+	Commits []string
+	// Truncated は、件名が上限を超えたため一部のみを取得したことを表します。
+	Truncated bool
+	// Err はサブモジュールのクローンや履歴の取得に失敗した場合のエラーです。
+	Err error
+}
+
+// SubmoduleChanges は、2つのブランチ (またはリビジョン) の 3-dot diff に含まれるサブモジュールの参照先の変更を返します。
+// SubmodulePointer 以外の場合は、サブモジュールをメモリ上にクローンして、変更に含まれるコミットの件名を取得します。
+func (c *Client) SubmoduleChanges(ctx context.Context, baseBranch, featureBranch string) ([]SubmoduleChange, error) {
+	repo, err := c.getRepository()
+	if err != nil {
+		return nil, err
+	}
+	mergeBase, featureCommit, err := c.resolveMergeBase(repo, baseBranch, featureBranch)
+	if err != nil {
+		return nil, err
+	}
+	baseTree, err := mergeBase.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("マージベースのツリー取得に失敗しました: %w", err)
+	}
+	featureTree, err := featureCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("フィーチャーブランチのツリー取得に失敗しました: %w", err)
+	}
+	changes, err := baseTree.DiffContext(ctx, featureTree)
+	if err != nil {
+		return nil, fmt.Errorf("ツリーの差分取得に失敗しました: %w", err)
+	}
+	return c.submoduleChanges(ctx, baseTree, featureTree, changes), nil
+}
+
+// submoduleChanges は、ツリーの差分からサブモジュールの参照先の変更を取り出します。
+func (c *Client) submoduleChanges(ctx context.Context, baseTree, featureTree *object.Tree, changes object.Changes) []SubmoduleChange {
+	var result []SubmoduleChange
+	var modules map[string]*config.Submodule
+	for _, change := range changes {
+		if change.From.TreeEntry.Mode != filemode.Submodule && change.To.TreeEntry.Mode != filemode.Submodule {
+			continue
+		}
+		if modules == nil {
+			// 削除されたサブモジュールは変更後の .gitmodules に含まれないため、変更前の定義で補う
+			modules = gitModules(baseTree)
+			for path, m := range gitModules(featureTree) {
+				modules[path] = m
+			}
+		}
+		sc := SubmoduleChange{Path: change.To.Name}
+		if change.From.TreeEntry.Mode == filemode.Submodule {
+			sc.Path, sc.OldCommit = change.From.Name, change.From.TreeEntry.Hash.String()
+		}
+		if change.To.TreeEntry.Mode == filemode.Submodule {
+			sc.Path, sc.NewCommit = change.To.Name, change.To.TreeEntry.Hash.String()
+		}
+		if m, ok := modules[sc.Path]; ok {
+			sc.URL = resolveSubmoduleURL(c.remoteURL, m.URL)
+		}
+		if c.SubmoduleMode == SubmoduleLog || c.SubmoduleMode == SubmoduleDiff {
+			sc.Commits, sc.Truncated, sc.Err = c.submoduleLog(ctx, sc)
+			if sc.Err != nil {
+				slog.Warn("サブモジュールの履歴の取得に失敗しました。参照先の変更のみをレビューします。", "path", sc.Path, "url", sc.URL, "error", sc.Err)
+			}
+		}
+		result = append(result, sc)
+	}
+	return result
+}
+
+// gitModules はツリーの .gitmodules のサブモジュールの定義をパスごとに返します。読み取れない場合は空です。
+func gitModules(tree *object.Tree) map[string]*config.Submodule {
+	result := make(map[string]*config.Submodule)
+	file, err := tree.File(".gitmodules")
+	if err != nil {
+		return result
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return result
+	}
+	modules := config.NewModules()
+	if err := modules.Unmarshal([]byte(content)); err != nil {
+		return result
+	}
+	for _, m := range modules.Submodules {
+		result[m.Path] = m
+	}
+	return result
+}
+
+// resolveSubmoduleURL は、'../other.git' のような相対URLを、親リポジトリのURLを基準に解決します。
+// 'git@host:org/repo.git' のような SSH の短縮形式では、':' の後のパスも1階層として扱います。
+func resolveSubmoduleURL(parentURL, url string) string {
+	if !strings.HasPrefix(url, "./") && !strings.HasPrefix(url, "../") {
+		return url
+	}
+	base, sep := strings.TrimSuffix(parentURL, "/"), "/"
+	for {
+		if rest, ok := strings.CutPrefix(url, "./"); ok {
+			url = rest
+			continue
+		}
+		rest, ok := strings.CutPrefix(url, "../")
+		if !ok {
+			return base + sep + url
+		}
+		url = rest
+		if i := strings.LastIndexAny(base, "/:"); i >= 0 {
+			base, sep = base[:i], base[i:i+1]
+		}
+	}
+}
+
+// submoduleRepository は、サブモジュールをメモリ上にクローンします。同じURLのクローンは Client の中で再利用します。
+func (c *Client) submoduleRepository(ctx context.Context, url string) (*git.Repository, error) {
+	if repo, ok := c.submodules[url]; ok {
+		return repo, nil
+	}
+	if url == "" {
+		return nil, fmt.Errorf(".gitmodules にサブモジュールのURLが定義されていません")
+	}
+	auth, err := c.getAuthMethod(url)
+	if err != nil {
+		return nil, fmt.Errorf("サブモジュールの認証情報の取得に失敗しました: %w", err)
+	}
+	slog.Info("サブモジュールをメモリ上にクローンします。", "url", url)
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, &git.CloneOptions{URL: url, Auth: auth, Tags: git.NoTags})
+	if err != nil {
+		return nil, fmt.Errorf("サブモジュールのクローンに失敗しました (URL: %s): %w", url, err)
+	}
+	if c.submodules == nil {
+		c.submodules = make(map[string]*git.Repository)
+	}
+	c.submodules[url] = repo
+	return repo, nil
+}
+
+// submoduleLog は、変更後の参照先から変更前の参照先までのコミットの件名を新しい順に返します。
+// 変更前の参照先が見つからない場合 (履歴の書き換えなど) は、上限の件数までを返します。
+func (c *Client) submoduleLog(ctx context.Context, sc SubmoduleChange) (subjects []string, truncated bool, err error) {
+	if sc.NewCommit == "" {
+		return nil, false, nil
+	}
+	repo, err := c.submoduleRepository(ctx, sc.URL)
+	if err != nil {
+		return nil, false, err
+	}
+	iter, err := repo.Log(&git.LogOptions{From: plumbing.NewHash(sc.NewCommit)})
+	if err != nil {
+		return nil, false, fmt.Errorf("サブモジュールのコミット '%s' の履歴の取得に失敗しました: %w", sc.NewCommit, err)
+	}
+	defer iter.Close()
+	for {
+		commit, err := iter.Next()
+		if err != nil || commit.Hash.String() == sc.OldCommit {
+			return subjects, false, nil
+		}
+		if len(subjects) == maxSubmoduleCommits {
+			return subjects, true, nil
+		}
+		subject, _, _ := strings.Cut(commit.Message, "\n")
+		subjects = append(subjects, fmt.Sprintf("%s %s", commit.Hash.String()[:7], strings.TrimSpace(subject)))
+	}
+}
+
+// submodulePatch は、サブモジュールの参照先の変更を git diff と同じ 'Subproject commit' の形式で返します。
+// SubmoduleDiff の場合は、サブモジュール自身の差分をパスにサブモジュールのパスを付けて続けます。
+func (c *Client) submodulePatch(ctx context.Context, sc SubmoduleChange) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "diff --git a/%s b/%s\n", sc.Path, sc.Path)
+	switch {
+	case sc.OldCommit == "":
+		fmt.Fprintf(&sb, "new file mode 160000\nindex %s..%s\n--- /dev/null\n+++ b/%s\n@@ -0,0 +1 @@\n+Subproject commit %s\n",
+			plumbing.ZeroHash, sc.NewCommit, sc.Path, sc.NewCommit)
+	case sc.NewCommit == "":
+		fmt.Fprintf(&sb, "deleted file mode 160000\nindex %s..%s\n--- a/%s\n+++ /dev/null\n@@ -1 +0,0 @@\n-Subproject commit %s\n",
+			sc.OldCommit, plumbing.ZeroHash, sc.Path, sc.OldCommit)
+	default:
+		fmt.Fprintf(&sb, "index %s..%s 160000\n--- a/%s\n+++ b/%s\n@@ -1 +1 @@\n-Subproject commit %s\n+Subproject commit %s\n",
+			sc.OldCommit, sc.NewCommit, sc.Path, sc.Path, sc.OldCommit, sc.NewCommit)
+	}
+	if c.SubmoduleMode != SubmoduleDiff || sc.NewCommit == "" || sc.Err != nil {
+		return sb.String()
+	}
+	content, err := c.submoduleContentDiff(ctx, sc)
+	if err != nil {
+		slog.Warn("サブモジュールの差分の取得に失敗しました。参照先の変更のみをレビューします。", "path", sc.Path, "error", err)
+		return sb.String()
+	}
+	return sb.String() + content
+}
+
+// submoduleContentDiff は、サブモジュールの変更前から変更後の参照先までの差分を返します。
+// 追加されたサブモジュールは、すべてのファイルを追加した差分になります。
+func (c *Client) submoduleContentDiff(ctx context.Context, sc SubmoduleChange) (string, error) {
+	repo, err := c.submoduleRepository(ctx, sc.URL)
+	if err != nil {
+		return "", err
+	}
+	newCommit, err := repo.CommitObject(plumbing.NewHash(sc.NewCommit))
+	if err != nil {
+		return "", fmt.Errorf("コミット '%s' の取得に失敗しました: %w", sc.NewCommit, err)
+	}
+	newTree, err := newCommit.Tree()
+	if err != nil {
+		return "", err
+	}
+	var oldTree *object.Tree
+	if sc.OldCommit != "" {
+		oldCommit, err := repo.CommitObject(plumbing.NewHash(sc.OldCommit))
+		if err != nil {
+			return "", fmt.Errorf("コミット '%s' の取得に失敗しました: %w", sc.OldCommit, err)
+		}
+		if oldTree, err = oldCommit.Tree(); err != nil {
+			return "", err
+		}
+	}
+	changes, err := object.DiffTreeWithOptions(ctx, oldTree, newTree, nil)
+	if err != nil {
+		return "", fmt.Errorf("ツリーの差分取得に失敗しました: %w", err)
+	}
+	patch, err := changes.PatchContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("パッチの生成に失敗しました: %w", err)
+	}
+	var sb strings.Builder
+	encoder := fdiff.NewUnifiedEncoder(&sb, fdiff.DefaultContextLines).
+		SetSrcPrefix("a/" + sc.Path + "/").
+		SetDstPrefix("b/" + sc.Path + "/")
+	if err := encoder.Encode(patch); err != nil {
+		return "", fmt.Errorf("パッチの生成に失敗しました: %w", err)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,161 @@
+package gitclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+const (
+	// tmpDirMarker はクローン中の一時ディレクトリ名に付与される識別子です。
+	tmpDirMarker = ".clone-tmp-"
+	// oldDirMarker は置き換え待ちの旧ディレクトリ名に付与される識別子です。
+	oldDirMarker = ".clone-old-"
+	// staleSwapDirAge は、一時ディレクトリと退避ディレクトリを中断された実行の残骸とみなすまでの時間です。
+	// 大きなリポジトリのクローンでも超えない長さとします。
+	staleSwapDirAge = 24 * time.Hour
+)
+
+// openVerified は localPath のリポジトリを開き、HEAD が解決できることを確認します。
+// ディレクトリが存在しない場合は os.ErrNotExist をラップしたエラーを返します。
+func openVerified(localPath string) (*git.Repository, error) {
+	if _, err := os.Stat(localPath); err != nil {
+		return nil, err
+	}
+	repo, err := git.PlainOpen(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("リポジトリのオープンに失敗しました: %w", err)
+	}
+	if _, err := repo.Head(); err != nil {
+		return nil, fmt.Errorf("HEAD の解決に失敗しました: %w", err)
+	}
+	return repo, nil
+}
+
+// cloneAtomically は LocalPath の兄弟となる一時ディレクトリにクローンし、
+// 検証に成功した場合にのみ LocalPath へリネームして置き換えます。
+// クローンが途中で失敗しても、既存の LocalPath は変更されません。
+func (c *Client) cloneAtomically(ctx context.Context, repositoryURL string) (*git.Repository, error) {
+	parentDir := filepath.Dir(c.LocalPath)
+	if err := os.MkdirAll(parentDir, 0755); err != nil {
+		return nil, fmt.Errorf("親ディレクトリの作成に失敗しました: %w", err)
+	}
+
+	tmpDir, err := os.MkdirTemp(parentDir, filepath.Base(c.LocalPath)+tmpDirMarker+"*")
+	if err != nil {
+		return nil, fmt.Errorf("一時ディレクトリの作成に失敗しました: %w", err)
+	}
+	// 正常に置き換えが完了した場合 tmpDir は存在しないため、RemoveAll は何もしません。
+	defer os.RemoveAll(tmpDir)
+
+	slog.Info("一時ディレクトリにリポジトリをクローンします。", "url", repositoryURL, "tmp_path", tmpDir, "depth", c.Depth)
+	_, err = git.PlainCloneContext(ctx, tmpDir, false, c.cloneOptions(repositoryURL))
+	if err != nil {
+		return nil, fmt.Errorf("go-git クローンに失敗しました: %w", err)
+	}
+
+	if _, err := openVerified(tmpDir); err != nil {
+		return nil, fmt.Errorf("クローン結果の検証に失敗しました: %w", err)
+	}
+
+	if err := swapDir(tmpDir, c.LocalPath); err != nil {
+		return nil, err
+	}
+	slog.Info("クローンしたリポジトリを配置しました。", "path", c.LocalPath)
+
+	return git.PlainOpen(c.LocalPath)
+}
+
+// cloneOptions は、ディスクへのクローンとメモリ上へのクローンで共通のクローンのオプションを返します。
+func (c *Client) cloneOptions(repositoryURL string) *git.CloneOptions {
+	return &git.CloneOptions{
+		URL:           repositoryURL,
+		ReferenceName: plumbing.NewBranchReferenceName(c.BaseBranch),
+		SingleBranch:  len(c.Branches) > 0,
+		Auth:          c.auth,
+		Depth:         c.Depth,
+		Progress:      io.Discard,
+	}
+}
+
+// swapDir は newDir を target にリネームします。target が既に存在する場合は一度退避し、
+// リネーム成功後に削除します。リネームに失敗した場合は退避したディレクトリを元に戻します。
+func swapDir(newDir, target string) error {
+	var oldDir string
+	if _, err := os.Lstat(target); err == nil {
+		oldDir = target + oldDirMarker + strconv.FormatInt(time.Now().UnixNano(), 10)
+		if err := os.Rename(target, oldDir); err != nil {
+			return fmt.Errorf("既存ディレクトリ '%s' の退避に失敗しました: %w", target, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("ローカルパス '%s' の確認に失敗しました: %w", target, err)
+	}
+
+	if err := os.Rename(newDir, target); err != nil {
+		if oldDir != "" {
+			if restoreErr := os.Rename(oldDir, target); restoreErr != nil {
+				slog.Error("退避したディレクトリの復元に失敗しました。", "path", oldDir, "error", restoreErr)
+			}
+		}
+		return fmt.Errorf("クローンしたディレクトリの配置に失敗しました: %w", err)
+	}
+
+	if oldDir != "" {
+		if err := os.RemoveAll(oldDir); err != nil {
+			slog.Warn("退避した旧ディレクトリの削除に失敗しました。", "path", oldDir, "error", err)
+		}
+	}
+	return nil
+}
+
+// removeStaleSwapDirs は、中断された過去の実行が残した一時ディレクトリと退避ディレクトリを削除します。
+// 同じローカルパスに別のランナーがクローン中の一時ディレクトリを消さないよう、staleSwapDirAge より古いもののみを対象とします。
+func removeStaleSwapDirs(localPath string) {
+	now := time.Now()
+	for _, marker := range []string{tmpDirMarker, oldDirMarker} {
+		matches, err := filepath.Glob(localPath + marker + "*")
+		if err != nil {
+			continue
+		}
+		for _, dir := range matches {
+			if modified := swapDirModTime(dir, marker); now.Sub(modified) < staleSwapDirAge {
+				slog.Debug("使用中の可能性がある一時ディレクトリのため、削除しません。", "path", dir, "modified", modified)
+				continue
+			}
+			if err := os.RemoveAll(dir); err != nil {
+				slog.Warn("残存していた一時ディレクトリの削除に失敗しました。", "path", dir, "error", err)
+				continue
+			}
+			slog.Debug("残存していた一時ディレクトリを削除しました。", "path", dir)
+		}
+	}
+}
+
+// swapDirModTime は、一時ディレクトリまたは退避ディレクトリが最後に使用された時刻を返します。
+// 退避ディレクトリは名前に含まれる退避した時刻を使用します。一時ディレクトリは、クローン中に更新される
+// ディレクトリ自身、.git、パックファイルのディレクトリのうち最も新しい更新時刻を使用します。
+func swapDirModTime(dir, marker string) time.Time {
+	if marker == oldDirMarker {
+		suffix := dir[strings.LastIndex(dir, oldDirMarker)+len(oldDirMarker):]
+		if nano, err := strconv.ParseInt(suffix, 10, 64); err == nil {
+			return time.Unix(0, nano)
+		}
+	}
+	var latest time.Time
+	for _, path := range []string{dir, filepath.Join(dir, ".git"), filepath.Join(dir, ".git", "objects", "pack")} {
+		if info, err := os.Stat(path); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
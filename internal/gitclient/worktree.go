@@ -0,0 +1,346 @@
+package gitclient
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// WorktreeChanges は、作業ツリーの差分に含める変更の種類です。
+type WorktreeChanges string
+
+const (
+	// WorktreeStaged はステージされた変更 (HEAD とインデックスの差分。git diff --cached 相当) です。
+	WorktreeStaged WorktreeChanges = "staged"
+	// WorktreeUnstaged はステージされていない変更 (インデックスと作業ツリーの差分) と未追跡のファイルです。
+	WorktreeUnstaged WorktreeChanges = "unstaged"
+	// WorktreeAll はコミットされていないすべての変更 (HEAD と作業ツリーの差分) と未追跡のファイルです。
+	WorktreeAll WorktreeChanges = "all"
+)
+
+// ParseWorktreeChanges は文字列を WorktreeChanges に変換します。空文字列は WorktreeAll として扱います。
+func ParseWorktreeChanges(s string) (WorktreeChanges, error) {
+	switch c := WorktreeChanges(strings.ToLower(strings.TrimSpace(s))); c {
+	case "":
+		return WorktreeAll, nil
+	case WorktreeStaged, WorktreeUnstaged, WorktreeAll:
+		return c, nil
+	default:
+		return "", fmt.Errorf("作業ツリーの変更の種類が不正です: '%s' ('staged', 'unstaged', 'all' のいずれかを指定してください)", s)
+	}
+}
+
+// binaryProbeSize は、バイナリファイルの判定で NUL 文字を探す先頭のバイト数です (git と同じ)。
+const binaryProbeSize = 8000
+
+// WorktreeDiff は、path のローカルリポジトリのコミットされていない変更を unified diff で返します。
+// リモートへのアクセスやクローンは行わず、リポジトリとインデックスも変更しません。
+// path がリポジトリのサブディレクトリの場合は、上位の .git を探します。未追跡のファイルは .gitignore に従います。
+func WorktreeDiff(ctx context.Context, path string, changes WorktreeChanges) (string, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", fmt.Errorf("ローカルリポジトリ '%s' のオープンに失敗しました: %w", path, err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("作業ツリーの取得に失敗しました: %w", err)
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return "", fmt.Errorf("作業ツリーの状態の取得に失敗しました: %w", err)
+	}
+	idx, err := repo.Storer.Index()
+	if err != nil {
+		return "", fmt.Errorf("インデックスの読み込みに失敗しました: %w", err)
+	}
+
+	// コミットがまだないリポジトリでは、HEAD を空のツリーとして扱います
+	var head *object.Tree
+	ref, err := repo.Head()
+	switch {
+	case err == nil:
+		commit, err := repo.CommitObject(ref.Hash())
+		if err != nil {
+			return "", fmt.Errorf("HEAD のコミットの取得に失敗しました: %w", err)
+		}
+		if head, err = commit.Tree(); err != nil {
+			return "", fmt.Errorf("HEAD のツリーの取得に失敗しました: %w", err)
+		}
+	case !errors.Is(err, plumbing.ErrReferenceNotFound):
+		return "", fmt.Errorf("HEAD の解決に失敗しました: %w", err)
+	}
+
+	src := worktreeSource{repo: repo, wt: wt, index: idx, head: head}
+	paths := make([]string, 0, len(status))
+	for p := range status {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var patch worktreePatch
+	for _, p := range paths {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		from, to, err := src.versions(p, status[p], changes)
+		if err != nil {
+			return "", err
+		}
+		if from == nil && to == nil || from != nil && to != nil && from.hash == to.hash && from.mode == to.mode {
+			continue
+		}
+		patch = append(patch, newFilePatch(from, to))
+	}
+
+	var buf bytes.Buffer
+	if err := fdiff.NewUnifiedEncoder(&buf, fdiff.DefaultContextLines).Encode(patch); err != nil {
+		return "", fmt.Errorf("差分の生成に失敗しました: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// worktreeSource は、HEAD・インデックス・作業ツリーのそれぞれからファイルの内容を読み込みます。
+type worktreeSource struct {
+	repo  *git.Repository
+	wt    *git.Worktree
+	index *index.Index
+	head  *object.Tree
+}
+
+// versions は、changes に応じて差分の変更前と変更後とするファイルを返します。存在しない側は nil です。
+func (s worktreeSource) versions(path string, st *git.FileStatus, changes WorktreeChanges) (from, to *fileVersion, err error) {
+	// ステージされた名前の変更は、変更前を元のパスから読み込みます
+	headPath := path
+	if st.Staging == git.Renamed && st.Extra != "" {
+		headPath = st.Extra
+	}
+
+	switch changes {
+	case WorktreeStaged:
+		if st.Staging == git.Unmodified || st.Staging == git.Untracked {
+			return nil, nil, nil
+		}
+		if from, err = s.fromHead(headPath); err != nil {
+			return nil, nil, err
+		}
+		to, err = s.fromIndex(path)
+	case WorktreeUnstaged:
+		if st.Worktree == git.Unmodified {
+			return nil, nil, nil
+		}
+		if st.Worktree != git.Untracked {
+			if from, err = s.fromIndex(path); err != nil {
+				return nil, nil, err
+			}
+		}
+		to, err = s.fromWorktree(path)
+	default:
+		if st.Staging == git.Unmodified && st.Worktree == git.Unmodified {
+			return nil, nil, nil
+		}
+		if st.Worktree != git.Untracked {
+			if from, err = s.fromHead(headPath); err != nil {
+				return nil, nil, err
+			}
+		}
+		to, err = s.fromWorktree(path)
+	}
+	return from, to, err
+}
+
+// fromHead は HEAD のファイルを返します。HEAD に存在しない場合は nil です。
+func (s worktreeSource) fromHead(path string) (*fileVersion, error) {
+	if s.head == nil {
+		return nil, nil
+	}
+	f, err := s.head.File(path)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("HEAD のファイル '%s' の取得に失敗しました: %w", path, err)
+	}
+	content, err := readBlob(&f.Blob)
+	if err != nil {
+		return nil, fmt.Errorf("HEAD のファイル '%s' の読み込みに失敗しました: %w", path, err)
+	}
+	return &fileVersion{path: path, mode: f.Mode, hash: f.Hash, content: content}, nil
+}
+
+// fromIndex はインデックスのファイルを返します。インデックスに存在しない場合は nil です。
+func (s worktreeSource) fromIndex(path string) (*fileVersion, error) {
+	e, err := s.index.Entry(path)
+	if errors.Is(err, index.ErrEntryNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("インデックスのファイル '%s' の取得に失敗しました: %w", path, err)
+	}
+	blob, err := s.repo.BlobObject(e.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("インデックスのファイル '%s' の取得に失敗しました: %w", path, err)
+	}
+	content, err := readBlob(blob)
+	if err != nil {
+		return nil, fmt.Errorf("インデックスのファイル '%s' の読み込みに失敗しました: %w", path, err)
+	}
+	return &fileVersion{path: path, mode: e.Mode, hash: e.Hash, content: content}, nil
+}
+
+// fromWorktree は作業ツリーのファイルを返します。削除されている場合は nil です。
+func (s worktreeSource) fromWorktree(path string) (*fileVersion, error) {
+	fs := s.wt.Filesystem
+	info, err := fs.Lstat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("作業ツリーのファイル '%s' の取得に失敗しました: %w", path, err)
+	}
+	mode, err := filemode.NewFromOSFileMode(info.Mode())
+	if err != nil {
+		return nil, fmt.Errorf("作業ツリーのファイル '%s' の種類に対応していません: %w", path, err)
+	}
+
+	var content []byte
+	if mode == filemode.Symlink {
+		target, err := fs.Readlink(path)
+		if err != nil {
+			return nil, fmt.Errorf("作業ツリーのシンボリックリンク '%s' の読み込みに失敗しました: %w", path, err)
+		}
+		content = []byte(target)
+	} else {
+		f, err := fs.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("作業ツリーのファイル '%s' のオープンに失敗しました: %w", path, err)
+		}
+		content, err = io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("作業ツリーのファイル '%s' の読み込みに失敗しました: %w", path, err)
+		}
+	}
+	return &fileVersion{path: path, mode: mode, hash: plumbing.ComputeHash(plumbing.BlobObject, content), content: content}, nil
+}
+
+// readBlob は Blob の内容を読み込みます。
+func readBlob(blob *object.Blob) ([]byte, error) {
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// --- fdiff.Patch の実装 ---
+
+// fileVersion は差分の片側のファイルです。fdiff.File を実装します。
+type fileVersion struct {
+	path    string
+	mode    filemode.FileMode
+	hash    plumbing.Hash
+	content []byte
+}
+
+func (f *fileVersion) Hash() plumbing.Hash     { return f.hash }
+func (f *fileVersion) Mode() filemode.FileMode { return f.mode }
+func (f *fileVersion) Path() string            { return f.path }
+
+func (f *fileVersion) isBinary() bool {
+	return f != nil && bytes.IndexByte(f.content[:min(len(f.content), binaryProbeSize)], 0) >= 0
+}
+
+func (f *fileVersion) text() string {
+	if f == nil {
+		return ""
+	}
+	return string(f.content)
+}
+
+// worktreeChunk は fdiff.Chunk の実装です。
+type worktreeChunk struct {
+	content string
+	op      fdiff.Operation
+}
+
+func (c worktreeChunk) Content() string       { return c.content }
+func (c worktreeChunk) Type() fdiff.Operation { return c.op }
+
+// worktreeFilePatch は fdiff.FilePatch の実装です。
+type worktreeFilePatch struct {
+	from, to *fileVersion
+	binary   bool
+	chunks   []fdiff.Chunk
+}
+
+// newFilePatch は、変更前と変更後のファイルから差分を計算します。
+func newFilePatch(from, to *fileVersion) *worktreeFilePatch {
+	fp := &worktreeFilePatch{from: from, to: to, binary: from.isBinary() || to.isBinary()}
+	if fp.binary {
+		return fp
+	}
+	for _, d := range diff.Do(from.text(), to.text()) {
+		op := fdiff.Equal
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			op = fdiff.Delete
+		case diffmatchpatch.DiffInsert:
+			op = fdiff.Add
+		}
+		fp.chunks = append(fp.chunks, worktreeChunk{content: d.Text, op: op})
+	}
+	return fp
+}
+
+func (p *worktreeFilePatch) IsBinary() bool        { return p.binary }
+func (p *worktreeFilePatch) Chunks() []fdiff.Chunk { return p.chunks }
+
+// Files は変更前と変更後のファイルを返します。存在しない側は、型付きの nil ではなく nil インターフェースを返します。
+func (p *worktreeFilePatch) Files() (from, to fdiff.File) {
+	if p.from != nil {
+		from = p.from
+	}
+	if p.to != nil {
+		to = p.to
+	}
+	return from, to
+}
+
+// worktreePatch は fdiff.Patch の実装です。
+type worktreePatch []fdiff.FilePatch
+
+func (p worktreePatch) FilePatches() []fdiff.FilePatch { return p }
+func (p worktreePatch) Message() string                { return "" }
+
+// WorktreeFile は、path のローカルリポジトリの作業ツリーにある、リポジトリのルートからの相対パス name のファイルの内容を返します。
+// path がリポジトリのサブディレクトリの場合は、上位の .git を探します。ファイルが存在しない場合は nil を返します。
+func WorktreeFile(path, name string) ([]byte, error) {
+	repo, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return nil, fmt.Errorf("ローカルリポジトリ '%s' のオープンに失敗しました: %w", path, err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("作業ツリーの取得に失敗しました: %w", err)
+	}
+	f, err := (worktreeSource{wt: wt}).fromWorktree(name)
+	if err != nil || f == nil {
+		return nil, err
+	}
+	return f.content, nil
+}
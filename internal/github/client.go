@@ -0,0 +1,187 @@
+// Package github は、GitHub のプルリクエストにレビューを投稿する REST API のクライアントです。
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/pkg/retry"
+)
+
+// DefaultBaseURL は github.com の REST API のベースURLです。
+const DefaultBaseURL = "https://api.github.com"
+
+// Repo は GitHub のリポジトリです。
+type Repo struct {
+	Owner string
+	Name  string
+}
+
+// String は 'owner/name' 形式の表記を返します。
+func (r Repo) String() string {
+	return r.Owner + "/" + r.Name
+}
+
+// ParseRepo は 'owner/name'、SSH URL (git@github.com:owner/name.git)、HTTPS URL のいずれかからリポジトリを読み取ります。
+func ParseRepo(spec string) (Repo, error) {
+	s := strings.TrimSpace(spec)
+	switch {
+	case strings.HasPrefix(s, "git@"):
+		_, s, _ = strings.Cut(s, ":")
+	case strings.Contains(s, "://"):
+		u, err := url.Parse(s)
+		if err != nil {
+			return Repo{}, fmt.Errorf("GitHub のリポジトリURLが不正です: '%s': %w", spec, err)
+		}
+		s = u.Path
+	}
+	s = strings.TrimSuffix(strings.Trim(s, "/"), ".git")
+	owner, name, ok := strings.Cut(s, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") {
+		return Repo{}, fmt.Errorf("GitHub のリポジトリの指定が不正です: '%s' ('owner/name' またはリポジトリURLを指定してください)", spec)
+	}
+	return Repo{Owner: owner, Name: name}, nil
+}
+
+// Client は GitHub REST API のクライアントです。
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	token      string
+}
+
+// NewClient は Client を生成します。baseURL が空の場合は github.com の API を使用します。
+func NewClient(httpClient *http.Client, baseURL, token string) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return &Client{httpClient: httpClient, baseURL: strings.TrimRight(baseURL, "/"), token: token}
+}
+
+// PullRequest はプルリクエストの情報のうち、レビューの投稿に必要なものです。
+type PullRequest struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+	Head    struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	} `json:"head"`
+	Base struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// ReviewComment は差分の行に紐付けるインラインコメントです。
+type ReviewComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	// Side は 'RIGHT' (変更後) または 'LEFT' (変更前) です。
+	Side string `json:"side"`
+	Body string `json:"body"`
+}
+
+// ReviewEvent はレビューの種類です。
+type ReviewEvent string
+
+const (
+	EventComment        ReviewEvent = "COMMENT"
+	EventRequestChanges ReviewEvent = "REQUEST_CHANGES"
+	EventApprove        ReviewEvent = "APPROVE"
+)
+
+// ReviewInput はプルリクエストに投稿するレビューです。
+type ReviewInput struct {
+	CommitID string          `json:"commit_id,omitempty"`
+	Body     string          `json:"body"`
+	Event    ReviewEvent     `json:"event"`
+	Comments []ReviewComment `json:"comments,omitempty"`
+}
+
+// GetPullRequest はプルリクエストの情報を取得します。
+func (c *Client) GetPullRequest(ctx context.Context, repo Repo, number int) (PullRequest, error) {
+	var pr PullRequest
+	err := c.call(ctx, http.MethodGet, fmt.Sprintf("/repos/%s/%s/pulls/%d", repo.Owner, repo.Name, number), nil, &pr)
+	return pr, err
+}
+
+// CreateReview はプルリクエストにインラインコメント付きのレビューを投稿し、レビューのURLを返します。
+func (c *Client) CreateReview(ctx context.Context, repo Repo, number int, input ReviewInput) (string, error) {
+	var review struct {
+		HTMLURL string `json:"html_url"`
+	}
+	err := c.call(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/%s/pulls/%d/reviews", repo.Owner, repo.Name, number), input, &review)
+	return review.HTMLURL, err
+}
+
+// CreateIssueComment はプルリクエストの会話にコメントを投稿し、コメントのURLを返します。
+// レビュー本文の上限を超えた続きの投稿に使用します。
+func (c *Client) CreateIssueComment(ctx context.Context, repo Repo, number int, body string) (string, error) {
+	var comment struct {
+		HTMLURL string `json:"html_url"`
+	}
+	input := map[string]string{"body": body}
+	err := c.call(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/%s/issues/%d/comments", repo.Owner, repo.Name, number), input, &comment)
+	return comment.HTMLURL, err
+}
+
+// call は GitHub REST API を呼び出します。
+func (c *Client) call(ctx context.Context, method, path string, input any, output any) error {
+	var body io.Reader
+	if input != nil {
+		payload, err := json.Marshal(input)
+		if err != nil {
+			return fmt.Errorf("GitHub API (%s %s) のリクエストのエンコードに失敗しました: %w", method, path, err)
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("GitHub APIリクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if input != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("GitHub API (%s %s) の呼び出しに失敗しました: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("GitHub API (%s %s) のレスポンスの読み込みに失敗しました: %w", method, path, err)
+	}
+	if resp.StatusCode/100 != 2 {
+		var apiErr struct {
+			Message string `json:"message"`
+			Errors  []any  `json:"errors"`
+		}
+		_ = json.Unmarshal(respBody, &apiErr)
+		err := fmt.Errorf("GitHub API (%s %s) がエラーを返しました (status: %d): %s %v", method, path, resp.StatusCode, apiErr.Message, apiErr.Errors)
+		// レート制限以外のクライアントエラーはリトライしても解消しません
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests && resp.Header.Get("X-RateLimit-Remaining") != "0" {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	if output != nil {
+		if err := json.Unmarshal(respBody, output); err != nil {
+			return fmt.Errorf("GitHub API (%s %s) のレスポンスのデコードに失敗しました: %w", method, path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Package guidelines は、リポジトリに置いたレビューの規約ファイルを読み込み、「プロジェクトのルール」としてプロンプトに添えます。
+// 命名・エラー処理・使用するフレームワークなど、チーム固有の規約を踏まえて AI が指摘できるようにするためのものです。
+package guidelines
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultFiles は、規約ファイルを探すリポジトリのルートからの既定のパスです。先に見つかったファイルを使用します。
+var DefaultFiles = []string{".ai-review.md", "docs/REVIEW_GUIDELINES.md"}
+
+// MaxBytes は、プロンプトに含める規約の最大サイズ (バイト) です。超える部分は省略します。
+const MaxBytes = 16 * 1024
+
+// Guidelines はリポジトリの規約です。ゼロ値は規約がないことを表します。
+type Guidelines struct {
+	// Path は規約ファイルのリポジトリのルートからのパスです。
+	Path    string
+	Content string
+	// Truncated は、MaxBytes を超えたため末尾を省略したかどうかです。
+	Truncated bool
+}
+
+// Find は paths を順に read で読み込み、最初に見つかった空でない規約を返します。
+// read は、ファイルが存在しない場合に nil を返す関数です。見つからない場合はゼロ値を返します。
+// 読み込めないファイルは飛ばして次の候補を探し、その失敗をエラーとして合わせて返します。
+func Find(paths []string, read func(path string) ([]byte, error)) (Guidelines, error) {
+	var errs []error
+	for _, path := range paths {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		content, err := read(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("規約ファイル '%s' の読み込みに失敗しました: %w", path, err))
+			continue
+		}
+		text := strings.TrimSpace(string(content))
+		if text == "" {
+			continue
+		}
+		g := Guidelines{Path: path, Content: text}
+		if len(text) > MaxBytes {
+			g.Content, g.Truncated = truncate(text, MaxBytes), true
+		}
+		return g, errors.Join(errs...)
+	}
+	return Guidelines{}, errors.Join(errs...)
+}
+
+// PromptNote は、規約をプロジェクトのルールとして AI に伝えるプロンプトの前置きを返します。規約がない場合は空文字列です。
+func (g Guidelines) PromptNote() string {
+	if g.Content == "" {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## 📐 プロジェクトのルール (リポジトリの規約ファイル)\n\n")
+	fmt.Fprintf(&sb, "このリポジトリでは `%s` に次の規約を定めています。一般的な慣習より規約を優先し、規約に反する変更は指摘してください。指摘では該当する規約に触れてください。\n\n", g.Path)
+	fence := "````"
+	for strings.Contains(g.Content, fence) {
+		fence += "`"
+	}
+	fmt.Fprintf(&sb, "%smarkdown\n%s\n%s\n\n", fence, g.Content, fence)
+	if g.Truncated {
+		fmt.Fprintf(&sb, "(規約ファイルが %d バイトを超えるため、以降は省略)\n\n", MaxBytes)
+	}
+	sb.WriteString("---\n\n")
+	return sb.String()
+}
+
+// truncate は、text を limit バイト以下の行の区切りで切り詰めます。1行目が limit を超える場合は文字の区切りで切り詰めます。
+func truncate(text string, limit int) string {
+	cut := text[:limit]
+	if i := strings.LastIndexByte(cut, '\n'); i > 0 {
+		return strings.TrimRight(cut[:i], "\r")
+	}
+	for !utf8.ValidString(cut) {
+		cut = cut[:len(cut)-1]
+	}
+	return cut
+}
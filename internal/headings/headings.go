@@ -0,0 +1,231 @@
+// Package headings は、レビュー結果の Markdown の見出しを、投稿先ごとの言語に翻訳する機能を提供します。
+// 1回のレビュー結果を、言語の異なる読み手の投稿先 (例: Backlog は日本語、GitHub は英語) に配信するために使用します。
+// 翻訳するのは見出しの行のみで、本文と AI の指摘の内容はそのまま残します。
+package headings
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Dictionary は見出しの翻訳です。キーは組み込みの見出し (日本語) に含まれる文言、値は翻訳後の文言です。
+type Dictionary map[string]string
+
+// DefaultLang は、翻訳を行わない組み込みの見出しの言語です。
+const DefaultLang = "ja"
+
+// Builtin は組み込みの辞書です。プロンプトテンプレートが指示する見出しと、本ツールが付与する見出しを含みます。
+var Builtin = map[string]Dictionary{
+	DefaultLang: {},
+	"en": {
+		// プロンプトテンプレートが AI に出力させる見出し
+		"リリース可否判定 (Release Decision)": "Release Decision",
+		"リリース可否判定":                    "Release Decision",
+		"総評 (Summary)":                "Summary",
+		"総評":                          "Summary",
+		"ファイルごとの指摘事項 (Detailed Findings)":      "Detailed Findings",
+		"ファイルごとの指摘事項":                          "Detailed Findings",
+		"クリティカルな指摘事項 (Critical Findings ONLY)": "Critical Findings",
+		"クリティカルな指摘事項":                          "Critical Findings",
+		"ファイル名:":                               "File:",
+		"前回の指摘へのフォローアップ":                       "Follow-up on Previous Findings",
+		// 本ツールが付与する見出し
+		"判定:":               "Verdict:",
+		"条件付きリリース可":         "Approved with Conditions",
+		"リリース不可":            "Blocked",
+		"リリース可":             "Approved",
+		"判定不明":              "Unknown",
+		"モジュール別レビュー結果":      "Review Results by Module",
+		"モジュール:":            "Module:",
+		"差分の範囲外の指摘":         "Findings Outside the Diff",
+		"重要パス (ツールによる自動判定)": "Critical Paths (Detected by Tool)",
+		"レビュー対象から除外されたファイル": "Files Excluded from Review",
+		"AI コードレビュー結果":      "AI Code Review",
+		"AIコードレビュー結果":       "AI Code Review",
+		"ブランチ:":             "Branch:",
+		"AI リリース判定":         "AI Release Decision",
+		// 2段階のレビュー (--two-pass) の見出し
+		"変更の概要とリスクの評価 (1回目)": "Overview and Risk Assessment (Pass 1)",
+		"詳細レビューの対象外のファイル":    "Files Not Covered by the Deep Dive",
+		"詳細レビュー (2回目:":       "Deep Dive (Pass 2:",
+	},
+}
+
+// Langs は組み込みの辞書の言語を昇順で返します。
+func Langs() []string {
+	langs := make([]string, 0, len(Builtin))
+	for lang := range Builtin {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// heading は Markdown の ATX 見出しの行です。
+var heading = regexp.MustCompile(`^(\s{0,3}#{1,6}\s+)(.*)$`)
+
+// Translate は、markdown の見出しの行に含まれる辞書の文言を翻訳します。コードブロック内の行は翻訳しません。
+func Translate(markdown string, dict Dictionary) string {
+	if len(dict) == 0 {
+		return markdown
+	}
+	replacer := newReplacer(dict)
+
+	lines := strings.Split(markdown, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if m := heading.FindStringSubmatch(line); m != nil {
+			lines[i] = m[1] + replacer.Replace(m[2])
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// newReplacer は辞書の文言を翻訳する Replacer を返します。文言が重なる場合は長い文言を優先します。
+func newReplacer(dict Dictionary) *strings.Replacer {
+	keys := make([]string, 0, len(dict))
+	for k := range dict {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	pairs := make([]string, 0, 2*len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k, dict[k])
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// Localizer は、投稿先ごとの見出しの言語と辞書です。ゼロ値と nil は翻訳を行いません。
+type Localizer struct {
+	// defaultLang は、言語を個別に指定していない投稿先の言語です。
+	defaultLang string
+	langs       map[string]string
+	dicts       map[string]Dictionary
+}
+
+// New は、'言語' (すべての投稿先) または '投稿先=言語' の指定から Localizer を生成します。
+// translationsPath を指定した場合は、言語ごとの辞書を記述した YAML ファイルを組み込みの辞書に重ねます。
+func New(specs []string, translationsPath string) (*Localizer, error) {
+	l := &Localizer{langs: make(map[string]string), dicts: make(map[string]Dictionary)}
+	for lang, dict := range Builtin {
+		l.dicts[lang] = dict
+	}
+	if translationsPath != "" {
+		custom, err := loadDictionaries(translationsPath)
+		if err != nil {
+			return nil, err
+		}
+		for lang, dict := range custom {
+			merged := make(Dictionary, len(l.dicts[lang])+len(dict))
+			for k, v := range l.dicts[lang] {
+				merged[k] = v
+			}
+			for k, v := range dict {
+				merged[k] = v
+			}
+			l.dicts[lang] = merged
+		}
+	}
+
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		destination, lang, ok := strings.Cut(spec, "=")
+		if !ok {
+			destination, lang = "", spec
+		}
+		destination, lang = strings.TrimSpace(destination), strings.TrimSpace(lang)
+		if _, known := l.dicts[lang]; !known {
+			return nil, fmt.Errorf("見出しの言語 '%s' の辞書がありません (組み込み: %s。--heading-translations で追加できます)", lang, strings.Join(Langs(), ", "))
+		}
+		if destination == "" {
+			l.defaultLang = lang
+			continue
+		}
+		l.langs[destination] = lang
+	}
+	return l, nil
+}
+
+// Lang は投稿先の見出しの言語を返します。指定がない場合は DefaultLang を返します。
+func (l *Localizer) Lang(destination string) string {
+	if l == nil {
+		return DefaultLang
+	}
+	if lang, ok := l.langs[destination]; ok {
+		return lang
+	}
+	if l.defaultLang != "" {
+		return l.defaultLang
+	}
+	return DefaultLang
+}
+
+// Localize は、markdown の見出しを投稿先の言語に翻訳します。
+func (l *Localizer) Localize(destination, markdown string) string {
+	if l == nil {
+		return markdown
+	}
+	return Translate(markdown, l.dicts[l.Lang(destination)])
+}
+
+// Text は、チャットのメッセージのタイトルなど、Markdown の見出しではない1行の文言を投稿先の言語に翻訳します。
+func (l *Localizer) Text(destination, text string) string {
+	if l == nil {
+		return text
+	}
+	dict := l.dicts[l.Lang(destination)]
+	if len(dict) == 0 {
+		return text
+	}
+	return newReplacer(dict).Replace(text)
+}
+
+// SetDefaultLang は、--heading-lang ですべての投稿先の言語が指定されていない場合に、その言語を lang にします。
+// lang の辞書がない場合はエラーを返し、言語は変更しません。
+func (l *Localizer) SetDefaultLang(lang string) error {
+	if _, known := l.dicts[lang]; !known {
+		return fmt.Errorf("見出しの言語 '%s' の辞書がありません (組み込み: %s。--heading-translations で追加できます)", lang, strings.Join(Langs(), ", "))
+	}
+	if l.defaultLang == "" {
+		l.defaultLang = lang
+	}
+	return nil
+}
+
+// loadDictionaries は、言語ごとの辞書を記述した YAML ファイルを読み込みます。
+func loadDictionaries(path string) (map[string]Dictionary, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("見出しの辞書が見つかりません: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("見出しの辞書の読み込みに失敗しました (%s): %w", path, err)
+	}
+	var dicts map[string]Dictionary
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&dicts); err != nil {
+		return nil, fmt.Errorf("見出しの辞書の解析に失敗しました (%s): %w", path, err)
+	}
+	return dicts, nil
+}
@@ -0,0 +1,6 @@
+//go:build postgres
+
+package history
+
+// PostgreSQL の履歴の保存先 ('postgres://...') で使用するドライバです。
+import _ "github.com/jackc/pgx/v5/stdlib"
@@ -0,0 +1,6 @@
+//go:build sqlite
+
+package history
+
+// SQLite の履歴の保存先 ('sqlite://パス') で使用するドライバです。cgo を使用しない実装を組み込みます。
+import _ "modernc.org/sqlite"
@@ -0,0 +1,194 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"git-gemini-reviewer-go/internal/pkg/filelock"
+)
+
+// lockSuffix は、履歴ファイルと同じディレクトリに置くロックファイルの接尾辞です。
+// 移行では履歴ファイル自体を置き換えるため、ロックは置き換えの対象にならない別のファイルで取得します。
+const lockSuffix = ".lock"
+
+// fileBackend は、ローカルのファイルに JSON Lines 形式で追記する backend です。
+// 共有ボリューム上の同じファイルを複数のプロセスが使用しても、ファイルロックで読み書きを直列化します。
+type fileBackend struct {
+	path string
+}
+
+// withLock は、ロックファイルに排他ロック (shared が true の場合は共有ロック) を取得して fn を実行します。
+// ファイルロックをサポートしないプラットフォームでは、ロックせずに実行します。
+func (b *fileBackend) withLock(shared bool, fn func() error) error {
+	if err := os.MkdirAll(filepath.Dir(b.path), 0755); err != nil {
+		return fmt.Errorf("履歴保存先ディレクトリの作成に失敗しました: %w", err)
+	}
+	lock, err := os.OpenFile(b.path+lockSuffix, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("履歴のロックファイルのオープンに失敗しました: %w", err)
+	}
+	defer lock.Close()
+
+	acquire := filelock.Lock
+	if shared {
+		acquire = filelock.RLock
+	}
+	switch err := acquire(lock); {
+	case errors.Is(err, filelock.ErrUnsupported):
+		slog.Debug("ファイルロックをサポートしないため、ロックせずに履歴にアクセスします。", "path", b.path)
+	case err != nil:
+		return fmt.Errorf("履歴のロックの取得に失敗しました: %w", err)
+	default:
+		defer filelock.Unlock(lock)
+	}
+	return fn()
+}
+
+// append は履歴ファイルに1行追記します。
+// 書き込みの途中で終了したプロセスが改行のない行を残していた場合は、改行を補ってから追記し、新しい行を壊さないようにします。
+func (b *fileBackend) append(line []byte) error {
+	return b.withLock(false, func() error {
+		f, err := os.OpenFile(b.path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0644)
+		if err != nil {
+			return fmt.Errorf("履歴ファイルのオープンに失敗しました: %w", err)
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil {
+			return fmt.Errorf("履歴ファイルの情報の取得に失敗しました: %w", err)
+		}
+		record := append(line, '\n')
+		if size := info.Size(); size > 0 {
+			last := make([]byte, 1)
+			if _, err := f.ReadAt(last, size-1); err != nil {
+				return fmt.Errorf("履歴ファイルの読み込みに失敗しました: %w", err)
+			}
+			if last[0] != '\n' {
+				slog.Warn("履歴ファイルの末尾の行が途中で終わっているため、改行を補って追記します。", "path", b.path)
+				record = append([]byte{'\n'}, record...)
+			}
+		}
+		if _, err := f.Write(record); err != nil {
+			return fmt.Errorf("履歴の書き込みに失敗しました: %w", err)
+		}
+		return nil
+	})
+}
+
+// lines は履歴ファイルのすべての行を返します。ファイルがない場合は空を返します。
+func (b *fileBackend) lines() ([][]byte, error) {
+	var lines [][]byte
+	err := b.withLock(true, func() error {
+		f, err := os.Open(b.path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("履歴ファイルのオープンに失敗しました: %w", err)
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		// レビュー結果の全文を含むため、行の上限を既定値より大きくします
+		scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			lines = append(lines, bytes.Clone(scanner.Bytes()))
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("履歴ファイルの読み込みに失敗しました: %w", err)
+		}
+		return nil
+	})
+	return lines, err
+}
+
+// rewrite は履歴ファイルの行を書き換えます。
+// 書き換えは一時ファイルへの書き込みと置き換えで行い、途中で失敗しても元のファイルを壊しません。
+func (b *fileBackend) rewrite(fn func(line []byte) ([]byte, bool, error)) (int, error) {
+	return b.edit(fn)
+}
+
+// remove は fn が true を返した行を履歴ファイルから削除します。rewrite と同様に一時ファイルを介して置き換えます。
+func (b *fileBackend) remove(fn func(line []byte) (bool, error)) (int, error) {
+	return b.edit(func(line []byte) ([]byte, bool, error) {
+		drop, err := fn(line)
+		return nil, drop, err
+	})
+}
+
+// edit は各行に fn を適用し、fn が変更を返した行を置き換えます。置き換える内容が nil の場合は行を削除します。
+func (b *fileBackend) edit(fn func(line []byte) ([]byte, bool, error)) (int, error) {
+	var edited int
+	err := b.withLock(false, func() error {
+		f, err := os.Open(b.path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("履歴ファイルのオープンに失敗しました: %w", err)
+		}
+		data, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("履歴ファイルの読み込みに失敗しました: %w", err)
+		}
+
+		var out []byte
+		for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+			if len(bytes.TrimSpace(line)) == 0 {
+				out = append(out, line...)
+				continue
+			}
+			replaced, changed, err := fn(bytes.TrimRight(line, "\n"))
+			if err != nil {
+				return err
+			}
+			if !changed {
+				out = append(out, line...)
+				continue
+			}
+			if replaced != nil {
+				out = append(append(out, replaced...), '\n')
+			}
+			edited++
+		}
+		if edited == 0 {
+			return nil
+		}
+		return replaceFile(b.path, out)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return edited, nil
+}
+
+// replaceFile は、一時ファイルに data を書き込んでから path を置き換えます。
+func replaceFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".rewrite-*")
+	if err != nil {
+		return fmt.Errorf("書き換え用の一時ファイルの作成に失敗しました: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("書き換え後の履歴の書き込みに失敗しました: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("書き換え後の履歴の書き込みに失敗しました: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("書き換え後の履歴ファイルの権限の設定に失敗しました: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("履歴ファイルの置き換えに失敗しました: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package history
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	// gcsScheme は GCS の保存先を表すURLのスキームです。
+	gcsScheme = "gs://"
+	// gcsTimeout は GCS への1回の操作 (追記・一覧の読み込み・移行・削除) に許容する時間です。
+	gcsTimeout = 5 * time.Minute
+	// gcsReadConcurrency は一覧の読み込みで並行して取得するオブジェクトの数です。
+	gcsReadConcurrency = 16
+	// gcsObjectTimeFormat はオブジェクト名の先頭に付ける記録日時の書式です。辞書順が記録順になります。
+	gcsObjectTimeFormat = "20060102T150405.000000000Z"
+)
+
+// gcsBackend は、1行を1つのオブジェクトとして GCS に保存する backend です。
+// 追記は新しいオブジェクトの作成のみで行い、既存のオブジェクトを読み書きしないため、
+// 同じプレフィックスを複数の CI ランナーが共有しても記録が失われません。
+type gcsBackend struct {
+	bucket string
+	// prefix はオブジェクト名の接頭辞です (末尾の '/' を含みます。バケット直下の場合は空文字列)。
+	prefix string
+}
+
+// newGCSBackend は 'gs://バケット/プレフィックス' を保存先とする gcsBackend を返します。
+func newGCSBackend(uri string) *gcsBackend {
+	bucket, prefix, _ := strings.Cut(strings.TrimPrefix(uri, gcsScheme), "/")
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		prefix += "/"
+	}
+	return &gcsBackend{bucket: bucket, prefix: prefix}
+}
+
+// withClient は GCS のクライアントを生成して fn を実行します。
+func (b *gcsBackend) withClient(fn func(ctx context.Context, bucket *storage.BucketHandle) error) error {
+	if b.bucket == "" {
+		return fmt.Errorf("履歴の保存先の GCS バケットが指定されていません ('gs://バケット/プレフィックス' の形式で指定してください)")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), gcsTimeout)
+	defer cancel()
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return fmt.Errorf("GCS クライアントの初期化に失敗しました: %w", err)
+	}
+	defer client.Close()
+	return fn(ctx, client.Bucket(b.bucket))
+}
+
+// append は、記録日時とランダムな接尾辞からなる新しいオブジェクトとして1行を保存します。
+// 同名のオブジェクトが存在する場合は上書きせずにエラーとします。
+func (b *gcsBackend) append(line []byte) error {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("履歴のオブジェクト名の生成に失敗しました: %w", err)
+	}
+	name := fmt.Sprintf("%s%s-%s.json", b.prefix, time.Now().UTC().Format(gcsObjectTimeFormat), hex.EncodeToString(suffix))
+	return b.withClient(func(ctx context.Context, bucket *storage.BucketHandle) error {
+		if err := writeObject(ctx, bucket.Object(name).If(storage.Conditions{DoesNotExist: true}), line); err != nil {
+			return fmt.Errorf("履歴の書き込みに失敗しました (gs://%s/%s): %w", b.bucket, name, err)
+		}
+		return nil
+	})
+}
+
+// gcsObject はプレフィックス配下の履歴のオブジェクトです。
+type gcsObject struct {
+	name       string
+	generation int64
+	data       []byte
+}
+
+// lines はプレフィックス配下のすべてのオブジェクトを、記録順 (オブジェクト名の昇順) に返します。
+func (b *gcsBackend) lines() ([][]byte, error) {
+	var lines [][]byte
+	err := b.withClient(func(ctx context.Context, bucket *storage.BucketHandle) error {
+		objects, err := b.readAll(ctx, bucket)
+		if err != nil {
+			return err
+		}
+		for _, o := range objects {
+			lines = append(lines, o.data)
+		}
+		return nil
+	})
+	return lines, err
+}
+
+// rewrite は、fn が変更を返したオブジェクトを書き換えます。
+// 読み込んだ後に他のプロセスが更新したオブジェクトは上書きせず、エラーとします。
+func (b *gcsBackend) rewrite(fn func(line []byte) ([]byte, bool, error)) (int, error) {
+	var rewritten int
+	err := b.withClient(func(ctx context.Context, bucket *storage.BucketHandle) error {
+		objects, err := b.readAll(ctx, bucket)
+		if err != nil {
+			return err
+		}
+		for _, o := range objects {
+			replaced, changed, err := fn(bytes.TrimSpace(o.data))
+			if err != nil {
+				return err
+			}
+			if !changed {
+				continue
+			}
+			obj := bucket.Object(o.name).If(storage.Conditions{GenerationMatch: o.generation})
+			if err := writeObject(ctx, obj, replaced); err != nil {
+				return fmt.Errorf("移行後の履歴の書き込みに失敗しました (gs://%s/%s): %w", b.bucket, o.name, err)
+			}
+			rewritten++
+		}
+		return nil
+	})
+	return rewritten, err
+}
+
+// remove は、fn が true を返したオブジェクトを削除します。
+// 読み込んだ後に他のプロセスが更新したオブジェクトは削除せず、エラーとします。既に削除されたオブジェクトは読み飛ばします。
+func (b *gcsBackend) remove(fn func(line []byte) (bool, error)) (int, error) {
+	var removed int
+	err := b.withClient(func(ctx context.Context, bucket *storage.BucketHandle) error {
+		objects, err := b.readAll(ctx, bucket)
+		if err != nil {
+			return err
+		}
+		for _, o := range objects {
+			drop, err := fn(bytes.TrimSpace(o.data))
+			if err != nil {
+				return err
+			}
+			if !drop {
+				continue
+			}
+			err = bucket.Object(o.name).If(storage.Conditions{GenerationMatch: o.generation}).Delete(ctx)
+			switch {
+			case errors.Is(err, storage.ErrObjectNotExist):
+				continue
+			case err != nil:
+				return fmt.Errorf("履歴の削除に失敗しました (gs://%s/%s): %w", b.bucket, o.name, err)
+			}
+			removed++
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// readAll はプレフィックス配下の履歴のオブジェクトを一覧し、並行して内容を読み込みます。
+// 一覧の取得後に削除されたオブジェクトは読み飛ばします。
+func (b *gcsBackend) readAll(ctx context.Context, bucket *storage.BucketHandle) ([]gcsObject, error) {
+	var objects []gcsObject
+	it := bucket.Objects(ctx, &storage.Query{Prefix: b.prefix})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("履歴のオブジェクトの一覧の取得に失敗しました (gs://%s/%s): %w", b.bucket, b.prefix, err)
+		}
+		if !strings.HasSuffix(attrs.Name, ".json") {
+			continue
+		}
+		objects = append(objects, gcsObject{name: attrs.Name})
+	}
+
+	var (
+		wg   sync.WaitGroup
+		sem  = make(chan struct{}, gcsReadConcurrency)
+		errs = make([]error, len(objects))
+	)
+	for i := range objects {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(o *gcsObject, errp *error) {
+			defer func() { <-sem; wg.Done() }()
+			o.data, o.generation, *errp = readObject(ctx, bucket.Object(o.name))
+		}(&objects[i], &errs[i])
+	}
+	wg.Wait()
+
+	result := objects[:0]
+	for i, o := range objects {
+		switch err := errs[i]; {
+		case errors.Is(err, storage.ErrObjectNotExist):
+		case err != nil:
+			return nil, fmt.Errorf("履歴の読み込みに失敗しました (gs://%s/%s): %w", b.bucket, o.name, err)
+		default:
+			result = append(result, o)
+		}
+	}
+	return result, nil
+}
+
+// readObject はオブジェクトの内容と、読み込んだ世代を返します。
+func readObject(ctx context.Context, obj *storage.ObjectHandle) ([]byte, int64, error) {
+	r, err := obj.NewReader(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	return data, r.Attrs.Generation, err
+}
+
+// writeObject はオブジェクトに data を書き込みます。
+func writeObject(ctx context.Context, obj *storage.ObjectHandle, data []byte) error {
+	w := obj.NewWriter(ctx)
+	w.ContentType = "application/json"
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
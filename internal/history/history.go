@@ -0,0 +1,322 @@
+// Package history は、レビューの実行履歴と人による承認判断を記録する履歴ストアを提供します。
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"git-gemini-reviewer-go/internal/findings"
+	"git-gemini-reviewer-go/internal/schema"
+)
+
+// Outcome は人による承認判断の結果です。
+type Outcome string
+
+const (
+	// OutcomeApproved はレビュー結果を確認したうえで承認されたことを表します。
+	OutcomeApproved Outcome = "approved"
+	// OutcomeChangesRequested は修正が依頼されたことを表します。
+	OutcomeChangesRequested Outcome = "changes_requested"
+)
+
+// ParseOutcome は文字列を Outcome に変換します。
+func ParseOutcome(s string) (Outcome, error) {
+	switch o := Outcome(strings.ToLower(strings.TrimSpace(s))); o {
+	case OutcomeApproved, OutcomeChangesRequested:
+		return o, nil
+	}
+	return "", fmt.Errorf("不明な承認判断です: '%s' ('%s' または '%s' を指定してください)", s, OutcomeApproved, OutcomeChangesRequested)
+}
+
+// Label は承認判断の表示名を返します。
+func (o Outcome) Label() string {
+	switch o {
+	case OutcomeApproved:
+		return "承認"
+	case OutcomeChangesRequested:
+		return "修正依頼"
+	}
+	return string(o)
+}
+
+// Decision は、AIレビューに対する人の承認判断です。
+type Decision struct {
+	// SchemaVersion は記録時のスキーマのバージョン (schema.Version) です。
+	SchemaVersion int     `json:"schema_version"`
+	ReviewID      string  `json:"review_id"`
+	Outcome       Outcome `json:"outcome"`
+	// Decider は判断したユーザーの識別子です (例: Slack のユーザーID)。
+	Decider   string    `json:"decider"`
+	Source    string    `json:"source"`
+	DecidedAt time.Time `json:"decided_at"`
+}
+
+// Review は1回のレビュー実行の記録です。Decision には最新の承認判断が設定されます。
+type Review struct {
+	// SchemaVersion は記録時のスキーマのバージョン (schema.Version) です。読み込み時に現在のバージョンへ移行します。
+	SchemaVersion int    `json:"schema_version"`
+	ReviewID      string `json:"review_id"`
+	RepoURL       string `json:"repo_url"`
+	BaseBranch    string `json:"base_branch"`
+	FeatureBranch string `json:"feature_branch"`
+	Mode          string `json:"mode"`
+	Model         string `json:"model"`
+	// PromptVariant はプロンプトの A/B 実験で割り当てた派生 ('A' または 'B') です。
+	PromptVariant string `json:"prompt_variant,omitempty"`
+	// BaseCommit と HeadCommit はレビューした差分の両端のコミットの SHA です。
+	BaseCommit string `json:"base_commit,omitempty"`
+	HeadCommit string `json:"head_commit,omitempty"`
+	// InputTokens と OutputTokens は AI に送信・受信したトークン数 (概算を含みます) です。
+	InputTokens  int    `json:"input_tokens,omitempty"`
+	OutputTokens int    `json:"output_tokens,omitempty"`
+	Verdict      string `json:"verdict"`
+	// CostUSD は料金表から計算した推定費用 (USD) です。料金が不明なモデルの場合は nil です。
+	CostUSD *float64 `json:"cost_usd,omitempty"`
+	// Findings は指摘のカテゴリごとの件数です。
+	Findings   map[findings.Category]int `json:"findings,omitempty"`
+	Result     string                    `json:"result"`
+	ReviewedAt time.Time                 `json:"reviewed_at"`
+	Decision   *Decision                 `json:"decision,omitempty"`
+}
+
+// FindingCounts は指摘のカテゴリごとの件数を返します。
+// カテゴリ体系の導入前に記録されたレビューは、結果の本文から集計します。
+func (r Review) FindingCounts() map[findings.Category]int {
+	if r.Findings != nil {
+		return r.Findings
+	}
+	return findings.Count(r.Result)
+}
+
+// 履歴ファイルの各行の種別です。
+const (
+	kindReview   = "review"
+	kindDecision = "decision"
+)
+
+// entry は履歴ファイルの1行です。レビューと承認判断を同じファイルに時系列で追記します。
+type entry struct {
+	Kind     string    `json:"kind"`
+	Review   *Review   `json:"review,omitempty"`
+	Decision *Decision `json:"decision,omitempty"`
+}
+
+// backend は履歴の各行 (JSON) を保存する先です。
+// 複数のプロセスが同じ保存先を共有しても、行の欠落や混在が起きないように実装します。
+type backend interface {
+	// append は1行を追記します。
+	append(line []byte) error
+	// lines は記録された順にすべての行を返します。
+	lines() ([][]byte, error)
+	// rewrite は各行に fn を適用し、fn が変更を返した行を書き換えて、書き換えた行数を返します。
+	rewrite(fn func(line []byte) ([]byte, bool, error)) (int, error)
+	// remove は各行に fn を適用し、fn が true を返した行を削除して、削除した行数を返します。
+	remove(fn func(line []byte) (bool, error)) (int, error)
+}
+
+// Store はレビュー履歴を JSON Lines 形式で追記保存します。
+// 保存先はローカルのファイル、'gs://バケット/プレフィックス' の GCS、または SQLite / PostgreSQL のデータベースです。
+type Store struct {
+	backend backend
+}
+
+// NewStore は指定された保存先の Store を生成します。
+// 'gs://' で始まる場合は GCS に1件ごとのオブジェクトとして保存し、'sqlite://' / 'postgres://' で始まる場合はデータベースのテーブルに1件ごとの行として保存します。
+// それ以外はファイルロックで排他制御したファイルに追記します。
+func NewStore(path string) *Store {
+	switch {
+	case strings.HasPrefix(path, gcsScheme):
+		return &Store{backend: newGCSBackend(path)}
+	case isSQLURL(path):
+		return &Store{backend: newSQLBackend(path)}
+	}
+	return &Store{backend: &fileBackend{path: path}}
+}
+
+// DefaultPath は履歴ファイルのデフォルトパスを返します。
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "history.jsonl"
+	}
+	return filepath.Join(home, ".git-gemini-reviewer", "history.jsonl")
+}
+
+// RecordReview はレビューの実行結果を1件追記します。
+func (s *Store) RecordReview(r Review) error {
+	if r.ReviewID == "" {
+		return errors.New("レビューIDが空のレビューは記録できません")
+	}
+	if r.ReviewedAt.IsZero() {
+		r.ReviewedAt = time.Now()
+	}
+	r.Decision = nil
+	r.SchemaVersion = schema.Version
+	return s.append(entry{Kind: kindReview, Review: &r})
+}
+
+// RecordDecision は人による承認判断を1件追記します。同じレビューに複数回判断した場合は最新の判断が有効です。
+func (s *Store) RecordDecision(d Decision) error {
+	if d.ReviewID == "" {
+		return errors.New("レビューIDが空の承認判断は記録できません")
+	}
+	if d.DecidedAt.IsZero() {
+		d.DecidedAt = time.Now()
+	}
+	d.SchemaVersion = schema.Version
+	return s.append(entry{Kind: kindDecision, Decision: &d})
+}
+
+// List は記録済みのレビューを実行日時の昇順で返します。since が非ゼロの場合は、それ以降のレビューのみを返します。
+func (s *Store) List(since time.Time) ([]Review, error) {
+	lines, err := s.backend.lines()
+	if err != nil {
+		return nil, err
+	}
+
+	reviews := make(map[string]*Review)
+	decisions := make(map[string]Decision)
+	for _, line := range lines {
+		var e entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			continue
+		}
+		if _, err := e.upgrade(); err != nil {
+			return nil, err
+		}
+		switch {
+		case e.Kind == kindReview && e.Review != nil:
+			reviews[e.Review.ReviewID] = e.Review
+		case e.Kind == kindDecision && e.Decision != nil:
+			decisions[e.Decision.ReviewID] = *e.Decision
+		}
+	}
+
+	result := make([]Review, 0, len(reviews))
+	for id, r := range reviews {
+		if !since.IsZero() && r.ReviewedAt.Before(since) {
+			continue
+		}
+		if d, ok := decisions[id]; ok {
+			r.Decision = &d
+		}
+		result = append(result, *r)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ReviewedAt.Before(result[j].ReviewedAt) })
+	return result, nil
+}
+
+// Get は、レビューIDのレビューを返します。記録がない場合は nil を返します。
+func (s *Store) Get(reviewID string) (*Review, error) {
+	reviews, err := s.List(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range reviews {
+		if reviews[i].ReviewID == reviewID {
+			return &reviews[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// Latest は、同じリポジトリとフィーチャーブランチの直近のレビューを返します。記録がない場合は nil を返します。
+func (s *Store) Latest(repoURL, featureBranch string) (*Review, error) {
+	reviews, err := s.List(time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	for i := len(reviews) - 1; i >= 0; i-- {
+		if reviews[i].RepoURL == repoURL && reviews[i].FeatureBranch == featureBranch {
+			return &reviews[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// version は行に記録されたスキーマのバージョンです。schema_version を含まない行は1とみなします。
+func (e entry) version() int {
+	switch {
+	case e.Review != nil:
+		return schema.Normalize(e.Review.SchemaVersion)
+	case e.Decision != nil:
+		return schema.Normalize(e.Decision.SchemaVersion)
+	}
+	return schema.Version
+}
+
+// upgrade は、古いスキーマのバージョンで記録された行を現在のバージョンの形式に変換し、変換したかを返します。
+// このバージョンより新しいスキーマの行はエラーとします。
+func (e *entry) upgrade() (bool, error) {
+	v := e.version()
+	if err := schema.Check(v); err != nil {
+		return false, err
+	}
+	if v == schema.Version {
+		return false, nil
+	}
+	// 1 → 2: カテゴリ体系の導入前のレビューは、指摘の件数を結果の本文から集計する
+	if v < 2 && e.Review != nil && e.Review.Findings == nil {
+		e.Review.Findings = findings.Count(e.Review.Result)
+	}
+	if e.Review != nil {
+		e.Review.SchemaVersion = schema.Version
+	}
+	if e.Decision != nil {
+		e.Decision.SchemaVersion = schema.Version
+	}
+	return true, nil
+}
+
+// Migrate は、履歴の古いスキーマのバージョンの行を現在のバージョンの形式に書き換え、書き換えた行数を返します。
+// 解析できない行はそのまま残します。
+func (s *Store) Migrate() (int, error) {
+	return s.backend.rewrite(func(line []byte) ([]byte, bool, error) {
+		var e entry
+		if json.Unmarshal(line, &e) != nil {
+			return nil, false, nil
+		}
+		changed, err := e.upgrade()
+		if err != nil || !changed {
+			return nil, false, err
+		}
+		upgraded, err := json.Marshal(e)
+		if err != nil {
+			return nil, false, fmt.Errorf("履歴のエンコードに失敗しました: %w", err)
+		}
+		return upgraded, true, nil
+	})
+}
+
+// Prune は、before より前に実行したレビューと、before より前に記録した承認判断を履歴から削除し、削除した行数を返します。
+// 保持期間の経過したレビュー結果の全文を残さないために使用します。解析できない行はそのまま残します。
+func (s *Store) Prune(before time.Time) (int, error) {
+	return s.backend.remove(func(line []byte) (bool, error) {
+		var e entry
+		if json.Unmarshal(line, &e) != nil {
+			return false, nil
+		}
+		switch {
+		case e.Review != nil:
+			return e.Review.ReviewedAt.Before(before), nil
+		case e.Decision != nil:
+			return e.Decision.DecidedAt.Before(before), nil
+		}
+		return false, nil
+	})
+}
+
+// append は履歴に1行追記します。
+func (s *Store) append(e entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("履歴のエンコードに失敗しました: %w", err)
+	}
+	return s.backend.append(line)
+}
@@ -0,0 +1,251 @@
+package history
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// sqliteScheme は SQLite のデータベースファイルの保存先を表すURLのスキームです ('sqlite://パス')。
+	sqliteScheme = "sqlite://"
+	// sqlTimeout はデータベースへの1回の操作 (追記・一覧の読み込み・移行・削除) に許容する時間です。
+	sqlTimeout = 5 * time.Minute
+	// sqliteBusyTimeout は、他のプロセスが書き込み中の SQLite のデータベースのロックの解放を待つ時間 (ミリ秒) です。
+	sqliteBusyTimeout = 10000
+)
+
+// sqlDrivers は保存先のスキームに対応する database/sql のドライバ名と、ドライバを組み込むビルドタグです。
+// ドライバはビルドタグを指定した場合のみ組み込みます (driver_sqlite.go、driver_postgres.go を参照)。
+var sqlDrivers = map[string]struct{ name, tag string }{
+	"sqlite":   {name: "sqlite", tag: "sqlite"},
+	"postgres": {name: "pgx", tag: "postgres"},
+}
+
+// isSQLURL は保存先がデータベースのURLかを返します。
+func isSQLURL(path string) bool {
+	return strings.HasPrefix(path, sqliteScheme) || strings.HasPrefix(path, "postgres://") || strings.HasPrefix(path, "postgresql://")
+}
+
+// sqlBackend は、1行をテーブルの1レコードとして SQLite または PostgreSQL に保存する backend です。
+// レコードには行の JSON に加えて、SQL で絞り込めるよう種別・レビューID・リポジトリ・フィーチャーブランチ・記録日時を保存します。
+type sqlBackend struct {
+	// kind は sqlDrivers のキー ('sqlite' または 'postgres') です。
+	kind string
+	dsn  string
+}
+
+// newSQLBackend は 'sqlite://パス' または 'postgres://...' を保存先とする sqlBackend を返します。
+func newSQLBackend(uri string) *sqlBackend {
+	if path, ok := strings.CutPrefix(uri, sqliteScheme); ok {
+		// 複数のプロセスが同時に記録しても SQLITE_BUSY で失敗しないよう、ロックの解放を待つ
+		if !strings.Contains(path, "busy_timeout") {
+			sep := "?"
+			if strings.Contains(path, "?") {
+				sep = "&"
+			}
+			path += sep + "_pragma=busy_timeout(" + strconv.Itoa(sqliteBusyTimeout) + ")"
+		}
+		return &sqlBackend{kind: "sqlite", dsn: path}
+	}
+	return &sqlBackend{kind: "postgres", dsn: uri}
+}
+
+// schema はテーブルとインデックスを作成する DDL です。
+func (b *sqlBackend) schema() []string {
+	id, recordedAt := "INTEGER PRIMARY KEY AUTOINCREMENT", "TIMESTAMP"
+	if b.kind == "postgres" {
+		id, recordedAt = "BIGSERIAL PRIMARY KEY", "TIMESTAMPTZ"
+	}
+	return []string{
+		`CREATE TABLE IF NOT EXISTS review_history (
+	id ` + id + `,
+	kind TEXT NOT NULL,
+	review_id TEXT NOT NULL,
+	repo_url TEXT NOT NULL DEFAULT '',
+	feature_branch TEXT NOT NULL DEFAULT '',
+	recorded_at ` + recordedAt + ` NOT NULL,
+	line TEXT NOT NULL
+)`,
+		`CREATE INDEX IF NOT EXISTS review_history_review_id ON review_history (review_id)`,
+		`CREATE INDEX IF NOT EXISTS review_history_repo_branch ON review_history (repo_url, feature_branch)`,
+	}
+}
+
+// bind は '?' のプレースホルダを、PostgreSQL の場合は '$1' 形式に置き換えます。
+func (b *sqlBackend) bind(query string) string {
+	if b.kind != "postgres" {
+		return query
+	}
+	var sb strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			sb.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// withDB はデータベースに接続し、テーブルがなければ作成してから fn を実行します。
+func (b *sqlBackend) withDB(fn func(ctx context.Context, db *sql.DB) error) error {
+	driver := sqlDrivers[b.kind]
+	if !slices.Contains(sql.Drivers(), driver.name) {
+		return fmt.Errorf("このバイナリは %s の履歴の保存先に対応していません ('-tags %s' を指定してビルドしてください)", b.kind, driver.tag)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), sqlTimeout)
+	defer cancel()
+	db, err := sql.Open(driver.name, b.dsn)
+	if err != nil {
+		return fmt.Errorf("履歴のデータベースへの接続に失敗しました: %w", err)
+	}
+	defer db.Close()
+	for _, ddl := range b.schema() {
+		if _, err := db.ExecContext(ctx, ddl); err != nil {
+			return fmt.Errorf("履歴のテーブルの作成に失敗しました: %w", err)
+		}
+	}
+	return fn(ctx, db)
+}
+
+// append は1行をレコードとして挿入します。
+func (b *sqlBackend) append(line []byte) error {
+	var e entry
+	if err := json.Unmarshal(line, &e); err != nil {
+		return fmt.Errorf("履歴のデコードに失敗しました: %w", err)
+	}
+	var reviewID, repoURL, featureBranch string
+	switch {
+	case e.Review != nil:
+		reviewID, repoURL, featureBranch = e.Review.ReviewID, e.Review.RepoURL, e.Review.FeatureBranch
+	case e.Decision != nil:
+		reviewID = e.Decision.ReviewID
+	}
+	return b.withDB(func(ctx context.Context, db *sql.DB) error {
+		_, err := db.ExecContext(ctx,
+			b.bind(`INSERT INTO review_history (kind, review_id, repo_url, feature_branch, recorded_at, line) VALUES (?, ?, ?, ?, ?, ?)`),
+			e.Kind, reviewID, repoURL, featureBranch, time.Now().UTC(), string(line))
+		if err != nil {
+			return fmt.Errorf("履歴の書き込みに失敗しました: %w", err)
+		}
+		return nil
+	})
+}
+
+// sqlRecord はテーブルの1レコードです。
+type sqlRecord struct {
+	id   int64
+	line []byte
+}
+
+// records は記録順 (id の昇順) にすべてのレコードを返します。
+func (b *sqlBackend) records(ctx context.Context, q interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}) ([]sqlRecord, error) {
+	rows, err := q.QueryContext(ctx, `SELECT id, line FROM review_history ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("履歴の読み込みに失敗しました: %w", err)
+	}
+	defer rows.Close()
+	var records []sqlRecord
+	for rows.Next() {
+		var r sqlRecord
+		var line string
+		if err := rows.Scan(&r.id, &line); err != nil {
+			return nil, fmt.Errorf("履歴の読み込みに失敗しました: %w", err)
+		}
+		r.line = []byte(line)
+		records = append(records, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("履歴の読み込みに失敗しました: %w", err)
+	}
+	return records, nil
+}
+
+// lines は記録順にすべての行を返します。
+func (b *sqlBackend) lines() ([][]byte, error) {
+	var lines [][]byte
+	err := b.withDB(func(ctx context.Context, db *sql.DB) error {
+		records, err := b.records(ctx, db)
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			lines = append(lines, r.line)
+		}
+		return nil
+	})
+	return lines, err
+}
+
+// rewrite は、fn が変更を返したレコードの行を1つのトランザクションで書き換えます。
+func (b *sqlBackend) rewrite(fn func(line []byte) ([]byte, bool, error)) (int, error) {
+	return b.edit(func(ctx context.Context, tx *sql.Tx, r sqlRecord) (bool, error) {
+		replaced, changed, err := fn(r.line)
+		if err != nil || !changed {
+			return false, err
+		}
+		if _, err := tx.ExecContext(ctx, b.bind(`UPDATE review_history SET line = ? WHERE id = ?`), string(replaced), r.id); err != nil {
+			return false, fmt.Errorf("移行後の履歴の書き込みに失敗しました: %w", err)
+		}
+		return true, nil
+	})
+}
+
+// remove は、fn が true を返したレコードを1つのトランザクションで削除します。
+func (b *sqlBackend) remove(fn func(line []byte) (bool, error)) (int, error) {
+	return b.edit(func(ctx context.Context, tx *sql.Tx, r sqlRecord) (bool, error) {
+		drop, err := fn(r.line)
+		if err != nil || !drop {
+			return false, err
+		}
+		if _, err := tx.ExecContext(ctx, b.bind(`DELETE FROM review_history WHERE id = ?`), r.id); err != nil {
+			return false, fmt.Errorf("履歴の削除に失敗しました: %w", err)
+		}
+		return true, nil
+	})
+}
+
+// edit はトランザクション内ですべてのレコードを読み込み、各レコードに fn を適用して、fn が変更したレコードの数を返します。
+// fn がエラーを返した場合はロールバックし、どのレコードも変更しません。
+func (b *sqlBackend) edit(fn func(ctx context.Context, tx *sql.Tx, r sqlRecord) (bool, error)) (int, error) {
+	var edited int
+	err := b.withDB(func(ctx context.Context, db *sql.DB) error {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("履歴のトランザクションの開始に失敗しました: %w", err)
+		}
+		defer tx.Rollback()
+
+		records, err := b.records(ctx, tx)
+		if err != nil {
+			return err
+		}
+		for _, r := range records {
+			changed, err := fn(ctx, tx, r)
+			if err != nil {
+				return err
+			}
+			if changed {
+				edited++
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("履歴のトランザクションのコミットに失敗しました: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return edited, nil
+}
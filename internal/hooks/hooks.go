@@ -0,0 +1,206 @@
+// Package hooks は、レビューパイプラインの決められた段階で利用者が指定したコマンドや Go のコールバックを実行します。
+// フックにはレビューの文脈を JSON で渡し、ゲート (失敗させて中断)、結果の加工、外部への記録などに利用できます。
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"plugin"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stage はフックを実行するパイプラインの段階です。
+type Stage string
+
+const (
+	// PreDiff は差分を取得する前の段階です。フックが失敗した場合、レビューは実行しません。
+	PreDiff Stage = "pre-diff"
+	// PostReview はAIレビューが完了した直後の段階です。フックの標準出力でレビュー結果を置き換えられます。
+	PostReview Stage = "post-review"
+	// PrePost は投稿先に配信する直前の段階です。フックが失敗した場合、配信しません。
+	PrePost Stage = "pre-post"
+)
+
+// Stages はすべての段階を実行順に返します。
+func Stages() []Stage {
+	return []Stage{PreDiff, PostReview, PrePost}
+}
+
+// ParseStage は文字列を Stage に変換します。
+func ParseStage(s string) (Stage, error) {
+	for _, stage := range Stages() {
+		if string(stage) == s {
+			return stage, nil
+		}
+	}
+	return "", fmt.Errorf("不明なフックの段階です: '%s' ('pre-diff', 'post-review', 'pre-post' のいずれかを指定してください)", s)
+}
+
+// commandTimeout はフック1件に許容する実行時間です。
+const commandTimeout = 5 * time.Minute
+
+// pluginPrefix は、コマンドの代わりに Go プラグインを指定する場合の接頭辞です。
+const pluginPrefix = "plugin:"
+
+// Event はフックに JSON で渡すレビューの文脈です。
+type Event struct {
+	// SchemaVersion はイベントのスキーマのバージョン (schema.Version) です。
+	SchemaVersion int    `json:"schema_version"`
+	Stage         Stage  `json:"stage"`
+	ReviewID      string `json:"review_id"`
+	RepoURL       string `json:"repo_url"`
+	BaseBranch    string `json:"base_branch"`
+	FeatureBranch string `json:"feature_branch"`
+	Mode          string `json:"mode"`
+	Model         string `json:"model"`
+	// Destination は投稿先のコマンド名です (例: 'slack', 'post')。
+	Destination string `json:"destination"`
+	// Review はレビュー結果の Markdown です。pre-diff では空です。
+	Review string `json:"review,omitempty"`
+	// Verdict はレビュー結果から判定した判定値です。pre-diff では空です。
+	Verdict string `json:"verdict,omitempty"`
+}
+
+// Func は Go で実装するフックです。
+// 空でない文字列を返した場合、その内容でレビュー結果を置き換えます (post-review と pre-post のみ)。
+// エラーを返した場合、パイプラインを中断します。
+type Func func(ctx context.Context, event Event) (string, error)
+
+// Hook は1つの段階で実行するフックです。
+type Hook struct {
+	Stage Stage
+	// Name はログとエラーに表示するフックの名前 (コマンドまたはプラグインのパス) です。
+	Name string
+	fn   Func
+}
+
+// Parse は 'stage=command' または 'stage=plugin:path.so' 形式の指定を解析します。
+func Parse(spec string) (Hook, error) {
+	stageName, target, ok := strings.Cut(spec, "=")
+	target = strings.TrimSpace(target)
+	if !ok || target == "" {
+		return Hook{}, fmt.Errorf("フックの指定が不正です: '%s' ('段階=コマンド' の形式で指定してください)", spec)
+	}
+	stage, err := ParseStage(strings.TrimSpace(stageName))
+	if err != nil {
+		return Hook{}, err
+	}
+
+	if path, ok := strings.CutPrefix(target, pluginPrefix); ok {
+		fn, err := openPlugin(path)
+		if err != nil {
+			return Hook{}, err
+		}
+		return Hook{Stage: stage, Name: target, fn: fn}, nil
+	}
+	return Hook{Stage: stage, Name: target, fn: commandFunc(target)}, nil
+}
+
+// ParseAll は複数のフックの指定を解析し、登録済みの Go コールバックと合わせて返します。
+func ParseAll(specs []string) ([]Hook, error) {
+	hooks := registered()
+	for _, spec := range specs {
+		h, err := Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, h)
+	}
+	return hooks, nil
+}
+
+// Run は、指定した段階のフックを登録順に実行し、フックによる置き換えを反映したレビュー結果を返します。
+// いずれかのフックが失敗した場合、残りのフックは実行せずにエラーを返します。
+func Run(ctx context.Context, hooks []Hook, stage Stage, event Event) (string, error) {
+	event.Stage = stage
+	for _, h := range hooks {
+		if h.Stage != stage {
+			continue
+		}
+		slog.Info("フックを実行します。", "stage", stage, "hook", h.Name)
+		out, err := h.fn(ctx, event)
+		if err != nil {
+			return "", fmt.Errorf("%s フック '%s' が失敗しました: %w", stage, h.Name, err)
+		}
+		if stage != PreDiff && strings.TrimSpace(out) != "" {
+			slog.Info("フックの出力でレビュー結果を置き換えました。", "stage", stage, "hook", h.Name)
+			event.Review = out
+		}
+	}
+	return event.Review, nil
+}
+
+// commandFunc はシェルコマンドを実行するフックを返します。
+// イベントを JSON で標準入力に渡し、標準出力をレビュー結果の置き換えとして扱います。標準エラー出力はそのまま表示します。
+func commandFunc(command string) Func {
+	return func(ctx context.Context, event Event) (string, error) {
+		input, err := json.Marshal(event)
+		if err != nil {
+			return "", fmt.Errorf("フックに渡すイベントのエンコードに失敗しました: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+		defer cancel()
+
+		var stdout bytes.Buffer
+		c := exec.CommandContext(ctx, "sh", "-c", command)
+		c.Stdin = bytes.NewReader(input)
+		c.Stdout = &stdout
+		c.Stderr = os.Stderr
+		c.Env = append(os.Environ(),
+			"GEMINI_REVIEWER_HOOK_STAGE="+string(event.Stage),
+			"GEMINI_REVIEWER_REVIEW_ID="+event.ReviewID,
+		)
+		if err := c.Run(); err != nil {
+			return "", err
+		}
+		return stdout.String(), nil
+	}
+}
+
+// openPlugin は Go プラグインから 'Hook' という名前の Func を読み込みます。
+// プラグインは本ツールと同じバージョンの Go とこのパッケージで `go build -buildmode=plugin` によりビルドする必要があります。
+func openPlugin(path string) (Func, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("フックのプラグインの読み込みに失敗しました (%s): %w", path, err)
+	}
+	sym, err := p.Lookup("Hook")
+	if err != nil {
+		return nil, fmt.Errorf("プラグインに Hook 関数がありません (%s): %w", path, err)
+	}
+	switch fn := sym.(type) {
+	case func(context.Context, Event) (string, error):
+		return fn, nil
+	case *Func:
+		return *fn, nil
+	default:
+		return nil, fmt.Errorf("プラグインの Hook の型が不正です (%s): func(context.Context, hooks.Event) (string, error) である必要があります", path)
+	}
+}
+
+var (
+	registryMu sync.Mutex
+	registry   []Hook
+)
+
+// Register は、本ツールに組み込んでビルドする Go のコールバックをフックとして登録します。
+// 登録したフックは、コマンドラインで指定したフックより先に実行されます。
+func Register(stage Stage, name string, fn Func) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry = append(registry, Hook{Stage: stage, Name: name, fn: fn})
+}
+
+func registered() []Hook {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	return append([]Hook(nil), registry...)
+}
@@ -0,0 +1,185 @@
+package htmlreport
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	// DefaultTheme はテーマ未指定時に使用されるテーマです。
+	DefaultTheme = "light"
+	// DefaultFontSize はフォントサイズ未指定時の基準フォントサイズ (px) です。
+	DefaultFontSize = 16
+
+	minFontSize = 12
+	maxFontSize = 32
+)
+
+// DefaultTitle は ReportData.Title を指定しない場合のページのタイトルです。
+const DefaultTitle = "AIコードレビュー結果"
+
+// Options は HTML レポートの表示オプションです。
+type Options struct {
+	// Theme は配色テーマ ('light', 'dark', 'high-contrast') です。
+	Theme string
+	// FontSize は基準フォントサイズ (px) です。見出しや本文はこの値からの相対サイズで描画されます。
+	FontSize int
+	// Lang は html 要素の lang 属性です。スクリーンリーダーの読み上げ言語に影響します。
+	Lang string
+}
+
+// ReportData はレポートに埋め込むレビュー情報です。
+type ReportData struct {
+	// Title はページのタイトルです。空の場合は DefaultTitle です。
+	Title         string
+	RepoURL       string
+	BaseBranch    string
+	FeatureBranch string
+	// Model はレビューに使用したモデル名です。空の場合は表示しません。
+	Model          string
+	ReviewMarkdown string
+	GeneratedAt    time.Time
+}
+
+// Themes は選択可能なテーマ名の一覧を返します。
+func Themes() []string {
+	return []string{"light", "dark", "high-contrast"}
+}
+
+// Validate はオプションを検証し、未指定の項目にデフォルト値を補完した Options を返します。
+func (o Options) Validate() (Options, error) {
+	if o.Theme == "" {
+		o.Theme = DefaultTheme
+	}
+	if _, ok := themePalettes[o.Theme]; !ok {
+		return o, fmt.Errorf("不明なテーマです: '%s' (%v のいずれかを指定してください)", o.Theme, Themes())
+	}
+	if o.FontSize == 0 {
+		o.FontSize = DefaultFontSize
+	}
+	if o.FontSize < minFontSize || o.FontSize > maxFontSize {
+		return o, fmt.Errorf("フォントサイズは %d〜%d px の範囲で指定してください: %d", minFontSize, maxFontSize, o.FontSize)
+	}
+	if o.Lang == "" {
+		o.Lang = "ja"
+	}
+	return o, nil
+}
+
+// Render はレビュー結果の Markdown を、テーマとアクセシビリティ設定を適用した完全な HTML 文書に変換します。
+// ページ全体の見出しを h1 とし、レビュー本文の見出しは1段階下げて h2 以降から始まるように調整します。
+func Render(data ReportData, opts Options) ([]byte, error) {
+	opts, err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+			parser.WithASTTransformers(util.Prioritized(headingShifter{offset: 1}, 100)),
+		),
+	)
+	var body bytes.Buffer
+	if err := md.Convert([]byte(data.ReviewMarkdown), &body); err != nil {
+		return nil, fmt.Errorf("MarkdownからHTMLへの変換に失敗しました: %w", err)
+	}
+
+	generatedAt := data.GeneratedAt
+	if generatedAt.IsZero() {
+		generatedAt = time.Now()
+	}
+	title := data.Title
+	if title == "" {
+		title = DefaultTitle
+	}
+
+	var out bytes.Buffer
+	err = pageTemplate.Execute(&out, pageData{
+		Lang:          opts.Lang,
+		Theme:         opts.Theme,
+		Title:         title,
+		CSS:           template.CSS(buildCSS(opts)),
+		RepoURL:       data.RepoURL,
+		BaseBranch:    data.BaseBranch,
+		FeatureBranch: data.FeatureBranch,
+		Model:         data.Model,
+		GeneratedAt:   generatedAt.Format("2006/01/02 15:04:05 MST"),
+		GeneratedISO:  generatedAt.Format(time.RFC3339),
+		Body:          template.HTML(body.String()),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("HTMLテンプレートの実行に失敗しました: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// headingShifter は見出しレベルを offset だけ下げる AST 変換です。
+// 文書内の h1 をページタイトルの1つに限定し、見出し構造を階層的に保つために使用します。
+type headingShifter struct {
+	offset int
+}
+
+func (t headingShifter) Transform(doc *ast.Document, _ text.Reader, _ parser.Context) {
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if h, ok := n.(*ast.Heading); ok && entering {
+			h.Level = min(h.Level+t.offset, 6)
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+type pageData struct {
+	Lang          string
+	Theme         string
+	Title         string
+	CSS           template.CSS
+	RepoURL       string
+	BaseBranch    string
+	FeatureBranch string
+	Model         string
+	GeneratedAt   string
+	GeneratedISO  string
+	Body          template.HTML
+}
+
+var pageTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}" data-theme="{{.Theme}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>{{.CSS}}</style>
+</head>
+<body>
+<a class="skip-link" href="#review-body">本文へスキップ</a>
+<header>
+<h1>{{.Title}}</h1>
+<dl class="review-meta" aria-label="レビュー対象">
+<dt>リポジトリ</dt><dd><code>{{.RepoURL}}</code></dd>
+<dt>ブランチ差分</dt><dd><code>{{.BaseBranch}}</code> ← <code>{{.FeatureBranch}}</code></dd>
+{{if .Model}}<dt>モデル</dt><dd><code>{{.Model}}</code></dd>
+{{end}}<dt>レビュー実行日時</dt><dd><time datetime="{{.GeneratedISO}}">{{.GeneratedAt}}</time></dd>
+</dl>
+</header>
+<main id="review-body" tabindex="-1">
+<article aria-label="レビュー本文">
+{{.Body}}
+</article>
+</main>
+<footer>
+<p>Generated by git-gemini-reviewer-go</p>
+</footer>
+</body>
+</html>
+`))
@@ -0,0 +1,109 @@
+package htmlreport
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"time"
+)
+
+const indexTitle = "AIコードレビュー一覧"
+
+// IndexEntry は一覧ページの1件のレビューです。
+type IndexEntry struct {
+	// Href は一覧ページからのレビュー結果の相対パスです。
+	Href          string
+	RepoURL       string
+	BaseBranch    string
+	FeatureBranch string
+	// Verdict は判定の表示名です (例: 'リリース可')。
+	Verdict     string
+	GeneratedAt time.Time
+}
+
+// RenderIndex は、レビュー結果へのリンクを新しい順に並べた一覧ページの HTML 文書を生成します。
+// entries は表示する順に渡します。
+func RenderIndex(entries []IndexEntry, opts Options) ([]byte, error) {
+	opts, err := opts.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]indexRow, 0, len(entries))
+	for _, e := range entries {
+		rows = append(rows, indexRow{
+			IndexEntry:   e,
+			GeneratedAt:  e.GeneratedAt.Format("2006/01/02 15:04"),
+			GeneratedISO: e.GeneratedAt.Format(time.RFC3339),
+		})
+	}
+
+	var out bytes.Buffer
+	err = indexTemplate.Execute(&out, indexPageData{
+		Lang:  opts.Lang,
+		Theme: opts.Theme,
+		Title: indexTitle,
+		CSS:   template.CSS(buildCSS(opts)),
+		Rows:  rows,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("一覧ページのHTMLテンプレートの実行に失敗しました: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+type indexRow struct {
+	IndexEntry
+	GeneratedAt  string
+	GeneratedISO string
+}
+
+type indexPageData struct {
+	Lang  string
+	Theme string
+	Title string
+	CSS   template.CSS
+	Rows  []indexRow
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}" data-theme="{{.Theme}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>{{.CSS}}</style>
+</head>
+<body>
+<a class="skip-link" href="#review-index">本文へスキップ</a>
+<header>
+<h1>{{.Title}}</h1>
+</header>
+<main id="review-index" tabindex="-1">
+{{- if .Rows}}
+<table>
+<caption>新しい順に {{len .Rows}} 件</caption>
+<thead>
+<tr><th scope="col">レビュー実行日時</th><th scope="col">リポジトリ</th><th scope="col">ブランチ差分</th><th scope="col">判定</th></tr>
+</thead>
+<tbody>
+{{- range .Rows}}
+<tr>
+<td><a href="{{.Href}}"><time datetime="{{.GeneratedISO}}">{{.GeneratedAt}}</time></a></td>
+<td><code>{{.RepoURL}}</code></td>
+<td><code>{{.BaseBranch}}</code> ← <code>{{.FeatureBranch}}</code></td>
+<td>{{.Verdict}}</td>
+</tr>
+{{- end}}
+</tbody>
+</table>
+{{- else}}
+<p>レビュー結果はまだありません。</p>
+{{- end}}
+</main>
+<footer>
+<p>Generated by git-gemini-reviewer-go</p>
+</footer>
+</body>
+</html>
+`))
@@ -0,0 +1,82 @@
+package htmlreport
+
+import (
+	"fmt"
+	"strings"
+)
+
+// palette はテーマごとの配色です。各組み合わせは WCAG 2.1 のコントラスト比 4.5:1 以上を満たすよう選定しています。
+type palette struct {
+	Background string
+	Text       string
+	Muted      string
+	Link       string
+	CodeBg     string
+	Border     string
+	Focus      string
+}
+
+var themePalettes = map[string]palette{
+	"light": {
+		Background: "#ffffff",
+		Text:       "#1f2328",
+		Muted:      "#59636e",
+		Link:       "#0550ae",
+		CodeBg:     "#f6f8fa",
+		Border:     "#d1d9e0",
+		Focus:      "#0969da",
+	},
+	"dark": {
+		Background: "#0d1117",
+		Text:       "#e6edf3",
+		Muted:      "#9198a1",
+		Link:       "#6cb6ff",
+		CodeBg:     "#161b22",
+		Border:     "#3d444d",
+		Focus:      "#4493f8",
+	},
+	"high-contrast": {
+		Background: "#000000",
+		Text:       "#ffffff",
+		Muted:      "#ffffff",
+		Link:       "#ffff00",
+		CodeBg:     "#000000",
+		Border:     "#ffffff",
+		Focus:      "#00ffff",
+	},
+}
+
+// buildCSS はテーマと基準フォントサイズからスタイルシートを生成します。
+// フォントサイズ以外の寸法は rem/em で指定し、ブラウザの拡大設定にも追従するようにしています。
+func buildCSS(opts Options) string {
+	p := themePalettes[opts.Theme]
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `:root{--bg:%s;--fg:%s;--muted:%s;--link:%s;--code-bg:%s;--border:%s;--focus:%s;}`,
+		p.Background, p.Text, p.Muted, p.Link, p.CodeBg, p.Border, p.Focus)
+	fmt.Fprintf(&sb, `html{font-size:%dpx;}`, opts.FontSize)
+	sb.WriteString(`
+body{margin:0 auto;max-width:60rem;padding:1.5rem;background:var(--bg);color:var(--fg);font-family:system-ui,-apple-system,"Segoe UI","Hiragino Sans","Noto Sans JP",sans-serif;line-height:1.7;}
+a{color:var(--link);text-decoration:underline;}
+a:focus-visible,main:focus-visible{outline:3px solid var(--focus);outline-offset:2px;}
+.skip-link{position:absolute;left:-999rem;}
+.skip-link:focus{left:1rem;top:1rem;padding:.5rem 1rem;background:var(--bg);color:var(--link);z-index:10;}
+h1{font-size:2rem;line-height:1.3;}
+h2{font-size:1.6rem;border-bottom:1px solid var(--border);padding-bottom:.3rem;}
+h3{font-size:1.3rem;}
+h4,h5,h6{font-size:1.1rem;}
+.review-meta{display:grid;grid-template-columns:max-content 1fr;gap:.25rem 1rem;color:var(--muted);}
+.review-meta dt{font-weight:bold;}
+.review-meta dd{margin:0;}
+code,pre{font-family:ui-monospace,SFMono-Regular,Menlo,Consolas,monospace;font-size:.9em;background:var(--code-bg);}
+code{padding:.1em .3em;border-radius:4px;}
+pre{padding:1rem;overflow-x:auto;border:1px solid var(--border);border-radius:6px;}
+pre code{padding:0;}
+table{border-collapse:collapse;}
+th,td{border:1px solid var(--border);padding:.4rem .8rem;}
+blockquote{margin:0;padding:0 1rem;border-left:4px solid var(--border);color:var(--muted);}
+footer{margin-top:3rem;color:var(--muted);font-size:.875rem;}
+@media (prefers-reduced-motion:reduce){*{scroll-behavior:auto!important;}}
+`)
+	return sb.String()
+}
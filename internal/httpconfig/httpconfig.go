@@ -0,0 +1,108 @@
+// Package httpconfig は、外部サービスへのすべての HTTP 通信で共有するタイムアウト・プロキシ・TLS の設定を扱います。
+// 企業ネットワークの TLS インスペクションなど、独自の CA やプロキシを経由する環境で各連携を動作させるためのものです。
+package httpconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Settings は HTTP クライアントの設定です。
+type Settings struct {
+	// Timeout は1回のリクエストの制限時間です。
+	Timeout time.Duration
+	// ProxyURL はプロキシのURLです。空の場合は環境変数 (HTTPS_PROXY / HTTP_PROXY / NO_PROXY) に従います。
+	ProxyURL string
+	// CABundle は、システムの証明書に加えて信頼する CA 証明書 (PEM) のファイルのパスです。
+	CABundle string
+	// TLSMinVersion は許可する TLS の最小バージョン ('1.2' または '1.3') です。空の場合は Go の既定値を使用します。
+	TLSMinVersion string
+}
+
+// tlsVersions は TLSMinVersion に指定できる値です。
+// 最小バージョンを引き上げるための設定のため、非推奨の TLS 1.0 と 1.1 は受け付けません。
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Transport は設定を適用した *http.Transport を生成します。
+func (s Settings) Transport() (*http.Transport, error) {
+	base, ok := http.DefaultTransport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("既定の HTTP トランスポートの型が想定外です: %T", http.DefaultTransport)
+	}
+	transport := base.Clone()
+
+	if s.ProxyURL != "" {
+		proxy, err := url.Parse(s.ProxyURL)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("プロキシのURLが不正です: '%s'", s.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	} else {
+		transport.Proxy = http.ProxyFromEnvironment
+	}
+
+	tlsConfig := &tls.Config{}
+	if transport.TLSClientConfig != nil {
+		tlsConfig = transport.TLSClientConfig.Clone()
+	}
+	if s.CABundle != "" {
+		pool, err := certPool(s.CABundle)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if s.TLSMinVersion != "" {
+		version, ok := tlsVersions[strings.TrimPrefix(s.TLSMinVersion, "v")]
+		if !ok {
+			return nil, fmt.Errorf("TLS の最小バージョンが不正です: '%s' ('1.2' または '1.3' を指定してください)", s.TLSMinVersion)
+		}
+		tlsConfig.MinVersion = version
+	}
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
+
+// certPool は、システムの証明書に CA バンドルの証明書を追加した証明書プールを返します。
+func certPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("CA バンドルの読み込みに失敗しました (%s): %w", path, err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("CA バンドルに有効な PEM 形式の証明書が含まれていません: %s", path)
+	}
+	return pool, nil
+}
+
+// Install は、設定を適用したトランスポートを http.DefaultTransport と http.DefaultClient に設定します。
+// Gemini や GCS、go-notifier の Slack / Backlog クライアントなど、トランスポートを注入できない
+// ライブラリも既定のトランスポートを経由するため、プロセス全体の通信に設定が適用されます。
+func (s Settings) Install() error {
+	transport, err := s.Transport()
+	if err != nil {
+		return err
+	}
+	http.DefaultTransport = transport
+	http.DefaultClient.Transport = transport
+	return nil
+}
+
+// NewClient は、既定のトランスポートと設定のタイムアウトを使用する *http.Client を返します。
+// Install の後に呼び出すと、設定したプロキシと TLS が適用されます。
+func (s Settings) NewClient() *http.Client {
+	return &http.Client{Transport: http.DefaultTransport, Timeout: s.Timeout}
+}
@@ -0,0 +1,393 @@
+// Package inline は、AI に行単位の構造化された指摘 (ファイル・行・重大度・内容) を出力させ、
+// 差分上の行に紐付けてインラインコメントとして投稿できる形に変換します。
+package inline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/diffstat"
+	"git-gemini-reviewer-go/internal/findings"
+	"git-gemini-reviewer-go/internal/monorepo"
+	"git-gemini-reviewer-go/internal/schema"
+	"git-gemini-reviewer-go/internal/tokenusage"
+	"git-gemini-reviewer-go/internal/verdict"
+)
+
+// Severity は指摘の重大度です。
+type Severity string
+
+const (
+	Critical Severity = "critical"
+	Major    Severity = "major"
+	Minor    Severity = "minor"
+)
+
+// Severities は重大度を重い順に返します。
+func Severities() []Severity {
+	return []Severity{Critical, Major, Minor}
+}
+
+// ParseSeverity は文字列を Severity に変換します。
+func ParseSeverity(s string) (Severity, error) {
+	sev := Severity(strings.ToLower(strings.TrimSpace(s)))
+	for _, known := range Severities() {
+		if sev == known {
+			return sev, nil
+		}
+	}
+	return "", fmt.Errorf("不明な重大度です: '%s' (%v のいずれかを指定してください)", s, Severities())
+}
+
+// rank は重大度の重さを返します。重いほど大きな値です。
+func (s Severity) rank() int {
+	switch s {
+	case Critical:
+		return 3
+	case Major:
+		return 2
+	case Minor:
+		return 1
+	}
+	return 0
+}
+
+// AtLeast は、重大度が threshold 以上かを返します。
+func (s Severity) AtLeast(threshold Severity) bool {
+	return s.rank() >= threshold.rank()
+}
+
+// Raise は重大度を1段階重くした重大度を返します。重大はそのまま返します。
+func (s Severity) Raise() Severity {
+	switch s {
+	case Minor:
+		return Major
+	case Major:
+		return Critical
+	}
+	return s
+}
+
+// CountAtLeast は、重大度が threshold 以上の指摘の件数を返します。抑制された指摘は数えません。
+func (r Review) CountAtLeast(threshold Severity) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity.AtLeast(threshold) {
+			n++
+		}
+	}
+	return n
+}
+
+// Label は重大度の表示名を返します。
+func (s Severity) Label() string {
+	switch s {
+	case Critical:
+		return "🔴 重大"
+	case Major:
+		return "🟠 要修正"
+	}
+	return "🟡 軽微"
+}
+
+// Finding は行 (または行の範囲) に紐付く指摘です。
+type Finding struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	// EndLine は指摘の対象の最終行です。1行のみの指摘では Line と同じ値です。
+	EndLine  int               `json:"end_line,omitempty"`
+	Severity Severity          `json:"severity"`
+	Category findings.Category `json:"category"`
+	Message  string            `json:"message"`
+	// Suggestion は修正案です (Markdown 可)。
+	Suggestion string `json:"suggestion,omitempty"`
+	// InDiff は、指摘の行が差分に含まれ、インラインコメントとして投稿できるかを表します。
+	InDiff bool `json:"in_diff"`
+	// SuppressedBy は、指摘を抑制したコメントの指定 (例: 'ai-review:ignore security') です。
+	SuppressedBy string `json:"suppressed_by,omitempty"`
+}
+
+// Review は構造化されたレビュー結果です。
+type Review struct {
+	Summary  string    `json:"summary"`
+	Findings []Finding `json:"findings"`
+	// Suppressed は、コード中のコメントで抑制された指摘です。判定、インラインコメント、指摘の集計の対象外です。
+	Suppressed []Finding `json:"-"`
+}
+
+// Document は --format json で出力する、構造化されたレビュー結果の文書です。
+// 形式は schema.ReviewFindings の JSON Schema で定義します。
+type Document struct {
+	SchemaVersion int             `json:"schema_version"`
+	ReviewID      string          `json:"review_id"`
+	Verdict       verdict.Verdict `json:"verdict"`
+	Summary       string          `json:"summary"`
+	Findings      []Finding       `json:"findings"`
+	Suppressed    []Finding       `json:"suppressed"`
+	// DiffStats はレビューした差分の変更量です。差分がない場合は含めません。
+	DiffStats *diffstat.Report `json:"diff_stats,omitempty"`
+	// Usage は AI に送信・受信したトークン数と推定費用です。AI にリクエストしなかった場合は含めません。
+	Usage *tokenusage.Report `json:"usage,omitempty"`
+}
+
+// Document は、レビュー結果を --format json で出力する文書に変換します。
+// 指摘がない場合も、利用者が null を扱わずに済むよう空の配列を出力します。
+func (r Review) Document(reviewID string) Document {
+	doc := Document{
+		SchemaVersion: schema.Version,
+		ReviewID:      reviewID,
+		Verdict:       r.Verdict(),
+		Summary:       strings.TrimSpace(r.Summary),
+		Findings:      r.Findings,
+		Suppressed:    r.Suppressed,
+	}
+	if doc.Findings == nil {
+		doc.Findings = []Finding{}
+	}
+	if doc.Suppressed == nil {
+		doc.Suppressed = []Finding{}
+	}
+	return doc
+}
+
+// PromptInstruction は、プロンプトの末尾に付与する出力形式の指示です。
+// AI のアダプタはテキストのみを返すため、JSON のスキーマをプロンプトで指定します。
+const PromptInstruction = `
+
+---
+【出力形式の指定】
+上記の指示にある出力形式の代わりに、以下のスキーマに従う JSON のみを出力してください。JSON 以外の文章やコードブロックの記号は含めないでください。
+{
+  "summary": "レビュー全体の要約 (Markdown 可、日本語)",
+  "findings": [
+    {
+      "file": "差分の +++ 行に記載された変更後のファイルパス",
+      "line": 変更後のファイルにおける指摘の開始行の行番号 (差分のハンクに含まれる行),
+      "end_line": 指摘の最終行の行番号 (1行のみの場合は line と同じ値),
+      "severity": "critical | major | minor",
+      "category": "security | correctness | performance | style | tests | docs",
+      "message": "問題点 (Markdown 可、日本語)",
+      "suggestion": "具体的な修正案 (Markdown 可、日本語。コードはコードブロックで示す)"
+    }
+  ]
+}
+severity は、リリースを止めるべき問題を critical、マージ前に修正すべき問題を major、それ以外を minor としてください。
+category は、指摘の観点に最も近いものを1つ選んでください。
+指摘がない場合は findings を空の配列にしてください。
+`
+
+// Parse は AI の応答から構造化されたレビュー結果を読み取ります。
+// 応答がコードブロックで囲まれている場合や、前後に文章を含む場合も JSON の部分のみを解析します。
+func Parse(response string) (Review, error) {
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start == -1 || end < start {
+		return Review{}, fmt.Errorf("AIの応答に構造化された指摘 (JSON) が含まれていません")
+	}
+	var r Review
+	if err := json.Unmarshal([]byte(response[start:end+1]), &r); err != nil {
+		return Review{}, fmt.Errorf("AIの応答の構造化された指摘の解析に失敗しました: %w", err)
+	}
+	r.Suppressed = nil
+	for i := range r.Findings {
+		f := &r.Findings[i]
+		f.File = strings.TrimPrefix(strings.TrimSpace(f.File), "b/")
+		switch s := Severity(strings.ToLower(strings.TrimSpace(string(f.Severity)))); s {
+		case Critical, Major, Minor:
+			f.Severity = s
+		default:
+			f.Severity = Minor
+		}
+		// カテゴリが指定されていない、または体系にない場合は本文から分類する
+		if c, err := findings.ParseCategory(string(f.Category)); err == nil {
+			f.Category = c
+		} else {
+			f.Category = findings.Classify(f.Message)
+		}
+		if f.EndLine < f.Line {
+			f.EndLine = f.Line
+		}
+		f.InDiff, f.SuppressedBy = false, ""
+	}
+	return r, nil
+}
+
+// Validate は、各指摘がスキーマの必須の値 (ファイル・1以上の行番号・本文) を満たしているかを検証します。
+// 満たしていない指摘は、差分の行に紐付けられず集計も不正確になるため、問題のある指摘をすべて列挙したエラーを返します。
+func (r Review) Validate() error {
+	var errs []error
+	for i, f := range r.Findings {
+		var problems []string
+		if f.File == "" {
+			problems = append(problems, "file が空です")
+		}
+		if f.Line < 1 {
+			problems = append(problems, fmt.Sprintf("line が1未満です (%d)", f.Line))
+		}
+		if strings.TrimSpace(f.Message) == "" {
+			problems = append(problems, "message が空です")
+		}
+		if len(problems) > 0 {
+			errs = append(errs, fmt.Errorf("findings[%d] (%s): %s", i, f.File, strings.Join(problems, "、")))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("AIの構造化された指摘 %d 件がスキーマを満たしていません: %w", len(errs), errors.Join(errs...))
+	}
+	return nil
+}
+
+// Anchor は、各指摘の行が差分の変更後の行 (追加行またはコンテキスト行) に含まれるかを判定します。
+// 差分に含まれない行にはインラインコメントを付けられないため、要約に含めて投稿します。
+func (r *Review) Anchor(diff string) {
+	lines := commentableLines(diff)
+	for i := range r.Findings {
+		f := &r.Findings[i]
+		f.InDiff = lines[f.File][f.Line]
+	}
+}
+
+// commentableLines は、ファイルごとに差分の変更後の行番号の集合を返します。
+func commentableLines(diff string) map[string]map[int]bool {
+	result := make(map[string]map[int]bool)
+	for _, f := range monorepo.SplitDiff(diff) {
+		lines := make(map[int]bool)
+		next := 0
+		for _, line := range strings.Split(f.Content, "\n") {
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				next = HunkStart(line)
+			case next == 0:
+			case strings.HasPrefix(line, "+"), strings.HasPrefix(line, " "):
+				lines[next] = true
+				next++
+			}
+		}
+		result[f.Path] = lines
+	}
+	return result
+}
+
+// HunkStart はハンクヘッダ "@@ -a,b +c,d @@" から変更後の開始行 c を返します。読み取れない場合は0を返します。
+func HunkStart(header string) int {
+	_, rest, ok := strings.Cut(header, " +")
+	if !ok {
+		return 0
+	}
+	end := strings.IndexAny(rest, ", ")
+	if end == -1 {
+		return 0
+	}
+	n, err := strconv.Atoi(rest[:end])
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// Verdict は最も重い指摘の重大度から判定を導きます。
+func (r Review) Verdict() verdict.Verdict {
+	v := verdict.Approved
+	for _, f := range r.Findings {
+		switch f.Severity {
+		case Critical:
+			return verdict.Blocked
+		case Major:
+			v = verdict.Conditional
+		}
+	}
+	return v
+}
+
+// Inline は差分の行に紐付けられる指摘を返します。
+func (r Review) Inline() []Finding {
+	var found []Finding
+	for _, f := range r.Findings {
+		if f.InDiff {
+			found = append(found, f)
+		}
+	}
+	return found
+}
+
+// Markdown は構造化されたレビュー結果を、他の投稿先や履歴と共通の Markdown 形式に変換します。
+// 判定と「問題点」の行を含めるため、判定のゲートや指摘カテゴリの集計もそのまま機能します。
+func (r Review) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### 判定: %s\n\n", r.Verdict().Label())
+	if s := strings.TrimSpace(r.Summary); s != "" {
+		sb.WriteString(s + "\n\n")
+	}
+	file := ""
+	for _, f := range r.Findings {
+		if f.File != file {
+			file = f.File
+			fmt.Fprintf(&sb, "#### ファイル名: `%s`\n\n", file)
+		}
+		fmt.Fprintf(&sb, "- **問題点** (%s, %s, %s): %s\n", f.Lines(), f.Severity.Label(), f.Category.Label(), strings.TrimSpace(f.Message))
+		if s := strings.TrimSpace(f.Suggestion); s != "" {
+			fmt.Fprintf(&sb, "  - **修正案**: %s\n", indent(s))
+		}
+	}
+	sb.WriteString(r.suppressedSection())
+	return sb.String()
+}
+
+// Body は、インラインコメントにできなかった指摘を含む、レビュー本文の Markdown を返します。
+func (r Review) Body() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### 判定: %s\n\n", r.Verdict().Label())
+	if s := strings.TrimSpace(r.Summary); s != "" {
+		sb.WriteString(s + "\n")
+	}
+	var outside []string
+	for _, f := range r.Findings {
+		if !f.InDiff {
+			outside = append(outside, fmt.Sprintf("- `%s` %s (%s): %s", f.File, f.Lines(), f.Severity.Label(), strings.TrimSpace(f.Message)))
+		}
+	}
+	if len(outside) > 0 {
+		fmt.Fprintf(&sb, "\n#### 差分の範囲外の指摘\n\n%s\n", strings.Join(outside, "\n"))
+	}
+	sb.WriteString(r.suppressedSection())
+	return sb.String()
+}
+
+// suppressedSection は抑制された指摘の一覧を返します。抑制された指摘がない場合は空文字列です。
+// 指摘の集計や重要パスの判定に数えられないよう、「問題点」の見出しを使用しません。
+func (r Review) suppressedSection() string {
+	if len(r.Suppressed) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n#### 🔕 抑制された指摘\n\n")
+	for _, f := range r.Suppressed {
+		fmt.Fprintf(&sb, "- `%s` %s (%s, `%s`): %s\n", f.File, f.Lines(), f.Severity.Label(), f.SuppressedBy, findings.Mute(strings.TrimSpace(f.Message)))
+	}
+	return sb.String()
+}
+
+// Comment はインラインコメントの本文を返します。
+func (f Finding) Comment() string {
+	comment := fmt.Sprintf("**%s** (%s): %s", f.Severity.Label(), f.Category.Label(), strings.TrimSpace(f.Message))
+	if s := strings.TrimSpace(f.Suggestion); s != "" {
+		comment += "\n\n**修正案**: " + s
+	}
+	return comment
+}
+
+// Lines は指摘の対象の行を 'L12' または 'L12-14' の形式で返します。
+func (f Finding) Lines() string {
+	if f.EndLine > f.Line {
+		return fmt.Sprintf("L%d-%d", f.Line, f.EndLine)
+	}
+	return fmt.Sprintf("L%d", f.Line)
+}
+
+// indent は、複数行の Markdown をリストの項目の下に続けて表示できるよう、2行目以降を字下げします。
+func indent(s string) string {
+	return strings.ReplaceAll(s, "\n", "\n    ")
+}
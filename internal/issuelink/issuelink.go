@@ -0,0 +1,162 @@
+package issuelink
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 組み込みのトラッカー名です。
+const (
+	TrackerBacklog = "backlog"
+	TrackerJira    = "jira"
+	TrackerGitHub  = "github"
+)
+
+var (
+	// projectKeyPattern は Backlog / Jira 形式の課題キー (例: PROJECT-123) です。
+	projectKeyPattern = regexp.MustCompile(`\b([A-Z][A-Z0-9_]*)-([0-9]+)\b`)
+	// githubRefPattern は GitHub 形式の Issue / PR 参照 (例: #123) です。
+	githubRefPattern = regexp.MustCompile(`(?:^|[^\w&/])#([0-9]+)\b`)
+
+	// nonIssuePrefixes は課題キーと同じ形式だが課題ではない、よく使われる表記の接頭辞です (例: UTF-8, SHA-256)。
+	nonIssuePrefixes = map[string]bool{
+		"UTF": true, "SHA": true, "ISO": true, "RFC": true, "TLS": true, "SSL": true,
+		"HTTP": true, "MD": true, "AES": true, "RSA": true, "CVE": true, "X": true,
+	}
+)
+
+// Tracker は課題キーをリンクに変換するための設定です。
+type Tracker struct {
+	// Name はトラッカーの種類 ('backlog', 'jira', 'github') です。
+	Name string
+	// Projects は対象とするプロジェクトキーです。空の場合はすべてのプロジェクトキーを対象とします。
+	// Backlog と Jira のキーは同じ形式のため、両方を使う場合はプロジェクトキーで振り分けます。
+	Projects []string
+	// URLTemplate はリンク先のテンプレートです。{key} は課題キー全体、{project} はプロジェクトキー、{number} は番号に置換されます。
+	URLTemplate string
+}
+
+// Link は検出された課題キーとリンク先です。
+type Link struct {
+	Tracker string
+	Key     string
+	URL     string
+}
+
+// ParseTracker は "name=template" または "name:PROJ1|PROJ2=template" 形式の指定を Tracker に変換します。
+func ParseTracker(spec string) (Tracker, error) {
+	name, template, ok := strings.Cut(spec, "=")
+	if !ok || strings.TrimSpace(template) == "" {
+		return Tracker{}, fmt.Errorf("課題トラッカーの指定が不正です: '%s' ('名前=URLテンプレート' の形式で指定してください)", spec)
+	}
+
+	t := Tracker{URLTemplate: strings.TrimSpace(template)}
+	name, projects, hasProjects := strings.Cut(strings.TrimSpace(name), ":")
+	t.Name = strings.ToLower(name)
+	if hasProjects {
+		for _, p := range strings.Split(projects, "|") {
+			if p = strings.TrimSpace(p); p != "" {
+				t.Projects = append(t.Projects, p)
+			}
+		}
+	}
+
+	switch t.Name {
+	case TrackerBacklog, TrackerJira, TrackerGitHub:
+	default:
+		return Tracker{}, fmt.Errorf("不明な課題トラッカーです: '%s' ('%s', '%s', '%s' のいずれかを指定してください)", t.Name, TrackerBacklog, TrackerJira, TrackerGitHub)
+	}
+	if !strings.Contains(t.URLTemplate, "{key}") && !strings.Contains(t.URLTemplate, "{number}") {
+		return Tracker{}, fmt.Errorf("URLテンプレートには {key} または {number} を含めてください: '%s'", t.URLTemplate)
+	}
+	return t, nil
+}
+
+// Extract は texts から課題キーを検出し、trackers の設定に従ってリンクに変換します。
+// 同じキーは最初の1件のみを返します。プロジェクトキー形式のキーは、指定順で最初に一致したトラッカーに割り当てます。
+func Extract(trackers []Tracker, texts ...string) []Link {
+	var links []Link
+	seen := map[string]bool{}
+	add := func(l Link) {
+		if !seen[l.Key] {
+			seen[l.Key] = true
+			links = append(links, l)
+		}
+	}
+
+	for _, text := range texts {
+		for _, m := range projectKeyPattern.FindAllStringSubmatch(text, -1) {
+			key, project, number := m[0], m[1], m[2]
+			if nonIssuePrefixes[project] {
+				continue
+			}
+			for _, t := range trackers {
+				if t.Name == TrackerGitHub || !t.matchesProject(project) {
+					continue
+				}
+				add(Link{Tracker: t.Name, Key: key, URL: t.expand(key, project, number)})
+				break
+			}
+		}
+		for _, m := range githubRefPattern.FindAllStringSubmatch(text, -1) {
+			number := m[1]
+			for _, t := range trackers {
+				if t.Name != TrackerGitHub {
+					continue
+				}
+				key := "#" + number
+				add(Link{Tracker: t.Name, Key: key, URL: t.expand(key, "", number)})
+				break
+			}
+		}
+	}
+	return links
+}
+
+// ProjectKeys は texts から Backlog / Jira 形式の課題キー (例: PROJECT-123) を出現順に重複なく返します。
+// projects を指定した場合は、そのプロジェクトキーの課題のみを返します。
+func ProjectKeys(projects []string, texts ...string) []string {
+	filter := Tracker{Projects: projects}
+	var keys []string
+	seen := map[string]bool{}
+	for _, text := range texts {
+		for _, m := range projectKeyPattern.FindAllStringSubmatch(text, -1) {
+			key, project := m[0], m[1]
+			if nonIssuePrefixes[project] || !filter.matchesProject(project) || seen[key] {
+				continue
+			}
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Markdown はリンクの一覧をレビュー本文の冒頭に置く Markdown として返します。リンクがない場合は空文字列です。
+func Markdown(links []Link) string {
+	if len(links) == 0 {
+		return ""
+	}
+	items := make([]string, 0, len(links))
+	for _, l := range links {
+		items = append(items, fmt.Sprintf("[%s](%s)", l.Key, l.URL))
+	}
+	return "**🔗 関連課題:** " + strings.Join(items, ", ") + "\n\n---\n\n"
+}
+
+func (t Tracker) matchesProject(project string) bool {
+	if len(t.Projects) == 0 {
+		return true
+	}
+	for _, p := range t.Projects {
+		if p == project {
+			return true
+		}
+	}
+	return false
+}
+
+func (t Tracker) expand(key, project, number string) string {
+	return strings.NewReplacer("{key}", key, "{project}", project, "{number}", number).Replace(t.URLTemplate)
+}
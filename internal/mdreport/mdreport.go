@@ -0,0 +1,94 @@
+// Package mdreport は、レビュー結果に対象のリポジトリ・ブランチ・日時・モデルを記したヘッダを付与し、
+// 単体で読める Markdown のレポートに整形します。
+package mdreport
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultHeader はヘッダのテンプレートを指定しない場合に使用するテンプレートです。
+const DefaultHeader = `# AIコードレビュー結果
+
+| 項目 | 内容 |
+| :--- | :--- |
+| リポジトリ | ` + "`{{.RepoURL}}`" + ` |
+| ブランチ差分 | ` + "`{{.BaseBranch}}` ← `{{.FeatureBranch}}`" + ` |
+| レビュー実行日時 | {{.GeneratedAt.Format "2006/01/02 15:04:05 MST"}} |
+| モデル | ` + "`{{.Model}}`" + ` |
+| レビューID | ` + "`{{.ReviewID}}`" + ` |
+
+---
+
+`
+
+// Data はヘッダのテンプレートに渡すレビューの情報です。
+type Data struct {
+	RepoURL       string
+	BaseBranch    string
+	FeatureBranch string
+	Model         string
+	Mode          string
+	ReviewID      string
+	GeneratedAt   time.Time
+}
+
+// Template はヘッダのテンプレートです。
+type Template struct {
+	tmpl *template.Template
+}
+
+// Parse はヘッダのテンプレート (text/template 形式) を解析します。空文字列の場合は DefaultHeader を使用します。
+func Parse(text string) (*Template, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultHeader
+	}
+	tmpl, err := template.New("header").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("レポートのヘッダのテンプレートの解析に失敗しました: %w", err)
+	}
+	return &Template{tmpl: tmpl}, nil
+}
+
+// Load はファイルからヘッダのテンプレートを読み込みます。path が空の場合は DefaultHeader を使用します。
+// 存在しないフィールドの参照などの誤りをレビューの実行前に検出できるよう、空のデータで一度描画します。
+func Load(path string) (*Template, error) {
+	var text string
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("レポートのヘッダのテンプレートの読み込みに失敗しました (%s): %w", path, err)
+		}
+		text = string(data)
+	}
+	t, err := Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := t.Header(Data{GeneratedAt: time.Now()}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Header はヘッダを描画します。
+func (t *Template) Header(data Data) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("レポートのヘッダの描画に失敗しました: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Render はヘッダを付与したレビュー結果の Markdown を返します。
+func (t *Template) Render(data Data, review string) (string, error) {
+	header, err := t.Header(data)
+	if err != nil {
+		return "", err
+	}
+	return header + strings.TrimSpace(review) + "\n", nil
+}
@@ -0,0 +1,132 @@
+// Package messages は、差分なし・スキップ時など、AIレビューを伴わない定型メッセージをテンプレートから生成します。
+// 組み込みテンプレートは言語ごとに用意し、テンプレートディレクトリのファイルで投稿先ごとに上書きできます。
+package messages
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+//go:embed templates
+var builtin embed.FS
+
+// Kind はメッセージの種類です。テンプレートのファイル名に対応します。
+type Kind string
+
+const (
+	// NoDiff は、ブランチ間に差分がない場合のメッセージです。
+	NoDiff Kind = "no-diff"
+	// Skipped は、スキップ指定によりレビューを行わなかった場合のメッセージです。
+	Skipped Kind = "skipped"
+)
+
+// kinds は検証対象とするメッセージの種類です。
+var kinds = []Kind{NoDiff, Skipped}
+
+// DefaultLang は組み込みテンプレートの既定の言語です。
+const DefaultLang = "ja"
+
+// HasLang は、組み込みテンプレートに言語 lang のテンプレートがあるかを返します。
+func HasLang(lang string) bool {
+	info, err := fs.Stat(builtin, "templates/"+lang)
+	return err == nil && info.IsDir()
+}
+
+// SkipReason はレビューをスキップした理由です。
+type SkipReason struct {
+	// Kind は 'label' (PRラベル) または 'marker' (コミットメッセージのマーカー) です。
+	Kind   string
+	Label  string
+	Marker string
+	// Commit はマーカーを含むコミットの件名です。
+	Commit string
+}
+
+// Data はテンプレートに渡す値です。
+type Data struct {
+	RepoURL       string
+	BaseBranch    string
+	FeatureBranch string
+	ReviewID      string
+	// Destination は投稿先のコマンド名です (例: 'slack', 'backlog', 'generic')。
+	Destination string
+	Skip        SkipReason
+}
+
+// Options はテンプレートの選択方法です。
+type Options struct {
+	// Dir は上書き用テンプレートのディレクトリです。空の場合は組み込みテンプレートのみを使用します。
+	Dir string
+	// Lang は組み込みテンプレートの言語です ('ja' または 'en')。空の場合は DefaultLang です。
+	Lang string
+}
+
+// Render は種類と投稿先に応じたテンプレートでメッセージを生成します。
+// テンプレートは次の順に探索します: <Dir>/<kind>.<destination>.md、<Dir>/<kind>.md、組み込みの <lang>/<kind>.md
+func Render(kind Kind, data Data, opts Options) (string, error) {
+	tmpl, err := load(kind, data.Destination, opts)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("メッセージテンプレート '%s' の展開に失敗しました: %w", tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
+
+// Validate は、すべての種類のテンプレートが読み込めることを確認します。
+func Validate(opts Options, destination string) error {
+	for _, kind := range kinds {
+		if _, err := load(kind, destination, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// load はテンプレートを探索して解析します。
+func load(kind Kind, destination string, opts Options) (*template.Template, error) {
+	if opts.Dir != "" {
+		candidates := []string{string(kind) + ".md"}
+		if destination != "" {
+			candidates = append([]string{fmt.Sprintf("%s.%s.md", kind, destination)}, candidates...)
+		}
+		for _, name := range candidates {
+			path := filepath.Join(opts.Dir, name)
+			body, err := os.ReadFile(path)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("メッセージテンプレートの読み込みに失敗しました (%s): %w", path, err)
+			}
+			return parse(path, body)
+		}
+	}
+
+	lang := opts.Lang
+	if lang == "" {
+		lang = DefaultLang
+	}
+	path := fmt.Sprintf("templates/%s/%s.md", lang, kind)
+	body, err := fs.ReadFile(builtin, path)
+	if err != nil {
+		return nil, fmt.Errorf("言語 '%s' のメッセージテンプレートはありません (対応言語: ja, en)", lang)
+	}
+	return parse(path, body)
+}
+
+func parse(name string, body []byte) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(string(body))
+	if err != nil {
+		return nil, fmt.Errorf("メッセージテンプレートの解析に失敗しました (%s): %w", name, err)
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,129 @@
+// Package modelchain は、--gemini にカンマ区切りで指定したモデルを順に試すフォールバックの連鎖を提供します。
+// 先頭のモデルがレート制限・過負荷・サーバーエラー・コンテキスト長の超過で失敗した場合に、次のモデルで同じプロンプトを送信し、
+// 最終的に応答したモデルをコンテキストの Recorder に記録します。
+package modelchain
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/shouni/gemini-reviewer-core/pkg/adapters"
+)
+
+// Split は --gemini の値 (カンマ区切り) を、先頭のモデルとフォールバックのモデルに分けます。空の要素と重複は取り除きます。
+func Split(s string) (primary string, fallbacks []string) {
+	var models []string
+	for _, m := range strings.Split(s, ",") {
+		if m = strings.TrimSpace(m); m != "" && !slices.Contains(models, m) {
+			models = append(models, m)
+		}
+	}
+	if len(models) == 0 {
+		return "", nil
+	}
+	return models[0], models[1:]
+}
+
+// fallbackPattern は、次のモデルで再試行すべきエラーのメッセージです。
+// AI プロバイダごとにエラーの型が異なるため、HTTP のステータスと API のエラーの種類をメッセージから判定します。
+var fallbackPattern = regexp.MustCompile(`(?i)(\b429\b|\b50[0-4]\b|resource[_ ]exhausted|rate[_ ]?limit|quota|overloaded|unavailable|internal error|` +
+	`context[_ ]length|context window|maximum context|too many tokens|token limit|input token count|exceeds the maximum number of tokens|prompt is too long)`)
+
+// ShouldFallback は、err が次のモデルで再試行すべきエラー (レート制限・過負荷・サーバーエラー・コンテキスト長の超過) かを判定します。
+func ShouldFallback(err error) bool {
+	return err != nil && fallbackPattern.MatchString(err.Error())
+}
+
+// Model は連鎖の1つのモデルです。
+type Model struct {
+	Name string
+	AI   adapters.CodeReviewAI
+}
+
+// Chain はモデルを順に試す adapters.CodeReviewAI です。
+type Chain struct {
+	models []Model
+}
+
+// New は models を先頭から順に試す Chain を生成します。
+func New(models ...Model) *Chain {
+	return &Chain{models: models}
+}
+
+// ReviewCodeDiff は adapters.CodeReviewAI を満たします。
+// ShouldFallback に該当するエラーの場合のみ次のモデルを試し、それ以外のエラーとコンテキストの終了はそのまま返します。
+func (c *Chain) ReviewCodeDiff(ctx context.Context, finalPrompt string) (string, error) {
+	var err error
+	for i, m := range c.models {
+		var result string
+		result, err = m.AI.ReviewCodeDiff(ctx, finalPrompt)
+		if err == nil {
+			if i > 0 {
+				slog.Warn("フォールバックのモデルが応答しました。", "model", m.Name, "primary", c.models[0].Name)
+			}
+			RecorderFrom(ctx).record(m.Name)
+			return result, nil
+		}
+		if ctx.Err() != nil || !ShouldFallback(err) || i == len(c.models)-1 {
+			break
+		}
+		slog.Warn("モデルの呼び出しに失敗したため、次のモデルで再試行します。", "model", m.Name, "next", c.models[i+1].Name, "error", err)
+	}
+	if len(c.models) > 1 && ShouldFallback(err) {
+		return "", fmt.Errorf("すべてのモデル (%s) の呼び出しに失敗しました: %w", c.names(), err)
+	}
+	return "", err
+}
+
+// names はモデル名をカンマ区切りで返します。
+func (c *Chain) names() string {
+	names := make([]string, len(c.models))
+	for i, m := range c.models {
+		names[i] = m.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// Recorder は、連鎖のうち応答したモデルを記録します。
+type Recorder struct {
+	mu    sync.Mutex
+	model string
+}
+
+// Model は最後に応答したモデルを返します。連鎖を使用していない場合は空文字列です。
+func (r *Recorder) Model() string {
+	if r == nil {
+		return ""
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.model
+}
+
+// record は応答したモデルを記録します。r が nil の場合は何もしません。
+func (r *Recorder) record(model string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.model = model
+}
+
+type recorderKey struct{}
+
+// WithRecorder は、応答したモデルを記録する Recorder をコンテキストに設定します。
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// RecorderFrom はコンテキストに設定された Recorder を返します。設定されていない場合は nil です。
+func RecorderFrom(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return r
+}
@@ -0,0 +1,228 @@
+package monorepo
+
+import (
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// RootModule は、どのモジュール境界にも属さないファイルをまとめるモジュール名です。
+const RootModule = "."
+
+// DefaultMarkers はモジュール境界とみなすビルド定義ファイルの名前です。
+var DefaultMarkers = []string{
+	"go.mod",
+	"package.json",
+	"pom.xml",
+	"build.gradle",
+	"build.gradle.kts",
+	"Cargo.toml",
+	"pyproject.toml",
+	"setup.py",
+	"composer.json",
+	"Gemfile",
+	"BUILD",
+	"BUILD.bazel",
+}
+
+// skipDirs は境界の探索時に辿らないディレクトリです。依存パッケージの展開先にあるビルド定義を誤検出しないようにします。
+var skipDirs = map[string]bool{
+	".git":         true,
+	"node_modules": true,
+	"vendor":       true,
+	"third_party":  true,
+}
+
+// FileDiff は unified diff のうち、1ファイル分の差分です。
+type FileDiff struct {
+	Path    string
+	Content string
+}
+
+// Module は1つのモジュール境界に属する変更をまとめたものです。
+type Module struct {
+	// Root はリポジトリルートからのモジュールのディレクトリです。境界に属さない場合は RootModule です。
+	Root  string
+	Files []string
+	Diff  string
+}
+
+// SplitDiff は unified diff をファイル単位に分割します。
+// "diff --git" ヘッダを優先し、存在しない場合は "--- " / "+++ " のヘッダ対で区切ります。
+func SplitDiff(diff string) []FileDiff {
+	lines := strings.SplitAfter(diff, "\n")
+	gitHeader := strings.Contains(diff, "diff --git ")
+
+	var (
+		files    []FileDiff
+		current  *strings.Builder
+		filePath string
+		inHunk   bool
+	)
+	flush := func() {
+		if current != nil && strings.TrimSpace(current.String()) != "" {
+			files = append(files, FileDiff{Path: filePath, Content: current.String()})
+		}
+	}
+
+	for i, line := range lines {
+		start := false
+		if gitHeader {
+			start = strings.HasPrefix(line, "diff --git ")
+		} else {
+			start = strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ")
+		}
+		if start {
+			flush()
+			current = &strings.Builder{}
+			filePath = ""
+			inHunk = false
+		}
+		if current == nil {
+			// 最初のファイルヘッダより前の前置き (format-patch のメールヘッダ等) は捨てます。
+			continue
+		}
+		current.WriteString(line)
+		if strings.HasPrefix(line, "@@") {
+			inHunk = true
+		}
+		// ハンク内の "--- " / "+++ " で始まる行は変更内容のため、パスの判定に使用しません。
+		if inHunk {
+			continue
+		}
+		if p := headerPath(line); p != "" && (filePath == "" || strings.HasPrefix(line, "+++ ")) {
+			filePath = p
+		}
+	}
+	flush()
+	return files
+}
+
+// headerPath は差分ヘッダ行から変更後のファイルパスを取り出します。削除されたファイルは変更前のパスを返します。
+func headerPath(line string) string {
+	line = strings.TrimRight(line, "\r\n")
+	switch {
+	case strings.HasPrefix(line, "diff --git "):
+		fields := strings.Fields(strings.TrimPrefix(line, "diff --git "))
+		if len(fields) == 2 {
+			return stripPrefix(fields[1])
+		}
+	case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+		p := strings.TrimSpace(line[4:])
+		if i := strings.IndexByte(p, '\t'); i >= 0 {
+			p = p[:i]
+		}
+		if p != "/dev/null" {
+			return stripPrefix(p)
+		}
+	}
+	return ""
+}
+
+// stripPrefix は git の a/ b/ 接頭辞を取り除きます。
+func stripPrefix(p string) string {
+	if strings.HasPrefix(p, "a/") || strings.HasPrefix(p, "b/") {
+		return p[2:]
+	}
+	return p
+}
+
+// FindRoots は fsys を走査し、ビルド定義ファイルを含むディレクトリをモジュールのルートとして返します。
+func FindRoots(fsys fs.FS, markers []string) ([]string, error) {
+	markerSet := toSet(markers)
+	seen := map[string]bool{}
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != "." && skipDirs[d.Name()] {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if markerSet[d.Name()] {
+			seen[path.Dir(p)] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sortedKeys(seen), nil
+}
+
+// RootsFromPaths は変更されたパスに含まれるビルド定義ファイルからモジュールのルートを返します。
+// フィーチャーブランチで新たに追加されたモジュールを検出するために使用します。
+func RootsFromPaths(paths []string, markers []string) []string {
+	markerSet := toSet(markers)
+	seen := map[string]bool{}
+	for _, p := range paths {
+		if markerSet[path.Base(p)] {
+			seen[path.Dir(p)] = true
+		}
+	}
+	return sortedKeys(seen)
+}
+
+// Group はファイル単位の差分を、最も深い (最長一致する) モジュールのルートごとにまとめます。
+// 結果はルートの辞書順に並びます。
+func Group(files []FileDiff, roots []string) []Module {
+	byRoot := map[string]*Module{}
+	for _, f := range files {
+		root := owningRoot(f.Path, roots)
+		m, ok := byRoot[root]
+		if !ok {
+			m = &Module{Root: root}
+			byRoot[root] = m
+		}
+		m.Files = append(m.Files, f.Path)
+		m.Diff += f.Content
+	}
+
+	modules := make([]Module, 0, len(byRoot))
+	for _, root := range sortedKeys(toBoolMap(byRoot)) {
+		modules = append(modules, *byRoot[root])
+	}
+	return modules
+}
+
+// owningRoot は p を含む最も深いモジュールのルートを返します。
+func owningRoot(p string, roots []string) string {
+	best, bestLen := RootModule, 0
+	for _, root := range roots {
+		if root == RootModule {
+			continue
+		}
+		if strings.HasPrefix(p, root+"/") && len(root) > bestLen {
+			best, bestLen = root, len(root)
+		}
+	}
+	return best
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+func toBoolMap(m map[string]*Module) map[string]bool {
+	out := make(map[string]bool, len(m))
+	for k := range m {
+		out[k] = true
+	}
+	return out
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
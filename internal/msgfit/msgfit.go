@@ -0,0 +1,218 @@
+// Package msgfit は、投稿先ごとの1メッセージの大きさの上限と、上限に収まるよう内容を分割・要約する処理を提供します。
+// 各投稿先が上限を超えたメッセージを拒否したり、途中で切り捨てたりすることを防ぐため、投稿の直前に適用します。
+package msgfit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Unit はメッセージの大きさの単位です。
+type Unit int
+
+const (
+	// Runes は文字数 (rune 数) です。
+	Runes Unit = iota
+	// Bytes は UTF-8 のバイト数です。
+	Bytes
+	// SlackBytes は、go-notifier が Markdown を Slack の mrkdwn に変換した後のバイト数の上限の見積もりです。
+	SlackBytes
+)
+
+// Limit は1メッセージの大きさの上限です。
+type Limit struct {
+	// Max は上限です。0 以下は無制限です。
+	Max int
+	// Unit は Max の単位です。
+	Unit Unit
+}
+
+// 投稿先ごとの上限です。
+var (
+	// Slack は Webhook のセクションブロックの上限です。go-notifier は 2900 バイトを超えるセクションを途中で切り捨てます。
+	Slack = Limit{Max: 2900, Unit: SlackBytes}
+	// SlackThread は、slack-app がスレッドに返信する1メッセージの文字数です。
+	SlackThread = Limit{Max: 3000, Unit: Runes}
+	// Backlog は課題コメントとプルリクエストのコメントの文字数です。
+	// Backlog が長すぎるコメントを拒否する前に切り替えられるよう、余裕を持たせた値としています。
+	Backlog = Limit{Max: 50000, Unit: Runes}
+	// GitHub はレビュー本文とコメントの文字数です。
+	GitHub = Limit{Max: 65536, Unit: Runes}
+	// Bitbucket はコメントの文字数です (Bitbucket Data Center の上限に合わせています)。
+	Bitbucket = Limit{Max: 32768, Unit: Runes}
+	// CodeCommit はプルリクエストのコメントの文字数です。
+	CodeCommit = Limit{Max: 10240, Unit: Runes}
+	// Teams は Webhook の1メッセージのバイト数です。Teams はカード全体で約 28KB を超えるメッセージを拒否するため、
+	// 見出しやカードの JSON の分を差し引いた値としています。
+	Teams = Limit{Max: 20000, Unit: Bytes}
+	// Discord は埋め込み (embed) の本文の文字数です。メッセージの本文 (2000 文字) ではなく、上限の大きい埋め込みの本文 (4096 文字) に収めます。
+	Discord = Limit{Max: 4096, Unit: Runes}
+	// Gerrit はレビューメッセージのバイト数です (change.commentSizeLimit の既定値)。
+	Gerrit = Limit{Max: 16384, Unit: Bytes}
+)
+
+// Chars は文字数の上限を返します。
+func Chars(max int) Limit {
+	return Limit{Max: max, Unit: Runes}
+}
+
+// fence はコードブロックの区切りです。
+const fence = "```"
+
+// slackListItem は、go-notifier が '• ' (4バイト) に置き換えるリスト項目の行頭 ('- ' の2バイト) です。
+var slackListItem = regexp.MustCompile(`^\s*-\s+`)
+
+// Size は、s の大きさを上限の単位で返します。
+func (l Limit) Size(s string) int {
+	switch l.Unit {
+	case Bytes:
+		return len(s)
+	case SlackBytes:
+		n := len(s)
+		for _, line := range strings.Split(s, "\n") {
+			if slackListItem.MatchString(line) {
+				n += 2
+			}
+		}
+		return n
+	default:
+		return utf8.RuneCountInString(s)
+	}
+}
+
+// Fits は、s が上限に収まるかを返します。
+func (l Limit) Fits(s string) bool {
+	return l.Max <= 0 || l.Size(s) <= l.Max
+}
+
+// Minus は、s を付け加える分だけ小さくした上限を返します。フッターなど、必ず残す内容の分を差し引くために使用します。
+func (l Limit) Minus(s string) Limit {
+	if l.Max <= 0 {
+		return l
+	}
+	l.Max = max(l.Max-l.Size(s), 1)
+	return l
+}
+
+// Split は、content を上限に収まる複数のメッセージに分割します。上限に収まる場合はそのまま返します。
+// 行の区切りで分割し、十分な長さがあれば見出しの前で区切ります。コードブロックの途中で区切る場合は、
+// 前のメッセージで閉じて次のメッセージで同じ言語の指定で開き直します。2件以上に分割した場合は、各メッセージの先頭に '(2/3)' のような番号を付けます。
+func Split(content string, l Limit) []string {
+	if l.Fits(content) {
+		return []string{content}
+	}
+	// 番号とコードブロックの閉じ直し・開き直しの分を差し引く
+	budget := max(l.Max-len("(999/999)\n\n")-2*len(fence+"\n"), 1)
+
+	var parts []string
+	var current strings.Builder
+	size := 0
+	inFence := false
+	opener := fence
+	flush := func() {
+		part := current.String()
+		if inFence {
+			if !strings.HasSuffix(part, "\n") {
+				part += "\n"
+			}
+			part += fence + "\n"
+		}
+		parts = append(parts, part)
+		current.Reset()
+		size = 0
+		if inFence {
+			current.WriteString(opener + "\n")
+			size = l.Size(opener + "\n")
+		}
+	}
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		lineSize := l.Size(line)
+		if size > 0 && strings.HasPrefix(line, "#") && !inFence && size >= budget/2 {
+			flush()
+		}
+		if size > 0 && size+lineSize > budget {
+			flush()
+		}
+		for lineSize > budget-size {
+			head := prefix(line, budget-size, l)
+			current.WriteString(head)
+			line = line[len(head):]
+			lineSize = l.Size(line)
+			flush()
+		}
+		current.WriteString(line)
+		size += lineSize
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, fence) {
+			inFence = !inFence
+			opener = trimmed
+		}
+	}
+	if strings.TrimSpace(current.String()) != "" {
+		inFence = false
+		flush()
+	}
+
+	if len(parts) > 1 {
+		for i := range parts {
+			parts[i] = fmt.Sprintf("(%d/%d)\n\n", i+1, len(parts)) + parts[i]
+		}
+	}
+	return parts
+}
+
+// Summarize は、content が上限を超える場合に、収まる範囲の先頭の行に note を付けた要約を返します。
+// 1件のメッセージしか投稿できない投稿先で使用します。上限に収まる場合はそのまま返します。
+func Summarize(content string, l Limit, note string) string {
+	if l.Fits(content) {
+		return content
+	}
+	tail := "\n\n" + note
+	budget := max(l.Max-l.Size(tail)-l.Size(fence+"\n"), 1)
+
+	var kept strings.Builder
+	size := 0
+	inFence := false
+	for _, line := range strings.SplitAfter(content, "\n") {
+		lineSize := l.Size(line)
+		if size+lineSize > budget {
+			if size == 0 {
+				kept.WriteString(prefix(line, budget, l))
+			}
+			break
+		}
+		kept.WriteString(line)
+		size += lineSize
+		if strings.HasPrefix(strings.TrimSpace(line), fence) {
+			inFence = !inFence
+		}
+	}
+	summary := strings.TrimRight(kept.String(), "\n")
+	if inFence {
+		summary += "\n" + fence
+	}
+	return summary + tail
+}
+
+// prefix は、上限の単位で n 以下となる s の最長の先頭部分を返します。少なくとも1文字は返します。
+func prefix(s string, n int, l Limit) string {
+	size := 0
+	if l.Unit == SlackBytes && slackListItem.MatchString(s) {
+		size = 2
+	}
+	end := 0
+	for i, r := range s {
+		w := utf8.RuneLen(r)
+		if l.Unit == Runes {
+			w = 1
+		}
+		if end > 0 && size+w > n {
+			break
+		}
+		size += w
+		end = i + utf8.RuneLen(r)
+	}
+	return s[:end]
+}
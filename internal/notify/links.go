@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// LinkMatrix は、配信先 (キー) の投稿に、どの配信先 (値) のパーマリンクを添えるかを表します。
+// 例えば {"slack": {"gcs"}} は、Slack のメッセージに GCS に保存した HTML レポートへのリンクを添えることを意味します。
+type LinkMatrix map[string][]string
+
+// ParseLinkMatrix は "投稿先=リンク元1|リンク元2" 形式の指定から LinkMatrix を作成します。
+func ParseLinkMatrix(specs []string) (LinkMatrix, error) {
+	matrix := LinkMatrix{}
+	for _, spec := range specs {
+		target, sources, ok := strings.Cut(spec, "=")
+		target = strings.TrimSpace(target)
+		if !ok || target == "" || strings.TrimSpace(sources) == "" {
+			return nil, fmt.Errorf("リンク指定が不正です: '%s' ('投稿先=リンク元1|リンク元2' の形式で指定してください)", spec)
+		}
+		for _, source := range strings.Split(sources, "|") {
+			source = strings.TrimSpace(source)
+			if source == "" {
+				continue
+			}
+			if source == target {
+				return nil, fmt.Errorf("投稿先 '%s' に自身へのリンクは指定できません", target)
+			}
+			matrix[target] = append(matrix[target], source)
+		}
+	}
+	return matrix, nil
+}
+
+// Validate は、リンク指定が destinations に対して有効か (未知の配信先や循環がないか) を検証します。
+func (m LinkMatrix) Validate(destinations []Destination) error {
+	_, err := m.order(destinations)
+	return err
+}
+
+// order は、リンク元の配信先がリンク先より先に配信されるよう destinations を並べ替えます。
+// 依存関係のない配信先は元の順序を保ちます。未知の配信先の指定や循環がある場合はエラーを返します。
+func (m LinkMatrix) order(destinations []Destination) ([]Destination, error) {
+	index := make(map[string]int, len(destinations))
+	for i, d := range destinations {
+		index[d.Name] = i
+	}
+	for _, target := range m.sortedTargets() {
+		if _, ok := index[target]; !ok {
+			return nil, fmt.Errorf("リンク指定の投稿先 '%s' は配信先に含まれていません", target)
+		}
+		for _, source := range m[target] {
+			if _, ok := index[source]; !ok {
+				return nil, fmt.Errorf("リンク指定のリンク元 '%s' は配信先に含まれていません", source)
+			}
+		}
+	}
+
+	ordered := make([]Destination, 0, len(destinations))
+	state := make(map[string]int, len(destinations)) // 0: 未訪問, 1: 訪問中, 2: 完了
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("リンク指定が循環しています ('%s' を含む)", name)
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		for _, source := range m[name] {
+			if err := visit(source); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		ordered = append(ordered, destinations[index[name]])
+		return nil
+	}
+	for _, d := range destinations {
+		if err := visit(d.Name); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// withLinks は、先に配信済みのリンク元のパーマリンクを content の末尾に追記します。
+// リンク元の配信が失敗した場合やパーマリンクが取得できない場合、そのリンクは省略します。
+func (m LinkMatrix) withLinks(target, content string, permalinks map[string]string) string {
+	var lines []string
+	for _, source := range m[target] {
+		if url := permalinks[source]; url != "" {
+			lines = append(lines, fmt.Sprintf("- %s: %s", source, url))
+		}
+	}
+	if len(lines) == 0 {
+		return content
+	}
+	return content + "\n\n---\n**🔗 関連リンク**\n" + strings.Join(lines, "\n") + "\n"
+}
+
+func (m LinkMatrix) sortedTargets() []string {
+	targets := make([]string, 0, len(m))
+	for t := range m {
+		targets = append(targets, t)
+	}
+	sort.Strings(targets)
+	return targets
+}
@@ -0,0 +1,109 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"git-gemini-reviewer-go/internal/aggregate"
+)
+
+// PostFunc はレビュー結果を1つの投稿先に配信する関数です。
+// 投稿先が作成したコメントやオブジェクトのURL (パーマリンク) が分かる場合はそれを返し、分からない場合は空文字列を返します。
+type PostFunc func(ctx context.Context, content string) (permalink string, err error)
+
+// Destination は配信先の名前と配信処理の組です。
+type Destination struct {
+	Name string
+	Post PostFunc
+}
+
+// Result は1つの配信先への配信結果です。
+type Result struct {
+	Name      string
+	Permalink string
+	Latency   time.Duration
+	Err       error
+}
+
+// Succeeded は配信が成功したかを返します。
+func (r Result) Succeeded() bool {
+	return r.Err == nil
+}
+
+// FanOutOption は FanOut の動作を変更する関数です。
+type FanOutOption func(*fanOutSettings)
+
+type fanOutSettings struct {
+	links LinkMatrix
+}
+
+// WithLinks は、配信済みの投稿先のパーマリンクを後続の投稿に添えるリンク指定を設定します。
+func WithLinks(m LinkMatrix) FanOutOption {
+	return func(s *fanOutSettings) {
+		s.links = m
+	}
+}
+
+// FanOut はすべての配信先に順番にレビュー結果を配信します。
+// 一部の配信先が失敗しても残りの配信は継続し、失敗は *aggregate.MultiError にまとめて返します。
+// WithLinks が指定された場合、リンク元の配信先を先に配信し、取得したパーマリンクをリンク先の投稿に追記します。
+func FanOut(ctx context.Context, destinations []Destination, content string, opts ...FanOutOption) ([]Result, error) {
+	var settings fanOutSettings
+	for _, opt := range opts {
+		opt(&settings)
+	}
+	if len(settings.links) > 0 {
+		ordered, err := settings.links.order(destinations)
+		if err != nil {
+			return nil, err
+		}
+		destinations = ordered
+	}
+
+	collector := aggregate.NewCollector("投稿")
+	results := make([]Result, 0, len(destinations))
+	permalinks := make(map[string]string, len(destinations))
+
+	for _, d := range destinations {
+		if err := ctx.Err(); err != nil {
+			// キャンセルされた場合、未配信の投稿先はすべて失敗として記録します。
+			results = append(results, Result{Name: d.Name, Err: err})
+			collector.Add(d.Name, err)
+			continue
+		}
+
+		startedAt := time.Now()
+		permalink, err := d.Post(ctx, settings.links.withLinks(d.Name, content, permalinks))
+		latency := time.Since(startedAt)
+		results = append(results, Result{Name: d.Name, Permalink: permalink, Latency: latency, Err: err})
+		collector.Add(d.Name, err)
+		if err != nil {
+			slog.Error("投稿先への配信に失敗しました。残りの投稿先への配信を継続します。", "destination", d.Name, "latency", latency, "error", err)
+			continue
+		}
+		permalinks[d.Name] = permalink
+		slog.Info("投稿先への配信が完了しました。", "destination", d.Name, "latency", latency, "permalink", permalink)
+	}
+
+	return results, collector.Err()
+}
+
+// Summary は配信結果を人が読める形式で1行にまとめます。
+func Summary(results []Result) string {
+	succeeded, failed := 0, 0
+	var failedNames []string
+	for _, r := range results {
+		if r.Succeeded() {
+			succeeded++
+			continue
+		}
+		failed++
+		failedNames = append(failedNames, r.Name)
+	}
+	if failed == 0 {
+		return fmt.Sprintf("すべての投稿先 (%d 件) への配信に成功しました。", succeeded)
+	}
+	return fmt.Sprintf("%d 件の投稿先に配信しました。配信できなかった投稿先: %v", succeeded, failedNames)
+}
@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"git-gemini-reviewer-go/internal/schema"
+)
+
+// 配信レポートにおける配信先ごとの状態です。
+const (
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed"
+)
+
+// Delivery は配信レポートの1行 (1つの配信先) です。
+type Delivery struct {
+	Destination string `json:"destination"`
+	Status      string `json:"status"`
+	LatencyMS   int64  `json:"latency_ms"`
+	Permalink   string `json:"permalink,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Report はファンアウト配信の結果をまとめた構造化レポートです。
+// CI からレビュー結果が実際にレビュアーへ届いたかを検証できるよう、JSON として出力します。
+type Report struct {
+	// SchemaVersion はレポートのスキーマのバージョン (schema.Version) です。
+	SchemaVersion int    `json:"schema_version"`
+	ReviewID      string `json:"review_id"`
+	// PromptVariant はプロンプトの A/B 実験で割り当てた派生です。実験を行わない場合は空です。
+	PromptVariant string     `json:"prompt_variant,omitempty"`
+	GeneratedAt   time.Time  `json:"generated_at"`
+	Delivered     int        `json:"delivered"`
+	Failed        int        `json:"failed"`
+	Deliveries    []Delivery `json:"deliveries"`
+}
+
+// NewReport は配信結果から Report を作成します。
+func NewReport(reviewID, promptVariant string, results []Result) Report {
+	report := Report{
+		SchemaVersion: schema.Version,
+		ReviewID:      reviewID,
+		PromptVariant: promptVariant,
+		GeneratedAt:   time.Now(),
+		Deliveries:    make([]Delivery, 0, len(results)),
+	}
+	for _, r := range results {
+		d := Delivery{
+			Destination: r.Name,
+			Status:      StatusDelivered,
+			LatencyMS:   r.Latency.Milliseconds(),
+			Permalink:   r.Permalink,
+		}
+		if r.Err != nil {
+			d.Status = StatusFailed
+			d.Error = r.Err.Error()
+			report.Failed++
+		} else {
+			report.Delivered++
+		}
+		report.Deliveries = append(report.Deliveries, d)
+	}
+	return report
+}
+
+// Log は配信先ごとの結果を構造化ログとして出力します。
+func (r Report) Log() {
+	for _, d := range r.Deliveries {
+		attrs := []any{
+			"review_id", r.ReviewID,
+			"destination", d.Destination,
+			"status", d.Status,
+			"latency_ms", d.LatencyMS,
+		}
+		if r.PromptVariant != "" {
+			attrs = append(attrs, "prompt_variant", r.PromptVariant)
+		}
+		if d.Permalink != "" {
+			attrs = append(attrs, "permalink", d.Permalink)
+		}
+		if d.Error != "" {
+			attrs = append(attrs, "error", d.Error)
+		}
+		slog.Info("配信レポート", attrs...)
+	}
+}
+
+// WriteJSON はレポートを整形済み JSON として w に書き込みます。
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return fmt.Errorf("配信レポートの書き込みに失敗しました: %w", err)
+	}
+	return nil
+}
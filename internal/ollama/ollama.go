@@ -0,0 +1,248 @@
+// Package ollama は、ローカルの Ollama サーバーの /api/chat でレビューを依頼する adapters.CodeReviewAI を提供します。
+// 差分を外部の API に送信せずにレビューできるため、機密性の高いリポジトリに使用します。
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+
+	"git-gemini-reviewer-go/internal/pkg/retry"
+	"git-gemini-reviewer-go/internal/ratelimit"
+	"git-gemini-reviewer-go/internal/sampling"
+	"git-gemini-reviewer-go/internal/streamai"
+	"git-gemini-reviewer-go/internal/tokenusage"
+
+	"github.com/shouni/gemini-reviewer-core/pkg/adapters"
+)
+
+// DefaultURL は Ollama サーバーの既定の URL です。
+const DefaultURL = "http://localhost:11434"
+
+const (
+	// outputReserve は、コンテキスト長を自動で決める際に応答のために確保するトークン数です。
+	outputReserve = 8192
+	// minNumCtx と numCtxStep は、自動で決めるコンテキスト長の下限と刻みです。
+	// 刻みを揃えることで、プロンプトの長さが少し変わっただけでモデルが再読み込みされるのを避けます。
+	minNumCtx  = 8192
+	numCtxStep = 4096
+)
+
+// Adapter は、Ollama の /api/chat を呼び出す adapters.CodeReviewAI です。
+// 応答はストリーミングで受信し、コンテキストに書き出し先 (streamai.WithWriter) が設定されている場合は逐次書き出します。
+type Adapter struct {
+	httpClient *http.Client
+	baseURL    string
+	model      string
+	// numCtx はコンテキスト長 (num_ctx) です。0 の場合はプロンプトの長さから決めます。
+	numCtx int
+	params sampling.Params
+}
+
+var _ adapters.CodeReviewAI = (*Adapter)(nil)
+
+// New は、baseURL の Ollama サーバーで model を呼び出す Adapter を返します。
+// baseURL が空の場合は環境変数 OLLAMA_HOST、未設定時は DefaultURL を使用します。
+// numCtx はコンテキスト長で、0 の場合はリクエストごとにプロンプトの長さから決めます。
+// Ollama の既定のコンテキスト長は短く、超えたプロンプトは警告なく切り詰められるため、差分の全体が読まれるよう明示的に指定します。
+// params は応答の生成を調整するパラメータで、出力トークン数の上限は num_predict として指定します。
+func New(httpClient *http.Client, model, baseURL string, numCtx int, params sampling.Params) (*Adapter, error) {
+	if baseURL == "" {
+		baseURL = os.Getenv("OLLAMA_HOST")
+	}
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	// OLLAMA_HOST は '127.0.0.1:11434' のようにスキームを省略して指定されることがある
+	if !strings.Contains(baseURL, "://") {
+		baseURL = "http://" + baseURL
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("Ollama サーバーの URL が不正です: '%s'", baseURL)
+	}
+	if !isLocal(u.Hostname()) {
+		slog.Warn("Ollama サーバーがローカルホストではありません。差分はこのサーバーに送信されます。", "url", u.Redacted())
+	}
+	if numCtx < 0 {
+		return nil, fmt.Errorf("Ollama のコンテキスト長には0以上の値を指定してください: %d", numCtx)
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Adapter{httpClient: httpClient, baseURL: strings.TrimSuffix(u.String(), "/"), model: model, numCtx: numCtx, params: params}, nil
+}
+
+// isLocal は、ホストがループバックアドレスまたは localhost かを判定します。
+func isLocal(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type options struct {
+	Temperature float64 `json:"temperature"`
+	TopP        float64 `json:"top_p,omitempty"`
+	NumCtx      int     `json:"num_ctx"`
+	NumPredict  int     `json:"num_predict,omitempty"`
+}
+
+type request struct {
+	Model    string    `json:"model"`
+	Messages []message `json:"messages"`
+	Stream   bool      `json:"stream"`
+	Options  options   `json:"options"`
+}
+
+// chunk はストリーミングの応答の1行です。
+type chunk struct {
+	Message         message `json:"message"`
+	Done            bool    `json:"done"`
+	DoneReason      string  `json:"done_reason"`
+	PromptEvalCount int     `json:"prompt_eval_count"`
+	EvalCount       int     `json:"eval_count"`
+	Error           string  `json:"error"`
+}
+
+// contextLength は、リクエストに指定するコンテキスト長を返します。
+func (a *Adapter) contextLength(prompt string) int {
+	if a.numCtx > 0 {
+		return a.numCtx
+	}
+	n := ratelimit.EstimateTokens(prompt) + outputReserve
+	n = (n + numCtxStep - 1) / numCtxStep * numCtxStep
+	return max(n, minNumCtx)
+}
+
+// ReviewCodeDiff はプロンプトを1件のユーザーメッセージとして送信し、応答の全体を返します。
+// サーバーに接続できない場合と、モデルが存在しない場合は、リトライしても解消しないため retry.Permanent で返します。
+func (a *Adapter) ReviewCodeDiff(ctx context.Context, finalPrompt string) (string, error) {
+	numCtx := a.contextLength(finalPrompt)
+	payload, err := json.Marshal(request{
+		Model:    a.model,
+		Messages: []message{{Role: "user", Content: finalPrompt}},
+		Stream:   true,
+		Options:  options{Temperature: a.params.Temperature, TopP: a.params.TopP, NumCtx: numCtx, NumPredict: a.params.MaxOutputTokens},
+	})
+	if err != nil {
+		return "", retry.Permanent(fmt.Errorf("Ollama のリクエストのエンコードに失敗しました: %w", err))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.baseURL+"/api/chat", bytes.NewReader(payload))
+	if err != nil {
+		return "", retry.Permanent(fmt.Errorf("Ollama のリクエストの作成に失敗しました: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		err = fmt.Errorf("Ollama サーバー (%s) の呼び出しに失敗しました: %w", a.baseURL, err)
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			return "", retry.Permanent(fmt.Errorf("%w ('ollama serve' でサーバーが起動しているか確認してください)", err))
+		}
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return "", a.statusError(resp)
+	}
+	return a.receive(ctx, resp.Body, numCtx)
+}
+
+// statusError はエラーのレスポンスをエラーに変換します。
+func (a *Adapter) statusError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	msg := strings.TrimSpace(string(body))
+	var c chunk
+	if json.Unmarshal(body, &c) == nil && c.Error != "" {
+		msg = c.Error
+	}
+	err := fmt.Errorf("Ollama がエラーを返しました (Model: %s, status: %d): %s", a.model, resp.StatusCode, msg)
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return retry.Permanent(fmt.Errorf("%w ('ollama pull %s' でモデルを取得してください)", err, a.model))
+	case resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests:
+		return retry.Permanent(err)
+	}
+	return err
+}
+
+// receive は改行区切りの JSON で送られる応答を受信し、書き出し先が設定されている場合は逐次書き出します。
+func (a *Adapter) receive(ctx context.Context, body io.Reader, numCtx int) (string, error) {
+	out := streamai.WriterFrom(ctx)
+	var b strings.Builder
+	fail := func(err error) (string, error) {
+		if out != nil && b.Len() > 0 {
+			_, _ = io.WriteString(out, streamai.InterruptedNote)
+		}
+		return "", fmt.Errorf("Ollama の応答の受信に失敗しました (Model: %s): %w", a.model, err)
+	}
+
+	reader := bufio.NewReader(body)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var c chunk
+			if jsonErr := json.Unmarshal(line, &c); jsonErr != nil {
+				return fail(fmt.Errorf("応答の解析に失敗しました: %w", jsonErr))
+			}
+			if c.Error != "" {
+				return fail(errors.New(c.Error))
+			}
+			b.WriteString(c.Message.Content)
+			if out != nil && c.Message.Content != "" {
+				if _, err := io.WriteString(out, c.Message.Content); err != nil {
+					return "", fmt.Errorf("応答の書き出しに失敗しました: %w", err)
+				}
+			}
+			if c.Done {
+				a.logUsage(c, numCtx)
+				tokenusage.Record(ctx, c.PromptEvalCount, c.EvalCount)
+				if strings.TrimSpace(b.String()) == "" {
+					return "", fmt.Errorf("Ollama から空の応答が返されました (Model: %s)", a.model)
+				}
+				return b.String(), nil
+			}
+		}
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return fail(ctxErr)
+			}
+			if errors.Is(err, io.EOF) {
+				return fail(fmt.Errorf("応答が完了を示さずに途切れました"))
+			}
+			return fail(err)
+		}
+	}
+}
+
+// logUsage は入力と出力のトークン数を記録し、出力トークン数の上限またはコンテキスト長に達した可能性がある場合は警告します。
+func (a *Adapter) logUsage(c chunk, numCtx int) {
+	slog.Info("Ollama の応答を受信しました。", "model", a.model, "prompt_tokens", c.PromptEvalCount, "output_tokens", c.EvalCount, "num_ctx", numCtx, "done_reason", c.DoneReason)
+	if a.params.MaxOutputTokens > 0 && c.EvalCount >= a.params.MaxOutputTokens {
+		slog.Warn("応答が出力トークン数の上限に達したため、途中で打ち切られました。--ai-max-output-tokens で大きな値を指定してください。",
+			"max_output_tokens", a.params.MaxOutputTokens, "output_tokens", c.EvalCount)
+		return
+	}
+	if c.DoneReason == "length" || c.PromptEvalCount+c.EvalCount >= numCtx {
+		slog.Warn("Ollama のコンテキスト長に達したため、プロンプトまたは応答が切り詰められた可能性があります。--ollama-num-ctx で大きな値を指定するか、--chunk-tokens で差分を分割してください。",
+			"num_ctx", numCtx, "prompt_tokens", c.PromptEvalCount, "output_tokens", c.EvalCount)
+	}
+}
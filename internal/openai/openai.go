@@ -0,0 +1,158 @@
+// Package openai は、OpenAI 互換の Chat Completions API でレビューを依頼する adapters.CodeReviewAI を提供します。
+// OpenAI のほか、Azure OpenAI や、社内の OpenAI 互換ゲートウェイ (LiteLLM、vLLM、Ollama など) にも接続できます。
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/pkg/retry"
+	"git-gemini-reviewer-go/internal/sampling"
+	"git-gemini-reviewer-go/internal/tokenusage"
+
+	"github.com/shouni/gemini-reviewer-core/pkg/adapters"
+)
+
+// DefaultBaseURL は OpenAI の API のベース URL です。
+const DefaultBaseURL = "https://api.openai.com/v1"
+
+// Adapter は、Chat Completions API を呼び出す adapters.CodeReviewAI です。
+type Adapter struct {
+	httpClient *http.Client
+	endpoint   string
+	model      string
+	params     sampling.Params
+	// header と key は認証ヘッダの名前と値です。
+	header string
+	key    string
+}
+
+var _ adapters.CodeReviewAI = (*Adapter)(nil)
+
+// New は、baseURL の Chat Completions API で model を呼び出す Adapter を返します。
+// baseURL が空の場合は環境変数 OPENAI_BASE_URL、未設定時は DefaultBaseURL を使用します。
+// baseURL のクエリ文字列 (Azure OpenAI の '?api-version=...' など) はリクエストの URL に引き継ぎます。
+// API キーは環境変数 OPENAI_API_KEY (Bearer 認証)、未設定時は AZURE_OPENAI_API_KEY ('api-key' ヘッダ) から読み込みます。
+// ローカルのゲートウェイなど認証が不要な接続先では、baseURL を指定すればキーを省略できます。
+// params は応答の生成を調整するパラメータです。
+func New(httpClient *http.Client, model, baseURL string, params sampling.Params) (*Adapter, error) {
+	if baseURL == "" {
+		baseURL = os.Getenv("OPENAI_BASE_URL")
+	}
+	custom := baseURL != ""
+	if !custom {
+		baseURL = DefaultBaseURL
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("OpenAI 互換 API のベース URL が不正です: '%s'", baseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/chat/completions"
+
+	a := &Adapter{httpClient: httpClient, endpoint: u.String(), model: model, params: params}
+	switch {
+	case os.Getenv("OPENAI_API_KEY") != "":
+		a.header, a.key = "Authorization", "Bearer "+os.Getenv("OPENAI_API_KEY")
+	case os.Getenv("AZURE_OPENAI_API_KEY") != "":
+		a.header, a.key = "api-key", os.Getenv("AZURE_OPENAI_API_KEY")
+	case !custom:
+		return nil, fmt.Errorf("OPENAI_API_KEY または AZURE_OPENAI_API_KEY が設定されていません")
+	}
+	if a.httpClient == nil {
+		a.httpClient = http.DefaultClient
+	}
+	return a, nil
+}
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type request struct {
+	Model       string    `json:"model"`
+	Messages    []message `json:"messages"`
+	Temperature float64   `json:"temperature"`
+	TopP        float64   `json:"top_p,omitempty"`
+	MaxTokens   int       `json:"max_tokens,omitempty"`
+}
+
+type response struct {
+	Choices []struct {
+		Message      message `json:"message"`
+		FinishReason string  `json:"finish_reason"`
+	} `json:"choices"`
+	// Usage は応答のトークン数です。返さない互換ゲートウェイでは nil です。
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
+
+// ReviewCodeDiff はプロンプトを1件のユーザーメッセージとして送信し、最初の候補の本文を返します。
+// レート制限 (429) を除くクライアントエラーは、リトライしても解消しないため retry.Permanent で返します。
+func (a *Adapter) ReviewCodeDiff(ctx context.Context, finalPrompt string) (string, error) {
+	payload, err := json.Marshal(request{
+		Model:       a.model,
+		Messages:    []message{{Role: "user", Content: finalPrompt}},
+		Temperature: a.params.Temperature,
+		TopP:        a.params.TopP,
+		MaxTokens:   a.params.MaxOutputTokens,
+	})
+	if err != nil {
+		return "", retry.Permanent(fmt.Errorf("OpenAI 互換 API のリクエストのエンコードに失敗しました: %w", err))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return "", retry.Permanent(fmt.Errorf("OpenAI 互換 API のリクエストの作成に失敗しました: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.key != "" {
+		req.Header.Set(a.header, a.key)
+	}
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("OpenAI 互換 API の呼び出しに失敗しました (Model: %s): %w", a.model, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("OpenAI 互換 API のレスポンスの読み込みに失敗しました: %w", err)
+	}
+
+	var out response
+	decodeErr := json.Unmarshal(body, &out)
+	if resp.StatusCode/100 != 2 {
+		msg := strings.TrimSpace(string(body))
+		if decodeErr == nil && out.Error != nil {
+			msg = out.Error.Message
+		}
+		err := fmt.Errorf("OpenAI 互換 API がエラーを返しました (Model: %s, status: %d): %s", a.model, resp.StatusCode, msg)
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+			return "", retry.Permanent(err)
+		}
+		return "", err
+	}
+	if decodeErr != nil {
+		return "", fmt.Errorf("OpenAI 互換 API のレスポンスのデコードに失敗しました: %w", decodeErr)
+	}
+	if len(out.Choices) == 0 || strings.TrimSpace(out.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("OpenAI 互換 API から空の応答が返されました (Model: %s)", a.model)
+	}
+	if out.Usage != nil {
+		tokenusage.Record(ctx, out.Usage.PromptTokens, out.Usage.CompletionTokens)
+	}
+	return out.Choices[0].Message.Content, nil
+}
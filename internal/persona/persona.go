@@ -0,0 +1,58 @@
+package persona
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+)
+
+//go:embed fragments/*.md
+var fragmentFS embed.FS
+
+// Names は選択可能なペルソナ名の一覧を返します。
+func Names() []string {
+	entries, err := fs.ReadDir(fragmentFS, "fragments")
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), ".md"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Compose は指定されたペルソナの指示を指定順に連結し、レビューモードのプロンプトの前に置く指示文を返します。
+// ペルソナは口調や重点の追加指示であり、出力構造などモードのテンプレートの制約は変更しません。
+// names が空の場合は空文字列を返します。
+func Compose(names []string) (string, error) {
+	if len(names) == 0 {
+		return "", nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## 🎭 レビュアーペルソナ (追加指示)\n\n")
+	sb.WriteString("以下のペルソナとしてレビューしてください。複数のペルソナが指定されている場合は、すべての観点を併せ持ってください。\n")
+	sb.WriteString("ただし、出力の構成や形式については、この後に続くレビュー指示の制約を必ず優先してください。\n\n")
+
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		fragment, err := fragmentFS.ReadFile("fragments/" + name + ".md")
+		if err != nil {
+			return "", fmt.Errorf("不明なペルソナです: '%s' (%v のいずれかを指定してください)", name, Names())
+		}
+		sb.Write(fragment)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("---\n\n")
+	return sb.String(), nil
+}
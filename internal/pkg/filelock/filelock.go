@@ -0,0 +1,8 @@
+// Package filelock は、同じファイルを共有する複数のプロセス (共有ボリューム上の CI ランナーなど) の間で、
+// ファイル全体へのアクセスを直列化するための勧告ロックを提供します。
+package filelock
+
+import "errors"
+
+// ErrUnsupported は、プラットフォームがファイルロックをサポートしないことを表します。
+var ErrUnsupported = errors.New("このプラットフォームではファイルロックはサポートされていません")
@@ -0,0 +1,20 @@
+//go:build !unix
+
+package filelock
+
+import "os"
+
+// Lock は、ファイルロックをサポートしないプラットフォームでは ErrUnsupported を返します。
+func Lock(*os.File) error {
+	return ErrUnsupported
+}
+
+// RLock は、ファイルロックをサポートしないプラットフォームでは ErrUnsupported を返します。
+func RLock(*os.File) error {
+	return ErrUnsupported
+}
+
+// Unlock は何もしません。
+func Unlock(*os.File) error {
+	return nil
+}
@@ -0,0 +1,23 @@
+//go:build unix
+
+package filelock
+
+import (
+	"os"
+	"syscall"
+)
+
+// Lock はファイル全体に排他ロックを取得します。他のプロセスがロック中の場合は解放されるまで待機します。
+func Lock(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+}
+
+// RLock はファイル全体に共有ロックを取得します。排他ロックを取得中のプロセスがある場合は解放されるまで待機します。
+func RLock(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_SH)
+}
+
+// Unlock はロックを解放します。
+func Unlock(f *os.File) error {
+	return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,33 @@
+package retry
+
+import "errors"
+
+// PermanentError はリトライしても解消しないエラー (認証エラー、設定不備、入力の不正など) を表します。
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent は err をリトライ対象外のエラーとしてマークします。err が nil の場合は nil を返します。
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	if IsPermanent(err) {
+		return err
+	}
+	return &PermanentError{Err: err}
+}
+
+// IsPermanent は err がリトライ対象外としてマークされているかを判定します。
+func IsPermanent(err error) bool {
+	var pe *PermanentError
+	return errors.As(err, &pe)
+}
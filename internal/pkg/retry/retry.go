@@ -0,0 +1,172 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy はリトライの間隔と回数の設定です。
+type Policy struct {
+	// MaxAttempts は初回を含む最大試行回数です。1 の場合はリトライしません。
+	MaxAttempts int
+	// InitialInterval は1回目のリトライまでの待機時間です。
+	InitialInterval time.Duration
+	// MaxInterval は待機時間の上限です。
+	MaxInterval time.Duration
+	// Multiplier はリトライごとに待機時間を増加させる倍率です。
+	Multiplier float64
+	// Jitter は待機時間をランダムに短縮する割合 (0〜1) です。複数プロセスのリトライが同時に集中するのを防ぎます。
+	Jitter float64
+	// Budget は全試行と待機を合わせた処理全体の制限時間です。0 の場合は制限しません。
+	Budget time.Duration
+}
+
+// DefaultPolicy は外部APIの呼び出しに使用する標準的なポリシーを返します。
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:     3,
+		InitialInterval: 1 * time.Second,
+		MaxInterval:     30 * time.Second,
+		Multiplier:      2.0,
+		Jitter:          0.5,
+	}
+}
+
+// Attempt は1回の試行の結果です。Hook に渡されます。
+type Attempt struct {
+	// Op は処理の名前です (例: "backlog.post_comment")。
+	Op string
+	// Number は1から始まる試行回数です。
+	Number int
+	Err    error
+	// Delay は次の試行までの待機時間です。これ以上リトライしない場合は 0 です。
+	Delay time.Duration
+	// Elapsed は最初の試行開始からの経過時間です。
+	Elapsed time.Duration
+}
+
+// Hook は失敗した試行ごとに呼び出される関数です。ログやメトリクスの記録に使用します。
+type Hook func(Attempt)
+
+// Option は Do の動作を変更する関数です。
+type Option func(*settings)
+
+type settings struct {
+	policy Policy
+	hooks  []Hook
+}
+
+// WithPolicy はポリシー全体を置き換えます。
+func WithPolicy(p Policy) Option {
+	return func(s *settings) {
+		s.policy = p
+	}
+}
+
+// WithMaxAttempts は最大試行回数を設定します。
+func WithMaxAttempts(n int) Option {
+	return func(s *settings) {
+		s.policy.MaxAttempts = n
+	}
+}
+
+// WithBudget は処理全体の制限時間を設定します。
+func WithBudget(d time.Duration) Option {
+	return func(s *settings) {
+		s.policy.Budget = d
+	}
+}
+
+// WithHook は失敗した試行ごとに呼び出される Hook を追加します。
+func WithHook(h Hook) Option {
+	return func(s *settings) {
+		s.hooks = append(s.hooks, h)
+	}
+}
+
+// Do は fn が成功するか、Permanent なエラーを返すか、試行回数または制限時間を使い切るまで fn を繰り返し実行します。
+// 失敗した試行は slog に記録され、WithHook で追加した Hook にも通知されます。
+func Do(ctx context.Context, op string, fn func(ctx context.Context) error, opts ...Option) error {
+	s := settings{policy: DefaultPolicy(), hooks: []Hook{logHook}}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	p := s.policy
+	if p.MaxAttempts < 1 {
+		p.MaxAttempts = 1
+	}
+
+	if p.Budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Budget)
+		defer cancel()
+	}
+
+	start := time.Now()
+	for n := 1; ; n++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		retryable := n < p.MaxAttempts && !IsPermanent(err) && ctx.Err() == nil
+		var delay time.Duration
+		if retryable {
+			delay = p.backoff(n)
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+				// 待機後に制限時間を超える場合はリトライせず終了します。
+				retryable, delay = false, 0
+			}
+		}
+
+		attempt := Attempt{Op: op, Number: n, Err: err, Delay: delay, Elapsed: time.Since(start)}
+		for _, h := range s.hooks {
+			h(attempt)
+		}
+
+		if !retryable {
+			if n == 1 || IsPermanent(err) {
+				return err
+			}
+			return fmt.Errorf("%s: %d 回試行しましたが失敗しました: %w", op, n, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s: リトライの待機中に中断されました: %w", op, errors.Join(ctx.Err(), err))
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff は n 回目の失敗後の待機時間を、指数バックオフにジッターを加えて計算します。
+func (p Policy) backoff(n int) time.Duration {
+	mult := p.Multiplier
+	if mult < 1 {
+		mult = 1
+	}
+	d := float64(p.InitialInterval) * math.Pow(mult, float64(n-1))
+	if p.MaxInterval > 0 && d > float64(p.MaxInterval) {
+		d = float64(p.MaxInterval)
+	}
+	if p.Jitter > 0 {
+		d -= d * min(p.Jitter, 1) * rand.Float64()
+	}
+	return time.Duration(d)
+}
+
+// logHook は失敗した試行を slog に記録する既定の Hook です。
+func logHook(a Attempt) {
+	if a.Delay > 0 {
+		slog.Warn("処理に失敗しました。リトライします。", "op", a.Op, "attempt", a.Number, "retry_in", a.Delay.Round(time.Millisecond), "error", a.Err)
+		return
+	}
+	slog.Debug("処理に失敗しました。リトライは行いません。", "op", a.Op, "attempt", a.Number, "elapsed", a.Elapsed.Round(time.Millisecond), "error", a.Err)
+}
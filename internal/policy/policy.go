@@ -0,0 +1,195 @@
+// Package policy は、複数リポジトリで共有するレビュー設定 (ポリシーパック) を提供します。
+// ポリシーパックは YAML ファイルとして一元管理し、名前で参照します。
+package policy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/diffstat"
+	"git-gemini-reviewer-go/internal/inline"
+	"git-gemini-reviewer-go/internal/persona"
+	"git-gemini-reviewer-go/internal/reviewmode"
+	"git-gemini-reviewer-go/internal/verdict"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 必須チェックの名前です。
+const (
+	// CheckTests は、本番コードの変更にテストの変更が伴っていることを求めます。
+	CheckTests = "tests"
+	// CheckDocs は、本番コードの変更にドキュメントの変更が伴っていることを求めます。
+	CheckDocs = "docs"
+)
+
+// Pack はポリシーパックです。未指定の項目はコマンドラインのデフォルト値のままとなります。
+type Pack struct {
+	Name     string   `yaml:"name"`
+	Mode     string   `yaml:"mode"`
+	Model    string   `yaml:"model"`
+	Personas []string `yaml:"personas"`
+	// Excludes はレビュー対象から除外するファイルのパターンです (例: 'vendor/', '*.pb.go')。
+	Excludes []string `yaml:"excludes"`
+	// CriticalPaths は、指摘の重大度を1段階引き上げる重要なパスのパターンです (例: 'auth/**')。
+	CriticalPaths []string `yaml:"critical_paths"`
+	MaxFiles      int      `yaml:"max_files"`
+	MaxHunks      int      `yaml:"max_hunks"`
+	// FailOn は、コマンドを失敗させる判定 ('blocked' または 'conditional') または指摘の重大度 ('critical', 'major', 'minor') のしきい値です。
+	FailOn string `yaml:"fail_on"`
+	// RequiredChecks は、満たされない場合にコマンドを失敗させるチェックです ('tests', 'docs')。
+	RequiredChecks []string `yaml:"required_checks"`
+	// Destinations は post コマンドの配信先です。
+	Destinations []string `yaml:"destinations"`
+}
+
+// Load は名前またはファイルパスでポリシーパックを読み込みます。
+// 名前の場合は dir 配下の <name>.yaml (または .yml) を読み込みます。
+func Load(nameOrPath, dir string) (Pack, error) {
+	path, err := resolve(nameOrPath, dir)
+	if err != nil {
+		return Pack{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Pack{}, fmt.Errorf("ポリシーパックの読み込みに失敗しました (%s): %w", path, err)
+	}
+
+	var p Pack
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	// 誤記した項目が黙って無視されないよう、未知の項目はエラーとします
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		return Pack{}, fmt.Errorf("ポリシーパックの解析に失敗しました (%s): %w", path, err)
+	}
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if err := p.Validate(); err != nil {
+		return Pack{}, fmt.Errorf("ポリシーパック '%s' が不正です: %w", p.Name, err)
+	}
+	return p, nil
+}
+
+// resolve はポリシーパックのファイルパスを決定します。
+func resolve(nameOrPath, dir string) (string, error) {
+	if strings.ContainsRune(nameOrPath, filepath.Separator) || strings.ContainsRune(nameOrPath, '/') ||
+		strings.HasSuffix(nameOrPath, ".yaml") || strings.HasSuffix(nameOrPath, ".yml") {
+		return nameOrPath, nil
+	}
+	for _, ext := range []string{".yaml", ".yml"} {
+		path := filepath.Join(dir, nameOrPath+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("ポリシーパックの確認に失敗しました (%s): %w", path, err)
+		}
+	}
+	return "", fmt.Errorf("ポリシーパック '%s' が見つかりません (検索先: %s)", nameOrPath, dir)
+}
+
+// Validate はポリシーパックの値を検証します。
+func (p Pack) Validate() error {
+	if p.Mode != "" {
+		if err := reviewmode.Validate(p.Mode); err != nil {
+			return fmt.Errorf("mode が不正です: %w", err)
+		}
+	}
+	if _, err := persona.Compose(p.Personas); err != nil {
+		return err
+	}
+	if p.MaxFiles < 0 || p.MaxHunks < 0 {
+		return fmt.Errorf("max_files と max_hunks には0以上を指定してください")
+	}
+	if _, err := ParseThreshold(p.FailOn); err != nil {
+		return err
+	}
+	return ValidateChecks(p.RequiredChecks)
+}
+
+// Threshold はコマンドを失敗させるしきい値です。判定 (Verdict) と指摘の重大度 (Severity) のいずれか一方を持ちます。
+// 空の Threshold はしきい値なしを表します。
+type Threshold struct {
+	Verdict  verdict.Verdict
+	Severity inline.Severity
+}
+
+// String はしきい値を指定時の表記で返します。
+func (t Threshold) String() string {
+	if t.Severity != "" {
+		return string(t.Severity)
+	}
+	return string(t.Verdict)
+}
+
+// ParseThreshold はしきい値を検証します。空文字列はしきい値なしを表します。
+// 判定 ('blocked', 'conditional') または指摘の重大度 ('critical', 'major', 'minor') を指定できます。
+func ParseThreshold(s string) (Threshold, error) {
+	normalized := strings.ToLower(strings.TrimSpace(s))
+	switch v := verdict.Verdict(normalized); v {
+	case "", verdict.Blocked, verdict.Conditional:
+		return Threshold{Verdict: v}, nil
+	}
+	if sev, err := inline.ParseSeverity(normalized); err == nil {
+		return Threshold{Severity: sev}, nil
+	}
+	return Threshold{}, fmt.Errorf("fail_on が不正です: '%s' (判定 '%s'、'%s' または重大度 %v のいずれかを指定してください)", s, verdict.Blocked, verdict.Conditional, inline.Severities())
+}
+
+// VerdictThreshold は、判定との照合に使用するしきい値を返します。
+// 重大度を指定した場合、構造化されていないレビュー結果は指摘ごとの重大度を持たないため、
+// 'critical' はリリース不可、'major' と 'minor' は条件付きリリース可以上の判定で代替します。
+func (t Threshold) VerdictThreshold() verdict.Verdict {
+	switch t.Severity {
+	case inline.Critical:
+		return verdict.Blocked
+	case inline.Major, inline.Minor:
+		return verdict.Conditional
+	}
+	return t.Verdict
+}
+
+// Exceeds は、判定がしきい値に達しているかを返します。
+// conditional を指定した場合は、条件付きリリース可とリリース不可の両方が該当します。
+func Exceeds(threshold, v verdict.Verdict) bool {
+	switch threshold {
+	case verdict.Blocked:
+		return v == verdict.Blocked
+	case verdict.Conditional:
+		return v == verdict.Blocked || v == verdict.Conditional
+	}
+	return false
+}
+
+// ValidateChecks は必須チェックの名前を検証します。
+func ValidateChecks(checks []string) error {
+	for _, c := range checks {
+		if c != CheckTests && c != CheckDocs {
+			return fmt.Errorf("不明な必須チェックです: '%s' ('%s' または '%s' を指定してください)", c, CheckTests, CheckDocs)
+		}
+	}
+	return nil
+}
+
+// EvaluateChecks は差分の変更構成に対して必須チェックを評価し、満たされなかったチェックの説明を返します。
+func EvaluateChecks(checks []string, stats diffstat.Stats) []string {
+	prod := stats.ByCategory[diffstat.Production].Files
+	var failed []string
+	for _, c := range checks {
+		switch c {
+		case CheckTests:
+			if stats.Untested() {
+				failed = append(failed, fmt.Sprintf("%s: 本番コード %d ファイルの変更に対して、テストの変更がありません", c, prod))
+			}
+		case CheckDocs:
+			if prod > 0 && stats.ByCategory[diffstat.Docs].Files == 0 {
+				failed = append(failed, fmt.Sprintf("%s: 本番コード %d ファイルの変更に対して、ドキュメントの変更がありません", c, prod))
+			}
+		}
+	}
+	return failed
+}
@@ -0,0 +1,119 @@
+// Package profile は、複数のリポジトリやチーム向けのレビュー設定を名前付きのプロファイルとしてまとめた設定ファイルを提供します。
+// 1つの設定ファイルで、リポジトリ・モデル・投稿先などの長いコマンドラインを置き換えることを目的とします。
+package profile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile は1つのプロファイルです。
+type Profile struct {
+	// Env は、未設定の場合に設定する環境変数です (例: チームごとの SLACK_WEBHOOK_URL)。
+	Env map[string]string `yaml:"env"`
+	// Flags はフラグ名 (先頭の '--' を除く) と値です。リストの値は要素ごとにフラグを指定した場合と同じです。
+	Flags map[string]any `yaml:",inline"`
+}
+
+// File は設定ファイルです。
+type File struct {
+	Profiles map[string]Profile `yaml:"profiles"`
+}
+
+// DefaultPath は設定ファイルのデフォルトパスを返します。
+func DefaultPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "config.yaml"
+	}
+	return filepath.Join(home, ".git-gemini-reviewer", "config.yaml")
+}
+
+// Load は設定ファイルを読み込みます。
+func Load(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return File{}, fmt.Errorf("設定ファイルが見つかりません: %s", path)
+	}
+	if err != nil {
+		return File{}, fmt.Errorf("設定ファイルの読み込みに失敗しました (%s): %w", path, err)
+	}
+
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	// 誤記した項目が黙って無視されないよう、未知の項目はエラーとします
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil {
+		return File{}, fmt.Errorf("設定ファイルの解析に失敗しました (%s): %w", path, err)
+	}
+	return f, nil
+}
+
+// Get は名前でプロファイルを取得します。
+func (f File) Get(name string) (Profile, error) {
+	p, ok := f.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("プロファイル '%s' が見つかりません (定義済み: %v)", name, f.Names())
+	}
+	return p, nil
+}
+
+// Names は定義されているプロファイルの名前を昇順で返します。
+func (f File) Names() []string {
+	names := make([]string, 0, len(f.Profiles))
+	for name := range f.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FlagNames はプロファイルが指定するフラグ名を昇順で返します。
+func (p Profile) FlagNames() []string {
+	names := make([]string, 0, len(p.Flags))
+	for name := range p.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Values は、フラグの値をコマンドラインで指定する場合の文字列に変換します。
+// リストの場合は要素ごとの値を返します。
+func (p Profile) Values(flag string) ([]string, error) {
+	switch v := p.Flags[flag].(type) {
+	case nil:
+		return nil, nil
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := scalar(item)
+			if err != nil {
+				return nil, fmt.Errorf("'%s' の値が不正です: %w", flag, err)
+			}
+			values = append(values, s)
+		}
+		return values, nil
+	default:
+		s, err := scalar(v)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' の値が不正です: %w", flag, err)
+		}
+		return []string{s}, nil
+	}
+}
+
+// scalar は YAML のスカラー値を文字列に変換します。
+func scalar(v any) (string, error) {
+	switch v.(type) {
+	case string, bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("文字列・数値・真偽値またはそのリストを指定してください (%T)", v)
+}
@@ -0,0 +1,98 @@
+// Package progress は、レビューパイプラインの段階の遷移を機械可読なイベントとして通知します。
+// CI のラッパーやサーバーの UI が、人向けのログを解析せずに進捗を表示できるようにするためのものです。
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Phase はパイプラインの段階です。失敗の収集 (aggregate) の段階名と揃えています。
+type Phase string
+
+const (
+	// PhaseCommand はコマンド全体 (レビューと投稿先への配信) です。
+	PhaseCommand Phase = "command"
+	// PhasePipeline はレビューパイプライン (差分の取得からフックの実行まで) です。
+	PhasePipeline  Phase = "pipeline"
+	PhaseDiff      Phase = "diff"
+	PhaseTransform Phase = "diff.transform"
+	PhaseReview    Phase = "ai.review"
+	PhaseHooks     Phase = "hooks"
+	PhaseHistory   Phase = "history"
+)
+
+// Status は段階の状態です。
+type Status string
+
+const (
+	Started   Status = "started"
+	Completed Status = "completed"
+	Failed    Status = "failed"
+	Skipped   Status = "skipped"
+)
+
+// Event は1回の段階の遷移です。
+type Event struct {
+	Time     time.Time `json:"time"`
+	ReviewID string    `json:"review_id,omitempty"`
+	Phase    Phase     `json:"phase"`
+	Status   Status    `json:"status"`
+	// Detail は段階の補足です (例: フックの段階、スキップの理由)。
+	Detail string `json:"detail,omitempty"`
+	// ElapsedMS は段階の開始からの経過時間です。started のイベントでは0です。
+	ElapsedMS int64  `json:"elapsed_ms,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Func はイベントを受け取るコールバックです。ライブラリとして利用する場合は、任意の Func をコンテキストに格納します。
+// 複数の goroutine から呼び出される場合があります。
+type Func func(Event)
+
+// JSONLines は、イベントを1行1件の JSON として w に書き込む Func を返します。
+func JSONLines(w io.Writer) Func {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		// 進捗の通知に失敗してもパイプラインは継続する
+		_ = enc.Encode(e)
+	}
+}
+
+type funcKey struct{}
+
+// NewContext は Func を格納したコンテキストを返します。
+func NewContext(ctx context.Context, fn Func) context.Context {
+	return context.WithValue(ctx, funcKey{}, fn)
+}
+
+// Emit は、コンテキストに Func が格納されている場合にイベントを通知します。
+func Emit(ctx context.Context, e Event) {
+	fn, _ := ctx.Value(funcKey{}).(Func)
+	if fn == nil {
+		return
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	fn(e)
+}
+
+// Start は段階の開始を通知し、段階の終了を通知する関数を返します。
+// 返した関数に nil を渡すと completed、エラーを渡すと failed を通知します。
+func Start(ctx context.Context, reviewID string, phase Phase, detail string) func(err error) {
+	startedAt := time.Now()
+	Emit(ctx, Event{Time: startedAt, ReviewID: reviewID, Phase: phase, Status: Started, Detail: detail})
+	return func(err error) {
+		e := Event{ReviewID: reviewID, Phase: phase, Status: Completed, Detail: detail, ElapsedMS: time.Since(startedAt).Milliseconds()}
+		if err != nil {
+			e.Status, e.Error = Failed, err.Error()
+		}
+		Emit(ctx, e)
+	}
+}
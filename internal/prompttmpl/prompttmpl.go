@@ -0,0 +1,170 @@
+// Package prompttmpl は、利用者が用意したファイルのプロンプトテンプレートでプロンプトを組み立てます。
+// 組み込みのテンプレートと異なり、差分に加えてリポジトリ・ブランチ・コミットの一覧をテンプレートから参照できます。
+package prompttmpl
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/shouni/gemini-reviewer-core/pkg/prompts"
+)
+
+// Ext は --prompt-dir のテンプレートファイルの拡張子です。ファイル名の拡張子を除いた部分がレビューモードです。
+const Ext = ".tmpl"
+
+// Data はテンプレートに渡す値です。
+type Data struct {
+	// DiffContent はレビュー対象の差分です。テンプレートで必ず参照する必要があります。
+	DiffContent   string
+	RepoURL       string
+	BaseBranch    string
+	FeatureBranch string
+	Mode          string
+	// Commits は差分に含まれるコミットです (新しい順)。パッチファイルや作業ツリーのレビューでは空です。
+	Commits []Commit
+}
+
+// Commit は Data に含めるコミットです。
+type Commit struct {
+	Hash    string
+	Author  string
+	Date    time.Time
+	Subject string
+	Body    string
+}
+
+// NewCommit はコミットメッセージを件名と本文に分けて Commit を返します。
+func NewCommit(hash, author string, date time.Time, message string) Commit {
+	subject, body, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return Commit{Hash: hash, Author: author, Date: date, Subject: subject, Body: strings.TrimSpace(body)}
+}
+
+// Builder は、レビューモードごとのテンプレートでプロンプトを組み立てます。
+// テンプレートのないレビューモードは fallback (組み込みのテンプレート) で組み立てます。
+type Builder struct {
+	// file は --prompt-file のテンプレートです。指定された場合はすべてのレビューモードで使用します。
+	file *template.Template
+	// modes は --prompt-dir のレビューモードごとのテンプレートです。
+	modes    map[string]*template.Template
+	fallback prompts.ReviewPromptBuilder
+}
+
+// Load は --prompt-file と --prompt-dir のテンプレートを読み込み、検証します。
+// どちらも指定されていない場合は nil を返します。
+func Load(file, dir string) (*Builder, error) {
+	if file != "" && dir != "" {
+		return nil, fmt.Errorf("--prompt-file と --prompt-dir は同時に指定できません")
+	}
+	b := &Builder{modes: make(map[string]*template.Template)}
+	switch {
+	case file != "":
+		tmpl, err := parseFile(file)
+		if err != nil {
+			return nil, err
+		}
+		b.file = tmpl
+	case dir != "":
+		paths, err := filepath.Glob(filepath.Join(dir, "*"+Ext))
+		if err != nil {
+			return nil, fmt.Errorf("プロンプトテンプレートのディレクトリの読み込みに失敗しました (%s): %w", dir, err)
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("プロンプトテンプレートのディレクトリに '*%s' のファイルがありません (%s)", Ext, dir)
+		}
+		for _, path := range paths {
+			tmpl, err := parseFile(path)
+			if err != nil {
+				return nil, err
+			}
+			b.modes[strings.TrimSuffix(filepath.Base(path), Ext)] = tmpl
+		}
+	default:
+		return nil, nil
+	}
+	return b, nil
+}
+
+// WithFallback は、テンプレートのないレビューモードで使用する PromptBuilder を設定します。
+func (b *Builder) WithFallback(fallback prompts.ReviewPromptBuilder) *Builder {
+	b.fallback = fallback
+	return b
+}
+
+// Modes は --prompt-dir でテンプレートが用意されたレビューモードを返します。
+func (b *Builder) Modes() []string {
+	modes := make([]string, 0, len(b.modes))
+	for mode := range b.modes {
+		modes = append(modes, mode)
+	}
+	sort.Strings(modes)
+	return modes
+}
+
+// Build は prompts.ReviewPromptBuilder を満たします。差分以外の値は空のままテンプレートに渡します。
+func (b *Builder) Build(mode string, data prompts.TemplateData) (string, error) {
+	return b.BuildData(Data{DiffContent: data.DiffContent, Mode: mode})
+}
+
+// BuildData は、レビューモードのテンプレートに data を渡してプロンプトを組み立てます。
+func (b *Builder) BuildData(data Data) (string, error) {
+	tmpl := b.template(data.Mode)
+	if tmpl == nil {
+		if b.fallback == nil {
+			return "", fmt.Errorf("レビューモード '%s' のプロンプトテンプレートがありません", data.Mode)
+		}
+		return b.fallback.Build(data.Mode, prompts.TemplateData{DiffContent: data.DiffContent})
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("プロンプトテンプレート (%s) の実行に失敗しました: %w", tmpl.Name(), err)
+	}
+	return sb.String(), nil
+}
+
+// template はレビューモードで使用するテンプレートを返します。ない場合は nil です。
+func (b *Builder) template(mode string) *template.Template {
+	if b.file != nil {
+		return b.file
+	}
+	return b.modes[mode]
+}
+
+// sampleDiff は、テンプレートが差分を参照しているかの検証に使用する差分です。
+const sampleDiff = "diff --git a/prompttmpl.check b/prompttmpl.check\n"
+
+// parseFile はテンプレートを読み込んで解析し、見本の値で実行して差分 ({{.DiffContent}}) を参照しているかを検証します。
+// 存在しないフィールドの参照などの誤りを、レビューの実行時ではなく起動時に検出するためのものです。
+func parseFile(path string) (*template.Template, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("プロンプトテンプレートの読み込みに失敗しました (%s): %w", path, err)
+	}
+	if strings.TrimSpace(string(content)) == "" {
+		return nil, fmt.Errorf("プロンプトテンプレートが空です (%s)", path)
+	}
+	tmpl, err := template.New(path).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("プロンプトテンプレートの解析に失敗しました (%s): %w", path, err)
+	}
+	sample := Data{
+		DiffContent:   sampleDiff,
+		RepoURL:       "git@example.com:org/repo.git",
+		BaseBranch:    "main",
+		FeatureBranch: "feature",
+		Mode:          "detail",
+		Commits:       []Commit{NewCommit("0000000000000000000000000000000000000000", "author", time.Unix(0, 0), "subject\n\nbody")},
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, sample); err != nil {
+		return nil, fmt.Errorf("プロンプトテンプレートの実行に失敗しました (%s): %w", path, err)
+	}
+	if !strings.Contains(sb.String(), sampleDiff) {
+		return nil, fmt.Errorf("プロンプトテンプレートが差分 ({{.DiffContent}}) を参照していません (%s)", path)
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"git-gemini-reviewer-go/internal/pkg/filelock"
+)
+
+// fileLimiter は状態をファイルに保存し、ファイルロックで排他制御することで、
+// 同じファイルを参照する複数のプロセス間でクォータを共有するトークンバケットです。
+type fileLimiter struct {
+	path   string
+	limits Limits
+}
+
+func (f *fileLimiter) Wait(ctx context.Context, tokens int) error {
+	for {
+		wait, err := f.take(tokens)
+		if err != nil {
+			return err
+		}
+		if wait == 0 {
+			return nil
+		}
+		slog.Debug("AIリクエストのレート制限に達したため待機します。", "wait", wait.Round(time.Millisecond), "state", f.path)
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// take はファイルロックを取得した状態でバケットを読み込み、枠を消費して書き戻します。
+func (f *fileLimiter) take(tokens int) (time.Duration, error) {
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return 0, fmt.Errorf("レート制限の状態ファイルのディレクトリ作成に失敗しました: %w", err)
+	}
+	file, err := os.OpenFile(f.path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("レート制限の状態ファイルのオープンに失敗しました: %w", err)
+	}
+	defer file.Close()
+
+	if err := filelock.Lock(file); err != nil {
+		if errors.Is(err, filelock.ErrUnsupported) {
+			err = errors.New("このプラットフォームではプロセス間で共有するレート制限はサポートされていません")
+		}
+		return 0, fmt.Errorf("レート制限の状態ファイルのロックに失敗しました: %w", err)
+	}
+	defer filelock.Unlock(file)
+
+	var state bucket
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return 0, fmt.Errorf("レート制限の状態ファイルの読み込みに失敗しました: %w", err)
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &state); err != nil {
+			slog.Warn("レート制限の状態ファイルが壊れているため初期化します。", "path", f.path, "error", err)
+			state = bucket{}
+		}
+	}
+
+	wait := state.take(f.limits, time.Now(), tokens)
+
+	data, err = json.Marshal(state)
+	if err != nil {
+		return 0, fmt.Errorf("レート制限の状態のエンコードに失敗しました: %w", err)
+	}
+	if err := file.Truncate(0); err != nil {
+		return 0, fmt.Errorf("レート制限の状態ファイルの書き込みに失敗しました: %w", err)
+	}
+	if _, err := file.WriteAt(data, 0); err != nil {
+		return 0, fmt.Errorf("レート制限の状態ファイルの書き込みに失敗しました: %w", err)
+	}
+	return wait, nil
+}
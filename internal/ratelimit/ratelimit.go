@@ -0,0 +1,146 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Limits は AI リクエストの分間上限です。0 の項目は制限しません。
+type Limits struct {
+	// RequestsPerMinute は1分あたりのリクエスト数 (QPM) の上限です。
+	RequestsPerMinute int
+	// TokensPerMinute は1分あたりの入力トークン数 (TPM) の上限です。
+	TokensPerMinute int
+}
+
+// Enabled は制限が1つ以上設定されているかを返します。
+func (l Limits) Enabled() bool {
+	return l.RequestsPerMinute > 0 || l.TokensPerMinute > 0
+}
+
+// Limiter は AI リクエストの送信前に、クォータに空きができるまで待機する契約です。
+type Limiter interface {
+	// Wait は1リクエスト分と tokens トークン分の枠を確保できるまでブロックします。
+	Wait(ctx context.Context, tokens int) error
+}
+
+// New は limits に従う Limiter を返します。
+// statePath が空の場合はプロセス内で共有されるトークンバケットを、
+// 指定された場合は同じファイルを参照する複数プロセス間で共有されるトークンバケットを使用します。
+// limits に制限が設定されていない場合は nil を返します。
+func New(limits Limits, statePath string) Limiter {
+	if !limits.Enabled() {
+		return nil
+	}
+	if statePath != "" {
+		return &fileLimiter{path: statePath, limits: limits}
+	}
+	return shared(limits)
+}
+
+// EstimateTokens はプロンプトの入力トークン数をバイト長から概算します。
+// 英語ではおよそ4バイト、日本語ではおよそ1文字 (3バイト) が1トークン程度になるため、4バイトを1トークンとして多めに見積もります。
+func EstimateTokens(s string) int {
+	return len(s)/4 + 1
+}
+
+// bucket はリクエスト数とトークン数の2つのトークンバケットの状態です。
+// ファイル共有時にはこの構造体が JSON として保存されます。
+type bucket struct {
+	Requests float64   `json:"requests"`
+	Tokens   float64   `json:"tokens"`
+	Updated  time.Time `json:"updated"`
+}
+
+// take は経過時間に応じて枠を補充し、1リクエストと tokens トークン分の枠を消費します。
+// 枠が足りない場合は何も消費せず、枠が貯まるまでの待機時間を返します。
+func (b *bucket) take(l Limits, now time.Time, tokens int) time.Duration {
+	if b.Updated.IsZero() {
+		b.Requests, b.Tokens = float64(l.RequestsPerMinute), float64(l.TokensPerMinute)
+	} else if elapsed := now.Sub(b.Updated); elapsed > 0 {
+		minutes := elapsed.Minutes()
+		b.Requests = min(float64(l.RequestsPerMinute), b.Requests+minutes*float64(l.RequestsPerMinute))
+		b.Tokens = min(float64(l.TokensPerMinute), b.Tokens+minutes*float64(l.TokensPerMinute))
+	}
+	b.Updated = now
+
+	// 1リクエストでバケット容量を超える場合は、満杯になった時点で送信できるよう容量に丸めます。
+	need := float64(min(tokens, l.TokensPerMinute))
+
+	var wait time.Duration
+	if l.RequestsPerMinute > 0 && b.Requests < 1 {
+		wait = max(wait, refillTime(1-b.Requests, l.RequestsPerMinute))
+	}
+	if l.TokensPerMinute > 0 && b.Tokens < need {
+		wait = max(wait, refillTime(need-b.Tokens, l.TokensPerMinute))
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	if l.RequestsPerMinute > 0 {
+		b.Requests--
+	}
+	if l.TokensPerMinute > 0 {
+		b.Tokens -= need
+	}
+	return 0
+}
+
+// refillTime は perMinute の速度で deficit 分が補充されるまでの時間です。
+func refillTime(deficit float64, perMinute int) time.Duration {
+	return time.Duration(deficit / float64(perMinute) * float64(time.Minute))
+}
+
+// sleep は ctx がキャンセルされるまで、最大 d だけ待機します。
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("レート制限の待機中に中断されました: %w", ctx.Err())
+	case <-timer.C:
+		return nil
+	}
+}
+
+// memoryLimiter はプロセス内で共有されるトークンバケットです。
+type memoryLimiter struct {
+	mu     sync.Mutex
+	limits Limits
+	state  bucket
+}
+
+var (
+	sharedMu       sync.Mutex
+	sharedLimiters = map[Limits]*memoryLimiter{}
+)
+
+// shared は同じ limits に対してプロセス内で1つの memoryLimiter を返します。
+// バッチやサーバーモードで複数の ReviewRunner が構築されても、同じクォータを共有するようにします。
+func shared(limits Limits) *memoryLimiter {
+	sharedMu.Lock()
+	defer sharedMu.Unlock()
+	if l, ok := sharedLimiters[limits]; ok {
+		return l
+	}
+	l := &memoryLimiter{limits: limits}
+	sharedLimiters[limits] = l
+	return l
+}
+
+func (m *memoryLimiter) Wait(ctx context.Context, tokens int) error {
+	for {
+		m.mu.Lock()
+		wait := m.state.take(m.limits, time.Now(), tokens)
+		m.mu.Unlock()
+		if wait == 0 {
+			return nil
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
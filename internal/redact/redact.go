@@ -0,0 +1,90 @@
+package redact
+
+import (
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Placeholder は秘匿情報を置き換える文字列です。
+const Placeholder = "[REDACTED]"
+
+// secretEnvNames は、値そのものを秘匿対象とする環境変数名の一覧です。
+var secretEnvNames = []string{
+	"GEMINI_API_KEY",
+	"GOOGLE_API_KEY",
+	"OPENAI_API_KEY",
+	"AZURE_OPENAI_API_KEY",
+	"BACKLOG_API_KEY",
+	"SLACK_WEBHOOK_URL",
+	"SLACK_BOT_TOKEN",
+	"TEAMS_WEBHOOK_URL",
+	"DISCORD_WEBHOOK_URL",
+	"REVIEWER_WEBHOOK_URL",
+	"REVIEWER_WEBHOOK_SECRET",
+	"GIT_HTTP_TOKEN",
+	"SSH_KEY_PASSPHRASE",
+	"REVIEWER_CALLBACK_SECRET",
+}
+
+// patterns は、よく知られた秘匿情報の形式にマッチする正規表現です。
+var patterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`AKIA[0-9A-Z]{16}`),
+	regexp.MustCompile(`AIza[0-9A-Za-z_\-]{35}`),
+	// OpenAI の API キー (sk-...、sk-proj-...)
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_\-]{20,}`),
+	regexp.MustCompile(`gh[pousr]_[0-9A-Za-z]{36,}`),
+	regexp.MustCompile(`xox[baprs]-[0-9A-Za-z\-]{10,}`),
+	regexp.MustCompile(`https://hooks\.slack\.com/services/[A-Za-z0-9/]+`),
+	// Microsoft Teams の Incoming Webhook の URL
+	regexp.MustCompile(`https://[A-Za-z0-9.\-]+\.webhook\.office\.com/[^\s)"'<>]+`),
+	// Discord の Webhook の URL
+	regexp.MustCompile(`https://(?:ptb\.|canary\.)?discord(?:app)?\.com/api/webhooks/[^\s)"'<>]+`),
+}
+
+// assignmentPattern は `api_key = "..."` のような代入形式の秘匿情報にマッチします。
+// キー部分は残し、値のみを置き換えます。
+var assignmentPattern = regexp.MustCompile(`(?i)((?:api[_-]?key|secret|token|password|passwd)["']?\s*[:=]\s*)["']?[^\s"',]{6,}["']?`)
+
+// String は文字列中の秘匿情報を Placeholder に置き換えます。
+// secrets に渡された値と、既知の環境変数に設定されている値も置き換え対象となります。
+func String(s string, secrets ...string) string {
+	for _, secret := range append(EnvSecrets(), secrets...) {
+		if len(secret) < 6 {
+			// 短すぎる値は誤検知による過剰な置換を招くため対象外とします。
+			continue
+		}
+		s = strings.ReplaceAll(s, secret, Placeholder)
+	}
+	for _, re := range patterns {
+		s = re.ReplaceAllString(s, Placeholder)
+	}
+	return assignmentPattern.ReplaceAllString(s, "${1}"+Placeholder)
+}
+
+// URL は URL に含まれる認証情報 (ユーザー情報のパスワード部分) を除去します。
+// SSH 短縮形 (git@host:path) など、パースできない形式はそのまま返します。
+func URL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		// 角括弧はユーザー情報内でエスケープされるため、URL では括弧なしの表記を使用します。
+		u.User = url.UserPassword(u.User.Username(), "REDACTED")
+	}
+	return u.String()
+}
+
+// EnvSecrets は既知の秘匿環境変数に設定されている値を返します。
+func EnvSecrets() []string {
+	var values []string
+	for _, name := range secretEnvNames {
+		if v := os.Getenv(name); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
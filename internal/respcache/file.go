@@ -0,0 +1,83 @@
+package respcache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileStore は、ローカルのディレクトリに応答をキーごとの JSON ファイルとして保存する Store です。
+// 期限切れの応答は、読み込んだ際に削除します。
+type FileStore struct {
+	dir string
+}
+
+var _ Store = (*FileStore)(nil)
+
+// NewFileStore は dir に応答を保存する FileStore を返します。ディレクトリは最初の保存時に作成します。
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// path は key の応答のファイルのパスです。1つのディレクトリのファイル数が増えすぎないよう、キーの先頭2文字で分けます。
+func (s *FileStore) path(key string) string {
+	return filepath.Join(s.dir, key[:2], key+".json")
+}
+
+// Get は Store を満たします。
+func (s *FileStore) Get(_ context.Context, key string) (*Entry, error) {
+	path := s.path(key)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("キャッシュの読み込みに失敗しました (%s): %w", path, err)
+	}
+	var e Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, fmt.Errorf("キャッシュの解析に失敗しました (%s): %w", path, err)
+	}
+	if !e.ExpiresAt.IsZero() && time.Now().After(e.ExpiresAt) {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("期限切れのキャッシュの削除に失敗しました (%s): %w", path, err)
+		}
+		return nil, nil
+	}
+	return &e, nil
+}
+
+// Put は Store を満たします。並行して実行される他のレビューが書き込み途中のファイルを読まないよう、一時ファイルから置き換えます。
+func (s *FileStore) Put(_ context.Context, key string, e Entry, ttl time.Duration) error {
+	e.ExpiresAt = e.CreatedAt.Add(ttl)
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("キャッシュのエンコードに失敗しました: %w", err)
+	}
+	path := s.path(key)
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("キャッシュのディレクトリの作成に失敗しました (%s): %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("キャッシュの一時ファイルの作成に失敗しました: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("キャッシュの書き込みに失敗しました (%s): %w", path, err)
+	}
+	return nil
+}
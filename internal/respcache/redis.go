@@ -0,0 +1,196 @@
+package respcache
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// redisKeyPrefix は、同じ Redis を使用する他のアプリケーションのキーと衝突しないよう、キーに付ける接頭辞です。
+	redisKeyPrefix = "git-gemini-reviewer:response:"
+	// redisTimeout は、コンテキストに期限がない場合の1回の操作の制限時間です。
+	redisTimeout = 10 * time.Second
+)
+
+// RedisStore は Redis に応答を保存する Store です。期限切れの応答は Redis の EX で削除されます。
+// 複数の CI のランナーでキャッシュを共有するためのもので、GET と SET のみを使用する最小限の RESP のクライアントで接続します。
+type RedisStore struct {
+	addr     string
+	tls      bool
+	username string
+	password string
+	db       int
+}
+
+var _ Store = (*RedisStore)(nil)
+
+// NewRedisStore は 'redis://[[ユーザー]:パスワード@]ホスト[:ポート][/DB番号]' の Redis に接続する RedisStore を返します。
+// 'rediss://' の場合は TLS で接続します。URI にパスワードがない場合は環境変数 REDIS_PASSWORD を使用します。
+func NewRedisStore(uri string) (*RedisStore, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Hostname() == "" {
+		return nil, fmt.Errorf("Redis の URI が不正です: '%s'", redactURI(uri))
+	}
+	s := &RedisStore{addr: u.Host, tls: u.Scheme == "rediss"}
+	if u.Port() == "" {
+		s.addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if u.User != nil {
+		s.username = u.User.Username()
+		s.password, _ = u.User.Password()
+	}
+	if s.password == "" {
+		s.password = os.Getenv("REDIS_PASSWORD")
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if s.db, err = strconv.Atoi(db); err != nil || s.db < 0 {
+			return nil, fmt.Errorf("Redis の URI のデータベース番号が不正です: '%s'", db)
+		}
+	}
+	return s, nil
+}
+
+// redactURI は、エラーメッセージに含める URI からパスワードを取り除きます。
+func redactURI(uri string) string {
+	if u, err := url.Parse(uri); err == nil {
+		return u.Redacted()
+	}
+	return "(解析できない URI)"
+}
+
+// Get は Store を満たします。
+func (s *RedisStore) Get(ctx context.Context, key string) (*Entry, error) {
+	var e *Entry
+	err := s.session(ctx, func(c *redisConn) error {
+		value, ok, err := c.command("GET", redisKeyPrefix+key)
+		if err != nil || !ok {
+			return err
+		}
+		e = &Entry{}
+		if err := json.Unmarshal([]byte(value), e); err != nil {
+			return fmt.Errorf("キャッシュの解析に失敗しました: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Redis (%s) からのキャッシュの読み込みに失敗しました: %w", s.addr, err)
+	}
+	return e, nil
+}
+
+// Put は Store を満たします。
+func (s *RedisStore) Put(ctx context.Context, key string, e Entry, ttl time.Duration) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("キャッシュのエンコードに失敗しました: %w", err)
+	}
+	seconds := strconv.Itoa(max(int(ttl.Seconds()), 1))
+	err = s.session(ctx, func(c *redisConn) error {
+		_, _, err := c.command("SET", redisKeyPrefix+key, string(data), "EX", seconds)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("Redis (%s) へのキャッシュの書き込みに失敗しました: %w", s.addr, err)
+	}
+	return nil
+}
+
+// session は Redis に接続し、認証とデータベースの選択を行ってから fn を実行します。接続は操作ごとに閉じます。
+func (s *RedisStore) session(ctx context.Context, fn func(c *redisConn) error) error {
+	var (
+		conn net.Conn
+		err  error
+	)
+	if s.tls {
+		host, _, _ := net.SplitHostPort(s.addr)
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}
+		conn, err = dialer.DialContext(ctx, "tcp", s.addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", s.addr)
+	}
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(redisTimeout)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	c := &redisConn{rw: bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))}
+	if s.password != "" {
+		args := []string{"AUTH", s.password}
+		if s.username != "" {
+			args = []string{"AUTH", s.username, s.password}
+		}
+		if _, _, err := c.command(args...); err != nil {
+			return err
+		}
+	}
+	if s.db != 0 {
+		if _, _, err := c.command("SELECT", strconv.Itoa(s.db)); err != nil {
+			return err
+		}
+	}
+	return fn(c)
+}
+
+// redisConn は RESP (Redis Serialization Protocol) でコマンドを送受信する接続です。
+type redisConn struct {
+	rw *bufio.ReadWriter
+}
+
+// command はコマンドを送信し、応答の文字列を返します。応答が nil の場合は false を返します。
+func (c *redisConn) command(args ...string) (string, bool, error) {
+	fmt.Fprintf(c.rw, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(c.rw, "$%d\r\n%s\r\n", len(a), a)
+	}
+	if err := c.rw.Flush(); err != nil {
+		return "", false, err
+	}
+
+	line, err := c.rw.ReadString('\n')
+	if err != nil {
+		return "", false, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return "", false, errors.New("Redis の応答が空です")
+	}
+	switch line[0] {
+	case '+', ':':
+		return line[1:], true, nil
+	case '-':
+		return "", false, fmt.Errorf("Redis がエラーを返しました (%s): %s", args[0], line[1:])
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return "", false, fmt.Errorf("Redis の応答が不正です: %q", line)
+		}
+		if n < 0 {
+			return "", false, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.rw, buf); err != nil {
+			return "", false, err
+		}
+		return string(buf[:n]), true, nil
+	}
+	return "", false, fmt.Errorf("Redis の応答の形式に対応していません: %q", line)
+}
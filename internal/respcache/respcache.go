@@ -0,0 +1,52 @@
+// Package respcache は、AI の応答をプロンプトのハッシュで保存するキャッシュを提供します。
+// CI のパイプラインの再実行などで同じ差分を同じプロンプトでレビューする場合に、API を呼び出さずに前回の応答を返すためのものです。
+// 保存先はローカルのディレクトリ、または Redis ('redis://' / 'rediss://') を指定できます。
+package respcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// DefaultTTL は応答を保存する既定の期間です。
+const DefaultTTL = 24 * time.Hour
+
+// Entry はキャッシュに保存する1件の応答です。
+type Entry struct {
+	// Model は応答したモデルです。フォールバックの連鎖では、先頭のモデルと異なる場合があります。
+	Model     string    `json:"model"`
+	Result    string    `json:"result"`
+	CreatedAt time.Time `json:"created_at"`
+	// ExpiresAt は応答の有効期限です。期限の管理を保存先に任せる Redis では使用しません。
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// Store は応答の保存先です。
+type Store interface {
+	// Get は key の応答を返します。保存されていない場合と期限切れの場合は nil を返します。
+	Get(ctx context.Context, key string) (*Entry, error)
+	// Put は key の応答を ttl の期間保存します。
+	Put(ctx context.Context, key string, e Entry, ttl time.Duration) error
+}
+
+// Key は、AI の呼び出しを決める値 (プロバイダ・モデル・生成のパラメータ・プロンプト) からキャッシュのキーを返します。
+// プロンプトにはテンプレート・差分・前置きがすべて含まれるため、いずれかが変わるとキーも変わります。
+func Key(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Open は uri の保存先を開きます。'redis://' または 'rediss://' で始まる場合は Redis、それ以外はローカルのディレクトリです。
+func Open(uri string) (Store, error) {
+	if strings.HasPrefix(uri, "redis://") || strings.HasPrefix(uri, "rediss://") {
+		return NewRedisStore(uri)
+	}
+	return NewFileStore(strings.TrimPrefix(uri, "file://")), nil
+}
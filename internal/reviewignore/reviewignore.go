@@ -0,0 +1,71 @@
+// Package reviewignore は、リポジトリに置いた .aireviewignore ファイルで、AI レビューの対象から除外するファイルを指定する機能を提供します。
+// 書式は .gitignore と同じで、除外したファイルは差分とプロンプトの両方から取り除きます。
+package reviewignore
+
+import (
+	"strings"
+
+	"git-gemini-reviewer-go/internal/monorepo"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// DefaultFileName は、リポジトリのルートから読み込む除外ファイルの既定の名前です。
+const DefaultFileName = ".aireviewignore"
+
+// Matcher は除外ファイルのパターンの集合です。
+type Matcher struct {
+	matcher gitignore.Matcher
+}
+
+// Parse は、.gitignore の書式の内容から Matcher を生成します。
+// 空行と '#' で始まる行は無視します。有効なパターンがない場合は nil を返します。
+func Parse(content string) *Matcher {
+	var patterns []gitignore.Pattern
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, gitignore.ParsePattern(line, nil))
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+	return &Matcher{matcher: gitignore.NewMatcher(patterns)}
+}
+
+// Match は、リポジトリのルートからの相対パス p が除外の対象かを判定します。
+// git と同様に、親ディレクトリが除外されている場合は、'!' による否定のパターンでもファイルを対象に戻しません。
+func (m *Matcher) Match(p string) bool {
+	if m == nil || p == "" {
+		return false
+	}
+	segments := strings.Split(p, "/")
+	for i := 1; i < len(segments); i++ {
+		if m.matcher.Match(segments[:i], true) {
+			return true
+		}
+	}
+	return m.matcher.Match(segments, false)
+}
+
+// Filter は、除外の対象のファイルを差分から取り除き、残った差分と除外したパスを返します。
+func (m *Matcher) Filter(diff string) (string, []string) {
+	if m == nil {
+		return diff, nil
+	}
+	var sb strings.Builder
+	var excluded []string
+	for _, f := range monorepo.SplitDiff(diff) {
+		if m.Match(f.Path) {
+			excluded = append(excluded, f.Path)
+			continue
+		}
+		sb.WriteString(f.Content)
+	}
+	if len(excluded) == 0 {
+		return diff, nil
+	}
+	return sb.String(), excluded
+}
@@ -0,0 +1,109 @@
+// Package reviewmode は、レビューモード (--mode) の一覧と、組み込みの専門的なレビューモードのプロンプトを提供します。
+// detail と release のプロンプトは gemini-reviewer-core の組み込みのテンプレートを使用し、
+// security などの専門的なレビューモードは、観点を絞ったこのパッケージのテンプレートを使用します。
+package reviewmode
+
+import (
+	"embed"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/shouni/gemini-reviewer-core/pkg/prompts"
+)
+
+// 組み込みのレビューモードです。
+const (
+	Detail       = "detail"
+	Release      = "release"
+	Security     = "security"
+	Performance  = "performance"
+	TestCoverage = "test-coverage"
+	APICompat    = "api-compat"
+)
+
+// Names は組み込みのレビューモードを表示順に返します。
+func Names() []string {
+	return []string{Detail, Release, Security, Performance, TestCoverage, APICompat}
+}
+
+// Label はレビューモードの表示名を返します。複数のレビューモードを組み合わせた場合のセクションの見出しに使用します。
+func Label(mode string) string {
+	switch mode {
+	case Detail:
+		return "🔍 詳細レビュー"
+	case Release:
+		return "🚀 リリース判定"
+	case Security:
+		return "🔒 セキュリティ監査"
+	case Performance:
+		return "⚡ パフォーマンスレビュー"
+	case TestCoverage:
+		return "🧪 テストの十分さ"
+	case APICompat:
+		return "🔗 API 互換性チェック"
+	}
+	return "📝 " + mode
+}
+
+// Split は --mode の値 (カンマ区切り) をレビューモードの一覧に分割します。空の要素と重複は取り除きます。
+func Split(s string) []string {
+	var modes []string
+	for _, m := range strings.Split(s, ",") {
+		if m = strings.ToLower(strings.TrimSpace(m)); m != "" && !slices.Contains(modes, m) {
+			modes = append(modes, m)
+		}
+	}
+	return modes
+}
+
+// Validate は --mode の値を検証します。
+// extra は組み込み以外に使用できるレビューモード (--prompt-dir でテンプレートが用意されたモード) です。
+func Validate(s string, extra ...string) error {
+	modes := Split(s)
+	if len(modes) == 0 {
+		return fmt.Errorf("--mode にレビューモードを指定してください")
+	}
+	for _, m := range modes {
+		if !slices.Contains(Names(), m) && !slices.Contains(extra, m) {
+			return fmt.Errorf("不明なレビューモードです: '%s' (%s のいずれか、またはカンマ区切りの組み合わせを指定してください)", m, strings.Join(append(Names(), extra...), ", "))
+		}
+	}
+	return nil
+}
+
+//go:embed prompts/*.md
+var promptFS embed.FS
+
+// templates は専門的なレビューモードのテンプレートです。
+var templates = func() map[string]*template.Template {
+	m := make(map[string]*template.Template)
+	for _, mode := range []string{Security, Performance, TestCoverage, APICompat} {
+		m[mode] = template.Must(template.ParseFS(promptFS, "prompts/"+mode+".md")).Option("missingkey=error")
+	}
+	return m
+}()
+
+// PromptBuilder は、専門的なレビューモードをこのパッケージのテンプレートで、それ以外を fallback で組み立てます。
+type PromptBuilder struct {
+	fallback prompts.ReviewPromptBuilder
+}
+
+// NewPromptBuilder は PromptBuilder を生成します。fallback には detail と release のテンプレートを持つ組み込みの PromptBuilder を指定します。
+func NewPromptBuilder(fallback prompts.ReviewPromptBuilder) *PromptBuilder {
+	return &PromptBuilder{fallback: fallback}
+}
+
+// Build は prompts.ReviewPromptBuilder を満たします。
+func (b *PromptBuilder) Build(mode string, data prompts.TemplateData) (string, error) {
+	tmpl, ok := templates[mode]
+	if !ok {
+		return b.fallback.Build(mode, data)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("レビューモード '%s' のプロンプトの組み立てに失敗しました: %w", mode, err)
+	}
+	return sb.String(), nil
+}
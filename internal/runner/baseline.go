@@ -0,0 +1,42 @@
+package runner
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"git-gemini-reviewer-go/internal/baseline"
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/inline"
+)
+
+// applyBaseline は、cfg.BaselineFile が指定された場合に、cfg.WriteBaseline であれば現在の指摘をベースラインに記録し、
+// それ以外はベースラインに記録済みの指摘を取り除いて、取り除いた件数を返します。
+// ベースラインの読み書きに失敗してもレビューは継続するため、縮退した処理として記録し、すべての指摘を報告します。
+func (r *ReviewRunner) applyBaseline(cfg config.ReviewConfig, structured *inline.Review, diff string) int {
+	if cfg.BaselineFile == "" {
+		return 0
+	}
+	if cfg.WriteBaseline {
+		b := baseline.New(*structured, diff, cfg.ReviewID, time.Now())
+		if err := b.Save(cfg.BaselineFile); err != nil {
+			r.issues.Degrade("baseline", err)
+			return 0
+		}
+		slog.Info("現在の指摘をベースラインに記録しました。", "path", cfg.BaselineFile, "findings", len(b.Findings))
+		return 0
+	}
+	b, err := baseline.Load(cfg.BaselineFile)
+	if err != nil {
+		if baseline.IsNotExist(err) {
+			err = fmt.Errorf("%w ('--write-baseline' を指定して作成してください)", err)
+		}
+		r.issues.Degrade("baseline", err)
+		return 0
+	}
+	known := b.Filter(structured, diff)
+	if known > 0 {
+		slog.Info("ベースラインに記録済みの指摘を除外しました。", "path", cfg.BaselineFile, "known", known, "new", len(structured.Findings))
+	}
+	return known
+}
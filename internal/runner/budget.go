@@ -0,0 +1,110 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"git-gemini-reviewer-go/internal/chunk"
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/estimate"
+	"git-gemini-reviewer-go/internal/ratelimit"
+	"git-gemini-reviewer-go/internal/tokencount"
+)
+
+// budgetMargin は、入力トークン数の上限を超えた差分を分割し直す際に、プロンプトの差分以外の部分と概算の誤差に残す余裕です。
+const budgetMargin = 0.9
+
+// InputBudgetError は、プロンプトの入力トークン数が cfg.MaxInputTokens を超えたため、AI への送信を中止したことを表します。
+type InputBudgetError struct {
+	Tokens int
+	Limit  int
+}
+
+func (e *InputBudgetError) Error() string {
+	return fmt.Sprintf("プロンプトの入力トークン数 (%d) が上限 (%d) を超えるため、AIへの送信を中止しました", e.Tokens, e.Limit)
+}
+
+// WithTokenCounter は、AIへの送信前にプロンプトの入力トークン数を数える Counter を設定します。
+// 未設定の場合はバイト長からの概算を使用します。
+func WithTokenCounter(c tokencount.Counter) Option {
+	return func(r *ReviewRunner) {
+		r.counter = c
+	}
+}
+
+// WithPrices は、推定費用の計算に使用するモデルごとの料金 (--pricing-file) を設定します。
+// 未設定の場合は組み込みの料金表 (estimate.Prices) を使用します。
+func WithPrices(prices map[string]estimate.Price) Option {
+	return func(r *ReviewRunner) {
+		r.prices = prices
+	}
+}
+
+// price はモデルの料金を返します。料金が不明な場合は nil を返します。
+func (r *ReviewRunner) price(model string) *estimate.Price {
+	prices := r.prices
+	if prices == nil {
+		prices = estimate.Prices
+	}
+	if p, ok := prices[model]; ok {
+		return &p
+	}
+	return nil
+}
+
+// countTokens はプロンプトの入力トークン数を数え、推定の入力費用とともにログに出力します。
+// Counter の呼び出しに失敗した場合は、レビューを止めずに概算の値を使用します。
+func (r *ReviewRunner) countTokens(ctx context.Context, cfg config.ReviewConfig, prompt string) int {
+	method := tokencount.MethodEstimate
+	tokens := ratelimit.EstimateTokens(prompt)
+	if r.counter != nil {
+		if n, err := r.counter.CountTokens(ctx, prompt); err != nil {
+			slog.WarnContext(ctx, "入力トークン数の計数に失敗したため、概算の値を使用します。", "error", err)
+		} else {
+			method, tokens = r.counter.Method(), n
+		}
+	}
+	attrs := []any{"tokens", tokens, "method", method, "model", cfg.GeminiModel}
+	if cfg.MaxInputTokens > 0 {
+		attrs = append(attrs, "limit", cfg.MaxInputTokens)
+	}
+	if price := r.price(cfg.GeminiModel); price != nil {
+		attrs = append(attrs, "estimated_input_cost_usd", fmt.Sprintf("%.4f", price.Cost(tokens, 0)))
+	}
+	slog.InfoContext(ctx, "プロンプトの入力トークン数", attrs...)
+	return tokens
+}
+
+// checkBudget は、入力トークン数が cfg.MaxInputTokens を超える場合に *InputBudgetError を返します。
+func checkBudget(cfg config.ReviewConfig, tokens int) error {
+	if cfg.MaxInputTokens > 0 && tokens > cfg.MaxInputTokens {
+		return &InputBudgetError{Tokens: tokens, Limit: cfg.MaxInputTokens}
+	}
+	return nil
+}
+
+// rechunk は、入力トークン数の上限を超えた差分を、上限に収まる見込みの大きさに分割し直します。
+// 分割後の差分のトークン数の上限を返します。分割が無効 (cfg.ChunkTokens が 0) な場合や、これ以上分割できない場合は nil を返します。
+func rechunk(cfg config.ReviewConfig, codeDiff string, budgetErr *InputBudgetError) ([]chunk.Chunk, int) {
+	if cfg.ChunkTokens <= 0 || budgetErr.Tokens <= 0 {
+		return nil, 0
+	}
+	diffTokens := ratelimit.EstimateTokens(codeDiff)
+	maxTokens := int(float64(diffTokens) * float64(budgetErr.Limit) / float64(budgetErr.Tokens) * budgetMargin)
+	if maxTokens <= 0 {
+		return nil, 0
+	}
+	if chunks := chunk.Split(codeDiff, maxTokens); len(chunks) > 1 {
+		return chunks, maxTokens
+	}
+	return nil, 0
+}
+
+// asBudgetError は err が *InputBudgetError を含む場合にそれを返します。
+func asBudgetError(err error) (*InputBudgetError, bool) {
+	var be *InputBudgetError
+	ok := errors.As(err, &be)
+	return be, ok
+}
@@ -0,0 +1,103 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"git-gemini-reviewer-go/internal/aggregate"
+	"git-gemini-reviewer-go/internal/chunk"
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/inline"
+	"git-gemini-reviewer-go/internal/streamai"
+)
+
+// reviewChunks は、トークン数の上限を超える差分を分割したチャンクごとにレビューし、最後の1回のリクエストで結果を統合します。
+// 一部のチャンクのレビューが失敗した場合は、成功したチャンクのみを統合し、欠落したファイルを冒頭に注記します。
+// 統合のリクエストが失敗した場合は、チャンクごとの結果を順に結合した結果を返します。
+// 統合のプロンプトが cfg.MaxInputTokens を超える場合は、段階的に統合します (consolidate を参照)。
+func (r *ReviewRunner) reviewChunks(ctx context.Context, cfg config.ReviewConfig, chunks []chunk.Chunk, promptNote string) (string, error) {
+	slog.Info("差分がトークン数の上限を超えるため、分割してレビューします。", "chunks", len(chunks), "chunk_tokens", cfg.ChunkTokens)
+
+	collector := aggregate.NewCollector("分割レビュー")
+	partials := make([]chunk.Partial, 0, len(chunks))
+	var failed []chunk.Chunk
+	for i, c := range chunks {
+		slog.Info("分割した差分のレビューを開始します。", "chunk", i+1, "total", len(chunks), "files", len(c.Files), "partial", c.Partial)
+
+		chunkCfg := cfg
+		// アーカイブがチャンク間で上書きされないよう、チャンクごとのサブディレクトリに保存します
+		chunkCfg.ReviewID = fmt.Sprintf("%s/chunks/%d", cfg.ReviewID, i+1)
+
+		// 分割した差分ごとの結果は統合の材料のため、逐次の書き出しは統合の結果のみとする
+		result, err := r.reviewSingle(streamai.WithWriter(ctx, nil), chunkCfg, c.Diff, promptNote)
+		if err != nil {
+			collector.Add(c.Label(), err)
+			failed = append(failed, c)
+			continue
+		}
+		partials = append(partials, chunk.Partial{Chunk: c, Review: result})
+	}
+	if len(partials) == 0 {
+		return "", collector.Err()
+	}
+	// 一部のチャンクの失敗は、成功したチャンクの結果のみを出力する縮退として扱う
+	r.issues.Degrade("ai.chunks", collector.Err())
+
+	slog.Info("分割したレビュー結果を統合します。", "partials", len(partials))
+	consolidated, err := r.consolidate(ctx, cfg, partials, len(chunks))
+	if err != nil {
+		// 構造化された指摘は1つの JSON として解析するため、結合では代替できない
+		if cfg.InlineFindings {
+			return "", fmt.Errorf("分割したレビュー結果の統合に失敗しました: %w", err)
+		}
+		r.issues.Degrade("ai.consolidate", fmt.Errorf("分割したレビュー結果の統合に失敗しました。部分ごとの結果を結合して出力します: %w", err))
+		consolidated = chunk.Concat(partials)
+	}
+	if cfg.InlineFindings {
+		return consolidated, nil
+	}
+	return chunk.Notice(len(chunks), failed) + consolidated, nil
+}
+
+// consolidate は、チャンクごとのレビュー結果を1回のリクエストで統合します。
+// 統合のプロンプトが cfg.MaxInputTokens を超える場合は、結果を前半と後半に分けてそれぞれ統合してから、その2つを統合します。
+// 2件以下の結果でも上限を超える場合は、これ以上分けられないため *InputBudgetError を返します。
+func (r *ReviewRunner) consolidate(ctx context.Context, cfg config.ReviewConfig, partials []chunk.Partial, total int) (string, error) {
+	result, err := r.ask(ctx, cfg, r.consolidationPrompt(cfg, partials, total))
+	budgetErr, ok := asBudgetError(err)
+	if !ok || len(partials) <= 2 {
+		return result, err
+	}
+	slog.WarnContext(ctx, "統合のプロンプトが入力トークン数の上限を超えるため、段階的に統合します。",
+		"partials", len(partials), "tokens", budgetErr.Tokens, "limit", budgetErr.Limit)
+
+	half := len(partials) / 2
+	merged := make([]chunk.Partial, 0, 2)
+	for _, group := range [][]chunk.Partial{partials[:half], partials[half:]} {
+		if len(group) == 1 {
+			merged = append(merged, group[0])
+			continue
+		}
+		// 途中の段階の統合の結果は材料のため、逐次の書き出しは最後の統合の結果のみとする
+		review, err := r.consolidate(streamai.WithWriter(ctx, nil), cfg, group, total)
+		if err != nil {
+			return "", err
+		}
+		chunks := make([]chunk.Chunk, 0, len(group))
+		for _, p := range group {
+			chunks = append(chunks, p.Chunk)
+		}
+		merged = append(merged, chunk.Partial{Chunk: chunk.Merge(chunks...), Review: review})
+	}
+	return r.ask(ctx, cfg, r.consolidationPrompt(cfg, merged, total))
+}
+
+// consolidationPrompt は、partials を統合するプロンプトを返します。
+func (r *ReviewRunner) consolidationPrompt(cfg config.ReviewConfig, partials []chunk.Partial, total int) string {
+	prompt := r.personaPrompt + chunk.ConsolidationPrompt(partials, total)
+	if cfg.InlineFindings {
+		prompt += inline.PromptInstruction
+	}
+	return prompt
+}
@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"git-gemini-reviewer-go/internal/chunk"
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/ratelimit"
+)
+
+// recordingAI は送信されたプロンプトを記録し、短い応答を返す AI です。
+type recordingAI struct {
+	prompts []string
+}
+
+func (a *recordingAI) ReviewCodeDiff(_ context.Context, prompt string) (string, error) {
+	a.prompts = append(a.prompts, prompt)
+	return fmt.Sprintf("統合結果 %d", len(a.prompts)), nil
+}
+
+func testPartials(n, size int) []chunk.Partial {
+	partials := make([]chunk.Partial, 0, n)
+	for i := range n {
+		partials = append(partials, chunk.Partial{
+			Chunk:  chunk.Chunk{Files: []string{fmt.Sprintf("file%d.go", i)}},
+			Review: strings.Repeat("x", size),
+		})
+	}
+	return partials
+}
+
+func TestConsolidateInStagesWhenPromptExceedsBudget(t *testing.T) {
+	ai := &recordingAI{}
+	r := NewReviewRunner(nil, ai, nil)
+	cfg := config.ReviewConfig{GeminiModel: "gemini-2.5-flash", MaxInputTokens: 3000}
+	partials := testPartials(4, 4000)
+	if tokens := ratelimit.EstimateTokens(r.consolidationPrompt(cfg, partials, len(partials))); tokens <= cfg.MaxInputTokens {
+		t.Fatalf("前提: 統合のプロンプト (%d トークン) が上限を超えていません", tokens)
+	}
+
+	result, err := r.consolidate(context.Background(), cfg, partials, len(partials))
+	if err != nil {
+		t.Fatalf("consolidate: %v", err)
+	}
+	// 前半と後半をそれぞれ統合し、その2つを統合する
+	if len(ai.prompts) != 3 {
+		t.Fatalf("AI へのリクエスト数 = %d, want 3", len(ai.prompts))
+	}
+	for i, prompt := range ai.prompts {
+		if tokens := ratelimit.EstimateTokens(prompt); tokens > cfg.MaxInputTokens {
+			t.Errorf("リクエスト %d のプロンプト (%d トークン) が上限 (%d) を超えています", i+1, tokens, cfg.MaxInputTokens)
+		}
+	}
+	if result != "統合結果 3" {
+		t.Errorf("result = %q, want 最後の統合の結果", result)
+	}
+	last := ai.prompts[2]
+	for _, file := range []string{"file0.go", "file1.go", "file2.go", "file3.go"} {
+		if !strings.Contains(last, file) {
+			t.Errorf("最後の統合のプロンプトに %s が含まれていません", file)
+		}
+	}
+}
+
+func TestConsolidateReturnsBudgetErrorWhenPartialsCannotBeSplit(t *testing.T) {
+	ai := &recordingAI{}
+	r := NewReviewRunner(nil, ai, nil)
+	cfg := config.ReviewConfig{GeminiModel: "gemini-2.5-flash", MaxInputTokens: 1500}
+
+	_, err := r.consolidate(context.Background(), cfg, testPartials(2, 4000), 2)
+	var budgetErr *InputBudgetError
+	if !errors.As(err, &budgetErr) {
+		t.Fatalf("err = %v, want *InputBudgetError", err)
+	}
+	if len(ai.prompts) != 0 {
+		t.Errorf("上限を超えるプロンプトが AI に送信されました (%d 件)", len(ai.prompts))
+	}
+}
@@ -0,0 +1,44 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/gitclient"
+)
+
+// commitLister はフィーチャーブランチのコミットの作者・日時・メッセージを取得できる GitService です。
+type commitLister interface {
+	Commits(ctx context.Context, baseBranch, featureBranch string) ([]gitclient.Commit, error)
+}
+
+// commitLogNote は、差分に含まれるコミットの一覧を AI に伝えるプロンプトの前置きを返します。
+// コミットメッセージに書かれた変更の意図と実装が一致しているかを判断する材料にします。
+// limit を超える場合は新しい順に limit 件のみを含め、省略した件数を添えます。
+func commitLogNote(commits []gitclient.Commit, limit int) string {
+	if len(commits) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## 📝 コミットの一覧 (ツールによる自動取得)\n\n")
+	sb.WriteString("差分に含まれるコミットは次のとおりです (新しい順)。コミットメッセージに書かれた意図と実装が一致しているかも確認してください。\n\n")
+	omitted := 0
+	if limit > 0 && len(commits) > limit {
+		commits, omitted = commits[:limit], len(commits)-limit
+	}
+	for _, c := range commits {
+		subject, body, _ := strings.Cut(strings.TrimSpace(c.Message), "\n")
+		fmt.Fprintf(&sb, "### %s %s\n\n", shortCommit(c.Hash), subject)
+		fmt.Fprintf(&sb, "- 作者: %s\n- 日時: %s\n", c.Author, c.Date.Format("2006-01-02 15:04:05 -0700"))
+		if body = strings.TrimSpace(body); body != "" {
+			fmt.Fprintf(&sb, "\n%s\n", body)
+		}
+		sb.WriteString("\n")
+	}
+	if omitted > 0 {
+		fmt.Fprintf(&sb, "(ほか、以前の %d 件のコミットは省略)\n\n", omitted)
+	}
+	sb.WriteString("---\n\n")
+	return sb.String()
+}
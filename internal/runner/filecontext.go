@@ -0,0 +1,54 @@
+package runner
+
+import (
+	"log/slog"
+	"slices"
+
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/filecontext"
+	"git-gemini-reviewer-go/internal/ratelimit"
+	"git-gemini-reviewer-go/internal/redact"
+)
+
+// fileContextBudgetRatio は、ファイルの内容を添えた後のプロンプトの推定トークン数を cfg.MaxInputTokens の何割までに収めるかです。
+// 推定値と AI のトークン数の誤差で上限を超え、送信の中止や分割し直しにならないよう余裕を残します。
+const fileContextBudgetRatio = 0.9
+
+// collectFileContents は、cfg.FileContext が有効な場合に、差分に含まれるファイルの変更後の内容を read で読み込みます。
+// 差分と同じく秘匿情報をマスクするよう、'redact' の変換器が有効な場合は読み込んだ内容もマスクします。
+func collectFileContents(cfg config.ReviewConfig, diff string, read func(path string) ([]byte, error)) map[string]string {
+	mode, _ := filecontext.Parse(cfg.FileContext)
+	if !mode.Enabled() {
+		return nil
+	}
+	maxBytes := cfg.FileContextMaxBytes
+	if maxBytes <= 0 {
+		maxBytes = filecontext.DefaultMaxBytes
+	}
+	contents := filecontext.Collect(diff, maxBytes, read)
+	if slices.Contains(cfg.DiffTransforms, "redact") {
+		for path, content := range contents {
+			contents[path] = redact.String(content)
+		}
+	}
+	slog.Info("変更されたファイルの内容を読み込みました。", "files", len(contents), "max_bytes", maxBytes)
+	return contents
+}
+
+// fileContextNote は、codeDiff に含まれるファイルの内容をプロンプトに添える前置きを返します。
+// cfg.MaxInputTokens が指定されている場合は、prompt (前置きを除くプロンプト) と合わせて上限に収まるファイルのみを添えます。
+func (r *ReviewRunner) fileContextNote(cfg config.ReviewConfig, codeDiff, prompt string) string {
+	if len(r.fileContents) == 0 {
+		return ""
+	}
+	mode, _ := filecontext.Parse(cfg.FileContext)
+	maxTokens := 0
+	if cfg.MaxInputTokens > 0 {
+		maxTokens = int(float64(cfg.MaxInputTokens)*fileContextBudgetRatio) - ratelimit.EstimateTokens(prompt)
+		if maxTokens <= 0 {
+			slog.Warn("入力トークン数の上限に余裕がないため、ファイルの内容はプロンプトに添えません。", "limit", cfg.MaxInputTokens)
+			return ""
+		}
+	}
+	return filecontext.PromptNote(r.fileContents, codeDiff, mode, maxTokens)
+}
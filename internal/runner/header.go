@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"log/slog"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/diffguard"
+	"git-gemini-reviewer-go/internal/diffstat"
+	"git-gemini-reviewer-go/internal/issuelink"
+)
+
+// reviewHeader はレビュー結果の冒頭に置く、重要パスへの指摘・変更規模の要約・変更構成のバッジ・差分削減の警告・必須チェックの未達・抑制された指摘とベースラインの既存の指摘の件数・関連課題のリンクを返します。
+// 変更構成は削減前の差分全体から集計します。
+func reviewHeader(cfg config.ReviewConfig, src diffSource, guard diffguard.Result, failedChecks []string, criticalNotice, suppressNotice, baselineNotice string) string {
+	var badges []string
+	stats := diffstat.Compute(src.Diff)
+	for _, b := range []string{criticalNotice, stats.Summary(), stats.Badge(), stackNotice(cfg.BaseBranch, src.StackParent), guard.Notice(), failedChecksNotice(failedChecks), suppressNotice, baselineNotice} {
+		if b != "" {
+			badges = append(badges, b)
+		}
+	}
+	links := issueLinkHeader(cfg, src)
+	switch {
+	case len(badges) == 0:
+		return links
+	case links == "":
+		return strings.Join(badges, "\n\n") + "\n\n---\n\n"
+	}
+	return strings.Join(badges, "\n\n") + "\n\n" + links
+}
+
+// issueLinkHeader は、ブランチ名とコミットメッセージから検出した課題キーのリンクを Markdown で返します。
+// 課題トラッカーが設定されていない場合、またはキーが見つからない場合は空文字列です。
+func issueLinkHeader(cfg config.ReviewConfig, src diffSource) string {
+	if len(cfg.IssueTrackers) == 0 {
+		return ""
+	}
+	texts := append([]string{cfg.FeatureBranch}, src.CommitMessages...)
+	links := issuelink.Extract(cfg.IssueTrackers, texts...)
+	if len(links) > 0 {
+		slog.Info("関連する課題キーを検出しました。", "count", len(links))
+	}
+	return issuelink.Markdown(links)
+}
+
+// failedChecksNotice は、満たされなかった必須チェックの警告を返します。
+func failedChecksNotice(failed []string) string {
+	if len(failed) == 0 {
+		return ""
+	}
+	return "🚫 **必須チェック未達:** " + strings.Join(failed, " / ")
+}
@@ -0,0 +1,38 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/headings"
+)
+
+// languageNames は、出力言語の指示に使用する言語の表示名です。ない言語は言語コードのまま指示します。
+var languageNames = map[string]string{
+	"en": "英語 (English)",
+	"zh": "中国語 (中文)",
+	"ko": "韓国語 (한국어)",
+	"fr": "フランス語 (Français)",
+	"de": "ドイツ語 (Deutsch)",
+	"es": "スペイン語 (Español)",
+	"pt": "ポルトガル語 (Português)",
+	"vi": "ベトナム語 (Tiếng Việt)",
+}
+
+// languageNote は、レビュー結果を lang で記述するよう AI に伝えるプロンプトの前置きを返します。
+// 日本語 (既定) の場合は空文字列です。判定と件数の解析は日本語の見出しと判定の文言を前提とするため、
+// それらは日本語のまま出力させ、見出しは投稿時に --heading-lang の辞書で翻訳します。
+func languageNote(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "" || lang == headings.DefaultLang {
+		return ""
+	}
+	name, ok := languageNames[lang]
+	if !ok {
+		name = fmt.Sprintf("言語コード '%s' の言語", lang)
+	}
+	return fmt.Sprintf("## 🌐 出力言語 (ツールによる自動指定)\n\n"+
+		"レビュー結果の本文 (総評・指摘の内容・改善案) は%sで記述してください。"+
+		"ただし、テンプレートが指示する見出しと判定の文言 (「リリース可」「条件付きリリース可」「リリース不可」など) はツールが解析するため、日本語のまま出力してください。見出しは投稿時にツールが翻訳します。\n\n"+
+		"---\n\n", name)
+}
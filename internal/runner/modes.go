@@ -0,0 +1,93 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/aggregate"
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/reviewmode"
+	"git-gemini-reviewer-go/internal/verdict"
+)
+
+// reviewModes は、複数のレビューモード (例: --mode security,detail) が指定された場合に、モードごとにレビューを実行します。
+// 各モードのレビュー結果はセクションとして1つのレポートにまとめ、冒頭にモードごとの判定と最も厳しい判定を置きます。
+// 一部のモードのレビューが失敗した場合も、失敗したモードの注記を含めて成功したモードの結果を返します。
+func (r *ReviewRunner) reviewModes(ctx context.Context, cfg config.ReviewConfig, modes []string, codeDiff string, treeRoots []string, promptNote string) (string, error) {
+	if cfg.InlineFindings {
+		return "", fmt.Errorf("複数のレビューモード (%s) と構造化された指摘は同時に指定できません", cfg.ReviewMode)
+	}
+	slog.Info("レビューモードごとにレビューします。", "modes", modes)
+
+	sections := make([]aggregate.Section, 0, len(modes))
+	verdicts := make([]verdict.Verdict, len(modes))
+	for i, mode := range modes {
+		slog.Info("レビューモードのレビューを開始します。", "mode", mode)
+
+		modeCfg := cfg
+		modeCfg.ReviewMode = mode
+		// アーカイブがモード間で上書きされないよう、モードごとのサブディレクトリに保存します
+		modeCfg.ReviewID = cfg.ReviewID + "/modes/" + mode
+
+		var result string
+		var err error
+		if cfg.SplitModules {
+			result, err = r.reviewModules(ctx, modeCfg, codeDiff, treeRoots, promptNote)
+		} else {
+			result, err = r.reviewDiff(ctx, modeCfg, codeDiff, promptNote)
+		}
+		verdicts[i] = verdict.Unknown
+		if err == nil {
+			verdicts[i] = verdict.Parse(result)
+		}
+		sections = append(sections, aggregate.Section{
+			Name:    reviewmode.Label(mode),
+			Content: "## " + reviewmode.Label(mode) + "\n\n" + strings.TrimSpace(result),
+			Err:     err,
+		})
+	}
+
+	merged, err := aggregate.MergeSections(sections)
+	if merged == "" {
+		return "", err
+	}
+	// 一部のモードの失敗は、成功したモードの結果のみを出力する縮退として扱う
+	r.issues.Degrade("ai.modes", err)
+	return formatModeSummary(modes, verdicts) + "\n\n" + merged, nil
+}
+
+// formatModeSummary はレポート冒頭に置く、モードごとの判定の表と最も厳しい判定を Markdown で返します。
+// verdict.Parse は最初に現れる判定を採用するため、履歴やポリシーの判定には最も厳しい判定が使われます。
+func formatModeSummary(modes []string, verdicts []verdict.Verdict) string {
+	overall := verdict.Unknown
+	for _, v := range verdicts {
+		if verdictRank(v) > verdictRank(overall) {
+			overall = v
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString("# 🧭 レビューモード別の結果\n\n")
+	if overall != verdict.Unknown {
+		fmt.Fprintf(&sb, "総合判定: **%s** (各モードの判定のうち最も厳しい判定)\n\n", overall.Label())
+	}
+	sb.WriteString("| レビューモード | 判定 |\n| :--- | :--- |\n")
+	for i, mode := range modes {
+		fmt.Fprintf(&sb, "| %s | %s |\n", reviewmode.Label(mode), verdicts[i].Label())
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// verdictRank は判定の厳しさを返します。判定不明は最も低く扱います。
+func verdictRank(v verdict.Verdict) int {
+	switch v {
+	case verdict.Blocked:
+		return 3
+	case verdict.Conditional:
+		return 2
+	case verdict.Approved:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,95 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/aggregate"
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/monorepo"
+)
+
+// reviewModules は差分をモジュール境界ごとに分割し、モジュール単位でレビューを実行します。
+// 各モジュールのレビュー結果はそれぞれ独立した判定を含み、1つのレポートにセクションとしてまとめられます。
+// 一部のモジュールのレビューが失敗した場合も、失敗したモジュールの注記を含めて成功したモジュールの結果を返します。
+func (r *ReviewRunner) reviewModules(ctx context.Context, cfg config.ReviewConfig, codeDiff string, treeRoots []string, promptNote string) (string, error) {
+	files := monorepo.SplitDiff(codeDiff)
+	paths := make([]string, 0, len(files))
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	roots := append(treeRoots, monorepo.RootsFromPaths(paths, monorepo.DefaultMarkers)...)
+	modules := monorepo.Group(files, roots)
+
+	if len(modules) <= 1 {
+		slog.Info("変更は単一のモジュールに収まっているため、分割せずにレビューします。")
+		return r.reviewDiff(ctx, cfg, codeDiff, promptNote)
+	}
+	slog.Info("差分をモジュールごとに分割してレビューします。", "modules", len(modules))
+
+	sections := make([]aggregate.Section, 0, len(modules))
+	for _, m := range modules {
+		slog.Info("モジュールのレビューを開始します。", "module", m.Root, "files", len(m.Files))
+
+		moduleCfg := cfg
+		// アーカイブがモジュール間で上書きされないよう、モジュールごとのサブディレクトリに保存します
+		moduleCfg.ReviewID = cfg.ReviewID + "/modules/" + moduleSlug(m.Root)
+
+		result, err := r.reviewDiff(ctx, moduleCfg, m.Diff, promptNote)
+		sections = append(sections, aggregate.Section{
+			Name:    moduleLabel(m.Root),
+			Content: formatModuleSection(m, result),
+			Err:     err,
+		})
+	}
+
+	merged, err := aggregate.MergeSections(sections)
+	if merged == "" {
+		return "", err
+	}
+	// 一部のモジュールの失敗は、成功したモジュールの結果のみを出力する縮退として扱う
+	r.issues.Degrade("ai.modules", err)
+	return formatModuleSummary(modules) + "\n\n" + merged, nil
+}
+
+// formatModuleSummary はレポート冒頭に置くモジュール一覧を Markdown の表で返します。
+func formatModuleSummary(modules []monorepo.Module) string {
+	var sb strings.Builder
+	sb.WriteString("# 📦 モジュール別レビュー結果\n\n")
+	sb.WriteString("| モジュール | 変更ファイル数 |\n| :--- | ---: |\n")
+	for _, m := range modules {
+		fmt.Fprintf(&sb, "| `%s` | %d |\n", moduleLabel(m.Root), len(m.Files))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// formatModuleSection は1モジュール分のレビュー結果に見出しと対象ファイルを付与します。
+func formatModuleSection(m monorepo.Module, review string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## 📦 モジュール: `%s`\n\n", moduleLabel(m.Root))
+	sb.WriteString("<details><summary>対象ファイル</summary>\n\n")
+	for _, f := range m.Files {
+		fmt.Fprintf(&sb, "- `%s`\n", f)
+	}
+	sb.WriteString("\n</details>\n\n")
+	sb.WriteString(strings.TrimSpace(review))
+	return sb.String()
+}
+
+// moduleLabel はモジュールの表示名を返します。
+func moduleLabel(root string) string {
+	if root == monorepo.RootModule {
+		return "(リポジトリルート)"
+	}
+	return root
+}
+
+// moduleSlug はモジュールのルートをアーカイブのディレクトリ名として使える形に変換します。
+func moduleSlug(root string) string {
+	if root == monorepo.RootModule {
+		return "_root"
+	}
+	return strings.ReplaceAll(root, "/", "__")
+}
@@ -0,0 +1,71 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// StdinPatchPath は、パッチを標準入力から読み込むことを示す --patch-file の値です。
+const StdinPatchPath = "-"
+
+// readPatch は、ユーザーが指定した unified diff 形式のパッチを読み込みます。
+// path が StdinPatchPath の場合は stdin から読み込みます。
+func readPatch(path string, stdin io.Reader) (string, error) {
+	var (
+		content []byte
+		err     error
+	)
+	if path == StdinPatchPath {
+		content, err = io.ReadAll(stdin)
+	} else {
+		content, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("パッチファイル '%s' の読み込みに失敗しました: %w", path, err)
+	}
+
+	patch := string(content)
+	if strings.TrimSpace(patch) != "" && !looksLikeUnifiedDiff(patch) {
+		slog.Warn("パッチが unified diff 形式ではない可能性があります。そのままレビューに使用します。", "path", path)
+	}
+	return patch, nil
+}
+
+// looksLikeUnifiedDiff は、内容が unified diff (git diff / diff -u / git format-patch) の形式かを簡易判定します。
+func looksLikeUnifiedDiff(patch string) bool {
+	return strings.Contains(patch, "diff --git ") ||
+		(strings.Contains(patch, "\n--- ") || strings.HasPrefix(patch, "--- ")) && strings.Contains(patch, "\n+++ ")
+}
+
+// patchMessages は git format-patch 形式のパッチから、各コミットの件名と本文を取り出します。
+// 件名 ("Subject: ") から、変更統計の前に置かれる区切り行 ("---") までをコミットメッセージとみなします。
+func patchMessages(patch string) []string {
+	var (
+		messages []string
+		current  []string
+		inHeader bool
+	)
+	for _, line := range strings.Split(patch, "\n") {
+		if subject, ok := strings.CutPrefix(line, "Subject: "); ok {
+			inHeader = true
+			current = []string{strings.TrimSpace(subject)}
+			continue
+		}
+		if !inHeader {
+			continue
+		}
+		if strings.TrimRight(line, "\r") == "---" || strings.HasPrefix(line, "diff --git ") {
+			messages = append(messages, strings.Join(current, "\n"))
+			inHeader = false
+			continue
+		}
+		current = append(current, line)
+	}
+	if inHeader {
+		messages = append(messages, strings.Join(current, "\n"))
+	}
+	return messages
+}
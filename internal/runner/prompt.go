@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/prompttmpl"
+
+	"github.com/shouni/gemini-reviewer-core/pkg/prompts"
+)
+
+// dataPromptBuilder は、差分以外の値 (リポジトリ・ブランチ・コミット) もテンプレートに渡せる PromptBuilder です。
+type dataPromptBuilder interface {
+	BuildData(data prompttmpl.Data) (string, error)
+}
+
+// templateData は、PromptBuilder が差分以外の値をテンプレートに渡せるかを返します。
+func (r *ReviewRunner) templateData() bool {
+	_, ok := r.promptBuilder.(dataPromptBuilder)
+	return ok
+}
+
+// buildPrompt は、codeDiff をレビューするプロンプトを PromptBuilder で組み立てます。
+// 利用者のテンプレートを使用する場合は、リポジトリ・ブランチと直前の Run で取得したコミットもテンプレートに渡します。
+func (r *ReviewRunner) buildPrompt(cfg config.ReviewConfig, codeDiff string) (string, error) {
+	pb, ok := r.promptBuilder.(dataPromptBuilder)
+	if !ok {
+		return r.promptBuilder.Build(cfg.ReviewMode, prompts.TemplateData{DiffContent: codeDiff})
+	}
+	data := prompttmpl.Data{
+		DiffContent:   codeDiff,
+		RepoURL:       cfg.RepoURL,
+		BaseBranch:    cfg.BaseBranch,
+		FeatureBranch: cfg.FeatureBranch,
+		Mode:          cfg.ReviewMode,
+	}
+	for _, c := range r.commits {
+		data.Commits = append(data.Commits, prompttmpl.NewCommit(c.Hash, c.Author, c.Date, c.Message))
+	}
+	return pb.BuildData(data)
+}
@@ -0,0 +1,73 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/inline"
+	"git-gemini-reviewer-go/internal/respcache"
+	"git-gemini-reviewer-go/internal/streamai"
+)
+
+// WithResponseCache は、同じプロンプトに対する AI の応答を ttl の期間再利用するキャッシュを設定します。
+func WithResponseCache(store respcache.Store, ttl time.Duration) Option {
+	return func(r *ReviewRunner) {
+		r.responseCache = store
+		r.responseCacheTTL = ttl
+	}
+}
+
+// responseCacheKey は、AI の呼び出しを決める cfg の値とプロンプトから、応答のキャッシュのキーを返します。
+// プロンプトにはテンプレートと差分が含まれるため、モデル・テンプレート・差分のいずれかが変わるとキーも変わります。
+func responseCacheKey(cfg config.ReviewConfig, finalPrompt string) string {
+	models := strings.Join(append([]string{cfg.GeminiModel}, cfg.FallbackModels...), ",")
+	params := fmt.Sprintf("%g/%g/%d", cfg.AITemperature, cfg.AITopP, cfg.AIMaxOutputTokens)
+	return respcache.Key(cfg.AIProvider, cfg.AIBaseURL, models, params, finalPrompt)
+}
+
+// cachedResponse は、キャッシュに保存された key の応答を返します。
+// キャッシュが無効な場合、保存されていない場合、読み込みに失敗した場合は nil を返します。
+// キャッシュの失敗で AI の呼び出しを止めないよう、読み込みのエラーはログに留めます。
+func (r *ReviewRunner) cachedResponse(ctx context.Context, key string) *respcache.Entry {
+	if r.responseCache == nil {
+		return nil
+	}
+	e, err := r.responseCache.Get(ctx, key)
+	if err != nil {
+		slog.WarnContext(ctx, "応答のキャッシュの読み込みに失敗しました。AIを呼び出します。", "error", err)
+		return nil
+	}
+	if e == nil {
+		return nil
+	}
+	slog.InfoContext(ctx, "キャッシュされた応答を使用します。AIは呼び出しません。", "model", e.Model, "cached_at", e.CreatedAt)
+	// ストリーミング出力では、キャッシュされた応答をまとめて書き出す
+	if w := streamai.WriterFrom(ctx); w != nil {
+		if _, err := io.WriteString(w, e.Result); err != nil {
+			slog.WarnContext(ctx, "キャッシュされた応答の書き出しに失敗しました。", "error", err)
+		}
+	}
+	return e
+}
+
+// storeResponse は応答をキャッシュに保存します。保存に失敗してもレビューは継続するため、エラーはログに留めます。
+// 構造化された指摘として解析できない応答は、再実行で同じ失敗を繰り返さないよう保存しません。
+func (r *ReviewRunner) storeResponse(ctx context.Context, cfg config.ReviewConfig, key, result string) {
+	if r.responseCache == nil || strings.TrimSpace(result) == "" {
+		return
+	}
+	if cfg.InlineFindings {
+		if _, err := inline.Parse(result); err != nil {
+			return
+		}
+	}
+	e := respcache.Entry{Model: cfg.GeminiModel, Result: result, CreatedAt: time.Now()}
+	if err := r.responseCache.Put(ctx, key, e, r.responseCacheTTL); err != nil {
+		slog.WarnContext(ctx, "応答のキャッシュへの保存に失敗しました。", "error", err)
+	}
+}
@@ -3,20 +3,140 @@ package runner
 import (
 	"context"
 	"fmt"
+	"git-gemini-reviewer-go/internal/aggregate"
+	"git-gemini-reviewer-go/internal/archive"
+	"git-gemini-reviewer-go/internal/baseline"
+	"git-gemini-reviewer-go/internal/chunk"
 	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/criticalpath"
+	"git-gemini-reviewer-go/internal/diffguard"
+	"git-gemini-reviewer-go/internal/diffstat"
+	"git-gemini-reviewer-go/internal/difftransform"
+	"git-gemini-reviewer-go/internal/estimate"
+	"git-gemini-reviewer-go/internal/gitclient"
+	"git-gemini-reviewer-go/internal/guidelines"
+	"git-gemini-reviewer-go/internal/inline"
+	"git-gemini-reviewer-go/internal/messages"
+	"git-gemini-reviewer-go/internal/modelchain"
+	"git-gemini-reviewer-go/internal/monorepo"
+	"git-gemini-reviewer-go/internal/pkg/retry"
+	"git-gemini-reviewer-go/internal/policy"
+	"git-gemini-reviewer-go/internal/progress"
+	"git-gemini-reviewer-go/internal/ratelimit"
+	"git-gemini-reviewer-go/internal/respcache"
+	"git-gemini-reviewer-go/internal/reviewignore"
+	"git-gemini-reviewer-go/internal/reviewmode"
+	"git-gemini-reviewer-go/internal/schema"
+	"git-gemini-reviewer-go/internal/suppress"
+	"git-gemini-reviewer-go/internal/tokencount"
+	"git-gemini-reviewer-go/internal/tokenusage"
+	"git-gemini-reviewer-go/internal/verdict"
 	"log/slog"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/shouni/gemini-reviewer-core/pkg/adapters"
 	"github.com/shouni/gemini-reviewer-core/pkg/prompts"
 )
 
+const (
+	// gitRetryBudget はクローンやフェッチの1操作に許容するリトライを含めた制限時間です。
+	gitRetryBudget = 5 * time.Minute
+	// aiRetryBudget はAIレビュー1回に許容するリトライを含めた制限時間です。
+	aiRetryBudget = 10 * time.Minute
+)
+
 // ReviewRunner はコードレビューのビジネスロジックを実行します。
 // 必要な依存関係（アダプタ）をフィールドとして保持します。
 type ReviewRunner struct {
 	gitService    adapters.GitService
 	geminiService adapters.CodeReviewAI
 	promptBuilder prompts.ReviewPromptBuilder
+	archiver      archive.Archiver
+	limiter       ratelimit.Limiter
+	counter       tokencount.Counter
+	personaPrompt string
+	followUpNote  string
+	transformers  difftransform.Chain
+	failedChecks  []string
+	// since は、前回のレビュー以降のコミットのみをレビューする場合の前回のレビューです。
+	since *SinceReview
+	// inlineReview は cfg.InlineFindings が有効な場合に、直前の Run で得た構造化された指摘です。
+	inlineReview *inline.Review
+	// commitMessages は直前の Run で取得したフィーチャーブランチのコミットメッセージ (新しい順) です。
+	commitMessages []string
+	// diffStats は直前の Run でレビューした差分 (除外・マスクの変換後) の種類別の変更量です。
+	diffStats diffstat.Stats
+	// baseCommit と headCommit は、直前の Run でレビューした差分の両端のコミットの SHA です。
+	baseCommit, headCommit string
+	// fileContents は cfg.FileContext が有効な場合に、直前の Run で読み込んだ変更されたファイルの変更後の内容です。
+	fileContents map[string]string
+	// commits は直前の Run で取得した差分に含まれるコミット (新しい順) です。
+	commits []gitclient.Commit
+	// usage は直前の Run で AI に送信・受信したトークン数と推定費用です。
+	usage tokenusage.Report
+	// prices は推定費用の計算に使用するモデルごとの料金です。nil の場合は組み込みの料金表を使用します。
+	prices map[string]estimate.Price
+	// responseCache は同じプロンプトに対する応答を再利用するキャッシュです。nil の場合は使用しません。
+	responseCache    respcache.Store
+	responseCacheTTL time.Duration
+	// model は直前の Run で最後に応答したモデルです。フォールバックの連鎖では、先頭のモデルと異なる場合があります。
+	model string
+	// issues は直前の Run で発生した失敗を深刻度とともに収集します。
+	issues *aggregate.Pipeline
+}
+
+// Option は ReviewRunner の任意の依存関係を設定するための関数です。
+type Option func(*ReviewRunner)
+
+// WithArchiver は、プロンプトとレスポンスを監査用に保存する Archiver を設定します。
+func WithArchiver(a archive.Archiver) Option {
+	return func(r *ReviewRunner) {
+		r.archiver = a
+	}
+}
+
+// WithRateLimiter は、AIへのリクエスト前にクォータの空きを待機する Limiter を設定します。
+func WithRateLimiter(l ratelimit.Limiter) Option {
+	return func(r *ReviewRunner) {
+		r.limiter = l
+	}
+}
+
+// WithPersonaPrompt は、レビューモードのプロンプトの前に置くペルソナの指示を設定します。
+func WithPersonaPrompt(prompt string) Option {
+	return func(r *ReviewRunner) {
+		r.personaPrompt = prompt
+	}
+}
+
+// WithDiffTransformers は、取得した差分をプロンプトの組み立て前に加工する変換器を設定します。
+func WithDiffTransformers(chain difftransform.Chain) Option {
+	return func(r *ReviewRunner) {
+		r.transformers = chain
+	}
+}
+
+// WithFollowUpPrompt は、前回のレビューの指摘の対応状況を確認させる指示を設定します。
+func WithFollowUpPrompt(prompt string) Option {
+	return func(r *ReviewRunner) {
+		r.followUpNote = prompt
+	}
+}
+
+// SinceReview は、差分の基準とする前回のレビューです。
+type SinceReview struct {
+	ReviewID string
+	// HeadCommit は前回レビューしたフィーチャーブランチのコミットの SHA です。
+	HeadCommit string
+}
+
+// WithSinceReview は、前回のレビューでレビューしたコミット以降の差分のみをレビューするよう設定します。
+func WithSinceReview(since SinceReview) Option {
+	return func(r *ReviewRunner) {
+		r.since = &since
+	}
 }
 
 // NewReviewRunner は ReviewRunner の新しいインスタンスを生成します。
@@ -25,66 +145,592 @@ func NewReviewRunner(
 	git adapters.GitService,
 	gemini adapters.CodeReviewAI,
 	pb prompts.ReviewPromptBuilder,
+	opts ...Option,
 ) *ReviewRunner {
-	return &ReviewRunner{
+	r := &ReviewRunner{
 		gitService:    git,
 		geminiService: gemini,
 		promptBuilder: pb,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // Run はGit Diffを取得し、Gemini AIでレビューを実行します。
+// 失敗は *aggregate.PipelineError として返します。致命的な失敗を含まない場合 (クリーンアップの失敗など)、
+// レビュー結果とともに縮退した処理の一覧を返します。
 func (r *ReviewRunner) Run(
 	ctx context.Context,
 	cfg config.ReviewConfig,
 ) (string, error) {
+	r.issues = aggregate.NewPipeline()
+	r.inlineReview = nil
+	r.commitMessages = nil
+	r.diffStats = diffstat.Stats{}
+	r.baseCommit, r.headCommit = "", ""
+	r.fileContents = nil
+	r.commits = nil
+	r.usage = tokenusage.Report{}
+	r.model = ""
 
-	slog.Info("Gitリポジトリのセットアップと差分取得を開始します。")
-	// Gitリポジトリのクローンまたは更新
-	err := r.gitService.CloneOrUpdate(ctx, cfg.RepoURL)
+	// PRラベルによるスキップは、リポジトリにアクセスする前に判定する
+	if reason, ok := labelSkipReason(cfg); ok {
+		slog.Info("スキップ指定によりAIレビューをスキップします。", "label", reason.Label)
+		return r.skipped(ctx, cfg, reason)
+	}
+
+	// コード差分を取得 (パッチファイル指定時はGit操作を行わない)
+	done := progress.Start(ctx, cfg.ReviewID, progress.PhaseDiff, "")
+	src, err := r.loadDiff(ctx, cfg)
+	done(err)
 	if err != nil {
-		return "", fmt.Errorf("リポジトリのセットアップに失敗しました: %w", err)
+		return "", r.issues.Fatal("diff", err)
 	}
+	r.commitMessages = src.CommitMessages
+	r.baseCommit, r.headCommit = src.BaseCommit, src.HeadCommit
+	r.fileContents = src.FileContents
+	r.commits = src.Commits
 
-	// クリーンアップを遅延実行 (常に実行を保証)
-	defer func() {
-		if cleanupErr := r.gitService.Cleanup(ctx); cleanupErr != nil {
-			slog.Error("Gitリポジトリのクリーンアップに失敗しました。", "error", cleanupErr)
-		}
-	}()
+	if reason, ok := markerSkipReason(cfg, src); ok {
+		slog.Info("スキップマーカーによりAIレビューをスキップします。", "marker", reason.Marker, "commit", reason.Commit)
+		return r.skipped(ctx, cfg, reason)
+	}
 
-	// リモートから最新の変更をフェッチ
-	if err := r.gitService.Fetch(ctx); err != nil {
-		return "", fmt.Errorf("最新の変更のフェッチに失敗しました: %w", err)
+	// 除外・マスクなどの変換は、変更構成の集計とレビューの両方に反映する
+	done = progress.Start(ctx, cfg.ReviewID, progress.PhaseTransform, "")
+	src.Diff, err = r.transformers.Apply(ctx, src.Diff)
+	done(err)
+	if err != nil {
+		return "", r.issues.Fatal("diff.transform", err)
 	}
 
-	// コード差分を取得
-	codeDiff, err := r.gitService.GetCodeDiff(ctx, cfg.BaseBranch, cfg.FeatureBranch)
+	if strings.TrimSpace(src.Diff) == "" {
+		progress.Emit(ctx, progress.Event{ReviewID: cfg.ReviewID, Phase: progress.PhaseReview, Status: progress.Skipped, Detail: string(messages.NoDiff)})
+		return "", r.issues.Err()
+	}
+	slog.Info("差分の取得に成功しました。", "size_bytes", len(src.Diff))
+
+	r.diffStats = diffstat.Compute(src.Diff)
+	logDiffStats(ctx, r.diffStats)
+	r.failedChecks = policy.EvaluateChecks(cfg.RequiredChecks, r.diffStats)
+	if len(r.failedChecks) > 0 {
+		slog.Warn("必須チェックを満たしていません。", "checks", r.failedChecks)
+	}
+
+	// ファイル数・ハンク数の上限を超える場合は、リスクの高いファイルを優先して差分を削減する
+	guard := diffguard.Apply(src.Diff, diffguard.Limits{MaxFiles: cfg.MaxFiles, MaxHunks: cfg.MaxHunks})
+	if len(guard.Omitted) > 0 {
+		slog.Warn("差分が上限を超えたため、一部のファイルをレビュー対象から除外しました。", "kept", guard.Kept, "omitted", len(guard.Omitted))
+	}
+
+	// 重要パスへの変更は、指摘の重大度を引き上げるよう AI に伝える
+	touched := criticalpath.Touched(src.Diff, cfg.CriticalPaths)
+	promptNote := src.Guidelines.PromptNote() + guard.PromptNote() + criticalpath.PromptNote(touched) + incrementalNote(src.SinceReviewID) + submoduleNote(src.Submodules)
+	if cfg.IncludeCommitLog {
+		promptNote += commitLogNote(src.Commits, cfg.CommitLogMax)
+	}
+	promptNote += languageNote(cfg.ReviewLanguage)
+
+	var reviewResult string
+	done = progress.Start(ctx, cfg.ReviewID, progress.PhaseReview, cfg.GeminiModel)
+	if modes := reviewmode.Split(cfg.ReviewMode); len(modes) > 1 {
+		reviewResult, err = r.reviewModes(ctx, cfg, modes, guard.Diff, src.ModuleRoots, promptNote)
+	} else if cfg.TwoPass {
+		reviewResult, err = r.reviewTwoPass(ctx, cfg, guard.Diff, promptNote)
+	} else if cfg.SplitModules {
+		reviewResult, err = r.reviewModules(ctx, cfg, guard.Diff, src.ModuleRoots, promptNote)
+	} else {
+		reviewResult, err = r.reviewDiff(ctx, cfg, guard.Diff, promptNote)
+	}
+	done(err)
 	if err != nil {
-		return "", fmt.Errorf("コード差分の取得に失敗しました: %w", err)
+		return "", r.issues.Fatal("ai.review", err)
+	}
+	if reviewResult == "" {
+		return "", r.issues.Err()
 	}
+	// 変更後のコードの ai-review:ignore コメントに一致する指摘は、抑制された指摘として扱う
+	suppressions := suppress.Parse(guard.Diff)
+	var suppressed, known int
+	var criticalNotice string
+	if cfg.InlineFindings {
+		structured, err := inline.Parse(reviewResult)
+		if err != nil {
+			return "", r.issues.Fatal("ai.inline", err)
+		}
+		if cfg.StrictFindings {
+			if err := structured.Validate(); err != nil {
+				return "", r.issues.Fatal("ai.inline", err)
+			}
+		}
+		structured.Anchor(guard.Diff)
+		suppressed = suppress.Review(&structured, suppressions)
+		known = r.applyBaseline(cfg, &structured, guard.Diff)
+		aiVerdict := structured.Verdict()
+		if raised := criticalpath.RaiseSeverities(&structured, cfg.CriticalPaths); raised > 0 {
+			slog.Info("重要パスへの指摘の重大度を引き上げました。", "raised", raised)
+		}
+		r.inlineReview = &structured
+		reviewResult = structured.Markdown()
+		criticalNotice = criticalpath.StructuredNotice(touched, criticalpath.Findings(reviewResult, cfg.CriticalPaths), aiVerdict, structured.Verdict())
+	} else {
+		reviewResult, suppressed = suppress.Markdown(reviewResult, suppressions)
+		criticalNotice = criticalpath.Notice(touched, criticalpath.Findings(reviewResult, cfg.CriticalPaths), verdict.Parse(reviewResult))
+	}
+	if suppressed > 0 {
+		slog.Info("コード中のコメントにより指摘を抑制しました。", "suppressed", suppressed)
+	}
+
+	// 変更構成のバッジと、ブランチ名とコミットメッセージに含まれる課題キーのリンクを冒頭に付与し、
+	// 除外したファイルがある場合は末尾に一覧を付与する
+	return reviewHeader(cfg, src, guard, r.failedChecks, criticalNotice, suppress.Notice(suppressed), baseline.Notice(known)) + reviewResult + guard.OmittedSection(), r.issues.Err()
+}
 
-	if strings.TrimSpace(codeDiff) == "" {
-		return "", nil
+// skipped はスキップされた旨の結果を返します。
+func (r *ReviewRunner) skipped(ctx context.Context, cfg config.ReviewConfig, reason messages.SkipReason) (string, error) {
+	progress.Emit(ctx, progress.Event{ReviewID: cfg.ReviewID, Phase: progress.PhaseReview, Status: progress.Skipped, Detail: reason.Kind})
+	result, err := skippedResult(cfg, reason)
+	if err != nil {
+		return "", r.issues.Fatal("messages.skipped", err)
 	}
-	slog.Info("Git差分の取得に成功しました。", "size_bytes", len(codeDiff))
+	return result, r.issues.Err()
+}
+
+// FailedChecks は、直前の Run で満たされなかった必須チェックの説明を返します。
+func (r *ReviewRunner) FailedChecks() []string {
+	return r.failedChecks
+}
+
+// InlineReview は、直前の Run で得た構造化された指摘を返します。cfg.InlineFindings が無効な場合は nil を返します。
+func (r *ReviewRunner) InlineReview() *inline.Review {
+	return r.inlineReview
+}
+
+// CommitMessages は、直前の Run で取得したフィーチャーブランチのコミットメッセージを新しい順に返します。
+func (r *ReviewRunner) CommitMessages() []string {
+	return r.commitMessages
+}
+
+// Commits は、直前の Run でレビューした差分の両端のコミットの SHA を返します。
+// パッチファイルや作業ツリーをレビューした場合、または解決できなかった場合は空文字列です。
+func (r *ReviewRunner) Commits() (base, head string) {
+	return r.baseCommit, r.headCommit
+}
+
+// Usage は、直前の Run で AI に送信・受信したトークン数と推定費用を返します。
+func (r *ReviewRunner) Usage() tokenusage.Report {
+	return r.usage
+}
+
+// Model は、直前の Run で最終的な結果を応答したモデルを返します。AI に送信しなかった場合は空文字列です。
+func (r *ReviewRunner) Model() string {
+	return r.model
+}
+
+// DiffStats は、直前の Run でレビューした差分の種類別の変更量を返します。
+func (r *ReviewRunner) DiffStats() diffstat.Stats {
+	return r.diffStats
+}
 
+// logDiffStats は、差分の変更量と変更の大きいファイルを記録します。
+func logDiffStats(ctx context.Context, stats diffstat.Stats) {
+	total := stats.Total()
+	largest := make([]string, 0, diffstat.LargestFiles)
+	for _, f := range stats.Largest(diffstat.LargestFiles) {
+		largest = append(largest, fmt.Sprintf("%s (+%d/-%d)", f.Path, f.Added, f.Removed))
+	}
+	slog.InfoContext(ctx, "差分の変更量を集計しました。", "files", total.Files, "added", total.Added, "removed", total.Removed, "largest_files", largest)
+}
+
+// reviewDiff は差分からプロンプトを生成し、AIによるレビューを実行します。
+// promptNote は差分の削減などツール側の補足事項で、プロンプトの前置きとして AI に伝えます。
+// 差分が cfg.ChunkTokens を超える場合は、分割してレビューした結果を統合します。
+// プロンプトが cfg.MaxInputTokens を超える場合も、分割が有効であれば上限に収まるよう分割し直します。
+func (r *ReviewRunner) reviewDiff(ctx context.Context, cfg config.ReviewConfig, codeDiff, promptNote string) (string, error) {
+	if chunks := chunk.Split(codeDiff, cfg.ChunkTokens); len(chunks) > 1 {
+		return r.reviewChunks(ctx, cfg, chunks, promptNote)
+	}
+	result, err := r.reviewSingle(ctx, cfg, codeDiff, promptNote)
+	if budgetErr, ok := asBudgetError(err); ok {
+		if chunks, maxTokens := rechunk(cfg, codeDiff, budgetErr); chunks != nil {
+			slog.WarnContext(ctx, "プロンプトが入力トークン数の上限を超えるため、差分を分割し直します。",
+				"tokens", budgetErr.Tokens, "limit", budgetErr.Limit)
+			cfg.ChunkTokens = maxTokens
+			return r.reviewChunks(ctx, cfg, chunks, promptNote)
+		}
+	}
+	return result, err
+}
+
+// reviewSingle は差分全体を1回のリクエストでレビューします。
+func (r *ReviewRunner) reviewSingle(ctx context.Context, cfg config.ReviewConfig, codeDiff, promptNote string) (string, error) {
 	// 5. プロンプトの生成
 	slog.InfoContext(ctx, "3. AIプロンプトを生成中...", "mode", cfg.ReviewMode)
-	templateData := prompts.TemplateData{DiffContent: codeDiff}
-	finalPrompt, err := r.promptBuilder.Build(cfg.ReviewMode, templateData)
+	finalPrompt, err := r.buildPrompt(cfg, codeDiff)
 	if err != nil {
 		return "", fmt.Errorf("プロンプトの組み立てに失敗しました: %w", err)
 	}
+	stats := diffstat.Compute(codeDiff)
+	prefix := r.personaPrompt + stats.PromptContext(cfg.ReviewMode) + promptNote + r.followUpNote
+	if cfg.InlineFindings {
+		finalPrompt += inline.PromptInstruction
+	}
+	finalPrompt = prefix + r.fileContextNote(cfg, codeDiff, prefix+finalPrompt) + finalPrompt
+	return r.ask(ctx, cfg, finalPrompt)
+}
+
+// ask はプロンプトを AI に送信し、応答を返します。リクエストはレート制限とリトライの対象とし、アーカイブに保存します。
+func (r *ReviewRunner) ask(ctx context.Context, cfg config.ReviewConfig, finalPrompt string) (string, error) {
+	// 同じプロンプトの応答がキャッシュにある場合は、入力トークン数の計数とレート制限の待機も行わずに返す
+	cacheKey := responseCacheKey(cfg, finalPrompt)
+	if e := r.cachedResponse(ctx, cacheKey); e != nil {
+		r.model = e.Model
+		return e.Result, nil
+	}
 
 	// AIレビューの実行
+	tokens := r.countTokens(ctx, cfg, finalPrompt)
+	if err := checkBudget(cfg, tokens); err != nil {
+		return "", err
+	}
 	slog.Info("Gemini AIによるコードレビューを開始します。", "model", cfg.GeminiModel)
 
 	// Gemini Adapterにレビューを依頼
-	reviewResult, err := r.geminiService.ReviewCodeDiff(ctx, finalPrompt)
+	// フォールバックの連鎖 (--gemini にカンマ区切りで指定) では、応答したモデルが Recorder に記録されます
+	startedAt := time.Now()
+	recorder := &modelchain.Recorder{}
+	ctx = modelchain.WithRecorder(ctx, recorder)
+	// 応答のメタデータにトークン数を含むアダプタは、tokenusage.Recorder に記録します
+	usage := &tokenusage.Recorder{}
+	ctx = tokenusage.WithRecorder(ctx, usage)
+	var reviewResult string
+	err := retry.Do(ctx, "gemini.review_code_diff", func(ctx context.Context) error {
+		// リトライを含め、すべてのリクエストをレート制限の対象とする
+		if r.limiter != nil {
+			if err := r.limiter.Wait(ctx, tokens); err != nil {
+				return retry.Permanent(err)
+			}
+		}
+		var err error
+		reviewResult, err = r.geminiService.ReviewCodeDiff(ctx, finalPrompt)
+		return err
+	}, retry.WithBudget(aiRetryBudget))
+	if model := recorder.Model(); model != "" {
+		cfg.GeminiModel = model
+	}
+	r.archive(ctx, cfg, finalPrompt, reviewResult, startedAt, err)
 	if err != nil {
 		return "", fmt.Errorf("AIレビューの実行に失敗しました: %w", err)
 	}
+	r.model = cfg.GeminiModel
+	input, output, measured := usage.Tokens()
+	if !measured {
+		input, output = tokens, ratelimit.EstimateTokens(reviewResult)
+	}
+	r.usage.Add(input, output, !measured, r.price(cfg.GeminiModel))
+	attrs := []any{"model", cfg.GeminiModel, "input_tokens", input, "output_tokens", output, "estimated", !measured}
+	if price := r.price(cfg.GeminiModel); price != nil {
+		attrs = append(attrs, "estimated_cost_usd", fmt.Sprintf("%.4f", price.Cost(input, output)))
+	}
+	slog.InfoContext(ctx, "AIの応答を受信しました。", attrs...)
+	r.storeResponse(ctx, cfg, cacheKey, reviewResult)
 
 	return reviewResult, nil
 }
+
+// diffSource はレビュー対象の差分と、クリーンアップ前のリポジトリから収集した付随情報です。
+type diffSource struct {
+	Diff string
+	// ModuleRoots は cfg.SplitModules が有効な場合に、ワークツリーから検出したモジュールのルートです。
+	ModuleRoots []string
+	// CommitMessages はフィーチャーブランチのコミットメッセージです。課題キーとスキップマーカーの検出に使用します。
+	CommitMessages []string
+	// StackParent は cfg.Stack が指定された場合に、差分の基準としたスタックの親ブランチです。
+	StackParent string
+	// BaseCommit と HeadCommit は、差分の両端のコミットの SHA です。
+	BaseCommit, HeadCommit string
+	// SinceReviewID は、前回のレビュー以降のコミットのみを差分とした場合の前回のレビューIDです。
+	SinceReviewID string
+	// Submodules は、cfg.Submodules が 'log' または 'diff' の場合に取得したサブモジュールの参照先の変更です。
+	Submodules []gitclient.SubmoduleChange
+	// FileContents は、cfg.FileContext が有効な場合に読み込んだ変更されたファイルの変更後の内容です (パスごと)。
+	FileContents map[string]string
+	// Commits は、cfg.IncludeCommitLog が有効な場合、またはテンプレートがコミットを参照できる場合に取得した差分に含まれるコミットです (新しい順)。
+	Commits []gitclient.Commit
+	// Guidelines は、cfg.GuidelinesFiles から読み込んだリポジトリの規約です。
+	Guidelines guidelines.Guidelines
+}
+
+// ancestorChecker はコミットの祖先関係を判定できる GitService です。
+type ancestorChecker interface {
+	IsAncestor(ctx context.Context, ancestor, rev string) (bool, error)
+}
+
+// commitResolver はブランチ名やリビジョンをコミットの SHA に解決できる GitService です。
+type commitResolver interface {
+	ResolveCommit(ctx context.Context, rev string) (string, error)
+}
+
+// sinceApplies は、前回レビューしたコミットが現在のフィーチャーブランチの祖先であり、差分の基準にできるかを返します。
+// 強制プッシュで履歴が書き換えられた場合や、コミットを判定できない場合はブランチ全体をレビューします。
+func (r *ReviewRunner) sinceApplies(ctx context.Context, feature string) bool {
+	checker, ok := r.gitService.(ancestorChecker)
+	if !ok {
+		return false
+	}
+	ancestor, err := checker.IsAncestor(ctx, r.since.HeadCommit, feature)
+	if err != nil {
+		slog.Warn("前回レビューしたコミットを判定できないため、ブランチ全体をレビューします。", "since", r.since.HeadCommit, "error", err)
+		return false
+	}
+	if !ancestor {
+		slog.Warn("前回レビューしたコミットがフィーチャーブランチの履歴にないため (強制プッシュなど)、ブランチ全体をレビューします。", "since", r.since.HeadCommit)
+		return false
+	}
+	return true
+}
+
+// incrementalNote は、差分が前回のレビュー以降のコミットのみであることを AI に伝えるプロンプトの前置きを返します。
+func incrementalNote(previousReviewID string) string {
+	if previousReviewID == "" {
+		return ""
+	}
+	return "## 🔂 差分の範囲 (ツールによる自動判定)\n\n" +
+		"この差分は、前回のレビュー (レビューID: " + previousReviewID + ") 以降に追加されたコミットのみです。ブランチのそれ以前の変更はレビュー済みのため、差分に含まれない箇所への指摘は控えてください。\n\n---\n\n"
+}
+
+// commitMessageLister はフィーチャーブランチのコミットメッセージを取得できる GitService です。
+type commitMessageLister interface {
+	CommitMessages(ctx context.Context, baseBranch, featureBranch string) ([]string, error)
+}
+
+// fileReader はリビジョンのファイルの内容を読み込める GitService です。
+type fileReader interface {
+	ReadFile(ctx context.Context, rev, path string) ([]byte, error)
+}
+
+// filterReviewIgnore は、除外ファイルの内容 content のパターンに一致するファイルを差分から取り除きます。
+// 除外ファイルの読み込みに失敗した場合 (readErr) は、除外せずにレビューを継続し、縮退した処理として記録します。
+func (r *ReviewRunner) filterReviewIgnore(cfg config.ReviewConfig, diff string, content []byte, readErr error) string {
+	if readErr != nil {
+		r.issues.Degrade("review_ignore", fmt.Errorf("除外ファイル '%s' の読み込みに失敗しました: %w", cfg.ReviewIgnoreFile, readErr))
+		return diff
+	}
+	matcher := reviewignore.Parse(string(content))
+	if matcher == nil {
+		return diff
+	}
+	diff, excluded := matcher.Filter(diff)
+	if len(excluded) > 0 {
+		slog.Info("除外ファイルに一致したファイルをレビュー対象から除外しました。", "file", cfg.ReviewIgnoreFile, "count", len(excluded), "files", excluded)
+	}
+	return diff
+}
+
+// loadGuidelines は、規約ファイルの候補を read で読み込み、最初に見つかった規約を返します。
+// 読み込みの失敗は規約なしでレビューを継続し、縮退した処理として記録します。
+func (r *ReviewRunner) loadGuidelines(cfg config.ReviewConfig, read func(path string) ([]byte, error)) guidelines.Guidelines {
+	if len(cfg.GuidelinesFiles) == 0 {
+		return guidelines.Guidelines{}
+	}
+	rules, err := guidelines.Find(cfg.GuidelinesFiles, read)
+	if err != nil {
+		r.issues.Degrade("guidelines", err)
+	}
+	if rules.Path != "" {
+		slog.Info("リポジトリの規約ファイルをプロンプトに含めます。", "path", rules.Path, "truncated", rules.Truncated)
+	}
+	return rules
+}
+
+// loadDiff はレビュー対象の差分を取得します。
+// cfg.PatchFile が指定されている場合は Git リポジトリにアクセスせず、パッチをそのまま使用します。
+// cfg.Worktree が指定されている場合は、ローカルリポジトリのコミットされていない変更を使用します。
+func (r *ReviewRunner) loadDiff(ctx context.Context, cfg config.ReviewConfig) (diffSource, error) {
+	if cfg.PatchFile != "" {
+		slog.Info("パッチファイルから差分を読み込みます。Git操作はスキップします。", "path", cfg.PatchFile)
+		patch, err := readPatch(cfg.PatchFile, os.Stdin)
+		return diffSource{Diff: patch, CommitMessages: patchMessages(patch)}, err
+	}
+	if cfg.Worktree != "" {
+		changes, err := gitclient.ParseWorktreeChanges(cfg.WorktreeChanges)
+		if err != nil {
+			return diffSource{}, err
+		}
+		slog.Info("作業ツリーのコミットされていない変更を読み込みます。リモートへのアクセスはスキップします。", "path", cfg.Worktree, "changes", changes)
+		diff, err := gitclient.WorktreeDiff(ctx, cfg.Worktree, changes)
+		if err != nil {
+			return diffSource{}, fmt.Errorf("作業ツリーの差分の取得に失敗しました: %w", err)
+		}
+		if cfg.ReviewIgnoreFile != "" {
+			content, err := gitclient.WorktreeFile(cfg.Worktree, cfg.ReviewIgnoreFile)
+			diff = r.filterReviewIgnore(cfg, diff, content, err)
+		}
+		contents := collectFileContents(cfg, diff, func(path string) ([]byte, error) {
+			return gitclient.WorktreeFile(cfg.Worktree, path)
+		})
+		rules := r.loadGuidelines(cfg, func(path string) ([]byte, error) {
+			return gitclient.WorktreeFile(cfg.Worktree, path)
+		})
+		return diffSource{Diff: diff, FileContents: contents, Guidelines: rules}, nil
+	}
+
+	slog.Info("Gitリポジトリのセットアップと差分取得を開始します。")
+	// Gitリポジトリのクローンまたは更新
+	err := retry.Do(ctx, "git.clone_or_update", func(ctx context.Context) error {
+		return r.gitService.CloneOrUpdate(ctx, cfg.RepoURL)
+	}, retry.WithBudget(gitRetryBudget))
+	if err != nil {
+		return diffSource{}, fmt.Errorf("リポジトリのセットアップに失敗しました: %w", err)
+	}
+
+	// クリーンアップを遅延実行 (常に実行を保証)
+	defer func() {
+		if cleanupErr := r.gitService.Cleanup(ctx); cleanupErr != nil {
+			r.issues.Degrade("git.cleanup", fmt.Errorf("Gitリポジトリのクリーンアップに失敗しました: %w", cleanupErr))
+		}
+	}()
+
+	// リモートから最新の変更をフェッチ (同じ実行の中でフェッチ済みの場合は省略)
+	if cfg.SkipFetch {
+		slog.Info("フェッチ済みのクローンを使用します。フェッチはスキップします。", "path", cfg.LocalPath)
+	} else {
+		err = retry.Do(ctx, "git.fetch", r.gitService.Fetch, retry.WithBudget(gitRetryBudget))
+		if err != nil {
+			return diffSource{}, fmt.Errorf("最新の変更のフェッチに失敗しました: %w", err)
+		}
+	}
+
+	// スタックされたブランチは、レビュー済みの下位の層を含めないよう直近の親ブランチと比較する
+	var src diffSource
+	if len(cfg.Stack) > 0 {
+		src.StackParent, err = r.resolveStackBase(ctx, cfg)
+		if err != nil {
+			return diffSource{}, err
+		}
+		slog.Info("スタックの親ブランチとの差分を取得します。", "parent", src.StackParent, "trunk", cfg.BaseBranch)
+		cfg.BaseBranch = src.StackParent
+	}
+
+	// リビジョンが指定された場合は、ブランチの代わりに差分の両端とする
+	base, feature := cfg.BaseBranch, cfg.FeatureBranch
+	if cfg.BaseRev != "" {
+		base = cfg.BaseRev
+	}
+	if cfg.FeatureRev != "" {
+		feature = cfg.FeatureRev
+	}
+	if cfg.BaseRev != "" || cfg.FeatureRev != "" {
+		slog.Info("リビジョン間の差分を取得します。", "base", base, "feature", feature)
+	}
+
+	// 前回のレビュー以降のコミットのみをレビューする場合は、前回レビューしたコミットを差分の基準とする
+	diffBase := base
+	if r.since != nil && cfg.BaseRev == "" {
+		if r.sinceApplies(ctx, feature) {
+			diffBase = r.since.HeadCommit
+			src.SinceReviewID = r.since.ReviewID
+			slog.Info("前回のレビュー以降のコミットのみをレビューします。", "previous_review_id", r.since.ReviewID, "since", r.since.HeadCommit)
+		}
+	}
+
+	// コード差分を取得
+	src.Diff, err = r.gitService.GetCodeDiff(ctx, diffBase, feature)
+	if err != nil {
+		return diffSource{}, fmt.Errorf("コード差分の取得に失敗しました: %w", err)
+	}
+
+	if lister, ok := r.gitService.(submoduleLister); ok && (cfg.Submodules == string(gitclient.SubmoduleLog) || cfg.Submodules == string(gitclient.SubmoduleDiff)) {
+		src.Submodules, err = lister.SubmoduleChanges(ctx, diffBase, feature)
+		if err != nil {
+			slog.Warn("サブモジュールの変更の取得に失敗しました。参照先の変更のみをレビューします。", "error", err)
+		}
+	}
+
+	if lister, ok := r.gitService.(commitLister); ok && (cfg.IncludeCommitLog || r.templateData()) {
+		src.Commits, err = lister.Commits(ctx, diffBase, feature)
+		if err != nil {
+			slog.Warn("コミットの一覧の取得に失敗しました。コミットの一覧をプロンプトに含めずにレビューします。", "error", err)
+		}
+	}
+
+	// 除外ファイルは、フィーチャーブランチの変更で自身の差分を除外できないよう、ベース側の内容を使用します
+	if reader, ok := r.gitService.(fileReader); ok && cfg.ReviewIgnoreFile != "" {
+		content, err := reader.ReadFile(ctx, base, cfg.ReviewIgnoreFile)
+		src.Diff = r.filterReviewIgnore(cfg, src.Diff, content, err)
+	}
+
+	// 規約ファイルも、フィーチャーブランチの変更で指摘を避けるよう書き換えられないよう、ベース側の内容を使用します
+	if reader, ok := r.gitService.(fileReader); ok {
+		src.Guidelines = r.loadGuidelines(cfg, func(path string) ([]byte, error) {
+			return reader.ReadFile(ctx, base, path)
+		})
+	}
+
+	if reader, ok := r.gitService.(fileReader); ok {
+		src.FileContents = collectFileContents(cfg, src.Diff, func(path string) ([]byte, error) {
+			return reader.ReadFile(ctx, feature, path)
+		})
+	}
+
+	// ワークツリーはクリーンアップで削除されるため、モジュール境界はここで検出します
+	if cfg.SplitModules && cfg.InMemoryRepo {
+		slog.Info("メモリ上のリポジトリにはワークツリーがないため、モジュール境界は差分内のビルド定義のみで判定します。")
+	} else if cfg.SplitModules {
+		src.ModuleRoots, err = monorepo.FindRoots(os.DirFS(cfg.LocalPath), monorepo.DefaultMarkers)
+		if err != nil {
+			slog.Warn("ワークツリーからのモジュール境界の検出に失敗しました。差分内のビルド定義のみで判定します。", "error", err)
+		}
+	}
+
+	if resolver, ok := r.gitService.(commitResolver); ok {
+		src.BaseCommit, err = resolver.ResolveCommit(ctx, diffBase)
+		if err == nil {
+			src.HeadCommit, err = resolver.ResolveCommit(ctx, feature)
+		}
+		if err != nil {
+			slog.Warn("差分の両端のコミットの解決に失敗しました。レビュー履歴にはコミットを記録しません。", "error", err)
+			src.BaseCommit, src.HeadCommit = "", ""
+		}
+	}
+
+	if lister, ok := r.gitService.(commitMessageLister); ok {
+		src.CommitMessages, err = lister.CommitMessages(ctx, base, feature)
+		if err != nil {
+			slog.Warn("コミットメッセージの取得に失敗しました。スキップマーカーと課題キーの検出はブランチ名のみで行います。", "error", err)
+		}
+	}
+	return src, nil
+}
+
+// archive は Archiver が設定されている場合に、プロンプトとレスポンスを保存します。
+// アーカイブの失敗はレビュー結果に影響させず、縮退した処理として記録するのみとします。
+func (r *ReviewRunner) archive(ctx context.Context, cfg config.ReviewConfig, prompt, response string, startedAt time.Time, reviewErr error) {
+	if r.archiver == nil {
+		return
+	}
+
+	meta := archive.Metadata{
+		SchemaVersion: schema.Version,
+		ReviewID:      cfg.ReviewID,
+		RepoURL:       cfg.RepoURL,
+		BaseBranch:    cfg.BaseBranch,
+		FeatureBranch: cfg.FeatureBranch,
+		ReviewMode:    cfg.ReviewMode,
+		Model:         cfg.GeminiModel,
+		PromptVariant: cfg.PromptVariant,
+		StartedAt:     startedAt,
+		FinishedAt:    time.Now(),
+	}
+	if reviewErr != nil {
+		meta.Error = reviewErr.Error()
+	}
+
+	if err := r.archiver.Archive(ctx, archive.Record{Prompt: prompt, Response: response, Metadata: meta}); err != nil {
+		r.issues.Degrade("archive", fmt.Errorf("プロンプトとレスポンスのアーカイブに失敗しました (review_id: %s): %w", cfg.ReviewID, err))
+		return
+	}
+	slog.Info("プロンプトとレスポンスをアーカイブしました。", "review_id", cfg.ReviewID, "uri", cfg.ArchiveURI)
+}
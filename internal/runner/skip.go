@@ -0,0 +1,59 @@
+package runner
+
+import (
+	"strings"
+
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/messages"
+)
+
+// labelSkipReason は、PRに付与されたラベルにスキップ用のラベルが含まれる場合にその理由を返します。
+func labelSkipReason(cfg config.ReviewConfig) (messages.SkipReason, bool) {
+	if cfg.SkipLabel == "" {
+		return messages.SkipReason{}, false
+	}
+	for _, label := range cfg.PRLabels {
+		if strings.EqualFold(strings.TrimSpace(label), cfg.SkipLabel) {
+			return messages.SkipReason{Kind: "label", Label: cfg.SkipLabel}, true
+		}
+	}
+	return messages.SkipReason{}, false
+}
+
+// markerSkipReason は、ブランチのコミットメッセージにスキップマーカーが含まれる場合にその理由を返します。
+func markerSkipReason(cfg config.ReviewConfig, src diffSource) (messages.SkipReason, bool) {
+	if cfg.SkipMarker == "" {
+		return messages.SkipReason{}, false
+	}
+	marker := strings.ToLower(cfg.SkipMarker)
+	for _, msg := range src.CommitMessages {
+		if strings.Contains(strings.ToLower(msg), marker) {
+			subject, _, _ := strings.Cut(strings.TrimSpace(msg), "\n")
+			return messages.SkipReason{Kind: "marker", Marker: cfg.SkipMarker, Commit: subject}, true
+		}
+	}
+	return messages.SkipReason{}, false
+}
+
+// skippedResult は、スキップされたことを投稿先に明示するためのレビュー結果です。
+func skippedResult(cfg config.ReviewConfig, reason messages.SkipReason) (string, error) {
+	data := MessageData(cfg)
+	data.Skip = reason
+	return messages.Render(messages.Skipped, data, MessageOptions(cfg))
+}
+
+// MessageData は、定型メッセージのテンプレートに渡す値を設定から組み立てます。
+func MessageData(cfg config.ReviewConfig) messages.Data {
+	return messages.Data{
+		RepoURL:       cfg.RepoURL,
+		BaseBranch:    cfg.BaseBranch,
+		FeatureBranch: cfg.FeatureBranch,
+		ReviewID:      cfg.ReviewID,
+		Destination:   cfg.Destination,
+	}
+}
+
+// MessageOptions は、設定から定型メッセージのテンプレートの選択方法を返します。
+func MessageOptions(cfg config.ReviewConfig) messages.Options {
+	return messages.Options{Dir: cfg.MessageTemplateDir, Lang: cfg.MessageLang}
+}
@@ -0,0 +1,47 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/config"
+)
+
+// resolveStackBase は、スタックされたブランチ (cfg.Stack) のうちリモートに存在する最も近い親ブランチを返します。
+// cfg.Stack はトランクに近い順に並べたブランチで、フィーチャーブランチ自身を含む場合はそれより下の層のみを候補とします。
+// 親ブランチがすべてマージ済みで削除されている場合は cfg.BaseBranch を返します。
+func (r *ReviewRunner) resolveStackBase(ctx context.Context, cfg config.ReviewConfig) (string, error) {
+	stack := cfg.Stack
+	for i, b := range stack {
+		if strings.TrimSpace(b) == cfg.FeatureBranch {
+			stack = stack[:i]
+			break
+		}
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		branch := strings.TrimSpace(stack[i])
+		if branch == "" {
+			continue
+		}
+		exists, err := r.gitService.CheckRemoteBranchExists(ctx, branch)
+		if err != nil {
+			return "", fmt.Errorf("スタックの親ブランチ '%s' の確認に失敗しました: %w", branch, err)
+		}
+		if exists {
+			return branch, nil
+		}
+		slog.Info("スタックの親ブランチがリモートに存在しないため、1つ下の層と比較します。", "branch", branch)
+	}
+	return cfg.BaseBranch, nil
+}
+
+// stackNotice は、スタックの親ブランチとの差分のみをレビューしている場合の注記を返します。
+func stackNotice(trunk, parent string) string {
+	if parent == "" || parent == trunk {
+		return ""
+	}
+	return fmt.Sprintf("📚 **スタックされたブランチ:** 親ブランチ `%s` との差分のみをレビューしています (`%s` までの下位の層は含みません)。", parent, trunk)
+}
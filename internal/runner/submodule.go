@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/gitclient"
+)
+
+// submoduleLister は差分に含まれるサブモジュールの参照先の変更を取得できる GitService です。
+type submoduleLister interface {
+	SubmoduleChanges(ctx context.Context, baseBranch, featureBranch string) ([]gitclient.SubmoduleChange, error)
+}
+
+// submoduleNote は、サブモジュールの参照先の変更に含まれるコミットの件名を AI に伝えるプロンプトの前置きを返します。
+// 差分の 'Subproject commit' の行だけでは変更の内容が分からないため、参照先の変更の意図を判断する材料にします。
+func submoduleNote(changes []gitclient.SubmoduleChange) string {
+	if len(changes) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## 📦 サブモジュールの変更 (ツールによる自動取得)\n\n")
+	sb.WriteString("差分の 'Subproject commit' はサブモジュールの参照先のコミットの変更です。参照先の変更に含まれるコミットは次のとおりです。\n\n")
+	for _, c := range changes {
+		switch {
+		case c.NewCommit == "":
+			fmt.Fprintf(&sb, "- `%s`: サブモジュールを削除\n", c.Path)
+			continue
+		case c.OldCommit == "":
+			fmt.Fprintf(&sb, "- `%s`: サブモジュールを追加 (%s)\n", c.Path, shortCommit(c.NewCommit))
+		default:
+			fmt.Fprintf(&sb, "- `%s`: %s..%s\n", c.Path, shortCommit(c.OldCommit), shortCommit(c.NewCommit))
+		}
+		if c.Err != nil {
+			sb.WriteString("  - (サブモジュールの履歴を取得できませんでした)\n")
+			continue
+		}
+		for _, subject := range c.Commits {
+			fmt.Fprintf(&sb, "  - %s\n", subject)
+		}
+		if c.Truncated {
+			sb.WriteString("  - (ほか、以前のコミットは省略)\n")
+		}
+	}
+	sb.WriteString("\n---\n\n")
+	return sb.String()
+}
+
+// shortCommit はコミットの SHA の先頭7文字を返します。
+func shortCommit(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,74 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"git-gemini-reviewer-go/internal/config"
+	"git-gemini-reviewer-go/internal/diffguard"
+	"git-gemini-reviewer-go/internal/streamai"
+	"git-gemini-reviewer-go/internal/twopass"
+	"git-gemini-reviewer-go/internal/verdict"
+)
+
+// reviewTwoPass は、2段階でレビューします (--two-pass)。
+// 1回目で変更全体の概要とファイルのリスクの順位を求め、2回目でリスクの高い上位 cfg.DeepDiveFiles 件のファイルのみを詳しくレビューし、
+// 両方を1つのレポートにまとめます。ファイル数が cfg.DeepDiveFiles 以下の場合は、1回目を省略して通常どおりレビューします。
+// 1回目が失敗した場合や応答から順位を読み取れない場合は、パスと変更行数から推定したリスクの順位で2回目を行います。
+func (r *ReviewRunner) reviewTwoPass(ctx context.Context, cfg config.ReviewConfig, codeDiff string, promptNote string) (string, error) {
+	if cfg.InlineFindings {
+		return "", fmt.Errorf("2段階のレビューと構造化された指摘は同時に指定できません")
+	}
+	if cfg.SplitModules {
+		return "", fmt.Errorf("2段階のレビューとモジュールごとの分割は同時に指定できません")
+	}
+	paths := twopass.Paths(codeDiff)
+	if len(paths) <= cfg.DeepDiveFiles {
+		slog.Info("ファイル数が詳しくレビューする件数以下のため、2段階のレビューを省略してすべてのファイルをレビューします。", "files", len(paths), "deep_dive_files", cfg.DeepDiveFiles)
+		return r.reviewDiff(ctx, cfg, codeDiff, promptNote)
+	}
+
+	slog.Info("2段階のレビューの1回目として、変更の概要とファイルのリスクを評価します。", "files", len(paths))
+	overviewCfg := cfg
+	// アーカイブが2回目で上書きされないよう、1回目はサブディレクトリに保存します
+	overviewCfg.ReviewID = cfg.ReviewID + "/two-pass/overview"
+	// 1回目の応答は2回目の材料のため、逐次の書き出しは2回目の結果のみとする
+	overview, err := r.ask(streamai.WithWriter(ctx, nil), overviewCfg, r.personaPrompt+promptNote+twopass.OverviewPrompt(codeDiff, cfg.DeepDiveFiles))
+	selected := twopass.Ranking(overview, paths, cfg.DeepDiveFiles)
+	if err != nil {
+		r.issues.Degrade("ai.two_pass", fmt.Errorf("2段階のレビューの1回目に失敗しました。推定したリスクの順位でファイルを選びます: %w", err))
+	} else if len(selected) == 0 {
+		slog.Warn("1回目の応答からリスクの高いファイルを読み取れませんでした。推定したリスクの順位でファイルを選びます。")
+	}
+	// 選ばれたファイルが足りない場合は、推定したリスクの順位で補います
+	for _, p := range diffguard.RiskOrder(codeDiff) {
+		if len(selected) >= cfg.DeepDiveFiles {
+			break
+		}
+		if !slices.Contains(selected, p) {
+			selected = append(selected, p)
+		}
+	}
+	var rest []string
+	for _, p := range paths {
+		if !slices.Contains(selected, p) {
+			rest = append(rest, p)
+		}
+	}
+
+	slog.Info("2段階のレビューの2回目として、リスクの高いファイルを詳しくレビューします。", "files", selected, "skipped", len(rest))
+	deepCfg := cfg
+	deepCfg.ReviewID = cfg.ReviewID + "/two-pass/deep-dive"
+	deepDive, err := r.reviewDiff(ctx, deepCfg, twopass.Select(codeDiff, selected), promptNote+twopass.OverviewNote(overview, selected, rest))
+	if err != nil {
+		return "", err
+	}
+	report := twopass.Report(overview, deepDive, selected, rest)
+	// 判定の解析は最初に現れる判定を採用するため、詳細レビューの判定を冒頭に置きます
+	if v := verdict.Parse(deepDive); v != verdict.Unknown {
+		report = fmt.Sprintf("総合判定: **%s** (詳細レビューの判定)\n\n", v.Label()) + report
+	}
+	return report, nil
+}
@@ -0,0 +1,45 @@
+// Package sampling は、AI の応答の生成を調整するパラメータ (温度・top-p・出力トークン数の上限) を提供します。
+// すべての AI プロバイダで共通の値を使用し、レビューの一貫性と多様性のどちらを優先するかをチームで調整できるようにします。
+package sampling
+
+import "fmt"
+
+// DefaultTemperature は、レビューの一貫性を優先した既定の温度です。gemini-reviewer-core のアダプタと同じ値を使用します。
+const DefaultTemperature = 0.2
+
+// MaxTemperature は指定できる温度の上限です。Gemini と OpenAI の API の上限に合わせています。
+const MaxTemperature = 2.0
+
+// Params は応答の生成を調整するパラメータです。ゼロ値は温度 0 を表すため、既定値は Default で取得してください。
+type Params struct {
+	// Temperature は温度 (0〜MaxTemperature) です。低いほど同じ差分に対して同じ結果になりやすくなります。
+	Temperature float64
+	// TopP は top-p (nucleus sampling) の値 (0 より大きく 1 以下) です。0 の場合はプロバイダの既定値を使用します。
+	TopP float64
+	// MaxOutputTokens は応答の最大トークン数です。0 の場合はプロバイダの既定値を使用します。
+	MaxOutputTokens int
+}
+
+// Default は既定のパラメータを返します。
+func Default() Params {
+	return Params{Temperature: DefaultTemperature}
+}
+
+// IsDefault は、p が既定のパラメータかを判定します。
+func (p Params) IsDefault() bool {
+	return p == Default()
+}
+
+// Validate はパラメータの範囲を検証します。
+func (p Params) Validate() error {
+	if p.Temperature < 0 || p.Temperature > MaxTemperature {
+		return fmt.Errorf("--ai-temperature には0から%gの値を指定してください: %g", MaxTemperature, p.Temperature)
+	}
+	if p.TopP < 0 || p.TopP > 1 {
+		return fmt.Errorf("--ai-top-p には0より大きく1以下の値 (0 はプロバイダの既定値) を指定してください: %g", p.TopP)
+	}
+	if p.MaxOutputTokens < 0 {
+		return fmt.Errorf("--ai-max-output-tokens には0以上の値 (0 はプロバイダの既定値) を指定してください: %d", p.MaxOutputTokens)
+	}
+	return nil
+}
@@ -0,0 +1,224 @@
+// Package sarif は、構造化されたレビュー結果を SARIF 2.1.0 の形式に変換します。
+// GitHub Code Scanning や Azure DevOps など、SARIF を取り込めるサービスに指摘をアップロードするために使用します。
+package sarif
+
+import (
+	"strings"
+
+	"git-gemini-reviewer-go/internal/findings"
+	"git-gemini-reviewer-go/internal/inline"
+)
+
+const (
+	// Version は出力する SARIF のバージョンです。
+	Version = "2.1.0"
+	// SchemaURI は SARIF 2.1.0 の JSON Schema の URI です。
+	SchemaURI = "https://json.schemastore.org/sarif-2.1.0.json"
+	// ToolName は SARIF の tool.driver.name に記録するツール名です。
+	ToolName = "git-gemini-reviewer-go"
+	// InformationURI はツールの説明のページです。
+	InformationURI = "https://github.com/shouni/git-gemini-reviewer-go"
+)
+
+// Log は SARIF のログ (最上位のオブジェクト) です。
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+// Run は1回のレビューの実行です。
+type Run struct {
+	Tool    Tool     `json:"tool"`
+	Results []Result `json:"results"`
+	// AutomationDetails は、同じレビューの結果を再アップロードした際に識別するための実行の識別子です。
+	AutomationDetails *AutomationDetails `json:"automationDetails,omitempty"`
+}
+
+// AutomationDetails は実行の識別子です。
+type AutomationDetails struct {
+	ID string `json:"id"`
+}
+
+// Tool は指摘を出力したツールです。
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+// Driver はツールの本体と、指摘のルールの一覧です。
+type Driver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri"`
+	Rules          []Rule `json:"rules"`
+}
+
+// Rule は指摘のルールです。指摘カテゴリを1つのルールとして扱います。
+type Rule struct {
+	ID                   string         `json:"id"`
+	Name                 string         `json:"name"`
+	ShortDescription     Message        `json:"shortDescription"`
+	DefaultConfiguration Configuration  `json:"defaultConfiguration"`
+	Properties           RuleProperties `json:"properties"`
+}
+
+// Configuration はルールの既定の重大度です。
+type Configuration struct {
+	Level string `json:"level"`
+}
+
+// RuleProperties はルールの付加情報です。GitHub Code Scanning はタグを絞り込みに使用します。
+type RuleProperties struct {
+	Tags []string `json:"tags"`
+}
+
+// Message は SARIF のメッセージです。
+type Message struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+// Result は1件の指摘です。
+type Result struct {
+	RuleID     string           `json:"ruleId"`
+	RuleIndex  int              `json:"ruleIndex"`
+	Level      string           `json:"level"`
+	Message    Message          `json:"message"`
+	Locations  []Location       `json:"locations"`
+	Properties ResultProperties `json:"properties"`
+	// Suppressions は、ai-review:ignore コメントで抑制された指摘に付与します。
+	Suppressions []Suppression `json:"suppressions,omitempty"`
+}
+
+// ResultProperties は指摘の付加情報です。
+type ResultProperties struct {
+	Severity inline.Severity `json:"severity"`
+	// SecuritySeverity は GitHub Code Scanning がセキュリティの指摘の重大度として表示する値 (0.0〜10.0) です。
+	SecuritySeverity string `json:"security-severity,omitempty"`
+}
+
+// Suppression は指摘の抑制です。
+type Suppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification"`
+}
+
+// Location は指摘の対象の位置です。
+type Location struct {
+	PhysicalLocation PhysicalLocation `json:"physicalLocation"`
+}
+
+// PhysicalLocation はファイルと行の範囲です。
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+	Region           Region           `json:"region"`
+}
+
+// ArtifactLocation はリポジトリのルートからのファイルのパスです。
+type ArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+// Region は行の範囲です。
+type Region struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+// Level は重大度を SARIF の level に対応付けます。
+// critical は error、major は warning、minor は note とします。
+func Level(s inline.Severity) string {
+	switch s {
+	case inline.Critical:
+		return "error"
+	case inline.Major:
+		return "warning"
+	}
+	return "note"
+}
+
+// securitySeverity は、セキュリティの指摘の重大度を GitHub Code Scanning の security-severity に対応付けます。
+// GitHub は 9.0 以上を critical、7.0 以上を high、4.0 以上を medium として表示します。
+func securitySeverity(s inline.Severity) string {
+	switch s {
+	case inline.Critical:
+		return "9.0"
+	case inline.Major:
+		return "7.0"
+	}
+	return "4.0"
+}
+
+// Convert は構造化されたレビュー結果を SARIF のログに変換します。
+// 抑制された指摘も、抑制の理由とともに結果に含めます。reviewID は automationDetails.id に記録します。
+func Convert(r inline.Review, reviewID string) Log {
+	categories := findings.Categories()
+	index := make(map[findings.Category]int, len(categories))
+	rules := make([]Rule, 0, len(categories))
+	for i, c := range categories {
+		index[c] = i
+		rules = append(rules, Rule{
+			ID:                   ruleID(c),
+			Name:                 string(c),
+			ShortDescription:     Message{Text: c.Label()},
+			DefaultConfiguration: Configuration{Level: "warning"},
+			Properties:           RuleProperties{Tags: ruleTags(c)},
+		})
+	}
+
+	results := make([]Result, 0, len(r.Findings)+len(r.Suppressed))
+	for _, f := range r.Findings {
+		results = append(results, result(f, index[f.Category]))
+	}
+	for _, f := range r.Suppressed {
+		res := result(f, index[f.Category])
+		res.Suppressions = []Suppression{{Kind: "inSource", Justification: f.SuppressedBy}}
+		results = append(results, res)
+	}
+
+	run := Run{
+		Tool:    Tool{Driver: Driver{Name: ToolName, InformationURI: InformationURI, Rules: rules}},
+		Results: results,
+	}
+	if reviewID != "" {
+		run.AutomationDetails = &AutomationDetails{ID: ToolName + "/" + reviewID}
+	}
+	return Log{Schema: SchemaURI, Version: Version, Runs: []Run{run}}
+}
+
+// result は1件の指摘を SARIF の結果に変換します。
+func result(f inline.Finding, ruleIndex int) Result {
+	message := strings.TrimSpace(f.Message)
+	markdown := message
+	if s := strings.TrimSpace(f.Suggestion); s != "" {
+		markdown += "\n\n**修正案**: " + s
+	}
+	res := Result{
+		RuleID:    ruleID(f.Category),
+		RuleIndex: ruleIndex,
+		Level:     Level(f.Severity),
+		Message:   Message{Text: message, Markdown: markdown},
+		Locations: []Location{{PhysicalLocation: PhysicalLocation{
+			ArtifactLocation: ArtifactLocation{URI: f.File, URIBaseID: "%SRCROOT%"},
+			Region:           Region{StartLine: f.Line, EndLine: f.EndLine},
+		}}},
+		Properties: ResultProperties{Severity: f.Severity},
+	}
+	if f.Category == findings.Security {
+		res.Properties.SecuritySeverity = securitySeverity(f.Severity)
+	}
+	return res
+}
+
+// ruleID は指摘カテゴリのルールIDを返します。
+func ruleID(c findings.Category) string {
+	return "ai-review/" + string(c)
+}
+
+// ruleTags はルールのタグを返します。セキュリティの指摘は Code Scanning でセキュリティの警告として扱われるよう 'security' を付与します。
+func ruleTags(c findings.Category) []string {
+	if c == findings.Security {
+		return []string{"ai-review", "security"}
+	}
+	return []string{"ai-review", string(c)}
+}
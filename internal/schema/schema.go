@@ -0,0 +1,49 @@
+// Package schema は、レビュー結果と指摘の JSON スキーマのバージョンを定義します。
+// コールバック・フック・配信レポート・監査アーカイブ・レビュー履歴の JSON には schema_version を含め、
+// 下流の利用者が将来の形式の変更を検知できるようにします。
+package schema
+
+import (
+	_ "embed"
+	"fmt"
+)
+
+// Version はレビュー結果と指摘の JSON スキーマの現在のバージョンです。
+// フィールドの削除や意味の変更など、互換性のない変更を行う場合に1つ上げ、history の移行処理を追加します。
+//
+//   - 1: schema_version を含まない初期の形式です。レビュー履歴には指摘カテゴリの件数 (findings) が含まれない場合があります。
+//   - 2: schema_version を追加し、レビュー履歴の findings を必須としました。
+const Version = 2
+
+//go:embed review-result.schema.json
+var reviewResultSchema []byte
+
+//go:embed review-findings.schema.json
+var reviewFindingsSchema []byte
+
+// ReviewResult は、レビュー結果 (コールバックのペイロード、レビュー履歴のレビュー) の JSON Schema を返します。
+func ReviewResult() []byte {
+	return reviewResultSchema
+}
+
+// ReviewFindings は、generic コマンドの --format json で出力する構造化されたレビュー結果の JSON Schema を返します。
+func ReviewFindings() []byte {
+	return reviewFindingsSchema
+}
+
+// Check は、読み込んだ JSON のスキーマのバージョンをこのバージョンで扱えるかを確認します。
+// schema_version を含まない記録はバージョン1とみなします。
+func Check(version int) error {
+	if version > Version {
+		return fmt.Errorf("スキーマのバージョン %d はこのバージョンで扱えるバージョン (%d) より新しいため読み込めません。ツールを更新してください", version, Version)
+	}
+	return nil
+}
+
+// Normalize は、schema_version を含まない記録のバージョン (0) を1として返します。
+func Normalize(version int) int {
+	if version == 0 {
+		return 1
+	}
+	return version
+}
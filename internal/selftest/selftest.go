@@ -0,0 +1,226 @@
+// Package selftest は、期待される指摘が既知の合成差分 (ゴールデンコーパス) をレビューさせ、
+// 現在のモデルとプロンプトの組み合わせの再現率・適合率を測定します。
+// モデルやプロンプトテンプレートを更新する前後で実行し、レビュー品質の劣化を検知するために利用します。
+package selftest
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/findings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed corpus/*.yaml
+var corpusFS embed.FS
+
+// Expectation はケースに期待する1件の指摘です。
+type Expectation struct {
+	// File は指摘が紐付くべきファイルのパスです。
+	File string `yaml:"file" json:"file"`
+	// Category は指摘が分類されるべきカテゴリです。
+	Category findings.Category `yaml:"category" json:"category"`
+	// Keywords は、カテゴリの分類が揺れた場合でも同じ指摘とみなすための語句です (大文字小文字を区別しません)。
+	Keywords []string `yaml:"keywords" json:"keywords,omitempty"`
+}
+
+// Case はコーパスの1件の合成差分です。
+type Case struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	// Mode はレビューモードです。未指定時は 'detail' を使用します。
+	Mode     string        `yaml:"mode"`
+	Expected []Expectation `yaml:"expected"`
+	Diff     string        `yaml:"diff"`
+}
+
+// Corpus は組み込みのコーパスを名前順に返します。
+func Corpus() ([]Case, error) {
+	entries, err := fs.Glob(corpusFS, "corpus/*.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("セルフテストのコーパスの一覧の取得に失敗しました: %w", err)
+	}
+	cases := make([]Case, 0, len(entries))
+	for _, name := range entries {
+		data, err := corpusFS.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("セルフテストのケースの読み込みに失敗しました (%s): %w", name, err)
+		}
+		var c Case
+		if err := yaml.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("セルフテストのケースの解析に失敗しました (%s): %w", name, err)
+		}
+		if c.Name == "" {
+			c.Name = strings.TrimSuffix(path.Base(name), ".yaml")
+		}
+		if c.Mode == "" {
+			c.Mode = "detail"
+		}
+		for _, e := range c.Expected {
+			if _, err := findings.ParseCategory(string(e.Category)); err != nil {
+				return nil, fmt.Errorf("セルフテストのケース '%s' の期待値が不正です: %w", c.Name, err)
+			}
+		}
+		cases = append(cases, c)
+	}
+	sort.Slice(cases, func(i, j int) bool { return cases[i].Name < cases[j].Name })
+	return cases, nil
+}
+
+// Filter は指定した名前のケースだけを返します。names が空の場合はすべてのケースを返します。
+func Filter(cases []Case, names []string) ([]Case, error) {
+	if len(names) == 0 {
+		return cases, nil
+	}
+	byName := make(map[string]Case, len(cases))
+	for _, c := range cases {
+		byName[c.Name] = c
+	}
+	selected := make([]Case, 0, len(names))
+	for _, name := range names {
+		c, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("不明なセルフテストのケースです: '%s'", name)
+		}
+		selected = append(selected, c)
+	}
+	return selected, nil
+}
+
+// ReviewFunc は1件のケースの差分をレビューし、レビュー結果の Markdown を返します。
+type ReviewFunc func(ctx context.Context, c Case) (string, error)
+
+// CaseResult は1件のケースの採点結果です。
+type CaseResult struct {
+	Name string `json:"name"`
+	// Expected は期待した指摘の件数です。
+	Expected int `json:"expected"`
+	// Found はレビュー結果から抽出した指摘の件数です。
+	Found int `json:"found"`
+	// Matched は期待した指摘のうち検出された件数です。
+	Matched int `json:"matched"`
+	// FalsePositives は、どの期待値にも一致しなかった指摘の件数です。
+	FalsePositives int `json:"false_positives"`
+	// Missed は検出されなかった期待値です。
+	Missed []Expectation `json:"missed,omitempty"`
+	// Error はレビューに失敗した場合のエラーです。
+	Error string `json:"error,omitempty"`
+}
+
+// Report はコーパス全体の採点結果です。
+type Report struct {
+	Model     string       `json:"model"`
+	Cases     []CaseResult `json:"cases"`
+	Recall    float64      `json:"recall"`
+	Precision float64      `json:"precision"`
+	// Failed はレビューに失敗したケースの件数です。失敗したケースの期待値は未検出として扱います。
+	Failed int `json:"failed"`
+}
+
+// Run は各ケースを順にレビューさせ、採点結果を返します。
+// 個々のケースのレビューの失敗は採点結果に記録し、残りのケースの実行を継続します。
+func Run(ctx context.Context, model string, cases []Case, review ReviewFunc) Report {
+	report := Report{Model: model}
+	var expected, matched, found, truePositives int
+	for _, c := range cases {
+		result, err := review(ctx, c)
+		var cr CaseResult
+		if err != nil {
+			cr = CaseResult{Name: c.Name, Expected: len(c.Expected), Missed: c.Expected, Error: err.Error()}
+			report.Failed++
+		} else {
+			cr = Score(c, result)
+		}
+		report.Cases = append(report.Cases, cr)
+
+		expected += cr.Expected
+		matched += cr.Matched
+		found += cr.Found
+		truePositives += cr.Found - cr.FalsePositives
+	}
+	report.Recall = ratio(matched, expected)
+	report.Precision = ratio(truePositives, found)
+	return report
+}
+
+// Score はレビュー結果から指摘を抽出し、ケースの期待値と照合します。
+// 同じ期待値に一致する指摘が複数ある場合、いずれも誤検出とはみなしません。
+func Score(c Case, result string) CaseResult {
+	found := findings.Extract(result)
+	cr := CaseResult{Name: c.Name, Expected: len(c.Expected), Found: len(found)}
+
+	hit := make([]bool, len(c.Expected))
+	for _, f := range found {
+		ok := false
+		for i, e := range c.Expected {
+			if matches(e, f) {
+				hit[i], ok = true, true
+			}
+		}
+		if !ok {
+			cr.FalsePositives++
+		}
+	}
+	for i, e := range c.Expected {
+		if hit[i] {
+			cr.Matched++
+		} else {
+			cr.Missed = append(cr.Missed, e)
+		}
+	}
+	return cr
+}
+
+// matches は指摘が期待値と同じファイルへの、同じカテゴリまたはキーワードを含む指摘であるかを判定します。
+func matches(e Expectation, f findings.Finding) bool {
+	if f.File != e.File && !strings.HasSuffix(f.File, "/"+e.File) {
+		return false
+	}
+	if f.Category == e.Category {
+		return true
+	}
+	text := strings.ToLower(f.Text)
+	for _, kw := range e.Keywords {
+		if strings.Contains(text, strings.ToLower(kw)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ratio は分母が0の場合を1として割合を返します (期待値も指摘もない場合は満点とします)。
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 1
+	}
+	return float64(n) / float64(d)
+}
+
+// Markdown は採点結果を Markdown の表に整形します。
+func (r Report) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# 🧪 レビュアーのセルフテスト (%s)\n\n", r.Model)
+	fmt.Fprintf(&sb, "- 再現率 (recall): **%.1f%%**\n", r.Recall*100)
+	fmt.Fprintf(&sb, "- 適合率 (precision): **%.1f%%**\n", r.Precision*100)
+	if r.Failed > 0 {
+		fmt.Fprintf(&sb, "- レビューに失敗したケース: %d 件\n", r.Failed)
+	}
+	sb.WriteString("\n| ケース | 期待 | 検出 | 指摘 | 誤検出 | 未検出 |\n|---|---:|---:|---:|---:|---|\n")
+	for _, c := range r.Cases {
+		missed := make([]string, 0, len(c.Missed))
+		for _, e := range c.Missed {
+			missed = append(missed, fmt.Sprintf("`%s` (%s)", e.File, e.Category))
+		}
+		if c.Error != "" {
+			missed = append(missed, "⚠️ "+c.Error)
+		}
+		fmt.Fprintf(&sb, "| %s | %d | %d | %d | %d | %s |\n", c.Name, c.Expected, c.Matched, c.Found, c.FalsePositives, strings.Join(missed, "<br>"))
+	}
+	return sb.String()
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+)
+
+// GitHubWebhookPath は GitHub の Webhook の Payload URL に設定するパスです。
+const GitHubWebhookPath = "/webhooks/github"
+
+// GitHub の Webhook のリポジトリのクローンに使用するプロトコルです。
+const (
+	CloneSSH   = "ssh"
+	CloneHTTPS = "https"
+)
+
+// githubActions は、レビューを実行する pull_request イベントのアクションです。
+var githubActions = []string{"opened", "synchronize", "reopened"}
+
+// errInvalidGitHubSignature は Webhook の署名の検証に失敗したことを表します。
+var errInvalidGitHubSignature = errors.New("GitHub の Webhook の署名が不正です")
+
+// GitHubPullRequest は、Webhook から依頼されたレビューの結果を投稿するプルリクエストです。
+type GitHubPullRequest struct {
+	// Repo は 'owner/name' 形式のリポジトリです。
+	Repo    string `json:"repo"`
+	Number  int    `json:"number"`
+	HeadSHA string `json:"head_sha"`
+}
+
+// pullRequestEvent は pull_request イベントのペイロードのうち、使用する項目です。
+type pullRequestEvent struct {
+	Action      string `json:"action"`
+	Number      int    `json:"number"`
+	PullRequest struct {
+		Head struct {
+			Ref  string `json:"ref"`
+			SHA  string `json:"sha"`
+			Repo struct {
+				FullName string `json:"full_name"`
+			} `json:"repo"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+		CloneURL string `json:"clone_url"`
+		SSHURL   string `json:"ssh_url"`
+	} `json:"repository"`
+}
+
+// WithGitHubWebhook は、GitHub の pull_request イベントを受け付ける Webhook のエンドポイントを有効にします。
+// secret は Webhook に設定したシークレット、cloneProtocol はリポジトリのクローンに使用するプロトコル (CloneSSH または CloneHTTPS) です。
+func WithGitHubWebhook(secret, cloneProtocol string) Option {
+	return func(h *Handler) {
+		h.githubSecret = secret
+		h.githubClone = cloneProtocol
+	}
+}
+
+// handleGitHubWebhook は、プルリクエストの作成・更新のイベントを受け付け、そのプルリクエストのレビューをジョブとして登録します。
+// GitHub は10秒以内の応答を求めるため、レビューはバックグラウンドで実行します。
+func (h *Handler) handleGitHubWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "リクエストの読み込みに失敗しました")
+		return
+	}
+	if err := verifyGitHubSignature(h.githubSecret, body, r.Header.Get("X-Hub-Signature-256")); err != nil {
+		slog.Warn("GitHub の Webhook を拒否しました。", "delivery", r.Header.Get("X-GitHub-Delivery"), "error", err)
+		writeError(w, http.StatusUnauthorized, "署名が不正です")
+		return
+	}
+
+	event := r.Header.Get("X-GitHub-Event")
+	if event != "pull_request" {
+		// ping など、レビューの対象でないイベントは受信のみ応答します
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	var e pullRequestEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("イベントの JSON が不正です: %v", err))
+		return
+	}
+	if !slices.Contains(githubActions, e.Action) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	// フォークからのプルリクエストのブランチは、レビュー対象のリポジトリには存在しません
+	if e.PullRequest.Head.Repo.FullName != e.Repository.FullName {
+		slog.Info("フォークからのプルリクエストのため、レビューをスキップします。", "repo", e.Repository.FullName, "pr", e.Number, "head", e.PullRequest.Head.Repo.FullName)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	req := Request{
+		RepoURL:       e.Repository.SSHURL,
+		FeatureBranch: e.PullRequest.Head.Ref,
+		BaseBranch:    e.PullRequest.Base.Ref,
+		GitHub:        &GitHubPullRequest{Repo: e.Repository.FullName, Number: e.Number, HeadSHA: e.PullRequest.Head.SHA},
+	}
+	if h.githubClone == CloneHTTPS {
+		req.RepoURL = e.Repository.CloneURL
+	}
+	if err := h.validate(req); err != nil {
+		slog.Warn("GitHub の Webhook から依頼されたレビューを拒否しました。", "repo", e.Repository.FullName, "pr", e.Number, "error", err)
+		writeError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	job, err := h.enqueue(req)
+	if err != nil {
+		writeQueueFull(w, err)
+		return
+	}
+	slog.Info("GitHub のプルリクエストのレビューを受け付けました。", "job_id", job.ID, "repo", e.Repository.FullName, "pr", e.Number, "action", e.Action, "delivery", r.Header.Get("X-GitHub-Delivery"))
+	writeJSON(w, http.StatusAccepted, map[string]string{"job_id": job.ID, "status": string(job.Status)})
+}
+
+// verifyGitHubSignature は、X-Hub-Signature-256 ヘッダ ('sha256=<hex>') の署名を検証します。
+func verifyGitHubSignature(secret string, body []byte, signature string) error {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return errInvalidGitHubSignature
+	}
+	return nil
+}
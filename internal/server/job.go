@@ -0,0 +1,123 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status はジョブの状態です。
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusNoDiff    Status = "no-diff"
+	StatusFailed    Status = "failed"
+)
+
+// Finished は、ジョブが終了した状態かを返します。
+func (s Status) Finished() bool {
+	return s == StatusCompleted || s == StatusNoDiff || s == StatusFailed
+}
+
+// Job は1件のレビューの依頼と、その実行状況です。
+type Job struct {
+	ID         string     `json:"id"`
+	Status     Status     `json:"status"`
+	Request    Request    `json:"request"`
+	ReviewID   string     `json:"review_id,omitempty"`
+	Review     string     `json:"review,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// defaultMaxFinished は、メモリに保持する終了済みのジョブの件数の既定値です。
+const defaultMaxFinished = 1000
+
+// jobStore はジョブをメモリに保持します。プロセスを再起動すると失われます。
+// 終了済みのジョブは古いものから破棄し、保持する件数を maxFinished に制限します。
+type jobStore struct {
+	mu          sync.Mutex
+	jobs        map[string]*Job
+	finished    []string
+	maxFinished int
+}
+
+func newJobStore(maxFinished int) *jobStore {
+	if maxFinished <= 0 {
+		maxFinished = defaultMaxFinished
+	}
+	return &jobStore{jobs: make(map[string]*Job), maxFinished: maxFinished}
+}
+
+// add は新しいジョブを登録します。
+func (s *jobStore) add(req Request, now time.Time) Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job := &Job{ID: newJobID(), Status: StatusQueued, Request: req, CreatedAt: now}
+	s.jobs[job.ID] = job
+	return *job
+}
+
+// remove はジョブを削除します。キューに登録できなかったジョブを取り消すために使用します。
+func (s *jobStore) remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+}
+
+// get はジョブの写しを返します。
+func (s *jobStore) get(id string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// list はジョブの写しを新しい順に返します。
+func (s *jobStore) list() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].CreatedAt.After(jobs[j].CreatedAt) })
+	return jobs
+}
+
+// update はジョブを更新します。終了した場合は、保持する件数を超えた古い終了済みのジョブを破棄します。
+func (s *jobStore) update(id string, fn func(*Job)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.jobs[id]
+	if !ok {
+		return
+	}
+	wasFinished := job.Status.Finished()
+	fn(job)
+	if wasFinished || !job.Status.Finished() {
+		return
+	}
+	s.finished = append(s.finished, id)
+	for len(s.finished) > s.maxFinished {
+		delete(s.jobs, s.finished[0])
+		s.finished = s.finished[1:]
+	}
+}
+
+// newJobID はジョブIDを生成します。
+func newJobID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
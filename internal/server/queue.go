@@ -0,0 +1,104 @@
+package server
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrQueueFull は、待機中のジョブが上限に達しているため依頼を受け付けられないことを表します。
+var ErrQueueFull = errors.New("待機中のレビューが上限に達しています")
+
+// task はキューで待機する1件のジョブです。
+type task struct {
+	id  string
+	req Request
+	// key は同時に実行できないジョブを識別するキー (クローン先のローカルパス) です。
+	key string
+}
+
+// queue は、ジョブを受け付けた順に固定数のワーカーで実行します。
+// 同じキーのジョブは同時に実行せず、先に実行中のジョブの終了を待ちます。待機中の他のキーのジョブは追い越して実行します。
+type queue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	pending  []task
+	busy     map[string]bool
+	running  int
+	capacity int
+	closed   bool
+}
+
+func newQueue(capacity int) *queue {
+	q := &queue{busy: make(map[string]bool), capacity: capacity}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push はジョブを待機させます。待機中のジョブが上限に達している場合は ErrQueueFull を返します。
+func (q *queue) push(t task) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.capacity > 0 && len(q.pending) >= q.capacity {
+		return ErrQueueFull
+	}
+	q.pending = append(q.pending, t)
+	q.cond.Signal()
+	return nil
+}
+
+// next は、実行中のジョブとキーが重ならない最も古いジョブを取り出します。キューが閉じられた場合は false を返します。
+func (q *queue) next() (task, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		if q.closed {
+			return task{}, false
+		}
+		for i, t := range q.pending {
+			if q.busy[t.key] {
+				continue
+			}
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			q.busy[t.key] = true
+			q.running++
+			return t, true
+		}
+		q.cond.Wait()
+	}
+}
+
+// done は、ジョブの終了を記録し、同じキーで待機中のジョブを実行できるようにします。
+func (q *queue) done(t task) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.busy, t.key)
+	q.running--
+	q.cond.Broadcast()
+}
+
+// close は待機中のワーカーを終了させます。待機中のジョブは実行しません。
+func (q *queue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+// stats は待機中と実行中のジョブの件数を返します。
+func (q *queue) stats() (pending, running int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.pending), q.running
+}
+
+// work は、キューが閉じられるまでジョブを実行します。
+func (h *Handler) work() {
+	for {
+		t, ok := h.queue.next()
+		if !ok {
+			return
+		}
+		h.process(t.id, t.req)
+		h.queue.done(t)
+	}
+}
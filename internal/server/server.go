@@ -0,0 +1,375 @@
+// Package server は、レビューを非同期に実行する HTTP API を提供します。
+// POST /review で依頼を受け付けてジョブIDを返し、GET /jobs/{id} で実行状況と結果を返します。
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"git-gemini-reviewer-go/internal/feedback"
+)
+
+const (
+	// ReviewPath はレビューを依頼するエンドポイントのパスです。
+	ReviewPath = "/review"
+	// JobsPath はジョブの実行状況を返すエンドポイントのパスの接頭辞です。末尾の '/' を除いたパスはジョブの一覧を返します。
+	JobsPath = "/jobs/"
+
+	// defaultRunTimeout は1件のレビューに許容する最大時間です。
+	defaultRunTimeout = 30 * time.Minute
+	// defaultWorkers は同時に実行するレビューの件数の既定値です。
+	defaultWorkers = 1
+	// defaultQueueSize は待機できるジョブの件数の既定値です。
+	defaultQueueSize = 100
+	// retryAfterSeconds は、待機中のジョブが上限に達している場合に再送までの待ち時間として返す秒数です。
+	retryAfterSeconds = "60"
+	// maxRequestBytes はレビューの依頼のボディの最大サイズです。
+	maxRequestBytes = 1 << 20
+	// maxWebhookBytes は Webhook のイベントのボディの最大サイズです。GitHub のペイロードの上限 (25MB) に合わせます。
+	maxWebhookBytes = 25 << 20
+)
+
+// Request はレビューの依頼です。
+type Request struct {
+	RepoURL       string `json:"repo_url"`
+	FeatureBranch string `json:"feature_branch"`
+	BaseBranch    string `json:"base_branch,omitempty"`
+	Mode          string `json:"mode,omitempty"`
+	// GitHub は、GitHub の Webhook から依頼された場合に結果を投稿するプルリクエストです。API の依頼では指定できません。
+	GitHub *GitHubPullRequest `json:"github,omitempty"`
+}
+
+// Result はレビューの実行結果です。差分がない場合 Markdown は空文字列です。
+type Result struct {
+	ReviewID string
+	Markdown string
+}
+
+// RunFunc はレビューを実行する関数です。
+type RunFunc func(ctx context.Context, req Request) (Result, error)
+
+// Handler はレビューの HTTP API のハンドラーです。
+type Handler struct {
+	run          RunFunc
+	jobs         *jobStore
+	queue        *queue
+	workers      int
+	queueSize    int
+	lockKey      func(Request) string
+	baseCtx      context.Context
+	runTimeout   time.Duration
+	allowedRepos []string
+	token        string
+	githubSecret string
+	githubClone  string
+	feedback     *feedback.Store
+	feedbackKey  string
+	now          func() time.Time
+}
+
+// Option は Handler の初期化オプションを設定するための関数です。
+type Option func(*Handler)
+
+// WithAllowedRepoPrefixes は、レビューを受け付けるリポジトリURLの接頭辞を制限します。
+// 未指定の場合はすべてのリポジトリを受け付けます。
+func WithAllowedRepoPrefixes(prefixes ...string) Option {
+	return func(h *Handler) {
+		h.allowedRepos = append(h.allowedRepos, prefixes...)
+	}
+}
+
+// WithBaseContext は、バックグラウンドで実行するレビューの親コンテキストを設定します。
+// サーバー停止時に実行中のレビューを中断するために使用します。
+func WithBaseContext(ctx context.Context) Option {
+	return func(h *Handler) {
+		h.baseCtx = ctx
+	}
+}
+
+// WithRunTimeout は1件のレビューに許容する最大時間を設定します。
+func WithRunTimeout(d time.Duration) Option {
+	return func(h *Handler) {
+		h.runTimeout = d
+	}
+}
+
+// WithToken は、API の呼び出しに 'Authorization: Bearer <token>' ヘッダを必須にします。
+// 未指定の場合は認証を行いません。
+func WithToken(token string) Option {
+	return func(h *Handler) {
+		h.token = token
+	}
+}
+
+// WithFeedback は、投稿に付与した 👍/👎 リンクを受け付けるエンドポイント (feedback.HandlerPath) を有効にし、投票を store に記録します。
+// secret はリンクの署名の検証に使用します。空の場合はエンドポイントを有効にしません。
+func WithFeedback(store *feedback.Store, secret string) Option {
+	return func(h *Handler) {
+		h.feedback = store
+		h.feedbackKey = secret
+	}
+}
+
+// WithMaxFinishedJobs は、メモリに保持する終了済みのジョブの件数を設定します。
+func WithMaxFinishedJobs(n int) Option {
+	return func(h *Handler) {
+		h.jobs = newJobStore(n)
+	}
+}
+
+// WithWorkers は、同時に実行するレビューの件数を設定します。
+func WithWorkers(n int) Option {
+	return func(h *Handler) {
+		h.workers = n
+	}
+}
+
+// WithQueueSize は、実行を待機できるジョブの件数を設定します。超えた依頼は 503 Service Unavailable で拒否します。
+func WithQueueSize(n int) Option {
+	return func(h *Handler) {
+		h.queueSize = n
+	}
+}
+
+// WithLockKey は、同時に実行できないジョブを識別するキーを返す関数を設定します。
+// 同じローカルパスにクローンするジョブが競合しないよう、クローン先のパスを返す関数を指定してください。
+// 未指定の場合はリポジトリURLをキーとします。
+func WithLockKey(fn func(Request) string) Option {
+	return func(h *Handler) {
+		h.lockKey = fn
+	}
+}
+
+// NewHandler は Handler を生成し、ジョブを実行するワーカーを起動します。
+// ワーカーは WithBaseContext のコンテキストの終了とともに終了します。
+func NewHandler(run RunFunc, opts ...Option) *Handler {
+	h := &Handler{
+		run:        run,
+		jobs:       newJobStore(defaultMaxFinished),
+		workers:    defaultWorkers,
+		queueSize:  defaultQueueSize,
+		lockKey:    func(req Request) string { return req.RepoURL },
+		baseCtx:    context.Background(),
+		runTimeout: defaultRunTimeout,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.queue = newQueue(h.queueSize)
+	context.AfterFunc(h.baseCtx, h.queue.close)
+	for range max(h.workers, 1) {
+		go h.work()
+	}
+	return h
+}
+
+// Register は API のエンドポイントを mux に登録します。
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("POST "+ReviewPath, h.authorized(h.handleReview))
+	mux.HandleFunc("GET "+strings.TrimSuffix(JobsPath, "/"), h.authorized(h.handleJobs))
+	mux.HandleFunc("GET "+JobsPath+"{id}", h.authorized(h.handleJob))
+	// Webhook は署名で検証するため、API のトークンは求めません
+	if h.githubSecret != "" {
+		mux.HandleFunc("POST "+GitHubWebhookPath, h.handleGitHubWebhook)
+	}
+	// 👍/👎 リンクはチャットやコメントから直接開かれるため、API のトークンではなくリンクの署名で検証します
+	if h.feedback != nil && h.feedbackKey != "" {
+		mux.Handle(feedback.HandlerPath, feedback.NewHandler(h.feedback, h.feedbackKey))
+	}
+}
+
+// handleReview はレビューの依頼を受け付け、ジョブIDと実行状況のURLを返します。
+// レビューはバックグラウンドで実行します。
+func (h *Handler) handleReview(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("依頼の JSON が不正です: %v", err))
+		return
+	}
+	req.RepoURL, req.FeatureBranch = strings.TrimSpace(req.RepoURL), strings.TrimSpace(req.FeatureBranch)
+	req.GitHub = nil
+	if err := h.validate(req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	job, err := h.enqueue(req)
+	if err != nil {
+		writeQueueFull(w, err)
+		return
+	}
+	slog.Info("レビューの依頼を受け付けました。", "job_id", job.ID, "repo", req.RepoURL, "branch", req.FeatureBranch)
+
+	w.Header().Set("Location", JobsPath+job.ID)
+	writeJSON(w, http.StatusAccepted, map[string]string{
+		"job_id":     job.ID,
+		"status":     string(job.Status),
+		"status_url": JobsPath + job.ID,
+	})
+}
+
+// handleJob はジョブの実行状況と、終了している場合は結果を返します。
+func (h *Handler) handleJob(w http.ResponseWriter, r *http.Request) {
+	job, ok := h.jobs.get(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "ジョブが見つかりません")
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+// enqueue はジョブを登録し、ワーカーによる実行を待機させます。待機中のジョブが上限に達している場合は ErrQueueFull を返します。
+func (h *Handler) enqueue(req Request) (Job, error) {
+	job := h.jobs.add(req, h.now())
+	if err := h.queue.push(task{id: job.ID, req: req, key: h.lockKey(req)}); err != nil {
+		h.jobs.remove(job.ID)
+		return Job{}, err
+	}
+	return job, nil
+}
+
+// jobsResponse はジョブの一覧の応答です。
+type jobsResponse struct {
+	Workers int   `json:"workers"`
+	Pending int   `json:"pending"`
+	Running int   `json:"running"`
+	Jobs    []Job `json:"jobs"`
+}
+
+// handleJobs は、ワーカーの稼働状況とジョブの一覧を新しい順に返します。一覧にはレビュー結果の本文を含めません。
+// '?status=running' のように状態で絞り込めます。
+func (h *Handler) handleJobs(w http.ResponseWriter, r *http.Request) {
+	status := Status(r.URL.Query().Get("status"))
+	pending, running := h.queue.stats()
+	resp := jobsResponse{Workers: max(h.workers, 1), Pending: pending, Running: running, Jobs: []Job{}}
+	for _, job := range h.jobs.list() {
+		if status != "" && job.Status != status {
+			continue
+		}
+		job.Review = ""
+		resp.Jobs = append(resp.Jobs, job)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// process はレビューを実行し、結果をジョブに記録します。
+func (h *Handler) process(id string, req Request) {
+	ctx, cancel := context.WithTimeout(h.baseCtx, h.runTimeout)
+	defer cancel()
+
+	started := h.now()
+	h.jobs.update(id, func(job *Job) {
+		job.Status = StatusRunning
+		job.StartedAt = &started
+	})
+	result, err := h.run(ctx, req)
+	finished := h.now()
+	h.jobs.update(id, func(job *Job) {
+		job.FinishedAt = &finished
+		job.ReviewID = result.ReviewID
+		// 結果の投稿に失敗した場合も、レビュー結果は取得できるようにする
+		job.Review = result.Markdown
+		switch {
+		case err != nil:
+			job.Status = StatusFailed
+			job.Error = err.Error()
+			if errors.Is(err, context.DeadlineExceeded) {
+				job.Error = fmt.Sprintf("レビューが制限時間 (%s) 内に完了しませんでした", h.runTimeout)
+			}
+		case result.Markdown == "":
+			job.Status = StatusNoDiff
+		default:
+			job.Status = StatusCompleted
+		}
+	})
+	if err != nil {
+		slog.Error("依頼されたレビューに失敗しました。", "job_id", id, "repo", req.RepoURL, "branch", req.FeatureBranch, "error", err)
+		return
+	}
+	slog.Info("依頼されたレビューが完了しました。", "job_id", id, "review_id", result.ReviewID, "elapsed", finished.Sub(started).Round(time.Second))
+}
+
+// validate は依頼の必須項目と、リポジトリURLが許可された接頭辞に一致するかを検証します。
+func (h *Handler) validate(req Request) error {
+	if req.RepoURL == "" || req.FeatureBranch == "" {
+		return fmt.Errorf("repo_url と feature_branch を指定してください")
+	}
+	if !isRemoteRepoURL(req.RepoURL) {
+		return fmt.Errorf("repo_url にはリモートリポジトリのURL (https://、ssh://、git@host:path、codecommit::) を指定してください: '%s'", req.RepoURL)
+	}
+	if len(h.allowedRepos) == 0 {
+		return nil
+	}
+	for _, prefix := range h.allowedRepos {
+		if strings.HasPrefix(req.RepoURL, prefix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("リポジトリ '%s' はレビューが許可されていません", req.RepoURL)
+}
+
+// remoteSchemes は、依頼で受け付けるリポジトリURLのスキームです。
+// file:// やローカルパスを受け付けると、サーバー上の任意のディレクトリがレビューされ、その内容が応答に含まれてしまいます。
+var remoteSchemes = []string{"https", "http", "ssh", "codecommit"}
+
+// isRemoteRepoURL は、リポジトリURLがリモートリポジトリを指すかどうかを判定します。
+// スキームのあるURLは remoteSchemes のいずれか、スキームのないURLは user@host:path 形式 (SSH の短縮形) のみを受け付けます。
+func isRemoteRepoURL(repoURL string) bool {
+	if strings.HasPrefix(repoURL, "codecommit::") {
+		return true
+	}
+	if scheme, rest, ok := strings.Cut(repoURL, "://"); ok {
+		return slices.Contains(remoteSchemes, strings.ToLower(scheme)) && rest != "" && !strings.HasPrefix(rest, "/")
+	}
+	userHost, path, ok := strings.Cut(repoURL, ":")
+	if !ok || path == "" || strings.ContainsAny(userHost, "/\\") {
+		return false
+	}
+	user, host, ok := strings.Cut(userHost, "@")
+	return ok && user != "" && host != ""
+}
+
+// authorized は、トークンが設定されている場合に Authorization ヘッダを検証します。
+func (h *Handler) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if h.token != "" {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) != 1 {
+				slog.Warn("API リクエストを拒否しました。", "path", r.URL.Path, "remote", r.RemoteAddr)
+				writeError(w, http.StatusUnauthorized, "認証に失敗しました")
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// writeQueueFull は、待機中のジョブが上限に達しているため依頼を拒否する応答を書き込みます。
+func writeQueueFull(w http.ResponseWriter, err error) {
+	slog.Warn("待機中のレビューが上限に達しているため、依頼を拒否しました。", "error", err)
+	w.Header().Set("Retry-After", retryAfterSeconds)
+	writeError(w, http.StatusServiceUnavailable, err.Error())
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("API の応答の書き込みに失敗しました。", "error", err)
+	}
+}
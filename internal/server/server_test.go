@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTestHandler は、レビューを実行しない RunFunc の Handler を生成します。ワーカーはテストの終了とともに終了します。
+func newTestHandler(t *testing.T, opts ...Option) *Handler {
+	t.Helper()
+	run := func(context.Context, Request) (Result, error) { return Result{}, nil }
+	return NewHandler(run, append([]Option{WithBaseContext(t.Context())}, opts...)...)
+}
+
+func TestAuthorized(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		wantStatus    int
+	}{
+		{"トークン未設定", "", "", http.StatusNoContent},
+		{"一致するトークン", "server-token", "Bearer server-token", http.StatusNoContent},
+		{"ヘッダなし", "server-token", "", http.StatusUnauthorized},
+		{"異なるトークン", "server-token", "Bearer other-token", http.StatusUnauthorized},
+		{"トークンの前方一致", "server-token", "Bearer server-token-x", http.StatusUnauthorized},
+		{"Bearer なし", "server-token", "server-token", http.StatusUnauthorized},
+		{"小文字の bearer", "server-token", "bearer server-token", http.StatusUnauthorized},
+		{"Basic 認証", "server-token", "Basic c2VydmVyLXRva2Vu", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t, WithToken(tt.token))
+			called := false
+			next := func(w http.ResponseWriter, _ *http.Request) {
+				called = true
+				w.WriteHeader(http.StatusNoContent)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/jobs", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			h.authorized(next)(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if wantCalled := tt.wantStatus == http.StatusNoContent; called != wantCalled {
+				t.Errorf("ハンドラーの呼び出し = %v, want %v", called, wantCalled)
+			}
+		})
+	}
+}
+
+func githubSignature(secret, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// testPullRequestEvent はプルリクエストの作成の pull_request イベントのペイロードです。
+const testPullRequestEvent = `{"action":"opened","number":1,"pull_request":{"head":{"ref":"feature","sha":"abc","repo":{"full_name":"owner/repo"}},"base":{"ref":"main"}},"repository":{"full_name":"owner/repo","clone_url":"https://github.com/owner/repo.git","ssh_url":"git@github.com:owner/repo.git"}}`
+
+func TestGitHubWebhookRejectsInvalidSignature(t *testing.T) {
+	const secret = "webhook-secret"
+	body := testPullRequestEvent
+	tests := []struct {
+		name      string
+		signature string
+	}{
+		{"署名なし", ""},
+		{"異なるシークレット", githubSignature("other-secret", body)},
+		{"異なるボディ", githubSignature(secret, body+" ")},
+		{"接頭辞なし", strings.TrimPrefix(githubSignature(secret, body), "sha256=")},
+		{"sha1 の接頭辞", "sha1=" + strings.TrimPrefix(githubSignature(secret, body), "sha256=")},
+		{"大文字の16進数", "sha256=" + strings.ToUpper(strings.TrimPrefix(githubSignature(secret, body), "sha256="))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t, WithGitHubWebhook(secret, CloneHTTPS))
+			mux := http.NewServeMux()
+			h.Register(mux)
+
+			req := httptest.NewRequest(http.MethodPost, GitHubWebhookPath, strings.NewReader(body))
+			req.Header.Set("X-GitHub-Event", "pull_request")
+			if tt.signature != "" {
+				req.Header.Set("X-Hub-Signature-256", tt.signature)
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+			}
+			if jobs := h.jobs.list(); len(jobs) != 0 {
+				t.Errorf("署名が不正な Webhook でジョブが登録されました (%d 件)", len(jobs))
+			}
+		})
+	}
+}
+
+func TestGitHubWebhookAcceptsValidSignature(t *testing.T) {
+	const secret = "webhook-secret"
+	body := testPullRequestEvent
+	h := newTestHandler(t, WithGitHubWebhook(secret, CloneHTTPS))
+	mux := http.NewServeMux()
+	h.Register(mux)
+
+	req := httptest.NewRequest(http.MethodPost, GitHubWebhookPath, strings.NewReader(body))
+	req.Header.Set("X-GitHub-Event", "pull_request")
+	req.Header.Set("X-Hub-Signature-256", githubSignature(secret, body))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d (body: %s)", rec.Code, http.StatusAccepted, rec.Body)
+	}
+	jobs := h.jobs.list()
+	if len(jobs) != 1 {
+		t.Fatalf("ジョブの件数 = %d, want 1", len(jobs))
+	}
+	if got := jobs[0].Request.RepoURL; got != "https://github.com/owner/repo.git" {
+		t.Errorf("RepoURL = %q, want クローン用の HTTPS の URL", got)
+	}
+}
@@ -0,0 +1,87 @@
+package slackapp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"git-gemini-reviewer-go/internal/history"
+)
+
+// 承認ボタンのアクションID。ボタンの value にはレビューIDを設定します。
+const (
+	approveActionID        = "review_approve"
+	requestChangesActionID = "review_request_changes"
+)
+
+// approvalSource は Slack のボタン経由の承認判断を表す記録元です。
+const approvalSource = "slack-button"
+
+// approvalBlocks は、レビュー結果に対する承認・修正依頼ボタンのブロックを返します。
+func approvalBlocks(reviewID string) []any {
+	button := func(actionID, label, style string) map[string]any {
+		return map[string]any{
+			"type":      "button",
+			"action_id": actionID,
+			"text":      plainText(label),
+			"style":     style,
+			"value":     reviewID,
+		}
+	}
+	return []any{
+		map[string]any{
+			"type": "section",
+			"text": map[string]any{"type": "mrkdwn", "text": fmt.Sprintf("🧑‍⚖️ AIの判定を確認し、承認判断をお願いします。(レビューID: `%s`)", reviewID)},
+		},
+		map[string]any{
+			"type":     "actions",
+			"block_id": "review_approval",
+			"elements": []any{
+				button(approveActionID, "✅ 承認", "primary"),
+				button(requestChangesActionID, "✋ 修正依頼", "danger"),
+			},
+		},
+	}
+}
+
+// handleApproval は承認・修正依頼ボタンの押下を履歴ストアに記録し、ボタンを判断結果で置き換えます。
+func (h *Handler) handleApproval(ctx context.Context, p interactionPayload) {
+	action := p.Actions[0]
+	var outcome history.Outcome
+	switch action.ActionID {
+	case approveActionID:
+		outcome = history.OutcomeApproved
+	case requestChangesActionID:
+		outcome = history.OutcomeChangesRequested
+	default:
+		return
+	}
+	if h.approvals == nil {
+		slog.Warn("承認ワークフローが無効のため、ボタン操作を無視します。", "action", action.ActionID)
+		return
+	}
+
+	decision := history.Decision{
+		ReviewID: action.Value,
+		Outcome:  outcome,
+		Decider:  p.User.ID,
+		Source:   approvalSource,
+	}
+	text := fmt.Sprintf("🧑‍⚖️ <@%s> が *%s* と判断しました。(レビューID: `%s`)", p.User.ID, outcome.Label(), action.Value)
+	replace := true
+	if err := h.approvals.RecordDecision(decision); err != nil {
+		slog.Error("承認判断の記録に失敗しました。", "review_id", action.Value, "error", err)
+		// 再操作できるよう、ボタンは残したまま失敗を通知します
+		text = fmt.Sprintf("⚠️ 承認判断の記録に失敗しました。時間をおいて再度お試しください。(レビューID: `%s`)", action.Value)
+		replace = false
+	} else {
+		slog.Info("承認判断を記録しました。", "review_id", action.Value, "outcome", outcome, "user", p.User.ID)
+	}
+
+	if p.ResponseURL == "" {
+		return
+	}
+	if err := h.api.respond(ctx, p.ResponseURL, text, replace); err != nil {
+		slog.Error("承認判断の結果の表示に失敗しました。", "review_id", action.Value, "error", err)
+	}
+}
@@ -0,0 +1,116 @@
+package slackapp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+const slackAPIBaseURL = "https://slack.com/api/"
+
+// client は Slack Web API を Bot トークンで呼び出すクライアントです。
+type client struct {
+	httpClient *http.Client
+	token      string
+}
+
+// postMessage はメッセージを投稿し、そのタイムスタンプを返します。threadTS を指定するとスレッドに返信します。
+func (c *client) postMessage(ctx context.Context, channel, threadTS, text string) (string, error) {
+	payload := map[string]any{"channel": channel, "text": text, "mrkdwn": true}
+	if threadTS != "" {
+		payload["thread_ts"] = threadTS
+	}
+	var resp struct {
+		TS string `json:"ts"`
+	}
+	if err := c.call(ctx, "chat.postMessage", payload, &resp); err != nil {
+		return "", err
+	}
+	return resp.TS, nil
+}
+
+// postBlocks は Block Kit のブロックを含むメッセージを投稿し、そのタイムスタンプを返します。text は通知用の代替テキストです。
+func (c *client) postBlocks(ctx context.Context, channel, threadTS, text string, blocks []any) (string, error) {
+	payload := map[string]any{"channel": channel, "text": text, "blocks": blocks}
+	if threadTS != "" {
+		payload["thread_ts"] = threadTS
+	}
+	var resp struct {
+		TS string `json:"ts"`
+	}
+	if err := c.call(ctx, "chat.postMessage", payload, &resp); err != nil {
+		return "", err
+	}
+	return resp.TS, nil
+}
+
+// respond はインタラクションの response_url を使用して応答します。replace が true の場合は操作されたメッセージを置き換えます。
+func (c *client) respond(ctx context.Context, responseURL, text string, replace bool) error {
+	body, err := json.Marshal(map[string]any{"replace_original": replace, "response_type": "ephemeral", "text": text})
+	if err != nil {
+		return fmt.Errorf("Slack への応答のエンコードに失敗しました: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Slack への応答リクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Slack への応答に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("Slack への応答がエラーを返しました (status: %d)", resp.StatusCode)
+	}
+	return nil
+}
+
+// openView はショートカットの trigger_id を使用してモーダルを開きます。
+func (c *client) openView(ctx context.Context, triggerID string, view any) error {
+	return c.call(ctx, "views.open", map[string]any{"trigger_id": triggerID, "view": view}, nil)
+}
+
+// call は JSON ボディで Slack Web API を呼び出します。
+func (c *client) call(ctx context.Context, method string, payload any, out any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("Slack API (%s) のリクエストのエンコードに失敗しました: %w", method, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackAPIBaseURL+method, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Slack APIリクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Slack API (%s) の呼び出しに失敗しました: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return fmt.Errorf("Slack APIレスポンスのデコードに失敗しました: %w", err)
+	}
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return fmt.Errorf("Slack APIレスポンスのデコードに失敗しました: %w", err)
+	}
+	if !status.OK {
+		return fmt.Errorf("Slack API (%s) がエラーを返しました: %s", method, status.Error)
+	}
+	if out != nil {
+		if err := json.Unmarshal(raw, out); err != nil {
+			return fmt.Errorf("Slack APIレスポンスのデコードに失敗しました: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+// Package slackapp は、Slack のスラッシュコマンドとショートカットからレビューを起動する Slack アプリのエンドポイントを提供します。
+// レビューはバックグラウンドで実行し、進捗と結果をスレッドに返信します。
+package slackapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"git-gemini-reviewer-go/internal/history"
+	"git-gemini-reviewer-go/internal/msgfit"
+)
+
+const (
+	// CommandPath はスラッシュコマンドの Request URL に設定するパスです。
+	CommandPath = "/slack/commands"
+	// InteractionPath はショートカットとモーダルの Request URL に設定するパスです。
+	InteractionPath = "/slack/interactions"
+
+	// ShortcutCallbackID は、App Home やショートカットメニューから起動するグローバルショートカットのコールバックIDです。
+	ShortcutCallbackID = "ai_review"
+	// modalCallbackID はレビュー依頼モーダルのコールバックIDです。
+	modalCallbackID = "ai_review_modal"
+
+	// defaultRunTimeout は1件のレビューに許容する最大時間です。
+	defaultRunTimeout = 30 * time.Minute
+)
+
+// Result はレビューの実行結果です。差分がない場合 Markdown は空文字列です。
+type Result struct {
+	ReviewID string
+	Markdown string
+}
+
+// RunFunc はレビューを実行する関数です。
+type RunFunc func(ctx context.Context, req Request) (Result, error)
+
+// Handler は Slack アプリからのリクエストを受け付ける http.Handler 群です。
+type Handler struct {
+	signingSecret string
+	api           *client
+	run           RunFunc
+	baseCtx       context.Context
+	runTimeout    time.Duration
+	allowedRepos  []string
+	approvals     *history.Store
+	now           func() time.Time
+}
+
+// Option は Handler の初期化オプションを設定するための関数です。
+type Option func(*Handler)
+
+// WithAllowedRepoPrefixes は、レビューを受け付けるリポジトリURLの接頭辞を制限します。
+// 未指定の場合はすべてのリポジトリを受け付けます。
+func WithAllowedRepoPrefixes(prefixes ...string) Option {
+	return func(h *Handler) {
+		h.allowedRepos = append(h.allowedRepos, prefixes...)
+	}
+}
+
+// WithBaseContext は、バックグラウンドで実行するレビューの親コンテキストを設定します。
+// サーバー停止時に実行中のレビューを中断するために使用します。
+func WithBaseContext(ctx context.Context) Option {
+	return func(h *Handler) {
+		h.baseCtx = ctx
+	}
+}
+
+// WithApproval は、レビュー結果に承認・修正依頼のボタンを付与し、押下された判断を履歴ストアに記録します。
+func WithApproval(store *history.Store) Option {
+	return func(h *Handler) {
+		h.approvals = store
+	}
+}
+
+// WithRunTimeout は1件のレビューに許容する最大時間を設定します。
+func WithRunTimeout(d time.Duration) Option {
+	return func(h *Handler) {
+		h.runTimeout = d
+	}
+}
+
+// NewHandler は Handler を生成します。signingSecret は Slack アプリの Signing Secret、botToken は Bot User OAuth Token です。
+func NewHandler(httpClient *http.Client, signingSecret, botToken string, run RunFunc, opts ...Option) *Handler {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	h := &Handler{
+		signingSecret: signingSecret,
+		api:           &client{httpClient: httpClient, token: botToken},
+		run:           run,
+		baseCtx:       context.Background(),
+		runTimeout:    defaultRunTimeout,
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Register は Slack アプリのエンドポイントを mux に登録します。
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc(CommandPath, h.handleCommand)
+	mux.HandleFunc(InteractionPath, h.handleInteraction)
+}
+
+// handleCommand はスラッシュコマンド (/ai-review repo branch) を処理します。
+// Slack は3秒以内の応答を求めるため、受付の応答を返した後にレビューをバックグラウンドで実行します。
+func (h *Handler) handleCommand(w http.ResponseWriter, r *http.Request) {
+	form, ok := h.verifiedForm(w, r)
+	if !ok {
+		return
+	}
+
+	req, err := parseCommandText(form.Get("text"))
+	if err == nil {
+		err = h.checkAllowed(req.RepoURL)
+	}
+	if err != nil {
+		writeEphemeral(w, fmt.Sprintf("⚠️ %v\n%s", err, commandUsage))
+		return
+	}
+	req.UserID = form.Get("user_id")
+
+	go h.process(req, form.Get("channel_id"))
+	writeEphemeral(w, fmt.Sprintf("🕒 `%s` の `%s` のレビューを受け付けました。進捗はスレッドでお知らせします。", req.RepoURL, req.FeatureBranch))
+}
+
+// interactionPayload は Slack のインタラクション (ショートカット・モーダル送信) のペイロードのうち、使用する項目です。
+type interactionPayload struct {
+	Type       string `json:"type"`
+	CallbackID string `json:"callback_id"`
+	TriggerID  string `json:"trigger_id"`
+	User       struct {
+		ID string `json:"id"`
+	} `json:"user"`
+	ResponseURL string `json:"response_url"`
+	Actions     []struct {
+		ActionID string `json:"action_id"`
+		Value    string `json:"value"`
+	} `json:"actions"`
+	View struct {
+		CallbackID string `json:"callback_id"`
+		State      struct {
+			Values map[string]map[string]struct {
+				Value          string `json:"value"`
+				SelectedOption *struct {
+					Value string `json:"value"`
+				} `json:"selected_option"`
+			} `json:"values"`
+		} `json:"state"`
+	} `json:"view"`
+}
+
+// handleInteraction は、ショートカットからのモーダル表示と、モーダル送信によるレビュー起動を処理します。
+func (h *Handler) handleInteraction(w http.ResponseWriter, r *http.Request) {
+	form, ok := h.verifiedForm(w, r)
+	if !ok {
+		return
+	}
+	var p interactionPayload
+	if err := json.Unmarshal([]byte(form.Get("payload")), &p); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case p.Type == "shortcut" && p.CallbackID == ShortcutCallbackID:
+		if err := h.api.openView(r.Context(), p.TriggerID, requestModal()); err != nil {
+			slog.Error("レビュー依頼モーダルの表示に失敗しました。", "user", p.User.ID, "error", err)
+		}
+		w.WriteHeader(http.StatusOK)
+
+	case p.Type == "view_submission" && p.View.CallbackID == modalCallbackID:
+		value := func(block string) string {
+			v := p.View.State.Values[block][block]
+			if v.SelectedOption != nil {
+				return v.SelectedOption.Value
+			}
+			return strings.TrimSpace(v.Value)
+		}
+		req := Request{
+			RepoURL:       value(blockRepo),
+			FeatureBranch: value(blockBranch),
+			BaseBranch:    value(blockBase),
+			Mode:          value(blockMode),
+			UserID:        p.User.ID,
+		}
+		if err := h.checkAllowed(req.RepoURL); err != nil {
+			writeJSON(w, map[string]any{"response_action": "errors", "errors": map[string]string{blockRepo: err.Error()}})
+			return
+		}
+		// モーダルには投稿先のチャンネルがないため、依頼者とのDMに結果を返信します
+		go h.process(req, req.UserID)
+		w.WriteHeader(http.StatusOK)
+
+	case p.Type == "block_actions" && len(p.Actions) > 0:
+		// 応答はハンドラーの終了まで送信されないため、判断の記録と表示の更新はバックグラウンドで行います
+		go h.handleApproval(h.baseCtx, p)
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// process はレビューを実行し、開始メッセージのスレッドに進捗と結果を返信します。
+func (h *Handler) process(req Request, channel string) {
+	ctx, cancel := context.WithTimeout(h.baseCtx, h.runTimeout)
+	defer cancel()
+
+	logger := slog.With("repo", req.RepoURL, "branch", req.FeatureBranch, "user", req.UserID)
+	intro := fmt.Sprintf("🤖 <@%s> の依頼で `%s` の `%s` をレビューします。", req.UserID, req.RepoURL, req.FeatureBranch)
+	threadTS, err := h.api.postMessage(ctx, channel, "", intro)
+	if err != nil && channel != req.UserID {
+		// Bot がチャンネルに参加していない場合は、依頼者とのDMで返信します
+		logger.Warn("チャンネルへの投稿に失敗したため、依頼者へのDMで返信します。", "channel", channel, "error", err)
+		channel = req.UserID
+		threadTS, err = h.api.postMessage(ctx, channel, "", intro)
+	}
+	if err != nil {
+		logger.Error("レビュー開始メッセージの投稿に失敗しました。", "error", err)
+		return
+	}
+
+	reply := func(text string) {
+		if _, err := h.api.postMessage(ctx, channel, threadTS, text); err != nil {
+			logger.Error("スレッドへの返信に失敗しました。", "error", err)
+		}
+	}
+
+	reply("⏳ リポジトリを取得し、AIレビューを実行しています...")
+	started := h.now()
+	result, err := h.run(ctx, req)
+	switch {
+	case err != nil:
+		logger.Error("Slack から依頼されたレビューに失敗しました。", "error", err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			reply(fmt.Sprintf("❌ レビューが制限時間 (%s) 内に完了しませんでした。", h.runTimeout))
+			return
+		}
+		reply(fmt.Sprintf("❌ レビューに失敗しました: %v", err))
+	case result.Markdown == "":
+		reply("✅ 差分がないため、レビューをスキップしました。")
+	default:
+		for _, chunk := range msgfit.Split(result.Markdown, msgfit.SlackThread) {
+			reply(chunk)
+		}
+		reply(fmt.Sprintf("✅ レビューが完了しました (所要時間: %s)。", h.now().Sub(started).Round(time.Second)))
+		if h.approvals != nil {
+			if _, err := h.api.postBlocks(ctx, channel, threadTS, "レビュー結果の承認判断をお願いします。", approvalBlocks(result.ReviewID)); err != nil {
+				logger.Error("承認ボタンの投稿に失敗しました。", "review_id", result.ReviewID, "error", err)
+			}
+		}
+	}
+}
+
+// verifiedForm は署名を検証したうえでフォームを解析します。検証に失敗した場合はエラー応答を書き込みます。
+func (h *Handler) verifiedForm(w http.ResponseWriter, r *http.Request) (url.Values, bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+	body, err := verifyRequest(r, h.signingSecret, h.now())
+	if err != nil {
+		slog.Warn("Slack リクエストを拒否しました。", "path", r.URL.Path, "error", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return nil, false
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		http.Error(w, "invalid form", http.StatusBadRequest)
+		return nil, false
+	}
+	return form, true
+}
+
+// checkAllowed は、リポジトリURLが許可された接頭辞に一致するかを検証します。
+func (h *Handler) checkAllowed(repoURL string) error {
+	if repoURL == "" {
+		return fmt.Errorf("リポジトリURLを指定してください")
+	}
+	if len(h.allowedRepos) == 0 {
+		return nil
+	}
+	for _, prefix := range h.allowedRepos {
+		if strings.HasPrefix(repoURL, prefix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("リポジトリ '%s' はレビューが許可されていません", repoURL)
+}
+
+func writeEphemeral(w http.ResponseWriter, text string) {
+	writeJSON(w, map[string]string{"response_type": "ephemeral", "text": text})
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Slack への応答の書き込みに失敗しました。", "error", err)
+	}
+}
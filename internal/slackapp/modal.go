@@ -0,0 +1,58 @@
+package slackapp
+
+// モーダルの入力ブロックID。action_id にも同じ値を使用します。
+const (
+	blockRepo   = "repo_url"
+	blockBranch = "feature_branch"
+	blockBase   = "base_branch"
+	blockMode   = "mode"
+)
+
+// requestModal は、ショートカットから開くレビュー依頼モーダルの定義を返します。
+func requestModal() map[string]any {
+	textInput := func(block, label, placeholder string, optional bool) map[string]any {
+		return map[string]any{
+			"type":     "input",
+			"block_id": block,
+			"optional": optional,
+			"label":    plainText(label),
+			"element": map[string]any{
+				"type":        "plain_text_input",
+				"action_id":   block,
+				"placeholder": plainText(placeholder),
+			},
+		}
+	}
+	option := func(value, label string) map[string]any {
+		return map[string]any{"value": value, "text": plainText(label)}
+	}
+	detail := option("detail", "詳細レビュー (detail)")
+
+	return map[string]any{
+		"type":        "modal",
+		"callback_id": modalCallbackID,
+		"title":       plainText("AIコードレビュー"),
+		"submit":      plainText("レビュー開始"),
+		"close":       plainText("キャンセル"),
+		"blocks": []any{
+			textInput(blockRepo, "リポジトリURL", "git@github.com:org/repo.git", false),
+			textInput(blockBranch, "レビュー対象ブランチ", "feature/my-branch", false),
+			textInput(blockBase, "基準ブランチ", "未入力の場合はサーバーの既定値", true),
+			map[string]any{
+				"type":     "input",
+				"block_id": blockMode,
+				"label":    plainText("レビューモード"),
+				"element": map[string]any{
+					"type":           "static_select",
+					"action_id":      blockMode,
+					"initial_option": detail,
+					"options":        []any{detail, option("release", "リリース判定 (release)")},
+				},
+			},
+		},
+	}
+}
+
+func plainText(text string) map[string]any {
+	return map[string]any{"type": "plain_text", "text": text}
+}
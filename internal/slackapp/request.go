@@ -0,0 +1,64 @@
+package slackapp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Request は Slack から受け付けたレビュー依頼です。
+type Request struct {
+	RepoURL       string
+	FeatureBranch string
+	BaseBranch    string
+	Mode          string
+	// UserID は依頼した Slack ユーザーのIDです。
+	UserID string
+}
+
+// commandUsage はスラッシュコマンドの使い方です。
+const commandUsage = "使い方: `/ai-review <リポジトリURL> <ブランチ> [基準ブランチ] [mode=release|detail]`"
+
+// parseCommandText はスラッシュコマンドの引数を解析します。
+// 位置引数としてリポジトリURL、レビュー対象ブランチ、基準ブランチを受け付け、
+// key=value 形式で base と mode を指定できます。
+func parseCommandText(text string) (Request, error) {
+	var req Request
+	var positional []string
+	for _, field := range strings.Fields(text) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			positional = append(positional, field)
+			continue
+		}
+		switch key {
+		case "base":
+			req.BaseBranch = value
+		case "mode":
+			req.Mode = value
+		default:
+			return Request{}, fmt.Errorf("不明なオプションです: '%s'", key)
+		}
+	}
+
+	switch len(positional) {
+	case 3:
+		if req.BaseBranch == "" {
+			req.BaseBranch = positional[2]
+		}
+		fallthrough
+	case 2:
+		req.RepoURL, req.FeatureBranch = unwrapLink(positional[0]), positional[1]
+	default:
+		return Request{}, fmt.Errorf("リポジトリURLとブランチを指定してください")
+	}
+	return req, nil
+}
+
+// unwrapLink は Slack が URL に付与する <https://...|label> 形式の装飾を取り除きます。
+func unwrapLink(s string) string {
+	if strings.HasPrefix(s, "<") && strings.HasSuffix(s, ">") {
+		s = strings.TrimSuffix(strings.TrimPrefix(s, "<"), ">")
+		s, _, _ = strings.Cut(s, "|")
+	}
+	return s
+}
@@ -0,0 +1,49 @@
+package slackapp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	signatureVersion = "v0"
+	// maxRequestAge は、リプレイ攻撃を防ぐために受け付けるリクエストの最大経過時間です。
+	maxRequestAge = 5 * time.Minute
+	// maxBodySize は Slack からのリクエストボディとして受け付ける最大サイズです。
+	maxBodySize = 1 << 20
+)
+
+// errInvalidSignature は署名の検証に失敗したことを表します。
+var errInvalidSignature = errors.New("Slack リクエストの署名が不正です")
+
+// verifyRequest は Slack の署名シークレットでリクエストの署名を検証し、検証済みのボディを返します。
+func verifyRequest(r *http.Request, signingSecret string, now time.Time) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("Slack リクエストの読み込みに失敗しました: %w", err)
+	}
+
+	timestamp := r.Header.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, errInvalidSignature
+	}
+	if age := now.Sub(time.Unix(sec, 0)); age > maxRequestAge || age < -maxRequestAge {
+		return nil, fmt.Errorf("%w: タイムスタンプが古すぎます", errInvalidSignature)
+	}
+
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	fmt.Fprintf(mac, "%s:%s:%s", signatureVersion, timestamp, body)
+	expected := signatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get("X-Slack-Signature"))) {
+		return nil, errInvalidSignature
+	}
+	return body, nil
+}
@@ -0,0 +1,136 @@
+// Package streamai は、Gemini の応答を生成と同時に書き出すストリーミング対応の adapters.CodeReviewAI を提供します。
+// 長いレビューでも最初の文字が表示されるまでの待ち時間を短くし、途中で中断 (Ctrl-C) できるようにします。
+package streamai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/sampling"
+	"git-gemini-reviewer-go/internal/tokenusage"
+
+	"github.com/shouni/gemini-reviewer-core/pkg/adapters"
+	"google.golang.org/genai"
+)
+
+// InterruptedNote は、受信の途中で応答が中断された場合に、出力済みの部分に続けて書き出す注記です。
+// ストリーミングに対応する他のアダプタも同じ注記を使用します。
+const InterruptedNote = "\n\n⚠️ 応答の受信が中断されました。\n\n"
+
+type writerKey struct{}
+
+// WithWriter は、応答を逐次書き出す先をコンテキストに設定します。nil を指定すると書き出しを無効にします。
+func WithWriter(ctx context.Context, w io.Writer) context.Context {
+	return context.WithValue(ctx, writerKey{}, w)
+}
+
+// WriterFrom はコンテキストに設定された書き出し先を返します。設定されていない場合は nil を返します。
+func WriterFrom(ctx context.Context) io.Writer {
+	w, _ := ctx.Value(writerKey{}).(io.Writer)
+	return w
+}
+
+// Adapter は、genai の GenerateContentStream で応答を受信する adapters.CodeReviewAI です。
+// コンテキストに書き出し先が設定されている場合は受信した部分を逐次書き出し、設定されていない場合は応答をまとめて返します。
+type Adapter struct {
+	client *genai.Client
+	model  string
+	params sampling.Params
+}
+
+var _ adapters.CodeReviewAI = (*Adapter)(nil)
+
+// New は、環境変数 GEMINI_API_KEY (未設定時は GOOGLE_API_KEY) の API キーで model を呼び出す Adapter を返します。
+// params は応答の生成を調整するパラメータです。
+func New(ctx context.Context, httpClient *http.Client, model string, params sampling.Params) (*Adapter, error) {
+	apiKey := os.Getenv("GEMINI_API_KEY")
+	if apiKey == "" {
+		apiKey = os.Getenv("GOOGLE_API_KEY")
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("GEMINI_API_KEY または GOOGLE_API_KEY が設定されていません")
+	}
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:     apiKey,
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: httpClient,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Gemini クライアントの初期化に失敗しました: %w", err)
+	}
+	return NewWithClient(client, model, params), nil
+}
+
+// NewWithClient は、初期化済みの genai のクライアントで model を呼び出す Adapter を返します。
+// Vertex AI など、API キー以外の方法で認証するクライアントに使用します。
+func NewWithClient(client *genai.Client, model string, params sampling.Params) *Adapter {
+	return &Adapter{client: client, model: model, params: params}
+}
+
+// generateConfig は、応答の生成を調整するパラメータを genai の設定に変換します。0 の top-p と出力トークン数は指定しません。
+func (a *Adapter) generateConfig() *genai.GenerateContentConfig {
+	config := &genai.GenerateContentConfig{Temperature: genai.Ptr(float32(a.params.Temperature))}
+	if a.params.TopP > 0 {
+		config.TopP = genai.Ptr(float32(a.params.TopP))
+	}
+	if a.params.MaxOutputTokens > 0 {
+		config.MaxOutputTokens = int32(a.params.MaxOutputTokens)
+	}
+	return config
+}
+
+// ReviewCodeDiff はプロンプトを送信し、応答の全体を返します。
+func (a *Adapter) ReviewCodeDiff(ctx context.Context, finalPrompt string) (string, error) {
+	out := WriterFrom(ctx)
+	config := a.generateConfig()
+
+	var (
+		b        strings.Builder
+		finished bool
+		usage    *genai.GenerateContentResponseUsageMetadata
+	)
+	fail := func(err error) (string, error) {
+		if out != nil && b.Len() > 0 {
+			_, _ = io.WriteString(out, InterruptedNote)
+		}
+		return "", fmt.Errorf("Gemini API のストリーミング呼び出しに失敗しました (Model: %s): %w", a.model, err)
+	}
+	for resp, err := range a.client.Models.GenerateContentStream(ctx, a.model, genai.Text(finalPrompt), config) {
+		if err != nil {
+			return fail(err)
+		}
+		if resp.UsageMetadata != nil {
+			usage = resp.UsageMetadata
+		}
+		text := resp.Text()
+		b.WriteString(text)
+		if out != nil && text != "" {
+			if _, err := io.WriteString(out, text); err != nil {
+				return "", fmt.Errorf("応答の書き出しに失敗しました: %w", err)
+			}
+		}
+		for _, c := range resp.Candidates {
+			finished = finished || c.FinishReason != ""
+			if c.FinishReason == genai.FinishReasonMaxTokens {
+				slog.Warn("応答が出力トークン数の上限に達したため、途中で打ち切られました。--ai-max-output-tokens で大きな値を指定してください。", "model", a.model, "max_output_tokens", a.params.MaxOutputTokens)
+			}
+		}
+	}
+	// genai は受信の途中で切断された場合にエラーを返さずに終了するため、取り消しと終了理由の有無で判定する
+	if err := ctx.Err(); err != nil {
+		return fail(err)
+	}
+	if !finished {
+		return fail(fmt.Errorf("応答が終了理由を含まずに途切れました"))
+	}
+	// 思考のトークンは出力のトークンとして課金されるため、出力に含めます
+	if usage != nil {
+		tokenusage.Record(ctx, int(usage.PromptTokenCount), int(usage.CandidatesTokenCount+usage.ThoughtsTokenCount))
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,99 @@
+// Package stubai は、ネットワークに接続せず、差分の統計から決定的なレビュー結果を生成する AI の代替実装です。
+// API キーや費用なしに、CI やデモでパイプライン・投稿先・書式の変更を確認するためのものです。
+package stubai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/diffstat"
+	"git-gemini-reviewer-go/internal/inline"
+	"git-gemini-reviewer-go/internal/monorepo"
+
+	"github.com/shouni/gemini-reviewer-core/pkg/adapters"
+)
+
+// largeChangeLines は、分割を提案する本番コードの変更行数の目安です。
+const largeChangeLines = 400
+
+// Reviewer は adapters.CodeReviewAI のスタブです。同じプロンプトには常に同じ結果を返します。
+type Reviewer struct{}
+
+var _ adapters.CodeReviewAI = Reviewer{}
+
+// New は Reviewer を返します。
+func New() adapters.CodeReviewAI {
+	return Reviewer{}
+}
+
+// ReviewCodeDiff は、プロンプトに含まれる差分を集計し、定型のレビュー結果を返します。
+// プロンプトで構造化された指摘 (inline.PromptInstruction) が要求されている場合は JSON を返します。
+func (Reviewer) ReviewCodeDiff(ctx context.Context, finalPrompt string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	// 出力形式の指示は差分の後に付与されるため、差分の一部として集計しないよう取り除く
+	if prompt, ok := strings.CutSuffix(finalPrompt, inline.PromptInstruction); ok {
+		out, err := json.Marshal(Review(prompt))
+		if err != nil {
+			return "", fmt.Errorf("スタブのレビュー結果のエンコードに失敗しました: %w", err)
+		}
+		return string(out), nil
+	}
+	return Review(finalPrompt).Markdown(), nil
+}
+
+// Review は差分の統計から構造化されたレビュー結果を生成します。
+// テストの変更を伴わない本番コードの変更を要修正、変更行数の多い本番コードのファイルを軽微な指摘とします。
+func Review(diff string) inline.Review {
+	stats := diffstat.Compute(diff)
+	untested := stats.Untested()
+
+	var findings []inline.Finding
+	for _, f := range monorepo.SplitDiff(diff) {
+		if diffstat.Classify(f.Path) != diffstat.Production {
+			continue
+		}
+		line, added, removed := scan(f.Content)
+		if untested {
+			findings = append(findings, inline.Finding{
+				File: f.Path, Line: line, Severity: inline.Major,
+				Message: fmt.Sprintf("本番コードの変更 (+%d / -%d 行) に対応するテストの変更がありません。", added, removed),
+			})
+		}
+		if added+removed >= largeChangeLines {
+			findings = append(findings, inline.Finding{
+				File: f.Path, Line: line, Severity: inline.Minor,
+				Message: fmt.Sprintf("変更行数が %d 行と多いため、レビューしやすい単位への分割を検討してください。", added+removed),
+			})
+		}
+	}
+
+	summary := "> 🧪 このレビューは `--ai-provider stub` が差分の統計から生成した定型の結果です。AI による解析は行っていません。\n\n" + stats.Table()
+	return inline.Review{Summary: summary, Findings: findings}
+}
+
+// scan は1ファイル分の差分から、最初の追加行の変更後の行番号と、追加行数・削除行数を返します。
+func scan(content string) (firstAdded, added, removed int) {
+	next := 0
+	for _, line := range strings.Split(content, "\n") {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			next = inline.HunkStart(line)
+		case next == 0:
+		case strings.HasPrefix(line, "+"):
+			if firstAdded == 0 {
+				firstAdded = next
+			}
+			added++
+			next++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		case strings.HasPrefix(line, " "):
+			next++
+		}
+	}
+	return firstAdded, added, removed
+}
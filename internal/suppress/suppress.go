@@ -0,0 +1,202 @@
+// Package suppress は、変更後のコードに書かれた `ai-review:ignore` コメントによる指摘の抑制を扱います。
+// リンターの '//nolint' と同様に、誤検知と判断した指摘をコード上で明示し、同じ指摘が繰り返し投稿されないようにします。
+// 抑制した指摘は削除せず、抑制された指摘として結果に残します。
+package suppress
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode"
+
+	"git-gemini-reviewer-go/internal/findings"
+	"git-gemini-reviewer-go/internal/inline"
+	"git-gemini-reviewer-go/internal/monorepo"
+)
+
+// Marker は抑制のコメントの目印です。
+// 'ai-review:ignore security,style' のようにカテゴリをカンマ区切りで続けると、そのカテゴリの指摘のみを抑制します。
+// カテゴリを省略した場合は、すべてのカテゴリの指摘を抑制します。
+const Marker = "ai-review:ignore"
+
+// Directive は1つの抑制のコメントです。
+type Directive struct {
+	// Categories は抑制する指摘のカテゴリです。空の場合はすべてのカテゴリです。
+	Categories []findings.Category
+}
+
+// Covers は、指摘のカテゴリが抑制の対象かを判定します。
+func (d Directive) Covers(c findings.Category) bool {
+	if len(d.Categories) == 0 {
+		return true
+	}
+	for _, known := range d.Categories {
+		if known == c {
+			return true
+		}
+	}
+	return false
+}
+
+// String はコメントでの指定を返します (例: 'ai-review:ignore security')。
+func (d Directive) String() string {
+	if len(d.Categories) == 0 {
+		return Marker
+	}
+	names := make([]string, len(d.Categories))
+	for i, c := range d.Categories {
+		names[i] = string(c)
+	}
+	return Marker + " " + strings.Join(names, ",")
+}
+
+// merge は同じ行に対する2つの指定をまとめます。いずれかがすべてのカテゴリを対象とする場合は、すべてのカテゴリを対象とします。
+func (d Directive) merge(other Directive) Directive {
+	if len(d.Categories) == 0 || len(other.Categories) == 0 {
+		return Directive{}
+	}
+	merged := d
+	for _, c := range other.Categories {
+		if !merged.Covers(c) {
+			merged.Categories = append(merged.Categories, c)
+		}
+	}
+	return merged
+}
+
+// Set は、ファイルと変更後の行番号ごとの抑制の指定です。
+type Set map[string]map[int]Directive
+
+// Parse は差分の変更後の行 (追加行とコンテキスト行) から抑制のコメントを読み取ります。
+// コードの行末のコメントはその行に、コメントのみの行は、その行と次の行に適用します。
+// 不明なカテゴリを含むコメントは、意図しない抑制を避けるため警告を出して無視します。
+func Parse(diff string) Set {
+	set := make(Set)
+	for _, f := range monorepo.SplitDiff(diff) {
+		next := 0
+		for _, line := range strings.Split(f.Content, "\n") {
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				next = inline.HunkStart(line)
+				continue
+			case next == 0:
+				continue
+			case !strings.HasPrefix(line, "+") && !strings.HasPrefix(line, " "):
+				continue
+			}
+			lineNo := next
+			next++
+			d, standalone, ok, err := parseComment(line[1:])
+			if err != nil {
+				slog.Warn("抑制のコメントを無視します。", "file", f.Path, "line", lineNo, "error", err)
+				continue
+			}
+			if !ok {
+				continue
+			}
+			set.add(f.Path, lineNo, d)
+			if standalone {
+				set.add(f.Path, lineNo+1, d)
+			}
+		}
+	}
+	return set
+}
+
+func (s Set) add(file string, line int, d Directive) {
+	if s[file] == nil {
+		s[file] = make(map[int]Directive)
+	}
+	if existing, ok := s[file][line]; ok {
+		d = existing.merge(d)
+	}
+	s[file][line] = d
+}
+
+// Lookup は、ファイルの行に対するカテゴリ c の指摘を抑制する指定を返します。
+func (s Set) Lookup(file string, line int, c findings.Category) (Directive, bool) {
+	d, ok := s[strings.TrimPrefix(file, "b/")][line]
+	if !ok || line == 0 || !d.Covers(c) {
+		return Directive{}, false
+	}
+	return d, true
+}
+
+// parseComment はコードの1行から抑制のコメントを読み取ります。
+// standalone は、行が抑制のコメントのみからなり、次の行にも適用するかを表します。
+func parseComment(code string) (d Directive, standalone, ok bool, err error) {
+	idx := strings.Index(code, Marker)
+	if idx == -1 {
+		return Directive{}, false, false, nil
+	}
+	rest := code[idx+len(Marker):]
+	// 'ai-review:ignored' のような別の語は対象外とする
+	if rest != "" && !unicode.IsSpace(rune(rest[0])) && !strings.HasPrefix(rest, "*/") && !strings.HasPrefix(rest, "-->") {
+		return Directive{}, false, false, nil
+	}
+	standalone = strings.Trim(code[:idx], " \t/#*-;<!") == ""
+
+	// 最初の語がカテゴリの指定です。続く語は抑制の理由として扱います
+	if fields := strings.Fields(rest); len(fields) > 0 && unicode.IsLetter(rune(fields[0][0])) {
+		for _, name := range strings.Split(fields[0], ",") {
+			if name == "" {
+				continue
+			}
+			c, err := findings.ParseCategory(name)
+			if err != nil {
+				return Directive{}, false, false, fmt.Errorf("抑制のコメントのカテゴリが不正です: %w", err)
+			}
+			d.Categories = append(d.Categories, c)
+		}
+	}
+	return d, standalone, true, nil
+}
+
+// Review は、構造化された指摘のうち抑制の対象を Suppressed に移し、抑制した件数を返します。
+// 抑制した指摘は判定とインラインコメントの対象外になります。
+func Review(r *inline.Review, s Set) int {
+	if len(s) == 0 {
+		return 0
+	}
+	active := r.Findings[:0]
+	for _, f := range r.Findings {
+		if d, ok := s.Lookup(f.File, f.Line, findings.Classify(f.Message)); ok {
+			f.SuppressedBy = d.String()
+			r.Suppressed = append(r.Suppressed, f)
+			continue
+		}
+		active = append(active, f)
+	}
+	r.Findings = active
+	return len(r.Suppressed)
+}
+
+// Markdown は、レビュー結果の Markdown のうち抑制の対象の指摘の行を、抑制された指摘として書き換えます。
+// 書き換えた行は指摘の集計 (findings.Count) や重要パスの判定の対象外になります。
+// AI が出力した判定はそのまま残すため、判定を指摘から導く場合は構造化された指摘 (inline) を使用してください。
+func Markdown(result string, s Set) (string, int) {
+	if len(s) == 0 {
+		return result, 0
+	}
+	lines := strings.Split(result, "\n")
+	suppressed := 0
+	for _, f := range findings.Extract(result) {
+		d, ok := s.Lookup(f.File, f.Line, f.Category)
+		if !ok {
+			continue
+		}
+		line := lines[f.Index]
+		prefix, text, _ := strings.Cut(line, "問題点")
+		lines[f.Index] = prefix + "🔕 抑制された指摘" + findings.Mute(strings.Replace(text, ":", fmt.Sprintf(" (`%s`):", d), 1))
+		suppressed++
+	}
+	return strings.Join(lines, "\n"), suppressed
+}
+
+// Notice はレビュー結果の冒頭に置く、抑制した指摘の件数の通知を返します。抑制した指摘がない場合は空文字列です。
+func Notice(suppressed int) string {
+	if suppressed == 0 {
+		return ""
+	}
+	return fmt.Sprintf("🔕 **抑制された指摘 %d 件:** 変更後のコードの `%s` コメントにより、指摘の集計とインラインコメントの対象外としました。", suppressed, Marker)
+}
@@ -0,0 +1,175 @@
+// Package teams は、Microsoft Teams の Incoming Webhook (または Workflows の Webhook) に
+// レビュー結果を Adaptive Card として投稿するクライアントです。
+package teams
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/pkg/retry"
+)
+
+// cardContentType は Adaptive Card の添付ファイルの種類です。
+const cardContentType = "application/vnd.microsoft.card.adaptive"
+
+// cardVersion は Teams が対応する Adaptive Card のスキーマのバージョンです。
+const cardVersion = "1.4"
+
+// Client は Teams の Webhook のクライアントです。
+type Client struct {
+	httpClient *http.Client
+	webhookURL string
+}
+
+// NewClient は Client を生成します。
+func NewClient(httpClient *http.Client, webhookURL string) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{httpClient: httpClient, webhookURL: webhookURL}
+}
+
+// message は Webhook に POST するメッセージです。
+type message struct {
+	Type        string       `json:"type"`
+	Attachments []attachment `json:"attachments"`
+}
+
+type attachment struct {
+	ContentType string  `json:"contentType"`
+	ContentURL  *string `json:"contentUrl"`
+	Content     card    `json:"content"`
+}
+
+type card struct {
+	Schema  string         `json:"$schema"`
+	Type    string         `json:"type"`
+	Version string         `json:"version"`
+	Body    []textBlock    `json:"body"`
+	MSTeams map[string]any `json:"msteams"`
+}
+
+// textBlock は Adaptive Card の TextBlock です。Markdown のサブセット (太字・斜体・リスト・リンク) を表示できます。
+type textBlock struct {
+	Type      string `json:"type"`
+	Text      string `json:"text"`
+	Wrap      bool   `json:"wrap"`
+	Size      string `json:"size,omitempty"`
+	Weight    string `json:"weight,omitempty"`
+	Separator bool   `json:"separator,omitempty"`
+	FontType  string `json:"fontType,omitempty"`
+}
+
+// Post は、見出しと本文を1つの Adaptive Card として投稿します。
+// 本文は Markdown の見出しごとのセクションに分け、セクションごとの TextBlock として区切り線付きで表示します。
+func (c *Client) Post(ctx context.Context, title, content string) error {
+	body := []textBlock{{Type: "TextBlock", Text: title, Wrap: true, Size: "Large", Weight: "Bolder"}}
+	for i, section := range Sections(content) {
+		body = append(body, textBlock{Type: "TextBlock", Text: section.Text, Wrap: true, Separator: i > 0, FontType: section.fontType()})
+	}
+	msg := message{
+		Type: "message",
+		Attachments: []attachment{{
+			ContentType: cardContentType,
+			Content: card{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: cardVersion,
+				Body:    body,
+				// 長いレビューが狭い幅で折り返されないよう、チャネルの幅いっぱいに表示する
+				MSTeams: map[string]any{"width": "Full"},
+			},
+		}},
+	}
+	return c.send(ctx, msg)
+}
+
+// send はメッセージを Webhook に POST します。
+func (c *Client) send(ctx context.Context, msg message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("Teams のメッセージのエンコードに失敗しました: %w", err))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.webhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return retry.Permanent(fmt.Errorf("Teams の Webhook のリクエストの作成に失敗しました: %w", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("Teams の Webhook の呼び出しに失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode/100 != 2 {
+		err := fmt.Errorf("Teams の Webhook がエラーを返しました (status: %d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		// レート制限以外のクライアントエラーはリトライしても解消しません
+		if resp.StatusCode/100 == 4 && resp.StatusCode != http.StatusTooManyRequests {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	// 旧来の Office 365 コネクタは、失敗時にも 200 でエラーの本文を返す場合があります
+	if text := strings.TrimSpace(string(body)); text != "" && text != "1" && strings.Contains(strings.ToLower(text), "error") {
+		return fmt.Errorf("Teams の Webhook がエラーを返しました: %s", text)
+	}
+	return nil
+}
+
+// Section は、本文を見出しで区切った1つのセクションです。
+type Section struct {
+	Text string
+	// Code は、セクションがコードブロックのみからなるかを表します。等幅フォントで表示します。
+	Code bool
+}
+
+func (s Section) fontType() string {
+	if s.Code {
+		return "Monospace"
+	}
+	return ""
+}
+
+var heading = regexp.MustCompile(`^#{1,6}\s+(.*)$`)
+
+// Sections は Markdown を見出しごとのセクションに分け、TextBlock で表示できる形に変換します。
+// TextBlock は見出しとコードブロックを表示できないため、見出しは太字に、コードブロックは区切りの行を除いた等幅のセクションにします。
+func Sections(content string) []Section {
+	var sections []Section
+	var current []string
+	flush := func(code bool) {
+		text := strings.TrimSpace(strings.Join(current, "\n"))
+		if text != "" {
+			sections = append(sections, Section{Text: text, Code: code})
+		}
+		current = nil
+	}
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			flush(inFence)
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			current = append(current, line)
+			continue
+		}
+		if m := heading.FindStringSubmatch(trimmed); m != nil {
+			flush(false)
+			current = append(current, "**"+strings.TrimSpace(m[1])+"**")
+			continue
+		}
+		current = append(current, line)
+	}
+	flush(inFence)
+	return sections
+}
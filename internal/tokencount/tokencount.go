@@ -0,0 +1,122 @@
+// Package tokencount は、AI に送信する前にプロンプトの入力トークン数を数える機能を提供します。
+// Gemini の countTokens API による正確な計数と、ネットワークに接続しないバイト長からの概算を選択できます。
+package tokencount
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"git-gemini-reviewer-go/internal/ratelimit"
+)
+
+// 計数の方法です。
+const (
+	// MethodAPI は Gemini の countTokens API で数えます。
+	MethodAPI = "api"
+	// MethodEstimate はネットワークに接続せず、バイト長から概算します。
+	MethodEstimate = "estimate"
+)
+
+// defaultBaseURL は Gemini API (Generative Language API) のベースURLです。
+const defaultBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+// Counter はプロンプトの入力トークン数を数えます。
+type Counter interface {
+	CountTokens(ctx context.Context, prompt string) (int, error)
+	// Method は計数の方法 (MethodAPI または MethodEstimate) を返します。ログの表示に使用します。
+	Method() string
+}
+
+// Estimator はバイト長から入力トークン数を概算する Counter です。
+type Estimator struct{}
+
+// CountTokens は ratelimit.EstimateTokens による概算を返します。
+func (Estimator) CountTokens(_ context.Context, prompt string) (int, error) {
+	return ratelimit.EstimateTokens(prompt), nil
+}
+
+// Method は MethodEstimate を返します。
+func (Estimator) Method() string {
+	return MethodEstimate
+}
+
+// Gemini は Gemini の countTokens API で入力トークン数を数える Counter です。
+// countTokens の呼び出しは課金の対象外で、生成のクォータも消費しません。
+type Gemini struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+	model      string
+}
+
+// NewGemini は、環境変数 GEMINI_API_KEY (未設定時は GOOGLE_API_KEY) の API キーで model のトークン数を数える Gemini を返します。
+func NewGemini(httpClient *http.Client, model string) (*Gemini, error) {
+	apiKey := os.Getenv("GEMINI_API_KEY")
+	if apiKey == "" {
+		apiKey = os.Getenv("GOOGLE_API_KEY")
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("countTokens API の呼び出しには GEMINI_API_KEY または GOOGLE_API_KEY が必要です")
+	}
+	return &Gemini{httpClient: httpClient, baseURL: defaultBaseURL, apiKey: apiKey, model: model}, nil
+}
+
+type countTokensRequest struct {
+	Contents []content `json:"contents"`
+}
+
+type content struct {
+	Parts []part `json:"parts"`
+}
+
+type part struct {
+	Text string `json:"text"`
+}
+
+type countTokensResponse struct {
+	TotalTokens int `json:"totalTokens"`
+}
+
+// CountTokens は countTokens API でプロンプトの入力トークン数を数えます。
+func (g *Gemini) CountTokens(ctx context.Context, prompt string) (int, error) {
+	body, err := json.Marshal(countTokensRequest{Contents: []content{{Parts: []part{{Text: prompt}}}}})
+	if err != nil {
+		return 0, fmt.Errorf("countTokens のリクエストの作成に失敗しました: %w", err)
+	}
+	endpoint := fmt.Sprintf("%s/models/%s:countTokens", g.baseURL, url.PathEscape(g.model))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("countTokens のリクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", g.apiKey)
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("countTokens API の呼び出しに失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return 0, fmt.Errorf("countTokens API の応答の読み込みに失敗しました: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("countTokens API がエラーを返しました (status: %d): %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+	var out countTokensResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		return 0, fmt.Errorf("countTokens API の応答の解析に失敗しました: %w", err)
+	}
+	return out.TotalTokens, nil
+}
+
+// Method は MethodAPI を返します。
+func (g *Gemini) Method() string {
+	return MethodAPI
+}
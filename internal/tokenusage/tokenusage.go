@@ -0,0 +1,111 @@
+// Package tokenusage は、AI の応答のメタデータから取得したトークン数を集計し、料金表から推定費用を計算します。
+// 応答にトークン数を含まないアダプタ (gemini-reviewer-core の Gemini アダプタなど) では、プロンプトと応答の長さからの概算を使用します。
+package tokenusage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"git-gemini-reviewer-go/internal/estimate"
+)
+
+// Recorder は、アダプタが応答のメタデータから取得したトークン数を記録します。
+type Recorder struct {
+	mu       sync.Mutex
+	input    int
+	output   int
+	recorded bool
+}
+
+// Record は応答のトークン数を記録します。r が nil の場合は何もしません。
+// リトライやフォールバックで複数回呼び出された場合は、最後に成功した応答の値を保持します。
+func (r *Recorder) Record(input, output int) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.input, r.output, r.recorded = input, output, true
+}
+
+// Tokens は記録したトークン数を返します。記録されていない場合は false を返します。
+func (r *Recorder) Tokens() (input, output int, ok bool) {
+	if r == nil {
+		return 0, 0, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.input, r.output, r.recorded
+}
+
+type recorderKey struct{}
+
+// WithRecorder は、応答のトークン数を記録する Recorder をコンテキストに設定します。
+func WithRecorder(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey{}, r)
+}
+
+// Record は、コンテキストに設定された Recorder に応答のトークン数を記録します。設定されていない場合は何もしません。
+// 応答のメタデータにトークン数を含むアダプタが呼び出します。
+func Record(ctx context.Context, input, output int) {
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	r.Record(input, output)
+}
+
+// Report は1回のレビューで AI に送信・受信したトークン数と推定費用です。分割レビューでは各リクエストの合計です。
+type Report struct {
+	Requests     int `json:"requests"`
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+	// Estimated は、応答のメタデータからトークン数を取得できず、概算の値を含むかを表します。
+	Estimated bool `json:"estimated,omitempty"`
+	// CostUSD は料金表から計算した推定費用 (USD) です。料金が不明なモデルのリクエストを含む場合は nil です。
+	CostUSD *float64 `json:"cost_usd,omitempty"`
+	// unpriced は、料金が不明なモデルのリクエストを含むかを表します。
+	unpriced bool
+}
+
+// Add は1件のリクエストのトークン数を加算します。price が nil の場合は料金が不明なモデルとして扱い、費用を nil とします。
+func (r *Report) Add(input, output int, estimated bool, price *estimate.Price) {
+	r.Requests++
+	r.InputTokens += input
+	r.OutputTokens += output
+	r.Estimated = r.Estimated || estimated
+	if price == nil || r.unpriced {
+		r.unpriced, r.CostUSD = true, nil
+		return
+	}
+	cost := price.Cost(input, output)
+	if r.CostUSD != nil {
+		cost += *r.CostUSD
+	}
+	r.CostUSD = &cost
+}
+
+// LogAttrs は、ログに出力する属性を返します。
+func (r Report) LogAttrs() []any {
+	attrs := []any{"requests", r.Requests, "input_tokens", r.InputTokens, "output_tokens", r.OutputTokens, "estimated", r.Estimated}
+	if r.CostUSD != nil {
+		attrs = append(attrs, "estimated_cost_usd", fmt.Sprintf("%.4f", *r.CostUSD))
+	}
+	return attrs
+}
+
+// Trailer は、投稿するレビュー結果の末尾に付与するトークン数と推定費用の Markdown を返します。
+// AI にリクエストしていない場合は空文字列です。
+func (r Report) Trailer(model string) string {
+	if r.Requests == 0 {
+		return ""
+	}
+	cost := "不明"
+	if r.CostUSD != nil {
+		cost = fmt.Sprintf("$%.4f", *r.CostUSD)
+	}
+	note := ""
+	if r.Estimated {
+		note = " (概算を含む)"
+	}
+	return fmt.Sprintf("\n\n---\n\n<sub>🧮 %s ・ 入力 %d / 出力 %d トークン%s ・ %d リクエスト ・ 推定費用 %s</sub>\n",
+		model, r.InputTokens, r.OutputTokens, note, r.Requests, cost)
+}
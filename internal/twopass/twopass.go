@@ -0,0 +1,194 @@
+// Package twopass は、2段階のレビューのプロンプトと結果の結合を提供します。
+// 1回目は差分の要約 (変更行のみ) から変更全体の概要とファイルのリスクの順位を求め、
+// 2回目はリスクの高い上位のファイルのみを詳しくレビューします。巨大な差分で、品質を保ちながらトークン数を抑えるためのものです。
+package twopass
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"git-gemini-reviewer-go/internal/diffguard"
+	"git-gemini-reviewer-go/internal/monorepo"
+)
+
+// DefaultTopFiles は、2回目に詳しくレビューするファイルの既定の件数です。
+const DefaultTopFiles = 5
+
+// outlineLines は、1回目のプロンプトに含める1ファイルあたりの変更行の上限です。
+const outlineLines = 40
+
+// listLimit は、レポートに一覧で示す詳細レビューの対象外のファイルの上限です。
+const listLimit = 30
+
+// RankingHeading は、1回目の応答でリスクの高いファイルの順位を記述させる見出しです。
+const RankingHeading = "## 🎯 リスクの高いファイル"
+
+// OverviewPrompt は、変更全体の概要とファイルのリスクの順位を求める1回目のプロンプトを返します。
+// トークン数を抑えるため、差分は前後の文脈の行を除き、ファイルごとに変更行を outlineLines 行までに要約して渡します。
+func OverviewPrompt(diff string, top int) string {
+	var sb strings.Builder
+	sb.WriteString("# 🔭 2段階レビュー: 変更の概要とリスクの評価\n\n")
+	fmt.Fprintf(&sb, "これは2段階のレビューの1回目です。以下の差分の要約 (変更行のみ) から変更全体を把握し、2回目に詳しくレビューすべきリスクの高いファイルを最大 %d 件選んでください。\n\n", top)
+	sb.WriteString("## 出力の規則 (MUST)\n\n")
+	sb.WriteString("- 次の2つの見出しのみを、この順に出力してください。\n")
+	sb.WriteString("  - `## 🗺️ 変更の概要`: 変更全体の目的と構成、影響の範囲を数行から10行程度で記述します。\n")
+	fmt.Fprintf(&sb, "  - `%s`: リスクの高い順の番号付きリストで、各行に差分のとおりのパスをバッククォートで囲んで理由とともに記述します (例: 1. `internal/auth/login.go` — 認証の判定を変更)。\n", RankingHeading)
+	sb.WriteString("- リスクは、不具合が起きた場合の影響の大きさ (認証・決済・データの変更など) と、変更の複雑さで評価してください。自動生成されたファイルやロックファイルは選ばないでください。\n")
+	sb.WriteString("- 個々の指摘事項とリリース可否の判定は、2回目のレビューで行うため出力しないでください。\n\n")
+	sb.WriteString("## 差分の要約\n\n")
+	sb.WriteString(outline(diff))
+	return sb.String()
+}
+
+// outline は、差分をファイルごとのハンクのヘッダと変更行のみに要約します。
+func outline(diff string) string {
+	var sb strings.Builder
+	for _, f := range monorepo.SplitDiff(diff) {
+		var lines []string
+		added, removed, omitted := 0, 0, 0
+		for _, line := range strings.Split(f.Content, "\n") {
+			switch {
+			case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+				continue
+			case strings.HasPrefix(line, "+"):
+				added++
+			case strings.HasPrefix(line, "-"):
+				removed++
+			case strings.HasPrefix(line, "@@"), strings.HasPrefix(line, diffguard.OmittedMarker):
+			default:
+				continue
+			}
+			if len(lines) >= outlineLines {
+				omitted++
+				continue
+			}
+			lines = append(lines, line)
+		}
+		fence := "```"
+		for strings.Contains(f.Content, fence) {
+			fence += "`"
+		}
+		fmt.Fprintf(&sb, "### `%s` (+%d / -%d)\n\n%sdiff\n%s\n", f.Path, added, removed, fence, strings.Join(lines, "\n"))
+		if omitted > 0 {
+			fmt.Fprintf(&sb, "... (ほか %d 行)\n", omitted)
+		}
+		sb.WriteString(fence + "\n\n")
+	}
+	return sb.String()
+}
+
+// quotedPath は、応答の行に含まれるバッククォートで囲まれた文字列です。
+var quotedPath = regexp.MustCompile("`([^`\n]+)`")
+
+// Ranking は、1回目の応答からリスクの高い順のファイルを最大 top 件取り出します。
+// RankingHeading 以降の行でバッククォートで囲まれた、差分に含まれるパス (paths) のみを採用し、見出しがない場合は応答全体から探します。
+func Ranking(overview string, paths []string, top int) []string {
+	if i := strings.Index(overview, RankingHeading); i >= 0 {
+		overview = overview[i+len(RankingHeading):]
+	}
+	var ranked []string
+	for _, m := range quotedPath.FindAllStringSubmatch(overview, -1) {
+		if len(ranked) >= top {
+			break
+		}
+		p := strings.TrimPrefix(strings.TrimSpace(m[1]), "./")
+		if slices.Contains(paths, p) && !slices.Contains(ranked, p) {
+			ranked = append(ranked, p)
+		}
+	}
+	return ranked
+}
+
+// Paths は差分に含まれるファイルのパスを差分の順に返します。
+func Paths(diff string) []string {
+	var paths []string
+	for _, f := range monorepo.SplitDiff(diff) {
+		if f.Path != "" && !slices.Contains(paths, f.Path) {
+			paths = append(paths, f.Path)
+		}
+	}
+	return paths
+}
+
+// Select は、差分から paths のファイルの差分のみを差分の順に取り出します。
+func Select(diff string, paths []string) string {
+	var sb strings.Builder
+	for _, f := range monorepo.SplitDiff(diff) {
+		if slices.Contains(paths, f.Path) {
+			sb.WriteString(f.Content)
+		}
+	}
+	return sb.String()
+}
+
+// OverviewNote は、1回目の概要を2回目のレビューに伝えるプロンプトの前置きを返します。概要がない場合は対象の絞り込みのみを伝えます。
+func OverviewNote(overview string, selected, rest []string) string {
+	var sb strings.Builder
+	sb.WriteString("## 🔭 2段階レビューの詳細レビュー (ツールによる自動判定)\n\n")
+	fmt.Fprintf(&sb, "このレビューは2段階のレビューの2回目です。差分は、リスクの高い %d 件のファイルに絞っています。", len(selected))
+	if len(rest) > 0 {
+		fmt.Fprintf(&sb, "ほかの %d 件のファイルも変更されていますが、このレビューの対象外です。", len(rest))
+	}
+	sb.WriteString("対象のファイルを詳しくレビューしてください。\n\n")
+	if overview = strings.TrimSpace(overview); overview != "" {
+		sb.WriteString("1回目のレビューによる変更全体の概要は次のとおりです。対象外のファイルとの関係を踏まえて指摘してください。\n\n")
+		fmt.Fprintf(&sb, "<overview>\n%s\n</overview>\n\n", overview)
+	}
+	sb.WriteString("---\n\n")
+	return sb.String()
+}
+
+// Report は、1回目の概要と2回目の詳細レビューを1つのレポートにまとめます。
+// 判定の解析は最初に現れる判定を採用するため、呼び出し元で詳細レビューの判定を先頭に置いてください。
+func Report(overview, deepDive string, selected, rest []string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "> 🔭 2段階でレビューしました。変更全体の概要とファイルのリスクを評価し、リスクの高い %d/%d 件のファイルを詳しくレビューしています。\n\n", len(selected), len(selected)+len(rest))
+	if overview = strings.TrimSpace(overview); overview != "" {
+		sb.WriteString("# 🗺️ 変更の概要とリスクの評価 (1回目)\n\n")
+		sb.WriteString(demote(overview))
+		sb.WriteString("\n\n")
+	}
+	fmt.Fprintf(&sb, "# 🔍 詳細レビュー (2回目: %s)\n\n", quoteAll(selected))
+	sb.WriteString(strings.TrimSpace(deepDive))
+	if len(rest) > 0 {
+		sb.WriteString("\n\n## 📋 詳細レビューの対象外のファイル\n\n")
+		for i, p := range rest {
+			if i == listLimit {
+				fmt.Fprintf(&sb, "- (ほか %d 件)\n", len(rest)-listLimit)
+				break
+			}
+			fmt.Fprintf(&sb, "- `%s`\n", p)
+		}
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// demote は、概要の見出しを1段階下げ、レポートの見出しの下に収めます。コードブロック内の行は変更しません。
+func demote(markdown string) string {
+	lines := strings.Split(markdown, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if !inFence && heading.MatchString(line) {
+			lines[i] = "#" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// heading は、1段階下げられる Markdown の見出しの行です。
+var heading = regexp.MustCompile(`^#{1,5}\s`)
+
+// quoteAll はパスをバッククォートで囲み、読点で区切って返します。
+func quoteAll(paths []string) string {
+	quoted := make([]string, len(paths))
+	for i, p := range paths {
+		quoted[i] = "`" + p + "`"
+	}
+	return strings.Join(quoted, "、")
+}
@@ -0,0 +1,69 @@
+package verdict
+
+import (
+	"regexp"
+)
+
+// Verdict はレビュー結果に含まれるリリース可否の判定です。
+type Verdict string
+
+const (
+	// Blocked はクリティカルな問題が見つかり、リリース不可と判定されたことを表します。
+	Blocked Verdict = "blocked"
+	// Conditional は軽微な問題があり、条件付きでリリース可と判定されたことを表します。
+	Conditional Verdict = "conditional"
+	// Approved はクリティカルな問題がなく、リリース可と判定されたことを表します。
+	Approved Verdict = "approved"
+	// Unknown は判定を読み取れなかったことを表します。
+	Unknown Verdict = "unknown"
+)
+
+// patterns はプロンプトテンプレートが指示する判定の表記です。
+// "リリース可否判定" の見出しや "条件付きリリース可" に誤って一致しないよう、最も早く出現した表記を採用します。
+var patterns = []struct {
+	verdict Verdict
+	re      *regexp.Regexp
+}{
+	{Blocked, regexp.MustCompile(`リリース不可|Critical Issues Found`)},
+	{Conditional, regexp.MustCompile(`条件付きリリース可|Minor Issues Found`)},
+	{Approved, regexp.MustCompile(`リリース可(?:[^否]|$)|No Critical Issues`)},
+}
+
+// Parse はレビュー結果の Markdown から判定を読み取ります。
+func Parse(review string) Verdict {
+	found, at := Unknown, -1
+	for _, p := range patterns {
+		loc := p.re.FindStringIndex(review)
+		if loc == nil {
+			continue
+		}
+		if at == -1 || loc[0] < at {
+			found, at = p.verdict, loc[0]
+		}
+	}
+	return found
+}
+
+// Label は判定の表示名を返します。
+func (v Verdict) Label() string {
+	switch v {
+	case Blocked:
+		return "リリース不可"
+	case Conditional:
+		return "条件付きリリース可"
+	case Approved:
+		return "リリース可"
+	}
+	return "判定不明"
+}
+
+// Raise は判定を1段階厳しくした判定を返します。リリース不可と判定不明はそのまま返します。
+func (v Verdict) Raise() Verdict {
+	switch v {
+	case Approved:
+		return Conditional
+	case Conditional:
+		return Blocked
+	}
+	return v
+}
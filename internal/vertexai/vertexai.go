@@ -0,0 +1,153 @@
+// Package vertexai は、API キーの代わりに Application Default Credentials (ADC) またはサービスアカウントで認証し、
+// Vertex AI のエンドポイント経由で Gemini を呼び出すクライアントを提供します。
+// ロケーションにリージョンを指定すると、そのリージョンのエンドポイント ('<リージョン>-aiplatform.googleapis.com') で処理させます。
+package vertexai
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"git-gemini-reviewer-go/internal/sampling"
+	"git-gemini-reviewer-go/internal/streamai"
+	"git-gemini-reviewer-go/internal/tokencount"
+
+	"cloud.google.com/go/auth/credentials"
+	"cloud.google.com/go/auth/httptransport"
+	"github.com/shouni/gemini-reviewer-core/pkg/adapters"
+	"google.golang.org/genai"
+)
+
+// scope は Vertex AI の呼び出しに必要な OAuth スコープです。
+const scope = "https://www.googleapis.com/auth/cloud-platform"
+
+// GlobalLocation は、リージョンを固定しないグローバルエンドポイントを表すロケーションです。
+const GlobalLocation = "global"
+
+// Options は Vertex AI への接続の設定です。
+type Options struct {
+	// Project は GCP のプロジェクトIDです。空の場合は環境変数 GOOGLE_CLOUD_PROJECT を使用します。
+	Project string
+	// Location はリクエストを処理させるリージョン (例: 'asia-northeast1') または 'global' です。
+	// 空の場合は環境変数 GOOGLE_CLOUD_LOCATION (未設定時は GOOGLE_CLOUD_REGION) を使用します。
+	Location string
+	// CredentialsFile はサービスアカウントキー (JSON) のパスです。空の場合は ADC を使用します。
+	CredentialsFile string
+}
+
+// resolve は環境変数で未指定の値を補完し、必須の値を検証します。
+// データの処理地域を意図せず変えないよう、ロケーションは既定値を持たず、指定を必須とします。
+func (o Options) resolve() (Options, error) {
+	if o.Project == "" {
+		o.Project = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+	if o.Location == "" {
+		o.Location = os.Getenv("GOOGLE_CLOUD_LOCATION")
+	}
+	if o.Location == "" {
+		o.Location = os.Getenv("GOOGLE_CLOUD_REGION")
+	}
+	if o.Project == "" {
+		return o, fmt.Errorf("Vertex AI のプロジェクトが指定されていません (--vertex-project または環境変数 GOOGLE_CLOUD_PROJECT で指定してください)")
+	}
+	if o.Location == "" {
+		return o, fmt.Errorf("Vertex AI のロケーションが指定されていません (--vertex-location または環境変数 GOOGLE_CLOUD_LOCATION で、'asia-northeast1' などのリージョンまたは '%s' を指定してください)", GlobalLocation)
+	}
+	return o, nil
+}
+
+// NewClient は Vertex AI のエンドポイントを使用する genai のクライアントを返します。
+// 認証はサービスアカウントキー (Options.CredentialsFile)、未指定の場合は ADC で行います。
+// httpClient のトランスポートを下層に使用するため、共通の HTTP 設定 (プロキシ・TLS) が適用されます。
+func NewClient(ctx context.Context, httpClient *http.Client, opts Options) (*genai.Client, error) {
+	opts, err := opts.resolve()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Location == GlobalLocation {
+		slog.Warn("Vertex AI のグローバルエンドポイントを使用します。リクエストを処理するリージョンは固定されません。")
+	}
+
+	creds, err := credentials.DetectDefault(&credentials.DetectOptions{
+		Scopes:          []string{scope},
+		CredentialsFile: opts.CredentialsFile,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Vertex AI の認証情報の取得に失敗しました: %w", err)
+	}
+	headers := http.Header{}
+	if quotaProject, err := creds.QuotaProjectID(ctx); err == nil && quotaProject != "" {
+		headers.Set("X-Goog-User-Project", quotaProject)
+	}
+	var base http.RoundTripper = http.DefaultTransport
+	if httpClient != nil && httpClient.Transport != nil {
+		base = httpClient.Transport
+	}
+	authClient, err := httptransport.NewClient(&httptransport.Options{
+		Credentials:      creds,
+		Headers:          headers,
+		BaseRoundTripper: base,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Vertex AI の HTTP クライアントの作成に失敗しました: %w", err)
+	}
+	if httpClient != nil {
+		authClient.Timeout = httpClient.Timeout
+	}
+
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		Backend:    genai.BackendVertexAI,
+		Project:    opts.Project,
+		Location:   opts.Location,
+		HTTPClient: authClient,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Vertex AI クライアントの初期化に失敗しました: %w", err)
+	}
+	slog.Debug("Vertex AI クライアントを初期化しました。", "project", opts.Project, "location", opts.Location)
+	return client, nil
+}
+
+// New は Vertex AI 経由で model を呼び出す adapters.CodeReviewAI を返します。
+// 応答は streamai.Adapter で受信するため、コンテキストに書き出し先が設定されている場合は逐次書き出します。
+// params は応答の生成を調整するパラメータです。
+func New(ctx context.Context, httpClient *http.Client, model string, opts Options, params sampling.Params) (adapters.CodeReviewAI, error) {
+	client, err := NewClient(ctx, httpClient, opts)
+	if err != nil {
+		return nil, err
+	}
+	return streamai.NewWithClient(client, model, params), nil
+}
+
+// Counter は Vertex AI の countTokens で入力トークン数を数える tokencount.Counter です。
+type Counter struct {
+	client *genai.Client
+	model  string
+}
+
+var _ tokencount.Counter = (*Counter)(nil)
+
+// NewCounter は Vertex AI 経由で model のトークン数を数える Counter を返します。
+func NewCounter(ctx context.Context, httpClient *http.Client, model string, opts Options) (*Counter, error) {
+	client, err := NewClient(ctx, httpClient, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Counter{client: client, model: model}, nil
+}
+
+// CountTokens はプロンプトの入力トークン数を返します。
+func (c *Counter) CountTokens(ctx context.Context, prompt string) (int, error) {
+	resp, err := c.client.Models.CountTokens(ctx, c.model, genai.Text(prompt), nil)
+	if err != nil {
+		return 0, fmt.Errorf("Vertex AI の countTokens の呼び出しに失敗しました (Model: %s): %w", c.model, err)
+	}
+	return int(resp.TotalTokens), nil
+}
+
+// Method は tokencount.MethodAPI を返します。
+func (c *Counter) Method() string {
+	return tokencount.MethodAPI
+}
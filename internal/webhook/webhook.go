@@ -0,0 +1,68 @@
+// Package webhook は、レビュー結果を任意の社内システムに連携するための構造化された JSON のペイロードを組み立てます。
+// 送信と署名は callback パッケージと同じ方式 (X-Reviewer-Signature) を使用します。
+package webhook
+
+import (
+	"time"
+
+	"git-gemini-reviewer-go/internal/diffstat"
+	"git-gemini-reviewer-go/internal/findings"
+	"git-gemini-reviewer-go/internal/inline"
+	"git-gemini-reviewer-go/internal/tokenusage"
+)
+
+// Payload は webhook コマンドが送信するレビュー結果です。
+type Payload struct {
+	// SchemaVersion はペイロードのスキーマのバージョン (schema.Version) です。
+	SchemaVersion int       `json:"schema_version"`
+	ReviewID      string    `json:"review_id"`
+	RepoURL       string    `json:"repo_url"`
+	BaseBranch    string    `json:"base_branch"`
+	FeatureBranch string    `json:"feature_branch"`
+	Mode          string    `json:"mode"`
+	Model         string    `json:"model"`
+	Verdict       string    `json:"verdict,omitempty"`
+	DiffStats     DiffStats `json:"diff_stats"`
+	Review        string    `json:"review"`
+	Findings      []Finding `json:"findings"`
+	GeneratedAt   time.Time `json:"generated_at"`
+	// Usage は AI に送信・受信したトークン数と推定費用です。
+	Usage tokenusage.Report `json:"usage"`
+}
+
+// DiffStats は差分の変更量です。
+type DiffStats = diffstat.Report
+
+// Finding は1件の指摘です。
+// 構造化された指摘 (--inline-findings) がない場合は Markdown から抽出するため、重大度と修正案は空になります。
+type Finding struct {
+	File       string            `json:"file"`
+	Line       int               `json:"line"`
+	Severity   inline.Severity   `json:"severity,omitempty"`
+	Category   findings.Category `json:"category"`
+	Message    string            `json:"message"`
+	Suggestion string            `json:"suggestion,omitempty"`
+}
+
+// NewDiffStats は種類別の集計結果をペイロードの形式に変換します。
+func NewDiffStats(s diffstat.Stats) DiffStats {
+	return s.Report()
+}
+
+// Findings は指摘の一覧を返します。structured が nil の場合はレビュー結果の Markdown から抽出します。
+// 抑制された指摘は含めません。
+func Findings(structured *inline.Review, reviewResult string) []Finding {
+	if structured != nil {
+		out := make([]Finding, 0, len(structured.Findings))
+		for _, f := range structured.Findings {
+			out = append(out, Finding{File: f.File, Line: f.Line, Severity: f.Severity, Category: f.Category, Message: f.Message, Suggestion: f.Suggestion})
+		}
+		return out
+	}
+	extracted := findings.Extract(reviewResult)
+	out := make([]Finding, 0, len(extracted))
+	for _, f := range extracted {
+		out = append(out, Finding{File: f.File, Line: f.Line, Category: f.Category, Message: f.Text})
+	}
+	return out
+}